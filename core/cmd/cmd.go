@@ -34,13 +34,20 @@ import (
 	accesstokenctl "github.com/horizoncd/horizon/core/controller/accesstoken"
 	applicationctl "github.com/horizoncd/horizon/core/controller/application"
 	applicationregionctl "github.com/horizoncd/horizon/core/controller/applicationregion"
+	auditctl "github.com/horizoncd/horizon/core/controller/audit"
+	autodeployctl "github.com/horizoncd/horizon/core/controller/autodeploy"
+	batchjobctl "github.com/horizoncd/horizon/core/controller/batchjob"
 	"github.com/horizoncd/horizon/core/controller/build"
+	buildcontextctl "github.com/horizoncd/horizon/core/controller/buildcontext"
 	clusterctl "github.com/horizoncd/horizon/core/controller/cluster"
 	codectl "github.com/horizoncd/horizon/core/controller/code"
+	doractl "github.com/horizoncd/horizon/core/controller/dora"
+	driftctl "github.com/horizoncd/horizon/core/controller/drift"
 	environmentctl "github.com/horizoncd/horizon/core/controller/environment"
 	environmentregionctl "github.com/horizoncd/horizon/core/controller/environmentregion"
 	envtemplatectl "github.com/horizoncd/horizon/core/controller/envtemplate"
 	eventctl "github.com/horizoncd/horizon/core/controller/event"
+	freezectl "github.com/horizoncd/horizon/core/controller/freeze"
 	groupctl "github.com/horizoncd/horizon/core/controller/group"
 	idpctl "github.com/horizoncd/horizon/core/controller/idp"
 	memberctl "github.com/horizoncd/horizon/core/controller/member"
@@ -48,9 +55,13 @@ import (
 	oauthappctl "github.com/horizoncd/horizon/core/controller/oauthapp"
 	oauthcheckctl "github.com/horizoncd/horizon/core/controller/oauthcheck"
 	prctl "github.com/horizoncd/horizon/core/controller/pipelinerun"
+	promotionctl "github.com/horizoncd/horizon/core/controller/promotion"
 	regionctl "github.com/horizoncd/horizon/core/controller/region"
 	registryctl "github.com/horizoncd/horizon/core/controller/registry"
+	releaseplanctl "github.com/horizoncd/horizon/core/controller/releaseplan"
 	roltctl "github.com/horizoncd/horizon/core/controller/role"
+	runqueuectl "github.com/horizoncd/horizon/core/controller/runqueue"
+	schedulectl "github.com/horizoncd/horizon/core/controller/schedule"
 	scopectl "github.com/horizoncd/horizon/core/controller/scope"
 	tagctl "github.com/horizoncd/horizon/core/controller/tag"
 	templatectl "github.com/horizoncd/horizon/core/controller/template"
@@ -83,19 +94,30 @@ import (
 	accessv2 "github.com/horizoncd/horizon/core/http/api/v2/access"
 	accesstokenv2 "github.com/horizoncd/horizon/core/http/api/v2/accesstoken"
 	applicationregionv2 "github.com/horizoncd/horizon/core/http/api/v2/applicationregion"
+	auditv2 "github.com/horizoncd/horizon/core/http/api/v2/audit"
+	autodeployv2 "github.com/horizoncd/horizon/core/http/api/v2/autodeploy"
+	batchjobv2 "github.com/horizoncd/horizon/core/http/api/v2/batchjob"
+	buildcontextv2 "github.com/horizoncd/horizon/core/http/api/v2/buildcontext"
 	clusterv2 "github.com/horizoncd/horizon/core/http/api/v2/cluster"
 	codev2 "github.com/horizoncd/horizon/core/http/api/v2/code"
+	dorav2 "github.com/horizoncd/horizon/core/http/api/v2/dora"
+	driftv2 "github.com/horizoncd/horizon/core/http/api/v2/drift"
 	environmentv2 "github.com/horizoncd/horizon/core/http/api/v2/environment"
 	environmentregionv2 "github.com/horizoncd/horizon/core/http/api/v2/environmentregion"
 	eventv2 "github.com/horizoncd/horizon/core/http/api/v2/event"
+	freezev2 "github.com/horizoncd/horizon/core/http/api/v2/freeze"
 	groupv2 "github.com/horizoncd/horizon/core/http/api/v2/group"
 	idpv2 "github.com/horizoncd/horizon/core/http/api/v2/idp"
 	memberv2 "github.com/horizoncd/horizon/core/http/api/v2/member"
 	oauthappv2 "github.com/horizoncd/horizon/core/http/api/v2/oauthapp"
 	pipelinerunv2 "github.com/horizoncd/horizon/core/http/api/v2/pipelinerun"
+	promotionv2 "github.com/horizoncd/horizon/core/http/api/v2/promotion"
 	regionv2 "github.com/horizoncd/horizon/core/http/api/v2/region"
 	registryv2 "github.com/horizoncd/horizon/core/http/api/v2/registry"
+	releaseplanv2 "github.com/horizoncd/horizon/core/http/api/v2/releaseplan"
 	rolev2 "github.com/horizoncd/horizon/core/http/api/v2/role"
+	runqueuev2 "github.com/horizoncd/horizon/core/http/api/v2/runqueue"
+	schedulev2 "github.com/horizoncd/horizon/core/http/api/v2/schedule"
 	scopev2 "github.com/horizoncd/horizon/core/http/api/v2/scope"
 	tagv2 "github.com/horizoncd/horizon/core/http/api/v2/tag"
 	templateschematagv2 "github.com/horizoncd/horizon/core/http/api/v2/templateschematag"
@@ -111,10 +133,17 @@ import (
 	"github.com/horizoncd/horizon/pkg/grafana"
 	"github.com/horizoncd/horizon/pkg/jobs"
 	"github.com/horizoncd/horizon/pkg/jobs/autofree"
+	jobbatchjob "github.com/horizoncd/horizon/pkg/jobs/batchjob"
+	jobcanary "github.com/horizoncd/horizon/pkg/jobs/canary"
 	"github.com/horizoncd/horizon/pkg/jobs/clean"
+	jobdora "github.com/horizoncd/horizon/pkg/jobs/dora"
+	jobdrift "github.com/horizoncd/horizon/pkg/jobs/drift"
 	"github.com/horizoncd/horizon/pkg/jobs/eventhandler"
 	"github.com/horizoncd/horizon/pkg/jobs/grafanasync"
 	"github.com/horizoncd/horizon/pkg/jobs/k8sevent"
+	jobreleaseplan "github.com/horizoncd/horizon/pkg/jobs/releaseplan"
+	jobrunqueue "github.com/horizoncd/horizon/pkg/jobs/runqueue"
+	jobschedule "github.com/horizoncd/horizon/pkg/jobs/schedule"
 	jobwebhook "github.com/horizoncd/horizon/pkg/jobs/webhook"
 	"github.com/horizoncd/horizon/pkg/regioninformers"
 	"github.com/horizoncd/horizon/pkg/token/generator"
@@ -133,6 +162,8 @@ import (
 	templatev2 "github.com/horizoncd/horizon/core/http/api/v2/template"
 	"github.com/horizoncd/horizon/core/http/health"
 	"github.com/horizoncd/horizon/core/http/metrics"
+	auditmiddle "github.com/horizoncd/horizon/core/middleware/audit"
+	freezemiddle "github.com/horizoncd/horizon/core/middleware/freeze"
 	ginlogmiddle "github.com/horizoncd/horizon/core/middleware/ginlog"
 	logmiddle "github.com/horizoncd/horizon/core/middleware/log"
 	metricsmiddle "github.com/horizoncd/horizon/core/middleware/metrics"
@@ -145,6 +176,8 @@ import (
 	"github.com/horizoncd/horizon/pkg/application/gitrepo"
 	applicationservice "github.com/horizoncd/horizon/pkg/application/service"
 	userauth "github.com/horizoncd/horizon/pkg/authentication/user"
+	"github.com/horizoncd/horizon/pkg/canary/prometheus"
+	"github.com/horizoncd/horizon/pkg/cluster/ci"
 	"github.com/horizoncd/horizon/pkg/cluster/code"
 	clustergitrepo "github.com/horizoncd/horizon/pkg/cluster/gitrepo"
 	clusterservice "github.com/horizoncd/horizon/pkg/cluster/service"
@@ -152,6 +185,7 @@ import (
 	oauthconfig "github.com/horizoncd/horizon/pkg/config/oauth"
 	"github.com/horizoncd/horizon/pkg/config/pprof"
 	roleconfig "github.com/horizoncd/horizon/pkg/config/role"
+	freezeservice "github.com/horizoncd/horizon/pkg/freeze/service"
 	groupservice "github.com/horizoncd/horizon/pkg/group/service"
 	memberservice "github.com/horizoncd/horizon/pkg/member/service"
 	oauthdao "github.com/horizoncd/horizon/pkg/oauth/dao"
@@ -161,6 +195,8 @@ import (
 	"github.com/horizoncd/horizon/pkg/param/managerparam"
 	"github.com/horizoncd/horizon/pkg/rbac"
 	"github.com/horizoncd/horizon/pkg/rbac/role"
+	"github.com/horizoncd/horizon/pkg/templaterelease/analysis"
+	"github.com/horizoncd/horizon/pkg/templaterelease/migration"
 	"github.com/horizoncd/horizon/pkg/templaterelease/output"
 	templateschemarepo "github.com/horizoncd/horizon/pkg/templaterelease/schema/repo"
 	"github.com/horizoncd/horizon/pkg/templaterepo"
@@ -357,6 +393,8 @@ func Init(ctx context.Context, flags *Flags, coreConfig *config.Config) {
 	if err != nil {
 		panic(err)
 	}
+	analysisGetter := analysis.NewAnalysisGetter(templateRepo, manager)
+	migrationGetter := migration.NewMigrationGetter(templateRepo, manager)
 
 	gitGetter, err := code.NewGitGetter(ctx, coreConfig.CodeGitRepos)
 	if err != nil {
@@ -366,6 +404,10 @@ func Init(ctx context.Context, flags *Flags, coreConfig *config.Config) {
 	if err != nil {
 		panic(err)
 	}
+	ciFty, err := ci.NewFactory(coreConfig.CIMapper, tektonFty)
+	if err != nil {
+		panic(err)
+	}
 
 	oauthAppDAO := oauthdao.NewDAO(mysqlDB)
 	tokenStorage := tokenstorage.NewStorage(mysqlDB)
@@ -429,6 +471,7 @@ func Init(ctx context.Context, flags *Flags, coreConfig *config.Config) {
 	clusterSvc := clusterservice.NewService(applicationSvc, manager)
 	userSvc := userservice.NewService(manager)
 	tokenSvc := tokenservice.NewService(manager, coreConfig.TokenConfig)
+	freezeSvc := freezeservice.NewService(manager)
 
 	// init kube client
 	_, client, err := kube.BuildClient(coreConfig.KubeConfig)
@@ -455,13 +498,17 @@ func Init(ctx context.Context, flags *Flags, coreConfig *config.Config) {
 		TemplateSchemaGetter: templateSchemaGetter,
 		CD: cd.NewCD(regionInformers, clusterGitRepo, coreConfig.ArgoCDMapper,
 			coreConfig.GitopsRepoConfig.DefaultBranch),
-		K8sUtil:        cd.NewK8sUtil(regionInformers, manager.EventManager),
-		OutputGetter:   outputGetter,
-		TektonFty:      tektonFty,
-		ClusterGitRepo: clusterGitRepo,
-		GitGetter:      gitGetter,
-		GrafanaService: grafanaService,
-		BuildSchema:    buildSchema,
+		K8sUtil:         cd.NewK8sUtil(regionInformers, manager.EventManager),
+		OutputGetter:    outputGetter,
+		AnalysisGetter:  analysisGetter,
+		MigrationGetter: migrationGetter,
+		TektonFty:       tektonFty,
+		CIFty:           ciFty,
+		ClusterGitRepo:  clusterGitRepo,
+		GitGetter:       gitGetter,
+		GrafanaService:  grafanaService,
+		FreezeSvc:       freezeSvc,
+		BuildSchema:     buildSchema,
 	}
 
 	var (
@@ -491,6 +538,7 @@ func Init(ctx context.Context, flags *Flags, coreConfig *config.Config) {
 		templateSchemaTagCtl = templateschematagctl.NewController(parameter)
 		accessCtl            = accessctl.NewController(rbacAuthorizer, rbacSkippers...)
 		applicationRegionCtl = applicationregionctl.NewController(parameter)
+		auditCtl             = auditctl.NewController(parameter)
 		groupCtl             = groupctl.NewController(parameter)
 		oauthCheckerCtl      = oauthcheckctl.NewOauthChecker(parameter)
 		oauthAppCtl          = oauthappctl.NewController(parameter)
@@ -504,8 +552,18 @@ func Init(ctx context.Context, flags *Flags, coreConfig *config.Config) {
 		buildSchemaCtrl      = build.NewController(buildSchema)
 		accessTokenCtl       = accesstokenctl.NewController(parameter)
 		scopeCtl             = scopectl.NewController(parameter)
-		webhookCtl           = webhookctl.NewController(parameter)
+		webhookCtl           = webhookctl.NewController(&coreConfig.WebhookConfig, parameter)
 		eventCtl             = eventctl.NewController(parameter)
+		promotionCtl         = promotionctl.NewController(parameter)
+		freezeCtl            = freezectl.NewController(parameter)
+		releasePlanCtl       = releaseplanctl.NewController(parameter)
+		driftCtl             = driftctl.NewController(parameter)
+		batchJobCtl          = batchjobctl.NewController(&coreConfig.BatchJobConfig, parameter)
+		scheduleCtl          = schedulectl.NewController(&coreConfig.ScheduleConfig, parameter)
+		autoDeployCtl        = autodeployctl.NewController(parameter, clusterCtl, accessCtl)
+		runQueueCtl          = runqueuectl.NewController(&coreConfig.RunQueueConfig, parameter)
+		buildContextCtl      = buildcontextctl.NewController(parameter)
+		doraCtl              = doractl.NewController(parameter)
 	)
 
 	var (
@@ -543,20 +601,31 @@ func Init(ctx context.Context, flags *Flags, coreConfig *config.Config) {
 		accessTokenAPIV2       = accesstokenv2.NewAPI(accessTokenCtl, roleService, scopeService)
 		applicationAPIV2       = appv2.NewAPI(applicationCtl)
 		applicationRegionAPIV2 = applicationregionv2.NewAPI(applicationRegionCtl)
+		auditAPIV2             = auditv2.NewAPI(auditCtl)
 		buildSchemaAPI         = buildAPI.NewAPI(buildSchemaCtrl)
 		clusterAPIV2           = clusterv2.NewAPI(clusterCtl)
 		codeGitAPIV2           = codev2.NewAPI(codeGitCtl)
+		driftAPIV2             = driftv2.NewAPI(driftCtl)
 		environmentAPIV2       = environmentv2.NewAPI(environmentCtl)
 		environmentRegionAPIV2 = environmentregionv2.NewAPI(environmentregionCtl)
 		envtemplateAPIV2       = envtemplatev2.NewAPI(envTemplateCtl)
 		eventAPIV2             = eventv2.NewAPI(eventCtl)
+		freezeAPIV2            = freezev2.NewAPI(freezeCtl)
 		groupAPIV2             = groupv2.NewAPI(groupCtl)
 		idpAPIV2               = idpv2.NewAPI(idpCtrl, store)
 		memberAPIV2            = memberv2.NewAPI(memberCtl, roleService)
 		oauthAppAPIV2          = oauthappv2.NewAPI(oauthAppCtl)
 		pipelinerunAPIV2       = pipelinerunv2.NewAPI(prCtl)
+		promotionAPIV2         = promotionv2.NewAPI(promotionCtl)
 		regionAPIV2            = regionv2.NewAPI(regionCtl, tagCtl)
 		registryAPIV2          = registryv2.NewAPI(registryCtl)
+		releasePlanAPIV2       = releaseplanv2.NewAPI(releasePlanCtl)
+		batchJobAPIV2          = batchjobv2.NewAPI(batchJobCtl)
+		scheduleAPIV2          = schedulev2.NewAPI(scheduleCtl)
+		autoDeployAPIV2        = autodeployv2.NewAPI(autoDeployCtl)
+		runQueueAPIV2          = runqueuev2.NewAPI(runQueueCtl)
+		buildContextAPIV2      = buildcontextv2.NewAPI(buildContextCtl)
+		doraAPIV2              = dorav2.NewAPI(doraCtl)
 		roleAPIV2              = rolev2.NewAPI(roleCtl)
 		scopeAPIV2             = scopev2.NewAPI(scopeCtl)
 		tagAPIV2               = tagv2.NewAPI(tagCtl)
@@ -578,8 +647,19 @@ func Init(ctx context.Context, flags *Flags, coreConfig *config.Config) {
 		grafanasync.Run(ctx, coreConfig, manager, client)
 	}
 	k8seventJob := k8sevent.New(coreConfig.KubernetesEvent, regionInformers, manager, mysqlDB)
+	canaryJob := jobcanary.New(&coreConfig.CanaryConfig, manager, clusterCtl, analysisGetter,
+		prometheus.NewQuerier(coreConfig.CanaryConfig.QueryTimeout))
+	releasePlanJob := jobreleaseplan.New(&coreConfig.ReleasePlanConfig, manager, clusterCtl,
+		parameter.CD, freezeSvc)
+	driftJob := jobdrift.New(&coreConfig.DriftConfig, manager, parameter.CD)
+	batchJobJob := jobbatchjob.New(&coreConfig.BatchJobConfig, manager, clusterCtl, tagCtl, accessCtl,
+		rbacAuthorizer, freezeSvc)
+	scheduleJob := jobschedule.New(&coreConfig.ScheduleConfig, manager, clusterCtl, accessCtl, freezeSvc)
+	runQueueJob := jobrunqueue.New(&coreConfig.RunQueueConfig, manager, clusterCtl)
+	doraJob := jobdora.New(&coreConfig.DORAConfig, manager)
 	go jobs.Run(ctx, &coreConfig.JobConfig, eventHandlerJob, webhookJob,
-		k8seventJob.Run, cleaner.Run, autoFreeJob, grafanaSyncJob)
+		k8seventJob.Run, cleaner.Run, autoFreeJob, grafanaSyncJob, canaryJob.Run, releasePlanJob.Run,
+		driftJob.Run, batchJobJob.Run, scheduleJob.Run, runQueueJob.Run, doraJob.Run)
 
 	// init server
 	r := gin.New()
@@ -593,6 +673,10 @@ func Init(ctx context.Context, flags *Flags, coreConfig *config.Config) {
 		metricsmiddle.Middleware( // metrics middleware
 			middleware.MethodAndPathSkipper("*", regexp.MustCompile("^/health")),
 			middleware.MethodAndPathSkipper("*", regexp.MustCompile("^/metrics"))),
+		// audit middleware, record mutating requests including the ones rejected by the middlewares below
+		auditmiddle.Middleware(parameter,
+			middleware.MethodAndPathSkipper("*", regexp.MustCompile("^/health")),
+			middleware.MethodAndPathSkipper("*", regexp.MustCompile("^/metrics"))),
 		regionmiddle.Middleware(parameter, applicationRegionCtl),
 		tokenmiddle.MiddleWare(oauthCheckerCtl, rbacSkippers...),
 		//  user middleware, check user and attach current user to context.
@@ -607,6 +691,8 @@ func Init(ctx context.Context, flags *Flags, coreConfig *config.Config) {
 			middleware.MethodAndPathSkipper(http.MethodPost, regexp.MustCompile("^/apis/core/v[12]/users/login"))),
 		prehandlemiddle.Middleware(r, manager),
 		auth.Middleware(rbacAuthorizer, rbacSkippers...),
+		// freeze middleware, reject mutating cluster operations in active freeze windows
+		freezemiddle.Middleware(rbacAuthorizer, parameter, rbacSkippers...),
 		tagmiddle.Middleware(), // tag middleware, parse and attach tagSelector to context
 	}
 	r.Use(middlewares...)
@@ -654,20 +740,31 @@ func Init(ctx context.Context, flags *Flags, coreConfig *config.Config) {
 		accessTokenAPIV2,
 		applicationAPIV2,
 		applicationRegionAPIV2,
+		auditAPIV2,
+		autoDeployAPIV2,
+		batchJobAPIV2,
+		buildContextAPIV2,
 		buildSchemaAPI,
 		clusterAPIV2,
 		codeGitAPIV2,
+		doraAPIV2,
+		driftAPIV2,
 		environmentAPIV2,
 		environmentRegionAPIV2,
 		envtemplateAPIV2,
 		eventAPIV2,
+		freezeAPIV2,
 		idpAPIV2,
 		memberAPIV2,
 		oauthAppAPIV2,
 		pipelinerunAPIV2,
+		promotionAPIV2,
 		regionAPIV2,
 		registryAPIV2,
+		releasePlanAPIV2,
 		roleAPIV2,
+		runQueueAPIV2,
+		scheduleAPIV2,
 		scopeAPIV2,
 		tagAPIV2,
 		templateAPIV2,
@@ -679,7 +776,7 @@ func Init(ctx context.Context, flags *Flags, coreConfig *config.Config) {
 
 	// start cloud event server
 	go runCloudEventServer(
-		tektonFty,
+		ciFty,
 		coreConfig.CloudEventServerConfig,
 		parameter,
 		ginlogmiddle.Middleware(gin.DefaultWriter, "/health", "/metrics"),
@@ -17,25 +17,26 @@ package common
 import triggers "github.com/tektoncd/triggers/pkg/apis/triggers/v1alpha1"
 
 const (
-	ClusterQueryEnvironment   = "environment"
-	ClusterQueryName          = "filter"
-	ClusterQueryByUser        = "userID"
-	ClusterQueryByTemplate    = "template"
-	ClusterQueryByRelease     = "templateRelease"
-	ClusterQueryByRegion      = "region"
-	ClusterQueryTagSelector   = "tagSelector"
-	ClusterQueryScope         = "scope"
-	ClusterQueryMergePatch    = "mergePatch"
-	ClusterQueryTargetBranch  = "targetBranch"
-	ClusterQueryTargetCommit  = "targetCommit"
-	ClusterQueryTargetTag     = "targetTag"
-	ClusterQueryContainerName = "containerName"
-	ClusterQueryPodName       = "podName"
-	ClusterQueryTailLines     = "tailLines"
-	ClusterQueryStart         = "start"
-	ClusterQueryEnd           = "end"
-	ClusterQueryExtraOwner    = "extraOwner"
-	ClusterQueryHard          = "hard"
+	ClusterQueryEnvironment    = "environment"
+	ClusterQueryName           = "filter"
+	ClusterQueryByUser         = "userID"
+	ClusterQueryByTemplate     = "template"
+	ClusterQueryByRelease      = "templateRelease"
+	ClusterQueryByApplications = "applicationIDs"
+	ClusterQueryByRegion       = "region"
+	ClusterQueryTagSelector    = "tagSelector"
+	ClusterQueryScope          = "scope"
+	ClusterQueryMergePatch     = "mergePatch"
+	ClusterQueryTargetBranch   = "targetBranch"
+	ClusterQueryTargetCommit   = "targetCommit"
+	ClusterQueryTargetTag      = "targetTag"
+	ClusterQueryContainerName  = "containerName"
+	ClusterQueryPodName        = "podName"
+	ClusterQueryTailLines      = "tailLines"
+	ClusterQueryStart          = "start"
+	ClusterQueryEnd            = "end"
+	ClusterQueryExtraOwner     = "extraOwner"
+	ClusterQueryHard           = "hard"
 
 	// ClusterQueryIsFavorite is used to query cluster with favorite for current user only.
 	ClusterQueryIsFavorite = "isFavorite"
@@ -46,6 +47,9 @@ const (
 	ClusterQueryByGVK = "gvk"
 
 	ClusterQueryResourceName = "resourceName"
+
+	// ClusterQueryDryRun is used to preview the result of an operation without applying it.
+	ClusterQueryDryRun = "dryRun"
 )
 
 const (
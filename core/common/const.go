@@ -36,6 +36,8 @@ const (
 	Orphaned  = "orphaned"
 	OrderBy   = "orderBy"
 	ReqID     = "reqID"
+	// Channel should be type []string
+	Channel = "channel"
 
 	DefaultPageNumber = 1
 	DefaultPageSize   = 20
@@ -104,6 +106,31 @@ const (
 
 	ResourceWebhook    = "webhooks"
 	ResourceWebhookLog = "webhooklogs"
+
+	ResourceEnvironment = "environments"
+
+	// ResourceFreezeWindow currently freeze windows do not have direct member info, will
+	// use the member info of the group or application they are attached to
+	ResourceFreezeWindow = "freezewindows"
+
+	// ResourceReleasePlan currently release plans do not have direct member info, will
+	// use the application's member info
+	ResourceReleasePlan = "releaseplans"
+
+	// ResourceBatchJob currently batch jobs do not have direct member info, will
+	// use the member info of the group they select clusters from
+	ResourceBatchJob = "batchjobs"
+
+	// ResourceSchedule currently schedules do not have direct member info, will
+	// use the member info of the cluster they belong to
+	ResourceSchedule = "schedules"
+
+	ResourceRegistry = "registries"
+
+	ResourceIdp = "idps"
+
+	// ResourceUser is used by events of personal access tokens
+	ResourceUser = "users"
 )
 
 const (
@@ -120,6 +147,7 @@ const (
 	ParamResourceType  = "resourceType"
 	ParamResourceID    = "resourceID"
 	ParamAccessTokenID = "accessTokenID"
+	ParamPipelinerunID = "pipelinerunID"
 )
 
 const (
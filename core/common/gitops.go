@@ -26,6 +26,10 @@ const (
 	GitopsFilePipeline       = "pipeline/pipeline.yaml"
 	GitopsFilePipelineOutput = "pipeline/pipeline-output.yaml"
 	GitopsFileManifest       = "manifest.yaml"
+	// only for kustomize and raw templates
+	GitopsFileKustomization        = "kustomization.yaml"
+	GitopsFileRestartKustomization = "system/restart/kustomization.yaml"
+	GitopsDirTemplate              = "template"
 
 	// value namespace
 	GitopsEnvValueNamespace  = "env"
@@ -15,3 +15,9 @@
 package common
 
 var ContextAuthRecord = "authRecord"
+
+// ContextAuthDecision is the auth.Decision made for the request
+var ContextAuthDecision = "authDecision"
+
+// ContextAuthReason is the reason of the decision made for the request
+var ContextAuthReason = "authReason"
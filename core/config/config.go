@@ -17,12 +17,19 @@ package config
 import (
 	"io/ioutil"
 	"strings"
+	"time"
 
 	"github.com/horizoncd/horizon/pkg/config/argocd"
 	"github.com/horizoncd/horizon/pkg/config/authenticate"
 	"github.com/horizoncd/horizon/pkg/config/autofree"
+	"github.com/horizoncd/horizon/pkg/config/batchjob"
+	"github.com/horizoncd/horizon/pkg/config/buildcache"
+	"github.com/horizoncd/horizon/pkg/config/canary"
+	"github.com/horizoncd/horizon/pkg/config/ci"
 	"github.com/horizoncd/horizon/pkg/config/clean"
 	"github.com/horizoncd/horizon/pkg/config/db"
+	"github.com/horizoncd/horizon/pkg/config/dora"
+	"github.com/horizoncd/horizon/pkg/config/drift"
 	"github.com/horizoncd/horizon/pkg/config/eventhandler"
 	"github.com/horizoncd/horizon/pkg/config/git"
 	"github.com/horizoncd/horizon/pkg/config/gitlab"
@@ -30,8 +37,12 @@ import (
 	"github.com/horizoncd/horizon/pkg/config/job"
 	"github.com/horizoncd/horizon/pkg/config/k8sevent"
 	"github.com/horizoncd/horizon/pkg/config/oauth"
+	"github.com/horizoncd/horizon/pkg/config/pipelinereport"
 	"github.com/horizoncd/horizon/pkg/config/pprof"
 	"github.com/horizoncd/horizon/pkg/config/redis"
+	"github.com/horizoncd/horizon/pkg/config/releaseplan"
+	"github.com/horizoncd/horizon/pkg/config/runqueue"
+	"github.com/horizoncd/horizon/pkg/config/schedule"
 	"github.com/horizoncd/horizon/pkg/config/server"
 	"github.com/horizoncd/horizon/pkg/config/session"
 	"github.com/horizoncd/horizon/pkg/config/tekton"
@@ -39,6 +50,7 @@ import (
 	"github.com/horizoncd/horizon/pkg/config/templaterepo"
 	"github.com/horizoncd/horizon/pkg/config/token"
 	"github.com/horizoncd/horizon/pkg/config/webhook"
+	runqueuemodels "github.com/horizoncd/horizon/pkg/runqueue/models"
 
 	"gopkg.in/yaml.v3"
 )
@@ -54,6 +66,7 @@ type Config struct {
 	ArgoCDMapper           argocd.Mapper           `yaml:"argoCDMapper"`
 	RedisConfig            redis.Redis             `yaml:"redisConfig"`
 	TektonMapper           tekton.Mapper           `yaml:"tektonMapper"`
+	CIMapper               ci.Mapper               `yaml:"ciMapper"`
 	TemplateRepo           templaterepo.Repo       `yaml:"templateRepo"`
 	AccessSecretKeys       authenticate.KeysConfig `yaml:"accessSecretKeys"`
 	GrafanaConfig          grafana.Config          `yaml:"grafanaConfig"`
@@ -67,6 +80,15 @@ type Config struct {
 	TemplateUpgradeMapper  template.UpgradeMapper  `yaml:"templateUpgradeMapper"`
 	KubernetesEvent        k8sevent.Config         `yaml:"kubernetesEvent"`
 	Clean                  clean.Config            `yaml:"clean"`
+	CanaryConfig           canary.Config           `yaml:"canary"`
+	ReleasePlanConfig      releaseplan.Config      `yaml:"releasePlan"`
+	DriftConfig            drift.Config            `yaml:"drift"`
+	BatchJobConfig         batchjob.Config         `yaml:"batchJob"`
+	BuildCacheConfig       buildcache.Config       `yaml:"buildCache"`
+	PipelinerunReport      pipelinereport.Config   `yaml:"pipelinerunReport"`
+	ScheduleConfig         schedule.Config         `yaml:"schedule"`
+	RunQueueConfig         runqueue.Config         `yaml:"runQueue"`
+	DORAConfig             dora.Config             `yaml:"dora"`
 }
 
 func LoadConfig(configFilePath string) (*Config, error) {
@@ -98,6 +120,15 @@ func LoadConfig(configFilePath string) (*Config, error) {
 	}
 	config.TektonMapper = newTektonMapper
 
+	newCIMapper := ci.Mapper{}
+	for key, v := range config.CIMapper {
+		ks := strings.Split(key, ",")
+		for i := 0; i < len(ks); i++ {
+			newCIMapper[ks[i]] = v
+		}
+	}
+	config.CIMapper = newCIMapper
+
 	if config.EventHandlerConfig.BatchEventsCount <= 0 {
 		config.EventHandlerConfig.BatchEventsCount = 5
 	}
@@ -119,6 +150,66 @@ func LoadConfig(configFilePath string) (*Config, error) {
 	if config.WebhookConfig.ResponseBodyTruncateSize <= 0 {
 		config.WebhookConfig.ResponseBodyTruncateSize = 16384
 	}
+	if config.WebhookConfig.RetryPolicy.MaxAttempts <= 0 {
+		config.WebhookConfig.RetryPolicy.MaxAttempts = 3
+	}
+	if config.WebhookConfig.RetryPolicy.InitialInterval <= 0 {
+		config.WebhookConfig.RetryPolicy.InitialInterval = 10
+	}
+	if config.WebhookConfig.RetryPolicy.MaxInterval <= 0 {
+		config.WebhookConfig.RetryPolicy.MaxInterval = 600
+	}
+	if config.WebhookConfig.RetryPolicy.Multiplier <= 0 {
+		config.WebhookConfig.RetryPolicy.Multiplier = 2
+	}
+	if config.WebhookConfig.SecretRotationOverlap <= 0 {
+		config.WebhookConfig.SecretRotationOverlap = 86400
+	}
+	if config.CanaryConfig.JobInterval <= 0 {
+		config.CanaryConfig.JobInterval = 30 * time.Second
+	}
+	if config.CanaryConfig.QueryTimeout <= 0 {
+		config.CanaryConfig.QueryTimeout = 10 * time.Second
+	}
+	if config.ReleasePlanConfig.JobInterval <= 0 {
+		config.ReleasePlanConfig.JobInterval = 15 * time.Second
+	}
+	if config.ReleasePlanConfig.HealthTimeout <= 0 {
+		config.ReleasePlanConfig.HealthTimeout = 15 * time.Minute
+	}
+	if config.DriftConfig.JobInterval <= 0 {
+		config.DriftConfig.JobInterval = 5 * time.Minute
+	}
+	if config.BatchJobConfig.JobInterval <= 0 {
+		config.BatchJobConfig.JobInterval = 10 * time.Second
+	}
+	if config.BatchJobConfig.ClusterTimeout <= 0 {
+		config.BatchJobConfig.ClusterTimeout = 30 * time.Minute
+	}
+	if config.BatchJobConfig.MaxConcurrency <= 0 {
+		config.BatchJobConfig.MaxConcurrency = 20
+	}
+	if config.ScheduleConfig.JobInterval <= 0 {
+		config.ScheduleConfig.JobInterval = 30 * time.Second
+	}
+	if config.ScheduleConfig.TimeZone == "" {
+		config.ScheduleConfig.TimeZone = "Asia/Shanghai"
+	}
+	if config.RunQueueConfig.DefaultPolicy == "" {
+		config.RunQueueConfig.DefaultPolicy = runqueuemodels.PolicyParallel
+	}
+	if config.RunQueueConfig.JobInterval <= 0 {
+		config.RunQueueConfig.JobInterval = 10 * time.Second
+	}
+	if config.RunQueueConfig.LockTimeout <= 0 {
+		config.RunQueueConfig.LockTimeout = 2 * time.Hour
+	}
+	if config.DORAConfig.JobInterval <= 0 {
+		config.DORAConfig.JobInterval = 5 * time.Minute
+	}
+	if config.DORAConfig.Window <= 0 {
+		config.DORAConfig.Window = 7 * 24 * time.Hour
+	}
 
 	return &config, nil
 }
@@ -23,6 +23,7 @@ import (
 	"github.com/google/uuid"
 
 	tokenmanager "github.com/horizoncd/horizon/pkg/token/manager"
+	tokenmodels "github.com/horizoncd/horizon/pkg/token/models"
 	tokenservice "github.com/horizoncd/horizon/pkg/token/service"
 
 	"github.com/horizoncd/horizon/core/common"
@@ -31,12 +32,15 @@ import (
 	accesstokenmanager "github.com/horizoncd/horizon/pkg/accesstoken/manager"
 	"github.com/horizoncd/horizon/pkg/accesstoken/models"
 	perror "github.com/horizoncd/horizon/pkg/errors"
+	eventmanager "github.com/horizoncd/horizon/pkg/event/manager"
+	eventmodels "github.com/horizoncd/horizon/pkg/event/models"
 	membermanager "github.com/horizoncd/horizon/pkg/member"
 	membermodels "github.com/horizoncd/horizon/pkg/member/models"
 	memberservice "github.com/horizoncd/horizon/pkg/member/service"
 	"github.com/horizoncd/horizon/pkg/param"
 	usermanager "github.com/horizoncd/horizon/pkg/user/manager"
 	usermodels "github.com/horizoncd/horizon/pkg/user/models"
+	"github.com/horizoncd/horizon/pkg/util/log"
 )
 
 type Controller interface {
@@ -59,6 +63,7 @@ type controller struct {
 	tokenSvc       tokenservice.Service
 	memberSvc      memberservice.Service
 	memberMgr      membermanager.Manager
+	eventMgr       eventmanager.Manager
 }
 
 func NewController(param *param.Param) Controller {
@@ -69,6 +74,7 @@ func NewController(param *param.Param) Controller {
 		tokenSvc:       param.TokenSvc,
 		memberSvc:      param.MemberService,
 		memberMgr:      param.MemberManager,
+		eventMgr:       param.EventManager,
 	}
 }
 
@@ -129,6 +135,9 @@ func (c *controller) CreateResourceAccessToken(ctx context.Context, request Crea
 		Token: token.Code,
 	}
 
+	// never put the token code into the event
+	c.recordEvent(ctx, resourceType, resourceID, eventmodels.AccessTokenCreated,
+		nil, &resp.ResourceAccessToken)
 	return resp, nil
 }
 
@@ -163,6 +172,8 @@ func (c *controller) CreatePersonalAccessToken(ctx context.Context,
 		Token: token.Code,
 	}
 
+	c.recordEvent(ctx, common.ResourceUser, currentUser.GetID(), eventmodels.AccessTokenCreated,
+		nil, &resp.PersonalAccessToken)
 	return resp, nil
 }
 
@@ -264,7 +275,12 @@ func (c *controller) RevokePersonalAccessToken(ctx context.Context, id uint) err
 	}
 
 	// 2. delete token
-	return c.tokenMgr.RevokeTokenByID(ctx, id)
+	if err := c.tokenMgr.RevokeTokenByID(ctx, id); err != nil {
+		return err
+	}
+	c.recordEvent(ctx, common.ResourceUser, token.UserID, eventmodels.AccessTokenRevoked,
+		ofTokenExtra(token), nil)
+	return nil
 }
 
 func (c *controller) RevokeResourceAccessToken(ctx context.Context, id uint) error {
@@ -281,12 +297,13 @@ func (c *controller) RevokeResourceAccessToken(ctx context.Context, id uint) err
 		return perror.Wrap(herror.ErrParamInvalid, "this is not a resource token")
 	}
 
+	// list members of the robot user
+	members, err := c.memberMgr.ListMembersByUserID(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+
 	checkPermission := func() error {
-		// list members of the robot user
-		members, err := c.memberMgr.ListMembersByUserID(ctx, user.ID)
-		if err != nil {
-			return err
-		}
 		// check if current user can delete all the members
 		for _, member := range members {
 			if err := c.memberSvc.RequirePermissionEqualOrHigher(ctx, member.Role, string(member.ResourceType),
@@ -318,7 +335,43 @@ func (c *controller) RevokeResourceAccessToken(ctx context.Context, id uint) err
 	}
 
 	// 3. delete related resources
-	return cleanRelatedResources()
+	if err := cleanRelatedResources(); err != nil {
+		return err
+	}
+	for _, member := range members {
+		c.recordEvent(ctx, string(member.ResourceType), member.ResourceID,
+			eventmodels.AccessTokenRevoked, ofTokenExtra(token), nil)
+	}
+	return nil
+}
+
+// tokenExtra is the snapshot of a token recorded in events, the code is left out on purpose
+type tokenExtra struct {
+	ID        uint      `json:"id"`
+	Name      string    `json:"name"`
+	Scope     string    `json:"scope"`
+	UserID    uint      `json:"userID"`
+	CreatedAt time.Time `json:"createdAt"`
+	ExpiresAt string    `json:"expiresAt"`
+}
+
+func ofTokenExtra(token *tokenmodels.Token) *tokenExtra {
+	return &tokenExtra{
+		ID:        token.ID,
+		Name:      token.Name,
+		Scope:     token.Scope,
+		UserID:    token.UserID,
+		CreatedAt: token.CreatedAt,
+		ExpiresAt: parseExpiredAt(token.CreatedAt, token.ExpiresIn),
+	}
+}
+
+func (c *controller) recordEvent(ctx context.Context, resourceType string, resourceID uint,
+	eventType string, before, after interface{}) {
+	if _, err := c.eventMgr.CreateMutationEvent(ctx, resourceType, resourceID,
+		eventType, before, after); err != nil {
+		log.Warningf(ctx, "failed to create event, err: %s", err.Error())
+	}
 }
 
 func generateRobot(token, resourceType string, resourceID uint) *usermodels.User {
@@ -35,6 +35,7 @@ import (
 	applicationmodels "github.com/horizoncd/horizon/pkg/application/models"
 	userauth "github.com/horizoncd/horizon/pkg/authentication/user"
 	perror "github.com/horizoncd/horizon/pkg/errors"
+	eventmodels "github.com/horizoncd/horizon/pkg/event/models"
 	groupmodels "github.com/horizoncd/horizon/pkg/group/models"
 	membermodels "github.com/horizoncd/horizon/pkg/member/models"
 	memberservice "github.com/horizoncd/horizon/pkg/member/service"
@@ -82,6 +83,7 @@ func TestMain(m *testing.M) {
 		&tokenmodels.Token{},
 		&groupmodels.Group{},
 		&applicationmodels.Application{},
+		&eventmodels.Event{},
 	); err != nil {
 		panic(err)
 	}
@@ -0,0 +1,107 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audit
+
+import (
+	"context"
+	"io"
+	"net/http"
+
+	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/lib/q"
+	"github.com/horizoncd/horizon/pkg/audit"
+	auditmanager "github.com/horizoncd/horizon/pkg/audit/manager"
+	"github.com/horizoncd/horizon/pkg/audit/models"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/param"
+	"github.com/horizoncd/horizon/pkg/util/wlog"
+)
+
+// _exportBatch is the number of audit logs read from db at a time when exporting
+const _exportBatch = 500
+
+type Controller interface {
+	// List lists the audit logs with pagination, the latest first
+	List(ctx context.Context, query *q.Query) ([]*audit.Entry, int64, error)
+	// Export writes all the audit logs matched to w in the format for SIEM to ingest
+	Export(ctx context.Context, query *q.Query, format string, w io.Writer) error
+}
+
+type controller struct {
+	auditLogMgr auditmanager.Manager
+}
+
+var _ Controller = (*controller)(nil)
+
+func NewController(param *param.Param) Controller {
+	return &controller{
+		auditLogMgr: param.AuditLogMgr,
+	}
+}
+
+func (c *controller) List(ctx context.Context, query *q.Query) ([]*audit.Entry, int64, error) {
+	const op = "audit controller: list audit logs"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	if err := checkAdmin(ctx); err != nil {
+		return nil, 0, err
+	}
+	auditLogs, total, err := c.auditLogMgr.List(ctx, query)
+	if err != nil {
+		return nil, 0, err
+	}
+	entries := make([]*audit.Entry, 0, len(auditLogs))
+	for _, l := range auditLogs {
+		entries = append(entries, audit.OfAuditLog(l))
+	}
+	return entries, total, nil
+}
+
+func (c *controller) Export(ctx context.Context, query *q.Query, format string, w io.Writer) error {
+	const op = "audit controller: export audit logs"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	if err := checkAdmin(ctx); err != nil {
+		return err
+	}
+	encoder, err := audit.NewEncoder(format, w)
+	if err != nil {
+		return perror.Wrap(herrors.ErrParamInvalid, err.Error())
+	}
+	return c.auditLogMgr.Iterate(ctx, query, _exportBatch, func(auditLogs []*models.AuditLog) error {
+		for _, l := range auditLogs {
+			if err := encoder.Encode(l); err != nil {
+				return err
+			}
+		}
+		// stream the logs to the client batch by batch
+		if flusher, ok := w.(http.Flusher); ok {
+			flusher.Flush()
+		}
+		return nil
+	})
+}
+
+func checkAdmin(ctx context.Context) error {
+	currentUser, err := common.UserFromContext(ctx)
+	if err != nil {
+		return err
+	}
+	if !currentUser.IsAdmin() {
+		return perror.Wrap(herrors.ErrForbidden, "only admin can access audit logs")
+	}
+	return nil
+}
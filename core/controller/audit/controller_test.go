@@ -0,0 +1,97 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/lib/orm"
+	"github.com/horizoncd/horizon/lib/q"
+	"github.com/horizoncd/horizon/pkg/audit"
+	"github.com/horizoncd/horizon/pkg/audit/models"
+	userauth "github.com/horizoncd/horizon/pkg/authentication/user"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/param"
+	"github.com/horizoncd/horizon/pkg/param/managerparam"
+)
+
+func Test(t *testing.T) {
+	db, _ := orm.NewSqliteDB("")
+	if err := db.AutoMigrate(&models.AuditLog{}); err != nil {
+		panic(err)
+	}
+	// nolint
+	ctx := common.WithContext(context.Background(), &userauth.DefaultInfo{
+		Name: "Tony",
+		ID:   1,
+	})
+	// nolint
+	adminCtx := common.WithContext(context.Background(), &userauth.DefaultInfo{
+		Name:  "Jerry",
+		ID:    2,
+		Admin: true,
+	})
+	mgr := managerparam.InitManager(db)
+	c := NewController(&param.Param{Manager: mgr})
+
+	for _, l := range []*models.AuditLog{
+		{ReqID: "a", ActorType: models.ActorUser, ActorName: "Tony", Method: "POST",
+			Path: "/apis/core/v2/groups/1/applications", Resource: "groups",
+			Decision: models.DecisionAllow, StatusCode: 200, SourceIP: "10.0.0.1"},
+		{ReqID: "b", ActorType: models.ActorRobot, ActorName: "robot", TokenID: 3, Method: "DELETE",
+			Path: "/apis/core/v2/clusters/1", Resource: "clusters", ResourceName: "1",
+			Decision: models.DecisionDeny, Reason: "not permitted", StatusCode: 403},
+	} {
+		assert.Nil(t, mgr.AuditLogMgr.Create(ctx, l))
+	}
+
+	// only admin can access audit logs
+	_, _, err := c.List(ctx, q.New(nil))
+	assert.Equal(t, herrors.ErrForbidden, perror.Cause(err))
+	err = c.Export(ctx, q.New(nil), audit.FormatJSONLines, &bytes.Buffer{})
+	assert.Equal(t, herrors.ErrForbidden, perror.Cause(err))
+
+	entries, total, err := c.List(adminCtx, q.New(q.KeyWords{common.AuditQueryDecision: models.DecisionDeny}))
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1), total)
+	assert.Equal(t, "robot", entries[0].ActorName)
+	assert.Equal(t, "not permitted", entries[0].Reason)
+
+	buf := &bytes.Buffer{}
+	err = c.Export(adminCtx, q.New(nil), audit.FormatJSONLines, buf)
+	assert.Nil(t, err)
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	assert.Equal(t, 2, len(lines))
+	entry := &audit.Entry{}
+	assert.Nil(t, json.Unmarshal([]byte(lines[0]), entry))
+	assert.Equal(t, "a", entry.ReqID)
+
+	buf.Reset()
+	err = c.Export(adminCtx, q.New(q.KeyWords{common.AuditQueryActor: "robot"}), audit.FormatSyslog, buf)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, strings.Count(buf.String(), "\n"))
+	assert.True(t, strings.Contains(buf.String(), `reqID="b"`))
+
+	err = c.Export(adminCtx, q.New(nil), "csv", &bytes.Buffer{})
+	assert.Equal(t, herrors.ErrParamInvalid, perror.Cause(err))
+}
@@ -0,0 +1,174 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package autodeploy
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+
+	"github.com/horizoncd/horizon/core/common"
+	accessctl "github.com/horizoncd/horizon/core/controller/access"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	autodeploymanager "github.com/horizoncd/horizon/pkg/autodeploy/manager"
+	"github.com/horizoncd/horizon/pkg/autodeploy/models"
+	"github.com/horizoncd/horizon/pkg/cluster/code"
+	clustermanager "github.com/horizoncd/horizon/pkg/cluster/manager"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	freezeservice "github.com/horizoncd/horizon/pkg/freeze/service"
+	"github.com/horizoncd/horizon/pkg/param"
+	usermanager "github.com/horizoncd/horizon/pkg/user/manager"
+	"github.com/horizoncd/horizon/pkg/util/glob"
+	"github.com/horizoncd/horizon/pkg/util/wlog"
+)
+
+type Controller interface {
+	Get(ctx context.Context, clusterID uint) (*AutoDeploy, error)
+	// Update opts the cluster into auto deploy, or updates its auto deploy
+	Update(ctx context.Context, clusterID uint, r *UpdateAutoDeployRequest) (*AutoDeploy, error)
+	// Delete opts the cluster out of auto deploy
+	Delete(ctx context.Context, clusterID uint) error
+	// ReceivePushEvent builds and deploys the clusters opted into auto deploy which build from the pushed ref,
+	// only the clusters whose webhook secret verifies the push webhook are deployed
+	ReceivePushEvent(ctx context.Context, header http.Header, body []byte) (*PushEventResponse, error)
+}
+
+type controller struct {
+	autoDeployMgr autodeploymanager.Manager
+	clusterMgr    clustermanager.Manager
+	userMgr       usermanager.Manager
+	gitGetter     code.GitGetter
+	operator      ClusterOperator
+	reviewer      accessctl.Controller
+	freezeSvc     freezeservice.Service
+	// goFunc runs the deployments triggered by a push in the background
+	goFunc func(f func())
+}
+
+var _ Controller = (*controller)(nil)
+
+func NewController(param *param.Param, operator ClusterOperator, reviewer accessctl.Controller) Controller {
+	return &controller{
+		autoDeployMgr: param.AutoDeployMgr,
+		clusterMgr:    param.ClusterMgr,
+		userMgr:       param.UserManager,
+		gitGetter:     param.GitGetter,
+		operator:      operator,
+		reviewer:      reviewer,
+		freezeSvc:     param.FreezeSvc,
+		goFunc:        func(f func()) { go f() },
+	}
+}
+
+func (c *controller) Get(ctx context.Context, clusterID uint) (*AutoDeploy, error) {
+	const op = "auto deploy controller: get"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	a, err := c.autoDeployMgr.GetByClusterID(ctx, clusterID)
+	if err != nil {
+		return nil, err
+	}
+	return ofAutoDeployModel(a), nil
+}
+
+func (c *controller) Update(ctx context.Context, clusterID uint, r *UpdateAutoDeployRequest) (*AutoDeploy, error) {
+	const op = "auto deploy controller: update"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	currentUser, err := common.UserFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	cluster, err := c.clusterMgr.GetByID(ctx, clusterID)
+	if err != nil {
+		return nil, err
+	}
+	if cluster.GitURL == "" {
+		return nil, perror.Wrap(herrors.ErrParamInvalid, "auto deploy requires the cluster to have a git repo")
+	}
+	for _, g := range r.PathGlobs {
+		if err := glob.Validate(g); err != nil {
+			return nil, err
+		}
+	}
+	if r.TagPattern != "" {
+		if err := glob.Validate(r.TagPattern); err != nil {
+			return nil, err
+		}
+	}
+
+	// pipelineruns are created as the operator, only admins may make them created as someone else
+	operatorID := r.OperatorID
+	if operatorID == 0 {
+		operatorID = currentUser.GetID()
+	}
+	if operatorID != currentUser.GetID() {
+		if !currentUser.IsAdmin() {
+			return nil, perror.Wrap(herrors.ErrForbidden, "only admins can set the operator to other users")
+		}
+		if _, err := c.userMgr.GetUserByID(ctx, operatorID); err != nil {
+			return nil, err
+		}
+	}
+
+	webhookSecret := r.WebhookSecret
+	if webhookSecret == "" {
+		existing, err := c.autoDeployMgr.GetByClusterID(ctx, clusterID)
+		if err != nil {
+			if _, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); !ok {
+				return nil, err
+			}
+			if webhookSecret, err = genWebhookSecret(); err != nil {
+				return nil, err
+			}
+		} else {
+			webhookSecret = existing.WebhookSecret
+		}
+	}
+
+	a := &models.AutoDeploy{
+		ClusterID:     clusterID,
+		TagPattern:    r.TagPattern,
+		OperatorID:    operatorID,
+		WebhookSecret: webhookSecret,
+		CreatedBy:     currentUser.GetID(),
+		UpdatedBy:     currentUser.GetID(),
+	}
+	a.SetPathGlobs(r.PathGlobs)
+	a, err = c.autoDeployMgr.Upsert(ctx, a)
+	if err != nil {
+		return nil, err
+	}
+	return ofAutoDeployModel(a), nil
+}
+
+func (c *controller) Delete(ctx context.Context, clusterID uint) error {
+	const op = "auto deploy controller: delete"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	if _, err := c.autoDeployMgr.GetByClusterID(ctx, clusterID); err != nil {
+		return err
+	}
+	return c.autoDeployMgr.DeleteByClusterID(ctx, clusterID)
+}
+
+func genWebhookSecret() (string, error) {
+	bytes := make([]byte, 20)
+	if _, err := rand.Read(bytes); err != nil {
+		return "", perror.Wrap(herrors.ErrGenerateRandomID, err.Error())
+	}
+	return hex.EncodeToString(bytes), nil
+}
@@ -0,0 +1,264 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package autodeploy
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"path"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/horizoncd/horizon/core/common"
+	accessctl "github.com/horizoncd/horizon/core/controller/access"
+	clusterctl "github.com/horizoncd/horizon/core/controller/cluster"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/core/middleware/requestid"
+	userauth "github.com/horizoncd/horizon/pkg/authentication/user"
+	"github.com/horizoncd/horizon/pkg/autodeploy/models"
+	cmodels "github.com/horizoncd/horizon/pkg/cluster/models"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/git"
+	prmodels "github.com/horizoncd/horizon/pkg/pipelinerun/models"
+	"github.com/horizoncd/horizon/pkg/util/glob"
+	"github.com/horizoncd/horizon/pkg/util/log"
+	"github.com/horizoncd/horizon/pkg/util/wlog"
+	uuid "github.com/satori/go.uuid"
+)
+
+// ClusterOperator is the part of the cluster controller used to deploy clusters on pushes
+type ClusterOperator interface {
+	BuildDeploy(ctx context.Context, clusterID uint,
+		request *clusterctl.BuildDeployRequest) (*clusterctl.BuildDeployResponse, error)
+}
+
+const _shortCommitLength = 8
+
+// regexSCPLikeURL matches git urls like git@github.com:horizoncd/horizon.git
+var regexSCPLikeURL = regexp.MustCompile(`^([^@/:]+@)?([^/:]+):([^/].*)$`)
+
+func (c *controller) ReceivePushEvent(ctx context.Context, header http.Header,
+	body []byte) (*PushEventResponse, error) {
+	const op = "auto deploy controller: receive push event"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	event, err := c.gitGetter.ParsePushEvent(header, body)
+	if err != nil {
+		return nil, err
+	}
+	resp := &PushEventResponse{Clusters: make([]*PushEventCluster, 0)}
+	if event == nil {
+		return resp, nil
+	}
+	resp.RefType, resp.Ref, resp.Commit = event.RefType, event.Ref, event.Commit
+	if event.Commit == "" {
+		// nothing to build from a deleted ref
+		return resp, nil
+	}
+
+	// the payload is not trusted until it's verified by the secret of a cluster,
+	// the repo urls in it are only used to find the clusters whose secrets may verify it
+	clusters, err := c.clusterMgr.ListByGitURLs(ctx, gitURLCandidates(event.GitURLs))
+	if err != nil {
+		return nil, err
+	}
+	clusterIDs := make([]uint, 0, len(clusters))
+	for _, cluster := range clusters {
+		clusterIDs = append(clusterIDs, cluster.ID)
+	}
+	autoDeploys, err := c.autoDeployMgr.ListByClusterIDs(ctx, clusterIDs)
+	if err != nil {
+		return nil, err
+	}
+	autoDeployMap := make(map[uint]*models.AutoDeploy, len(autoDeploys))
+	for _, a := range autoDeploys {
+		autoDeployMap[a.ClusterID] = a
+	}
+
+	verified := false
+	for _, cluster := range clusters {
+		a, ok := autoDeployMap[cluster.ID]
+		if !ok {
+			continue
+		}
+		// a webhook is configured in the repo for each cluster, and it's verified by the secret of that cluster only
+		if err := c.gitGetter.VerifyWebhook(header, body, a.WebhookSecret); err != nil {
+			continue
+		}
+		verified = true
+		matched, message := match(cluster, a, event)
+		resp.Clusters = append(resp.Clusters, &PushEventCluster{
+			ClusterID: cluster.ID,
+			Triggered: matched,
+			Message:   message,
+		})
+		if !matched {
+			continue
+		}
+		// the webhook is responded before the clusters are built, as git servers wait for a short time
+		cluster, a := cluster, a
+		c.goFunc(func() {
+			// nolint
+			ctx := context.WithValue(context.Background(), requestid.HeaderXRequestID, uuid.NewV4().String())
+			c.deploy(ctx, cluster, a, event)
+		})
+	}
+	if !verified {
+		return nil, perror.Wrap(herrors.ErrSignatureInvalid, "the webhook is not verified by any cluster of the repo")
+	}
+	return resp, nil
+}
+
+// match tells whether the push deploys the cluster, and why if it does not
+func match(cluster *cmodels.Cluster, a *models.AutoDeploy, event *git.PushEvent) (bool, string) {
+	switch event.RefType {
+	case git.GitRefTypeBranch:
+		if cluster.GitRefType != git.GitRefTypeBranch || cluster.GitRef != event.Ref {
+			return false, fmt.Sprintf("cluster builds from %s %s", cluster.GitRefType, cluster.GitRef)
+		}
+	case git.GitRefTypeTag:
+		if cluster.GitRefType != git.GitRefTypeTag {
+			return false, fmt.Sprintf("cluster builds from %s %s", cluster.GitRefType, cluster.GitRef)
+		}
+		if a.TagPattern == "" && cluster.GitRef != event.Ref {
+			return false, fmt.Sprintf("cluster builds from tag %s", cluster.GitRef)
+		}
+		if a.TagPattern != "" && !glob.Match(a.TagPattern, event.Ref) {
+			return false, fmt.Sprintf("tag does not match %s", a.TagPattern)
+		}
+		// tags are filtered by the pattern only
+		return true, ""
+	default:
+		return false, fmt.Sprintf("unsupported ref type %s", event.RefType)
+	}
+
+	// files changed by too many commits are unknown, deploy the cluster in case it's changed
+	if !event.ChangedFilesComplete {
+		return true, ""
+	}
+	globs := a.GetPathGlobs()
+	for _, file := range event.ChangedFiles {
+		if underSubfolder(cluster.GitSubfolder, file) && (len(globs) == 0 || glob.MatchAny(globs, file)) {
+			return true, ""
+		}
+	}
+	return false, "no changed files of the cluster"
+}
+
+// underSubfolder tells whether the file is in the subfolder of the repo, the root of the repo contains all files
+func underSubfolder(subfolder, file string) bool {
+	subfolder = path.Clean("/" + subfolder)
+	if subfolder == "/" {
+		return true
+	}
+	return strings.HasPrefix(path.Clean("/"+file)+"/", subfolder+"/")
+}
+
+// gitURLCandidates lists the forms of the repo urls which clusters may build from,
+// e.g. with or without .git, and ssh urls like ssh://git@github.com/horizoncd/horizon.git
+func gitURLCandidates(gitURLs []string) []string {
+	var forms []string
+	for _, u := range gitURLs {
+		forms = append(forms, u)
+		if !strings.Contains(u, "://") {
+			if m := regexSCPLikeURL.FindStringSubmatch(u); m != nil {
+				forms = append(forms, fmt.Sprintf("ssh://%s%s/%s", m[1], m[2], m[3]))
+			}
+		}
+	}
+	candidates := make([]string, 0, 2*len(forms))
+	for _, u := range forms {
+		trimmed := strings.TrimSuffix(u, ".git")
+		candidates = append(candidates, trimmed, trimmed+".git")
+	}
+	return candidates
+}
+
+// deploy builds and deploys the cluster as the operator of its auto deploy, and records the result
+func (c *controller) deploy(ctx context.Context, cluster *cmodels.Cluster, a *models.AutoDeploy,
+	event *git.PushEvent) {
+	prID, err := c.buildDeploy(ctx, cluster, a, event)
+	message := ""
+	if err != nil {
+		log.Warningf(ctx, "failed to deploy cluster %d on push of %s %s, err: %v",
+			cluster.ID, event.RefType, event.Ref, err)
+		message = err.Error()
+	}
+	if err := c.autoDeployMgr.UpdateResultByID(ctx, a.ID, time.Now(), event.Commit, prID, message); err != nil {
+		log.Errorf(ctx, "failed to update result of auto deploy %d, err: %v", a.ID, err)
+	}
+}
+
+func (c *controller) buildDeploy(ctx context.Context, cluster *cmodels.Cluster, a *models.AutoDeploy,
+	event *git.PushEvent) (uint, error) {
+	user, err := c.userMgr.GetUserByID(ctx, a.OperatorID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get operator: %v", err)
+	}
+	ctx = common.WithContext(ctx, &userauth.DefaultInfo{
+		Name:     user.Name,
+		FullName: user.FullName,
+		ID:       user.ID,
+		Email:    user.Email,
+		Admin:    user.Admin,
+	})
+	ctx = clusterctl.WithPipelinerunTrigger(ctx, prmodels.TriggerPush)
+
+	api := accessctl.API{URL: fmt.Sprintf("/apis/core/v2/clusters/%d/builddeploy", cluster.ID),
+		Method: http.MethodPost}
+	results, err := c.reviewer.Review(ctx, []accessctl.API{api})
+	if err != nil {
+		return 0, err
+	}
+	if result := results[api.URL][api.Method]; result == nil || !result.Allowed {
+		reason := "not reviewed"
+		if result != nil {
+			reason = result.Reason
+		}
+		return 0, fmt.Errorf("forbidden: %s", reason)
+	}
+
+	if c.freezeSvc != nil {
+		window, until, err := c.freezeSvc.GetActiveWindow(ctx, cluster.ID)
+		if err != nil {
+			return 0, err
+		}
+		if window != nil {
+			return 0, fmt.Errorf("skipped, frozen by %s until %s", window.Name, until.Format(time.RFC3339))
+		}
+	}
+
+	commit := event.Commit
+	if len(commit) > _shortCommitLength {
+		commit = commit[:_shortCommitLength]
+	}
+	gitRequest := &clusterctl.BuildDeployRequestGit{}
+	if event.RefType == git.GitRefTypeTag {
+		gitRequest.Tag = event.Ref
+	} else {
+		gitRequest.Branch = event.Ref
+	}
+	resp, err := c.operator.BuildDeploy(ctx, cluster.ID, &clusterctl.BuildDeployRequest{
+		Title:       fmt.Sprintf("push to %s %s", event.RefType, event.Ref),
+		Description: fmt.Sprintf("commit %s pushed by %s", commit, event.Pusher),
+		Git:         gitRequest,
+	})
+	if err != nil {
+		return 0, err
+	}
+	return resp.PipelinerunID, nil
+}
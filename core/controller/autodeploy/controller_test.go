@@ -0,0 +1,259 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package autodeploy
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/horizoncd/horizon/core/common"
+	accessctl "github.com/horizoncd/horizon/core/controller/access"
+	clusterctl "github.com/horizoncd/horizon/core/controller/cluster"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/lib/orm"
+	codemock "github.com/horizoncd/horizon/mock/pkg/cluster/code"
+	userauth "github.com/horizoncd/horizon/pkg/authentication/user"
+	"github.com/horizoncd/horizon/pkg/autodeploy/models"
+	clustermodels "github.com/horizoncd/horizon/pkg/cluster/models"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/git"
+	"github.com/horizoncd/horizon/pkg/param"
+	"github.com/horizoncd/horizon/pkg/param/managerparam"
+	prmodels "github.com/horizoncd/horizon/pkg/pipelinerun/models"
+	regionmodels "github.com/horizoncd/horizon/pkg/region/models"
+	usermodels "github.com/horizoncd/horizon/pkg/user/models"
+)
+
+// fakeOperator records the refs built and the trigger they are built with
+type fakeOperator struct {
+	clusters []uint
+	refs     []string
+	triggers []string
+}
+
+func (o *fakeOperator) BuildDeploy(ctx context.Context, clusterID uint,
+	r *clusterctl.BuildDeployRequest) (*clusterctl.BuildDeployResponse, error) {
+	o.clusters = append(o.clusters, clusterID)
+	o.refs = append(o.refs, r.Git.Branch+r.Git.Tag)
+	o.triggers = append(o.triggers, clusterctl.PipelinerunTriggerFromContext(ctx))
+	return &clusterctl.BuildDeployResponse{PipelinerunID: uint(len(o.clusters))}, nil
+}
+
+// fakeReviewer denies building the forbidden clusters
+type fakeReviewer struct {
+	forbidden uint
+}
+
+func (r *fakeReviewer) Review(_ context.Context,
+	apis []accessctl.API) (map[string]map[string]*accessctl.ReviewResult, error) {
+	results := make(map[string]map[string]*accessctl.ReviewResult)
+	for _, api := range apis {
+		allowed := api.URL != fmt.Sprintf("/apis/core/v2/clusters/%d/builddeploy", r.forbidden)
+		results[api.URL] = map[string]*accessctl.ReviewResult{
+			api.Method: {Allowed: allowed, Reason: "not a member"},
+		}
+	}
+	return results, nil
+}
+
+func Test(t *testing.T) {
+	db, _ := orm.NewSqliteDB("")
+	if err := db.AutoMigrate(&models.AutoDeploy{}, &clustermodels.Cluster{}, &regionmodels.Region{},
+		&usermodels.User{}); err != nil {
+		panic(err)
+	}
+	mockCtl := gomock.NewController(t)
+	gitGetter := codemock.NewMockGitGetter(mockCtl)
+	operator := &fakeOperator{}
+	parameter := &param.Param{Manager: managerparam.InitManager(db), GitGetter: gitGetter}
+	c := NewController(parameter, operator, &fakeReviewer{forbidden: 4}).(*controller)
+	c.goFunc = func(f func()) { f() }
+
+	// nolint
+	ctx := common.WithContext(context.Background(), &userauth.DefaultInfo{
+		Name: "Tony",
+		ID:   1,
+	})
+	db.Save(&regionmodels.Region{Name: "autodeploy-hz"})
+	db.Save(&usermodels.User{Name: "Tony"})
+	newCluster := func(name, gitURL, subfolder, refType, ref string) *clustermodels.Cluster {
+		cluster := &clustermodels.Cluster{ApplicationID: 1, Name: name, RegionName: "autodeploy-hz",
+			GitURL: gitURL, GitSubfolder: subfolder, GitRefType: refType, GitRef: ref}
+		db.Save(cluster)
+		return cluster
+	}
+	gitURL := "ssh://git@github.com/horizoncd/horizon.git"
+	api := newCluster("autodeploy-api", gitURL, "/services/api", git.GitRefTypeBranch, "main")
+	web := newCluster("autodeploy-web", gitURL, "", git.GitRefTypeBranch, "main")
+	release := newCluster("autodeploy-release", gitURL, "", git.GitRefTypeTag, "v1.0.0")
+	forbidden := newCluster("autodeploy-forbidden", gitURL, "", git.GitRefTypeBranch, "main")
+	optedOut := newCluster("autodeploy-opted-out", gitURL, "", git.GitRefTypeBranch, "main")
+	otherTeam := newCluster("autodeploy-other-team", gitURL, "", git.GitRefTypeBranch, "main")
+	image := newCluster("autodeploy-image", "", "", "", "")
+	assert.Equal(t, uint(4), forbidden.ID)
+
+	// invalid requests
+	_, err := c.Update(ctx, image.ID, &UpdateAutoDeployRequest{})
+	assert.Equal(t, herrors.ErrParamInvalid, perror.Cause(err))
+	_, err = c.Update(ctx, web.ID, &UpdateAutoDeployRequest{PathGlobs: []string{"web/["}})
+	assert.Equal(t, herrors.ErrParamInvalid, perror.Cause(err))
+	_, err = c.Update(ctx, web.ID, &UpdateAutoDeployRequest{OperatorID: 2})
+	assert.Equal(t, herrors.ErrForbidden, perror.Cause(err))
+
+	// a random secret is generated for each cluster, and kept when it's not specified
+	a, err := c.Update(ctx, otherTeam.ID, &UpdateAutoDeployRequest{})
+	assert.Nil(t, err)
+	otherTeamSecret := a.WebhookSecret
+	assert.Equal(t, 40, len(otherTeamSecret))
+	a, err = c.Update(ctx, otherTeam.ID, &UpdateAutoDeployRequest{TagPattern: "v*"})
+	assert.Nil(t, err)
+	assert.Equal(t, otherTeamSecret, a.WebhookSecret)
+	_, err = c.Update(ctx, otherTeam.ID, &UpdateAutoDeployRequest{})
+	assert.Nil(t, err)
+
+	// the clusters of a team share a webhook in the repo
+	teamSecret := "team-secret"
+	for _, cluster := range []*clustermodels.Cluster{api, forbidden} {
+		a, err := c.Update(ctx, cluster.ID, &UpdateAutoDeployRequest{WebhookSecret: teamSecret})
+		assert.Nil(t, err)
+		assert.Equal(t, uint(1), a.OperatorID)
+		assert.Equal(t, teamSecret, a.WebhookSecret)
+	}
+	_, err = c.Update(ctx, web.ID, &UpdateAutoDeployRequest{PathGlobs: []string{"**/*.go"}, WebhookSecret: teamSecret})
+	assert.Nil(t, err)
+	a, err = c.Update(ctx, web.ID, &UpdateAutoDeployRequest{PathGlobs: []string{"web/**", "go.mod"}})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"web/**", "go.mod"}, a.PathGlobs)
+	assert.Equal(t, teamSecret, a.WebhookSecret)
+	_, err = c.Update(ctx, release.ID, &UpdateAutoDeployRequest{TagPattern: "v*", WebhookSecret: teamSecret})
+	assert.Nil(t, err)
+	_, err = c.Update(ctx, optedOut.ID, &UpdateAutoDeployRequest{})
+	assert.Nil(t, err)
+	assert.Nil(t, c.Delete(ctx, optedOut.ID))
+	_, err = c.Get(ctx, optedOut.ID)
+	_, ok := perror.Cause(err).(*herrors.HorizonErrNotFound)
+	assert.True(t, ok)
+
+	gitGetter.EXPECT().VerifyWebhook(gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes().
+		DoAndReturn(func(header http.Header, _ []byte, secret string) error {
+			if header.Get("X-Gitlab-Token") != secret {
+				return herrors.ErrSignatureInvalid
+			}
+			return nil
+		})
+	receiveWithSecret := func(event *git.PushEvent, secret string) (*PushEventResponse, error) {
+		gitGetter.EXPECT().ParsePushEvent(gomock.Any(), gomock.Any()).Return(event, nil)
+		return c.ReceivePushEvent(ctx, http.Header{"X-Gitlab-Token": []string{secret}}, []byte("{}"))
+	}
+	receive := func(event *git.PushEvent) *PushEventResponse {
+		resp, err := receiveWithSecret(event, teamSecret)
+		assert.Nil(t, err)
+		return resp
+	}
+	triggered := func(resp *PushEventResponse) []uint {
+		ids := make([]uint, 0)
+		for _, cluster := range resp.Clusters {
+			if cluster.Triggered {
+				ids = append(ids, cluster.ClusterID)
+			}
+		}
+		return ids
+	}
+
+	// the web cluster is not changed, and the forbidden cluster is triggered but fails to build
+	resp := receive(&git.PushEvent{
+		GitURLs:              []string{"git@github.com:horizoncd/horizon.git", "https://github.com/horizoncd/horizon"},
+		RefType:              git.GitRefTypeBranch,
+		Ref:                  "main",
+		Commit:               "0123456789abcdef",
+		Pusher:               "tony",
+		ChangedFiles:         []string{"services/api/main.go", "README.md"},
+		ChangedFilesComplete: true,
+	})
+	assert.Equal(t, 4, len(resp.Clusters))
+	assert.Equal(t, []uint{api.ID, forbidden.ID}, triggered(resp))
+	assert.Equal(t, []uint{api.ID}, operator.clusters)
+	assert.Equal(t, []string{prmodels.TriggerPush}, operator.triggers)
+	a, err = c.Get(ctx, api.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, "0123456789abcdef", a.LastCommit)
+	assert.Equal(t, uint(1), a.LastPipelinerunID)
+	assert.Equal(t, "", a.LastMessage)
+	a, err = c.Get(ctx, forbidden.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, "forbidden: not a member", a.LastMessage)
+
+	// all the clusters of the branch are deployed if the changed files are unknown
+	resp = receive(&git.PushEvent{
+		GitURLs: []string{"git@github.com:horizoncd/horizon.git"},
+		RefType: git.GitRefTypeBranch,
+		Ref:     "main",
+		Commit:  "1123456789abcdef",
+	})
+	assert.Equal(t, []uint{api.ID, web.ID, forbidden.ID}, triggered(resp))
+
+	// tags are matched by the pattern
+	resp = receive(&git.PushEvent{
+		GitURLs: []string{"ssh://git@github.com/horizoncd/horizon.git"},
+		RefType: git.GitRefTypeTag,
+		Ref:     "v1.1.0",
+		Commit:  "2123456789abcdef",
+	})
+	assert.Equal(t, []uint{release.ID}, triggered(resp))
+	assert.Equal(t, "v1.1.0", operator.refs[len(operator.refs)-1])
+
+	// deleted refs and other repos deploy nothing
+	resp = receive(&git.PushEvent{
+		GitURLs: []string{"git@github.com:horizoncd/horizon.git"},
+		RefType: git.GitRefTypeBranch,
+		Ref:     "main",
+	})
+	assert.Equal(t, 0, len(resp.Clusters))
+	_, err = receiveWithSecret(&git.PushEvent{
+		GitURLs: []string{"git@github.com:horizoncd/other.git"},
+		RefType: git.GitRefTypeBranch,
+		Ref:     "main",
+		Commit:  "3123456789abcdef",
+	}, teamSecret)
+	assert.Equal(t, herrors.ErrSignatureInvalid, perror.Cause(err))
+
+	// a push forged with the secret of a team deploys nothing of other teams
+	mainPush := &git.PushEvent{
+		GitURLs: []string{"git@github.com:horizoncd/horizon.git"},
+		RefType: git.GitRefTypeBranch,
+		Ref:     "main",
+		Commit:  "4123456789abcdef",
+	}
+	_, err = receiveWithSecret(mainPush, "forged")
+	assert.Equal(t, herrors.ErrSignatureInvalid, perror.Cause(err))
+	resp, err = receiveWithSecret(mainPush, otherTeamSecret)
+	assert.Nil(t, err)
+	assert.Equal(t, []uint{otherTeam.ID}, triggered(resp))
+	assert.Equal(t, otherTeam.ID, operator.clusters[len(operator.clusters)-1])
+}
+
+func TestUnderSubfolder(t *testing.T) {
+	assert.True(t, underSubfolder("", "main.go"))
+	assert.True(t, underSubfolder("/", "main.go"))
+	assert.True(t, underSubfolder("/services/api", "services/api/main.go"))
+	assert.True(t, underSubfolder("services/api/", "services/api"))
+	assert.False(t, underSubfolder("/services/api", "services/api-gateway/main.go"))
+	assert.False(t, underSubfolder("/services/api", "main.go"))
+}
@@ -0,0 +1,84 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package autodeploy
+
+import (
+	"time"
+
+	"github.com/horizoncd/horizon/pkg/autodeploy/models"
+)
+
+type UpdateAutoDeployRequest struct {
+	// PathGlobs filters pushes to branches by the changed files, relative to the root of the repo,
+	// e.g. services/api/**, all pushes deploy the cluster if it's empty
+	PathGlobs []string `json:"pathGlobs"`
+	// TagPattern is the glob of the tags deploying the cluster if it builds from a tag,
+	// e.g. v*, only pushes to the tag of the cluster deploy it if it's empty
+	TagPattern string `json:"tagPattern"`
+	// OperatorID is the user whom the pipelineruns are created as, defaults to the current user,
+	// only admins can set it to other users
+	OperatorID uint `json:"operatorID"`
+	// WebhookSecret is the secret of the push webhook in the repo, a random one is generated
+	// when the cluster opts into auto deploy, and it's kept if empty when updating
+	WebhookSecret string `json:"webhookSecret"`
+}
+
+type AutoDeploy struct {
+	UpdateAutoDeployRequest
+	ClusterID         uint       `json:"clusterID"`
+	LastTriggeredAt   *time.Time `json:"lastTriggeredAt,omitempty"`
+	LastCommit        string     `json:"lastCommit,omitempty"`
+	LastPipelinerunID uint       `json:"lastPipelinerunID,omitempty"`
+	LastMessage       string     `json:"lastMessage,omitempty"`
+	CreatedAt         time.Time  `json:"createdAt"`
+	UpdatedAt         time.Time  `json:"updatedAt"`
+	CreatedBy         uint       `json:"createdBy"`
+	UpdatedBy         uint       `json:"updatedBy"`
+}
+
+func ofAutoDeployModel(a *models.AutoDeploy) *AutoDeploy {
+	return &AutoDeploy{
+		UpdateAutoDeployRequest: UpdateAutoDeployRequest{
+			PathGlobs:     a.GetPathGlobs(),
+			TagPattern:    a.TagPattern,
+			OperatorID:    a.OperatorID,
+			WebhookSecret: a.WebhookSecret,
+		},
+		ClusterID:         a.ClusterID,
+		LastTriggeredAt:   a.LastTriggeredAt,
+		LastCommit:        a.LastCommit,
+		LastPipelinerunID: a.LastPipelinerunID,
+		LastMessage:       a.LastMessage,
+		CreatedAt:         a.CreatedAt,
+		UpdatedAt:         a.UpdatedAt,
+		CreatedBy:         a.CreatedBy,
+		UpdatedBy:         a.UpdatedBy,
+	}
+}
+
+type PushEventResponse struct {
+	RefType string `json:"refType"`
+	Ref     string `json:"ref"`
+	Commit  string `json:"commit"`
+	// Clusters are the clusters opted into auto deploy which build from the pushed repo
+	Clusters []*PushEventCluster `json:"clusters"`
+}
+
+type PushEventCluster struct {
+	ClusterID uint `json:"clusterID"`
+	// Triggered tells whether the cluster is being built and deployed, Message tells why if it's not
+	Triggered bool   `json:"triggered"`
+	Message   string `json:"message,omitempty"`
+}
@@ -0,0 +1,268 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package batchjob
+
+import (
+	"context"
+
+	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/lib/q"
+	applicationmanager "github.com/horizoncd/horizon/pkg/application/manager"
+	batchjobmanager "github.com/horizoncd/horizon/pkg/batchjob/manager"
+	"github.com/horizoncd/horizon/pkg/batchjob/models"
+	clustermanager "github.com/horizoncd/horizon/pkg/cluster/manager"
+	"github.com/horizoncd/horizon/pkg/config/batchjob"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	groupmanager "github.com/horizoncd/horizon/pkg/group/manager"
+	"github.com/horizoncd/horizon/pkg/param"
+	tagmanager "github.com/horizoncd/horizon/pkg/tag/manager"
+	tagmodels "github.com/horizoncd/horizon/pkg/tag/models"
+	tagutil "github.com/horizoncd/horizon/pkg/util/tag"
+	"github.com/horizoncd/horizon/pkg/util/wlog"
+)
+
+type Controller interface {
+	// CreateBatchJob selects the clusters in the subtree of the group and starts operating them
+	CreateBatchJob(ctx context.Context, groupID uint, r *CreateBatchJobRequest) (*BatchJob, error)
+	GetBatchJob(ctx context.Context, id uint) (*BatchJob, error)
+	ListBatchJobs(ctx context.Context, groupID uint, query *q.Query) ([]*BatchJob, int64, error)
+	// CancelBatchJob skips the clusters not started yet, operations already started are not affected
+	CancelBatchJob(ctx context.Context, id uint) (*BatchJob, error)
+}
+
+type controller struct {
+	config         *batchjob.Config
+	batchJobMgr    batchjobmanager.Manager
+	groupMgr       groupmanager.Manager
+	applicationMgr applicationmanager.Manager
+	clusterMgr     clustermanager.Manager
+}
+
+var _ Controller = (*controller)(nil)
+
+func NewController(config *batchjob.Config, param *param.Param) Controller {
+	return &controller{
+		config:         config,
+		batchJobMgr:    param.BatchJobMgr,
+		groupMgr:       param.GroupManager,
+		applicationMgr: param.ApplicationManager,
+		clusterMgr:     param.ClusterMgr,
+	}
+}
+
+func (c *controller) CreateBatchJob(ctx context.Context, groupID uint,
+	r *CreateBatchJobRequest) (*BatchJob, error) {
+	const op = "batch job controller: create"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	currentUser, err := common.UserFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := c.groupMgr.GetByID(ctx, groupID); err != nil {
+		return nil, err
+	}
+
+	// 1. validate request
+	if err := c.validate(r); err != nil {
+		return nil, err
+	}
+
+	// 2. select clusters
+	clusters, err := c.selectClusters(ctx, groupID, r.Selector)
+	if err != nil {
+		return nil, err
+	}
+
+	// 3. create job
+	job := &models.BatchJob{
+		GroupID:       groupID,
+		Title:         r.Title,
+		Operation:     r.Operation,
+		Concurrency:   r.Concurrency,
+		FailureBudget: r.FailureBudget,
+		Status:        models.StatusRunning,
+		CreatedBy:     currentUser.GetID(),
+		UpdatedBy:     currentUser.GetID(),
+	}
+	job.SetSelector(r.Selector)
+	job.SetParams(r.Params)
+	job, err = c.batchJobMgr.Create(ctx, job, clusters)
+	if err != nil {
+		return nil, err
+	}
+	return ofBatchJobModel(job, clusters), nil
+}
+
+func (c *controller) validate(r *CreateBatchJobRequest) error {
+	switch r.Operation {
+	case models.OperationDeploy, models.OperationRestart:
+	case models.OperationUpgrade:
+		if r.Params.TemplateRelease == "" || r.Selector.Template == "" {
+			return perror.Wrap(herrors.ErrParamInvalid,
+				"upgrade requires the template release to upgrade to and the template of the clusters")
+		}
+	case models.OperationUpdateTags:
+		if len(r.Params.Tags) == 0 {
+			return perror.Wrap(herrors.ErrParamInvalid, "updatetags requires tags")
+		}
+		tags := make([]*tagmodels.Tag, 0, len(r.Params.Tags))
+		for _, tag := range r.Params.Tags {
+			tags = append(tags, &tagmodels.Tag{
+				ResourceType: common.ResourceCluster,
+				Key:          tag.Key,
+				Value:        tag.Value,
+			})
+		}
+		if err := tagmanager.ValidateUpsert(tags); err != nil {
+			return err
+		}
+	default:
+		return perror.Wrapf(herrors.ErrParamInvalid, "unsupported operation %s", r.Operation)
+	}
+
+	if r.Concurrency < 0 || r.FailureBudget < 0 {
+		return perror.Wrap(herrors.ErrParamInvalid, "concurrency and failure budget can not be negative")
+	}
+	if r.Concurrency == 0 {
+		r.Concurrency = 1
+	}
+	if c.config.MaxConcurrency > 0 && r.Concurrency > c.config.MaxConcurrency {
+		return perror.Wrapf(herrors.ErrParamInvalid,
+			"concurrency can not be greater than %d", c.config.MaxConcurrency)
+	}
+	return nil
+}
+
+// selectClusters lists the clusters of the applications in the group subtree which match the selector
+func (c *controller) selectClusters(ctx context.Context, groupID uint,
+	selector models.Selector) ([]*models.BatchJobCluster, error) {
+	groups, err := c.groupMgr.GetSubGroupsByGroupIDs(ctx, []uint{groupID})
+	if err != nil {
+		return nil, err
+	}
+	groupIDs := make([]uint, 0, len(groups))
+	for _, group := range groups {
+		groupIDs = append(groupIDs, group.ID)
+	}
+	applications, err := c.applicationMgr.GetByGroupIDs(ctx, groupIDs)
+	if err != nil {
+		return nil, err
+	}
+	if len(applications) == 0 {
+		return nil, perror.Wrapf(herrors.ErrParamInvalid, "no applications under group %d", groupID)
+	}
+	applicationIDs := make([]uint, 0, len(applications))
+	for _, application := range applications {
+		applicationIDs = append(applicationIDs, application.ID)
+	}
+
+	keywords := q.KeyWords{common.ClusterQueryByApplications: applicationIDs}
+	if selector.Template != "" {
+		keywords[common.ClusterQueryByTemplate] = selector.Template
+	}
+	if selector.TemplateRelease != "" {
+		keywords[common.ClusterQueryByRelease] = selector.TemplateRelease
+	}
+	if selector.Environment != "" {
+		keywords[common.ClusterQueryEnvironment] = selector.Environment
+	}
+	if selector.TagSelector != "" {
+		tagSelectors, err := tagutil.ParseTagSelector(selector.TagSelector)
+		if err != nil {
+			return nil, perror.Wrapf(herrors.ErrParamInvalid, "invalid tag selector: %v", err)
+		}
+		keywords[common.ClusterQueryTagSelector] = tagSelectors
+	}
+	query := q.New(keywords)
+	query.WithoutPagination = true
+	_, clusters, err := c.clusterMgr.List(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	if len(clusters) == 0 {
+		return nil, perror.Wrap(herrors.ErrParamInvalid, "no clusters are selected")
+	}
+
+	ret := make([]*models.BatchJobCluster, 0, len(clusters))
+	for _, cluster := range clusters {
+		ret = append(ret, &models.BatchJobCluster{
+			ClusterID: cluster.ID,
+			Status:    models.ClusterStatusPending,
+		})
+	}
+	return ret, nil
+}
+
+func (c *controller) GetBatchJob(ctx context.Context, id uint) (*BatchJob, error) {
+	const op = "batch job controller: get"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	job, err := c.batchJobMgr.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	clusters, err := c.batchJobMgr.ListClustersByJobID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return ofBatchJobModel(job, clusters), nil
+}
+
+func (c *controller) ListBatchJobs(ctx context.Context, groupID uint,
+	query *q.Query) ([]*BatchJob, int64, error) {
+	const op = "batch job controller: list"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	jobs, total, err := c.batchJobMgr.ListByGroupID(ctx, groupID, query)
+	if err != nil {
+		return nil, 0, err
+	}
+	result := make([]*BatchJob, 0, len(jobs))
+	for _, job := range jobs {
+		result = append(result, ofBatchJobModel(job, nil))
+	}
+	return result, total, nil
+}
+
+func (c *controller) CancelBatchJob(ctx context.Context, id uint) (*BatchJob, error) {
+	const op = "batch job controller: cancel"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	currentUser, err := common.UserFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	job, err := c.batchJobMgr.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	job.Status = models.StatusCancelled
+	job.Message = "cancelled manually"
+	job.UpdatedBy = currentUser.GetID()
+	updated, err := c.batchJobMgr.UpdateStatusByID(ctx, id, []string{models.StatusRunning}, job)
+	if err != nil {
+		return nil, err
+	}
+	if !updated {
+		return nil, perror.Wrapf(herrors.ErrBatchJobStatusInvalid, "batch job %d is not running", id)
+	}
+	if err := c.batchJobMgr.SkipPendingClusters(ctx, id, "batch job is cancelled"); err != nil {
+		return nil, err
+	}
+	return c.GetBatchJob(ctx, id)
+}
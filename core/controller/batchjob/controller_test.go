@@ -0,0 +1,142 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package batchjob
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/lib/orm"
+	"github.com/horizoncd/horizon/lib/q"
+	applicationmodels "github.com/horizoncd/horizon/pkg/application/models"
+	userauth "github.com/horizoncd/horizon/pkg/authentication/user"
+	"github.com/horizoncd/horizon/pkg/batchjob/models"
+	clustermodels "github.com/horizoncd/horizon/pkg/cluster/models"
+	"github.com/horizoncd/horizon/pkg/config/batchjob"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	groupmodels "github.com/horizoncd/horizon/pkg/group/models"
+	"github.com/horizoncd/horizon/pkg/param"
+	"github.com/horizoncd/horizon/pkg/param/managerparam"
+	regionmodels "github.com/horizoncd/horizon/pkg/region/models"
+	tagmodels "github.com/horizoncd/horizon/pkg/tag/models"
+)
+
+func Test(t *testing.T) {
+	db, _ := orm.NewSqliteDB("")
+	if err := db.AutoMigrate(&models.BatchJob{}, &models.BatchJobCluster{}, &groupmodels.Group{},
+		&applicationmodels.Application{}, &clustermodels.Cluster{}, &regionmodels.Region{},
+		&tagmodels.Tag{}); err != nil {
+		panic(err)
+	}
+	// nolint
+	ctx := common.WithContext(context.Background(), &userauth.DefaultInfo{
+		Name: "Tony",
+		ID:   1,
+	})
+	c := NewController(&batchjob.Config{MaxConcurrency: 5}, &param.Param{Manager: managerparam.InitManager(db)})
+
+	// group tree: root -> sub, and another root
+	root := &groupmodels.Group{Name: "root", Path: "root"}
+	db.Save(root)
+	db.Model(root).Update("traversal_ids", fmt.Sprintf("%d", root.ID))
+	sub := &groupmodels.Group{Name: "sub", Path: "sub", ParentID: root.ID}
+	db.Save(sub)
+	db.Model(sub).Update("traversal_ids", fmt.Sprintf("%d,%d", root.ID, sub.ID))
+	other := &groupmodels.Group{Name: "other", Path: "other"}
+	db.Save(other)
+	db.Model(other).Update("traversal_ids", fmt.Sprintf("%d", other.ID))
+
+	db.Save(&regionmodels.Region{Name: "batch-hz"})
+	rootApp := &applicationmodels.Application{Name: "root-app", GroupID: root.ID}
+	db.Save(rootApp)
+	subApp := &applicationmodels.Application{Name: "sub-app", GroupID: sub.ID}
+	db.Save(subApp)
+	otherApp := &applicationmodels.Application{Name: "other-app", GroupID: other.ID}
+	db.Save(otherApp)
+	var clusterIDs []uint
+	for _, cluster := range []*clustermodels.Cluster{
+		{ApplicationID: rootApp.ID, Name: "root-online", EnvironmentName: "online",
+			Template: "javaapp", TemplateRelease: "v1"},
+		{ApplicationID: subApp.ID, Name: "sub-online", EnvironmentName: "online",
+			Template: "javaapp", TemplateRelease: "v2"},
+		{ApplicationID: subApp.ID, Name: "sub-test", EnvironmentName: "test",
+			Template: "javaapp", TemplateRelease: "v1"},
+		{ApplicationID: otherApp.ID, Name: "other-online", EnvironmentName: "online",
+			Template: "javaapp", TemplateRelease: "v1"},
+	} {
+		cluster.RegionName = "batch-hz"
+		db.Save(cluster)
+		clusterIDs = append(clusterIDs, cluster.ID)
+	}
+	db.Save(&tagmodels.Tag{ResourceType: common.ResourceCluster, ResourceID: clusterIDs[1],
+		Key: "team", Value: "a"})
+
+	// invalid requests
+	for _, r := range []*CreateBatchJobRequest{
+		{Operation: "delete"},
+		{Operation: models.OperationUpgrade, Params: models.Params{TemplateRelease: "v2"}},
+		{Operation: models.OperationUpdateTags},
+		{Operation: models.OperationRestart, Concurrency: 10},
+		{Operation: models.OperationRestart, Selector: models.Selector{TagSelector: "team in"}},
+		{Operation: models.OperationRestart, Selector: models.Selector{Environment: "perf"}},
+	} {
+		_, err := c.CreateBatchJob(ctx, root.ID, r)
+		assert.Equal(t, herrors.ErrParamInvalid, perror.Cause(err))
+	}
+
+	// clusters are selected from the subtree
+	job, err := c.CreateBatchJob(ctx, root.ID, &CreateBatchJobRequest{
+		Title:     "restart",
+		Operation: models.OperationRestart,
+		Selector:  models.Selector{Environment: "online"},
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, models.StatusRunning, job.Status)
+	assert.Equal(t, 1, job.Concurrency)
+	assert.Equal(t, 2, job.Progress.Total)
+	assert.Equal(t, 2, job.Progress.Pending)
+
+	for selector, clusterID := range map[models.Selector]uint{
+		{Template: "javaapp", TemplateRelease: "v1"}: clusterIDs[2],
+		{Template: "javaapp", TagSelector: "team=a"}: clusterIDs[1],
+	} {
+		j, err := c.CreateBatchJob(ctx, sub.ID, &CreateBatchJobRequest{
+			Operation: models.OperationUpgrade,
+			Selector:  selector,
+			Params:    models.Params{TemplateRelease: "v3"},
+		})
+		assert.Nil(t, err)
+		assert.Equal(t, 1, len(j.Clusters))
+		assert.Equal(t, clusterID, j.Clusters[0].ClusterID)
+	}
+
+	// cancel
+	job, err = c.CancelBatchJob(ctx, job.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, models.StatusCancelled, job.Status)
+	assert.Equal(t, 2, job.Progress.Skipped)
+	_, err = c.CancelBatchJob(ctx, job.ID)
+	assert.Equal(t, herrors.ErrBatchJobStatusInvalid, perror.Cause(err))
+
+	jobs, total, err := c.ListBatchJobs(ctx, root.ID, &q.Query{PageNumber: 1, PageSize: 10})
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1), total)
+	assert.Equal(t, job.ID, jobs[0].ID)
+}
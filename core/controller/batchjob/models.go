@@ -0,0 +1,113 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package batchjob
+
+import (
+	"time"
+
+	"github.com/horizoncd/horizon/pkg/batchjob/models"
+)
+
+type CreateBatchJobRequest struct {
+	Title     string          `json:"title"`
+	Operation string          `json:"operation"`
+	Selector  models.Selector `json:"selector"`
+	Params    models.Params   `json:"params"`
+	// Concurrency defaults to 1 and is capped by the server
+	Concurrency int `json:"concurrency"`
+	// FailureBudget is how many clusters may fail before the rest are skipped
+	FailureBudget int `json:"failureBudget"`
+}
+
+type BatchJob struct {
+	CreateBatchJobRequest
+	ID        uint       `json:"id"`
+	GroupID   uint       `json:"groupID"`
+	Status    string     `json:"status"`
+	Message   string     `json:"message"`
+	Progress  *Progress  `json:"progress,omitempty"`
+	Clusters  []*Cluster `json:"clusters,omitempty"`
+	CreatedAt time.Time  `json:"createdAt"`
+	UpdatedAt time.Time  `json:"updatedAt"`
+	CreatedBy uint       `json:"createdBy"`
+	UpdatedBy uint       `json:"updatedBy"`
+}
+
+// Progress counts the clusters of the job by status
+type Progress struct {
+	Total     int `json:"total"`
+	Pending   int `json:"pending"`
+	Running   int `json:"running"`
+	Succeeded int `json:"succeeded"`
+	Failed    int `json:"failed"`
+	Skipped   int `json:"skipped"`
+}
+
+type Cluster struct {
+	ClusterID     uint       `json:"clusterID"`
+	PipelinerunID uint       `json:"pipelinerunID"`
+	Status        string     `json:"status"`
+	Message       string     `json:"message"`
+	StartedAt     *time.Time `json:"startedAt,omitempty"`
+	FinishedAt    *time.Time `json:"finishedAt,omitempty"`
+}
+
+func ofBatchJobModel(job *models.BatchJob, clusters []*models.BatchJobCluster) *BatchJob {
+	j := &BatchJob{
+		CreateBatchJobRequest: CreateBatchJobRequest{
+			Title:         job.Title,
+			Operation:     job.Operation,
+			Selector:      job.GetSelector(),
+			Params:        job.GetParams(),
+			Concurrency:   job.Concurrency,
+			FailureBudget: job.FailureBudget,
+		},
+		ID:        job.ID,
+		GroupID:   job.GroupID,
+		Status:    job.Status,
+		Message:   job.Message,
+		CreatedAt: job.CreatedAt,
+		UpdatedAt: job.UpdatedAt,
+		CreatedBy: job.CreatedBy,
+		UpdatedBy: job.UpdatedBy,
+	}
+	if clusters == nil {
+		return j
+	}
+	j.Progress = &Progress{Total: len(clusters)}
+	for _, cluster := range clusters {
+		switch cluster.Status {
+		case models.ClusterStatusPending:
+			j.Progress.Pending++
+		case models.ClusterStatusRunning:
+			j.Progress.Running++
+		case models.ClusterStatusSucceeded:
+			j.Progress.Succeeded++
+		case models.ClusterStatusFailed:
+			j.Progress.Failed++
+		case models.ClusterStatusSkipped:
+			j.Progress.Skipped++
+		}
+		j.Clusters = append(j.Clusters, &Cluster{
+			ClusterID:     cluster.ClusterID,
+			PipelinerunID: cluster.PipelinerunID,
+			Status:        cluster.Status,
+			Message:       cluster.Message,
+			StartedAt:     cluster.StartedAt,
+			FinishedAt:    cluster.FinishedAt,
+		})
+	}
+	return j
+}
@@ -0,0 +1,108 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package buildcontext
+
+import (
+	"context"
+
+	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	buildcontextmanager "github.com/horizoncd/horizon/pkg/buildcontext/manager"
+	"github.com/horizoncd/horizon/pkg/buildcontext/models"
+	clustermanager "github.com/horizoncd/horizon/pkg/cluster/manager"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/param"
+	"github.com/horizoncd/horizon/pkg/util/glob"
+	"github.com/horizoncd/horizon/pkg/util/wlog"
+)
+
+type Controller interface {
+	Get(ctx context.Context, clusterID uint) (*BuildContext, error)
+	// Update declares the build context of the cluster, then builds of the cluster are skipped
+	// if none of the files it depends on changed since its last build
+	Update(ctx context.Context, clusterID uint, r *UpdateBuildContextRequest) (*BuildContext, error)
+	// Delete makes the cluster built on every builddeploy again
+	Delete(ctx context.Context, clusterID uint) error
+}
+
+type controller struct {
+	buildContextMgr buildcontextmanager.Manager
+	clusterMgr      clustermanager.Manager
+}
+
+var _ Controller = (*controller)(nil)
+
+func NewController(param *param.Param) Controller {
+	return &controller{
+		buildContextMgr: param.BuildContextMgr,
+		clusterMgr:      param.ClusterMgr,
+	}
+}
+
+func (c *controller) Get(ctx context.Context, clusterID uint) (*BuildContext, error) {
+	const op = "build context controller: get"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	b, err := c.buildContextMgr.GetByClusterID(ctx, clusterID)
+	if err != nil {
+		return nil, err
+	}
+	return ofBuildContextModel(b), nil
+}
+
+func (c *controller) Update(ctx context.Context, clusterID uint,
+	r *UpdateBuildContextRequest) (*BuildContext, error) {
+	const op = "build context controller: update"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	currentUser, err := common.UserFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	cluster, err := c.clusterMgr.GetByID(ctx, clusterID)
+	if err != nil {
+		return nil, err
+	}
+	if cluster.GitURL == "" {
+		return nil, perror.Wrap(herrors.ErrParamInvalid, "build context requires the cluster to have a git repo")
+	}
+	for _, g := range r.PathGlobs {
+		if err := glob.Validate(g); err != nil {
+			return nil, err
+		}
+	}
+
+	b := &models.BuildContext{
+		ClusterID: clusterID,
+		CreatedBy: currentUser.GetID(),
+		UpdatedBy: currentUser.GetID(),
+	}
+	b.SetPathGlobs(r.PathGlobs)
+	b, err = c.buildContextMgr.Upsert(ctx, b)
+	if err != nil {
+		return nil, err
+	}
+	return ofBuildContextModel(b), nil
+}
+
+func (c *controller) Delete(ctx context.Context, clusterID uint) error {
+	const op = "build context controller: delete"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	if _, err := c.buildContextMgr.GetByClusterID(ctx, clusterID); err != nil {
+		return err
+	}
+	return c.buildContextMgr.DeleteByClusterID(ctx, clusterID)
+}
@@ -0,0 +1,79 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package buildcontext
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/lib/orm"
+	userauth "github.com/horizoncd/horizon/pkg/authentication/user"
+	"github.com/horizoncd/horizon/pkg/buildcontext/models"
+	clustermodels "github.com/horizoncd/horizon/pkg/cluster/models"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/param"
+	"github.com/horizoncd/horizon/pkg/param/managerparam"
+	regionmodels "github.com/horizoncd/horizon/pkg/region/models"
+)
+
+func Test(t *testing.T) {
+	db, _ := orm.NewSqliteDB("")
+	if err := db.AutoMigrate(&models.BuildContext{}, &clustermodels.Cluster{},
+		&regionmodels.Region{}); err != nil {
+		panic(err)
+	}
+	c := NewController(&param.Param{Manager: managerparam.InitManager(db)})
+
+	// nolint
+	ctx := common.WithContext(context.Background(), &userauth.DefaultInfo{
+		Name: "Tony",
+		ID:   1,
+	})
+	db.Save(&regionmodels.Region{Name: "buildcontext-hz"})
+	monorepo := &clustermodels.Cluster{ApplicationID: 1, Name: "buildcontext-api",
+		RegionName: "buildcontext-hz", GitURL: "ssh://git@github.com/horizoncd/horizon.git"}
+	db.Save(monorepo)
+	image := &clustermodels.Cluster{ApplicationID: 1, Name: "buildcontext-image", RegionName: "buildcontext-hz"}
+	db.Save(image)
+
+	_, err := c.Get(ctx, monorepo.ID)
+	_, ok := perror.Cause(err).(*herrors.HorizonErrNotFound)
+	assert.True(t, ok)
+
+	_, err = c.Update(ctx, image.ID, &UpdateBuildContextRequest{})
+	assert.Equal(t, herrors.ErrParamInvalid, perror.Cause(err))
+	_, err = c.Update(ctx, monorepo.ID, &UpdateBuildContextRequest{PathGlobs: []string{"libs/[a-"}})
+	assert.Equal(t, herrors.ErrParamInvalid, perror.Cause(err))
+
+	b, err := c.Update(ctx, monorepo.ID, &UpdateBuildContextRequest{
+		PathGlobs: []string{"services/api/**", "libs/**"},
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"services/api/**", "libs/**"}, b.PathGlobs)
+	assert.Equal(t, uint(1), b.CreatedBy)
+
+	b, err = c.Get(ctx, monorepo.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, monorepo.ID, b.ClusterID)
+
+	assert.Nil(t, c.Delete(ctx, monorepo.ID))
+	err = c.Delete(ctx, monorepo.ID)
+	_, ok = perror.Cause(err).(*herrors.HorizonErrNotFound)
+	assert.True(t, ok)
+}
@@ -0,0 +1,49 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package buildcontext
+
+import (
+	"time"
+
+	"github.com/horizoncd/horizon/pkg/buildcontext/models"
+)
+
+type UpdateBuildContextRequest struct {
+	// PathGlobs are the globs of the files the build depends on, relative to the root of the repo,
+	// e.g. services/api/** and libs/**, the files under the subfolder of the cluster if it's empty
+	PathGlobs []string `json:"pathGlobs"`
+}
+
+type BuildContext struct {
+	UpdateBuildContextRequest
+	ClusterID uint      `json:"clusterID"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+	CreatedBy uint      `json:"createdBy"`
+	UpdatedBy uint      `json:"updatedBy"`
+}
+
+func ofBuildContextModel(b *models.BuildContext) *BuildContext {
+	return &BuildContext{
+		UpdateBuildContextRequest: UpdateBuildContextRequest{
+			PathGlobs: b.GetPathGlobs(),
+		},
+		ClusterID: b.ClusterID,
+		CreatedAt: b.CreatedAt,
+		UpdatedAt: b.UpdatedAt,
+		CreatedBy: b.CreatedBy,
+		UpdatedBy: b.UpdatedBy,
+	}
+}
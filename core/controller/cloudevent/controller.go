@@ -16,22 +16,27 @@ package cloudevent
 
 import (
 	"context"
+	"encoding/json"
 	"strings"
 
 	herrors "github.com/horizoncd/horizon/core/errors"
 	applicationmanager "github.com/horizoncd/horizon/pkg/application/manager"
+	"github.com/horizoncd/horizon/pkg/cluster/ci"
 	"github.com/horizoncd/horizon/pkg/cluster/gitrepo"
 	clustermanager "github.com/horizoncd/horizon/pkg/cluster/manager"
-	"github.com/horizoncd/horizon/pkg/cluster/tekton/collector"
-	"github.com/horizoncd/horizon/pkg/cluster/tekton/factory"
 	"github.com/horizoncd/horizon/pkg/cluster/tekton/metrics"
 	perror "github.com/horizoncd/horizon/pkg/errors"
+	eventmanager "github.com/horizoncd/horizon/pkg/event/manager"
+	eventmodels "github.com/horizoncd/horizon/pkg/event/models"
 	"github.com/horizoncd/horizon/pkg/param"
 	prmanager "github.com/horizoncd/horizon/pkg/pipelinerun/manager"
 	prmodels "github.com/horizoncd/horizon/pkg/pipelinerun/models"
 	pipelinemanager "github.com/horizoncd/horizon/pkg/pipelinerun/pipeline/manager"
+	prprovenancemanager "github.com/horizoncd/horizon/pkg/pipelinerun/provenance/manager"
+	prreportmanager "github.com/horizoncd/horizon/pkg/pipelinerun/report/manager"
 	"github.com/horizoncd/horizon/pkg/server/global"
 	trmanager "github.com/horizoncd/horizon/pkg/templaterelease/manager"
+	tokenservice "github.com/horizoncd/horizon/pkg/token/service"
 	usermanager "github.com/horizoncd/horizon/pkg/user/manager"
 	"github.com/horizoncd/horizon/pkg/util/log"
 	"github.com/horizoncd/horizon/pkg/util/wlog"
@@ -41,29 +46,40 @@ import (
 
 type Controller interface {
 	CloudEvent(ctx context.Context, wpr *WrappedPipelineRun) error
+	// ExternalCallback records the run or the result reported by an external pipeline,
+	// the jwt token of the pipelinerun is expected in the context
+	ExternalCallback(ctx context.Context, callback *ci.ExternalCallback) error
 }
 
 type controller struct {
-	tektonFty          factory.Factory
+	ciFty              ci.Factory
+	tokenSvc           tokenservice.Service
 	pipelinerunMgr     prmanager.Manager
 	pipelineMgr        pipelinemanager.Manager
+	reportMgr          prreportmanager.Manager
+	provenanceMgr      prprovenancemanager.Manager
 	clusterMgr         clustermanager.Manager
 	clusterGitRepo     gitrepo.ClusterGitRepo
 	templateReleaseMgr trmanager.Manager
 	applicationMgr     applicationmanager.Manager
 	userMgr            usermanager.Manager
+	eventMgr           eventmanager.Manager
 }
 
-func NewController(tektonFty factory.Factory, parameter *param.Param) Controller {
+func NewController(ciFty ci.Factory, parameter *param.Param) Controller {
 	return &controller{
-		tektonFty:          tektonFty,
+		ciFty:              ciFty,
+		tokenSvc:           parameter.TokenSvc,
 		pipelinerunMgr:     parameter.PipelinerunMgr,
 		pipelineMgr:        parameter.PipelineMgr,
+		reportMgr:          parameter.PipelinerunReportMgr,
+		provenanceMgr:      parameter.PipelinerunProvenanceMgr,
 		clusterMgr:         parameter.ClusterMgr,
 		clusterGitRepo:     parameter.ClusterGitRepo,
 		templateReleaseMgr: parameter.TemplateReleaseManager,
 		applicationMgr:     parameter.ApplicationManager,
 		userMgr:            parameter.UserManager,
+		eventMgr:           parameter.EventManager,
 	}
 }
 
@@ -71,24 +87,25 @@ func (c *controller) CloudEvent(ctx context.Context, wpr *WrappedPipelineRun) (e
 	const op = "cloudEvent controller: cloudEvent"
 	defer wlog.Start(ctx, op).StopPrint()
 
-	horizonMetaData, err := c.getHorizonMetaData(ctx, wpr)
+	eventID := wpr.PipelineRun.Labels[common.TektonTriggersEventIDKey]
+	pipelinerun, err := c.pipelinerunMgr.GetByCIEventID(ctx, eventID)
+	if err != nil {
+		return err
+	}
+	horizonMetaData, err := c.getHorizonMetaData(ctx, pipelinerun)
 	if err != nil {
 		return err
 	}
 	log.Debugf(ctx, "got cloudEvent of pipelineRun %v, event id: %v",
 		horizonMetaData.PipelinerunID, horizonMetaData.EventID)
 
-	environment := horizonMetaData.Environment
-	pipelinerunID := horizonMetaData.PipelinerunID
-
 	// 1. collect log & pipelinerun object
-	tektonCollector, err := c.tektonFty.GetTektonCollector(environment)
+	engine, err := c.ciFty.GetEngine(horizonMetaData.Environment)
 	if err != nil {
 		return err
 	}
-
-	var result *collector.CollectResult
-	if result, err = tektonCollector.Collect(ctx, wpr.PipelineRun, horizonMetaData); err != nil {
+	result, err := engine.Complete(ctx, &ci.Callback{PipelineRun: wpr.PipelineRun}, horizonMetaData)
+	if err != nil {
 		if _, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); ok {
 			log.Warningf(ctx, "received pipelineRun: %v is not found when collect", wpr.PipelineRun.Name)
 			return nil
@@ -96,40 +113,104 @@ func (c *controller) CloudEvent(ctx context.Context, wpr *WrappedPipelineRun) (e
 		return err
 	}
 
-	log.Debugf(ctx, "pipelineRun %v status: %v, started at %v, finished at %v",
-		pipelinerunID, result.Result, result.StartTime, result.CompletionTime)
-
-	// 2. update pipelinerun in db
-	if err := c.pipelinerunMgr.UpdateResultByID(ctx, pipelinerunID, &prmodels.Result{
-		S3Bucket:   result.Bucket,
-		LogObject:  result.LogObject,
-		PrObject:   result.PrObject,
-		Result:     result.Result,
-		StartedAt:  &result.StartTime.Time,
-		FinishedAt: &result.CompletionTime.Time,
-	}); err != nil {
+	// todo remove codes below in the future
+	err = c.handleJibBuild(ctx, result.Pipeline, horizonMetaData)
+	if err != nil {
 		return err
 	}
 
-	// format Pipeline results
-	pipelineResult := metrics.FormatPipelineResults(wpr.PipelineRun)
+	return c.complete(ctx, result, horizonMetaData)
+}
+
+func (c *controller) ExternalCallback(ctx context.Context, callback *ci.ExternalCallback) (err error) {
+	const op = "cloudEvent controller: external callback"
+	defer wlog.Start(ctx, op).StopPrint()
 
-	// todo remove codes below in the future
-	err = c.handleJibBuild(ctx, pipelineResult, horizonMetaData)
+	// auth jwt token
+	jwtTokenString, err := common.JWTTokenStringFromContext(ctx)
 	if err != nil {
-		return err
+		return perror.Wrapf(herrors.ErrTokenInvalid, "%v", err.Error())
+	}
+	claims, err := c.tokenSvc.ParseJWTToken(jwtTokenString)
+	if err != nil {
+		return perror.Wrapf(herrors.ErrTokenInvalid, "%v", err.Error())
+	}
+	if claims.PipelinerunID == nil || *claims.PipelinerunID != callback.PipelinerunID {
+		return perror.Wrapf(herrors.ErrForbidden,
+			"no permission to report pipelinerun %v", callback.PipelinerunID)
 	}
 
-	// 4. observe metrics
-	metrics.Observe(pipelineResult, horizonMetaData)
+	pipelinerun, err := c.pipelinerunMgr.GetByID(ctx, callback.PipelinerunID)
+	if err != nil {
+		return err
+	}
+	// the pipeline may have deployed the cluster by the internal deploy api before it completes,
+	// so the status is not created anymore, only the finish time tells the result is recorded
+	if pipelinerun.FinishedAt != nil {
+		log.Warningf(ctx, "pipelinerun %v already finished with %v", pipelinerun.ID, pipelinerun.Status)
+		return nil
+	}
+	// the run id is used to stop the pipeline
+	if callback.RunID != "" && callback.RunID != pipelinerun.CIEventID {
+		if err := c.pipelinerunMgr.UpdateCIEventIDByID(ctx, pipelinerun.ID, callback.RunID); err != nil {
+			return err
+		}
+		pipelinerun.CIEventID = callback.RunID
+	}
+	if callback.Status == ci.ExternalStatusRunning {
+		return nil
+	}
 
-	// 5. insert pipeline into db
-	err = c.pipelineMgr.Create(ctx, pipelineResult, horizonMetaData)
+	horizonMetaData, err := c.getHorizonMetaData(ctx, pipelinerun)
+	if err != nil {
+		return err
+	}
+	engine, err := c.ciFty.GetEngine(horizonMetaData.Environment)
+	if err != nil {
+		return err
+	}
+	result, err := engine.Complete(ctx, &ci.Callback{External: callback}, horizonMetaData)
 	if err != nil {
 		return err
 	}
+	return c.complete(ctx, result, horizonMetaData)
+}
 
-	return nil
+// complete saves the result of the finished pipelinerun
+func (c *controller) complete(ctx context.Context, result *ci.Result,
+	horizonMetaData *global.HorizonMetaData) error {
+	log.Debugf(ctx, "pipelineRun %v status: %v, started at %v, finished at %v",
+		horizonMetaData.PipelinerunID, result.Result.Result, result.StartedAt, result.FinishedAt)
+
+	// 1. update pipelinerun in db
+	if err := c.pipelinerunMgr.UpdateResultByID(ctx, horizonMetaData.PipelinerunID, &result.Result); err != nil {
+		return err
+	}
+
+	if result.Result.Result == string(prmodels.StatusFailed) {
+		c.recordFailedEvent(ctx, horizonMetaData)
+	}
+
+	// 2. observe metrics
+	metrics.Observe(result.Pipeline, horizonMetaData)
+
+	// 3. insert summaries of reports into db
+	if err := c.reportMgr.Create(ctx, result.Reports); err != nil {
+		return err
+	}
+
+	// 4. save provenance signed by tekton chains
+	if result.Provenance != nil {
+		if err := c.provenanceMgr.Upsert(ctx, result.Provenance); err != nil {
+			return err
+		}
+	}
+
+	// 5. insert pipeline into db
+	if result.Pipeline == nil {
+		return nil
+	}
+	return c.pipelineMgr.Create(ctx, result.Pipeline, horizonMetaData)
 }
 
 // TODO remove this function in the future
@@ -177,13 +258,8 @@ func (c *controller) handleJibBuild(ctx context.Context, result *metrics.Pipelin
 }
 
 // getHorizonMetaData resolves info about this pipelinerun
-func (c *controller) getHorizonMetaData(ctx context.Context, wpr *WrappedPipelineRun) (
+func (c *controller) getHorizonMetaData(ctx context.Context, pipelinerun *prmodels.Pipelinerun) (
 	*global.HorizonMetaData, error) {
-	eventID := wpr.PipelineRun.Labels[common.TektonTriggersEventIDKey]
-	pipelinerun, err := c.pipelinerunMgr.GetByCIEventID(ctx, eventID)
-	if err != nil {
-		return nil, err
-	}
 	cluster, err := c.clusterMgr.GetByID(ctx, pipelinerun.ClusterID)
 	if err != nil {
 		return nil, err
@@ -207,6 +283,41 @@ func (c *controller) getHorizonMetaData(ctx context.Context, wpr *WrappedPipelin
 		PipelinerunID: pipelinerun.ID,
 		Region:        cluster.RegionName,
 		Template:      cluster.Template,
-		EventID:       eventID,
+		EventID:       pipelinerun.CIEventID,
 	}, nil
 }
+
+type failedExtra struct {
+	PipelinerunID uint   `json:"pipelinerunID"`
+	Action        string `json:"action"`
+	Environment   string `json:"environment"`
+}
+
+// recordFailedEvent notifies the failure of the pipelinerun, the pipelinerun is completed anyway
+func (c *controller) recordFailedEvent(ctx context.Context, horizonMetaData *global.HorizonMetaData) {
+	pr, err := c.pipelinerunMgr.GetByID(ctx, horizonMetaData.PipelinerunID)
+	if err != nil {
+		log.Warningf(ctx, "failed to get pipelinerun %v, err: %s", horizonMetaData.PipelinerunID, err.Error())
+		return
+	}
+	extraBytes, err := json.Marshal(failedExtra{
+		PipelinerunID: pr.ID,
+		Action:        pr.Action,
+		Environment:   horizonMetaData.Environment,
+	})
+	if err != nil {
+		log.Warningf(ctx, "failed to marshal event extra: %v", err.Error())
+	}
+	extra := string(extraBytes)
+	if _, err := c.eventMgr.CreateEvent(ctx, &eventmodels.Event{
+		EventSummary: eventmodels.EventSummary{
+			ResourceType: common.ResourceCluster,
+			EventType:    eventmodels.ClusterPipelinerunFailed,
+			ResourceID:   pr.ClusterID,
+			Extra:        &extra,
+		},
+		CreatedBy: pr.CreatedBy,
+	}); err != nil {
+		log.Warningf(ctx, "failed to create event, err: %s", err.Error())
+	}
+}
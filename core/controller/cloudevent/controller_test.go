@@ -16,12 +16,15 @@ package cloudevent
 
 import (
 	"context"
+	"encoding/base64"
 	"encoding/json"
 	"os"
+	"strconv"
 	"testing"
 	"time"
 
 	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
 	"github.com/horizoncd/horizon/lib/orm"
 	clustergitrepomock "github.com/horizoncd/horizon/mock/pkg/cluster/gitrepo"
 	tektonmock "github.com/horizoncd/horizon/mock/pkg/cluster/tekton"
@@ -30,15 +33,22 @@ import (
 	trmock "github.com/horizoncd/horizon/mock/pkg/templaterelease/manager"
 	appmodels "github.com/horizoncd/horizon/pkg/application/models"
 	userauth "github.com/horizoncd/horizon/pkg/authentication/user"
+	"github.com/horizoncd/horizon/pkg/cluster/ci"
 	"github.com/horizoncd/horizon/pkg/cluster/gitrepo"
 	clustermodels "github.com/horizoncd/horizon/pkg/cluster/models"
 	"github.com/horizoncd/horizon/pkg/cluster/tekton/collector"
+	ciconfig "github.com/horizoncd/horizon/pkg/config/ci"
+	tokenconfig "github.com/horizoncd/horizon/pkg/config/token"
+	perror "github.com/horizoncd/horizon/pkg/errors"
 	membermodels "github.com/horizoncd/horizon/pkg/member/models"
 	"github.com/horizoncd/horizon/pkg/param"
 	"github.com/horizoncd/horizon/pkg/param/managerparam"
 	prmodels "github.com/horizoncd/horizon/pkg/pipelinerun/models"
 	pipelinemodels "github.com/horizoncd/horizon/pkg/pipelinerun/pipeline/models"
+	provenancemodels "github.com/horizoncd/horizon/pkg/pipelinerun/provenance/models"
+	reportmodels "github.com/horizoncd/horizon/pkg/pipelinerun/report/models"
 	trmodels "github.com/horizoncd/horizon/pkg/templaterelease/models"
+	tokenservice "github.com/horizoncd/horizon/pkg/token/service"
 	usermodels "github.com/horizoncd/horizon/pkg/user/models"
 	"github.com/stretchr/testify/assert"
 	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
@@ -71,6 +81,12 @@ func TestMain(m *testing.M) {
 	if err := db.AutoMigrate(&pipelinemodels.Step{}); err != nil {
 		panic(err)
 	}
+	if err := db.AutoMigrate(&reportmodels.Report{}); err != nil {
+		panic(err)
+	}
+	if err := db.AutoMigrate(&provenancemodels.Provenance{}); err != nil {
+		panic(err)
+	}
 	if err := db.AutoMigrate(&appmodels.Application{}); err != nil {
 		panic(err)
 	}
@@ -228,6 +244,16 @@ func Test(t *testing.T) {
 	tektonFty.EXPECT().GetTekton(gomock.Any()).Return(tekton, nil).AnyTimes()
 	tektonFty.EXPECT().GetTektonCollector(gomock.Any()).Return(tektonCollector, nil).AnyTimes()
 
+	// the pipelinerun is signed by tekton chains after the cloud event is sent
+	signedPipelineRun := pipelineRun.DeepCopy()
+	signedPipelineRun.Annotations = map[string]string{
+		"chains.tekton.dev/signed": "true",
+		"chains.tekton.dev/payload-pipelinerun-1": base64.StdEncoding.EncodeToString([]byte(
+			`{"predicateType":"https://slsa.dev/provenance/v0.2","predicate":{"builder":{"id":"chains"}}}`)),
+		"chains.tekton.dev/signature-pipelinerun-1": "c2ln",
+	}
+	tekton.EXPECT().GetFinishedPipelineRun(ctx, gomock.Any()).Return(signedPipelineRun, nil)
+
 	tektonCollector.EXPECT().Collect(ctx, gomock.Any(), gomock.Any()).Return(&collector.CollectResult{
 		Bucket:    "bucket",
 		LogObject: "log-object",
@@ -245,6 +271,15 @@ func Test(t *testing.T) {
 				Time: tt,
 			}
 		}(),
+		Reports: []*reportmodels.Report{{
+			PipelinerunID: 1,
+			Task:          "build",
+			Name:          "junit-report",
+			Kind:          reportmodels.KindTest,
+			Format:        reportmodels.FormatJUnit,
+			Object:        "report-object",
+			Summary:       reportmodels.Summary{Tests: 3, Failures: 1},
+		}},
 	}, nil)
 
 	templateReleaseMgr := trmock.NewMockManager(mockCtl)
@@ -281,7 +316,8 @@ func Test(t *testing.T) {
 	p := &param.Param{Manager: manager}
 	p.ClusterGitRepo = clusterGitRepo
 	p.TemplateReleaseManager = templateReleaseMgr
-	c := NewController(tektonFty, p)
+	ciFty, _ := ci.NewFactory(nil, tektonFty)
+	c := NewController(ciFty, p)
 	err = c.CloudEvent(ctx, &WrappedPipelineRun{
 		PipelineRun: pipelineRun,
 	})
@@ -291,4 +327,111 @@ func Test(t *testing.T) {
 	assert.Nil(t, err)
 	assert.Equal(t, pr.Status, "ok")
 	assert.Equal(t, pr.LogObject, "log-object")
+
+	reports, err := manager.PipelinerunReportMgr.ListByPipelinerunID(ctx, pr.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(reports))
+	assert.Equal(t, 3, reports[0].Tests)
+	assert.Equal(t, "report-object", reports[0].Object)
+
+	provenance, err := manager.PipelinerunProvenanceMgr.GetByPipelinerunID(ctx, pr.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, "chains", provenance.BuilderID)
+	assert.Equal(t, "c2ln", provenance.Signature)
+}
+
+func TestExternalCallback(t *testing.T) {
+	mapper := ciconfig.Mapper{"external": {
+		Type: ci.TypeExternal,
+		External: &ciconfig.External{
+			Provider:     ci.ProviderGitLab,
+			Server:       "https://gitlab.com/api/v4",
+			Project:      "horizoncd/pipelines",
+			TriggerToken: "trigger-token",
+		},
+	}}
+	ciFty, err := ci.NewFactory(mapper, nil)
+	assert.Nil(t, err)
+	p := &param.Param{Manager: manager}
+	p.TokenSvc = tokenservice.NewService(manager, tokenconfig.Config{
+		JwtSigningKey:         "UZMccEsEgXA/phl3w/OK1gZU6lhKJIswZqsyfQEPqpc=",
+		CallbackTokenExpireIn: time.Hour,
+	})
+	c := NewController(ciFty, p)
+
+	application, _ := manager.ApplicationManager.Create(ctx, &appmodels.Application{
+		Name: "app-external",
+	}, map[string]string{})
+	user, _ := manager.UserManager.Create(ctx, &usermodels.User{
+		Name: "user-external",
+	})
+	cluster, _ := manager.ClusterMgr.Create(ctx, &clustermodels.Cluster{
+		ApplicationID:   application.ID,
+		Name:            "cluster-external",
+		EnvironmentName: "external",
+	}, nil, nil)
+	pipelinerunMgr := manager.PipelinerunMgr
+	pr, err := pipelinerunMgr.Create(ctx, &prmodels.Pipelinerun{
+		ClusterID: cluster.ID,
+		Action:    prmodels.ActionBuildDeploy,
+		Status:    string(prmodels.StatusCreated),
+		CreatedBy: user.ID,
+	})
+	assert.Nil(t, err)
+	token, err := p.TokenSvc.CreateJWTToken(strconv.Itoa(int(user.ID)), time.Hour,
+		tokenservice.WithPipelinerunID(pr.ID))
+	assert.Nil(t, err)
+	callbackCtx := common.WithContextJWTTokenString(ctx, token)
+
+	// the token only reports its own pipelinerun
+	err = c.ExternalCallback(callbackCtx, &ci.ExternalCallback{PipelinerunID: pr.ID + 1,
+		Status: ci.ExternalStatusRunning})
+	assert.Equal(t, herrors.ErrForbidden, perror.Cause(err))
+
+	err = c.ExternalCallback(callbackCtx, &ci.ExternalCallback{PipelinerunID: pr.ID, RunID: "1001",
+		Status: ci.ExternalStatusRunning})
+	assert.Nil(t, err)
+	pr, err = pipelinerunMgr.GetByID(ctx, pr.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, "1001", pr.CIEventID)
+	assert.Nil(t, pr.FinishedAt)
+
+	// the pipeline deploys the cluster by the internal deploy api before it reports the completion
+	for _, status := range []prmodels.PipelineStatus{prmodels.StatusCommitted,
+		prmodels.StatusMerged, prmodels.StatusOK} {
+		assert.Nil(t, pipelinerunMgr.UpdateStatusByID(ctx, pr.ID, status))
+	}
+	startedAt := time.Now().Add(-time.Minute).Truncate(time.Second)
+	finishedAt := time.Now().Truncate(time.Second)
+	err = c.ExternalCallback(callbackCtx, &ci.ExternalCallback{
+		PipelinerunID: pr.ID,
+		RunID:         "1001",
+		Status:        string(prmodels.StatusOK),
+		StartedAt:     &startedAt,
+		FinishedAt:    &finishedAt,
+		Tasks: []*ci.ExternalTask{{
+			Name:       "build",
+			Result:     string(prmodels.StatusOK),
+			StartedAt:  &startedAt,
+			FinishedAt: &finishedAt,
+		}},
+	})
+	assert.Nil(t, err)
+	pr, err = pipelinerunMgr.GetByID(ctx, pr.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, string(prmodels.StatusOK), pr.Status)
+	assert.True(t, startedAt.Equal(*pr.StartedAt))
+	assert.True(t, finishedAt.Equal(*pr.FinishedAt))
+	stats, total, err := manager.PipelineMgr.ListPipelineStats(ctx, application.Name, cluster.Name, 1, 10)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1), total)
+	assert.Equal(t, pr.ID, stats[0].PipelinerunID)
+
+	// the result is recorded only once
+	err = c.ExternalCallback(callbackCtx, &ci.ExternalCallback{PipelinerunID: pr.ID,
+		Status: string(prmodels.StatusFailed)})
+	assert.Nil(t, err)
+	pr, err = pipelinerunMgr.GetByID(ctx, pr.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, string(prmodels.StatusOK), pr.Status)
 }
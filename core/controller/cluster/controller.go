@@ -23,30 +23,45 @@ import (
 	appgitrepo "github.com/horizoncd/horizon/pkg/application/gitrepo"
 	appmanager "github.com/horizoncd/horizon/pkg/application/manager"
 	applicationservice "github.com/horizoncd/horizon/pkg/application/service"
+	buildcontextmanager "github.com/horizoncd/horizon/pkg/buildcontext/manager"
+	canarymanager "github.com/horizoncd/horizon/pkg/canary/manager"
 	"github.com/horizoncd/horizon/pkg/cd"
+	"github.com/horizoncd/horizon/pkg/cluster/ci"
 	"github.com/horizoncd/horizon/pkg/cluster/code"
 	"github.com/horizoncd/horizon/pkg/cluster/gitrepo"
 	clustermanager "github.com/horizoncd/horizon/pkg/cluster/manager"
 	registryfty "github.com/horizoncd/horizon/pkg/cluster/registry/factory"
 	"github.com/horizoncd/horizon/pkg/cluster/tekton/factory"
 	collectionmanager "github.com/horizoncd/horizon/pkg/collection/manager"
+	"github.com/horizoncd/horizon/pkg/config/buildcache"
+	"github.com/horizoncd/horizon/pkg/config/canary"
 	"github.com/horizoncd/horizon/pkg/config/grafana"
+	"github.com/horizoncd/horizon/pkg/config/pipelinereport"
+	"github.com/horizoncd/horizon/pkg/config/runqueue"
 	"github.com/horizoncd/horizon/pkg/config/template"
 	"github.com/horizoncd/horizon/pkg/config/token"
 	envmanager "github.com/horizoncd/horizon/pkg/environment/manager"
 	"github.com/horizoncd/horizon/pkg/environment/service"
 	environmentregionmapper "github.com/horizoncd/horizon/pkg/environmentregion/manager"
 	eventmanager "github.com/horizoncd/horizon/pkg/event/manager"
+	freezeservice "github.com/horizoncd/horizon/pkg/freeze/service"
 	grafanaservice "github.com/horizoncd/horizon/pkg/grafana"
 	groupmanager "github.com/horizoncd/horizon/pkg/group/manager"
 	groupsvc "github.com/horizoncd/horizon/pkg/group/service"
 	"github.com/horizoncd/horizon/pkg/member"
+	memberservice "github.com/horizoncd/horizon/pkg/member/service"
 	"github.com/horizoncd/horizon/pkg/param"
 	prmanager "github.com/horizoncd/horizon/pkg/pipelinerun/manager"
 	pipelinemanager "github.com/horizoncd/horizon/pkg/pipelinerun/pipeline/manager"
+	prreportmanager "github.com/horizoncd/horizon/pkg/pipelinerun/report/manager"
+	promotionmanager "github.com/horizoncd/horizon/pkg/promotion/manager"
+	"github.com/horizoncd/horizon/pkg/rbac/role"
 	regionmanager "github.com/horizoncd/horizon/pkg/region/manager"
+	runqueuemanager "github.com/horizoncd/horizon/pkg/runqueue/manager"
 	tagmanager "github.com/horizoncd/horizon/pkg/tag/manager"
+	"github.com/horizoncd/horizon/pkg/templaterelease/analysis"
 	trmanager "github.com/horizoncd/horizon/pkg/templaterelease/manager"
+	"github.com/horizoncd/horizon/pkg/templaterelease/migration"
 	"github.com/horizoncd/horizon/pkg/templaterelease/output"
 	templateschema "github.com/horizoncd/horizon/pkg/templaterelease/schema"
 	templateschematagmanager "github.com/horizoncd/horizon/pkg/templateschematag/manager"
@@ -76,6 +91,17 @@ type Controller interface {
 	Restart(ctx context.Context, clusterID uint) (*PipelinerunIDResponse, error)
 	Deploy(ctx context.Context, clusterID uint, request *DeployRequest) (*PipelinerunIDResponse, error)
 	Rollback(ctx context.Context, clusterID uint, request *RollbackRequest) (*PipelinerunIDResponse, error)
+	// PromoteCluster promotes image, template release and chosen values of the cluster
+	// in previous environment of the promotion path to this cluster, and deploys it
+	PromoteCluster(ctx context.Context, clusterID uint, request *PromoteRequest) (*PipelinerunIDResponse, error)
+	// ApprovePipelinerun approves a pending pipelinerun of a cluster in protected environment, and executes it
+	ApprovePipelinerun(ctx context.Context, pipelinerunID uint,
+		request *ReviewPipelinerunRequest) (*PipelinerunIDResponse, error)
+	// RejectPipelinerun rejects a pending pipelinerun of a cluster in protected environment
+	RejectPipelinerun(ctx context.Context, pipelinerunID uint, request *ReviewPipelinerunRequest) error
+	// DequeuePipelinerun executes the queued pipelinerun if its cluster is neither held by another pipelinerun
+	// nor frozen, it returns false if the pipelinerun is still queued
+	DequeuePipelinerun(ctx context.Context, pipelinerunID uint) (bool, error)
 
 	FreeCluster(ctx context.Context, clusterID uint) error
 
@@ -117,8 +143,16 @@ type Controller interface {
 	GetClusterPipelinerunStatus(ctx context.Context, clusterID uint) (*PipelinerunStatusResponse, error)
 	GetResourceTree(ctx context.Context, clusterID uint) (*GetResourceTreeResponse, error)
 	GetStep(ctx context.Context, clusterID uint) (resp *GetStepResponse, err error)
-	// Deprecated: for internal usage, v1 to v2
+	// Deprecated: for internal usage, v1 to v2.
+	// Template releases should be upgraded with ApplyTemplateMigration.
 	Upgrade(ctx context.Context, clusterID uint) error
+	// DryRunTemplateMigration shows how the values of clusters change and whether they are valid
+	// when the clusters are upgraded to the template release
+	DryRunTemplateMigration(ctx context.Context, releaseID uint,
+		r *TemplateMigrationRequest) (*TemplateMigrationResponse, error)
+	// ApplyTemplateMigration upgrades clusters to the template release with migrated values
+	ApplyTemplateMigration(ctx context.Context, releaseID uint,
+		r *TemplateMigrationRequest) (*TemplateMigrationResponse, error)
 	ToggleLikeStatus(ctx context.Context, clusterID uint, like *WhetherLike) (err error)
 }
 
@@ -142,10 +176,13 @@ type controller struct {
 	pipelinerunMgr        prmanager.Manager
 	pipelineMgr           pipelinemanager.Manager
 	tektonFty             factory.Factory
+	ciFty                 ci.Factory
 	registryFty           registryfty.RegistryGetter
 	userManager           usermanager.Manager
 	userSvc               usersvc.Service
 	memberManager         member.Manager
+	memberService         memberservice.Service
+	roleService           role.Service
 	groupManager          groupmanager.Manager
 	schemaTagManager      templateschematagmanager.Manager
 	tagMgr                tagmanager.Manager
@@ -157,6 +194,18 @@ type controller struct {
 	tokenConfig           token.Config
 	templateUpgradeMapper template.UpgradeMapper
 	collectionManager     collectionmanager.Manager
+	promotionPathMgr      promotionmanager.Manager
+	analysisGetter        analysis.Getter
+	migrationGetter       migration.Getter
+	canaryAnalysisMgr     canarymanager.Manager
+	canaryConfig          canary.Config
+	buildCacheConfig      buildcache.Config
+	prReportMgr           prreportmanager.Manager
+	reportConfig          pipelinereport.Config
+	runQueueMgr           runqueuemanager.Manager
+	runQueueConfig        runqueue.Config
+	buildContextMgr       buildcontextmanager.Manager
+	freezeSvc             freezeservice.Service
 }
 
 var _ Controller = (*controller)(nil)
@@ -182,10 +231,13 @@ func NewController(config *config.Config, param *param.Param) Controller {
 		pipelinerunMgr:        param.PipelinerunMgr,
 		pipelineMgr:           param.PipelineMgr,
 		tektonFty:             param.TektonFty,
+		ciFty:                 param.CIFty,
 		registryFty:           registryfty.Fty,
 		userManager:           param.UserManager,
 		userSvc:               param.UserSvc,
 		memberManager:         param.MemberManager,
+		memberService:         param.MemberService,
+		roleService:           param.RoleService,
 		groupManager:          param.GroupManager,
 		schemaTagManager:      param.ClusterSchemaTagMgr,
 		tagMgr:                param.TagManager,
@@ -197,5 +249,17 @@ func NewController(config *config.Config, param *param.Param) Controller {
 		tokenConfig:           config.TokenConfig,
 		templateUpgradeMapper: config.TemplateUpgradeMapper,
 		collectionManager:     param.CollectionMgr,
+		promotionPathMgr:      param.PromotionPathMgr,
+		analysisGetter:        param.AnalysisGetter,
+		migrationGetter:       param.MigrationGetter,
+		canaryAnalysisMgr:     param.CanaryAnalysisMgr,
+		canaryConfig:          config.CanaryConfig,
+		buildCacheConfig:      config.BuildCacheConfig,
+		prReportMgr:           param.PipelinerunReportMgr,
+		reportConfig:          config.PipelinerunReport,
+		runQueueMgr:           param.RunQueueMgr,
+		runQueueConfig:        config.RunQueueConfig,
+		buildContextMgr:       param.BuildContextMgr,
+		freezeSvc:             param.FreezeSvc,
 	}
 }
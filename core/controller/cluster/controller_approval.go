@@ -0,0 +1,326 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	userauth "github.com/horizoncd/horizon/pkg/authentication/user"
+	codemodels "github.com/horizoncd/horizon/pkg/cluster/code"
+	cmodels "github.com/horizoncd/horizon/pkg/cluster/models"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	eventmodels "github.com/horizoncd/horizon/pkg/event/models"
+	prmodels "github.com/horizoncd/horizon/pkg/pipelinerun/models"
+	"github.com/horizoncd/horizon/pkg/rbac/role"
+	"github.com/horizoncd/horizon/pkg/util/log"
+	"github.com/horizoncd/horizon/pkg/util/wlog"
+)
+
+// approvedPipelinerunKey is the context key of the approved or dequeued pipelinerun which is being executed
+type approvedPipelinerunKey struct{}
+
+type approvalExtra struct {
+	PipelinerunID uint   `json:"pipelinerunID"`
+	Action        string `json:"action"`
+	Comment       string `json:"comment,omitempty"`
+}
+
+func (c *controller) ApprovePipelinerun(ctx context.Context, pipelinerunID uint,
+	r *ReviewPipelinerunRequest) (_ *PipelinerunIDResponse, err error) {
+	const op = "cluster controller: approve pipelinerun"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	currentUser, err := common.UserFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	pr, cluster, err := c.getPendingPipelinerun(ctx, pipelinerunID)
+	if err != nil {
+		return nil, err
+	}
+	if pr.CreatedBy == currentUser.GetID() {
+		return nil, perror.Wrap(herrors.ErrForbidden, "pipelinerun cannot be approved by its creator")
+	}
+	if err := c.checkApprover(ctx, cluster, currentUser); err != nil {
+		return nil, err
+	}
+
+	if err := c.pipelinerunMgr.UpdateReviewByID(ctx, pr.ID, prmodels.StatusApproved,
+		currentUser.GetID(), r.Comment); err != nil {
+		return nil, err
+	}
+	c.recordApprovalEvent(ctx, eventmodels.ClusterApproved, pr, r.Comment)
+
+	resp, err := c.executeApprovedPipelinerun(context.WithValue(ctx, approvedPipelinerunKey{}, pr), cluster, pr)
+	if err != nil {
+		// the pipelinerun rejected by the run queue has been cancelled
+		if perror.Cause(err) == herrors.ErrClusterBusy {
+			return nil, err
+		}
+		if err := c.pipelinerunMgr.UpdateStatusByID(ctx, pr.ID, prmodels.StatusFailed); err != nil {
+			log.Errorf(ctx, "failed to update status of pipelinerun %d, err: %v", pr.ID, err)
+		}
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (c *controller) RejectPipelinerun(ctx context.Context, pipelinerunID uint,
+	r *ReviewPipelinerunRequest) (err error) {
+	const op = "cluster controller: reject pipelinerun"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	currentUser, err := common.UserFromContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	pr, cluster, err := c.getPendingPipelinerun(ctx, pipelinerunID)
+	if err != nil {
+		return err
+	}
+	// the creator can always withdraw its own request
+	if pr.CreatedBy != currentUser.GetID() {
+		if err := c.checkApprover(ctx, cluster, currentUser); err != nil {
+			return err
+		}
+	}
+
+	if err := c.pipelinerunMgr.UpdateReviewByID(ctx, pr.ID, prmodels.StatusRejected,
+		currentUser.GetID(), r.Comment); err != nil {
+		return err
+	}
+	c.recordApprovalEvent(ctx, eventmodels.ClusterRejected, pr, r.Comment)
+	return nil
+}
+
+func (c *controller) getPendingPipelinerun(ctx context.Context,
+	pipelinerunID uint) (*prmodels.Pipelinerun, *cmodels.Cluster, error) {
+	pr, err := c.pipelinerunMgr.GetByID(ctx, pipelinerunID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if pr.Status != string(prmodels.StatusPending) {
+		return nil, nil, perror.Wrapf(herrors.ErrPipelinerunNotPending,
+			"status of pipelinerun %d is %s", pr.ID, pr.Status)
+	}
+	cluster, err := c.clusterMgr.GetByID(ctx, pr.ClusterID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return pr, cluster, nil
+}
+
+// checkApprover checks whether the user is an approver of the cluster's environment,
+// that is an admin, a user in the approver list, or a member with the approver role or a higher one
+func (c *controller) checkApprover(ctx context.Context, cluster *cmodels.Cluster, user userauth.User) error {
+	if user.IsAdmin() {
+		return nil
+	}
+
+	env, err := c.envMgr.GetByName(ctx, cluster.EnvironmentName)
+	if err != nil {
+		return err
+	}
+	for _, approver := range env.ApproverList() {
+		if approver == user.GetName() || approver == user.GetEmail() {
+			return nil
+		}
+	}
+
+	if env.ApproverRole != "" {
+		member, err := c.memberService.GetMemberOfResource(ctx, common.ResourceCluster,
+			strconv.Itoa(int(cluster.ID)))
+		if err != nil {
+			return err
+		}
+		if member != nil {
+			result, err := c.roleService.RoleCompare(ctx, member.Role, env.ApproverRole)
+			if err != nil {
+				return err
+			}
+			if result == role.RoleEqual || result == role.RoleBigger {
+				return nil
+			}
+		}
+	}
+	return perror.Wrapf(herrors.ErrForbidden,
+		"user %s is not an approver of environment %s", user.GetName(), env.Name)
+}
+
+func (c *controller) executeApprovedPipelinerun(ctx context.Context, cluster *cmodels.Cluster,
+	pr *prmodels.Pipelinerun) (*PipelinerunIDResponse, error) {
+	switch pr.Action {
+	case prmodels.ActionBuildDeploy:
+		git := &BuildDeployRequestGit{}
+		switch pr.GitRefType {
+		case codemodels.GitRefTypeCommit:
+			git.Commit = pr.GitRef
+		case codemodels.GitRefTypeTag:
+			git.Tag = pr.GitRef
+		case codemodels.GitRefTypeBranch:
+			git.Branch = pr.GitRef
+		}
+		r := &BuildDeployRequest{}
+		if err := unmarshalRequest(pr, r); err != nil {
+			return nil, err
+		}
+		if pr.Request == "" {
+			// pipelineruns requested before the request was kept reuse the image as they did then
+			reuseBuild := pr.ReuseFrom != nil
+			r.ReuseBuild = &reuseBuild
+		}
+		r.Title, r.Description, r.Git = pr.Title, pr.Description, git
+		resp, err := c.BuildDeploy(ctx, cluster.ID, r)
+		if err != nil {
+			return nil, err
+		}
+		return &PipelinerunIDResponse{PipelinerunID: resp.PipelinerunID}, nil
+	case prmodels.ActionDeploy:
+		var imageTag string
+		if tag, err := name.NewTag(pr.ImageURL); err == nil {
+			imageTag = tag.TagStr()
+		}
+		return c.Deploy(ctx, cluster.ID, &DeployRequest{
+			Title:       pr.Title,
+			Description: pr.Description,
+			ImageTag:    imageTag,
+		})
+	case prmodels.ActionRestart:
+		return c.Restart(ctx, cluster.ID)
+	case prmodels.ActionRollback:
+		if pr.RollbackFrom == nil {
+			return nil, perror.Wrapf(herrors.ErrParamInvalid,
+				"pipelinerun %d does not specify which pipelinerun to rollback", pr.ID)
+		}
+		return c.Rollback(ctx, cluster.ID, &RollbackRequest{PipelinerunID: *pr.RollbackFrom})
+	case prmodels.ActionPromote:
+		if pr.PromoteFrom == nil || pr.Request == "" {
+			return nil, perror.Wrapf(herrors.ErrParamInvalid,
+				"pipelinerun %d does not specify which pipelinerun to promote", pr.ID)
+		}
+		r := &PromoteRequest{}
+		if err := unmarshalRequest(pr, r); err != nil {
+			return nil, err
+		}
+		// promote the pipelinerun chosen when requested even if the source cluster was deployed since
+		r.Title, r.Description, r.PipelinerunID = pr.Title, pr.Description, *pr.PromoteFrom
+		return c.PromoteCluster(ctx, cluster.ID, r)
+	default:
+		return nil, perror.Wrapf(herrors.ErrParamInvalid,
+			"action %s of pipelinerun %d does not support approval", pr.Action, pr.ID)
+	}
+}
+
+// marshalRequest marshals the request of an operation to be kept in its pipelinerun
+func marshalRequest(ctx context.Context, r interface{}) string {
+	bts, err := json.Marshal(r)
+	if err != nil {
+		log.Warningf(ctx, "failed to marshal request: %v", err)
+		return ""
+	}
+	return string(bts)
+}
+
+// unmarshalRequest unmarshals the request kept in the pipelinerun, if any
+func unmarshalRequest(pr *prmodels.Pipelinerun, r interface{}) error {
+	if pr.Request == "" {
+		return nil
+	}
+	if err := json.Unmarshal([]byte(pr.Request), r); err != nil {
+		return perror.Wrapf(herrors.ErrParamInvalid,
+			"failed to unmarshal request of pipelinerun %d: %v", pr.ID, err)
+	}
+	return nil
+}
+
+// createPipelinerun creates the pipelinerun of deploy, builddeploy, rollback, restart or promote.
+// If the cluster is in a protected environment, the pipelinerun is created as pending and
+// the caller should return without executing it, the operation will be executed again once
+// the pipelinerun is approved, and then the pending pipelinerun is reused.
+// Likewise, if the cluster is held by another pipelinerun, the pipelinerun is queued and
+// returned as pending according to the run queue policy of the cluster, the operation
+// will be executed again once the pipelinerun is dequeued.
+func (c *controller) createPipelinerun(ctx context.Context, cluster *cmodels.Cluster,
+	pr *prmodels.Pipelinerun) (_ *prmodels.Pipelinerun, pending bool, err error) {
+	if approved, ok := ctx.Value(approvedPipelinerunKey{}).(*prmodels.Pipelinerun); ok &&
+		approved.ClusterID == cluster.ID && approved.Action == pr.Action {
+		pr.Status = string(prmodels.StatusCreated)
+		if err := c.pipelinerunMgr.UpdateByID(ctx, approved.ID, pr); err != nil {
+			return nil, false, err
+		}
+		pr.ID = approved.ID
+		pr.CreatedAt = approved.CreatedAt
+		pr.CreatedBy = approved.CreatedBy
+		queued, err := c.admitPipelinerun(ctx, cluster, pr)
+		if err != nil {
+			return nil, false, err
+		}
+		return pr, queued, nil
+	}
+
+	pr.Trigger = PipelinerunTriggerFromContext(ctx)
+	env, err := c.envMgr.GetByName(ctx, cluster.EnvironmentName)
+	if err != nil {
+		return nil, false, err
+	}
+	if env.Protected {
+		pr.Status = string(prmodels.StatusPending)
+	}
+	prCreated, err := c.pipelinerunMgr.Create(ctx, pr)
+	if err != nil {
+		return nil, false, err
+	}
+	if env.Protected {
+		log.Infof(ctx, "environment %s is protected, pipelinerun %d is pending for approval",
+			env.Name, prCreated.ID)
+		c.recordApprovalEvent(ctx, eventmodels.ClusterPending, prCreated, "")
+		return prCreated, true, nil
+	}
+	queued, err := c.admitPipelinerun(ctx, cluster, prCreated)
+	if err != nil {
+		return nil, false, err
+	}
+	return prCreated, queued, nil
+}
+
+func (c *controller) recordApprovalEvent(ctx context.Context, eventType string,
+	pr *prmodels.Pipelinerun, comment string) {
+	extraBytes, err := json.Marshal(approvalExtra{
+		PipelinerunID: pr.ID,
+		Action:        pr.Action,
+		Comment:       comment,
+	})
+	if err != nil {
+		log.Warningf(ctx, "failed to marshal event extra: %v", err.Error())
+	}
+	extra := string(extraBytes)
+	if _, err := c.eventMgr.CreateEvent(ctx, &eventmodels.Event{
+		EventSummary: eventmodels.EventSummary{
+			ResourceType: common.ResourceCluster,
+			EventType:    eventType,
+			ResourceID:   pr.ClusterID,
+			Extra:        &extra,
+		},
+	}); err != nil {
+		log.Warningf(ctx, "failed to create event, err: %s", err.Error())
+	}
+}
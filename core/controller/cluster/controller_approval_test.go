@@ -0,0 +1,156 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	cdmock "github.com/horizoncd/horizon/mock/pkg/cd"
+	clustergitrepomock "github.com/horizoncd/horizon/mock/pkg/cluster/gitrepo"
+	appmodels "github.com/horizoncd/horizon/pkg/application/models"
+	userauth "github.com/horizoncd/horizon/pkg/authentication/user"
+	"github.com/horizoncd/horizon/pkg/cluster/gitrepo"
+	"github.com/horizoncd/horizon/pkg/cluster/models"
+	envmodels "github.com/horizoncd/horizon/pkg/environment/models"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	groupmodels "github.com/horizoncd/horizon/pkg/group/models"
+	prmodels "github.com/horizoncd/horizon/pkg/pipelinerun/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func testApprovePipelinerun(t *testing.T) {
+	applicationName := "app-approval"
+	mockCtl := gomock.NewController(t)
+	clusterGitRepo := clustergitrepomock.NewMockClusterGitRepo(mockCtl)
+	mockCd := cdmock.NewMockCD(mockCtl)
+
+	_, err := manager.EnvMgr.CreateEnvironment(ctx, &envmodels.Environment{
+		Name:      "approval-online",
+		Protected: true,
+		Approvers: "approver",
+	})
+	assert.Nil(t, err)
+	group, err := manager.GroupManager.Create(ctx, &groupmodels.Group{
+		Name: "approval-group",
+		Path: "approval-group",
+	})
+	assert.Nil(t, err)
+	application, err := manager.ApplicationManager.Create(ctx, &appmodels.Application{
+		GroupID: group.ID,
+		Name:    applicationName,
+	}, nil)
+	assert.Nil(t, err)
+	cluster, err := manager.ClusterMgr.Create(ctx, &models.Cluster{
+		ApplicationID:   application.ID,
+		Name:            "app-approval-online",
+		EnvironmentName: "approval-online",
+		RegionName:      "hz",
+		Template:        "javaapp",
+	}, nil, nil)
+	assert.Nil(t, err)
+
+	c = &controller{
+		clusterMgr:     manager.ClusterMgr,
+		clusterGitRepo: clusterGitRepo,
+		applicationMgr: manager.ApplicationManager,
+		envMgr:         manager.EnvMgr,
+		pipelinerunMgr: manager.PipelinerunMgr,
+		cd:             mockCd,
+		eventMgr:       manager.EventManager,
+	}
+
+	// restart in a protected environment only creates a pending pipelinerun
+	clusterGitRepo.EXPECT().GetConfigCommit(gomock.Any(), applicationName, cluster.Name).
+		Return(&gitrepo.ClusterCommit{Master: "master", Gitops: "gitops"}, nil).Times(3)
+	resp, err := c.Restart(ctx, cluster.ID)
+	assert.Nil(t, err)
+	pr, err := manager.PipelinerunMgr.GetByID(ctx, resp.PipelinerunID)
+	assert.Nil(t, err)
+	assert.Equal(t, string(prmodels.StatusPending), pr.Status)
+
+	// only approvers can approve
+	guestCtx := context.WithValue(ctx, common.UserContextKey(), &userauth.DefaultInfo{
+		Name: "guest",
+		ID:   uint(2),
+	})
+	_, err = c.ApprovePipelinerun(guestCtx, pr.ID, &ReviewPipelinerunRequest{})
+	assert.Equal(t, herrors.ErrForbidden, perror.Cause(err))
+
+	approverCtx := context.WithValue(ctx, common.UserContextKey(), &userauth.DefaultInfo{
+		Name: "approver",
+		ID:   uint(3),
+	})
+	clusterGitRepo.EXPECT().UpdateRestartTime(gomock.Any(), applicationName, cluster.Name, "javaapp").
+		Return("restart-commit", nil).Times(1)
+	mockCd.EXPECT().DeployCluster(gomock.Any(), gomock.Any()).Return(nil).Times(1)
+	resp, err = c.ApprovePipelinerun(approverCtx, pr.ID, &ReviewPipelinerunRequest{Comment: "lgtm"})
+	assert.Nil(t, err)
+	assert.Equal(t, pr.ID, resp.PipelinerunID)
+
+	pr, err = manager.PipelinerunMgr.GetByID(ctx, pr.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, string(prmodels.StatusOK), pr.Status)
+	assert.Equal(t, "restart-commit", pr.ConfigCommit)
+	assert.Equal(t, uint(3), *pr.ReviewedBy)
+	assert.Equal(t, "lgtm", pr.ReviewComment)
+
+	_, err = c.ApprovePipelinerun(approverCtx, pr.ID, &ReviewPipelinerunRequest{})
+	assert.Equal(t, herrors.ErrPipelinerunNotPending, perror.Cause(err))
+
+	// reject the next one
+	resp, err = c.Restart(ctx, cluster.ID)
+	assert.Nil(t, err)
+	err = c.RejectPipelinerun(guestCtx, resp.PipelinerunID, &ReviewPipelinerunRequest{})
+	assert.Equal(t, herrors.ErrForbidden, perror.Cause(err))
+	err = c.RejectPipelinerun(approverCtx, resp.PipelinerunID, &ReviewPipelinerunRequest{Comment: "not now"})
+	assert.Nil(t, err)
+	pr, err = manager.PipelinerunMgr.GetByID(ctx, resp.PipelinerunID)
+	assert.Nil(t, err)
+	assert.Equal(t, string(prmodels.StatusRejected), pr.Status)
+	assert.Equal(t, "not now", pr.ReviewComment)
+}
+
+func testPipelinerunRequest(t *testing.T) {
+	// the request keeps the whole description besides the other fields, so it could be longer than the description
+	r := &BuildDeployRequest{
+		Title:       strings.Repeat("t", 256),
+		Description: strings.Repeat("d", 2048),
+		Git: &BuildDeployRequestGit{
+			Branch: "develop",
+		},
+	}
+	request := marshalRequest(ctx, r)
+	assert.True(t, len(request) > 2048)
+
+	pr, err := manager.PipelinerunMgr.Create(ctx, &prmodels.Pipelinerun{
+		ClusterID: 1,
+		Action:    prmodels.ActionBuildDeploy,
+		Status:    string(prmodels.StatusPending),
+		Request:   request,
+	})
+	assert.Nil(t, err)
+	pr, err = manager.PipelinerunMgr.GetByID(ctx, pr.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, request, pr.Request)
+
+	kept := &BuildDeployRequest{}
+	assert.Nil(t, unmarshalRequest(pr, kept))
+	assert.Equal(t, r, kept)
+}
@@ -0,0 +1,122 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+
+	herrors "github.com/horizoncd/horizon/core/errors"
+	cmodels "github.com/horizoncd/horizon/pkg/cluster/models"
+	"github.com/horizoncd/horizon/pkg/cluster/registry"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	prmodels "github.com/horizoncd/horizon/pkg/pipelinerun/models"
+	regionmodels "github.com/horizoncd/horizon/pkg/region/models"
+	"github.com/horizoncd/horizon/pkg/util/log"
+)
+
+// _buildCacheCandidates is the number of latest builds checked in the registry
+const _buildCacheCandidates = 10
+
+// hashBuildConfig hashes the build config, keys of maps are sorted by json, so equal configs have the same hash
+func hashBuildConfig(buildConfig map[string]interface{}) (string, error) {
+	bts, err := json.Marshal(buildConfig)
+	if err != nil {
+		return "", perror.Wrap(herrors.ErrParamInvalid, err.Error())
+	}
+	sum := sha256.Sum256(bts)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+func (c *controller) shouldReuseBuild(r *BuildDeployRequest) bool {
+	if r.ReuseBuild != nil {
+		return *r.ReuseBuild
+	}
+	return c.buildCacheConfig.AutoReuse
+}
+
+// getBuildCacheOfCommit finds the build of the commit with the current build config of the cluster
+func (c *controller) getBuildCacheOfCommit(ctx context.Context, cluster *cmodels.Cluster,
+	application, commit string) (*prmodels.Pipelinerun, error) {
+	if cluster.GitURL == "" {
+		return nil, nil
+	}
+	tr, err := c.templateReleaseMgr.GetByTemplateNameAndRelease(ctx, cluster.Template, cluster.TemplateRelease)
+	if err != nil {
+		return nil, err
+	}
+	clusterFiles, err := c.clusterGitRepo.GetCluster(ctx, application, cluster.Name, tr.ChartName)
+	if err != nil {
+		return nil, err
+	}
+	buildConfigHash, err := hashBuildConfig(clusterFiles.PipelineJSONBlob)
+	if err != nil {
+		return nil, err
+	}
+	regionEntity, err := c.regionMgr.GetRegionEntity(ctx, cluster.RegionName)
+	if err != nil {
+		return nil, err
+	}
+	return c.getBuildCache(ctx, cluster, regionEntity, commit, buildConfigHash)
+}
+
+// getBuildCache finds the latest build of the commit with the build config whose image
+// is still kept by the registry of the region, nil is returned if there is none
+func (c *controller) getBuildCache(ctx context.Context, cluster *cmodels.Cluster,
+	regionEntity *regionmodels.RegionEntity, commit, buildConfigHash string) (*prmodels.Pipelinerun, error) {
+	builds, err := c.pipelinerunMgr.ListBuilds(ctx, cluster.GitURL, cluster.GitSubfolder,
+		commit, buildConfigHash, _buildCacheCandidates)
+	if err != nil {
+		return nil, err
+	}
+	return c.getBuildWithImage(ctx, regionEntity, builds)
+}
+
+// getBuildWithImage returns the first build whose image is still kept by the registry of the region
+func (c *controller) getBuildWithImage(ctx context.Context, regionEntity *regionmodels.RegionEntity,
+	builds []*prmodels.Pipelinerun) (*prmodels.Pipelinerun, error) {
+	if len(builds) == 0 || regionEntity.Registry == nil {
+		return nil, nil
+	}
+
+	config := &registry.Config{
+		Server:             regionEntity.Registry.Server,
+		Token:              regionEntity.Registry.Token,
+		InsecureSkipVerify: regionEntity.Registry.InsecureSkipTLSVerify,
+		Kind:               regionEntity.Registry.Kind,
+		Path:               regionEntity.Registry.Path,
+	}
+	rg, err := c.registryFty.GetRegistryByConfig(ctx, config)
+	if err != nil {
+		return nil, err
+	}
+	for _, build := range builds {
+		// images pushed to registries of other regions can not be pulled by this region
+		if _, _, err := registry.ParseImage(config, build.ImageURL); err != nil {
+			continue
+		}
+		exists, err := rg.ImageExists(ctx, build.ImageURL)
+		if err != nil {
+			log.Warningf(ctx, "failed to check image %s of pipelinerun %d: %v", build.ImageURL, build.ID, err)
+			continue
+		}
+		if exists {
+			return build, nil
+		}
+	}
+	return nil, nil
+}
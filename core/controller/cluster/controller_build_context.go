@@ -0,0 +1,89 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import (
+	"context"
+	"path"
+
+	herrors "github.com/horizoncd/horizon/core/errors"
+	cmodels "github.com/horizoncd/horizon/pkg/cluster/models"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	prmodels "github.com/horizoncd/horizon/pkg/pipelinerun/models"
+	regionmodels "github.com/horizoncd/horizon/pkg/region/models"
+	"github.com/horizoncd/horizon/pkg/util/glob"
+	"github.com/horizoncd/horizon/pkg/util/log"
+)
+
+// compareWithLastBuild compares the commit with the last successful build of the cluster if the cluster
+// declares its build context. It returns the commit of the last build, and the build whose image can be
+// deployed instead of building again if none of the files the build depends on changed.
+func (c *controller) compareWithLastBuild(ctx context.Context, cluster *cmodels.Cluster,
+	regionEntity *regionmodels.RegionEntity, commit, buildConfigHash string) (string, *prmodels.Pipelinerun, error) {
+	if c.buildContextMgr == nil {
+		return "", nil, nil
+	}
+	buildContext, err := c.buildContextMgr.GetByClusterID(ctx, cluster.ID)
+	if err != nil {
+		if _, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); ok {
+			return "", nil, nil
+		}
+		return "", nil, err
+	}
+	last, err := c.pipelinerunMgr.GetLatestByClusterIDAndActionAndStatus(ctx, cluster.ID,
+		prmodels.ActionBuildDeploy, string(prmodels.StatusOK))
+	if err != nil {
+		return "", nil, err
+	}
+	if last == nil || last.GitCommit == "" || last.GitURL != cluster.GitURL {
+		return "", nil, nil
+	}
+
+	comparison, err := c.commitGetter.Compare(ctx, cluster.GitURL, last.GitCommit, commit)
+	if err != nil {
+		// build anyway if the changes are unknown
+		log.Warningf(ctx, "failed to compare commit %s with %s of the last build %d: %v",
+			commit, last.GitCommit, last.ID, err)
+		return "", nil, nil
+	}
+	if !comparison.Complete {
+		return last.GitCommit, nil, nil
+	}
+	globs := buildContext.GetPathGlobs()
+	if len(globs) == 0 {
+		globs = []string{path.Join(cluster.GitSubfolder, "**")}
+	}
+	for _, file := range comparison.ChangedFiles {
+		if glob.MatchAny(globs, file) {
+			return last.GitCommit, nil, nil
+		}
+	}
+
+	// the image of the last build can't be reused if it's built from another build config or subfolder
+	if last.BuildConfigHash != buildConfigHash || last.GitSubfolder != cluster.GitSubfolder {
+		return last.GitCommit, nil, nil
+	}
+	build := last
+	if last.ReuseFrom != nil {
+		if build, err = c.pipelinerunMgr.GetByID(ctx, *last.ReuseFrom); err != nil {
+			return "", nil, err
+		}
+	}
+	build, err = c.getBuildWithImage(ctx, regionEntity, []*prmodels.Pipelinerun{build})
+	if err != nil {
+		return "", nil, err
+	}
+	return last.GitCommit, build, nil
+}
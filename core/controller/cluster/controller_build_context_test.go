@@ -0,0 +1,113 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import (
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	commitmock "github.com/horizoncd/horizon/mock/pkg/cluster/code"
+	registrymock "github.com/horizoncd/horizon/mock/pkg/cluster/registry"
+	registryftymock "github.com/horizoncd/horizon/mock/pkg/cluster/registry/factory"
+	buildcontextmodels "github.com/horizoncd/horizon/pkg/buildcontext/models"
+	"github.com/horizoncd/horizon/pkg/cluster/models"
+	"github.com/horizoncd/horizon/pkg/git"
+	prmodels "github.com/horizoncd/horizon/pkg/pipelinerun/models"
+	regionmodels "github.com/horizoncd/horizon/pkg/region/models"
+	registrymodels "github.com/horizoncd/horizon/pkg/registry/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func testBuildContext(t *testing.T) {
+	mockCtl := gomock.NewController(t)
+	commitGetter := commitmock.NewMockGitGetter(mockCtl)
+	registryFty := registryftymock.NewMockRegistryGetter(mockCtl)
+	imageRegistry := registrymock.NewMockRegistry(mockCtl)
+	registryFty.EXPECT().GetRegistryByConfig(gomock.Any(), gomock.Any()).Return(imageRegistry, nil).AnyTimes()
+	imageRegistry.EXPECT().ImageExists(gomock.Any(), gomock.Any()).Return(true, nil).AnyTimes()
+
+	c = &controller{
+		pipelinerunMgr:  manager.PipelinerunMgr,
+		buildContextMgr: manager.BuildContextMgr,
+		commitGetter:    commitGetter,
+		registryFty:     registryFty,
+	}
+	gitURL := "ssh://git@cloudnative.com:22222/demo/monorepo.git"
+	cluster, err := manager.ClusterMgr.Create(ctx, &models.Cluster{
+		ApplicationID: 1,
+		Name:          "monorepo-api",
+		GitURL:        gitURL,
+		GitSubfolder:  "/services/api",
+	}, nil, nil)
+	assert.Nil(t, err)
+	regionEntity := &regionmodels.RegionEntity{
+		Region:   &regionmodels.Region{Name: "hz"},
+		Registry: &registrymodels.Registry{Server: "https://harbor.cloudnative.com", Path: "horizon"},
+	}
+
+	// clusters without build context are always built
+	lastCommit, unchanged, err := c.compareWithLastBuild(ctx, cluster, regionEntity, "c2", "hash")
+	assert.Nil(t, err)
+	assert.Equal(t, "", lastCommit)
+	assert.Nil(t, unchanged)
+
+	buildContext := &buildcontextmodels.BuildContext{ClusterID: cluster.ID}
+	_, err = manager.BuildContextMgr.Upsert(ctx, buildContext)
+	assert.Nil(t, err)
+	last, err := manager.PipelinerunMgr.Create(ctx, &prmodels.Pipelinerun{
+		ClusterID:       cluster.ID,
+		Action:          prmodels.ActionBuildDeploy,
+		Status:          string(prmodels.StatusOK),
+		GitURL:          gitURL,
+		GitCommit:       "c1",
+		GitSubfolder:    "/services/api",
+		BuildConfigHash: "hash",
+		ImageURL:        "harbor.cloudnative.com/horizon/monorepo/monorepo-api:main-c1",
+	})
+	assert.Nil(t, err)
+
+	// files out of the subfolder are changed
+	commitGetter.EXPECT().Compare(ctx, gitURL, "c1", "c2").Return(&git.Comparison{
+		ChangedFiles: []string{"services/web/main.go", "libs/log/log.go"},
+		Complete:     true,
+	}, nil).Times(3)
+	lastCommit, unchanged, err = c.compareWithLastBuild(ctx, cluster, regionEntity, "c2", "hash")
+	assert.Nil(t, err)
+	assert.Equal(t, "c1", lastCommit)
+	assert.Equal(t, last.ID, unchanged.ID)
+
+	// the build config is changed
+	_, unchanged, err = c.compareWithLastBuild(ctx, cluster, regionEntity, "c2", "another-hash")
+	assert.Nil(t, err)
+	assert.Nil(t, unchanged)
+
+	// the build depends on the shared libs
+	buildContext = &buildcontextmodels.BuildContext{ClusterID: cluster.ID}
+	buildContext.SetPathGlobs([]string{"services/api/**", "libs/**"})
+	_, err = manager.BuildContextMgr.Upsert(ctx, buildContext)
+	assert.Nil(t, err)
+	lastCommit, unchanged, err = c.compareWithLastBuild(ctx, cluster, regionEntity, "c2", "hash")
+	assert.Nil(t, err)
+	assert.Equal(t, "c1", lastCommit)
+	assert.Nil(t, unchanged)
+
+	// too many files are changed to tell
+	commitGetter.EXPECT().Compare(ctx, gitURL, "c1", "c3").Return(&git.Comparison{
+		ChangedFiles: []string{"services/web/main.go"},
+	}, nil).Times(1)
+	_, unchanged, err = c.compareWithLastBuild(ctx, cluster, regionEntity, "c3", "hash")
+	assert.Nil(t, err)
+	assert.Nil(t, unchanged)
+}
@@ -75,8 +75,10 @@ func (c *controller) BuildDeploy(ctx context.Context, clusterID uint,
 		}
 	}
 
+	commitFound := true
 	commit, err := c.commitGetter.GetCommit(ctx, cluster.GitURL, gitRefType, gitRef)
 	if err != nil {
+		commitFound = false
 		commit = &git.Commit{
 			Message: "commit not found",
 			ID:      gitRef,
@@ -88,8 +90,44 @@ func (c *controller) BuildDeploy(ctx context.Context, clusterID uint,
 		return nil, err
 	}
 
-	// 1. assemble artifact imageURL
+	tr, err := c.templateReleaseMgr.GetByTemplateNameAndRelease(ctx, cluster.Template, cluster.TemplateRelease)
+	if err != nil {
+		return nil, err
+	}
+	clusterFiles, err := c.clusterGitRepo.GetCluster(ctx,
+		application.Name, cluster.Name, tr.ChartName)
+	if err != nil {
+		return nil, err
+	}
+	buildConfigHash, err := hashBuildConfig(clusterFiles.PipelineJSONBlob)
+	if err != nil {
+		return nil, err
+	}
+
+	// 1. assemble artifact imageURL, or reuse the image built from the same commit and build config
 	imageURL := assembleImageURL(regionEntity, application.Name, cluster.Name, gitRef, commit.ID)
+	var reused *prmodels.Pipelinerun
+	if commitFound && c.shouldReuseBuild(r) {
+		reused, err = c.getBuildCache(ctx, cluster, regionEntity, commit.ID, buildConfigHash)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// the build is skipped if none of the files it depends on changed since the last build,
+	// unless the image is not allowed to be reused
+	var lastCommit string
+	if commitFound {
+		var unchanged *prmodels.Pipelinerun
+		lastCommit, unchanged, err = c.compareWithLastBuild(ctx, cluster, regionEntity, commit.ID, buildConfigHash)
+		if err != nil {
+			return nil, err
+		}
+		if reused == nil && unchanged != nil && (r.ReuseBuild == nil || *r.ReuseBuild) {
+			log.Infof(ctx, "files of cluster %s are not changed since commit %s", cluster.Name, lastCommit)
+			reused = unchanged
+		}
+	}
 
 	configCommit, err := c.clusterGitRepo.GetConfigCommit(ctx, application.Name, cluster.Name)
 	if err != nil {
@@ -107,34 +145,39 @@ func (c *controller) BuildDeploy(ctx context.Context, clusterID uint,
 		GitRefType:       gitRefType,
 		GitRef:           gitRef,
 		GitCommit:        commit.ID,
+		GitCommitTime:    commit.CommittedAt,
+		GitSubfolder:     cluster.GitSubfolder,
+		LastGitCommit:    lastCommit,
+		BuildConfigHash:  buildConfigHash,
 		ImageURL:         imageURL,
 		LastConfigCommit: configCommit.Master,
 		ConfigCommit:     configCommit.Gitops,
+		Request:          marshalRequest(ctx, r),
 	}
-	prCreated, err := c.pipelinerunMgr.Create(ctx, pr)
+	if reused != nil {
+		log.Infof(ctx, "reuse image %s built by pipelinerun %d", reused.ImageURL, reused.ID)
+		pr.ImageURL = reused.ImageURL
+		pr.ReuseFrom = &reused.ID
+	}
+	prCreated, pending, err := c.createPipelinerun(ctx, cluster, pr)
 	if err != nil {
 		return nil, err
 	}
+	if pending {
+		return &BuildDeployResponse{
+			PipelinerunID: prCreated.ID,
+		}, nil
+	}
 
-	// 3. generate a JWT token for tekton callback
+	// 3. generate a JWT token for the callbacks of the ci engine
 	token, err := c.tokenSvc.CreateJWTToken(strconv.Itoa(int(currentUser.GetID())),
 		c.tokenConfig.CallbackTokenExpireIn, tokensvc.WithPipelinerunID(prCreated.ID))
 	if err != nil {
 		return nil, err
 	}
 
-	// 4. create pipelinerun in k8s
-	tektonClient, err := c.tektonFty.GetTekton(cluster.EnvironmentName)
-	if err != nil {
-		return nil, err
-	}
-
-	tr, err := c.templateReleaseMgr.GetByTemplateNameAndRelease(ctx, cluster.Template, cluster.TemplateRelease)
-	if err != nil {
-		return nil, err
-	}
-	clusterFiles, err := c.clusterGitRepo.GetCluster(ctx,
-		application.Name, cluster.Name, tr.ChartName)
+	// 4. create pipelinerun in the ci engine
+	engine, err := c.ciFty.GetEngine(cluster.EnvironmentName)
 	if err != nil {
 		return nil, err
 	}
@@ -151,15 +194,20 @@ func (c *controller) BuildDeploy(ctx context.Context, clusterID uint,
 		prGit.Branch = prCreated.GitRef
 	}
 
-	ciEventID, err := tektonClient.CreatePipelineRun(ctx, &tekton.PipelineRun{
-		Action:           prmodels.ActionBuildDeploy,
+	// the image reused is deployed without building
+	action := prmodels.ActionBuildDeploy
+	if prCreated.ReuseFrom != nil {
+		action = prmodels.ActionDeploy
+	}
+	ciEventID, err := engine.CreatePipelineRun(ctx, &tekton.PipelineRun{
+		Action:           action,
 		Application:      application.Name,
 		ApplicationID:    application.ID,
 		Cluster:          cluster.Name,
 		ClusterID:        cluster.ID,
 		Environment:      cluster.EnvironmentName,
 		Git:              prGit,
-		ImageURL:         imageURL,
+		ImageURL:         prCreated.ImageURL,
 		Operator:         currentUser.GetEmail(),
 		PipelinerunID:    prCreated.ID,
 		PipelineJSONBlob: clusterFiles.PipelineJSONBlob,
@@ -172,8 +220,8 @@ func (c *controller) BuildDeploy(ctx context.Context, clusterID uint,
 		return nil, err
 	}
 
-	// update event id returned from tekton-trigger EventListener
-	log.Infof(ctx, "received event id: %s from tekton-trigger EventListener, pipelinerunID: %d", ciEventID, pr.ID)
+	// update event id returned from the ci engine
+	log.Infof(ctx, "received event id: %s from the ci engine, pipelinerunID: %d", ciEventID, pr.ID)
 	err = c.pipelinerunMgr.UpdateCIEventIDByID(ctx, pr.ID, ciEventID)
 	if err != nil {
 		return nil, err
@@ -229,8 +277,10 @@ func (c *controller) GetDiff(ctx context.Context, clusterID uint, refType, ref s
 
 	// 3. get code commit
 	var commit *git.Commit
+	commitFound := false
 	if ref != "" {
 		commit, err = c.commitGetter.GetCommit(ctx, cluster.GitURL, refType, ref)
+		commitFound = err == nil
 		if err != nil {
 			commit = &git.Commit{
 				ID:      ref,
@@ -249,7 +299,26 @@ func (c *controller) GetDiff(ctx context.Context, clusterID uint, refType, ref s
 	if err != nil {
 		return nil, err
 	}
-	return c.ofClusterDiff(cluster.GitURL, refType, ref, commit, diff)
+	resp, err := c.ofClusterDiff(cluster.GitURL, refType, ref, commit, diff)
+	if err != nil {
+		return nil, err
+	}
+
+	// 5. offer the build whose image can be reused, the diff is still returned if it fails
+	if commitFound {
+		buildCache, err := c.getBuildCacheOfCommit(ctx, cluster, application.Name, commit.ID)
+		if err != nil {
+			log.Warningf(ctx, "failed to get build cache of commit %s: %v", commit.ID, err)
+		} else if buildCache != nil {
+			resp.BuildCache = &BuildCache{
+				PipelinerunID: buildCache.ID,
+				ClusterID:     buildCache.ClusterID,
+				ImageURL:      buildCache.ImageURL,
+				CreatedAt:     buildCache.CreatedAt,
+			}
+		}
+	}
+	return resp, nil
 }
 
 func (c *controller) ofClusterDiff(gitURL, refType, ref string, commit *git.Commit, diff string) (
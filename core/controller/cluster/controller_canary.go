@@ -0,0 +1,86 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import (
+	"context"
+	"time"
+
+	canarymodels "github.com/horizoncd/horizon/pkg/canary/models"
+	cmodels "github.com/horizoncd/horizon/pkg/cluster/models"
+	prmodels "github.com/horizoncd/horizon/pkg/pipelinerun/models"
+	"github.com/horizoncd/horizon/pkg/util/log"
+)
+
+// startCanaryAnalysis starts analyzing the canary steps of the rollout deployed by the pipelinerun
+// if the cluster's template declares success criteria, the analysis is done by the canary job.
+// failures are only logged, so that the deployment itself is not affected
+func (c *controller) startCanaryAnalysis(ctx context.Context, cluster *cmodels.Cluster, pr *prmodels.Pipelinerun) {
+	if !c.canaryConfig.Enabled || c.analysisGetter == nil {
+		return
+	}
+
+	// analyses of former deployments are superseded
+	if err := c.canaryAnalysisMgr.CancelRunningByClusterID(ctx, cluster.ID); err != nil {
+		log.Warningf(ctx, "failed to cancel running canary analyses of cluster %d: %v", cluster.ID, err)
+		return
+	}
+
+	spec, err := c.analysisGetter.GetTemplateAnalysis(ctx, cluster.Template, cluster.TemplateRelease)
+	if err != nil {
+		log.Warningf(ctx, "failed to get analysis of template %s release %s: %v",
+			cluster.Template, cluster.TemplateRelease, err)
+		return
+	}
+	if spec == nil || len(spec.Metrics) == 0 {
+		return
+	}
+
+	if _, err := c.canaryAnalysisMgr.Create(ctx, &canarymodels.CanaryAnalysis{
+		PipelinerunID: pr.ID,
+		ClusterID:     cluster.ID,
+		Status:        canarymodels.StatusRunning,
+		StartedAt:     time.Now(),
+	}); err != nil {
+		log.Warningf(ctx, "failed to create canary analysis for pipelinerun %d: %v", pr.ID, err)
+	}
+}
+
+// listCanaryAnalyses lists the analyses of the latest deployment of the cluster
+func (c *controller) listCanaryAnalyses(ctx context.Context, clusterID uint) ([]*CanaryAnalysis, error) {
+	pr, err := c.pipelinerunMgr.GetLatestByClusterIDAndActions(ctx, clusterID,
+		prmodels.ActionBuildDeploy, prmodels.ActionDeploy)
+	if err != nil || pr == nil {
+		return nil, err
+	}
+	analyses, err := c.canaryAnalysisMgr.ListByPipelinerunID(ctx, pr.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*CanaryAnalysis, 0, len(analyses))
+	for _, analysis := range analyses {
+		result = append(result, &CanaryAnalysis{
+			PipelinerunID: analysis.PipelinerunID,
+			Step:          analysis.Step,
+			Status:        analysis.Status,
+			Metrics:       analysis.MetricResults(),
+			Message:       analysis.Message,
+			StartedAt:     analysis.StartedAt,
+			FinishedAt:    analysis.FinishedAt,
+		})
+	}
+	return result, nil
+}
@@ -124,6 +124,7 @@ func (c *controller) InternalDeploy(ctx context.Context, clusterID uint,
 		ValueFiles:   repoInfo.ValueFiles,
 		RegionEntity: regionEntity,
 		Namespace:    envValue.Namespace,
+		TemplateKind: tr.Kind,
 	}); err != nil {
 		return nil, err
 	}
@@ -150,6 +151,7 @@ func (c *controller) InternalDeploy(ctx context.Context, clusterID uint,
 	if err := updatePRStatus(prmodels.StatusOK, masterRevision); err != nil {
 		return nil, err
 	}
+	c.startCanaryAnalysis(ctx, cluster, pr)
 
 	// 10. record event
 	if _, err := c.eventMgr.CreateEvent(ctx, &eventmodels.Event{
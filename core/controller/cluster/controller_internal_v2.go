@@ -94,11 +94,22 @@ func (c *controller) InternalDeployV2(ctx context.Context, clusterID uint,
 		return nil
 	}
 
+	if pr.Action == prmodels.ActionBuildDeploy {
+		if err := c.checkVulnerabilityPolicy(ctx, cluster, pr); err != nil {
+			return nil, err
+		}
+	}
+
 	if pr.Action != prmodels.ActionDeploy || pr.GitURL == "" {
 		// 3. update pipeline output in git repo
-		log.Infof(ctx, "pipeline %v output content: %+v", r.PipelinerunID, r.Output)
+		var output interface{} = r.Output
+		if pr.ReuseFrom != nil {
+			// the pipeline only deployed the image reused, so the output of the build is rebuilt from the pipelinerun
+			output = ofPipelineOutput(pr)
+		}
+		log.Infof(ctx, "pipeline %v output content: %+v", r.PipelinerunID, output)
 		commit, err := c.clusterGitRepo.UpdatePipelineOutput(ctx, application.Name, cluster.Name,
-			tr.ChartName, r.Output)
+			tr.ChartName, output)
 		if err != nil {
 			return nil, perror.WithMessage(err, op)
 		}
@@ -150,6 +161,7 @@ func (c *controller) InternalDeployV2(ctx context.Context, clusterID uint,
 		ValueFiles:   repoInfo.ValueFiles,
 		RegionEntity: regionEntity,
 		Namespace:    envValue.Namespace,
+		TemplateKind: tr.Kind,
 	}); err != nil {
 		return nil, err
 	}
@@ -176,6 +188,7 @@ func (c *controller) InternalDeployV2(ctx context.Context, clusterID uint,
 	if err := updatePRStatus(prmodels.StatusOK, masterRevision); err != nil {
 		return nil, err
 	}
+	c.startCanaryAnalysis(ctx, cluster, pr)
 
 	// 10. record event
 	c.recordEvent(ctx, pr, cluster)
@@ -0,0 +1,195 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import (
+	"context"
+
+	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/lib/q"
+	codemodels "github.com/horizoncd/horizon/pkg/cluster/code"
+	"github.com/horizoncd/horizon/pkg/cluster/models"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/templaterelease/migration"
+	trmodels "github.com/horizoncd/horizon/pkg/templaterelease/models"
+	"github.com/horizoncd/horizon/pkg/util/log"
+	"github.com/horizoncd/horizon/pkg/util/wlog"
+)
+
+func (c *controller) DryRunTemplateMigration(ctx context.Context, releaseID uint,
+	r *TemplateMigrationRequest) (*TemplateMigrationResponse, error) {
+	const op = "cluster controller: dry run template migration"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	return c.migrateTemplate(ctx, releaseID, r, false)
+}
+
+func (c *controller) ApplyTemplateMigration(ctx context.Context, releaseID uint,
+	r *TemplateMigrationRequest) (*TemplateMigrationResponse, error) {
+	const op = "cluster controller: apply template migration"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	return c.migrateTemplate(ctx, releaseID, r, true)
+}
+
+func (c *controller) migrateTemplate(ctx context.Context, releaseID uint,
+	r *TemplateMigrationRequest, apply bool) (*TemplateMigrationResponse, error) {
+	tr, err := c.templateReleaseMgr.GetByID(ctx, releaseID)
+	if err != nil {
+		return nil, err
+	}
+	spec, err := c.migrationGetter.GetTemplateMigration(ctx, tr.TemplateName, tr.Name)
+	if err != nil {
+		return nil, err
+	}
+	if spec == nil {
+		// the release can be upgraded to without changing values
+		spec = &migration.Spec{}
+	}
+
+	clusters, err := c.listMigratingClusters(ctx, tr, r)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &TemplateMigrationResponse{
+		Template: tr.TemplateName,
+		Release:  tr.Name,
+		Clusters: make([]*ClusterMigration, 0, len(clusters)),
+	}
+	for _, cluster := range clusters {
+		resp.Clusters = append(resp.Clusters, c.migrateCluster(ctx, tr, spec, cluster, apply))
+	}
+	return resp, nil
+}
+
+// listMigratingClusters lists the clusters of the template which are not on the release yet
+func (c *controller) listMigratingClusters(ctx context.Context, tr *trmodels.TemplateRelease,
+	r *TemplateMigrationRequest) ([]*models.Cluster, error) {
+	query := q.New(q.KeyWords{common.ClusterQueryByTemplate: tr.TemplateName})
+	query.WithoutPagination = true
+	_, clusters, err := c.clusterMgr.List(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	migrating := make(map[uint]*models.Cluster)
+	for _, cluster := range clusters {
+		if cluster.TemplateRelease != tr.Name {
+			migrating[cluster.ID] = cluster.Cluster
+		}
+	}
+
+	ret := make([]*models.Cluster, 0, len(migrating))
+	if r == nil || len(r.ClusterIDs) == 0 {
+		for _, cluster := range clusters {
+			if cluster, ok := migrating[cluster.ID]; ok {
+				ret = append(ret, cluster)
+			}
+		}
+		return ret, nil
+	}
+	for _, id := range r.ClusterIDs {
+		cluster, ok := migrating[id]
+		if !ok {
+			return nil, perror.Wrapf(herrors.ErrParamInvalid,
+				"cluster %d is not a cluster of template %s on other releases", id, tr.TemplateName)
+		}
+		ret = append(ret, cluster)
+	}
+	return ret, nil
+}
+
+// migrateCluster migrates and validates the values of the cluster,
+// and upgrades the cluster with them if apply is true and nothing goes wrong
+func (c *controller) migrateCluster(ctx context.Context, tr *trmodels.TemplateRelease,
+	spec *migration.Spec, cluster *models.Cluster, apply bool) *ClusterMigration {
+	result := &ClusterMigration{
+		ClusterID:   cluster.ID,
+		Cluster:     cluster.Name,
+		FromRelease: cluster.TemplateRelease,
+	}
+	fail := func(err error) *ClusterMigration {
+		log.Warningf(ctx, "failed to migrate cluster %s to template %s release %s: %v",
+			cluster.Name, tr.TemplateName, tr.Name, err)
+		result.Errors = append(result.Errors, err.Error())
+		return result
+	}
+
+	application, err := c.applicationMgr.GetByID(ctx, cluster.ApplicationID)
+	if err != nil {
+		return fail(err)
+	}
+	result.Application = application.Name
+
+	files, err := c.clusterGitRepo.GetCluster(ctx, application.Name, cluster.Name, cluster.Template)
+	if err != nil {
+		return fail(err)
+	}
+	if files.Manifest == nil {
+		return fail(perror.Wrapf(herrors.ErrParamInvalid, "git repo %s not support v2 interface", cluster.Name))
+	}
+
+	buildConfig, templateConfig := files.PipelineJSONBlob, files.ApplicationJSONBlob
+	if m := spec.Match(cluster.TemplateRelease); m != nil {
+		result.Migrated = true
+		if buildConfig, err = migration.Apply(buildConfig, m.Pipeline); err != nil {
+			return fail(perror.WithMessage(err, "failed to migrate pipeline values"))
+		}
+		if templateConfig, err = migration.Apply(templateConfig, m.Application); err != nil {
+			return fail(perror.WithMessage(err, "failed to migrate application values"))
+		}
+	}
+	result.BuildConfigDiff = migration.Diff(files.PipelineJSONBlob, buildConfig)
+	result.TemplateConfigDiff = migration.Diff(files.ApplicationJSONBlob, templateConfig)
+
+	templateInfo := &codemodels.TemplateInfo{
+		Name:    tr.TemplateName,
+		Release: tr.Name,
+	}
+	renderValues, err := c.getRenderValueFromTag(ctx, cluster.ID)
+	if err != nil {
+		return fail(err)
+	}
+	info := BuildTemplateInfo{
+		BuildConfig:    buildConfig,
+		TemplateInfo:   templateInfo,
+		TemplateConfig: templateConfig,
+	}
+	if err := info.Validate(ctx, c.templateSchemaGetter, renderValues, c.buildSchema); err != nil {
+		return fail(err)
+	}
+
+	if !apply {
+		return result
+	}
+	if buildConfig == nil {
+		buildConfig = make(map[string]interface{})
+	}
+	if templateConfig == nil {
+		templateConfig = make(map[string]interface{})
+	}
+	if err := c.UpdateClusterV2(ctx, cluster.ID, &UpdateClusterRequestV2{
+		Description:    cluster.Description,
+		BuildConfig:    buildConfig,
+		TemplateInfo:   templateInfo,
+		TemplateConfig: templateConfig,
+	}, false); err != nil {
+		return fail(err)
+	}
+	result.Applied = true
+	return result
+}
@@ -0,0 +1,205 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import (
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	clustergitrepomock "github.com/horizoncd/horizon/mock/pkg/cluster/gitrepo"
+	migrationmock "github.com/horizoncd/horizon/mock/pkg/templaterelease/migration"
+	trschemamock "github.com/horizoncd/horizon/mock/pkg/templaterelease/schema"
+	appmodels "github.com/horizoncd/horizon/pkg/application/models"
+	"github.com/horizoncd/horizon/pkg/cluster/gitrepo"
+	"github.com/horizoncd/horizon/pkg/cluster/models"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	groupmodels "github.com/horizoncd/horizon/pkg/group/models"
+	regionmodels "github.com/horizoncd/horizon/pkg/region/models"
+	"github.com/horizoncd/horizon/pkg/templaterelease/migration"
+	trmodels "github.com/horizoncd/horizon/pkg/templaterelease/models"
+	trschema "github.com/horizoncd/horizon/pkg/templaterelease/schema"
+	"github.com/stretchr/testify/assert"
+)
+
+func testTemplateMigration(t *testing.T) {
+	templateName := "migrateapp"
+	applicationName := "app-migrate"
+	mockCtl := gomock.NewController(t)
+	clusterGitRepo := clustergitrepomock.NewMockClusterGitRepo(mockCtl)
+	templateSchemaGetter := trschemamock.NewMockGetter(mockCtl)
+	migrationGetter := migrationmock.NewMockGetter(mockCtl)
+
+	_, err := manager.RegionMgr.Create(ctx, &regionmodels.Region{
+		Name: "migrate-hz",
+	})
+	assert.Nil(t, err)
+	var target *trmodels.TemplateRelease
+	for _, release := range []string{"v1.0.0", "v1.1.0"} {
+		tr, err := manager.TemplateReleaseManager.Create(ctx, &trmodels.TemplateRelease{
+			TemplateName: templateName,
+			Name:         release,
+			ChartName:    templateName,
+		})
+		assert.Nil(t, err)
+		target = tr
+	}
+	group, err := manager.GroupManager.Create(ctx, &groupmodels.Group{
+		Name: "migrate-group",
+		Path: "migrate-group",
+	})
+	assert.Nil(t, err)
+	application, err := manager.ApplicationManager.Create(ctx, &appmodels.Application{
+		GroupID:         group.ID,
+		Name:            applicationName,
+		Template:        templateName,
+		TemplateRelease: "v1.0.0",
+	}, nil)
+	assert.Nil(t, err)
+
+	clusters := make(map[string]*models.Cluster)
+	for name, release := range map[string]string{
+		"app-migrate-a":      "v1.0.0",
+		"app-migrate-b":      "v1.0.0",
+		"app-migrate-v1":     "v1.0.0",
+		"app-migrate-latest": "v1.1.0",
+	} {
+		cluster, err := manager.ClusterMgr.Create(ctx, &models.Cluster{
+			ApplicationID:   application.ID,
+			Name:            name,
+			Description:     "keep me",
+			EnvironmentName: "test",
+			RegionName:      "migrate-hz",
+			GitURL:          "ssh://git.com",
+			GitRefType:      "branch",
+			GitRef:          "master",
+			Template:        templateName,
+			TemplateRelease: release,
+		}, nil, nil)
+		assert.Nil(t, err)
+		clusters[name] = cluster
+	}
+
+	// values of v1.0.0 name replicas as replica
+	valuesOf := func(resource string) map[string]interface{} {
+		values, err := promoteValues(applicationJSONBlob, nil, nil)
+		assert.Nil(t, err)
+		spec := values["app"].(map[string]interface{})["spec"].(map[string]interface{})
+		delete(spec, "replicas")
+		spec["replica"] = float64(2)
+		spec["resource"] = resource
+		return values
+	}
+	clusterGitRepo.EXPECT().GetCluster(ctx, applicationName, "app-migrate-a", templateName).
+		Return(&gitrepo.ClusterFiles{
+			PipelineJSONBlob:    pipelineJSONBlob,
+			ApplicationJSONBlob: valuesOf("small"),
+			Manifest:            map[string]interface{}{"version": "0.0.2"},
+		}, nil).AnyTimes()
+	clusterGitRepo.EXPECT().GetCluster(ctx, applicationName, "app-migrate-b", templateName).
+		Return(&gitrepo.ClusterFiles{
+			PipelineJSONBlob:    pipelineJSONBlob,
+			ApplicationJSONBlob: valuesOf("huge"),
+			Manifest:            map[string]interface{}{"version": "0.0.2"},
+		}, nil).AnyTimes()
+	clusterGitRepo.EXPECT().GetCluster(ctx, applicationName, "app-migrate-v1", templateName).
+		Return(&gitrepo.ClusterFiles{
+			PipelineJSONBlob:    pipelineJSONBlob,
+			ApplicationJSONBlob: valuesOf("small"),
+		}, nil).AnyTimes()
+	templateSchemaGetter.EXPECT().GetTemplateSchema(gomock.Any(), templateName, "v1.1.0", gomock.Any()).
+		Return(&trschema.Schemas{
+			Application: &trschema.Schema{JSONSchema: applicationSchema},
+			Pipeline:    &trschema.Schema{JSONSchema: pipelineSchema},
+		}, nil).AnyTimes()
+	spec, err := migration.Parse([]byte(`migrations:
+  - from: ["v1.0.0"]
+    application:
+      - op: move
+        from: /app/spec/replica
+        path: /app/spec/replicas
+`))
+	assert.Nil(t, err)
+	migrationGetter.EXPECT().GetTemplateMigration(gomock.Any(), templateName, "v1.1.0").
+		Return(spec, nil).AnyTimes()
+
+	c = &controller{
+		clusterMgr:           manager.ClusterMgr,
+		clusterGitRepo:       clusterGitRepo,
+		applicationMgr:       manager.ApplicationManager,
+		templateReleaseMgr:   manager.TemplateReleaseManager,
+		templateSchemaGetter: templateSchemaGetter,
+		migrationGetter:      migrationGetter,
+		schemaTagManager:     manager.ClusterSchemaTagMgr,
+		tagMgr:               manager.TagManager,
+		eventMgr:             manager.EventManager,
+	}
+
+	// 1. dry run on all clusters of other releases
+	resp, err := c.DryRunTemplateMigration(ctx, target.ID, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, "v1.1.0", resp.Release)
+	assert.Equal(t, 3, len(resp.Clusters))
+	results := make(map[string]*ClusterMigration)
+	for _, result := range resp.Clusters {
+		assert.False(t, result.Applied)
+		assert.Equal(t, "v1.0.0", result.FromRelease)
+		results[result.Cluster] = result
+	}
+	assert.Empty(t, results["app-migrate-a"].Errors)
+	assert.True(t, results["app-migrate-a"].Migrated)
+	assert.Empty(t, results["app-migrate-a"].BuildConfigDiff)
+	assert.Equal(t, []migration.Change{
+		{Path: "/app/spec/replica", Type: migration.ChangeRemoved, Old: float64(2)},
+		{Path: "/app/spec/replicas", Type: migration.ChangeAdded, New: float64(2)},
+	}, results["app-migrate-a"].TemplateConfigDiff)
+	// resource is not valid for the schema
+	assert.Equal(t, 1, len(results["app-migrate-b"].Errors))
+	assert.Equal(t, 2, len(results["app-migrate-b"].TemplateConfigDiff))
+	assert.Equal(t, 1, len(results["app-migrate-v1"].Errors))
+
+	// 2. clusters which are already on the release can not be migrated
+	_, err = c.DryRunTemplateMigration(ctx, target.ID, &TemplateMigrationRequest{
+		ClusterIDs: []uint{clusters["app-migrate-latest"].ID},
+	})
+	assert.Equal(t, herrors.ErrParamInvalid, perror.Cause(err))
+
+	// 3. apply to the chosen clusters, invalid ones are skipped
+	clusterGitRepo.EXPECT().UpdateCluster(ctx, gomock.Any()).
+		DoAndReturn(func(_ interface{}, params *gitrepo.UpdateClusterParams) error {
+			assert.Equal(t, "app-migrate-a", params.Cluster)
+			assert.Equal(t, "v1.1.0", params.TemplateRelease.Name)
+			spec := params.ApplicationJSONBlob["app"].(map[string]interface{})["spec"].(map[string]interface{})
+			assert.Equal(t, float64(2), spec["replicas"])
+			_, ok := spec["replica"]
+			assert.False(t, ok)
+			return nil
+		}).Times(1)
+	resp, err = c.ApplyTemplateMigration(ctx, target.ID, &TemplateMigrationRequest{
+		ClusterIDs: []uint{clusters["app-migrate-a"].ID, clusters["app-migrate-b"].ID},
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(resp.Clusters))
+	assert.True(t, resp.Clusters[0].Applied)
+	assert.False(t, resp.Clusters[1].Applied)
+
+	cluster, err := manager.ClusterMgr.GetByID(ctx, clusters["app-migrate-a"].ID)
+	assert.Nil(t, err)
+	assert.Equal(t, "v1.1.0", cluster.TemplateRelease)
+	assert.Equal(t, "keep me", cluster.Description)
+	cluster, err = manager.ClusterMgr.GetByID(ctx, clusters["app-migrate-b"].ID)
+	assert.Nil(t, err)
+	assert.Equal(t, "v1.0.0", cluster.TemplateRelease)
+}
@@ -63,7 +63,7 @@ func (c *controller) Restart(ctx context.Context, clusterID uint) (_ *Pipelineru
 	}
 
 	// 2. create pipeline record
-	prCreated, err := c.pipelinerunMgr.Create(ctx, &prmodels.Pipelinerun{
+	prCreated, pending, err := c.createPipelinerun(ctx, cluster, &prmodels.Pipelinerun{
 		ClusterID:        clusterID,
 		Action:           prmodels.ActionRestart,
 		Status:           string(prmodels.StatusCreated),
@@ -71,6 +71,14 @@ func (c *controller) Restart(ctx context.Context, clusterID uint) (_ *Pipelineru
 		LastConfigCommit: lastConfigCommit.Master,
 		ConfigCommit:     lastConfigCommit.Master,
 	})
+	if err != nil {
+		return nil, err
+	}
+	if pending {
+		return &PipelinerunIDResponse{
+			PipelinerunID: prCreated.ID,
+		}, nil
+	}
 
 	// 2. update restartTime in git repo, and return the newest commit
 	commit, err := c.clusterGitRepo.UpdateRestartTime(ctx, application.Name, cluster.Name, cluster.Template)
@@ -148,6 +156,7 @@ func (c *controller) Deploy(ctx context.Context, clusterID uint,
 		return nil, err
 	}
 	codeCommitID := cluster.GitRef
+	var codeCommitTime *time.Time
 	imageURL := cluster.Image
 
 	if cluster.GitURL != "" {
@@ -158,13 +167,14 @@ func (c *controller) Deploy(ctx context.Context, clusterID uint,
 		commit, err := c.commitGetter.GetCommit(ctx, cluster.GitURL, cluster.GitRefType, cluster.GitRef)
 		if err == nil {
 			codeCommitID = commit.ID
+			codeCommitTime = commit.CommittedAt
 		}
 	} else if cluster.Image != "" {
 		imageURL, err = getDeployImage(cluster.Image, r.ImageTag)
 	}
 
 	// 2. create pipeline record
-	prCreated, err := c.pipelinerunMgr.Create(ctx, &prmodels.Pipelinerun{
+	prCreated, pending, err := c.createPipelinerun(ctx, cluster, &prmodels.Pipelinerun{
 		ClusterID:        clusterID,
 		Action:           prmodels.ActionDeploy,
 		Status:           string(prmodels.StatusCreated),
@@ -174,6 +184,7 @@ func (c *controller) Deploy(ctx context.Context, clusterID uint,
 		GitRefType:       cluster.GitRefType,
 		GitRef:           cluster.GitRef,
 		GitCommit:        codeCommitID,
+		GitCommitTime:    codeCommitTime,
 		ImageURL:         imageURL,
 		LastConfigCommit: configCommit.Master,
 		ConfigCommit:     configCommit.Gitops,
@@ -181,15 +192,20 @@ func (c *controller) Deploy(ctx context.Context, clusterID uint,
 	if err != nil {
 		return nil, err
 	}
+	if pending {
+		return &PipelinerunIDResponse{
+			PipelinerunID: prCreated.ID,
+		}, nil
+	}
 
-	// 3. generate a JWT token for tekton callback
+	// 3. generate a JWT token for the callbacks of the ci engine
 	token, err := c.tokenSvc.CreateJWTToken(strconv.Itoa(int(currentUser.GetID())),
 		c.tokenConfig.CallbackTokenExpireIn, tokensvc.WithPipelinerunID(prCreated.ID))
 	if err != nil {
 		return nil, err
 	}
 
-	// 4. create pipelinerun in k8s
+	// 4. create pipelinerun in the ci engine
 	prGit := tekton.PipelineRunGit{
 		URL:       cluster.GitURL,
 		Subfolder: cluster.GitSubfolder,
@@ -205,12 +221,12 @@ func (c *controller) Deploy(ctx context.Context, clusterID uint,
 	if clusterFiles.PipelineJSONBlob != nil {
 		pipelineJSONBlob = clusterFiles.PipelineJSONBlob
 	}
-	tektonClient, err := c.tektonFty.GetTekton(cluster.EnvironmentName)
+	engine, err := c.ciFty.GetEngine(cluster.EnvironmentName)
 	if err != nil {
 		return nil, err
 	}
 
-	ciEventID, err := tektonClient.CreatePipelineRun(ctx, &tekton.PipelineRun{
+	ciEventID, err := engine.CreatePipelineRun(ctx, &tekton.PipelineRun{
 		Action:           prmodels.ActionDeploy,
 		Application:      application.Name,
 		ApplicationID:    application.ID,
@@ -231,8 +247,8 @@ func (c *controller) Deploy(ctx context.Context, clusterID uint,
 		return nil, err
 	}
 
-	// update event id returned from tekton-trigger EventListener
-	log.Infof(ctx, "received event id: %s from tekton-trigger EventListener, pipelinerunID: %d",
+	// update event id returned from the ci engine
+	log.Infof(ctx, "received event id: %s from the ci engine, pipelinerunID: %d",
 		ciEventID, prCreated.ID)
 	err = c.pipelinerunMgr.UpdateCIEventIDByID(ctx, prCreated.ID, ciEventID)
 	if err != nil {
@@ -323,7 +339,7 @@ func (c *controller) Rollback(ctx context.Context,
 	}
 
 	// 3. create record
-	prCreated, err := c.pipelinerunMgr.Create(ctx, &prmodels.Pipelinerun{
+	prCreated, pending, err := c.createPipelinerun(ctx, cluster, &prmodels.Pipelinerun{
 		ClusterID:        clusterID,
 		Action:           prmodels.ActionRollback,
 		Status:           string(prmodels.StatusCreated),
@@ -332,6 +348,7 @@ func (c *controller) Rollback(ctx context.Context,
 		GitRefType:       pipelinerun.GitRefType,
 		GitRef:           pipelinerun.GitRef,
 		GitCommit:        pipelinerun.GitCommit,
+		GitCommitTime:    pipelinerun.GitCommitTime,
 		ImageURL:         pipelinerun.ImageURL,
 		LastConfigCommit: lastConfigCommit.Master,
 		ConfigCommit:     lastConfigCommit.Master,
@@ -340,6 +357,11 @@ func (c *controller) Rollback(ctx context.Context,
 	if err != nil {
 		return nil, err
 	}
+	if pending {
+		return &PipelinerunIDResponse{
+			PipelinerunID: prCreated.ID,
+		}, nil
+	}
 
 	// Deprecated: for internal usage
 	err = c.checkAndSyncGitOpsBranch(ctx, application.Name, cluster.Name, pipelinerun.ConfigCommit)
@@ -378,6 +400,10 @@ func (c *controller) Rollback(ctx context.Context,
 	if err != nil {
 		return nil, err
 	}
+	tr, err := c.templateReleaseMgr.GetByTemplateNameAndRelease(ctx, cluster.Template, cluster.TemplateRelease)
+	if err != nil {
+		return nil, err
+	}
 
 	// 7. create cluster in cd system
 	regionEntity, err := c.regionMgr.GetRegionEntity(ctx, cluster.RegionName)
@@ -396,6 +422,7 @@ func (c *controller) Rollback(ctx context.Context,
 		ValueFiles:   repoInfo.ValueFiles,
 		RegionEntity: regionEntity,
 		Namespace:    envValue.Namespace,
+		TemplateKind: tr.Kind,
 	}); err != nil {
 		return nil, err
 	}
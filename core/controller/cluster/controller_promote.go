@@ -0,0 +1,417 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+
+	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/pkg/cd"
+	codemodels "github.com/horizoncd/horizon/pkg/cluster/code"
+	"github.com/horizoncd/horizon/pkg/cluster/gitrepo"
+	cmodels "github.com/horizoncd/horizon/pkg/cluster/models"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	eventmodels "github.com/horizoncd/horizon/pkg/event/models"
+	prmodels "github.com/horizoncd/horizon/pkg/pipelinerun/models"
+	"github.com/horizoncd/horizon/pkg/util/log"
+	"github.com/horizoncd/horizon/pkg/util/wlog"
+)
+
+const _valueKeySeparator = "."
+
+type promotedExtra struct {
+	SourceClusterID     uint `json:"sourceClusterID"`
+	SourcePipelinerunID uint `json:"sourcePipelinerunID"`
+}
+
+func (c *controller) PromoteCluster(ctx context.Context, clusterID uint,
+	r *PromoteRequest) (_ *PipelinerunIDResponse, err error) {
+	const op = "cluster controller: promote cluster"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	// 1. get source and target cluster, and check the promotion path
+	cluster, err := c.clusterMgr.GetByID(ctx, clusterID)
+	if err != nil {
+		return nil, err
+	}
+	sourceCluster, err := c.clusterMgr.GetByID(ctx, r.SourceClusterID)
+	if err != nil {
+		return nil, err
+	}
+	if sourceCluster.ApplicationID != cluster.ApplicationID {
+		return nil, perror.Wrapf(herrors.ErrParamInvalid,
+			"cluster %v and cluster %v belong to different applications", r.SourceClusterID, clusterID)
+	}
+	if sourceCluster.Template != cluster.Template {
+		return nil, perror.Wrapf(herrors.ErrParamInvalid,
+			"cannot promote from template %s to template %s", sourceCluster.Template, cluster.Template)
+	}
+	application, err := c.applicationMgr.GetByID(ctx, cluster.ApplicationID)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.checkPromotionPath(ctx, application.ID,
+		sourceCluster.EnvironmentName, cluster.EnvironmentName); err != nil {
+		return nil, err
+	}
+
+	// 2. get the pipelinerun to promote
+	sourcePipelinerun, err := c.getPipelinerunToPromote(ctx, sourceCluster, r.PipelinerunID)
+	if err != nil {
+		return nil, err
+	}
+
+	// 3. assemble config of target cluster from source cluster
+	tr, err := c.templateReleaseMgr.GetByTemplateNameAndRelease(ctx,
+		sourceCluster.Template, sourceCluster.TemplateRelease)
+	if err != nil {
+		return nil, err
+	}
+	sourceFiles, err := c.clusterGitRepo.GetCluster(ctx, application.Name, sourceCluster.Name, sourceCluster.Template)
+	if err != nil {
+		return nil, err
+	}
+	files, err := c.clusterGitRepo.GetCluster(ctx, application.Name, cluster.Name, cluster.Template)
+	if err != nil {
+		return nil, err
+	}
+	if files.Manifest == nil {
+		return nil, perror.Wrapf(herrors.ErrParamInvalid, "git repo %s not support v2 interface", cluster.Name)
+	}
+	templateConfig, err := promoteValues(files.ApplicationJSONBlob,
+		sourceFiles.ApplicationJSONBlob, r.TemplateConfigKeys)
+	if err != nil {
+		return nil, err
+	}
+	buildConfig := files.PipelineJSONBlob
+	if len(r.BuildConfigKeys) > 0 {
+		buildConfig, err = promoteValues(files.PipelineJSONBlob, sourceFiles.PipelineJSONBlob, r.BuildConfigKeys)
+		if err != nil {
+			return nil, err
+		}
+	}
+	renderValues, err := c.getRenderValueFromTag(ctx, cluster.ID)
+	if err != nil {
+		return nil, err
+	}
+	info := BuildTemplateInfo{
+		BuildConfig: buildConfig,
+		TemplateInfo: &codemodels.TemplateInfo{
+			Name:    sourceCluster.Template,
+			Release: sourceCluster.TemplateRelease,
+		},
+		TemplateConfig: templateConfig,
+	}
+	if err := info.Validate(ctx, c.templateSchemaGetter, renderValues, c.buildSchema); err != nil {
+		return nil, err
+	}
+
+	// 4. create pipelinerun record
+	lastConfigCommit, err := c.clusterGitRepo.GetConfigCommit(ctx, application.Name, cluster.Name)
+	if err != nil {
+		return nil, err
+	}
+	title := r.Title
+	if title == "" {
+		title = prmodels.ActionPromote
+	}
+	prCreated, pending, err := c.createPipelinerun(ctx, cluster, &prmodels.Pipelinerun{
+		ClusterID:        clusterID,
+		Action:           prmodels.ActionPromote,
+		Status:           string(prmodels.StatusCreated),
+		Title:            title,
+		Description:      r.Description,
+		GitURL:           sourcePipelinerun.GitURL,
+		GitRefType:       sourcePipelinerun.GitRefType,
+		GitRef:           sourcePipelinerun.GitRef,
+		GitCommit:        sourcePipelinerun.GitCommit,
+		GitCommitTime:    sourcePipelinerun.GitCommitTime,
+		ImageURL:         sourcePipelinerun.ImageURL,
+		LastConfigCommit: lastConfigCommit.Master,
+		ConfigCommit:     lastConfigCommit.Master,
+		PromoteFrom:      &sourcePipelinerun.ID,
+		Request:          marshalRequest(ctx, r),
+	})
+	if err != nil {
+		return nil, err
+	}
+	if pending {
+		return &PipelinerunIDResponse{PipelinerunID: prCreated.ID}, nil
+	}
+
+	// 5. write config and image to gitops branch, and update status
+	if err := c.clusterGitRepo.UpdateCluster(ctx, &gitrepo.UpdateClusterParams{
+		BaseParams: &gitrepo.BaseParams{
+			ClusterID:           cluster.ID,
+			Cluster:             cluster.Name,
+			PipelineJSONBlob:    buildConfig,
+			ApplicationJSONBlob: templateConfig,
+			TemplateRelease:     tr,
+			Application:         application,
+			Environment:         cluster.EnvironmentName,
+			Version:             common.MetaVersion2,
+		}}); err != nil {
+		return nil, err
+	}
+	commit, err := c.clusterGitRepo.UpdatePipelineOutput(ctx, application.Name, cluster.Name,
+		tr.ChartName, ofPipelineOutput(sourcePipelinerun))
+	if err != nil {
+		return nil, err
+	}
+	if err := c.updatePipelineRunStatus(ctx, prmodels.ActionPromote, prCreated.ID, prmodels.StatusCommitted,
+		commit); err != nil {
+		return nil, err
+	}
+
+	// 6. merge branch & update config commit and status
+	masterRevision, err := c.clusterGitRepo.MergeBranch(ctx, application.Name, cluster.Name,
+		gitrepo.GitOpsBranch, c.clusterGitRepo.DefaultBranch(), &prCreated.ID)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.pipelinerunMgr.UpdateConfigCommitByID(ctx, prCreated.ID, masterRevision); err != nil {
+		log.Errorf(ctx, "UpdateConfigCommitByID error, pr = %d, commit = %s, err = %v",
+			prCreated.ID, masterRevision, err)
+	}
+	if err := c.updatePipelineRunStatus(ctx, prmodels.ActionPromote, prCreated.ID, prmodels.StatusMerged,
+		masterRevision); err != nil {
+		return nil, err
+	}
+
+	// 7. update template release and image in db
+	cluster.TemplateRelease = sourceCluster.TemplateRelease
+	if cluster.GitURL == "" && cluster.Image != "" {
+		cluster.Image = sourcePipelinerun.ImageURL
+	}
+	if cluster.Status == common.ClusterStatusFreed {
+		cluster.Status = common.ClusterStatusEmpty
+	}
+	cluster, err = c.clusterMgr.UpdateByID(ctx, cluster.ID, cluster)
+	if err != nil {
+		return nil, err
+	}
+
+	// 8. create cluster in cd system
+	regionEntity, err := c.regionMgr.GetRegionEntity(ctx, cluster.RegionName)
+	if err != nil {
+		return nil, err
+	}
+	envValue, err := c.clusterGitRepo.GetEnvValue(ctx, application.Name, cluster.Name, tr.ChartName)
+	if err != nil {
+		return nil, err
+	}
+	repoInfo := c.clusterGitRepo.GetRepoInfo(ctx, application.Name, cluster.Name)
+	if err := c.cd.CreateCluster(ctx, &cd.CreateClusterParams{
+		Environment:  cluster.EnvironmentName,
+		Cluster:      cluster.Name,
+		GitRepoURL:   repoInfo.GitRepoURL,
+		ValueFiles:   repoInfo.ValueFiles,
+		RegionEntity: regionEntity,
+		Namespace:    envValue.Namespace,
+		TemplateKind: tr.Kind,
+	}); err != nil {
+		return nil, err
+	}
+
+	// 9. deploy cluster in cd and update status
+	if err := c.cd.DeployCluster(ctx, &cd.DeployClusterParams{
+		Environment: cluster.EnvironmentName,
+		Cluster:     cluster.Name,
+		Revision:    masterRevision,
+	}); err != nil {
+		return nil, err
+	}
+	if err := c.updatePipelineRunStatus(ctx,
+		prmodels.ActionPromote, prCreated.ID, prmodels.StatusOK, masterRevision); err != nil {
+		return nil, err
+	}
+
+	// 10. record event
+	extraBytes, err := json.Marshal(promotedExtra{
+		SourceClusterID:     sourceCluster.ID,
+		SourcePipelinerunID: sourcePipelinerun.ID,
+	})
+	if err != nil {
+		log.Warningf(ctx, "failed to marshal event extra: %v", err.Error())
+	}
+	extra := string(extraBytes)
+	if _, err := c.eventMgr.CreateEvent(ctx, &eventmodels.Event{
+		EventSummary: eventmodels.EventSummary{
+			ResourceType: common.ResourceCluster,
+			EventType:    eventmodels.ClusterPromoted,
+			ResourceID:   cluster.ID,
+			Extra:        &extra,
+		},
+	}); err != nil {
+		log.Warningf(ctx, "failed to create event, err: %s", err.Error())
+	}
+
+	return &PipelinerunIDResponse{
+		PipelinerunID: prCreated.ID,
+	}, nil
+}
+
+// checkPromotionPath checks that target environment follows source environment in the promotion path
+// of the application, the ordered environment list is used if the application has not configured one
+func (c *controller) checkPromotionPath(ctx context.Context, applicationID uint,
+	sourceEnv, targetEnv string) error {
+	var stages []string
+	path, err := c.promotionPathMgr.GetByApplicationID(ctx, applicationID)
+	if err != nil {
+		if _, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); !ok {
+			return err
+		}
+		environments, err := c.envMgr.ListAllEnvironment(ctx)
+		if err != nil {
+			return err
+		}
+		for _, env := range environments {
+			stages = append(stages, env.Name)
+		}
+	} else {
+		stages = path.Stages()
+	}
+
+	for i := 0; i+1 < len(stages); i++ {
+		if stages[i] == sourceEnv && stages[i+1] == targetEnv {
+			return nil
+		}
+	}
+	return perror.Wrapf(herrors.ErrParamInvalid,
+		"environment %s is not the next stage of environment %s in the promotion path", targetEnv, sourceEnv)
+}
+
+func (c *controller) getPipelinerunToPromote(ctx context.Context,
+	sourceCluster *cmodels.Cluster, pipelinerunID uint) (*prmodels.Pipelinerun, error) {
+	var (
+		pipelinerun *prmodels.Pipelinerun
+		err         error
+	)
+	if pipelinerunID == 0 {
+		pipelinerun, err = c.pipelinerunMgr.GetFirstCanRollbackPipelinerun(ctx, sourceCluster.ID)
+		if err != nil {
+			return nil, err
+		}
+		if pipelinerun == nil {
+			return nil, perror.Wrapf(herrors.ErrParamInvalid,
+				"cluster %v has no successful pipelinerun to promote", sourceCluster.ID)
+		}
+	} else {
+		pipelinerun, err = c.pipelinerunMgr.GetByID(ctx, pipelinerunID)
+		if err != nil {
+			return nil, err
+		}
+		if pipelinerun.ClusterID != sourceCluster.ID {
+			return nil, perror.Wrapf(herrors.ErrParamInvalid,
+				"the pipelinerun with id: %v is not belongs to cluster: %v", pipelinerunID, sourceCluster.ID)
+		}
+		if pipelinerun.Action == prmodels.ActionRestart || pipelinerun.Status != string(prmodels.StatusOK) {
+			return nil, perror.Wrapf(herrors.ErrParamInvalid,
+				"the pipelinerun with id: %v can not be promoted", pipelinerunID)
+		}
+	}
+	if pipelinerun.ImageURL == "" {
+		return nil, perror.Wrapf(herrors.ErrParamInvalid,
+			"the pipelinerun with id: %v has no image to promote", pipelinerun.ID)
+	}
+	return pipelinerun, nil
+}
+
+func ofPipelineOutput(pr *prmodels.Pipelinerun) *gitrepo.PipelineOutput {
+	output := &gitrepo.PipelineOutput{
+		Image: &pr.ImageURL,
+	}
+	if pr.GitURL != "" {
+		output.Git = &gitrepo.Git{
+			URL:      &pr.GitURL,
+			CommitID: &pr.GitCommit,
+		}
+		switch pr.GitRefType {
+		case codemodels.GitRefTypeTag:
+			output.Git.Tag = &pr.GitRef
+		case codemodels.GitRefTypeBranch:
+			output.Git.Branch = &pr.GitRef
+		}
+	}
+	return output
+}
+
+// promoteValues returns a copy of target in which the values of keys are replaced by the ones in source,
+// keys absent in source are removed from target
+func promoteValues(target, source map[string]interface{}, keys []string) (map[string]interface{}, error) {
+	ret := make(map[string]interface{})
+	if target != nil {
+		bts, err := json.Marshal(target)
+		if err != nil {
+			return nil, perror.Wrap(herrors.ErrParamInvalid, err.Error())
+		}
+		if err := json.Unmarshal(bts, &ret); err != nil {
+			return nil, perror.Wrap(herrors.ErrParamInvalid, err.Error())
+		}
+	}
+
+	for _, key := range keys {
+		parts := strings.Split(key, _valueKeySeparator)
+		value, found := lookupValue(source, parts)
+		if !found {
+			deleteValue(ret, parts)
+			continue
+		}
+
+		parent := ret
+		for _, part := range parts[:len(parts)-1] {
+			child, ok := parent[part]
+			if !ok {
+				child = make(map[string]interface{})
+				parent[part] = child
+			}
+			childMap, ok := child.(map[string]interface{})
+			if !ok {
+				return nil, perror.Wrapf(herrors.ErrParamInvalid, "value of %s is not an object", part)
+			}
+			parent = childMap
+		}
+		parent[parts[len(parts)-1]] = value
+	}
+	return ret, nil
+}
+
+func lookupValue(values map[string]interface{}, parts []string) (interface{}, bool) {
+	var current interface{} = values
+	for _, part := range parts {
+		m, ok := current.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		current, ok = m[part]
+		if !ok {
+			return nil, false
+		}
+	}
+	return current, true
+}
+
+func deleteValue(values map[string]interface{}, parts []string) {
+	parent, ok := lookupValue(values, parts[:len(parts)-1])
+	if !ok {
+		return
+	}
+	if m, ok := parent.(map[string]interface{}); ok {
+		delete(m, parts[len(parts)-1])
+	}
+}
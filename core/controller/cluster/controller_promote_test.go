@@ -0,0 +1,418 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	cdmock "github.com/horizoncd/horizon/mock/pkg/cd"
+	clustergitrepomock "github.com/horizoncd/horizon/mock/pkg/cluster/gitrepo"
+	trschemamock "github.com/horizoncd/horizon/mock/pkg/templaterelease/schema"
+	appmodels "github.com/horizoncd/horizon/pkg/application/models"
+	userauth "github.com/horizoncd/horizon/pkg/authentication/user"
+	"github.com/horizoncd/horizon/pkg/cluster/gitrepo"
+	"github.com/horizoncd/horizon/pkg/cluster/models"
+	runqueueconfig "github.com/horizoncd/horizon/pkg/config/runqueue"
+	envmodels "github.com/horizoncd/horizon/pkg/environment/models"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	groupmodels "github.com/horizoncd/horizon/pkg/group/models"
+	prmodels "github.com/horizoncd/horizon/pkg/pipelinerun/models"
+	promotionmodels "github.com/horizoncd/horizon/pkg/promotion/models"
+	regionmodels "github.com/horizoncd/horizon/pkg/region/models"
+	registrydao "github.com/horizoncd/horizon/pkg/registry/dao"
+	registrymodels "github.com/horizoncd/horizon/pkg/registry/models"
+	runqueuemodels "github.com/horizoncd/horizon/pkg/runqueue/models"
+	trmodels "github.com/horizoncd/horizon/pkg/templaterelease/models"
+	trschema "github.com/horizoncd/horizon/pkg/templaterelease/schema"
+	"github.com/stretchr/testify/assert"
+)
+
+func testPromoteValues(t *testing.T) {
+	target := map[string]interface{}{
+		"app": map[string]interface{}{
+			"spec": map[string]interface{}{
+				"replicas": 1,
+				"resource": "small",
+			},
+			"envs": []interface{}{"a"},
+		},
+	}
+	source := map[string]interface{}{
+		"app": map[string]interface{}{
+			"spec": map[string]interface{}{
+				"replicas": 3,
+				"resource": "large",
+			},
+			"health": map[string]interface{}{
+				"port": 8080,
+			},
+		},
+	}
+
+	values, err := promoteValues(target, source, []string{"app.spec.replicas", "app.health", "app.envs"})
+	assert.Nil(t, err)
+	app := values["app"].(map[string]interface{})
+	assert.Equal(t, 3, app["spec"].(map[string]interface{})["replicas"])
+	assert.Equal(t, "small", app["spec"].(map[string]interface{})["resource"])
+	assert.Equal(t, map[string]interface{}{"port": 8080}, app["health"])
+	_, ok := app["envs"]
+	assert.False(t, ok)
+	// target should not be modified
+	assert.Equal(t, 1, target["app"].(map[string]interface{})["spec"].(map[string]interface{})["replicas"])
+
+	_, err = promoteValues(target, source, []string{"app.envs.x"})
+	assert.Nil(t, err)
+	_, err = promoteValues(map[string]interface{}{"app": "x"}, source, []string{"app.spec"})
+	assert.Equal(t, herrors.ErrParamInvalid, perror.Cause(err))
+}
+
+func testPromoteCluster(t *testing.T) {
+	templateName := "promoteapp"
+	applicationName := "app-promote"
+	mockCtl := gomock.NewController(t)
+	clusterGitRepo := clustergitrepomock.NewMockClusterGitRepo(mockCtl)
+	templateSchemaGetter := trschemamock.NewMockGetter(mockCtl)
+	mockCd := cdmock.NewMockCD(mockCtl)
+
+	registryID, err := registrydao.NewDAO(db).Create(ctx, &registrymodels.Registry{
+		Server: "https://harbor.com",
+	})
+	assert.Nil(t, err)
+	_, err = manager.RegionMgr.Create(ctx, &regionmodels.Region{
+		Name:       "promote-hz",
+		RegistryID: registryID,
+	})
+	assert.Nil(t, err)
+	for _, env := range []string{"promote-test", "promote-online"} {
+		_, err = manager.EnvMgr.CreateEnvironment(ctx, &envmodels.Environment{Name: env})
+		assert.Nil(t, err)
+	}
+	for _, release := range []string{"v1.0.0", "v1.0.1"} {
+		_, err = manager.TemplateReleaseManager.Create(ctx, &trmodels.TemplateRelease{
+			TemplateName: templateName,
+			Name:         release,
+			ChartName:    templateName,
+		})
+		assert.Nil(t, err)
+	}
+	group, err := manager.GroupManager.Create(ctx, &groupmodels.Group{
+		Name: "promote-group",
+		Path: "promote-group",
+	})
+	assert.Nil(t, err)
+	application, err := manager.ApplicationManager.Create(ctx, &appmodels.Application{
+		GroupID:         group.ID,
+		Name:            applicationName,
+		Template:        templateName,
+		TemplateRelease: "v1.0.1",
+	}, nil)
+	assert.Nil(t, err)
+
+	sourceCluster, err := manager.ClusterMgr.Create(ctx, &models.Cluster{
+		ApplicationID:   application.ID,
+		Name:            "app-promote-test",
+		EnvironmentName: "promote-test",
+		RegionName:      "promote-hz",
+		GitURL:          "ssh://git.com",
+		GitRefType:      "branch",
+		GitRef:          "master",
+		Template:        templateName,
+		TemplateRelease: "v1.0.1",
+	}, nil, nil)
+	assert.Nil(t, err)
+	cluster, err := manager.ClusterMgr.Create(ctx, &models.Cluster{
+		ApplicationID:   application.ID,
+		Name:            "app-promote-online",
+		EnvironmentName: "promote-online",
+		RegionName:      "promote-hz",
+		GitURL:          "ssh://git.com",
+		GitRefType:      "branch",
+		GitRef:          "master",
+		Template:        templateName,
+		TemplateRelease: "v1.0.0",
+	}, nil, nil)
+	assert.Nil(t, err)
+
+	sourcePR, err := manager.PipelinerunMgr.Create(ctx, &prmodels.Pipelinerun{
+		ClusterID:    sourceCluster.ID,
+		Action:       prmodels.ActionBuildDeploy,
+		Status:       string(prmodels.StatusOK),
+		GitURL:       "ssh://git.com",
+		GitRefType:   "branch",
+		GitRef:       "master",
+		GitCommit:    "117651f0c06486ba50a01eb0ed82be46ef3b528e",
+		ImageURL:     "harbor.com/app-promote/app-promote-test:master-117651f0",
+		ConfigCommit: "config-commit",
+	})
+	assert.Nil(t, err)
+
+	c = &controller{
+		clusterMgr:           manager.ClusterMgr,
+		clusterGitRepo:       clusterGitRepo,
+		applicationMgr:       manager.ApplicationManager,
+		templateReleaseMgr:   manager.TemplateReleaseManager,
+		templateSchemaGetter: templateSchemaGetter,
+		envMgr:               manager.EnvMgr,
+		regionMgr:            manager.RegionMgr,
+		pipelinerunMgr:       manager.PipelinerunMgr,
+		schemaTagManager:     manager.ClusterSchemaTagMgr,
+		cd:                   mockCd,
+		eventMgr:             manager.EventManager,
+		promotionPathMgr:     manager.PromotionPathMgr,
+	}
+
+	// online is not the next stage of test in the default path
+	_, err = c.PromoteCluster(ctx, sourceCluster.ID, &PromoteRequest{SourceClusterID: cluster.ID})
+	assert.Equal(t, herrors.ErrParamInvalid, perror.Cause(err))
+
+	err = manager.PromotionPathMgr.UpsertByApplicationID(ctx, &promotionmodels.PromotionPath{
+		ApplicationID: application.ID,
+		Environments:  promotionmodels.JoinEnvironments([]string{"promote-test", "promote-online"}),
+	})
+	assert.Nil(t, err)
+
+	// copy of applicationJSONBlob with more replicas
+	sourceTemplateConfig, err := promoteValues(applicationJSONBlob, nil, nil)
+	assert.Nil(t, err)
+	sourceTemplateConfig["app"].(map[string]interface{})["spec"].(map[string]interface{})["replicas"] = 3
+	clusterGitRepo.EXPECT().GetCluster(ctx, applicationName, sourceCluster.Name, templateName).
+		Return(&gitrepo.ClusterFiles{
+			PipelineJSONBlob:    pipelineJSONBlob,
+			ApplicationJSONBlob: sourceTemplateConfig,
+		}, nil).Times(1)
+	clusterGitRepo.EXPECT().GetCluster(ctx, applicationName, cluster.Name, templateName).
+		Return(&gitrepo.ClusterFiles{
+			PipelineJSONBlob:    pipelineJSONBlob,
+			ApplicationJSONBlob: applicationJSONBlob,
+			Manifest:            map[string]interface{}{"version": "0.0.2"},
+		}, nil).Times(1)
+	templateSchemaGetter.EXPECT().GetTemplateSchema(gomock.Any(), templateName, "v1.0.1", gomock.Any()).
+		Return(&trschema.Schemas{
+			Application: &trschema.Schema{JSONSchema: applicationSchema},
+			Pipeline:    &trschema.Schema{JSONSchema: pipelineSchema},
+		}, nil).Times(1)
+	clusterGitRepo.EXPECT().GetConfigCommit(ctx, applicationName, cluster.Name).
+		Return(&gitrepo.ClusterCommit{Master: "master", Gitops: "gitops"}, nil).Times(1)
+	clusterGitRepo.EXPECT().UpdateCluster(ctx, gomock.Any()).
+		DoAndReturn(func(_ interface{}, params *gitrepo.UpdateClusterParams) error {
+			assert.Equal(t, "v1.0.1", params.TemplateRelease.Name)
+			spec := params.ApplicationJSONBlob["app"].(map[string]interface{})["spec"].(map[string]interface{})
+			assert.Equal(t, 3, spec["replicas"])
+			return nil
+		}).Times(1)
+	clusterGitRepo.EXPECT().UpdatePipelineOutput(ctx, applicationName, cluster.Name, templateName, gomock.Any()).
+		DoAndReturn(func(_ interface{}, _, _, _ string, output interface{}) (string, error) {
+			assert.Equal(t, sourcePR.ImageURL, *output.(*gitrepo.PipelineOutput).Image)
+			assert.Equal(t, "master", *output.(*gitrepo.PipelineOutput).Git.Branch)
+			return "commit", nil
+		}).Times(1)
+	clusterGitRepo.EXPECT().DefaultBranch().Return("master").Times(1)
+	clusterGitRepo.EXPECT().MergeBranch(ctx, applicationName, cluster.Name, gitrepo.GitOpsBranch,
+		"master", gomock.Any()).Return("merged", nil).Times(1)
+	clusterGitRepo.EXPECT().GetEnvValue(ctx, applicationName, cluster.Name, templateName).
+		Return(&gitrepo.EnvValue{Namespace: "ns"}, nil).Times(1)
+	clusterGitRepo.EXPECT().GetRepoInfo(ctx, applicationName, cluster.Name).
+		Return(&gitrepo.RepoInfo{}).Times(1)
+	mockCd.EXPECT().CreateCluster(ctx, gomock.Any()).Return(nil).Times(1)
+	mockCd.EXPECT().DeployCluster(ctx, gomock.Any()).Return(nil).Times(1)
+
+	resp, err := c.PromoteCluster(ctx, cluster.ID, &PromoteRequest{
+		SourceClusterID:    sourceCluster.ID,
+		TemplateConfigKeys: []string{"app.spec.replicas"},
+	})
+	assert.Nil(t, err)
+
+	pr, err := manager.PipelinerunMgr.GetByID(ctx, resp.PipelinerunID)
+	assert.Nil(t, err)
+	assert.Equal(t, prmodels.ActionPromote, pr.Action)
+	assert.Equal(t, string(prmodels.StatusOK), pr.Status)
+	assert.Equal(t, sourcePR.ID, *pr.PromoteFrom)
+	assert.Equal(t, sourcePR.ImageURL, pr.ImageURL)
+	assert.Equal(t, "merged", pr.ConfigCommit)
+
+	cluster, err = manager.ClusterMgr.GetByID(ctx, cluster.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, "v1.0.1", cluster.TemplateRelease)
+
+	// promotion to a protected environment is pending until approved
+	onlineEnv, err := manager.EnvMgr.GetByName(ctx, "promote-online")
+	assert.Nil(t, err)
+	onlineEnv.Protected = true
+	onlineEnv.Approvers = "approver"
+	assert.Nil(t, manager.EnvMgr.UpdateByID(ctx, onlineEnv.ID, onlineEnv))
+
+	clusterGitRepo.EXPECT().GetCluster(gomock.Any(), applicationName, sourceCluster.Name, templateName).
+		Return(&gitrepo.ClusterFiles{
+			PipelineJSONBlob:    pipelineJSONBlob,
+			ApplicationJSONBlob: sourceTemplateConfig,
+		}, nil).Times(2)
+	clusterGitRepo.EXPECT().GetCluster(gomock.Any(), applicationName, cluster.Name, templateName).
+		Return(&gitrepo.ClusterFiles{
+			PipelineJSONBlob:    pipelineJSONBlob,
+			ApplicationJSONBlob: applicationJSONBlob,
+			Manifest:            map[string]interface{}{"version": "0.0.2"},
+		}, nil).Times(2)
+	templateSchemaGetter.EXPECT().GetTemplateSchema(gomock.Any(), templateName, "v1.0.1", gomock.Any()).
+		Return(&trschema.Schemas{
+			Application: &trschema.Schema{JSONSchema: applicationSchema},
+			Pipeline:    &trschema.Schema{JSONSchema: pipelineSchema},
+		}, nil).Times(2)
+	clusterGitRepo.EXPECT().GetConfigCommit(gomock.Any(), applicationName, cluster.Name).
+		Return(&gitrepo.ClusterCommit{Master: "merged", Gitops: "merged"}, nil).Times(2)
+
+	resp, err = c.PromoteCluster(ctx, cluster.ID, &PromoteRequest{
+		Title:              "promote-protected",
+		SourceClusterID:    sourceCluster.ID,
+		TemplateConfigKeys: []string{"app.spec.replicas"},
+	})
+	assert.Nil(t, err)
+	pr, err = manager.PipelinerunMgr.GetByID(ctx, resp.PipelinerunID)
+	assert.Nil(t, err)
+	assert.Equal(t, string(prmodels.StatusPending), pr.Status)
+	assert.Equal(t, sourcePR.ID, *pr.PromoteFrom)
+
+	// the source cluster is deployed again before the approval, but the requested pipelinerun is promoted
+	_, err = manager.PipelinerunMgr.Create(ctx, &prmodels.Pipelinerun{
+		ClusterID:  sourceCluster.ID,
+		Action:     prmodels.ActionBuildDeploy,
+		Status:     string(prmodels.StatusOK),
+		GitURL:     "ssh://git.com",
+		GitRefType: "branch",
+		GitRef:     "master",
+		ImageURL:   "harbor.com/app-promote/app-promote-test:master-newer",
+	})
+	assert.Nil(t, err)
+	clusterGitRepo.EXPECT().UpdateCluster(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ interface{}, params *gitrepo.UpdateClusterParams) error {
+			spec := params.ApplicationJSONBlob["app"].(map[string]interface{})["spec"].(map[string]interface{})
+			assert.Equal(t, 3, spec["replicas"])
+			return nil
+		}).Times(1)
+	clusterGitRepo.EXPECT().UpdatePipelineOutput(gomock.Any(), applicationName, cluster.Name,
+		templateName, gomock.Any()).
+		DoAndReturn(func(_ interface{}, _, _, _ string, output interface{}) (string, error) {
+			assert.Equal(t, sourcePR.ImageURL, *output.(*gitrepo.PipelineOutput).Image)
+			return "commit-approved", nil
+		}).Times(1)
+	clusterGitRepo.EXPECT().DefaultBranch().Return("master").Times(1)
+	clusterGitRepo.EXPECT().MergeBranch(gomock.Any(), applicationName, cluster.Name, gitrepo.GitOpsBranch,
+		"master", gomock.Any()).Return("merged-approved", nil).Times(1)
+	clusterGitRepo.EXPECT().GetEnvValue(gomock.Any(), applicationName, cluster.Name, templateName).
+		Return(&gitrepo.EnvValue{Namespace: "ns"}, nil).Times(1)
+	clusterGitRepo.EXPECT().GetRepoInfo(gomock.Any(), applicationName, cluster.Name).
+		Return(&gitrepo.RepoInfo{}).Times(1)
+	mockCd.EXPECT().CreateCluster(gomock.Any(), gomock.Any()).Return(nil).Times(1)
+	mockCd.EXPECT().DeployCluster(gomock.Any(), gomock.Any()).Return(nil).Times(1)
+
+	approverCtx := context.WithValue(ctx, common.UserContextKey(), &userauth.DefaultInfo{
+		Name: "approver",
+		ID:   uint(3),
+	})
+	approvedResp, err := c.ApprovePipelinerun(approverCtx, pr.ID, &ReviewPipelinerunRequest{})
+	assert.Nil(t, err)
+	assert.Equal(t, pr.ID, approvedResp.PipelinerunID)
+	pr, err = manager.PipelinerunMgr.GetByID(ctx, pr.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, string(prmodels.StatusOK), pr.Status)
+	assert.Equal(t, "promote-protected", pr.Title)
+	assert.Equal(t, sourcePR.ImageURL, pr.ImageURL)
+	assert.Equal(t, "merged-approved", pr.ConfigCommit)
+
+	// promotion arriving while a deploy is running on the cluster is queued
+	onlineEnv.Protected = false
+	onlineEnv.Approvers = ""
+	assert.Nil(t, manager.EnvMgr.UpdateByID(ctx, onlineEnv.ID, onlineEnv))
+	c.runQueueMgr = manager.RunQueueMgr
+	c.runQueueConfig = runqueueconfig.Config{
+		DefaultPolicy: runqueuemodels.PolicyParallel,
+		LockTimeout:   time.Hour,
+	}
+	_, err = manager.RunQueueMgr.UpsertPolicy(ctx, &runqueuemodels.RunQueue{
+		ClusterID: cluster.ID,
+		Policy:    runqueuemodels.PolicySerialize,
+	})
+	assert.Nil(t, err)
+	holder, err := manager.PipelinerunMgr.Create(ctx, &prmodels.Pipelinerun{
+		ClusterID: cluster.ID,
+		Action:    prmodels.ActionDeploy,
+		Status:    string(prmodels.StatusCreated),
+	})
+	assert.Nil(t, err)
+	held, err := manager.RunQueueMgr.Transfer(ctx, cluster.ID, 0, holder.ID, time.Now())
+	assert.Nil(t, err)
+	assert.True(t, held)
+
+	clusterGitRepo.EXPECT().GetCluster(gomock.Any(), applicationName, sourceCluster.Name, templateName).
+		Return(&gitrepo.ClusterFiles{
+			PipelineJSONBlob:    pipelineJSONBlob,
+			ApplicationJSONBlob: sourceTemplateConfig,
+		}, nil).Times(2)
+	clusterGitRepo.EXPECT().GetCluster(gomock.Any(), applicationName, cluster.Name, templateName).
+		Return(&gitrepo.ClusterFiles{
+			PipelineJSONBlob:    pipelineJSONBlob,
+			ApplicationJSONBlob: applicationJSONBlob,
+			Manifest:            map[string]interface{}{"version": "0.0.2"},
+		}, nil).Times(2)
+	templateSchemaGetter.EXPECT().GetTemplateSchema(gomock.Any(), templateName, "v1.0.1", gomock.Any()).
+		Return(&trschema.Schemas{
+			Application: &trschema.Schema{JSONSchema: applicationSchema},
+			Pipeline:    &trschema.Schema{JSONSchema: pipelineSchema},
+		}, nil).Times(2)
+	clusterGitRepo.EXPECT().GetConfigCommit(gomock.Any(), applicationName, cluster.Name).
+		Return(&gitrepo.ClusterCommit{Master: "merged-approved", Gitops: "merged-approved"}, nil).Times(2)
+	resp, err = c.PromoteCluster(ctx, cluster.ID, &PromoteRequest{
+		Title:           "promote-queued",
+		SourceClusterID: sourceCluster.ID,
+		PipelinerunID:   sourcePR.ID,
+	})
+	assert.Nil(t, err)
+	pr, err = manager.PipelinerunMgr.GetByID(ctx, resp.PipelinerunID)
+	assert.Nil(t, err)
+	assert.Equal(t, string(prmodels.StatusQueued), pr.Status)
+
+	dequeued, err := c.DequeuePipelinerun(ctx, pr.ID)
+	assert.Nil(t, err)
+	assert.False(t, dequeued)
+
+	// the promotion is executed once the deploy finishes
+	assert.Nil(t, manager.PipelinerunMgr.UpdateStatusByID(ctx, holder.ID, prmodels.StatusOK))
+	clusterGitRepo.EXPECT().UpdateCluster(gomock.Any(), gomock.Any()).Return(nil).Times(1)
+	clusterGitRepo.EXPECT().UpdatePipelineOutput(gomock.Any(), applicationName, cluster.Name,
+		templateName, gomock.Any()).Return("commit-queued", nil).Times(1)
+	clusterGitRepo.EXPECT().DefaultBranch().Return("master").Times(1)
+	clusterGitRepo.EXPECT().MergeBranch(gomock.Any(), applicationName, cluster.Name, gitrepo.GitOpsBranch,
+		"master", gomock.Any()).Return("merged-queued", nil).Times(1)
+	clusterGitRepo.EXPECT().GetEnvValue(gomock.Any(), applicationName, cluster.Name, templateName).
+		Return(&gitrepo.EnvValue{Namespace: "ns"}, nil).Times(1)
+	clusterGitRepo.EXPECT().GetRepoInfo(gomock.Any(), applicationName, cluster.Name).
+		Return(&gitrepo.RepoInfo{}).Times(1)
+	mockCd.EXPECT().CreateCluster(gomock.Any(), gomock.Any()).Return(nil).Times(1)
+	mockCd.EXPECT().DeployCluster(gomock.Any(), gomock.Any()).Return(nil).Times(1)
+	dequeued, err = c.DequeuePipelinerun(ctx, pr.ID)
+	assert.Nil(t, err)
+	assert.True(t, dequeued)
+	pr, err = manager.PipelinerunMgr.GetByID(ctx, pr.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, string(prmodels.StatusOK), pr.Status)
+	assert.Equal(t, "merged-queued", pr.ConfigCommit)
+	q, err := manager.RunQueueMgr.GetByClusterID(ctx, cluster.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, pr.ID, q.PipelinerunID)
+}
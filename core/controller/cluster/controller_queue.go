@@ -0,0 +1,227 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import (
+	"context"
+	"time"
+
+	herrors "github.com/horizoncd/horizon/core/errors"
+	cmodels "github.com/horizoncd/horizon/pkg/cluster/models"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	prmodels "github.com/horizoncd/horizon/pkg/pipelinerun/models"
+	runqueuemodels "github.com/horizoncd/horizon/pkg/runqueue/models"
+	"github.com/horizoncd/horizon/pkg/util/log"
+	"github.com/horizoncd/horizon/pkg/util/wlog"
+)
+
+func (c *controller) DequeuePipelinerun(ctx context.Context, pipelinerunID uint) (_ bool, err error) {
+	const op = "cluster controller: dequeue pipelinerun"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	pr, err := c.pipelinerunMgr.GetByID(ctx, pipelinerunID)
+	if err != nil {
+		return false, err
+	}
+	if pr.Status != string(prmodels.StatusQueued) {
+		return false, nil
+	}
+	cluster, err := c.clusterMgr.GetByID(ctx, pr.ClusterID)
+	if err != nil {
+		if _, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); ok {
+			_, err = c.pipelinerunMgr.UpdateStatusByIDAndStatus(ctx, pr.ID,
+				prmodels.StatusQueued, prmodels.StatusCancelled)
+		}
+		return false, err
+	}
+
+	// the pipelinerun queued before a freeze window begins waits until the window ends
+	if c.freezeSvc != nil {
+		window, until, err := c.freezeSvc.GetActiveWindow(ctx, cluster.ID)
+		if err != nil {
+			return false, err
+		}
+		if window != nil {
+			log.Infof(ctx, "cluster %s is frozen by %s until %s, pipelinerun %d stays queued",
+				cluster.Name, window.Name, until.Format(time.RFC3339), pr.ID)
+			return false, nil
+		}
+	}
+
+	held, _, err := c.holdCluster(ctx, cluster.ID, pr.ID)
+	if err != nil || !held {
+		return false, err
+	}
+	started, err := c.pipelinerunMgr.UpdateStatusByIDAndStatus(ctx, pr.ID,
+		prmodels.StatusQueued, prmodels.StatusCreated)
+	if err != nil || !started {
+		// the pipelinerun is stopped while it's being dequeued
+		if _, err := c.runQueueMgr.Transfer(ctx, cluster.ID, pr.ID, 0, time.Now()); err != nil {
+			log.Errorf(ctx, "failed to release cluster %d held by pipelinerun %d, err: %v", cluster.ID, pr.ID, err)
+		}
+		return false, err
+	}
+
+	// the queued pipelinerun is reused when the operation is executed again
+	if _, err := c.executeApprovedPipelinerun(context.WithValue(ctx, approvedPipelinerunKey{}, pr),
+		cluster, pr); err != nil {
+		if err := c.pipelinerunMgr.UpdateStatusByID(ctx, pr.ID, prmodels.StatusFailed); err != nil {
+			log.Errorf(ctx, "failed to update status of pipelinerun %d, err: %v", pr.ID, err)
+		}
+		return true, err
+	}
+	return true, nil
+}
+
+// admitPipelinerun lets the pipelinerun hold the cluster to execute it according to the policy of the cluster,
+// it returns true if the pipelinerun is queued, and the caller should return without executing it
+func (c *controller) admitPipelinerun(ctx context.Context, cluster *cmodels.Cluster,
+	pr *prmodels.Pipelinerun) (bool, error) {
+	policy, err := c.getRunQueuePolicy(ctx, cluster.ID)
+	if err != nil {
+		return false, err
+	}
+	if policy == runqueuemodels.PolicyParallel {
+		return false, nil
+	}
+
+	held, holder, err := c.holdCluster(ctx, cluster.ID, pr.ID)
+	if err != nil {
+		return false, err
+	}
+	if held {
+		return false, nil
+	}
+
+	holderID := uint(0)
+	if holder != nil {
+		holderID = holder.ID
+	}
+	switch policy {
+	case runqueuemodels.PolicyReject:
+		if err := c.pipelinerunMgr.UpdateStatusByID(ctx, pr.ID, prmodels.StatusCancelled); err != nil {
+			return false, err
+		}
+		return false, perror.Wrapf(herrors.ErrClusterBusy,
+			"cluster %s is held by pipelinerun %d", cluster.Name, holderID)
+	case runqueuemodels.PolicyCancel:
+		c.cancelPipelineruns(ctx, cluster, holder, pr.ID)
+	}
+	if err := c.pipelinerunMgr.UpdateStatusByID(ctx, pr.ID, prmodels.StatusQueued); err != nil {
+		return false, err
+	}
+	pr.Status = string(prmodels.StatusQueued)
+	log.Infof(ctx, "cluster %s is held by pipelinerun %d, pipelinerun %d is queued", cluster.Name, holderID, pr.ID)
+	return true, nil
+}
+
+// holdCluster makes the cluster held by the pipelinerun, the cluster is taken over from the pipelinerun
+// holding it if that one is finished or has held the cluster for too long.
+// It returns false and the pipelinerun holding the cluster if the cluster can't be held.
+func (c *controller) holdCluster(ctx context.Context, clusterID,
+	pipelinerunID uint) (bool, *prmodels.Pipelinerun, error) {
+	now := time.Now()
+	held, err := c.runQueueMgr.Transfer(ctx, clusterID, 0, pipelinerunID, now)
+	if err != nil || held {
+		return held, nil, err
+	}
+	q, err := c.runQueueMgr.GetByClusterID(ctx, clusterID)
+	if err != nil {
+		return false, nil, err
+	}
+	if q.PipelinerunID == pipelinerunID {
+		return true, nil, nil
+	}
+
+	holder, err := c.pipelinerunMgr.GetByID(ctx, q.PipelinerunID)
+	if err != nil {
+		if _, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); !ok {
+			return false, nil, err
+		}
+		holder = nil
+	}
+	expired := q.LockedAt != nil && now.Sub(*q.LockedAt) > c.runQueueConfig.LockTimeout
+	if holder != nil && isPipelinerunInProgress(holder) && !expired {
+		return false, holder, nil
+	}
+	if expired && holder != nil && isPipelinerunInProgress(holder) {
+		log.Warningf(ctx, "pipelinerun %d has held cluster %d since %v, the cluster is taken over",
+			holder.ID, clusterID, q.LockedAt)
+	}
+	held, err = c.runQueueMgr.Transfer(ctx, clusterID, q.PipelinerunID, pipelinerunID, now)
+	return held, holder, err
+}
+
+// cancelPipelineruns stops the pipelinerun holding the cluster, and cancels the pipelineruns queued before the
+// new one. The new pipelinerun stays queued until the pipelinerun holding the cluster is actually stopped.
+func (c *controller) cancelPipelineruns(ctx context.Context, cluster *cmodels.Cluster,
+	holder *prmodels.Pipelinerun, pipelinerunID uint) {
+	queued, err := c.pipelinerunMgr.ListByStatus(ctx, prmodels.StatusQueued)
+	if err != nil {
+		log.Errorf(ctx, "failed to list queued pipelineruns, err: %v", err)
+	}
+	for _, pr := range queued {
+		if pr.ClusterID != cluster.ID || pr.ID == pipelinerunID {
+			continue
+		}
+		if _, err := c.pipelinerunMgr.UpdateStatusByIDAndStatus(ctx, pr.ID,
+			prmodels.StatusQueued, prmodels.StatusCancelled); err != nil {
+			log.Errorf(ctx, "failed to cancel queued pipelinerun %d, err: %v", pr.ID, err)
+		}
+	}
+
+	// restarts, rollbacks and promotions finish within their requests,
+	// and pipelines which started deploying are not stopped
+	if holder == nil || holder.Status != string(prmodels.StatusCreated) || holder.CIEventID == "" ||
+		(holder.Action != prmodels.ActionBuildDeploy && holder.Action != prmodels.ActionDeploy) {
+		return
+	}
+	engine, err := c.ciFty.GetEngine(cluster.EnvironmentName)
+	if err == nil {
+		err = engine.StopPipelineRun(ctx, holder)
+	}
+	if err != nil {
+		log.Errorf(ctx, "failed to stop pipelinerun %d, err: %v", holder.ID, err)
+	}
+}
+
+// getRunQueuePolicy returns the policy of the cluster, or the default policy if the cluster has not set one
+func (c *controller) getRunQueuePolicy(ctx context.Context, clusterID uint) (string, error) {
+	if c.runQueueMgr == nil {
+		return runqueuemodels.PolicyParallel, nil
+	}
+	q, err := c.runQueueMgr.GetByClusterID(ctx, clusterID)
+	if err != nil {
+		if _, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); !ok {
+			return "", err
+		}
+	} else if q.Policy != "" {
+		return q.Policy, nil
+	}
+	if c.runQueueConfig.DefaultPolicy == "" {
+		return runqueuemodels.PolicyParallel, nil
+	}
+	return c.runQueueConfig.DefaultPolicy, nil
+}
+
+// isPipelinerunInProgress tells whether the pipelinerun is executing, that is it may still change the cluster
+func isPipelinerunInProgress(pr *prmodels.Pipelinerun) bool {
+	switch prmodels.PipelineStatus(pr.Status) {
+	case prmodels.StatusCreated, prmodels.StatusCommitted, prmodels.StatusMerged, prmodels.StatusDeployed:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,165 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import (
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	cdmock "github.com/horizoncd/horizon/mock/pkg/cd"
+	clustergitrepomock "github.com/horizoncd/horizon/mock/pkg/cluster/gitrepo"
+	appmodels "github.com/horizoncd/horizon/pkg/application/models"
+	"github.com/horizoncd/horizon/pkg/cluster/gitrepo"
+	"github.com/horizoncd/horizon/pkg/cluster/models"
+	runqueueconfig "github.com/horizoncd/horizon/pkg/config/runqueue"
+	envmodels "github.com/horizoncd/horizon/pkg/environment/models"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	freezemodels "github.com/horizoncd/horizon/pkg/freeze/models"
+	freezeservice "github.com/horizoncd/horizon/pkg/freeze/service"
+	groupmodels "github.com/horizoncd/horizon/pkg/group/models"
+	prmodels "github.com/horizoncd/horizon/pkg/pipelinerun/models"
+	runqueuemodels "github.com/horizoncd/horizon/pkg/runqueue/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func testRunQueue(t *testing.T) {
+	applicationName := "app-queue"
+	mockCtl := gomock.NewController(t)
+	clusterGitRepo := clustergitrepomock.NewMockClusterGitRepo(mockCtl)
+	mockCd := cdmock.NewMockCD(mockCtl)
+
+	_, err := manager.EnvMgr.CreateEnvironment(ctx, &envmodels.Environment{Name: "queue-dev"})
+	assert.Nil(t, err)
+	group, err := manager.GroupManager.Create(ctx, &groupmodels.Group{
+		Name: "queue-group",
+		Path: "queue-group",
+	})
+	assert.Nil(t, err)
+	application, err := manager.ApplicationManager.Create(ctx, &appmodels.Application{
+		GroupID: group.ID,
+		Name:    applicationName,
+	}, nil)
+	assert.Nil(t, err)
+	cluster, err := manager.ClusterMgr.Create(ctx, &models.Cluster{
+		ApplicationID:   application.ID,
+		Name:            "app-queue-dev",
+		EnvironmentName: "queue-dev",
+		RegionName:      "hz",
+		Template:        "javaapp",
+	}, nil, nil)
+	assert.Nil(t, err)
+
+	c = &controller{
+		clusterMgr:     manager.ClusterMgr,
+		clusterGitRepo: clusterGitRepo,
+		applicationMgr: manager.ApplicationManager,
+		envMgr:         manager.EnvMgr,
+		pipelinerunMgr: manager.PipelinerunMgr,
+		runQueueMgr:    manager.RunQueueMgr,
+		runQueueConfig: runqueueconfig.Config{
+			DefaultPolicy: runqueuemodels.PolicyParallel,
+			LockTimeout:   time.Hour,
+		},
+		cd:        mockCd,
+		eventMgr:  manager.EventManager,
+		freezeSvc: freezeservice.NewService(manager),
+	}
+	_, err = manager.RunQueueMgr.UpsertPolicy(ctx, &runqueuemodels.RunQueue{
+		ClusterID: cluster.ID,
+		Policy:    runqueuemodels.PolicySerialize,
+	})
+	assert.Nil(t, err)
+
+	// a builddeploy is running on the cluster
+	holder, err := manager.PipelinerunMgr.Create(ctx, &prmodels.Pipelinerun{
+		ClusterID: cluster.ID,
+		Action:    prmodels.ActionBuildDeploy,
+		Status:    string(prmodels.StatusCreated),
+	})
+	assert.Nil(t, err)
+	held, err := manager.RunQueueMgr.Transfer(ctx, cluster.ID, 0, holder.ID, time.Now())
+	assert.Nil(t, err)
+	assert.True(t, held)
+
+	// restart is queued until the builddeploy finishes
+	clusterGitRepo.EXPECT().GetConfigCommit(gomock.Any(), applicationName, cluster.Name).
+		Return(&gitrepo.ClusterCommit{Master: "master", Gitops: "gitops"}, nil).Times(3)
+	resp, err := c.Restart(ctx, cluster.ID)
+	assert.Nil(t, err)
+	pr, err := manager.PipelinerunMgr.GetByID(ctx, resp.PipelinerunID)
+	assert.Nil(t, err)
+	assert.Equal(t, string(prmodels.StatusQueued), pr.Status)
+
+	dequeued, err := c.DequeuePipelinerun(ctx, pr.ID)
+	assert.Nil(t, err)
+	assert.False(t, dequeued)
+
+	assert.Nil(t, manager.PipelinerunMgr.UpdateStatusByID(ctx, holder.ID, prmodels.StatusOK))
+
+	// the restart still waits while a freeze window of the application is active
+	start, end := time.Now().Add(-time.Hour), time.Now().Add(time.Hour)
+	window, err := manager.FreezeWindowMgr.Create(ctx, &freezemodels.FreezeWindow{
+		ResourceType: common.ResourceApplication,
+		ResourceID:   application.ID,
+		Name:         "queue-freeze",
+		StartAt:      &start,
+		EndAt:        &end,
+	})
+	assert.Nil(t, err)
+	dequeued, err = c.DequeuePipelinerun(ctx, pr.ID)
+	assert.Nil(t, err)
+	assert.False(t, dequeued)
+	pr, err = manager.PipelinerunMgr.GetByID(ctx, pr.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, string(prmodels.StatusQueued), pr.Status)
+	assert.Nil(t, manager.FreezeWindowMgr.DeleteByID(ctx, window.ID))
+
+	clusterGitRepo.EXPECT().UpdateRestartTime(gomock.Any(), applicationName, cluster.Name, "javaapp").
+		Return("restart-commit", nil).Times(1)
+	mockCd.EXPECT().DeployCluster(gomock.Any(), gomock.Any()).Return(nil).Times(1)
+	dequeued, err = c.DequeuePipelinerun(ctx, pr.ID)
+	assert.Nil(t, err)
+	assert.True(t, dequeued)
+	pr, err = manager.PipelinerunMgr.GetByID(ctx, pr.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, string(prmodels.StatusOK), pr.Status)
+	q, err := manager.RunQueueMgr.GetByClusterID(ctx, cluster.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, pr.ID, q.PipelinerunID)
+
+	// new pipelineruns are rejected while the cluster is held
+	_, err = manager.RunQueueMgr.UpsertPolicy(ctx, &runqueuemodels.RunQueue{
+		ClusterID: cluster.ID,
+		Policy:    runqueuemodels.PolicyReject,
+	})
+	assert.Nil(t, err)
+	holder, err = manager.PipelinerunMgr.Create(ctx, &prmodels.Pipelinerun{
+		ClusterID: cluster.ID,
+		Action:    prmodels.ActionBuildDeploy,
+		Status:    string(prmodels.StatusCreated),
+	})
+	assert.Nil(t, err)
+	held, err = manager.RunQueueMgr.Transfer(ctx, cluster.ID, pr.ID, holder.ID, time.Now())
+	assert.Nil(t, err)
+	assert.True(t, held)
+	_, err = c.Restart(ctx, cluster.ID)
+	assert.Equal(t, herrors.ErrClusterBusy, perror.Cause(err))
+	latest, err := manager.PipelinerunMgr.GetLatestByClusterIDAndActions(ctx, cluster.ID, prmodels.ActionRestart)
+	assert.Nil(t, err)
+	assert.Equal(t, string(prmodels.StatusCancelled), latest.Status)
+}
@@ -24,6 +24,7 @@ import (
 	herrors "github.com/horizoncd/horizon/core/errors"
 	"github.com/horizoncd/horizon/lib/q"
 	"github.com/horizoncd/horizon/pkg/cd"
+	"github.com/horizoncd/horizon/pkg/cluster/ci"
 	clustermodels "github.com/horizoncd/horizon/pkg/cluster/models"
 	"github.com/horizoncd/horizon/pkg/cluster/tekton"
 	perror "github.com/horizoncd/horizon/pkg/errors"
@@ -40,6 +41,7 @@ const (
 	_taskDeploy        = "deploy"
 	_taskBuild         = "build"
 	_taskStatusPending = "Pending"
+	_taskStatusQueued  = "Queued"
 
 	_notFound = "NotFound"
 )
@@ -69,6 +71,13 @@ func (c *controller) GetClusterPipelinerunStatus(ctx context.Context,
 		resp.RunningTask = &RunningTask{
 			Task: _taskNone,
 		}
+	} else if latestPipelinerun.Status == string(prmodels.StatusQueued) {
+		// the pipelinerun is waiting for the cluster held by another one
+		resp.RunningTask = &RunningTask{
+			Task:          _taskBuild,
+			TaskStatus:    _taskStatusQueued,
+			PipelinerunID: latestPipelinerun.ID,
+		}
 	} else {
 		latestPipelineRunObject, err := c.getLatestPipelineRunObject(ctx, cluster, latestPipelinerun)
 		if err != nil {
@@ -272,6 +281,14 @@ func (c *controller) getLatestPipelinerunByClusterID(ctx context.Context,
 
 func (c *controller) getLatestPipelineRunObject(ctx context.Context, cluster *clustermodels.Cluster,
 	pipelinerun *prmodels.Pipelinerun) (*v1beta1.PipelineRun, error) {
+	engine, err := c.ciFty.GetEngine(cluster.EnvironmentName)
+	if err != nil {
+		return nil, err
+	}
+	if engine.Type() != ci.TypeTekton {
+		// external pipelines do not report their tasks until they finish
+		return nil, nil
+	}
 	tektonCollector, err := c.tektonFty.GetTektonCollector(cluster.EnvironmentName)
 	if err != nil {
 		return nil, err
@@ -545,6 +562,11 @@ func (c *controller) GetStep(ctx context.Context, clusterID uint) (resp *GetStep
 			AutoPromote:  steps.AutoPromote,
 		}
 	}
+
+	resp.Analyses, err = c.listCanaryAnalyses(ctx, clusterID)
+	if err != nil {
+		return nil, err
+	}
 	return
 }
 
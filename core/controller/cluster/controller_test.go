@@ -50,6 +50,8 @@ import (
 	appgitrepo "github.com/horizoncd/horizon/pkg/application/gitrepo"
 	appmodels "github.com/horizoncd/horizon/pkg/application/models"
 	userauth "github.com/horizoncd/horizon/pkg/authentication/user"
+	buildcontextmodels "github.com/horizoncd/horizon/pkg/buildcontext/models"
+	"github.com/horizoncd/horizon/pkg/cluster/ci"
 	codemodels "github.com/horizoncd/horizon/pkg/cluster/code"
 	"github.com/horizoncd/horizon/pkg/cluster/gitrepo"
 	"github.com/horizoncd/horizon/pkg/cluster/models"
@@ -61,6 +63,7 @@ import (
 	envregionmodels "github.com/horizoncd/horizon/pkg/environmentregion/models"
 	perror "github.com/horizoncd/horizon/pkg/errors"
 	eventmodels "github.com/horizoncd/horizon/pkg/event/models"
+	freezemodels "github.com/horizoncd/horizon/pkg/freeze/models"
 	"github.com/horizoncd/horizon/pkg/git"
 	"github.com/horizoncd/horizon/pkg/git/gitlab"
 	groupmodels "github.com/horizoncd/horizon/pkg/group/models"
@@ -69,9 +72,12 @@ import (
 	"github.com/horizoncd/horizon/pkg/param"
 	"github.com/horizoncd/horizon/pkg/param/managerparam"
 	prmodels "github.com/horizoncd/horizon/pkg/pipelinerun/models"
+	reportmodels "github.com/horizoncd/horizon/pkg/pipelinerun/report/models"
+	promotionmodels "github.com/horizoncd/horizon/pkg/promotion/models"
 	regionmodels "github.com/horizoncd/horizon/pkg/region/models"
 	registrydao "github.com/horizoncd/horizon/pkg/registry/dao"
 	registrymodels "github.com/horizoncd/horizon/pkg/registry/models"
+	runqueuemodels "github.com/horizoncd/horizon/pkg/runqueue/models"
 	"github.com/horizoncd/horizon/pkg/server/global"
 	tagmodels "github.com/horizoncd/horizon/pkg/tag/models"
 	tmodel "github.com/horizoncd/horizon/pkg/tag/models"
@@ -454,7 +460,9 @@ func TestMain(m *testing.M) {
 		&registrymodels.Registry{}, eventmodels.Event{},
 		&regionmodels.Region{}, &envregionmodels.EnvironmentRegion{}, &eventmodels.Event{},
 		&prmodels.Pipelinerun{}, &schematagmodel.ClusterTemplateSchemaTag{}, &tmodel.Tag{},
-		&envmodels.Environment{}, &tokenmodels.Token{}); err != nil {
+		&envmodels.Environment{}, &tokenmodels.Token{}, &promotionmodels.PromotionPath{},
+		&reportmodels.Report{}, &runqueuemodels.RunQueue{}, &buildcontextmodels.BuildContext{},
+		&freezemodels.FreezeWindow{}); err != nil {
 		panic(err)
 	}
 	ctx = context.TODO()
@@ -505,6 +513,14 @@ func TestAll(t *testing.T) {
 	t.Run("TestListClusterWithExpiry", testListClusterWithExpiry)
 	t.Run("TestControllerFreeOrDeleteClusterFailed", testControllerFreeOrDeleteClusterFailed)
 	t.Run("TestGetClusterStatusV2", testGetClusterStatusV2)
+	t.Run("TestPromoteValues", testPromoteValues)
+	t.Run("TestPromoteCluster", testPromoteCluster)
+	t.Run("TestApprovePipelinerun", testApprovePipelinerun)
+	t.Run("TestPipelinerunRequest", testPipelinerunRequest)
+	t.Run("TestTemplateMigration", testTemplateMigration)
+	t.Run("TestVulnerabilityPolicy", testVulnerabilityPolicy)
+	t.Run("TestRunQueue", testRunQueue)
+	t.Run("TestBuildContext", testBuildContext)
 }
 
 // nolint
@@ -524,6 +540,7 @@ func test(t *testing.T) {
 	cd := cdmock.NewMockLegacyCD(mockCtl)
 	k8sutil := cdmock.NewMockK8sUtil(mockCtl)
 	tektonFty := tektonftymock.NewMockFactory(mockCtl)
+	ciFty, _ := ci.NewFactory(nil, tektonFty)
 	registryFty := registryftymock.NewMockRegistryGetter(mockCtl)
 	commitGetter := commitmock.NewMockGitGetter(mockCtl)
 	tagManager := manager.TagManager
@@ -642,6 +659,7 @@ func test(t *testing.T) {
 		groupSvc:             groupservice.NewService(manager),
 		pipelinerunMgr:       manager.PipelinerunMgr,
 		tektonFty:            tektonFty,
+		ciFty:                ciFty,
 		registryFty:          registryFty,
 		userManager:          manager.UserManager,
 		userSvc:              userservice.NewService(manager),
@@ -844,7 +862,7 @@ func test(t *testing.T) {
 
 	tekton := tektonmock.NewMockInterface(mockCtl)
 	tektonFty.EXPECT().GetTekton(gomock.Any()).Return(tekton, nil).AnyTimes()
-	tekton.EXPECT().CreatePipelineRun(ctx, gomock.Any()).Return("abc", nil).Times(2)
+	tekton.EXPECT().CreatePipelineRun(ctx, gomock.Any()).Return("abc", nil).Times(3)
 	tekton.EXPECT().GetPipelineRunByID(ctx, gomock.Any()).Return(pr, nil).AnyTimes()
 	tektonCollector := tektoncollectormock.NewMockInterface(mockCtl)
 
@@ -947,6 +965,13 @@ func test(t *testing.T) {
 	clusterGitRepo.EXPECT().CompareConfig(ctx, gomock.Any(), gomock.Any(),
 		gomock.Any(), gomock.Any()).Return(configDiff, nil).AnyTimes()
 
+	// the image built by the builddeploy is still in the registry
+	imageRegistry := registrymock.NewMockRegistry(mockCtl)
+	registryFty.EXPECT().GetRegistryByConfig(gomock.Any(), gomock.Any()).Return(imageRegistry, nil).AnyTimes()
+	imageRegistry.EXPECT().ImageExists(gomock.Any(), gomock.Any()).Return(true, nil).AnyTimes()
+	build, err := manager.PipelinerunMgr.GetByID(ctx, buildDeployResp.PipelinerunID)
+	assert.Nil(t, err)
+
 	getdiffResp, err := c.GetDiff(ctx, resp.ID, codemodels.GitRefTypeBranch, codeBranch)
 	assert.Nil(t, err)
 
@@ -959,10 +984,38 @@ func test(t *testing.T) {
 			Link:      link,
 		},
 		ConfigDiff: configDiff,
+		BuildCache: &BuildCache{
+			PipelinerunID: build.ID,
+			ClusterID:     build.ClusterID,
+			ImageURL:      build.ImageURL,
+			CreatedAt:     build.CreatedAt,
+		},
 	}, getdiffResp)
 	b, _ = json.Marshal(getdiffResp)
 	t.Logf("%s", string(b))
 
+	// test builddeploy reusing the image
+	commitGetter.EXPECT().GetCommit(ctx, gomock.Any(), gomock.Any(), gomock.Any()).Return(&git.Commit{
+		ID:      commitID,
+		Message: commitMsg,
+	}, nil)
+	reuseBuild := true
+	reuseResp, err := c.BuildDeploy(ctx, resp.ID, &BuildDeployRequest{
+		Title:      "reuse",
+		Git:        &BuildDeployRequestGit{Branch: codeBranch},
+		ReuseBuild: &reuseBuild,
+	})
+	assert.Nil(t, err)
+	reusePr, err := manager.PipelinerunMgr.GetByID(ctx, reuseResp.PipelinerunID)
+	assert.Nil(t, err)
+	assert.Equal(t, build.ID, *reusePr.ReuseFrom)
+	assert.Equal(t, build.ImageURL, reusePr.ImageURL)
+	assert.Equal(t, build.BuildConfigHash, reusePr.BuildConfigHash)
+	// the request is kept to build and deploy again with it once approved or dequeued
+	keptRequest := &BuildDeployRequest{}
+	assert.Nil(t, unmarshalRequest(reusePr, keptRequest))
+	assert.True(t, *keptRequest.ReuseBuild)
+
 	// test restart
 	clusterGitRepo.EXPECT().UpdateRestartTime(ctx, gomock.Any(), gomock.Any(),
 		gomock.Any()).Return("update-image-commit", nil)
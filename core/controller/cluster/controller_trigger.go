@@ -0,0 +1,32 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import "context"
+
+// pipelinerunTriggerKey is the context key of what triggers the pipelineruns created with the context
+type pipelinerunTriggerKey struct{}
+
+// WithPipelinerunTrigger marks the pipelineruns created with the returned context as triggered by trigger,
+// e.g. prmodels.TriggerScheduled, pipelineruns are regarded as created by users without it
+func WithPipelinerunTrigger(ctx context.Context, trigger string) context.Context {
+	return context.WithValue(ctx, pipelinerunTriggerKey{}, trigger)
+}
+
+// PipelinerunTriggerFromContext returns the trigger set by WithPipelinerunTrigger, empty if it's not set
+func PipelinerunTriggerFromContext(ctx context.Context) string {
+	trigger, _ := ctx.Value(pipelinerunTriggerKey{}).(string)
+	return trigger
+}
@@ -0,0 +1,71 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import (
+	"context"
+
+	herrors "github.com/horizoncd/horizon/core/errors"
+	clustermodels "github.com/horizoncd/horizon/pkg/cluster/models"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	prmodels "github.com/horizoncd/horizon/pkg/pipelinerun/models"
+	reportmodels "github.com/horizoncd/horizon/pkg/pipelinerun/report/models"
+	"github.com/horizoncd/horizon/pkg/util/log"
+)
+
+// checkVulnerabilityPolicy blocks deploying the build of the pipelinerun
+// when the critical vulnerabilities found by its scans exceed the deploy policy
+func (c *controller) checkVulnerabilityPolicy(ctx context.Context, cluster *clustermodels.Cluster,
+	pr *prmodels.Pipelinerun) error {
+	policy := c.reportConfig.DeployPolicy
+	if !policy.AppliesTo(cluster.EnvironmentName) {
+		return nil
+	}
+
+	critical := 0
+	if pr.ReuseFrom != nil {
+		// the image was scanned by the pipelinerun which built it
+		reports, err := c.prReportMgr.ListByPipelinerunID(ctx, *pr.ReuseFrom)
+		if err != nil {
+			return err
+		}
+		for _, r := range reports {
+			if r.Kind == reportmodels.KindVulnerability {
+				critical += r.Critical
+			}
+		}
+	} else {
+		engine, err := c.ciFty.GetEngine(cluster.EnvironmentName)
+		if err != nil {
+			return err
+		}
+		reports, err := engine.GetPipelineRunReports(ctx, pr)
+		if err != nil {
+			return err
+		}
+		for _, r := range reports {
+			if r.Kind == reportmodels.KindVulnerability {
+				critical += r.Summary.Critical
+			}
+		}
+	}
+
+	if critical > policy.MaxCriticalVulnerabilities {
+		log.Warningf(ctx, "deploying pipelinerun %d is blocked with %d critical vulnerabilities", pr.ID, critical)
+		return perror.Wrapf(herrors.ErrVulnerabilityPolicy, "%d critical vulnerabilities are found, at most %d allowed",
+			critical, policy.MaxCriticalVulnerabilities)
+	}
+	return nil
+}
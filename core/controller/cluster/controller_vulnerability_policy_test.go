@@ -0,0 +1,81 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import (
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	cimock "github.com/horizoncd/horizon/mock/pkg/cluster/ci"
+	"github.com/horizoncd/horizon/pkg/cluster/models"
+	"github.com/horizoncd/horizon/pkg/cluster/tekton/report"
+	"github.com/horizoncd/horizon/pkg/config/pipelinereport"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	prmodels "github.com/horizoncd/horizon/pkg/pipelinerun/models"
+	reportmodels "github.com/horizoncd/horizon/pkg/pipelinerun/report/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func testVulnerabilityPolicy(t *testing.T) {
+	mockCtl := gomock.NewController(t)
+	ciFty := cimock.NewMockFactory(mockCtl)
+	engine := cimock.NewMockEngine(mockCtl)
+	ciFty.EXPECT().GetEngine("online").Return(engine, nil).AnyTimes()
+
+	c := &controller{
+		ciFty:       ciFty,
+		prReportMgr: manager.PipelinerunReportMgr,
+	}
+	cluster := &models.Cluster{EnvironmentName: "online"}
+	pr := &prmodels.Pipelinerun{ID: 100, Action: prmodels.ActionBuildDeploy}
+
+	// no policy
+	assert.Nil(t, c.checkVulnerabilityPolicy(ctx, cluster, pr))
+
+	// the environment is not checked
+	c.reportConfig = pipelinereport.Config{DeployPolicy: &pipelinereport.DeployPolicy{
+		MaxCriticalVulnerabilities: 1,
+		Environments:               []string{"pre"},
+	}}
+	assert.Nil(t, c.checkVulnerabilityPolicy(ctx, cluster, pr))
+
+	c.reportConfig.DeployPolicy.Environments = append(c.reportConfig.DeployPolicy.Environments, "online")
+	engine.EXPECT().GetPipelineRunReports(ctx, pr).Return([]*report.Report{
+		{Kind: reportmodels.KindVulnerability, Summary: &reportmodels.Summary{Critical: 1}},
+		{Kind: reportmodels.KindTest, Summary: &reportmodels.Summary{Tests: 3}},
+	}, nil)
+	assert.Nil(t, c.checkVulnerabilityPolicy(ctx, cluster, pr))
+
+	engine.EXPECT().GetPipelineRunReports(ctx, pr).Return([]*report.Report{
+		{Kind: reportmodels.KindVulnerability, Summary: &reportmodels.Summary{Critical: 1}},
+		{Kind: reportmodels.KindVulnerability, Summary: &reportmodels.Summary{Critical: 1}},
+	}, nil)
+	err := c.checkVulnerabilityPolicy(ctx, cluster, pr)
+	assert.Equal(t, herrors.ErrVulnerabilityPolicy, perror.Cause(err))
+
+	// the reports of the build reused are checked
+	reuseFrom := uint(99)
+	err = manager.PipelinerunReportMgr.Create(ctx, []*reportmodels.Report{{
+		PipelinerunID: reuseFrom,
+		Kind:          reportmodels.KindVulnerability,
+		Format:        reportmodels.FormatTrivy,
+		Summary:       reportmodels.Summary{Critical: 2},
+	}})
+	assert.Nil(t, err)
+	pr.ReuseFrom = &reuseFrom
+	err = c.checkVulnerabilityPolicy(ctx, cluster, pr)
+	assert.Equal(t, herrors.ErrVulnerabilityPolicy, perror.Cause(err))
+}
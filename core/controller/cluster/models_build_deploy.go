@@ -14,10 +14,16 @@
 
 package cluster
 
+import "time"
+
 type BuildDeployRequest struct {
 	Title       string                 `json:"title"`
 	Description string                 `json:"description"`
 	Git         *BuildDeployRequestGit `json:"git"`
+	// ReuseBuild tells whether to deploy the image built from the same commit and build config
+	// instead of building again, the autoReuse of the build cache config is followed if it's not set.
+	// Setting it to false also builds the cluster whose build context is not changed since the last build
+	ReuseBuild *bool `json:"reuseBuild,omitempty"`
 }
 
 type BuildDeployRequestGit struct {
@@ -33,6 +39,16 @@ type BuildDeployResponse struct {
 type GetDiffResponse struct {
 	CodeInfo   *CodeInfo `json:"codeInfo"`
 	ConfigDiff string    `json:"configDiff"`
+	// BuildCache is the build whose image can be reused to deploy the commit
+	BuildCache *BuildCache `json:"buildCache,omitempty"`
+}
+
+type BuildCache struct {
+	// PipelinerunID is the pipelinerun which built the image
+	PipelinerunID uint      `json:"pipelinerunID"`
+	ClusterID     uint      `json:"clusterID"`
+	ImageURL      string    `json:"imageURL"`
+	CreatedAt     time.Time `json:"createdAt"`
 }
 
 type CodeInfo struct {
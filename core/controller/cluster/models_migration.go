@@ -0,0 +1,48 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import (
+	"github.com/horizoncd/horizon/pkg/templaterelease/migration"
+)
+
+type TemplateMigrationRequest struct {
+	// ClusterIDs are the clusters to upgrade,
+	// all clusters of the template on other releases are upgraded if it's empty
+	ClusterIDs []uint `json:"clusterIDs"`
+}
+
+type TemplateMigrationResponse struct {
+	Template string              `json:"template"`
+	Release  string              `json:"release"`
+	Clusters []*ClusterMigration `json:"clusters"`
+}
+
+type ClusterMigration struct {
+	ClusterID   uint   `json:"clusterID"`
+	Cluster     string `json:"cluster"`
+	Application string `json:"application"`
+	FromRelease string `json:"fromRelease"`
+	// Migrated is whether a migration of the release applies to the cluster,
+	// values are kept as they are otherwise
+	Migrated           bool               `json:"migrated"`
+	BuildConfigDiff    []migration.Change `json:"buildConfigDiff"`
+	TemplateConfigDiff []migration.Change `json:"templateConfigDiff"`
+	// Errors are failures of migration and schema validation,
+	// the cluster is not upgraded if there is any
+	Errors []string `json:"errors,omitempty"`
+	// Applied is whether the migrated values are committed to the cluster's git repo
+	Applied bool `json:"applied"`
+}
@@ -68,6 +68,23 @@ type RollbackRequest struct {
 	PipelinerunID uint `json:"pipelinerunID"`
 }
 
+type ReviewPipelinerunRequest struct {
+	Comment string `json:"comment"`
+}
+
+type PromoteRequest struct {
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	// SourceClusterID the cluster in the previous environment of the promotion path
+	SourceClusterID uint `json:"sourceClusterID"`
+	// PipelinerunID the pipelinerun of source cluster to promote, defaults to its latest successful one
+	PipelinerunID uint `json:"pipelinerunID"`
+	// TemplateConfigKeys keys of template config copied from source cluster, nested keys are separated by dot
+	TemplateConfigKeys []string `json:"templateConfigKeys"`
+	// BuildConfigKeys keys of build config copied from source cluster, nested keys are separated by dot
+	BuildConfigKeys []string `json:"buildConfigKeys"`
+}
+
 type BatchResponse map[string]OperationResult
 type OperationResult struct {
 	// Result bool value indicates whether the result is successfully
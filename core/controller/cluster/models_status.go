@@ -15,7 +15,10 @@
 package cluster
 
 import (
+	"time"
+
 	"github.com/argoproj/argo-cd/pkg/apis/application/v1alpha1"
+	canarymodels "github.com/horizoncd/horizon/pkg/canary/models"
 	"github.com/horizoncd/horizon/pkg/grafana"
 	corev1 "k8s.io/api/core/v1"
 )
@@ -85,4 +88,16 @@ type GetStepResponse struct {
 	ManualPaused bool    `json:"manualPaused"`
 	AutoPromote  bool    `json:"autoPromote"`
 	Extra        *string `json:"extra"`
+	// Analyses are the canary analyses of the latest deployment
+	Analyses []*CanaryAnalysis `json:"analyses,omitempty"`
+}
+
+type CanaryAnalysis struct {
+	PipelinerunID uint                        `json:"pipelinerunID"`
+	Step          int                         `json:"step"`
+	Status        string                      `json:"status"`
+	Metrics       []canarymodels.MetricResult `json:"metrics"`
+	Message       string                      `json:"message"`
+	StartedAt     time.Time                   `json:"startedAt"`
+	FinishedAt    *time.Time                  `json:"finishedAt,omitempty"`
 }
@@ -0,0 +1,160 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dora
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	herrors "github.com/horizoncd/horizon/core/errors"
+	applicationmanager "github.com/horizoncd/horizon/pkg/application/manager"
+	doramanager "github.com/horizoncd/horizon/pkg/dora/manager"
+	"github.com/horizoncd/horizon/pkg/dora/models"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	groupmanager "github.com/horizoncd/horizon/pkg/group/manager"
+	"github.com/horizoncd/horizon/pkg/param"
+	"github.com/horizoncd/horizon/pkg/util/wlog"
+)
+
+const (
+	_defaultWindow = 30 * 24 * time.Hour
+	_maxWindow     = 366 * 24 * time.Hour
+)
+
+type Controller interface {
+	// GetApplicationMetrics returns the DORA metrics of the clusters of the application by environment
+	GetApplicationMetrics(ctx context.Context, applicationID uint, query *Query) ([]*EnvironmentMetrics, error)
+	// GetGroupMetrics returns the DORA metrics of the clusters of the applications in the group and
+	// its subgroups by environment
+	GetGroupMetrics(ctx context.Context, groupID uint, query *Query) ([]*EnvironmentMetrics, error)
+}
+
+type controller struct {
+	doraMgr        doramanager.Manager
+	applicationMgr applicationmanager.Manager
+	groupMgr       groupmanager.Manager
+}
+
+var _ Controller = (*controller)(nil)
+
+func NewController(param *param.Param) Controller {
+	return &controller{
+		doraMgr:        param.DORAMgr,
+		applicationMgr: param.ApplicationManager,
+		groupMgr:       param.GroupManager,
+	}
+}
+
+func (c *controller) GetApplicationMetrics(ctx context.Context, applicationID uint,
+	query *Query) ([]*EnvironmentMetrics, error) {
+	const op = "dora controller: get application metrics"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	if _, err := c.applicationMgr.GetByID(ctx, applicationID); err != nil {
+		return nil, err
+	}
+	return c.getMetrics(ctx, &models.Query{ApplicationID: applicationID}, query)
+}
+
+func (c *controller) GetGroupMetrics(ctx context.Context, groupID uint,
+	query *Query) ([]*EnvironmentMetrics, error) {
+	const op = "dora controller: get group metrics"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	if _, err := c.groupMgr.GetByID(ctx, groupID); err != nil {
+		return nil, err
+	}
+	groups, err := c.groupMgr.GetSubGroupsByGroupIDs(ctx, []uint{groupID})
+	if err != nil {
+		return nil, err
+	}
+	groupIDs := make([]uint, 0, len(groups))
+	for _, group := range groups {
+		groupIDs = append(groupIDs, group.ID)
+	}
+	return c.getMetrics(ctx, &models.Query{GroupIDs: groupIDs}, query)
+}
+
+func (c *controller) getMetrics(ctx context.Context, selector *models.Query,
+	query *Query) ([]*EnvironmentMetrics, error) {
+	window, interval, err := parseQuery(query)
+	if err != nil {
+		return nil, err
+	}
+	selector.Environment = query.Environment
+	selector.Start, selector.End = window.Start, window.End
+	deployments, err := c.doraMgr.ListDeployments(ctx, selector)
+	if err != nil {
+		return nil, err
+	}
+
+	byEnvironment := make(map[string][]*models.Deployment)
+	for _, d := range deployments {
+		byEnvironment[d.Environment] = append(byEnvironment[d.Environment], d)
+	}
+	environments := make([]string, 0, len(byEnvironment))
+	for environment := range byEnvironment {
+		environments = append(environments, environment)
+	}
+	sort.Strings(environments)
+
+	result := make([]*EnvironmentMetrics, 0, len(environments))
+	for _, environment := range environments {
+		windows := []models.Window{window}
+		if interval > 0 {
+			windows = append(windows, window.Split(interval)...)
+		}
+		metrics := models.Compute(byEnvironment[environment], windows...)
+		environmentMetrics := &EnvironmentMetrics{
+			Environment: environment,
+			Summary:     metrics[0],
+		}
+		if interval > 0 {
+			environmentMetrics.Series = metrics[1:]
+		}
+		result = append(result, environmentMetrics)
+	}
+	return result, nil
+}
+
+func parseQuery(query *Query) (models.Window, time.Duration, error) {
+	window := models.Window{Start: query.Start, End: query.End}
+	if window.End.IsZero() {
+		window.End = time.Now()
+	}
+	if window.Start.IsZero() {
+		window.Start = window.End.Add(-_defaultWindow)
+	}
+	if !window.Start.Before(window.End) {
+		return window, 0, perror.Wrap(herrors.ErrParamInvalid, "start must be before end")
+	}
+	if window.End.Sub(window.Start) > _maxWindow {
+		return window, 0, perror.Wrapf(herrors.ErrParamInvalid,
+			"window can not be longer than %v days", _maxWindow.Hours()/24)
+	}
+
+	var interval time.Duration
+	switch query.Interval {
+	case "":
+	case IntervalDay:
+		interval = 24 * time.Hour
+	case IntervalWeek:
+		interval = 7 * 24 * time.Hour
+	default:
+		return window, 0, perror.Wrapf(herrors.ErrParamInvalid, "invalid interval %s", query.Interval)
+	}
+	return window, interval, nil
+}
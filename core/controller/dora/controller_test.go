@@ -0,0 +1,106 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dora
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/lib/orm"
+	appmodels "github.com/horizoncd/horizon/pkg/application/models"
+	clustermodels "github.com/horizoncd/horizon/pkg/cluster/models"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	groupmodels "github.com/horizoncd/horizon/pkg/group/models"
+	"github.com/horizoncd/horizon/pkg/param"
+	"github.com/horizoncd/horizon/pkg/param/managerparam"
+	prmodels "github.com/horizoncd/horizon/pkg/pipelinerun/models"
+	"github.com/horizoncd/horizon/pkg/server/global"
+)
+
+func Test(t *testing.T) {
+	db, _ := orm.NewSqliteDB("")
+	if err := db.AutoMigrate(&groupmodels.Group{}, &appmodels.Application{}, &clustermodels.Cluster{},
+		&prmodels.Pipelinerun{}); err != nil {
+		panic(err)
+	}
+	c := NewController(&param.Param{Manager: managerparam.InitManager(db)})
+	ctx := context.Background()
+
+	db.Create([]*groupmodels.Group{
+		{Model: global.Model{ID: 1}, Name: "a", Path: "a", TraversalIDs: "1"},
+		{Model: global.Model{ID: 2}, Name: "b", Path: "b", ParentID: 1, TraversalIDs: "1,2"},
+		{Model: global.Model{ID: 3}, Name: "c", Path: "c", TraversalIDs: "3"},
+	})
+	db.Create([]*appmodels.Application{
+		{Model: global.Model{ID: 1}, GroupID: 1, Name: "app1"},
+		{Model: global.Model{ID: 2}, GroupID: 2, Name: "app2"},
+	})
+	db.Create([]*clustermodels.Cluster{
+		{Model: global.Model{ID: 1}, ApplicationID: 1, Name: "app1-online", EnvironmentName: "online"},
+		{Model: global.Model{ID: 2}, ApplicationID: 1, Name: "app1-test", EnvironmentName: "test"},
+		{Model: global.Model{ID: 3}, ApplicationID: 2, Name: "app2-online", EnvironmentName: "online"},
+	})
+	start := time.Date(2023, 7, 1, 0, 0, 0, 0, time.UTC)
+	at := func(hours int) *time.Time {
+		t := start.Add(time.Duration(hours) * time.Hour)
+		return &t
+	}
+	ok, failed := string(prmodels.StatusOK), string(prmodels.StatusFailed)
+	db.Create([]*prmodels.Pipelinerun{
+		{ClusterID: 1, Action: prmodels.ActionBuildDeploy, Status: ok, GitCommitTime: at(0), FinishedAt: at(2)},
+		{ClusterID: 1, Action: prmodels.ActionDeploy, Status: failed, FinishedAt: at(26)},
+		{ClusterID: 2, Action: prmodels.ActionBuildDeploy, Status: ok, FinishedAt: at(3)},
+		{ClusterID: 3, Action: prmodels.ActionPromote, Status: ok, FinishedAt: at(4)},
+	})
+
+	query := &Query{Start: start, End: *at(48), Interval: IntervalDay}
+	metrics, err := c.GetApplicationMetrics(ctx, 1, query)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(metrics))
+	assert.Equal(t, "online", metrics[0].Environment)
+	assert.Equal(t, 1, metrics[0].Summary.Deployments)
+	assert.Equal(t, 0.5, metrics[0].Summary.ChangeFailureRate)
+	assert.Equal(t, (2 * time.Hour).Seconds(), metrics[0].Summary.LeadTimeSeconds)
+	assert.Equal(t, 2, len(metrics[0].Series))
+	assert.Equal(t, 0.0, metrics[0].Series[0].ChangeFailureRate)
+	assert.Equal(t, 1.0, metrics[0].Series[1].ChangeFailureRate)
+	assert.Equal(t, "test", metrics[1].Environment)
+
+	// subgroups are included
+	query = &Query{Start: start, End: *at(48), Environment: "online"}
+	metrics, err = c.GetGroupMetrics(ctx, 1, query)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(metrics))
+	assert.Equal(t, 2, metrics[0].Summary.Deployments)
+	assert.Equal(t, 3, metrics[0].Summary.Changes)
+	assert.Nil(t, metrics[0].Series)
+
+	metrics, err = c.GetGroupMetrics(ctx, 3, query)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(metrics))
+
+	_, err = c.GetGroupMetrics(ctx, 4, query)
+	_, isNotFound := perror.Cause(err).(*herrors.HorizonErrNotFound)
+	assert.True(t, isNotFound)
+
+	_, err = c.GetApplicationMetrics(ctx, 1, &Query{Start: *at(48), End: start})
+	assert.Equal(t, herrors.ErrParamInvalid, perror.Cause(err))
+	_, err = c.GetApplicationMetrics(ctx, 1, &Query{Interval: "month"})
+	assert.Equal(t, herrors.ErrParamInvalid, perror.Cause(err))
+}
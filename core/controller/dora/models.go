@@ -0,0 +1,44 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dora
+
+import (
+	"time"
+
+	"github.com/horizoncd/horizon/pkg/dora/models"
+)
+
+// intervals the window of a query can be split by
+const (
+	IntervalDay  = "day"
+	IntervalWeek = "week"
+)
+
+type Query struct {
+	// Start defaults to 30 days before End
+	Start time.Time
+	// End defaults to now
+	End time.Time
+	// Interval splits the window into a series of metrics, no series if empty
+	Interval string
+	// Environment selects the clusters of the environment, all environments if empty
+	Environment string
+}
+
+type EnvironmentMetrics struct {
+	Environment string            `json:"environment"`
+	Summary     *models.Metrics   `json:"summary"`
+	Series      []*models.Metrics `json:"series,omitempty"`
+}
@@ -0,0 +1,144 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package drift
+
+import (
+	"context"
+	"time"
+
+	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	applicationmanager "github.com/horizoncd/horizon/pkg/application/manager"
+	"github.com/horizoncd/horizon/pkg/cd"
+	"github.com/horizoncd/horizon/pkg/cluster/gitrepo"
+	clustermanager "github.com/horizoncd/horizon/pkg/cluster/manager"
+	driftmanager "github.com/horizoncd/horizon/pkg/drift/manager"
+	"github.com/horizoncd/horizon/pkg/drift/models"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/param"
+	"github.com/horizoncd/horizon/pkg/util/wlog"
+)
+
+type Controller interface {
+	// GetDrift gets the latest drift report of the cluster
+	GetDrift(ctx context.Context, clusterID uint) (*Report, error)
+	// Resync syncs the cluster to the master of its gitops repo, so that the drifted resources are
+	// overwritten by the desired state. The drift is resynced only if it was detected against the current
+	// master, otherwise it's outdated and should be detected again. Accepted drift can also be resynced.
+	Resync(ctx context.Context, clusterID uint) (*Report, error)
+	// Accept keeps the drift as it is, it is reported again only if the drifted resources or fields change
+	Accept(ctx context.Context, clusterID uint) (*Report, error)
+}
+
+type controller struct {
+	driftReportMgr driftmanager.Manager
+	clusterMgr     clustermanager.Manager
+	applicationMgr applicationmanager.Manager
+	clusterGitRepo gitrepo.ClusterGitRepo
+	cd             cd.CD
+}
+
+var _ Controller = (*controller)(nil)
+
+func NewController(param *param.Param) Controller {
+	return &controller{
+		driftReportMgr: param.DriftReportMgr,
+		clusterMgr:     param.ClusterMgr,
+		applicationMgr: param.ApplicationManager,
+		clusterGitRepo: param.ClusterGitRepo,
+		cd:             param.CD,
+	}
+}
+
+func (c *controller) GetDrift(ctx context.Context, clusterID uint) (*Report, error) {
+	const op = "drift controller: get"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	report, err := c.driftReportMgr.GetByClusterID(ctx, clusterID)
+	if err != nil {
+		return nil, err
+	}
+	return ofReportModel(report), nil
+}
+
+func (c *controller) Resync(ctx context.Context, clusterID uint) (*Report, error) {
+	const op = "drift controller: resync"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	report, err := c.driftReportMgr.GetByClusterID(ctx, clusterID)
+	if err != nil {
+		return nil, err
+	}
+	if report.Status == models.StatusResolved {
+		return nil, perror.Wrapf(herrors.ErrDriftStatusInvalid,
+			"cluster %d has no drift to resync", clusterID)
+	}
+	cluster, err := c.clusterMgr.GetByID(ctx, clusterID)
+	if err != nil {
+		return nil, err
+	}
+	application, err := c.applicationMgr.GetByID(ctx, cluster.ApplicationID)
+	if err != nil {
+		return nil, err
+	}
+	configCommit, err := c.clusterGitRepo.GetConfigCommit(ctx, application.Name, cluster.Name)
+	if err != nil {
+		return nil, err
+	}
+	// syncing to an older revision would roll back the changes merged since the drift was detected
+	if report.Revision != configCommit.Master {
+		return nil, perror.Wrapf(herrors.ErrDriftStatusInvalid,
+			"drift of cluster %d was detected against revision %s, but the latest revision is %s",
+			clusterID, report.Revision, configCommit.Master)
+	}
+
+	if err := c.cd.DeployCluster(ctx, &cd.DeployClusterParams{
+		Environment: cluster.EnvironmentName,
+		Cluster:     cluster.Name,
+		Revision:    configCommit.Master,
+	}); err != nil {
+		return nil, err
+	}
+	return ofReportModel(report), nil
+}
+
+func (c *controller) Accept(ctx context.Context, clusterID uint) (*Report, error) {
+	const op = "drift controller: accept"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	currentUser, err := common.UserFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := c.driftReportMgr.GetByClusterID(ctx, clusterID); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	updated, err := c.driftReportMgr.UpdateStatusByClusterID(ctx, clusterID,
+		[]string{models.StatusDrifted}, &models.DriftReport{
+			Status:     models.StatusAccepted,
+			AcceptedAt: &now,
+			AcceptedBy: currentUser.GetID(),
+		})
+	if err != nil {
+		return nil, err
+	}
+	if !updated {
+		return nil, perror.Wrapf(herrors.ErrDriftStatusInvalid,
+			"drift of cluster %d can not be accepted", clusterID)
+	}
+	return c.GetDrift(ctx, clusterID)
+}
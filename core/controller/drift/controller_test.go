@@ -0,0 +1,118 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package drift
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/lib/orm"
+	cdmock "github.com/horizoncd/horizon/mock/pkg/cd"
+	clustergitrepomock "github.com/horizoncd/horizon/mock/pkg/cluster/gitrepo"
+	appmodels "github.com/horizoncd/horizon/pkg/application/models"
+	userauth "github.com/horizoncd/horizon/pkg/authentication/user"
+	"github.com/horizoncd/horizon/pkg/cd"
+	"github.com/horizoncd/horizon/pkg/cluster/gitrepo"
+	clustermodels "github.com/horizoncd/horizon/pkg/cluster/models"
+	"github.com/horizoncd/horizon/pkg/drift/models"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/param"
+	"github.com/horizoncd/horizon/pkg/param/managerparam"
+)
+
+func Test(t *testing.T) {
+	db, _ := orm.NewSqliteDB("")
+	if err := db.AutoMigrate(&models.DriftReport{}, &clustermodels.Cluster{},
+		&appmodels.Application{}); err != nil {
+		panic(err)
+	}
+	// nolint
+	ctx := common.WithContext(context.Background(), &userauth.DefaultInfo{
+		Name: "Tony",
+		ID:   1,
+	})
+	mockCtl := gomock.NewController(t)
+	cdMock := cdmock.NewMockCD(mockCtl)
+	clusterGitRepo := clustergitrepomock.NewMockClusterGitRepo(mockCtl)
+	manager := managerparam.InitManager(db)
+	c := NewController(&param.Param{Manager: manager, CD: cdMock, ClusterGitRepo: clusterGitRepo})
+
+	application := &appmodels.Application{Name: "app"}
+	db.Save(application)
+	cluster := &clustermodels.Cluster{ApplicationID: application.ID, Name: "app-hz", EnvironmentName: "online"}
+	db.Save(cluster)
+
+	_, err := c.GetDrift(ctx, cluster.ID)
+	_, ok := perror.Cause(err).(*herrors.HorizonErrNotFound)
+	assert.True(t, ok)
+
+	now := time.Now()
+	report := &models.DriftReport{
+		ClusterID:  cluster.ID,
+		Status:     models.StatusDrifted,
+		Revision:   "r1",
+		DetectedAt: &now,
+		CheckedAt:  now,
+	}
+	report.SetResources([]models.Resource{{Group: "apps", Version: "v1", Kind: "Deployment",
+		Namespace: "ns", Name: "app-hz", FieldPaths: []string{"spec.replicas"}}})
+	_, err = manager.DriftReportMgr.Create(ctx, report)
+	assert.Nil(t, err)
+
+	got, err := c.GetDrift(ctx, cluster.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, models.StatusDrifted, got.Status)
+	assert.Equal(t, []string{"spec.replicas"}, got.Resources[0].FieldPaths)
+
+	// the drift detected against an outdated revision is not resynced
+	clusterGitRepo.EXPECT().GetConfigCommit(gomock.Any(), "app", "app-hz").
+		Return(&gitrepo.ClusterCommit{Master: "r2", Gitops: "r2"}, nil).Times(1)
+	_, err = c.Resync(ctx, cluster.ID)
+	assert.Equal(t, herrors.ErrDriftStatusInvalid, perror.Cause(err))
+
+	clusterGitRepo.EXPECT().GetConfigCommit(gomock.Any(), "app", "app-hz").
+		Return(&gitrepo.ClusterCommit{Master: "r1", Gitops: "r1"}, nil).Times(2)
+	cdMock.EXPECT().DeployCluster(gomock.Any(), &cd.DeployClusterParams{
+		Environment: "online",
+		Cluster:     "app-hz",
+		Revision:    "r1",
+	}).Return(nil).Times(2)
+	_, err = c.Resync(ctx, cluster.ID)
+	assert.Nil(t, err)
+
+	got, err = c.Accept(ctx, cluster.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, models.StatusAccepted, got.Status)
+	assert.Equal(t, uint(1), got.AcceptedBy)
+	assert.NotNil(t, got.AcceptedAt)
+
+	_, err = c.Accept(ctx, cluster.ID)
+	assert.Equal(t, herrors.ErrDriftStatusInvalid, perror.Cause(err))
+
+	// accepted drift can still be resynced
+	_, err = c.Resync(ctx, cluster.ID)
+	assert.Nil(t, err)
+
+	report.Status = models.StatusResolved
+	assert.Nil(t, manager.DriftReportMgr.UpdateByID(ctx, report.ID, report))
+	_, err = c.Resync(ctx, cluster.ID)
+	assert.Equal(t, herrors.ErrDriftStatusInvalid, perror.Cause(err))
+}
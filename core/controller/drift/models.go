@@ -0,0 +1,51 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package drift
+
+import (
+	"time"
+
+	"github.com/horizoncd/horizon/pkg/drift/models"
+)
+
+type Report struct {
+	ClusterID       uint              `json:"clusterID"`
+	Status          string            `json:"status"`
+	Revision        string            `json:"revision"`
+	ManifestVersion string            `json:"manifestVersion"`
+	Resources       []models.Resource `json:"resources"`
+	DetectedAt      *time.Time        `json:"detectedAt,omitempty"`
+	CheckedAt       time.Time         `json:"checkedAt"`
+	AcceptedAt      *time.Time        `json:"acceptedAt,omitempty"`
+	AcceptedBy      uint              `json:"acceptedBy,omitempty"`
+}
+
+func ofReportModel(report *models.DriftReport) *Report {
+	resources := report.GetResources()
+	if resources == nil {
+		resources = []models.Resource{}
+	}
+	return &Report{
+		ClusterID:       report.ClusterID,
+		Status:          report.Status,
+		Revision:        report.Revision,
+		ManifestVersion: report.ManifestVersion,
+		Resources:       resources,
+		DetectedAt:      report.DetectedAt,
+		CheckedAt:       report.CheckedAt,
+		AcceptedAt:      report.AcceptedAt,
+		AcceptedBy:      report.AcceptedBy,
+	}
+}
@@ -16,14 +16,22 @@ package environment
 
 import (
 	"context"
+	"strings"
 
+	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
 	environmentmanager "github.com/horizoncd/horizon/pkg/environment/manager"
 	"github.com/horizoncd/horizon/pkg/environment/models"
 	"github.com/horizoncd/horizon/pkg/environment/service"
 	envregionmanager "github.com/horizoncd/horizon/pkg/environmentregion/manager"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	eventmanager "github.com/horizoncd/horizon/pkg/event/manager"
+	eventmodels "github.com/horizoncd/horizon/pkg/event/models"
 	"github.com/horizoncd/horizon/pkg/param"
+	"github.com/horizoncd/horizon/pkg/rbac/role"
 	regionmanager "github.com/horizoncd/horizon/pkg/region/manager"
 	regionmodels "github.com/horizoncd/horizon/pkg/region/models"
+	"github.com/horizoncd/horizon/pkg/util/log"
 )
 
 type Controller interface {
@@ -46,6 +54,8 @@ func NewController(param *param.Param) Controller {
 		envMgr:       param.EnvMgr,
 		envRegionMgr: param.EnvRegionMgr,
 		regionMgr:    param.RegionMgr,
+		roleSvc:      param.RoleService,
+		eventMgr:     param.EventManager,
 	}
 }
 
@@ -54,6 +64,8 @@ type controller struct {
 	envRegionMgr envregionmanager.Manager
 	regionMgr    regionmanager.Manager
 	autoFreeSvc  *service.AutoFreeSVC
+	roleSvc      role.Service
+	eventMgr     eventmanager.Manager
 }
 
 func (c *controller) GetByID(ctx context.Context, id uint) (*Environment, error) {
@@ -74,20 +86,68 @@ func (c *controller) GetByName(ctx context.Context, name string) (*Environment,
 }
 
 func (c *controller) Create(ctx context.Context, request *CreateEnvironmentRequest) (uint, error) {
-	environment, err := c.envMgr.CreateEnvironment(ctx, &models.Environment{
-		Name:        request.Name,
-		DisplayName: request.DisplayName,
-	})
+	environment := &models.Environment{
+		Name:         request.Name,
+		DisplayName:  request.DisplayName,
+		Protected:    request.Protected,
+		ApproverRole: request.ApproverRole,
+		Approvers:    strings.Join(request.Approvers, models.ApproverSeparator),
+	}
+	if err := c.validateProtection(ctx, environment); err != nil {
+		return 0, err
+	}
+	environment, err := c.envMgr.CreateEnvironment(ctx, environment)
 	if err != nil {
 		return 0, err
 	}
+	c.recordEvent(ctx, environment.ID, eventmodels.EnvironmentCreated, nil, c.snapshot(environment))
 	return environment.ID, nil
 }
 
 func (c *controller) UpdateByID(ctx context.Context, id uint, request *UpdateEnvironmentRequest) error {
-	return c.envMgr.UpdateByID(ctx, id, &models.Environment{
-		DisplayName: request.DisplayName,
-	})
+	environment, err := c.envMgr.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	before := c.snapshot(environment)
+	environment.DisplayName = request.DisplayName
+	if request.Protected != nil {
+		environment.Protected = *request.Protected
+	}
+	if request.ApproverRole != nil {
+		environment.ApproverRole = *request.ApproverRole
+	}
+	if request.Approvers != nil {
+		environment.Approvers = strings.Join(request.Approvers, models.ApproverSeparator)
+	}
+	if err := c.validateProtection(ctx, environment); err != nil {
+		return err
+	}
+	if err := c.envMgr.UpdateByID(ctx, id, environment); err != nil {
+		return err
+	}
+	c.recordEvent(ctx, id, eventmodels.EnvironmentUpdated, before, c.snapshot(environment))
+	return nil
+}
+
+// validateProtection checks that a protected environment has someone to approve its operations
+func (c *controller) validateProtection(ctx context.Context, environment *models.Environment) error {
+	for _, approver := range environment.ApproverList() {
+		if approver == "" {
+			return perror.Wrap(herrors.ErrParamInvalid, "approver cannot be empty")
+		}
+	}
+	if environment.ApproverRole != "" {
+		if _, err := c.roleSvc.GetRole(ctx, environment.ApproverRole); err != nil {
+			return perror.Wrapf(herrors.ErrParamInvalid, "approver role %s is invalid: %v",
+				environment.ApproverRole, err)
+		}
+	}
+	if environment.Protected && environment.ApproverRole == "" && environment.Approvers == "" {
+		return perror.Wrap(herrors.ErrParamInvalid, "approver role or approvers must be specified "+
+			"for a protected environment")
+	}
+	return nil
 }
 
 func (c *controller) ListEnvironments(ctx context.Context) (_ Environments, err error) {
@@ -105,5 +165,26 @@ func (c *controller) ListEnabledRegionsByEnvironment(ctx context.Context, enviro
 }
 
 func (c *controller) DeleteByID(ctx context.Context, id uint) error {
-	return c.envMgr.DeleteByID(ctx, id)
+	environment, err := c.envMgr.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := c.envMgr.DeleteByID(ctx, id); err != nil {
+		return err
+	}
+	c.recordEvent(ctx, id, eventmodels.EnvironmentDeleted, c.snapshot(environment), nil)
+	return nil
+}
+
+func (c *controller) snapshot(environment *models.Environment) *Environment {
+	autoFree := c.autoFreeSvc != nil && c.autoFreeSvc.WhetherSupported(environment.Name)
+	return ofEnvironmentModel(environment, autoFree)
+}
+
+func (c *controller) recordEvent(ctx context.Context, id uint, eventType string,
+	before, after *Environment) {
+	if _, err := c.eventMgr.CreateMutationEvent(ctx, common.ResourceEnvironment, id,
+		eventType, before, after); err != nil {
+		log.Warningf(ctx, "failed to create event, err: %s", err.Error())
+	}
 }
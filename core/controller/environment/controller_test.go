@@ -16,15 +16,21 @@ package environment
 
 import (
 	"context"
+	"encoding/json"
 	"os"
 	"testing"
 
 	"github.com/horizoncd/horizon/core/common"
 	"github.com/horizoncd/horizon/core/controller/region"
+	herrors "github.com/horizoncd/horizon/core/errors"
 	"github.com/horizoncd/horizon/lib/orm"
+	appregionmodels "github.com/horizoncd/horizon/pkg/applicationregion/models"
 	userauth "github.com/horizoncd/horizon/pkg/authentication/user"
 	"github.com/horizoncd/horizon/pkg/environment/models"
 	"github.com/horizoncd/horizon/pkg/environment/service"
+	envregionmodels "github.com/horizoncd/horizon/pkg/environmentregion/models"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	eventmodels "github.com/horizoncd/horizon/pkg/event/models"
 	"github.com/horizoncd/horizon/pkg/param"
 	"github.com/horizoncd/horizon/pkg/param/managerparam"
 	regionmodels "github.com/horizoncd/horizon/pkg/region/models"
@@ -46,6 +52,10 @@ func TestMain(m *testing.M) {
 	if err := db.AutoMigrate(&regionmodels.Region{}); err != nil {
 		panic(err)
 	}
+	if err := db.AutoMigrate(&eventmodels.Event{}, &appregionmodels.ApplicationRegion{},
+		&envregionmodels.EnvironmentRegion{}); err != nil {
+		panic(err)
+	}
 	ctx = context.TODO()
 	ctx = context.WithValue(ctx, common.UserContextKey(), &userauth.DefaultInfo{
 		ID: uint(1),
@@ -106,4 +116,59 @@ func Test(t *testing.T) {
 	assert.Equal(t, 1, len(envs))
 	assert.Equal(t, "dev", envs[0].Name)
 	assert.Equal(t, "DEV-update", envs[0].DisplayName)
+	assert.Equal(t, false, envs[0].Protected)
+
+	// a protected environment must have approvers
+	protected := true
+	err = ctl.UpdateByID(ctx, devID, &UpdateEnvironmentRequest{
+		DisplayName: "DEV-update",
+		Protected:   &protected,
+	})
+	assert.Equal(t, herrors.ErrParamInvalid, perror.Cause(err))
+
+	err = ctl.UpdateByID(ctx, devID, &UpdateEnvironmentRequest{
+		DisplayName: "DEV-update",
+		Protected:   &protected,
+		Approvers:   []string{"tony", "jerry"},
+	})
+	assert.Nil(t, err)
+
+	// protection is kept when only the display name is updated
+	err = ctl.UpdateByID(ctx, devID, &UpdateEnvironmentRequest{
+		DisplayName: "DEV",
+	})
+	assert.Nil(t, err)
+	env, err = ctl.GetByID(ctx, devID)
+	assert.Nil(t, err)
+	assert.Equal(t, "DEV", env.DisplayName)
+	assert.Equal(t, true, env.Protected)
+	assert.Equal(t, []string{"tony", "jerry"}, env.Approvers)
+
+	// every mutation of the environment is recorded with its diff
+	events, err := manager.EventManager.ListEvents(ctx, nil)
+	assert.Nil(t, err)
+	envEvents := make([]*eventmodels.Event, 0)
+	for _, event := range events {
+		if event.ResourceType == common.ResourceEnvironment && event.ResourceID == devID {
+			envEvents = append(envEvents, event)
+		}
+	}
+	assert.Equal(t, 4, len(envEvents))
+	assert.Equal(t, eventmodels.EnvironmentCreated, envEvents[0].EventType)
+	assert.Equal(t, eventmodels.EnvironmentUpdated, envEvents[1].EventType)
+	extra := eventmodels.MutationExtra{}
+	assert.Nil(t, json.Unmarshal([]byte(*envEvents[1].Extra), &extra))
+	assert.Equal(t, "DEV", extra.Before["displayName"])
+	assert.Equal(t, "DEV-update", extra.After["displayName"])
+	assert.Contains(t, extra.Changes, "displayName")
+
+	assert.Nil(t, ctl.DeleteByID(ctx, devID))
+	events, err = manager.EventManager.ListEvents(ctx, nil)
+	assert.Nil(t, err)
+	last := events[len(events)-1]
+	assert.Equal(t, eventmodels.EnvironmentDeleted, last.EventType)
+	extra = eventmodels.MutationExtra{}
+	assert.Nil(t, json.Unmarshal([]byte(*last.Extra), &extra))
+	assert.Nil(t, extra.After)
+	assert.Equal(t, "dev", extra.Before["name"])
 }
@@ -22,12 +22,16 @@ import (
 )
 
 type Environment struct {
-	ID          uint      `json:"id"`
-	Name        string    `json:"name"`
-	DisplayName string    `json:"displayName"`
-	AutoFree    bool      `json:"autoFree"`
-	CreatedAt   time.Time `json:"createdAt"`
-	UpdatedAt   time.Time `json:"updatedAt"`
+	ID          uint   `json:"id"`
+	Name        string `json:"name"`
+	DisplayName string `json:"displayName"`
+	AutoFree    bool   `json:"autoFree"`
+	// Protected whether the operations on clusters need to be approved
+	Protected    bool      `json:"protected"`
+	ApproverRole string    `json:"approverRole,omitempty"`
+	Approvers    []string  `json:"approvers,omitempty"`
+	CreatedAt    time.Time `json:"createdAt"`
+	UpdatedAt    time.Time `json:"updatedAt"`
 }
 
 type Environments []*Environment
@@ -43,20 +47,32 @@ func ofEnvironmentModels(envs []*models.Environment, autoFreeSvc *service.AutoFr
 
 func ofEnvironmentModel(env *models.Environment, isAutoFree bool) *Environment {
 	return &Environment{
-		ID:          env.ID,
-		Name:        env.Name,
-		DisplayName: env.DisplayName,
-		AutoFree:    isAutoFree,
-		CreatedAt:   env.CreatedAt,
-		UpdatedAt:   env.UpdatedAt,
+		ID:           env.ID,
+		Name:         env.Name,
+		DisplayName:  env.DisplayName,
+		AutoFree:     isAutoFree,
+		Protected:    env.Protected,
+		ApproverRole: env.ApproverRole,
+		Approvers:    env.ApproverList(),
+		CreatedAt:    env.CreatedAt,
+		UpdatedAt:    env.UpdatedAt,
 	}
 }
 
 type CreateEnvironmentRequest struct {
 	Name        string `json:"name"`
 	DisplayName string `json:"displayName"`
+	// Protected deploy, builddeploy, rollback and restart must be approved by
+	// the members with ApproverRole or the users in Approvers
+	Protected    bool     `json:"protected"`
+	ApproverRole string   `json:"approverRole"`
+	Approvers    []string `json:"approvers"`
 }
 
 type UpdateEnvironmentRequest struct {
 	DisplayName string `json:"displayName"`
+	// the protection is kept unchanged when the following fields are not specified
+	Protected    *bool    `json:"protected"`
+	ApproverRole *string  `json:"approverRole"`
+	Approvers    []string `json:"approvers"`
 }
@@ -0,0 +1,228 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package freeze
+
+import (
+	"context"
+	"time"
+
+	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	applicationmanager "github.com/horizoncd/horizon/pkg/application/manager"
+	envmanager "github.com/horizoncd/horizon/pkg/environment/manager"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	freezemanager "github.com/horizoncd/horizon/pkg/freeze/manager"
+	"github.com/horizoncd/horizon/pkg/freeze/models"
+	groupmanager "github.com/horizoncd/horizon/pkg/group/manager"
+	"github.com/horizoncd/horizon/pkg/param"
+	"github.com/horizoncd/horizon/pkg/util/wlog"
+	"github.com/robfig/cron/v3"
+)
+
+type Controller interface {
+	CreateFreezeWindow(ctx context.Context, resourceType string,
+		resourceID uint, r *CreateFreezeWindowRequest) (*FreezeWindow, error)
+	GetFreezeWindow(ctx context.Context, id uint) (*FreezeWindow, error)
+	ListFreezeWindows(ctx context.Context, resourceType string, resourceID uint) ([]*FreezeWindow, error)
+	UpdateFreezeWindow(ctx context.Context, id uint, r *UpdateFreezeWindowRequest) (*FreezeWindow, error)
+	DeleteFreezeWindow(ctx context.Context, id uint) error
+}
+
+type controller struct {
+	freezeWindowMgr freezemanager.Manager
+	groupMgr        groupmanager.Manager
+	applicationMgr  applicationmanager.Manager
+	envMgr          envmanager.Manager
+}
+
+var _ Controller = (*controller)(nil)
+
+func NewController(param *param.Param) Controller {
+	return &controller{
+		freezeWindowMgr: param.FreezeWindowMgr,
+		groupMgr:        param.GroupManager,
+		applicationMgr:  param.ApplicationManager,
+		envMgr:          param.EnvMgr,
+	}
+}
+
+func (c *controller) CreateFreezeWindow(ctx context.Context, resourceType string,
+	resourceID uint, r *CreateFreezeWindowRequest) (*FreezeWindow, error) {
+	const op = "freeze window controller: create"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	// 1. validate request
+	if err := c.checkResource(ctx, resourceType, resourceID); err != nil {
+		return nil, err
+	}
+	window := r.toModel(resourceType, resourceID)
+	if err := validateFreezeWindow(window); err != nil {
+		return nil, err
+	}
+
+	// 2. create freeze window
+	window, err := c.freezeWindowMgr.Create(ctx, window)
+	if err != nil {
+		return nil, err
+	}
+	return ofFreezeWindowModel(window, time.Now()), nil
+}
+
+func (c *controller) GetFreezeWindow(ctx context.Context, id uint) (*FreezeWindow, error) {
+	const op = "freeze window controller: get"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	window, err := c.freezeWindowMgr.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return ofFreezeWindowModel(window, time.Now()), nil
+}
+
+func (c *controller) ListFreezeWindows(ctx context.Context, resourceType string,
+	resourceID uint) ([]*FreezeWindow, error) {
+	const op = "freeze window controller: list"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	if err := validateResourceType(resourceType); err != nil {
+		return nil, err
+	}
+	windows, err := c.freezeWindowMgr.ListByResources(ctx, map[string][]uint{
+		resourceType: {resourceID},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	result := make([]*FreezeWindow, 0, len(windows))
+	for _, window := range windows {
+		result = append(result, ofFreezeWindowModel(window, now))
+	}
+	return result, nil
+}
+
+func (c *controller) UpdateFreezeWindow(ctx context.Context, id uint,
+	r *UpdateFreezeWindowRequest) (*FreezeWindow, error) {
+	const op = "freeze window controller: update"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	window, err := c.freezeWindowMgr.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.checkResource(ctx, window.ResourceType, window.ResourceID); err != nil {
+		return nil, err
+	}
+	window = r.toModel(window)
+	if err := validateFreezeWindow(window); err != nil {
+		return nil, err
+	}
+
+	if err := c.freezeWindowMgr.UpdateByID(ctx, id, window); err != nil {
+		return nil, err
+	}
+	window, err = c.freezeWindowMgr.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return ofFreezeWindowModel(window, time.Now()), nil
+}
+
+func (c *controller) DeleteFreezeWindow(ctx context.Context, id uint) error {
+	const op = "freeze window controller: delete"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	window, err := c.freezeWindowMgr.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := c.checkResource(ctx, window.ResourceType, window.ResourceID); err != nil {
+		return err
+	}
+	return c.freezeWindowMgr.DeleteByID(ctx, id)
+}
+
+// checkResource checks that the resource which the freeze window is attached to exists.
+// environments are not authorized by rbac, so only admins can manage their freeze windows
+func (c *controller) checkResource(ctx context.Context, resourceType string, resourceID uint) error {
+	if err := validateResourceType(resourceType); err != nil {
+		return err
+	}
+
+	var err error
+	switch resourceType {
+	case common.ResourceGroup:
+		_, err = c.groupMgr.GetByID(ctx, resourceID)
+	case common.ResourceApplication:
+		_, err = c.applicationMgr.GetByID(ctx, resourceID)
+	case common.ResourceEnvironment:
+		currentUser, e := common.UserFromContext(ctx)
+		if e != nil {
+			return e
+		}
+		if !currentUser.IsAdmin() {
+			return perror.Wrap(herrors.ErrForbidden,
+				"only admin can manage freeze windows of environments")
+		}
+		_, err = c.envMgr.GetByID(ctx, resourceID)
+	}
+	return err
+}
+
+func validateResourceType(resourceType string) error {
+	switch resourceType {
+	case common.ResourceGroup, common.ResourceApplication, common.ResourceEnvironment:
+	default:
+		return perror.Wrapf(herrors.ErrParamInvalid, "invalid resource type %s", resourceType)
+	}
+	return nil
+}
+
+// validateFreezeWindow checks that the window is either an absolute range or a cron schedule with a duration
+func validateFreezeWindow(window *models.FreezeWindow) error {
+	if window.Name == "" {
+		return perror.Wrap(herrors.ErrParamInvalid, "name should not be empty")
+	}
+
+	if window.Cron == "" {
+		if window.StartAt == nil || window.EndAt == nil {
+			return perror.Wrap(herrors.ErrParamInvalid,
+				"either startAt and endAt or cron and duration should be specified")
+		}
+		if !window.StartAt.Before(*window.EndAt) {
+			return perror.Wrap(herrors.ErrParamInvalid, "startAt should be before endAt")
+		}
+		if window.Duration != "" {
+			return perror.Wrap(herrors.ErrParamInvalid, "duration is only valid with cron")
+		}
+		return nil
+	}
+
+	if window.StartAt != nil || window.EndAt != nil {
+		return perror.Wrap(herrors.ErrParamInvalid, "startAt and endAt are not valid with cron")
+	}
+	if _, err := cron.ParseStandard(window.Cron); err != nil {
+		return perror.Wrapf(herrors.ErrParamInvalid, "invalid cron %s: %v", window.Cron, err)
+	}
+	duration, err := time.ParseDuration(window.Duration)
+	if err != nil {
+		return perror.Wrapf(herrors.ErrParamInvalid, "invalid duration %s: %v", window.Duration, err)
+	}
+	if duration <= 0 {
+		return perror.Wrap(herrors.ErrParamInvalid, "duration should be positive")
+	}
+	return nil
+}
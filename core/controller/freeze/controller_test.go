@@ -0,0 +1,125 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package freeze
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/lib/orm"
+	applicationmodels "github.com/horizoncd/horizon/pkg/application/models"
+	userauth "github.com/horizoncd/horizon/pkg/authentication/user"
+	envmodels "github.com/horizoncd/horizon/pkg/environment/models"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/freeze/models"
+	groupmodels "github.com/horizoncd/horizon/pkg/group/models"
+	"github.com/horizoncd/horizon/pkg/param"
+	"github.com/horizoncd/horizon/pkg/param/managerparam"
+	utilcommon "github.com/horizoncd/horizon/pkg/util/common"
+)
+
+func Test(t *testing.T) {
+	db, _ := orm.NewSqliteDB("")
+	if err := db.AutoMigrate(&models.FreezeWindow{}, &groupmodels.Group{},
+		&applicationmodels.Application{}, &envmodels.Environment{}); err != nil {
+		panic(err)
+	}
+	// nolint
+	ctx := common.WithContext(context.Background(), &userauth.DefaultInfo{
+		Name: "Tony",
+		ID:   1,
+	})
+	// nolint
+	adminCtx := common.WithContext(context.Background(), &userauth.DefaultInfo{
+		Name:  "Jerry",
+		ID:    2,
+		Admin: true,
+	})
+	c := NewController(&param.Param{Manager: managerparam.InitManager(db)})
+
+	group := &groupmodels.Group{Name: "a", Path: "a", TraversalIDs: "1"}
+	db.Save(group)
+	env := &envmodels.Environment{Name: "online", DisplayName: "online"}
+	db.Save(env)
+
+	now := time.Now()
+	start, end := now.Add(-time.Hour), now.Add(time.Hour)
+
+	// invalid requests
+	_, err := c.CreateFreezeWindow(ctx, common.ResourceCluster, 1,
+		&CreateFreezeWindowRequest{Name: "a", StartAt: &start, EndAt: &end})
+	assert.Equal(t, herrors.ErrParamInvalid, perror.Cause(err))
+	_, err = c.CreateFreezeWindow(ctx, common.ResourceGroup, group.ID,
+		&CreateFreezeWindowRequest{Name: "a", StartAt: &end, EndAt: &start})
+	assert.Equal(t, herrors.ErrParamInvalid, perror.Cause(err))
+	_, err = c.CreateFreezeWindow(ctx, common.ResourceGroup, group.ID,
+		&CreateFreezeWindowRequest{Name: "a", Cron: "0 0 * * 6"})
+	assert.Equal(t, herrors.ErrParamInvalid, perror.Cause(err))
+	_, err = c.CreateFreezeWindow(ctx, common.ResourceGroup, group.ID,
+		&CreateFreezeWindowRequest{Name: "a", Cron: "0 0 * * 6", Duration: "48h", StartAt: &start})
+	assert.Equal(t, herrors.ErrParamInvalid, perror.Cause(err))
+	_, err = c.CreateFreezeWindow(ctx, common.ResourceGroup, group.ID+1,
+		&CreateFreezeWindowRequest{Name: "a", StartAt: &start, EndAt: &end})
+	_, ok := perror.Cause(err).(*herrors.HorizonErrNotFound)
+	assert.True(t, ok)
+
+	// only admin can manage freeze windows of environments
+	_, err = c.CreateFreezeWindow(ctx, common.ResourceEnvironment, env.ID,
+		&CreateFreezeWindowRequest{Name: "a", StartAt: &start, EndAt: &end})
+	assert.Equal(t, herrors.ErrForbidden, perror.Cause(err))
+	envWindow, err := c.CreateFreezeWindow(adminCtx, common.ResourceEnvironment, env.ID,
+		&CreateFreezeWindowRequest{Name: "a", StartAt: &start, EndAt: &end})
+	assert.Nil(t, err)
+	assert.True(t, envWindow.Active)
+
+	window, err := c.CreateFreezeWindow(ctx, common.ResourceGroup, group.ID,
+		&CreateFreezeWindowRequest{Name: "release", Cron: "0 0 * * 6", Duration: "48h"})
+	assert.Nil(t, err)
+	assert.Equal(t, "release", window.Name)
+
+	window, err = c.GetFreezeWindow(ctx, window.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, "0 0 * * 6", window.Cron)
+
+	// switch to an absolute window
+	window, err = c.UpdateFreezeWindow(ctx, window.ID, &UpdateFreezeWindowRequest{
+		Description: utilcommon.StringPtr("holiday"),
+		StartAt:     &start,
+		EndAt:       &end,
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, "holiday", window.Description)
+	assert.Equal(t, "", window.Cron)
+	assert.Equal(t, "", window.Duration)
+	assert.True(t, window.Active)
+
+	windows, err := c.ListFreezeWindows(ctx, common.ResourceGroup, group.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(windows))
+
+	err = c.DeleteFreezeWindow(ctx, window.ID)
+	assert.Nil(t, err)
+	windows, err = c.ListFreezeWindows(ctx, common.ResourceGroup, group.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(windows))
+
+	err = c.DeleteFreezeWindow(ctx, envWindow.ID)
+	assert.Equal(t, herrors.ErrForbidden, perror.Cause(err))
+}
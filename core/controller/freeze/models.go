@@ -0,0 +1,115 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package freeze
+
+import (
+	"time"
+
+	"github.com/horizoncd/horizon/pkg/freeze/models"
+)
+
+type CreateFreezeWindowRequest struct {
+	Name        string     `json:"name"`
+	Description string     `json:"description"`
+	StartAt     *time.Time `json:"startAt"`
+	EndAt       *time.Time `json:"endAt"`
+	Cron        string     `json:"cron"`
+	Duration    string     `json:"duration"`
+}
+
+type UpdateFreezeWindowRequest struct {
+	Name        *string    `json:"name"`
+	Description *string    `json:"description"`
+	StartAt     *time.Time `json:"startAt"`
+	EndAt       *time.Time `json:"endAt"`
+	Cron        *string    `json:"cron"`
+	Duration    *string    `json:"duration"`
+}
+
+type FreezeWindow struct {
+	CreateFreezeWindowRequest
+	ID           uint       `json:"id"`
+	ResourceType string     `json:"resourceType"`
+	ResourceID   uint       `json:"resourceID"`
+	Active       bool       `json:"active"`
+	ActiveUntil  *time.Time `json:"activeUntil,omitempty"`
+	CreatedAt    time.Time  `json:"createdAt"`
+	UpdatedAt    time.Time  `json:"updatedAt"`
+}
+
+func (r *CreateFreezeWindowRequest) toModel(resourceType string, resourceID uint) *models.FreezeWindow {
+	return &models.FreezeWindow{
+		ResourceType: resourceType,
+		ResourceID:   resourceID,
+		Name:         r.Name,
+		Description:  r.Description,
+		StartAt:      r.StartAt,
+		EndAt:        r.EndAt,
+		Cron:         r.Cron,
+		Duration:     r.Duration,
+	}
+}
+
+func (r *UpdateFreezeWindowRequest) toModel(window *models.FreezeWindow) *models.FreezeWindow {
+	if r.Name != nil {
+		window.Name = *r.Name
+	}
+	if r.Description != nil {
+		window.Description = *r.Description
+	}
+	// switching between an absolute window and a recurring one clears the other kind
+	if r.Cron != nil {
+		window.Cron = *r.Cron
+		if window.Cron != "" {
+			window.StartAt, window.EndAt = nil, nil
+		}
+	}
+	if r.Duration != nil {
+		window.Duration = *r.Duration
+	}
+	if r.StartAt != nil || r.EndAt != nil {
+		if r.StartAt != nil {
+			window.StartAt = r.StartAt
+		}
+		if r.EndAt != nil {
+			window.EndAt = r.EndAt
+		}
+		window.Cron, window.Duration = "", ""
+	}
+	return window
+}
+
+func ofFreezeWindowModel(window *models.FreezeWindow, now time.Time) *FreezeWindow {
+	w := &FreezeWindow{
+		CreateFreezeWindowRequest: CreateFreezeWindowRequest{
+			Name:        window.Name,
+			Description: window.Description,
+			StartAt:     window.StartAt,
+			EndAt:       window.EndAt,
+			Cron:        window.Cron,
+			Duration:    window.Duration,
+		},
+		ID:           window.ID,
+		ResourceType: window.ResourceType,
+		ResourceID:   window.ResourceID,
+		CreatedAt:    window.CreatedAt,
+		UpdatedAt:    window.UpdatedAt,
+	}
+	if until, ok := window.ActiveUntil(now); ok {
+		w.Active = true
+		w.ActiveUntil = &until
+	}
+	return w
+}
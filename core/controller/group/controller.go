@@ -30,6 +30,8 @@ import (
 	appmodels "github.com/horizoncd/horizon/pkg/application/models"
 	clustermanager "github.com/horizoncd/horizon/pkg/cluster/manager"
 	perror "github.com/horizoncd/horizon/pkg/errors"
+	eventmanager "github.com/horizoncd/horizon/pkg/event/manager"
+	eventmodels "github.com/horizoncd/horizon/pkg/event/models"
 	groupmanager "github.com/horizoncd/horizon/pkg/group/manager"
 	"github.com/horizoncd/horizon/pkg/group/models"
 	"github.com/horizoncd/horizon/pkg/group/service"
@@ -39,6 +41,7 @@ import (
 	tmanager "github.com/horizoncd/horizon/pkg/template/manager"
 	trmanager "github.com/horizoncd/horizon/pkg/templaterelease/manager"
 	"github.com/horizoncd/horizon/pkg/util/errors"
+	"github.com/horizoncd/horizon/pkg/util/log"
 	"github.com/horizoncd/horizon/pkg/util/wlog"
 )
 
@@ -83,6 +86,7 @@ type controller struct {
 	memberSvc          memberservice.Service
 	templateMgr        tmanager.Manager
 	templateReleaseMgr trmanager.Manager
+	eventMgr           eventmanager.Manager
 }
 
 // NewController initializes a new group controller
@@ -94,6 +98,7 @@ func NewController(param *param.Param) Controller {
 		memberSvc:          param.MemberService,
 		templateMgr:        param.TemplateMgr,
 		templateReleaseMgr: param.TemplateReleaseManager,
+		eventMgr:           param.EventManager,
 	}
 }
 
@@ -309,24 +314,43 @@ func (c *controller) GetSubGroups(ctx context.Context, id uint, pageNumber, page
 
 // UpdateBasic update basic info of a group, including name, path, description and visibilityLevel
 func (c *controller) UpdateBasic(ctx context.Context, id uint, updateGroup *UpdateGroup) error {
+	before, err := c.groupManager.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
 	group := convertUpdateGroupToGroup(updateGroup)
 	group.ID = id
 
-	err := c.groupManager.UpdateBasic(ctx, group)
+	err = c.groupManager.UpdateBasic(ctx, group)
 	if err != nil {
 		return err
 	}
 
+	c.recordEvent(ctx, id, eventmodels.GroupUpdated, &UpdateGroup{
+		Name:            before.Name,
+		Path:            before.Path,
+		VisibilityLevel: before.VisibilityLevel,
+		Description:     before.Description,
+	}, updateGroup)
 	return nil
 }
 
 // Transfer put a group under another parent group
 func (c *controller) Transfer(ctx context.Context, id, newParentID uint) error {
-	err := c.groupManager.Transfer(ctx, id, newParentID)
+	before, err := c.groupManager.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	err = c.groupManager.Transfer(ctx, id, newParentID)
 	if err != nil {
 		return err
 	}
 
+	c.recordEvent(ctx, id, eventmodels.GroupTransferred,
+		map[string]interface{}{"name": before.Name, "parentID": before.ParentID},
+		map[string]interface{}{"name": before.Name, "parentID": newParentID})
 	return nil
 }
 
@@ -339,6 +363,7 @@ func (c *controller) CreateGroup(ctx context.Context, newGroup *NewGroup) (uint,
 		return 0, err
 	}
 
+	c.recordEvent(ctx, group.ID, eventmodels.GroupCreated, nil, newGroup)
 	return group.ID, err
 }
 
@@ -571,6 +596,14 @@ func (c *controller) GetByID(ctx context.Context, id uint) (*StructuredGroup, er
 func (c *controller) Delete(ctx context.Context, id uint) error {
 	const op = "group *controller: delete group by id"
 
+	before, err := c.groupManager.GetByID(ctx, id)
+	if err != nil {
+		if _, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); ok {
+			return errors.E(op, http.StatusNotFound, ErrCodeNotFound, fmt.Sprintf("no group matching the id: %d", id))
+		}
+		return err
+	}
+
 	rowsAffected, err := c.groupManager.Delete(ctx, id)
 	if err != nil {
 		if err == herrors.ErrGroupHasChildren {
@@ -582,6 +615,13 @@ func (c *controller) Delete(ctx context.Context, id uint) error {
 		return errors.E(op, http.StatusNotFound, ErrCodeNotFound, fmt.Sprintf("no group matching the id: %d", id))
 	}
 
+	c.recordEvent(ctx, id, eventmodels.GroupDeleted, &NewGroup{
+		Name:            before.Name,
+		Path:            before.Path,
+		VisibilityLevel: before.VisibilityLevel,
+		Description:     before.Description,
+		ParentID:        before.ParentID,
+	}, nil)
 	return nil
 }
 
@@ -692,5 +732,23 @@ func (c *controller) UpdateRegionSelector(ctx context.Context, id uint, regionSe
 		return herrors.NewErrUpdateFailed(herrors.GroupInDB, err.Error())
 	}
 
-	return c.groupManager.UpdateRegionSelector(ctx, id, string(regionSelectorBytes))
+	before, err := c.groupManager.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := c.groupManager.UpdateRegionSelector(ctx, id, string(regionSelectorBytes)); err != nil {
+		return err
+	}
+
+	c.recordEvent(ctx, id, eventmodels.GroupUpdated, map[string]string{"regionSelector": before.RegionSelector},
+		map[string]string{"regionSelector": string(regionSelectorBytes)})
+	return nil
+}
+
+// recordEvent records the mutation of the group, the mutation is done even if it fails
+func (c *controller) recordEvent(ctx context.Context, id uint, eventType string, before, after interface{}) {
+	if _, err := c.eventMgr.CreateMutationEvent(ctx, common.ResourceGroup, id,
+		eventType, before, after); err != nil {
+		log.Warningf(ctx, "failed to create event, err: %s", err.Error())
+	}
 }
@@ -16,6 +16,7 @@ package group
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"os"
 	"reflect"
@@ -32,6 +33,7 @@ import (
 	applicationdao "github.com/horizoncd/horizon/pkg/application/dao"
 	appmodels "github.com/horizoncd/horizon/pkg/application/models"
 	userauth "github.com/horizoncd/horizon/pkg/authentication/user"
+	eventmodels "github.com/horizoncd/horizon/pkg/event/models"
 	"github.com/horizoncd/horizon/pkg/group/models"
 	"github.com/horizoncd/horizon/pkg/group/service"
 	membermodels "github.com/horizoncd/horizon/pkg/member/models"
@@ -91,6 +93,11 @@ func init() {
 		fmt.Printf("%+v", err)
 		os.Exit(1)
 	}
+	err = db.AutoMigrate(&eventmodels.Event{})
+	if err != nil {
+		fmt.Printf("%+v", err)
+		os.Exit(1)
+	}
 
 	callbacks.RegisterCustomCallbacks(db)
 }
@@ -1221,6 +1228,23 @@ func TestControllerTransfer(t *testing.T) {
 	assert.Equal(t, "/c/a/b", group2.FullPath)
 	assert.Equal(t, strconv.Itoa(int(id3))+","+strconv.Itoa(int(id))+","+strconv.Itoa(int(id2)), group2.TraversalIDs)
 
+	// the transfer is recorded with the parent before and after
+	events, err := manager.EventManager.ListEvents(ctx, nil)
+	assert.Nil(t, err)
+	var transferred *eventmodels.Event
+	for _, event := range events {
+		if event.EventType == eventmodels.GroupTransferred && event.ResourceID == id {
+			transferred = event
+		}
+	}
+	assert.NotNil(t, transferred)
+	assert.Equal(t, common.ResourceGroup, transferred.ResourceType)
+	extra := eventmodels.MutationExtra{}
+	assert.Nil(t, json.Unmarshal([]byte(*transferred.Extra), &extra))
+	assert.Equal(t, float64(0), extra.Before["parentID"])
+	assert.Equal(t, float64(id3), extra.After["parentID"])
+	assert.Equal(t, []string{"parentID"}, extra.Changes)
+
 	db.Session(&gorm.Session{AllowGlobalUpdate: true}).Delete(&models.Group{})
 }
 
@@ -26,12 +26,16 @@ import (
 	herrors "github.com/horizoncd/horizon/core/errors"
 	"github.com/horizoncd/horizon/lib/q"
 	perror "github.com/horizoncd/horizon/pkg/errors"
+	eventmanager "github.com/horizoncd/horizon/pkg/event/manager"
+	eventmodels "github.com/horizoncd/horizon/pkg/event/models"
 	"github.com/horizoncd/horizon/pkg/idp/manager"
+	idpmodels "github.com/horizoncd/horizon/pkg/idp/models"
 	"github.com/horizoncd/horizon/pkg/idp/utils"
 	"github.com/horizoncd/horizon/pkg/param"
 	usermanager "github.com/horizoncd/horizon/pkg/user/manager"
 	usermodel "github.com/horizoncd/horizon/pkg/user/models"
 	linkmanager "github.com/horizoncd/horizon/pkg/userlink/manager"
+	"github.com/horizoncd/horizon/pkg/util/log"
 	"golang.org/x/oauth2"
 )
 
@@ -55,6 +59,7 @@ type controller struct {
 	idpManager  manager.Manager
 	userManager usermanager.Manager
 	linkManager linkmanager.Manager
+	eventMgr    eventmanager.Manager
 }
 
 func NewController(param *param.Param) Controller {
@@ -62,6 +67,7 @@ func NewController(param *param.Param) Controller {
 		idpManager:  param.IdpManager,
 		userManager: param.UserManager,
 		linkManager: param.UserLinksManager,
+		eventMgr:    param.EventManager,
 	}
 }
 
@@ -213,26 +219,54 @@ func (c *controller) Create(ctx context.Context,
 	if err != nil {
 		return nil, err
 	}
+	c.recordEvent(ctx, idp.ID, eventmodels.IdpCreated, nil, idp)
 	return ofIDPModel(idp), err
 }
 
 func (c *controller) Delete(ctx context.Context, idpID uint) error {
-	if _, err := c.idpManager.GetByID(ctx, idpID); err != nil {
+	idp, err := c.idpManager.GetByID(ctx, idpID)
+	if err != nil {
+		return err
+	}
+	if err := c.idpManager.Delete(ctx, idpID); err != nil {
 		return err
 	}
-	return c.idpManager.Delete(ctx, idpID)
+	c.recordEvent(ctx, idpID, eventmodels.IdpDeleted, idp, nil)
+	return nil
 }
 
 func (c *controller) Update(ctx context.Context,
 	id uint, updateParam *UpdateIDPRequest) (*IdentityProvider, error) {
+	before, err := c.idpManager.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
 	updateIDP := updateParam.toModel()
 	idp, err := c.idpManager.Update(ctx, id, updateIDP)
 	if err != nil {
 		return nil, err
 	}
+	c.recordEvent(ctx, id, eventmodels.IdpUpdated, before, idp)
 	return ofIDPModel(idp), nil
 }
 
+// recordEvent creates an event of the idp, client secret is never recorded
+func (c *controller) recordEvent(ctx context.Context, id uint, eventType string,
+	before, after *idpmodels.IdentityProvider) {
+	snapshot := func(idp *idpmodels.IdentityProvider) *IdentityProvider {
+		if idp == nil {
+			return nil
+		}
+		res := ofIDPModel(idp)
+		res.ClientSecret = ""
+		return res
+	}
+	if _, err := c.eventMgr.CreateMutationEvent(ctx, common.ResourceIdp, id,
+		eventType, snapshot(before), snapshot(after)); err != nil {
+		log.Warningf(ctx, "failed to create event, err: %s", err.Error())
+	}
+}
+
 func (c *controller) GetDiscovery(ctx context.Context, s Discovery) (*DiscoveryConfig, error) {
 	issuer := strings.TrimSuffix(
 		strings.TrimSuffix(s.FromURL, "/"),
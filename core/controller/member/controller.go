@@ -18,8 +18,11 @@ import (
 	"context"
 	"strconv"
 
+	eventmanager "github.com/horizoncd/horizon/pkg/event/manager"
+	eventmodels "github.com/horizoncd/horizon/pkg/event/models"
 	memberservice "github.com/horizoncd/horizon/pkg/member/service"
 	"github.com/horizoncd/horizon/pkg/param"
+	"github.com/horizoncd/horizon/pkg/util/log"
 )
 
 type Controller interface {
@@ -40,12 +43,14 @@ func NewController(param *param.Param) Controller {
 	return &controller{
 		memberService: param.MemberService,
 		convertHelper: New(param),
+		eventMgr:      param.EventManager,
 	}
 }
 
 type controller struct {
 	memberService memberservice.Service
 	convertHelper ConvertMemberHelp
+	eventMgr      eventmanager.Manager
 }
 
 func (c *controller) CreateMember(ctx context.Context, postMember *PostMember) (*Member, error) {
@@ -57,10 +62,20 @@ func (c *controller) CreateMember(ctx context.Context, postMember *PostMember) (
 	if err != nil {
 		return nil, err
 	}
+	c.recordEvent(ctx, retMember, eventmodels.MemberAdded, nil, ofMemberExtra(retMember))
 	return retMember, nil
 }
 
 func (c *controller) UpdateMember(ctx context.Context, id uint, role string) (*Member, error) {
+	before, err := c.memberService.GetMember(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	beforeRole := ""
+	if before != nil {
+		beforeRole = before.Role
+	}
+
 	member, err := c.memberService.UpdateMember(ctx, id, role)
 	if err != nil {
 		return nil, err
@@ -69,11 +84,57 @@ func (c *controller) UpdateMember(ctx context.Context, id uint, role string) (*M
 	if err != nil {
 		return nil, err
 	}
+	beforeExtra := ofMemberExtra(retMember)
+	beforeExtra.Role = beforeRole
+	c.recordEvent(ctx, retMember, eventmodels.MemberRoleUpdated, beforeExtra, ofMemberExtra(retMember))
 	return retMember, nil
 }
 
 func (c *controller) RemoveMember(ctx context.Context, id uint) error {
-	return c.memberService.RemoveMember(ctx, id)
+	before, err := c.memberService.GetMember(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := c.memberService.RemoveMember(ctx, id); err != nil {
+		return err
+	}
+	if before == nil {
+		return nil
+	}
+	retMember, err := c.convertHelper.ConvertMember(ctx, before)
+	if err != nil {
+		log.Warningf(ctx, "failed to convert member %d, err: %s", id, err.Error())
+		return nil
+	}
+	c.recordEvent(ctx, retMember, eventmodels.MemberRemoved, ofMemberExtra(retMember), nil)
+	return nil
+}
+
+// memberExtra is the member in the extra of the events of members
+type memberExtra struct {
+	ID           uint   `json:"id"`
+	MemberType   uint8  `json:"memberType"`
+	MemberName   string `json:"memberName"`
+	MemberNameID uint   `json:"memberNameID"`
+	Role         string `json:"role"`
+}
+
+func ofMemberExtra(member *Member) *memberExtra {
+	return &memberExtra{
+		ID:           member.ID,
+		MemberType:   uint8(member.MemberType),
+		MemberName:   member.MemberName,
+		MemberNameID: member.MemberNameID,
+		Role:         member.Role,
+	}
+}
+
+// recordEvent records the mutation of the member on its resource, the mutation is done even if it fails
+func (c *controller) recordEvent(ctx context.Context, member *Member, eventType string, before, after interface{}) {
+	if _, err := c.eventMgr.CreateMutationEvent(ctx, string(member.ResourceType), member.ResourceID,
+		eventType, before, after); err != nil {
+		log.Warningf(ctx, "failed to create event, err: %s", err.Error())
+	}
 }
 
 func (c *controller) ListMember(ctx context.Context, resourceType string, id uint) ([]Member, error) {
@@ -30,12 +30,15 @@ import (
 	"github.com/horizoncd/horizon/pkg/param"
 	"github.com/horizoncd/horizon/pkg/rbac/types"
 	tokenmanager "github.com/horizoncd/horizon/pkg/token/manager"
+	tokenmodels "github.com/horizoncd/horizon/pkg/token/models"
 	usermanager "github.com/horizoncd/horizon/pkg/user/manager"
 )
 
 type Controller interface {
 	ValidateToken(ctx context.Context, token string) error
 	LoadAccessTokenUser(ctx context.Context, token string) (user.User, error)
+	// LoadAccessToken loads the access token by its code
+	LoadAccessToken(ctx context.Context, token string) (*tokenmodels.Token, error)
 	CheckScopePermission(ctx context.Context, token string, authInfo auth.RequestInfo) (bool, string, error)
 }
 
@@ -98,6 +101,10 @@ func (c *controller) LoadAccessTokenUser(ctx context.Context, accessToken string
 	}, nil
 }
 
+func (c *controller) LoadAccessToken(ctx context.Context, accessToken string) (*tokenmodels.Token, error) {
+	return c.tokenManager.LoadTokenByCode(ctx, accessToken)
+}
+
 func (c *controller) CheckScopePermission(ctx context.Context, accessToken string,
 	requestInfo auth.RequestInfo) (bool, string, error) {
 	token, err := c.tokenManager.LoadTokenByCode(ctx, accessToken)
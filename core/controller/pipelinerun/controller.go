@@ -21,26 +21,40 @@ import (
 
 	"github.com/horizoncd/horizon/lib/q"
 	appmanager "github.com/horizoncd/horizon/pkg/application/manager"
+	"github.com/horizoncd/horizon/pkg/cluster/ci"
 	"github.com/horizoncd/horizon/pkg/cluster/code"
 	codemodels "github.com/horizoncd/horizon/pkg/cluster/code"
 	"github.com/horizoncd/horizon/pkg/cluster/gitrepo"
 	clustermanager "github.com/horizoncd/horizon/pkg/cluster/manager"
 	"github.com/horizoncd/horizon/pkg/cluster/tekton/collector"
-	"github.com/horizoncd/horizon/pkg/cluster/tekton/factory"
 	envmanager "github.com/horizoncd/horizon/pkg/environment/manager"
 	perror "github.com/horizoncd/horizon/pkg/errors"
 	"github.com/horizoncd/horizon/pkg/param"
 	prmanager "github.com/horizoncd/horizon/pkg/pipelinerun/manager"
 	"github.com/horizoncd/horizon/pkg/pipelinerun/models"
 	prmodels "github.com/horizoncd/horizon/pkg/pipelinerun/models"
+	prprovenancemanager "github.com/horizoncd/horizon/pkg/pipelinerun/provenance/manager"
+	prreportmanager "github.com/horizoncd/horizon/pkg/pipelinerun/report/manager"
 	usermanager "github.com/horizoncd/horizon/pkg/user/manager"
 	"github.com/horizoncd/horizon/pkg/util/errors"
+	"github.com/horizoncd/horizon/pkg/util/log"
 	"github.com/horizoncd/horizon/pkg/util/wlog"
 )
 
 type Controller interface {
 	GetPipelinerunLog(ctx context.Context, pipelinerunID uint) (*collector.Log, error)
 	GetClusterLatestLog(ctx context.Context, clusterID uint) (*collector.Log, error)
+	// StreamPipelinerunLog calls fn with the lines of the log from offset until the pipelinerun finishes,
+	// the live log is followed and the lines missed are read from the collected log after it's finished
+	StreamPipelinerunLog(ctx context.Context, pipelinerunID uint, offset int,
+		fn func(line *collector.LogLine) error) (*StreamLogResult, error)
+	// ListReports lists the test, coverage and vulnerability reports of the pipelinerun,
+	// the reports produced so far are read from the engine while the pipelinerun is running
+	ListReports(ctx context.Context, pipelinerunID uint) ([]*Report, error)
+	// GetReportFile gets the file of a collected report
+	GetReportFile(ctx context.Context, pipelinerunID, reportID uint) (*Report, []byte, error)
+	// GetProvenance gets the provenance of the pipelinerun signed by tekton chains
+	GetProvenance(ctx context.Context, pipelinerunID uint) (*Provenance, error)
 	GetDiff(ctx context.Context, pipelinerunID uint) (*GetDiffResponse, error)
 	Get(ctx context.Context, pipelinerunID uint) (*PipelineBasic, error)
 	List(ctx context.Context, clusterID uint, canRollback bool, query q.Query) (int, []*PipelineBasic, error)
@@ -53,10 +67,12 @@ type controller struct {
 	applicationMgr appmanager.Manager
 	clusterMgr     clustermanager.Manager
 	envMgr         envmanager.Manager
-	tektonFty      factory.Factory
+	ciFty          ci.Factory
 	commitGetter   code.GitGetter
 	clusterGitRepo gitrepo.ClusterGitRepo
 	userManager    usermanager.Manager
+	reportMgr      prreportmanager.Manager
+	provenanceMgr  prprovenancemanager.Manager
 }
 
 var _ Controller = (*controller)(nil)
@@ -66,11 +82,13 @@ func NewController(param *param.Param) Controller {
 		pipelinerunMgr: param.PipelinerunMgr,
 		clusterMgr:     param.ClusterMgr,
 		envMgr:         param.EnvMgr,
-		tektonFty:      param.TektonFty,
+		ciFty:          param.CIFty,
 		commitGetter:   param.GitGetter,
 		applicationMgr: param.ApplicationManager,
 		clusterGitRepo: param.ClusterGitRepo,
 		userManager:    param.UserManager,
+		reportMgr:      param.PipelinerunReportMgr,
+		provenanceMgr:  param.PipelinerunProvenanceMgr,
 	}
 }
 
@@ -121,12 +139,12 @@ func (c *controller) getPipelinerunLog(ctx context.Context, pr *prmodels.Pipelin
 	const op = "pipeline controller: get pipelinerun log"
 	defer wlog.Start(ctx, op).StopPrint()
 
-	tektonCollector, err := c.tektonFty.GetTektonCollector(environment)
+	engine, err := c.ciFty.GetEngine(environment)
 	if err != nil {
-		return nil, perror.WithMessagef(err, "failed to get tekton collector for %s", environment)
+		return nil, perror.WithMessagef(err, "failed to get ci engine for %s", environment)
 	}
 
-	return tektonCollector.GetPipelineRunLog(ctx, pr)
+	return engine.GetPipelineRunLog(ctx, pr)
 }
 
 func (c *controller) GetDiff(ctx context.Context, pipelinerunID uint) (_ *GetDiffResponse, err error) {
@@ -173,6 +191,19 @@ func (c *controller) GetDiff(ctx context.Context, pipelinerunID uint) (_ *GetDif
 		case codemodels.GitRefTypeBranch:
 			codeDiff.Branch = pipelinerun.GitRef
 		}
+		// the diff is still returned without the changed files if the comparison fails
+		if pipelinerun.LastGitCommit != "" {
+			codeDiff.LastCommitID = pipelinerun.LastGitCommit
+			comparison, err := c.commitGetter.Compare(ctx, pipelinerun.GitURL,
+				pipelinerun.LastGitCommit, pipelinerun.GitCommit)
+			if err != nil {
+				log.Warningf(ctx, "failed to compare commit %s with %s: %v",
+					pipelinerun.GitCommit, pipelinerun.LastGitCommit, err)
+			} else {
+				codeDiff.ChangedFiles = comparison.ChangedFiles
+				codeDiff.ChangedFilesComplete = comparison.Complete
+			}
+		}
 	}
 
 	// 4. get config diff
@@ -255,6 +286,7 @@ func (c *controller) ofPipelineBasic(ctx context.Context,
 		Title:            pr.Title,
 		Description:      pr.Description,
 		Action:           pr.Action,
+		Trigger:          pr.Trigger,
 		Status:           pr.Status,
 		GitURL:           pr.GitURL,
 		GitCommit:        pr.GitCommit,
@@ -265,11 +297,25 @@ func (c *controller) ofPipelineBasic(ctx context.Context,
 		UpdatedAt:        pr.UpdatedAt,
 		StartedAt:        pr.StartedAt,
 		FinishedAt:       pr.FinishedAt,
+		PromoteFrom:      pr.PromoteFrom,
+		ReuseFrom:        pr.ReuseFrom,
 		CanRollback:      canRollback,
 		CreatedBy: UserInfo{
 			UserID:   pr.CreatedBy,
 			UserName: user.Name,
 		},
+		ReviewedAt:    pr.ReviewedAt,
+		ReviewComment: pr.ReviewComment,
+	}
+	if pr.ReviewedBy != nil {
+		reviewer, err := c.userManager.GetUserByID(ctx, *pr.ReviewedBy)
+		if err != nil {
+			return nil, err
+		}
+		prBasic.ReviewedBy = &UserInfo{
+			UserID:   reviewer.ID,
+			UserName: reviewer.Name,
+		}
 	}
 	switch pr.GitRefType {
 	case codemodels.GitRefTypeTag:
@@ -301,6 +347,12 @@ func (c *controller) StopPipelinerun(ctx context.Context, pipelinerunID uint) (e
 	if err != nil {
 		return errors.E(op, err)
 	}
+	if pipelinerun.Status == string(prmodels.StatusQueued) {
+		stopped, err := c.cancelQueuedPipelinerun(ctx, pipelinerun)
+		if err != nil || stopped {
+			return err
+		}
+	}
 	if pipelinerun.Status != string(prmodels.StatusCreated) {
 		return errors.E(op, http.StatusBadRequest, errors.ErrorCode("BadRequest"), "pipelinerun is already completed")
 	}
@@ -309,12 +361,28 @@ func (c *controller) StopPipelinerun(ctx context.Context, pipelinerunID uint) (e
 		return errors.E(op, err)
 	}
 
-	tektonClient, err := c.tektonFty.GetTekton(cluster.EnvironmentName)
+	engine, err := c.ciFty.GetEngine(cluster.EnvironmentName)
 	if err != nil {
 		return errors.E(op, err)
 	}
 
-	return tektonClient.StopPipelineRun(ctx, pipelinerun.CIEventID)
+	return engine.StopPipelineRun(ctx, pipelinerun)
+}
+
+// cancelQueuedPipelinerun cancels the pipelinerun waiting in the run queue of its cluster.
+// If the pipelinerun is dequeued meanwhile, it's reloaded and false is returned.
+func (c *controller) cancelQueuedPipelinerun(ctx context.Context, pipelinerun *prmodels.Pipelinerun) (bool, error) {
+	cancelled, err := c.pipelinerunMgr.UpdateStatusByIDAndStatus(ctx, pipelinerun.ID,
+		prmodels.StatusQueued, prmodels.StatusCancelled)
+	if err != nil || cancelled {
+		return cancelled, err
+	}
+	pr, err := c.pipelinerunMgr.GetByID(ctx, pipelinerun.ID)
+	if err != nil {
+		return false, err
+	}
+	*pipelinerun = *pr
+	return false, nil
 }
 
 func (c *controller) StopPipelinerunForCluster(ctx context.Context, clusterID uint) (err error) {
@@ -326,6 +394,18 @@ func (c *controller) StopPipelinerunForCluster(ctx context.Context, clusterID ui
 	}
 	// get cluster latest builddeploy pipelinerun
 	pipelinerun, err := c.pipelinerunMgr.GetLatestByClusterIDAndActions(ctx, clusterID, prmodels.ActionBuildDeploy)
+	if err != nil {
+		return errors.E(op, err)
+	}
+	if pipelinerun == nil {
+		return nil
+	}
+	if pipelinerun.Status == string(prmodels.StatusQueued) {
+		stopped, err := c.cancelQueuedPipelinerun(ctx, pipelinerun)
+		if err != nil || stopped {
+			return err
+		}
+	}
 
 	// if pipelinerun.Status is not created, ignore, and return success
 	if pipelinerun.Status != string(prmodels.StatusCreated) {
@@ -336,10 +416,10 @@ func (c *controller) StopPipelinerunForCluster(ctx context.Context, clusterID ui
 		return errors.E(op, err)
 	}
 
-	tektonClient, err := c.tektonFty.GetTekton(cluster.EnvironmentName)
+	engine, err := c.ciFty.GetEngine(cluster.EnvironmentName)
 	if err != nil {
 		return errors.E(op, err)
 	}
 
-	return tektonClient.StopPipelineRun(ctx, pipelinerun.CIEventID)
+	return engine.StopPipelineRun(ctx, pipelinerun)
 }
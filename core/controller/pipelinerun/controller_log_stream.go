@@ -0,0 +1,109 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pipelinerun
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/horizoncd/horizon/pkg/cluster/tekton/collector"
+	prmodels "github.com/horizoncd/horizon/pkg/pipelinerun/models"
+	"github.com/horizoncd/horizon/pkg/util/errors"
+	"github.com/horizoncd/horizon/pkg/util/log"
+	"github.com/horizoncd/horizon/pkg/util/wlog"
+)
+
+// the live log ends before the pipelinerun is collected,
+// the log object is waited for at most _logCollectTimeout to read the lines missed
+var (
+	_logCollectInterval = 2 * time.Second
+	_logCollectTimeout  = time.Minute
+)
+
+func (c *controller) StreamPipelinerunLog(ctx context.Context, pipelinerunID uint, offset int,
+	fn func(line *collector.LogLine) error) (_ *StreamLogResult, err error) {
+	const op = "pipelinerun controller: stream pipelinerun log"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	pr, err := c.pipelinerunMgr.GetByID(ctx, pipelinerunID)
+	if err != nil {
+		return nil, errors.E(op, err)
+	}
+	if pr.Action != prmodels.ActionBuildDeploy && pr.Action != prmodels.ActionDeploy {
+		return nil, errors.E(op, fmt.Errorf("%v action has no log", pr.Action))
+	}
+	cluster, err := c.clusterMgr.GetByID(ctx, pr.ClusterID)
+	if err != nil {
+		return nil, errors.E(op, err)
+	}
+	engine, err := c.ciFty.GetEngine(cluster.EnvironmentName)
+	if err != nil {
+		return nil, errors.E(op, err)
+	}
+
+	for {
+		l, err := engine.GetPipelineRunLog(ctx, pr)
+		if err != nil {
+			return nil, errors.E(op, err)
+		}
+		live := l.LogChannel != nil || l.ErrChannel != nil
+		offset, err = l.ReadLines(ctx, offset, fn)
+		if err != nil {
+			return nil, err
+		}
+		if !live {
+			break
+		}
+
+		// the live log ends when the pods finish or the pipelineRun is deleted by the collector,
+		// lines written after the log was read are in the log object once the pipelinerun is collected
+		pr, err = c.waitForCollected(ctx, pr.ID)
+		if err != nil {
+			return nil, err
+		}
+		if pr.FinishedAt == nil || pr.PrObject == "" {
+			break
+		}
+	}
+	return &StreamLogResult{
+		Offset: offset,
+		Status: pr.Status,
+	}, nil
+}
+
+// waitForCollected waits until the result of the pipelinerun is saved, or the timeout is reached
+func (c *controller) waitForCollected(ctx context.Context, pipelinerunID uint) (*prmodels.Pipelinerun, error) {
+	timeout := time.After(_logCollectTimeout)
+	ticker := time.NewTicker(_logCollectInterval)
+	defer ticker.Stop()
+	for {
+		pr, err := c.pipelinerunMgr.GetByID(ctx, pipelinerunID)
+		if err != nil {
+			return nil, err
+		}
+		if pr.FinishedAt != nil {
+			return pr, nil
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-timeout:
+			log.Warningf(ctx, "pipelinerun %d is not collected in %v", pipelinerunID, _logCollectTimeout)
+			return pr, nil
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,36 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pipelinerun
+
+import (
+	"context"
+
+	"github.com/horizoncd/horizon/pkg/util/wlog"
+)
+
+func (c *controller) GetProvenance(ctx context.Context, pipelinerunID uint) (_ *Provenance, err error) {
+	const op = "pipelinerun controller: get provenance"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	if _, err := c.pipelinerunMgr.GetByID(ctx, pipelinerunID); err != nil {
+		return nil, err
+	}
+	// not found if the pipelinerun is not signed
+	provenance, err := c.provenanceMgr.GetByPipelinerunID(ctx, pipelinerunID)
+	if err != nil {
+		return nil, err
+	}
+	return ofProvenanceModel(provenance), nil
+}
@@ -0,0 +1,100 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pipelinerun
+
+import (
+	"context"
+
+	herrors "github.com/horizoncd/horizon/core/errors"
+	reportmodels "github.com/horizoncd/horizon/pkg/pipelinerun/report/models"
+	"github.com/horizoncd/horizon/pkg/util/errors"
+	"github.com/horizoncd/horizon/pkg/util/wlog"
+)
+
+func (c *controller) ListReports(ctx context.Context, pipelinerunID uint) (_ []*Report, err error) {
+	const op = "pipelinerun controller: list reports"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	pr, err := c.pipelinerunMgr.GetByID(ctx, pipelinerunID)
+	if err != nil {
+		return nil, errors.E(op, err)
+	}
+
+	reports := make([]*Report, 0)
+	if pr.FinishedAt != nil {
+		collected, err := c.reportMgr.ListByPipelinerunID(ctx, pr.ID)
+		if err != nil {
+			return nil, errors.E(op, err)
+		}
+		for _, r := range collected {
+			reports = append(reports, ofReportModel(r))
+		}
+		return reports, nil
+	}
+
+	cluster, err := c.clusterMgr.GetByID(ctx, pr.ClusterID)
+	if err != nil {
+		return nil, errors.E(op, err)
+	}
+	engine, err := c.ciFty.GetEngine(cluster.EnvironmentName)
+	if err != nil {
+		return nil, errors.E(op, err)
+	}
+	running, err := engine.GetPipelineRunReports(ctx, pr)
+	if err != nil {
+		return nil, errors.E(op, err)
+	}
+	for _, r := range running {
+		reports = append(reports, ofReportModel(&reportmodels.Report{
+			Task:    r.Task,
+			Name:    r.Name,
+			Kind:    r.Kind,
+			Format:  r.Format,
+			Summary: *r.Summary,
+		}))
+	}
+	return reports, nil
+}
+
+func (c *controller) GetReportFile(ctx context.Context, pipelinerunID,
+	reportID uint) (_ *Report, _ []byte, err error) {
+	const op = "pipelinerun controller: get report file"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	r, err := c.reportMgr.GetByID(ctx, reportID)
+	if err != nil {
+		return nil, nil, errors.E(op, err)
+	}
+	if r.PipelinerunID != pipelinerunID {
+		return nil, nil, herrors.NewErrNotFound(herrors.PipelinerunReportInDB, "report not found in the pipelinerun")
+	}
+	if r.Object == "" {
+		return nil, nil, herrors.NewErrNotFound(herrors.PipelinerunReport, "report file is not collected")
+	}
+
+	cluster, err := c.clusterMgr.GetByID(ctx, r.ClusterID)
+	if err != nil {
+		return nil, nil, errors.E(op, err)
+	}
+	engine, err := c.ciFty.GetEngine(cluster.EnvironmentName)
+	if err != nil {
+		return nil, nil, errors.E(op, err)
+	}
+	content, err := engine.GetPipelineRunReport(ctx, r.Object)
+	if err != nil {
+		return nil, nil, errors.E(op, err)
+	}
+	return ofReportModel(r), content, nil
+}
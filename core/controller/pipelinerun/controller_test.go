@@ -20,9 +20,11 @@ import (
 	"os"
 	"strconv"
 	"testing"
+	"time"
 
 	"github.com/golang/mock/gomock"
 	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
 	"github.com/horizoncd/horizon/lib/orm"
 	"github.com/horizoncd/horizon/lib/q"
 	applicationmockmanager "github.com/horizoncd/horizon/mock/pkg/application/manager"
@@ -36,18 +38,23 @@ import (
 	usermock "github.com/horizoncd/horizon/mock/pkg/user/manager"
 	applicationmodel "github.com/horizoncd/horizon/pkg/application/models"
 	userauth "github.com/horizoncd/horizon/pkg/authentication/user"
+	"github.com/horizoncd/horizon/pkg/cluster/ci"
 	codemodels "github.com/horizoncd/horizon/pkg/cluster/code"
 	clustermodel "github.com/horizoncd/horizon/pkg/cluster/models"
 	"github.com/horizoncd/horizon/pkg/cluster/tekton/collector"
 	"github.com/horizoncd/horizon/pkg/cluster/tekton/log"
 	envmodels "github.com/horizoncd/horizon/pkg/environmentregion/models"
+	perror "github.com/horizoncd/horizon/pkg/errors"
 	"github.com/horizoncd/horizon/pkg/git"
 	groupmodels "github.com/horizoncd/horizon/pkg/group/models"
 	membermodels "github.com/horizoncd/horizon/pkg/member/models"
 	"github.com/horizoncd/horizon/pkg/param/managerparam"
 	"github.com/horizoncd/horizon/pkg/pipelinerun/models"
 	prmodels "github.com/horizoncd/horizon/pkg/pipelinerun/models"
+	provenancemodels "github.com/horizoncd/horizon/pkg/pipelinerun/provenance/models"
+	reportmodels "github.com/horizoncd/horizon/pkg/pipelinerun/report/models"
 	"github.com/stretchr/testify/assert"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
 
 	pipelinemodel "github.com/horizoncd/horizon/pkg/pipelinerun/models"
 	usermodel "github.com/horizoncd/horizon/pkg/user/models"
@@ -73,7 +80,7 @@ func TestGetAndListPipelinerun(t *testing.T) {
 		clusterMgr:     mockClusterManager,
 		applicationMgr: mockApplicationMananger,
 		envMgr:         nil,
-		tektonFty:      nil,
+		ciFty:          nil,
 		commitGetter:   mockCommitGetter,
 		clusterGitRepo: mockClusterGitRepo,
 		userManager:    mockUserManager,
@@ -152,7 +159,7 @@ func TestGetDiff(t *testing.T) {
 		clusterMgr:     mockClusterManager,
 		applicationMgr: mockApplicationMananger,
 		envMgr:         nil,
-		tektonFty:      nil,
+		ciFty:          nil,
 		commitGetter:   mockCommitGetter,
 		clusterGitRepo: mockClusterGitRepo,
 	}
@@ -220,6 +227,35 @@ func TestGetDiff(t *testing.T) {
 		},
 	}
 	assert.Equal(t, *expectResp, *resp)
+
+	// files changed since the last build are listed for clusters declaring their build contexts
+	lastGitCommit := "98765"
+	mockPipelineManager.EXPECT().GetByID(ctx, pipelineID).Return(&models.Pipelinerun{
+		ClusterID:     clusterID,
+		GitURL:        gitURL,
+		GitRefType:    codemodels.GitRefTypeBranch,
+		GitRef:        gitBranch,
+		GitCommit:     gitCommit,
+		LastGitCommit: lastGitCommit,
+	}, nil).Times(1)
+	mockClusterManager.EXPECT().GetByID(ctx, clusterID).Return(&clustermodel.Cluster{
+		ApplicationID: applicationID,
+		Name:          clusterName,
+	}, nil).Times(1)
+	mockApplicationMananger.EXPECT().GetByID(ctx, applicationID).Return(&applicationmodel.Application{
+		Name: applicationName,
+	}, nil).Times(1)
+	mockCommitGetter.EXPECT().GetCommit(ctx, gitURL, codemodels.GitRefTypeCommit, gitCommit).
+		Return(&git.Commit{ID: gitCommit, Message: commitMsg}, nil)
+	mockCommitGetter.EXPECT().Compare(ctx, gitURL, lastGitCommit, gitCommit).Return(&git.Comparison{
+		ChangedFiles: []string{"services/api/main.go"},
+		Complete:     true,
+	}, nil)
+	resp, err = ctl.GetDiff(ctx, pipelineID)
+	assert.Nil(t, err)
+	assert.Equal(t, lastGitCommit, resp.CodeInfo.LastCommitID)
+	assert.Equal(t, []string{"services/api/main.go"}, resp.CodeInfo.ChangedFiles)
+	assert.True(t, resp.CodeInfo.ChangedFilesComplete)
 }
 
 // nolint
@@ -228,7 +264,8 @@ func TestMain(m *testing.M) {
 	manager = managerparam.InitManager(db)
 
 	if err := db.AutoMigrate(&clustermodel.Cluster{}, &membermodels.Member{},
-		&envmodels.EnvironmentRegion{}, &prmodels.Pipelinerun{}); err != nil {
+		&envmodels.EnvironmentRegion{}, &prmodels.Pipelinerun{}, &reportmodels.Report{},
+		&provenancemodels.Provenance{}); err != nil {
 		panic(err)
 	}
 	if err := db.AutoMigrate(&groupmodels.Group{}); err != nil {
@@ -250,6 +287,7 @@ func Test(t *testing.T) {
 	tektonCollector := tektoncollectormock.NewMockInterface(mockCtl)
 	tektonFty.EXPECT().GetTekton(gomock.Any()).Return(tekton, nil).AnyTimes()
 	tektonFty.EXPECT().GetTektonCollector(gomock.Any()).Return(tektonCollector, nil).AnyTimes()
+	ciFty, _ := ci.NewFactory(nil, tektonFty)
 
 	envMgr := manager.EnvMgr
 
@@ -281,7 +319,7 @@ func Test(t *testing.T) {
 		pipelinerunMgr: pipelinerunMgr,
 		clusterMgr:     clusterMgr,
 		envMgr:         envMgr,
-		tektonFty:      tektonFty,
+		ciFty:          ciFty,
 	}
 
 	logBytes := []byte("this is a log")
@@ -376,3 +414,220 @@ func Test(t *testing.T) {
 	err = c.StopPipelinerunForCluster(ctx, pipelinerun.ClusterID)
 	assert.Nil(t, err)
 }
+
+func TestStreamPipelinerunLog(t *testing.T) {
+	mockCtl := gomock.NewController(t)
+	tektonFty := tektonftymock.NewMockFactory(mockCtl)
+	tekton := tektonmock.NewMockInterface(mockCtl)
+	tektonCollector := tektoncollectormock.NewMockInterface(mockCtl)
+	tektonFty.EXPECT().GetTekton(gomock.Any()).Return(tekton, nil).AnyTimes()
+	tektonFty.EXPECT().GetTektonCollector(gomock.Any()).Return(tektonCollector, nil).AnyTimes()
+	ciFty, _ := ci.NewFactory(nil, tektonFty)
+
+	_logCollectInterval = 10 * time.Millisecond
+
+	cluster, err := manager.ClusterMgr.Create(ctx, &clustermodel.Cluster{
+		Name:            "cluster-log-stream",
+		EnvironmentName: "test",
+		RegionName:      "hz",
+	}, nil, nil)
+	assert.Nil(t, err)
+	pipelinerun, err := manager.PipelinerunMgr.Create(ctx, &prmodels.Pipelinerun{
+		ClusterID: cluster.ID,
+		Action:    prmodels.ActionBuildDeploy,
+		Status:    string(prmodels.StatusCreated),
+		CreatedBy: 1,
+	})
+	assert.Nil(t, err)
+
+	c := &controller{
+		pipelinerunMgr: manager.PipelinerunMgr,
+		clusterMgr:     manager.ClusterMgr,
+		ciFty:          ciFty,
+	}
+
+	logs := []log.Log{
+		{Task: "build", Step: "git", Log: "clone"},
+		{Task: "build", Step: "compile", Log: "compile"},
+		{Task: "deploy", Step: "deploy", Log: "deploy"},
+	}
+	var logBytes []byte
+	for _, l := range logs {
+		logBytes = append(logBytes, collector.FormatLog(l)...)
+	}
+
+	// the live log ends after two lines, the rest is read from the collected log
+	tektonCollector.EXPECT().GetPipelineRunLog(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, pr *prmodels.Pipelinerun) (*collector.Log, error) {
+			logCh := make(chan log.Log)
+			errCh := make(chan error)
+			go func() {
+				defer close(logCh)
+				defer close(errCh)
+				for _, l := range logs[:2] {
+					logCh <- l
+				}
+				now := time.Now()
+				err := manager.PipelinerunMgr.UpdateResultByID(ctx, pr.ID, &prmodels.Result{
+					S3Bucket:   "bucket",
+					LogObject:  "logObject",
+					PrObject:   "prObject",
+					Result:     string(prmodels.StatusOK),
+					FinishedAt: &now,
+				})
+				assert.Nil(t, err)
+			}()
+			return &collector.Log{LogChannel: logCh, ErrChannel: errCh}, nil
+		}).Times(1)
+	tektonCollector.EXPECT().GetPipelineRunLog(gomock.Any(), gomock.Any()).
+		Return(&collector.Log{LogBytes: logBytes}, nil).Times(1)
+
+	var lines []*collector.LogLine
+	result, err := c.StreamPipelinerunLog(ctx, pipelinerun.ID, 0, func(line *collector.LogLine) error {
+		lines = append(lines, line)
+		return nil
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, &StreamLogResult{Offset: 3, Status: string(prmodels.StatusOK)}, result)
+	assert.Equal(t, 3, len(lines))
+	for i, line := range lines {
+		assert.Equal(t, i, line.Offset)
+		assert.Equal(t, logs[i].Log, line.Log)
+	}
+
+	// resume from an offset
+	tektonCollector.EXPECT().GetPipelineRunLog(gomock.Any(), gomock.Any()).
+		Return(&collector.Log{LogBytes: logBytes}, nil).Times(1)
+	lines = nil
+	result, err = c.StreamPipelinerunLog(ctx, pipelinerun.ID, 2, func(line *collector.LogLine) error {
+		lines = append(lines, line)
+		return nil
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, 3, result.Offset)
+	assert.Equal(t, []*collector.LogLine{{Offset: 2, Task: "deploy", Step: "deploy", Log: "deploy"}}, lines)
+}
+
+func TestReports(t *testing.T) {
+	mockCtl := gomock.NewController(t)
+	tektonFty := tektonftymock.NewMockFactory(mockCtl)
+	tekton := tektonmock.NewMockInterface(mockCtl)
+	tektonCollector := tektoncollectormock.NewMockInterface(mockCtl)
+	tektonFty.EXPECT().GetTekton(gomock.Any()).Return(tekton, nil).AnyTimes()
+	tektonFty.EXPECT().GetTektonCollector(gomock.Any()).Return(tektonCollector, nil).AnyTimes()
+	ciFty, _ := ci.NewFactory(nil, tektonFty)
+
+	cluster, err := manager.ClusterMgr.Create(ctx, &clustermodel.Cluster{
+		Name:            "cluster-reports",
+		EnvironmentName: "test",
+		RegionName:      "hz",
+	}, nil, nil)
+	assert.Nil(t, err)
+
+	c := &controller{
+		pipelinerunMgr: manager.PipelinerunMgr,
+		clusterMgr:     manager.ClusterMgr,
+		ciFty:          ciFty,
+		reportMgr:      manager.PipelinerunReportMgr,
+	}
+
+	// reports of a finished pipelinerun are read from db
+	finishedAt := time.Now()
+	finished, err := manager.PipelinerunMgr.Create(ctx, &prmodels.Pipelinerun{
+		ClusterID:  cluster.ID,
+		Action:     prmodels.ActionBuildDeploy,
+		Status:     string(prmodels.StatusOK),
+		PrObject:   "prObject",
+		FinishedAt: &finishedAt,
+	})
+	assert.Nil(t, err)
+	err = manager.PipelinerunReportMgr.Create(ctx, []*reportmodels.Report{{
+		PipelinerunID: finished.ID,
+		ClusterID:     cluster.ID,
+		Task:          "scan",
+		Name:          "trivy-report",
+		Kind:          reportmodels.KindVulnerability,
+		Format:        reportmodels.FormatTrivy,
+		Object:        "reportObject",
+		Summary:       reportmodels.Summary{Critical: 2},
+	}})
+	assert.Nil(t, err)
+
+	reports, err := c.ListReports(ctx, finished.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(reports))
+	assert.True(t, reports[0].Collected)
+	assert.Equal(t, 2, reports[0].Summary.Critical)
+
+	tektonCollector.EXPECT().GetPipelineRunReport(gomock.Any(), "reportObject").
+		Return([]byte(`{"Results":[]}`), nil)
+	report, content, err := c.GetReportFile(ctx, finished.ID, reports[0].ID)
+	assert.Nil(t, err)
+	assert.Equal(t, reportmodels.FormatTrivy, report.Format)
+	assert.Equal(t, `{"Results":[]}`, string(content))
+
+	_, _, err = c.GetReportFile(ctx, finished.ID+1, reports[0].ID)
+	assert.NotNil(t, err)
+
+	// reports of a running pipelinerun are read from tekton
+	running, err := manager.PipelinerunMgr.Create(ctx, &prmodels.Pipelinerun{
+		ClusterID: cluster.ID,
+		Action:    prmodels.ActionBuildDeploy,
+		Status:    string(prmodels.StatusCreated),
+	})
+	assert.Nil(t, err)
+	tektonPR := &v1beta1.PipelineRun{}
+	tektonPR.Status.TaskRuns = map[string]*v1beta1.PipelineRunTaskRunStatus{
+		"pr-test": {
+			PipelineTaskName: "test",
+			Status: &v1beta1.TaskRunStatus{TaskRunStatusFields: v1beta1.TaskRunStatusFields{
+				TaskRunResults: []v1beta1.TaskRunResult{
+					{Name: "junit-report", Value: `<testsuite tests="4" failures="1"/>`},
+				},
+			}},
+		},
+	}
+	tektonCollector.EXPECT().GetPipelineRun(gomock.Any(), gomock.Any()).Return(tektonPR, nil)
+	reports, err = c.ListReports(ctx, running.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(reports))
+	assert.False(t, reports[0].Collected)
+	assert.Equal(t, 4, reports[0].Summary.Tests)
+}
+
+func TestGetProvenance(t *testing.T) {
+	c := &controller{
+		pipelinerunMgr: manager.PipelinerunMgr,
+		provenanceMgr:  manager.PipelinerunProvenanceMgr,
+	}
+	pr, err := manager.PipelinerunMgr.Create(ctx, &prmodels.Pipelinerun{
+		ClusterID: 1,
+		Action:    prmodels.ActionBuildDeploy,
+		Status:    string(prmodels.StatusOK),
+	})
+	assert.Nil(t, err)
+
+	// not signed
+	_, err = c.GetProvenance(ctx, pr.ID)
+	_, ok := perror.Cause(err).(*herrors.HorizonErrNotFound)
+	assert.True(t, ok)
+
+	p := &provenancemodels.Provenance{
+		PipelinerunID: pr.ID,
+		ClusterID:     1,
+		PredicateType: "https://slsa.dev/provenance/v0.2",
+		BuilderID:     "https://tekton.dev/chains/v2",
+		Statement:     `{"predicateType":"https://slsa.dev/provenance/v0.2"}`,
+		Signature:     "c2ln",
+	}
+	p.SetSubjects([]*provenancemodels.Artifact{{Name: "harbor.com/app/cluster",
+		Digest: map[string]string{"sha256": "abc"}}})
+	assert.Nil(t, manager.PipelinerunProvenanceMgr.Upsert(ctx, p))
+
+	provenance, err := c.GetProvenance(ctx, pr.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, "https://tekton.dev/chains/v2", provenance.BuilderID)
+	assert.Equal(t, 1, len(provenance.Subjects))
+	assert.Equal(t, 0, len(provenance.Materials))
+	assert.JSONEq(t, p.Statement, string(provenance.Statement))
+}
@@ -15,7 +15,11 @@
 package pipelinerun
 
 import (
+	"encoding/json"
 	"time"
+
+	provenancemodels "github.com/horizoncd/horizon/pkg/pipelinerun/provenance/models"
+	reportmodels "github.com/horizoncd/horizon/pkg/pipelinerun/report/models"
 )
 
 type GetDiffResponse struct {
@@ -34,6 +38,12 @@ type CodeInfo struct {
 	CommitMsg string `json:"commitMsg"`
 	// code history link
 	Link string `json:"link"`
+	// the commit of the last build compared with, only set when the cluster declares its build context
+	LastCommitID string `json:"lastCommitID,omitempty"`
+	// files changed since the last build
+	ChangedFiles []string `json:"changedFiles,omitempty"`
+	// whether changedFiles covers all the changes, git servers limit the files compared
+	ChangedFilesComplete bool `json:"changedFilesComplete,omitempty"`
 }
 
 type ConfigDiff struct {
@@ -49,9 +59,11 @@ type PipelineBasic struct {
 	// Description of this pipelinerun
 	Description string `json:"description"`
 
-	// Action type, which can be builddeploy, deploy, restart, rollback
+	// Action type, which can be builddeploy, deploy, restart, rollback, promote
 	Action string `json:"action"`
-	// Status of this pipelinerun, which can be created, ok, failed, cancelled, unknown
+	// Trigger what triggered this pipelinerun, empty when it's created by a user
+	Trigger string `json:"trigger,omitempty"`
+	// Status of this pipelinerun, which can be pending, approved, rejected, created, ok, failed, cancelled, unknown
 	Status string `json:"status"`
 	// Title of this pipelinerun
 
@@ -78,13 +90,88 @@ type PipelineBasic struct {
 	StartedAt *time.Time `json:"startedAt"`
 	// FinishedAt finish time of this pipelinerun
 	FinishedAt *time.Time `json:"finishedAt"`
+	// PromoteFrom the pipelinerun of the source cluster this pipelinerun promoted from
+	PromoteFrom *uint `json:"promoteFrom,omitempty"`
+	// ReuseFrom the pipelinerun whose image this pipelinerun deployed without building
+	ReuseFrom *uint `json:"reuseFrom,omitempty"`
 	// CanRollback can this pipelinerun be rollback, default is false
 	CanRollback bool `json:"canRollback"`
 	// createInfo
 	CreatedBy UserInfo `json:"createdBy"`
+	// ReviewedBy who approved or rejected this pipelinerun, only set in protected environments
+	ReviewedBy *UserInfo `json:"reviewedBy,omitempty"`
+	// ReviewedAt review time of this pipelinerun
+	ReviewedAt *time.Time `json:"reviewedAt,omitempty"`
+	// ReviewComment comment left by the reviewer
+	ReviewComment string `json:"reviewComment,omitempty"`
 }
 
 type UserInfo struct {
 	UserID   uint   `json:"userID"`
 	UserName string `json:"userName"`
 }
+
+// StreamLogResult is the end of a log stream
+type StreamLogResult struct {
+	// Offset is the offset of the next line, from which the stream can be resumed
+	Offset int `json:"offset"`
+	// Status is the status of the pipelinerun when the stream ends
+	Status string `json:"status"`
+}
+
+// Report is a report produced by a task of the pipelinerun
+type Report struct {
+	// ID is empty for the reports of a running pipelinerun, which are not collected yet
+	ID      uint                  `json:"id,omitempty"`
+	Task    string                `json:"task"`
+	Name    string                `json:"name"`
+	Kind    string                `json:"kind"`
+	Format  string                `json:"format"`
+	Summary *reportmodels.Summary `json:"summary"`
+	// Collected tells whether the report file is kept and can be downloaded
+	Collected bool `json:"collected"`
+}
+
+func ofReportModel(r *reportmodels.Report) *Report {
+	summary := r.Summary
+	return &Report{
+		ID:        r.ID,
+		Task:      r.Task,
+		Name:      r.Name,
+		Kind:      r.Kind,
+		Format:    r.Format,
+		Summary:   &summary,
+		Collected: r.Object != "",
+	}
+}
+
+// Provenance is the slsa provenance of the pipelinerun signed by tekton chains
+type Provenance struct {
+	PipelinerunID uint                         `json:"pipelinerunID"`
+	PredicateType string                       `json:"predicateType"`
+	BuilderID     string                       `json:"builderID"`
+	BuildType     string                       `json:"buildType"`
+	Subjects      []*provenancemodels.Artifact `json:"subjects"`
+	Materials     []*provenancemodels.Artifact `json:"materials"`
+	// Statement is the in-toto statement signed, it's verified with the signature
+	Statement json.RawMessage `json:"statement,omitempty"`
+	Signature string          `json:"signature"`
+	CreatedAt time.Time       `json:"createdAt"`
+}
+
+func ofProvenanceModel(p *provenancemodels.Provenance) *Provenance {
+	provenance := &Provenance{
+		PipelinerunID: p.PipelinerunID,
+		PredicateType: p.PredicateType,
+		BuilderID:     p.BuilderID,
+		BuildType:     p.BuildType,
+		Subjects:      p.GetSubjects(),
+		Materials:     p.GetMaterials(),
+		Signature:     p.Signature,
+		CreatedAt:     p.CreatedAt,
+	}
+	if json.Valid([]byte(p.Statement)) {
+		provenance.Statement = json.RawMessage(p.Statement)
+	}
+	return provenance
+}
@@ -0,0 +1,133 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package promotion
+
+import (
+	"context"
+
+	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	applicationmanager "github.com/horizoncd/horizon/pkg/application/manager"
+	envmanager "github.com/horizoncd/horizon/pkg/environment/manager"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/param"
+	promotionmanager "github.com/horizoncd/horizon/pkg/promotion/manager"
+	"github.com/horizoncd/horizon/pkg/promotion/models"
+	"github.com/horizoncd/horizon/pkg/util/wlog"
+)
+
+type Controller interface {
+	// GetPath returns the promotion path of an application,
+	// falls back to the ordered environment list if the application has not configured one
+	GetPath(ctx context.Context, applicationID uint) (*PromotionPath, error)
+	// UpdatePath replaces the promotion path of an application, an empty path resets it to the default one
+	UpdatePath(ctx context.Context, applicationID uint, r *UpdatePromotionPathRequest) error
+}
+
+type controller struct {
+	promotionPathMgr promotionmanager.Manager
+	applicationMgr   applicationmanager.Manager
+	envMgr           envmanager.Manager
+}
+
+var _ Controller = (*controller)(nil)
+
+func NewController(param *param.Param) Controller {
+	return &controller{
+		promotionPathMgr: param.PromotionPathMgr,
+		applicationMgr:   param.ApplicationManager,
+		envMgr:           param.EnvMgr,
+	}
+}
+
+func (c *controller) GetPath(ctx context.Context, applicationID uint) (*PromotionPath, error) {
+	const op = "promotion controller: get path"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	if _, err := c.applicationMgr.GetByID(ctx, applicationID); err != nil {
+		return nil, err
+	}
+
+	path, err := c.promotionPathMgr.GetByApplicationID(ctx, applicationID)
+	if err == nil {
+		return &PromotionPath{
+			ApplicationID: applicationID,
+			Environments:  path.Stages(),
+		}, nil
+	}
+	if _, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); !ok {
+		return nil, err
+	}
+
+	environments, err := c.envMgr.ListAllEnvironment(ctx)
+	if err != nil {
+		return nil, err
+	}
+	envNames := make([]string, 0, len(environments))
+	for _, env := range environments {
+		envNames = append(envNames, env.Name)
+	}
+	return &PromotionPath{
+		ApplicationID: applicationID,
+		Environments:  envNames,
+		IsDefault:     true,
+	}, nil
+}
+
+func (c *controller) UpdatePath(ctx context.Context, applicationID uint, r *UpdatePromotionPathRequest) error {
+	const op = "promotion controller: update path"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	currentUser, err := common.UserFromContext(ctx)
+	if err != nil {
+		return err
+	}
+	if _, err := c.applicationMgr.GetByID(ctx, applicationID); err != nil {
+		return err
+	}
+
+	if len(r.Environments) == 0 {
+		return c.promotionPathMgr.DeleteByApplicationID(ctx, applicationID)
+	}
+	if len(r.Environments) < 2 {
+		return perror.Wrap(herrors.ErrParamInvalid, "promotion path should contain at least two environments")
+	}
+
+	environments, err := c.envMgr.ListAllEnvironment(ctx)
+	if err != nil {
+		return err
+	}
+	existed := make(map[string]bool, len(environments))
+	for _, env := range environments {
+		existed[env.Name] = true
+	}
+	seen := make(map[string]bool, len(r.Environments))
+	for _, env := range r.Environments {
+		if !existed[env] {
+			return perror.Wrapf(herrors.ErrParamInvalid, "environment %s does not exist", env)
+		}
+		if seen[env] {
+			return perror.Wrapf(herrors.ErrParamInvalid, "environment %s appears more than once", env)
+		}
+		seen[env] = true
+	}
+
+	return c.promotionPathMgr.UpsertByApplicationID(ctx, &models.PromotionPath{
+		ApplicationID: applicationID,
+		Environments:  models.JoinEnvironments(r.Environments),
+		CreatedBy:     currentUser.GetID(),
+		UpdatedBy:     currentUser.GetID(),
+	})
+}
@@ -0,0 +1,99 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package promotion
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/lib/orm"
+	appmodels "github.com/horizoncd/horizon/pkg/application/models"
+	userauth "github.com/horizoncd/horizon/pkg/authentication/user"
+	envmodels "github.com/horizoncd/horizon/pkg/environment/models"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	groupmodels "github.com/horizoncd/horizon/pkg/group/models"
+	membermodels "github.com/horizoncd/horizon/pkg/member/models"
+	"github.com/horizoncd/horizon/pkg/param/managerparam"
+	"github.com/horizoncd/horizon/pkg/promotion/models"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	ctx     context.Context
+	manager *managerparam.Manager
+)
+
+// nolint
+func TestMain(m *testing.M) {
+	db, _ := orm.NewSqliteDB("")
+	manager = managerparam.InitManager(db)
+	if err := db.AutoMigrate(&models.PromotionPath{}, &envmodels.Environment{},
+		&groupmodels.Group{}, &appmodels.Application{}, &membermodels.Member{}); err != nil {
+		panic(err)
+	}
+	ctx = common.WithContext(context.TODO(), &userauth.DefaultInfo{
+		Name: "Tony",
+		ID:   1,
+	})
+	os.Exit(m.Run())
+}
+
+func Test(t *testing.T) {
+	for _, env := range []string{"test", "reg", "pre", "online"} {
+		_, err := manager.EnvMgr.CreateEnvironment(ctx, &envmodels.Environment{Name: env})
+		assert.Nil(t, err)
+	}
+	group, err := manager.GroupManager.Create(ctx, &groupmodels.Group{Name: "group", Path: "group"})
+	assert.Nil(t, err)
+	application, err := manager.ApplicationManager.Create(ctx, &appmodels.Application{
+		GroupID: group.ID,
+		Name:    "app",
+	}, map[string]string{})
+	assert.Nil(t, err)
+
+	c := &controller{
+		promotionPathMgr: manager.PromotionPathMgr,
+		applicationMgr:   manager.ApplicationManager,
+		envMgr:           manager.EnvMgr,
+	}
+
+	path, err := c.GetPath(ctx, application.ID)
+	assert.Nil(t, err)
+	assert.True(t, path.IsDefault)
+	assert.Equal(t, []string{"reg", "test", "pre", "online"}, path.Environments)
+
+	err = c.UpdatePath(ctx, application.ID, &UpdatePromotionPathRequest{
+		Environments: []string{"test", "reg", "pre", "online"},
+	})
+	assert.Nil(t, err)
+	path, err = c.GetPath(ctx, application.ID)
+	assert.Nil(t, err)
+	assert.False(t, path.IsDefault)
+	assert.Equal(t, []string{"test", "reg", "pre", "online"}, path.Environments)
+
+	for _, envs := range [][]string{{"test"}, {"test", "perf"}, {"test", "pre", "test"}} {
+		err = c.UpdatePath(ctx, application.ID, &UpdatePromotionPathRequest{Environments: envs})
+		assert.Equal(t, herrors.ErrParamInvalid, perror.Cause(err))
+	}
+
+	err = c.UpdatePath(ctx, application.ID, &UpdatePromotionPathRequest{})
+	assert.Nil(t, err)
+	path, err = c.GetPath(ctx, application.ID)
+	assert.Nil(t, err)
+	assert.True(t, path.IsDefault)
+}
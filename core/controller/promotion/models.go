@@ -0,0 +1,27 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package promotion
+
+type PromotionPath struct {
+	ApplicationID uint     `json:"applicationID"`
+	Environments  []string `json:"environments"`
+	// IsDefault is true when the application has no promotion path of its own,
+	// and the path is derived from the environment list
+	IsDefault bool `json:"isDefault"`
+}
+
+type UpdatePromotionPathRequest struct {
+	Environments []string `json:"environments"`
+}
@@ -17,9 +17,13 @@ package region
 import (
 	"context"
 
+	"github.com/horizoncd/horizon/core/common"
+	eventmanager "github.com/horizoncd/horizon/pkg/event/manager"
+	eventmodels "github.com/horizoncd/horizon/pkg/event/models"
 	"github.com/horizoncd/horizon/pkg/param"
 	regionmanager "github.com/horizoncd/horizon/pkg/region/manager"
 	"github.com/horizoncd/horizon/pkg/region/models"
+	"github.com/horizoncd/horizon/pkg/util/log"
 )
 
 type Controller interface {
@@ -33,11 +37,13 @@ type Controller interface {
 func NewController(param *param.Param) Controller {
 	return &controller{
 		regionMgr: param.RegionMgr,
+		eventMgr:  param.EventManager,
 	}
 }
 
 type controller struct {
 	regionMgr regionmanager.Manager
+	eventMgr  eventmanager.Manager
 }
 
 func (c controller) GetByID(ctx context.Context, id uint) (*Region, error) {
@@ -49,11 +55,26 @@ func (c controller) GetByID(ctx context.Context, id uint) (*Region, error) {
 }
 
 func (c controller) DeleteByID(ctx context.Context, id uint) error {
-	return c.regionMgr.DeleteByID(ctx, id)
+	before, err := c.regionMgr.GetRegionByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if err := c.regionMgr.DeleteByID(ctx, id); err != nil {
+		return err
+	}
+
+	c.recordEvent(ctx, id, eventmodels.RegionDeleted, before, nil)
+	return nil
 }
 
 func (c controller) UpdateByID(ctx context.Context, id uint, request *UpdateRegionRequest) error {
-	err := c.regionMgr.UpdateByID(ctx, id, &models.Region{
+	before, err := c.regionMgr.GetRegionByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	err = c.regionMgr.UpdateByID(ctx, id, &models.Region{
 		DisplayName:   request.DisplayName,
 		Server:        request.Server,
 		Certificate:   request.Certificate,
@@ -66,6 +87,12 @@ func (c controller) UpdateByID(ctx context.Context, id uint, request *UpdateRegi
 		return err
 	}
 
+	after, err := c.regionMgr.GetRegionByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	c.recordEvent(ctx, id, eventmodels.RegionUpdated, before, after)
 	return nil
 }
 
@@ -83,6 +110,8 @@ func (c controller) Create(ctx context.Context, request *CreateRegionRequest) (u
 		return 0, err
 	}
 
+	c.recordEvent(ctx, create.ID, eventmodels.RegionCreated, nil,
+		&models.RegionEntity{Region: create})
 	return create.ID, nil
 }
 
@@ -93,3 +122,21 @@ func (c controller) ListRegions(ctx context.Context) ([]*Region, error) {
 	}
 	return ofRegionEntities(entities), nil
 }
+
+// recordEvent creates an event with snapshots of the region, certificate is left out
+// since it contains the credential of the kubernetes cluster.
+func (c controller) recordEvent(ctx context.Context, id uint, eventType string,
+	before, after *models.RegionEntity) {
+	snapshot := func(entity *models.RegionEntity) *Region {
+		if entity == nil {
+			return nil
+		}
+		region := ofRegionEntity(entity)
+		region.Certificate = ""
+		return region
+	}
+	if _, err := c.eventMgr.CreateMutationEvent(ctx, common.ResourceRegion, id,
+		eventType, snapshot(before), snapshot(after)); err != nil {
+		log.Warningf(ctx, "failed to create event, err: %s", err.Error())
+	}
+}
@@ -18,10 +18,14 @@ import (
 	"context"
 	"sync"
 
+	"github.com/horizoncd/horizon/core/common"
 	"github.com/horizoncd/horizon/pkg/cluster/registry"
+	eventmanager "github.com/horizoncd/horizon/pkg/event/manager"
+	eventmodels "github.com/horizoncd/horizon/pkg/event/models"
 	"github.com/horizoncd/horizon/pkg/param"
 	"github.com/horizoncd/horizon/pkg/registry/manager"
 	"github.com/horizoncd/horizon/pkg/registry/models"
+	"github.com/horizoncd/horizon/pkg/util/log"
 )
 
 var kindCache []string
@@ -40,11 +44,15 @@ type Controller interface {
 }
 
 func NewController(param *param.Param) Controller {
-	return &controller{registryManager: param.RegistryManager}
+	return &controller{
+		registryManager: param.RegistryManager,
+		eventMgr:        param.EventManager,
+	}
 }
 
 type controller struct {
 	registryManager manager.Manager
+	eventMgr        eventmanager.Manager
 }
 
 func (c controller) Create(ctx context.Context, request *CreateRegistryRequest) (uint, error) {
@@ -60,6 +68,9 @@ func (c controller) Create(ctx context.Context, request *CreateRegistryRequest)
 		return 0, err
 	}
 
+	if created, err := c.registryManager.GetByID(ctx, id); err == nil {
+		c.recordEvent(ctx, id, eventmodels.RegistryCreated, nil, created)
+	}
 	return id, nil
 }
 
@@ -73,7 +84,7 @@ func (c controller) ListAll(ctx context.Context) (Registries, error) {
 }
 
 func (c controller) UpdateByID(ctx context.Context, id uint, request *UpdateRegistryRequest) error {
-	_, err := c.registryManager.GetByID(ctx, id)
+	before, err := c.registryManager.GetByID(ctx, id)
 	if err != nil {
 		return err
 	}
@@ -95,15 +106,27 @@ func (c controller) UpdateByID(ctx context.Context, id uint, request *UpdateRegi
 		return err
 	}
 
+	after, err := c.registryManager.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	c.recordEvent(ctx, id, eventmodels.RegistryUpdated, before, after)
 	return nil
 }
 
 func (c controller) DeleteByID(ctx context.Context, id uint) error {
-	err := c.registryManager.DeleteByID(ctx, id)
+	before, err := c.registryManager.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	err = c.registryManager.DeleteByID(ctx, id)
 	if err != nil {
 		return err
 	}
 
+	c.recordEvent(ctx, id, eventmodels.RegistryDeleted, before, nil)
 	return nil
 }
 
@@ -124,3 +147,20 @@ func (c controller) GetKinds(ctx context.Context) []string {
 
 	return kindCache
 }
+
+// recordEvent creates an event with snapshots of the registry without its token
+func (c controller) recordEvent(ctx context.Context, id uint, eventType string,
+	before, after *models.Registry) {
+	snapshot := func(entity *models.Registry) *Registry {
+		if entity == nil {
+			return nil
+		}
+		registry := ofRegistryModel(entity)
+		registry.Token = ""
+		return registry
+	}
+	if _, err := c.eventMgr.CreateMutationEvent(ctx, common.ResourceRegistry, id,
+		eventType, snapshot(before), snapshot(after)); err != nil {
+		log.Warningf(ctx, "failed to create event, err: %s", err.Error())
+	}
+}
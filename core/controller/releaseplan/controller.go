@@ -0,0 +1,254 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package releaseplan
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/lib/q"
+	applicationmanager "github.com/horizoncd/horizon/pkg/application/manager"
+	clustermanager "github.com/horizoncd/horizon/pkg/cluster/manager"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/param"
+	releaseplanmanager "github.com/horizoncd/horizon/pkg/releaseplan/manager"
+	"github.com/horizoncd/horizon/pkg/releaseplan/models"
+	"github.com/horizoncd/horizon/pkg/util/wlog"
+)
+
+type Controller interface {
+	CreateReleasePlan(ctx context.Context, applicationID uint, r *CreateReleasePlanRequest) (*ReleasePlan, error)
+	GetReleasePlan(ctx context.Context, id uint) (*ReleasePlan, error)
+	ListReleasePlans(ctx context.Context, applicationID uint, query *q.Query) ([]*ReleasePlan, int64, error)
+	// StartReleasePlan starts releasing the first wave of a pending plan
+	StartReleasePlan(ctx context.Context, id uint) (*ReleasePlan, error)
+	// PauseReleasePlan stops releasing further clusters of a running plan,
+	// deployments already triggered are not affected
+	PauseReleasePlan(ctx context.Context, id uint) (*ReleasePlan, error)
+	// ResumeReleasePlan continues a paused plan
+	ResumeReleasePlan(ctx context.Context, id uint) (*ReleasePlan, error)
+	// AbortReleasePlan ends an unfinished plan, the released clusters are kept as they are
+	AbortReleasePlan(ctx context.Context, id uint) (*ReleasePlan, error)
+}
+
+type controller struct {
+	releasePlanMgr releaseplanmanager.Manager
+	applicationMgr applicationmanager.Manager
+	clusterMgr     clustermanager.Manager
+}
+
+var _ Controller = (*controller)(nil)
+
+func NewController(param *param.Param) Controller {
+	return &controller{
+		releasePlanMgr: param.ReleasePlanMgr,
+		applicationMgr: param.ApplicationManager,
+		clusterMgr:     param.ClusterMgr,
+	}
+}
+
+func (c *controller) CreateReleasePlan(ctx context.Context, applicationID uint,
+	r *CreateReleasePlanRequest) (*ReleasePlan, error) {
+	const op = "release plan controller: create"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	currentUser, err := common.UserFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := c.applicationMgr.GetByID(ctx, applicationID); err != nil {
+		return nil, err
+	}
+
+	// 1. validate waves
+	clusters, err := c.validateWaves(ctx, applicationID, r)
+	if err != nil {
+		return nil, err
+	}
+
+	// 2. create plan
+	plan := &models.ReleasePlan{
+		ApplicationID: applicationID,
+		Environment:   r.Environment,
+		Title:         r.Title,
+		Description:   r.Description,
+		ImageTag:      r.ImageTag,
+		Status:        models.StatusPending,
+		CreatedBy:     currentUser.GetID(),
+		UpdatedBy:     currentUser.GetID(),
+	}
+	plan.SetWaves(r.Waves)
+	plan, err = c.releasePlanMgr.Create(ctx, plan, clusters)
+	if err != nil {
+		return nil, err
+	}
+	return ofReleasePlanModel(plan, clusters), nil
+}
+
+func (c *controller) GetReleasePlan(ctx context.Context, id uint) (*ReleasePlan, error) {
+	const op = "release plan controller: get"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	plan, err := c.releasePlanMgr.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	clusters, err := c.releasePlanMgr.ListClustersByPlanID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return ofReleasePlanModel(plan, clusters), nil
+}
+
+func (c *controller) ListReleasePlans(ctx context.Context, applicationID uint,
+	query *q.Query) ([]*ReleasePlan, int64, error) {
+	const op = "release plan controller: list"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	plans, total, err := c.releasePlanMgr.ListByApplicationID(ctx, applicationID, query)
+	if err != nil {
+		return nil, 0, err
+	}
+	result := make([]*ReleasePlan, 0, len(plans))
+	for _, plan := range plans {
+		result = append(result, ofReleasePlanModel(plan, nil))
+	}
+	return result, total, nil
+}
+
+func (c *controller) StartReleasePlan(ctx context.Context, id uint) (*ReleasePlan, error) {
+	const op = "release plan controller: start"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	plan, err := c.releasePlanMgr.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	// clusters of an application are released by one plan at a time
+	count, err := c.releasePlanMgr.CountActiveByApplicationID(ctx, plan.ApplicationID)
+	if err != nil {
+		return nil, err
+	}
+	if count > 0 {
+		return nil, perror.Wrapf(herrors.ErrReleasePlanConflict,
+			"application %d has a release plan in progress", plan.ApplicationID)
+	}
+	return c.transit(ctx, id, []string{models.StatusPending}, models.StatusRunning, "")
+}
+
+func (c *controller) PauseReleasePlan(ctx context.Context, id uint) (*ReleasePlan, error) {
+	const op = "release plan controller: pause"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	return c.transit(ctx, id, []string{models.StatusRunning}, models.StatusPaused, "paused manually")
+}
+
+func (c *controller) ResumeReleasePlan(ctx context.Context, id uint) (*ReleasePlan, error) {
+	const op = "release plan controller: resume"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	return c.transit(ctx, id, []string{models.StatusPaused}, models.StatusRunning, "")
+}
+
+func (c *controller) AbortReleasePlan(ctx context.Context, id uint) (*ReleasePlan, error) {
+	const op = "release plan controller: abort"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	return c.transit(ctx, id, []string{models.StatusPending, models.StatusRunning, models.StatusPaused},
+		models.StatusAborted, "aborted manually")
+}
+
+// transit changes the status of the plan if it is in one of the from statuses
+func (c *controller) transit(ctx context.Context, id uint, from []string,
+	to, message string) (*ReleasePlan, error) {
+	currentUser, err := common.UserFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	plan, err := c.releasePlanMgr.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	plan.Status = to
+	plan.Message = message
+	plan.UpdatedBy = currentUser.GetID()
+	updated, err := c.releasePlanMgr.UpdateStatusByID(ctx, id, from, plan)
+	if err != nil {
+		return nil, err
+	}
+	if !updated {
+		return nil, perror.Wrapf(herrors.ErrReleasePlanStatusInvalid,
+			"release plan %d can not be %s", id, to)
+	}
+	return c.GetReleasePlan(ctx, id)
+}
+
+// validateWaves checks that every wave releases clusters of the application in the environment,
+// and each cluster is released only once
+func (c *controller) validateWaves(ctx context.Context, applicationID uint,
+	r *CreateReleasePlanRequest) ([]*models.ReleasePlanCluster, error) {
+	if r.Title == "" {
+		return nil, perror.Wrap(herrors.ErrParamInvalid, "title should not be empty")
+	}
+	if r.Environment == "" {
+		return nil, perror.Wrap(herrors.ErrParamInvalid, "environment should not be empty")
+	}
+	if len(r.Waves) == 0 {
+		return nil, perror.Wrap(herrors.ErrParamInvalid, "waves should not be empty")
+	}
+
+	var clusters []*models.ReleasePlanCluster
+	released := make(map[uint]bool)
+	for i, wave := range r.Waves {
+		if wave.Name == "" {
+			r.Waves[i].Name = fmt.Sprintf("wave-%d", i+1)
+		}
+		if len(wave.ClusterIDs) == 0 {
+			return nil, perror.Wrapf(herrors.ErrParamInvalid, "clusters of wave %d should not be empty", i)
+		}
+		if wave.Pause != "" {
+			pause, err := time.ParseDuration(wave.Pause)
+			if err != nil || pause < 0 {
+				return nil, perror.Wrapf(herrors.ErrParamInvalid, "invalid pause %s of wave %d", wave.Pause, i)
+			}
+		}
+		for _, clusterID := range wave.ClusterIDs {
+			if released[clusterID] {
+				return nil, perror.Wrapf(herrors.ErrParamInvalid,
+					"cluster %d is released more than once", clusterID)
+			}
+			released[clusterID] = true
+
+			cluster, err := c.clusterMgr.GetByID(ctx, clusterID)
+			if err != nil {
+				return nil, err
+			}
+			if cluster.ApplicationID != applicationID || cluster.EnvironmentName != r.Environment {
+				return nil, perror.Wrapf(herrors.ErrParamInvalid,
+					"cluster %s does not belong to the application in environment %s", cluster.Name, r.Environment)
+			}
+			clusters = append(clusters, &models.ReleasePlanCluster{
+				ClusterID: clusterID,
+				Wave:      i,
+				Status:    models.ClusterStatusPending,
+			})
+		}
+	}
+	return clusters, nil
+}
@@ -0,0 +1,133 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package releaseplan
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/lib/orm"
+	"github.com/horizoncd/horizon/lib/q"
+	applicationmodels "github.com/horizoncd/horizon/pkg/application/models"
+	userauth "github.com/horizoncd/horizon/pkg/authentication/user"
+	clustermodels "github.com/horizoncd/horizon/pkg/cluster/models"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/param"
+	"github.com/horizoncd/horizon/pkg/param/managerparam"
+	"github.com/horizoncd/horizon/pkg/releaseplan/models"
+)
+
+func Test(t *testing.T) {
+	db, _ := orm.NewSqliteDB("")
+	if err := db.AutoMigrate(&models.ReleasePlan{}, &models.ReleasePlanCluster{},
+		&applicationmodels.Application{}, &clustermodels.Cluster{}); err != nil {
+		panic(err)
+	}
+	// nolint
+	ctx := common.WithContext(context.Background(), &userauth.DefaultInfo{
+		Name: "Tony",
+		ID:   1,
+	})
+	c := NewController(&param.Param{Manager: managerparam.InitManager(db)})
+
+	app := &applicationmodels.Application{Name: "app"}
+	db.Save(app)
+	other := &applicationmodels.Application{Name: "other"}
+	db.Save(other)
+	var clusterIDs []uint
+	for _, cluster := range []*clustermodels.Cluster{
+		{ApplicationID: app.ID, Name: "app-hz", EnvironmentName: "online", RegionName: "hz"},
+		{ApplicationID: app.ID, Name: "app-sh", EnvironmentName: "online", RegionName: "sh"},
+		{ApplicationID: app.ID, Name: "app-bj", EnvironmentName: "online", RegionName: "bj"},
+		{ApplicationID: app.ID, Name: "app-test", EnvironmentName: "test", RegionName: "hz"},
+		{ApplicationID: other.ID, Name: "other-hz", EnvironmentName: "online", RegionName: "hz"},
+	} {
+		db.Save(cluster)
+		clusterIDs = append(clusterIDs, cluster.ID)
+	}
+
+	// invalid requests
+	for _, waves := range [][]models.Wave{
+		nil,
+		{{ClusterIDs: nil}},
+		{{ClusterIDs: []uint{clusterIDs[0]}}, {ClusterIDs: []uint{clusterIDs[0]}}},
+		{{ClusterIDs: []uint{clusterIDs[3]}}},
+		{{ClusterIDs: []uint{clusterIDs[4]}}},
+		{{ClusterIDs: []uint{clusterIDs[0]}, Pause: "a while"}},
+	} {
+		_, err := c.CreateReleasePlan(ctx, app.ID, &CreateReleasePlanRequest{
+			Title:       "release",
+			Environment: "online",
+			Waves:       waves,
+		})
+		assert.Equal(t, herrors.ErrParamInvalid, perror.Cause(err))
+	}
+
+	request := &CreateReleasePlanRequest{
+		Title:       "release",
+		Environment: "online",
+		ImageTag:    "v2",
+		Waves: []models.Wave{
+			{Name: "canary", ClusterIDs: []uint{clusterIDs[0]}, Pause: "10m"},
+			{ClusterIDs: []uint{clusterIDs[1], clusterIDs[2]}, ManualResume: true},
+		},
+	}
+	plan, err := c.CreateReleasePlan(ctx, app.ID, request)
+	assert.Nil(t, err)
+	assert.Equal(t, models.StatusPending, plan.Status)
+	assert.Equal(t, "wave-2", plan.Waves[1].Name)
+	assert.Equal(t, 3, len(plan.Clusters))
+
+	plan, err = c.GetReleasePlan(ctx, plan.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, plan.Clusters[2].Wave)
+	assert.Equal(t, uint(1), plan.CreatedBy)
+
+	// transitions
+	_, err = c.PauseReleasePlan(ctx, plan.ID)
+	assert.Equal(t, herrors.ErrReleasePlanStatusInvalid, perror.Cause(err))
+	plan, err = c.StartReleasePlan(ctx, plan.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, models.StatusRunning, plan.Status)
+	plan, err = c.PauseReleasePlan(ctx, plan.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, models.StatusPaused, plan.Status)
+	plan, err = c.ResumeReleasePlan(ctx, plan.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, models.StatusRunning, plan.Status)
+
+	// only one plan of an application is in progress at a time
+	another, err := c.CreateReleasePlan(ctx, app.ID, request)
+	assert.Nil(t, err)
+	_, err = c.StartReleasePlan(ctx, another.ID)
+	assert.Equal(t, herrors.ErrReleasePlanConflict, perror.Cause(err))
+
+	plan, err = c.AbortReleasePlan(ctx, plan.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, models.StatusAborted, plan.Status)
+	_, err = c.ResumeReleasePlan(ctx, plan.ID)
+	assert.Equal(t, herrors.ErrReleasePlanStatusInvalid, perror.Cause(err))
+	_, err = c.StartReleasePlan(ctx, another.ID)
+	assert.Nil(t, err)
+
+	plans, total, err := c.ListReleasePlans(ctx, app.ID, &q.Query{PageNumber: 1, PageSize: 10})
+	assert.Nil(t, err)
+	assert.Equal(t, int64(2), total)
+	assert.Equal(t, another.ID, plans[0].ID)
+}
@@ -0,0 +1,85 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package releaseplan
+
+import (
+	"time"
+
+	"github.com/horizoncd/horizon/pkg/releaseplan/models"
+)
+
+type CreateReleasePlanRequest struct {
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	Environment string `json:"environment"`
+	// ImageTag is deployed to the clusters built from images, their current tag is kept if it is empty
+	ImageTag string        `json:"imageTag"`
+	Waves    []models.Wave `json:"waves"`
+}
+
+type ReleasePlan struct {
+	CreateReleasePlanRequest
+	ID            uint       `json:"id"`
+	ApplicationID uint       `json:"applicationID"`
+	CurrentWave   int        `json:"currentWave"`
+	Status        string     `json:"status"`
+	Message       string     `json:"message"`
+	Clusters      []*Cluster `json:"clusters,omitempty"`
+	CreatedAt     time.Time  `json:"createdAt"`
+	UpdatedAt     time.Time  `json:"updatedAt"`
+	CreatedBy     uint       `json:"createdBy"`
+	UpdatedBy     uint       `json:"updatedBy"`
+}
+
+type Cluster struct {
+	ClusterID     uint       `json:"clusterID"`
+	Wave          int        `json:"wave"`
+	PipelinerunID uint       `json:"pipelinerunID"`
+	Status        string     `json:"status"`
+	Message       string     `json:"message"`
+	DeployedAt    *time.Time `json:"deployedAt,omitempty"`
+}
+
+func ofReleasePlanModel(plan *models.ReleasePlan, clusters []*models.ReleasePlanCluster) *ReleasePlan {
+	p := &ReleasePlan{
+		CreateReleasePlanRequest: CreateReleasePlanRequest{
+			Title:       plan.Title,
+			Description: plan.Description,
+			Environment: plan.Environment,
+			ImageTag:    plan.ImageTag,
+			Waves:       plan.GetWaves(),
+		},
+		ID:            plan.ID,
+		ApplicationID: plan.ApplicationID,
+		CurrentWave:   plan.CurrentWave,
+		Status:        plan.Status,
+		Message:       plan.Message,
+		CreatedAt:     plan.CreatedAt,
+		UpdatedAt:     plan.UpdatedAt,
+		CreatedBy:     plan.CreatedBy,
+		UpdatedBy:     plan.UpdatedBy,
+	}
+	for _, cluster := range clusters {
+		p.Clusters = append(p.Clusters, &Cluster{
+			ClusterID:     cluster.ClusterID,
+			Wave:          cluster.Wave,
+			PipelinerunID: cluster.PipelinerunID,
+			Status:        cluster.Status,
+			Message:       cluster.Message,
+			DeployedAt:    cluster.DeployedAt,
+		})
+	}
+	return p
+}
@@ -0,0 +1,124 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package runqueue
+
+import (
+	"context"
+
+	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	clustermanager "github.com/horizoncd/horizon/pkg/cluster/manager"
+	"github.com/horizoncd/horizon/pkg/config/runqueue"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/param"
+	prmanager "github.com/horizoncd/horizon/pkg/pipelinerun/manager"
+	prmodels "github.com/horizoncd/horizon/pkg/pipelinerun/models"
+	runqueuemanager "github.com/horizoncd/horizon/pkg/runqueue/manager"
+	"github.com/horizoncd/horizon/pkg/runqueue/models"
+	"github.com/horizoncd/horizon/pkg/util/wlog"
+)
+
+type Controller interface {
+	// Get returns the policy of the cluster and the pipelineruns in its queue
+	Get(ctx context.Context, clusterID uint) (*RunQueue, error)
+	// Update sets the policy of the cluster, which applies to the pipelineruns created afterwards
+	Update(ctx context.Context, clusterID uint, r *UpdateRunQueueRequest) (*RunQueue, error)
+}
+
+type controller struct {
+	config         *runqueue.Config
+	runQueueMgr    runqueuemanager.Manager
+	clusterMgr     clustermanager.Manager
+	pipelinerunMgr prmanager.Manager
+}
+
+var _ Controller = (*controller)(nil)
+
+func NewController(config *runqueue.Config, param *param.Param) Controller {
+	return &controller{
+		config:         config,
+		runQueueMgr:    param.RunQueueMgr,
+		clusterMgr:     param.ClusterMgr,
+		pipelinerunMgr: param.PipelinerunMgr,
+	}
+}
+
+func (c *controller) Get(ctx context.Context, clusterID uint) (*RunQueue, error) {
+	const op = "run queue controller: get"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	if _, err := c.clusterMgr.GetByID(ctx, clusterID); err != nil {
+		return nil, err
+	}
+	q, err := c.runQueueMgr.GetByClusterID(ctx, clusterID)
+	if err != nil {
+		if _, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); !ok {
+			return nil, err
+		}
+		q = &models.RunQueue{ClusterID: clusterID}
+	}
+	return c.ofRunQueueModel(ctx, q)
+}
+
+func (c *controller) Update(ctx context.Context, clusterID uint, r *UpdateRunQueueRequest) (*RunQueue, error) {
+	const op = "run queue controller: update"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	currentUser, err := common.UserFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if r.Policy != "" && !models.ValidPolicy(r.Policy) {
+		return nil, perror.Wrapf(herrors.ErrParamInvalid, "invalid policy %s", r.Policy)
+	}
+	if _, err := c.clusterMgr.GetByID(ctx, clusterID); err != nil {
+		return nil, err
+	}
+	q, err := c.runQueueMgr.UpsertPolicy(ctx, &models.RunQueue{
+		ClusterID: clusterID,
+		Policy:    r.Policy,
+		CreatedBy: currentUser.GetID(),
+		UpdatedBy: currentUser.GetID(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return c.ofRunQueueModel(ctx, q)
+}
+
+func (c *controller) ofRunQueueModel(ctx context.Context, q *models.RunQueue) (*RunQueue, error) {
+	queued, err := c.pipelinerunMgr.ListByStatus(ctx, prmodels.StatusQueued)
+	if err != nil {
+		return nil, err
+	}
+	queuedIDs := make([]uint, 0)
+	for _, pr := range queued {
+		if pr.ClusterID == q.ClusterID {
+			queuedIDs = append(queuedIDs, pr.ID)
+		}
+	}
+	policy := q.Policy
+	if policy == "" {
+		policy = c.config.DefaultPolicy
+	}
+	return &RunQueue{
+		ClusterID:            q.ClusterID,
+		Policy:               q.Policy,
+		EffectivePolicy:      policy,
+		PipelinerunID:        q.PipelinerunID,
+		LockedAt:             q.LockedAt,
+		QueuedPipelinerunIDs: queuedIDs,
+	}, nil
+}
@@ -0,0 +1,84 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package runqueue
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/lib/orm"
+	userauth "github.com/horizoncd/horizon/pkg/authentication/user"
+	clustermodels "github.com/horizoncd/horizon/pkg/cluster/models"
+	"github.com/horizoncd/horizon/pkg/config/runqueue"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/param"
+	"github.com/horizoncd/horizon/pkg/param/managerparam"
+	prmodels "github.com/horizoncd/horizon/pkg/pipelinerun/models"
+	regionmodels "github.com/horizoncd/horizon/pkg/region/models"
+	"github.com/horizoncd/horizon/pkg/runqueue/models"
+)
+
+func Test(t *testing.T) {
+	db, _ := orm.NewSqliteDB("")
+	if err := db.AutoMigrate(&models.RunQueue{}, &clustermodels.Cluster{}, &regionmodels.Region{},
+		&prmodels.Pipelinerun{}); err != nil {
+		panic(err)
+	}
+	manager := managerparam.InitManager(db)
+	c := NewController(&runqueue.Config{DefaultPolicy: models.PolicyParallel}, &param.Param{Manager: manager})
+
+	// nolint
+	ctx := common.WithContext(context.Background(), &userauth.DefaultInfo{
+		Name: "Tony",
+		ID:   1,
+	})
+	db.Save(&regionmodels.Region{Name: "runqueue-hz"})
+	cluster := &clustermodels.Cluster{ApplicationID: 1, Name: "runqueue-a", RegionName: "runqueue-hz"}
+	db.Save(cluster)
+
+	q, err := c.Get(ctx, cluster.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, "", q.Policy)
+	assert.Equal(t, models.PolicyParallel, q.EffectivePolicy)
+	assert.Equal(t, []uint{}, q.QueuedPipelinerunIDs)
+
+	_, err = c.Update(ctx, cluster.ID, &UpdateRunQueueRequest{Policy: "fifo"})
+	assert.Equal(t, herrors.ErrParamInvalid, perror.Cause(err))
+	_, err = c.Update(ctx, cluster.ID+1, &UpdateRunQueueRequest{Policy: models.PolicySerialize})
+	_, ok := perror.Cause(err).(*herrors.HorizonErrNotFound)
+	assert.True(t, ok)
+
+	pr, err := manager.PipelinerunMgr.Create(ctx, &prmodels.Pipelinerun{
+		ClusterID: cluster.ID,
+		Action:    prmodels.ActionRestart,
+		Status:    string(prmodels.StatusQueued),
+	})
+	assert.Nil(t, err)
+	q, err = c.Update(ctx, cluster.ID, &UpdateRunQueueRequest{Policy: models.PolicySerialize})
+	assert.Nil(t, err)
+	assert.Equal(t, models.PolicySerialize, q.Policy)
+	assert.Equal(t, models.PolicySerialize, q.EffectivePolicy)
+	assert.Equal(t, []uint{pr.ID}, q.QueuedPipelinerunIDs)
+
+	// back to the default policy
+	q, err = c.Update(ctx, cluster.ID, &UpdateRunQueueRequest{})
+	assert.Nil(t, err)
+	assert.Equal(t, "", q.Policy)
+	assert.Equal(t, models.PolicyParallel, q.EffectivePolicy)
+}
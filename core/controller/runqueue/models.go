@@ -0,0 +1,34 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package runqueue
+
+import "time"
+
+type UpdateRunQueueRequest struct {
+	// Policy is one of parallel, serialize, cancel and reject, the default policy is used if it's empty
+	Policy string `json:"policy"`
+}
+
+type RunQueue struct {
+	ClusterID uint   `json:"clusterID"`
+	Policy    string `json:"policy"`
+	// EffectivePolicy is the policy applied to the cluster, that is the default policy if Policy is empty
+	EffectivePolicy string `json:"effectivePolicy"`
+	// PipelinerunID is the pipelinerun which holds the cluster last, it may have finished
+	PipelinerunID uint       `json:"pipelinerunID,omitempty"`
+	LockedAt      *time.Time `json:"lockedAt,omitempty"`
+	// QueuedPipelinerunIDs are the pipelineruns waiting for the cluster, in the order they are executed
+	QueuedPipelinerunIDs []uint `json:"queuedPipelinerunIDs"`
+}
@@ -0,0 +1,262 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schedule
+
+import (
+	"context"
+	"time"
+
+	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	codemodels "github.com/horizoncd/horizon/pkg/cluster/code"
+	clustermanager "github.com/horizoncd/horizon/pkg/cluster/manager"
+	cmodels "github.com/horizoncd/horizon/pkg/cluster/models"
+	"github.com/horizoncd/horizon/pkg/config/schedule"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/param"
+	schedulemanager "github.com/horizoncd/horizon/pkg/schedule/manager"
+	"github.com/horizoncd/horizon/pkg/schedule/models"
+	usermanager "github.com/horizoncd/horizon/pkg/user/manager"
+	"github.com/horizoncd/horizon/pkg/util/wlog"
+)
+
+type Controller interface {
+	List(ctx context.Context, clusterID uint) ([]*Schedule, error)
+	Create(ctx context.Context, clusterID uint, r *CreateScheduleRequest) (*Schedule, error)
+	Get(ctx context.Context, id uint) (*Schedule, error)
+	Update(ctx context.Context, id uint, r *UpdateScheduleRequest) (*Schedule, error)
+	Delete(ctx context.Context, id uint) error
+	// Pause stops the schedule from running until it's resumed
+	Pause(ctx context.Context, id uint) (*Schedule, error)
+	// Resume runs the schedule again from the next time its cron matches, runs missed while paused are skipped
+	Resume(ctx context.Context, id uint) (*Schedule, error)
+}
+
+type controller struct {
+	config      *schedule.Config
+	scheduleMgr schedulemanager.Manager
+	clusterMgr  clustermanager.Manager
+	userMgr     usermanager.Manager
+}
+
+var _ Controller = (*controller)(nil)
+
+func NewController(config *schedule.Config, param *param.Param) Controller {
+	return &controller{
+		config:      config,
+		scheduleMgr: param.ScheduleMgr,
+		clusterMgr:  param.ClusterMgr,
+		userMgr:     param.UserManager,
+	}
+}
+
+func (c *controller) List(ctx context.Context, clusterID uint) ([]*Schedule, error) {
+	const op = "schedule controller: list"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	if _, err := c.clusterMgr.GetByID(ctx, clusterID); err != nil {
+		return nil, err
+	}
+	schedules, err := c.scheduleMgr.ListByClusterID(ctx, clusterID)
+	if err != nil {
+		return nil, err
+	}
+	resp := make([]*Schedule, 0, len(schedules))
+	for _, s := range schedules {
+		resp = append(resp, ofScheduleModel(s))
+	}
+	return resp, nil
+}
+
+func (c *controller) Create(ctx context.Context, clusterID uint, r *CreateScheduleRequest) (*Schedule, error) {
+	const op = "schedule controller: create"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	currentUser, err := common.UserFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	cluster, err := c.clusterMgr.GetByID(ctx, clusterID)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &models.Schedule{
+		ClusterID: clusterID,
+		CreatedBy: currentUser.GetID(),
+	}
+	if err := c.fill(ctx, s, cluster, r, time.Now()); err != nil {
+		return nil, err
+	}
+	s, err = c.scheduleMgr.Create(ctx, s)
+	if err != nil {
+		return nil, err
+	}
+	return ofScheduleModel(s), nil
+}
+
+func (c *controller) Get(ctx context.Context, id uint) (*Schedule, error) {
+	const op = "schedule controller: get"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	s, err := c.scheduleMgr.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return ofScheduleModel(s), nil
+}
+
+func (c *controller) Update(ctx context.Context, id uint, r *UpdateScheduleRequest) (*Schedule, error) {
+	const op = "schedule controller: update"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	s, err := c.scheduleMgr.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	cluster, err := c.clusterMgr.GetByID(ctx, s.ClusterID)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.fill(ctx, s, cluster, (*CreateScheduleRequest)(r), time.Now()); err != nil {
+		return nil, err
+	}
+	if err := c.scheduleMgr.UpdateByID(ctx, id, s); err != nil {
+		return nil, err
+	}
+	return c.Get(ctx, id)
+}
+
+func (c *controller) Delete(ctx context.Context, id uint) error {
+	const op = "schedule controller: delete"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	if _, err := c.scheduleMgr.GetByID(ctx, id); err != nil {
+		return err
+	}
+	return c.scheduleMgr.DeleteByID(ctx, id)
+}
+
+func (c *controller) Pause(ctx context.Context, id uint) (*Schedule, error) {
+	const op = "schedule controller: pause"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	return c.setPaused(ctx, id, true)
+}
+
+func (c *controller) Resume(ctx context.Context, id uint) (*Schedule, error) {
+	const op = "schedule controller: resume"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	return c.setPaused(ctx, id, false)
+}
+
+func (c *controller) setPaused(ctx context.Context, id uint, paused bool) (*Schedule, error) {
+	currentUser, err := common.UserFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	s, err := c.scheduleMgr.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if s.Paused == paused {
+		return ofScheduleModel(s), nil
+	}
+	if !paused {
+		// runs missed while paused are not caught up
+		if err := c.schedule(s, time.Now()); err != nil {
+			return nil, err
+		}
+	}
+	s.Paused = paused
+	s.UpdatedBy = currentUser.GetID()
+	if err := c.scheduleMgr.UpdateByID(ctx, id, s); err != nil {
+		return nil, err
+	}
+	return c.Get(ctx, id)
+}
+
+// fill validates the request and fills it into the schedule
+func (c *controller) fill(ctx context.Context, s *models.Schedule, cluster *cmodels.Cluster,
+	r *CreateScheduleRequest, now time.Time) error {
+	currentUser, err := common.UserFromContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	params := r.Params
+	switch r.Action {
+	case models.ActionBuildDeploy:
+		if cluster.GitURL == "" {
+			return perror.Wrap(herrors.ErrParamInvalid, "builddeploy requires the cluster to have a git repo")
+		}
+		if params.GitBranch == "" {
+			if cluster.GitRefType != codemodels.GitRefTypeBranch {
+				return perror.Wrap(herrors.ErrParamInvalid, "builddeploy requires a git branch")
+			}
+			params.GitBranch = cluster.GitRef
+		}
+		params.ImageTag = ""
+	case models.ActionDeploy:
+		params.GitBranch = ""
+	case models.ActionRestart:
+		params = models.Params{}
+	default:
+		return perror.Wrapf(herrors.ErrParamInvalid,
+			"action should be one of %s, %s and %s", models.ActionBuildDeploy, models.ActionDeploy, models.ActionRestart)
+	}
+
+	// pipelineruns are created as the operator, only admins may make them created as someone else
+	operatorID := r.OperatorID
+	if operatorID == 0 {
+		operatorID = currentUser.GetID()
+	}
+	if operatorID != currentUser.GetID() {
+		if !currentUser.IsAdmin() {
+			return perror.Wrap(herrors.ErrForbidden, "only admins can set the operator to other users")
+		}
+		if _, err := c.userMgr.GetUserByID(ctx, operatorID); err != nil {
+			return err
+		}
+	}
+
+	s.Name = r.Name
+	s.Cron = r.Cron
+	s.Action = r.Action
+	s.SetParams(params)
+	s.OperatorID = operatorID
+	s.Paused = r.Paused
+	s.UpdatedBy = currentUser.GetID()
+	return c.schedule(s, now)
+}
+
+// schedule sets when the schedule is due next after now
+func (c *controller) schedule(s *models.Schedule, now time.Time) error {
+	loc, err := time.LoadLocation(c.config.TimeZone)
+	if err != nil {
+		return err
+	}
+	sched, err := models.ParseCron(s.Cron, loc)
+	if err != nil {
+		return perror.Wrapf(herrors.ErrParamInvalid, "invalid cron %q: %v", s.Cron, err)
+	}
+	next := sched.Next(now)
+	if next.IsZero() {
+		return perror.Wrapf(herrors.ErrParamInvalid, "cron %q never runs", s.Cron)
+	}
+	s.NextRunAt = &next
+	return nil
+}
@@ -0,0 +1,140 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schedule
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/lib/orm"
+	userauth "github.com/horizoncd/horizon/pkg/authentication/user"
+	codemodels "github.com/horizoncd/horizon/pkg/cluster/code"
+	clustermodels "github.com/horizoncd/horizon/pkg/cluster/models"
+	"github.com/horizoncd/horizon/pkg/config/schedule"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/param"
+	"github.com/horizoncd/horizon/pkg/param/managerparam"
+	regionmodels "github.com/horizoncd/horizon/pkg/region/models"
+	"github.com/horizoncd/horizon/pkg/schedule/models"
+	usermodels "github.com/horizoncd/horizon/pkg/user/models"
+)
+
+func Test(t *testing.T) {
+	db, _ := orm.NewSqliteDB("")
+	if err := db.AutoMigrate(&models.Schedule{}, &clustermodels.Cluster{}, &regionmodels.Region{},
+		&usermodels.User{}); err != nil {
+		panic(err)
+	}
+	// nolint
+	ctx := common.WithContext(context.Background(), &userauth.DefaultInfo{
+		Name: "Tony",
+		ID:   1,
+	})
+	// nolint
+	adminCtx := common.WithContext(context.Background(), &userauth.DefaultInfo{
+		Name:  "admin",
+		ID:    2,
+		Admin: true,
+	})
+	c := NewController(&schedule.Config{TimeZone: "UTC"}, &param.Param{Manager: managerparam.InitManager(db)})
+
+	db.Save(&regionmodels.Region{Name: "schedule-hz"})
+	db.Save(&usermodels.User{Name: "Tony"})
+	db.Save(&usermodels.User{Name: "admin", Admin: true})
+	cluster := &clustermodels.Cluster{ApplicationID: 1, Name: "schedule-online", EnvironmentName: "online",
+		RegionName: "schedule-hz", GitURL: "ssh://git@github.com/horizoncd/horizon.git",
+		GitRefType: codemodels.GitRefTypeBranch, GitRef: "master"}
+	db.Save(cluster)
+	image := &clustermodels.Cluster{ApplicationID: 1, Name: "schedule-image", EnvironmentName: "online",
+		RegionName: "schedule-hz"}
+	db.Save(image)
+
+	// invalid requests
+	for _, r := range []*CreateScheduleRequest{
+		{Cron: "0 2 * * *", Action: "rollback"},
+		{Cron: "0 25 * * *", Action: models.ActionRestart},
+		{Cron: "0 0 30 2 *", Action: models.ActionRestart},
+	} {
+		_, err := c.Create(ctx, cluster.ID, r)
+		assert.Equal(t, herrors.ErrParamInvalid, perror.Cause(err))
+	}
+	_, err := c.Create(ctx, image.ID, &CreateScheduleRequest{Cron: "@daily", Action: models.ActionBuildDeploy})
+	assert.Equal(t, herrors.ErrParamInvalid, perror.Cause(err))
+	_, err = c.Create(ctx, cluster.ID, &CreateScheduleRequest{Cron: "@daily", Action: models.ActionRestart,
+		OperatorID: 2})
+	assert.Equal(t, herrors.ErrForbidden, perror.Cause(err))
+
+	// the branch of the cluster is built by default
+	nightly, err := c.Create(ctx, cluster.ID, &CreateScheduleRequest{
+		Name:   "nightly",
+		Cron:   "0 2 * * *",
+		Action: models.ActionBuildDeploy,
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, "master", nightly.Params.GitBranch)
+	assert.Equal(t, uint(1), nightly.OperatorID)
+	assert.Equal(t, 2, nightly.NextRunAt.UTC().Hour())
+	assert.True(t, nightly.NextRunAt.After(time.Now()))
+
+	// admins can make pipelineruns created as others
+	restart, err := c.Create(adminCtx, cluster.ID, &CreateScheduleRequest{
+		Name:       "restart",
+		Cron:       "CRON_TZ=Asia/Shanghai 0 6 * * 1-5",
+		Action:     models.ActionRestart,
+		OperatorID: 1,
+		Params:     models.Params{ImageTag: "ignored"},
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, uint(1), restart.OperatorID)
+	assert.Equal(t, "", restart.Params.ImageTag)
+	assert.Equal(t, 22, restart.NextRunAt.UTC().Hour())
+
+	schedules, err := c.List(ctx, cluster.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(schedules))
+
+	deploy, err := c.Update(ctx, restart.ID, &UpdateScheduleRequest{
+		Name:   "deploy",
+		Cron:   "@hourly",
+		Action: models.ActionDeploy,
+		Params: models.Params{ImageTag: "v1.0.0"},
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, "v1.0.0", deploy.Params.ImageTag)
+	assert.Equal(t, models.ActionDeploy, deploy.Action)
+	assert.Equal(t, uint(1), deploy.UpdatedBy)
+
+	paused, err := c.Pause(ctx, deploy.ID)
+	assert.Nil(t, err)
+	assert.True(t, paused.Paused)
+	due, err := managerparam.InitManager(db).ScheduleMgr.ListDue(ctx, time.Now().Add(2*time.Hour))
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(due))
+
+	resumed, err := c.Resume(ctx, deploy.ID)
+	assert.Nil(t, err)
+	assert.False(t, resumed.Paused)
+	assert.True(t, resumed.NextRunAt.Before(time.Now().Add(time.Hour+time.Second)))
+
+	assert.Nil(t, c.Delete(ctx, nightly.ID))
+	_, err = c.Get(ctx, nightly.ID)
+	_, ok := perror.Cause(err).(*herrors.HorizonErrNotFound)
+	assert.True(t, ok)
+}
@@ -0,0 +1,72 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schedule
+
+import (
+	"time"
+
+	"github.com/horizoncd/horizon/pkg/schedule/models"
+)
+
+type CreateScheduleRequest struct {
+	Name string `json:"name"`
+	// Cron is a standard cron expression with five fields or a descriptor like @daily
+	Cron   string        `json:"cron"`
+	Action string        `json:"action"`
+	Params models.Params `json:"params"`
+	// OperatorID is the user whom the pipelineruns are created as, defaults to the current user,
+	// only admins can set it to other users
+	OperatorID uint `json:"operatorID"`
+	Paused     bool `json:"paused"`
+}
+
+type UpdateScheduleRequest CreateScheduleRequest
+
+type Schedule struct {
+	CreateScheduleRequest
+	ID                uint       `json:"id"`
+	ClusterID         uint       `json:"clusterID"`
+	NextRunAt         *time.Time `json:"nextRunAt,omitempty"`
+	LastRunAt         *time.Time `json:"lastRunAt,omitempty"`
+	LastPipelinerunID uint       `json:"lastPipelinerunID,omitempty"`
+	LastMessage       string     `json:"lastMessage,omitempty"`
+	CreatedAt         time.Time  `json:"createdAt"`
+	UpdatedAt         time.Time  `json:"updatedAt"`
+	CreatedBy         uint       `json:"createdBy"`
+	UpdatedBy         uint       `json:"updatedBy"`
+}
+
+func ofScheduleModel(s *models.Schedule) *Schedule {
+	return &Schedule{
+		CreateScheduleRequest: CreateScheduleRequest{
+			Name:       s.Name,
+			Cron:       s.Cron,
+			Action:     s.Action,
+			Params:     s.GetParams(),
+			OperatorID: s.OperatorID,
+			Paused:     s.Paused,
+		},
+		ID:                s.ID,
+		ClusterID:         s.ClusterID,
+		NextRunAt:         s.NextRunAt,
+		LastRunAt:         s.LastRunAt,
+		LastPipelinerunID: s.LastPipelinerunID,
+		LastMessage:       s.LastMessage,
+		CreatedAt:         s.CreatedAt,
+		UpdatedAt:         s.UpdatedAt,
+		CreatedBy:         s.CreatedBy,
+		UpdatedBy:         s.UpdatedBy,
+	}
+}
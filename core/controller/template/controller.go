@@ -29,6 +29,8 @@ import (
 	"github.com/horizoncd/horizon/lib/q"
 	hctx "github.com/horizoncd/horizon/pkg/context"
 	perror "github.com/horizoncd/horizon/pkg/errors"
+	eventmanager "github.com/horizoncd/horizon/pkg/event/manager"
+	eventmodels "github.com/horizoncd/horizon/pkg/event/models"
 	"github.com/horizoncd/horizon/pkg/git"
 	gmanager "github.com/horizoncd/horizon/pkg/group/manager"
 	groupModels "github.com/horizoncd/horizon/pkg/group/models"
@@ -44,9 +46,9 @@ import (
 	trmodels "github.com/horizoncd/horizon/pkg/templaterelease/models"
 	"github.com/horizoncd/horizon/pkg/templaterelease/schema"
 	"github.com/horizoncd/horizon/pkg/templaterepo"
+	"github.com/horizoncd/horizon/pkg/util/log"
 	"github.com/horizoncd/horizon/pkg/util/permission"
 	"github.com/horizoncd/horizon/pkg/util/wlog"
-	"helm.sh/helm/v3/pkg/chart/loader"
 )
 
 type Controller interface {
@@ -92,6 +94,7 @@ type controller struct {
 	memberMgr            membermanager.Manager
 	memberSvc            memberservice.Service
 	templateSchemaGetter schema.Getter
+	eventMgr             eventmanager.Manager
 }
 
 var _ Controller = (*controller)(nil)
@@ -107,6 +110,7 @@ func NewController(param *param.Param, repo templaterepo.TemplateRepo) Controlle
 		memberMgr:            param.MemberManager,
 		memberSvc:            param.MemberService,
 		groupMgr:             param.GroupManager,
+		eventMgr:             param.EventManager,
 	}
 }
 
@@ -589,6 +593,8 @@ func (c *controller) CreateTemplate(ctx context.Context,
 		return nil, err
 	}
 
+	c.recordEvent(ctx, common.ResourceTemplate, template.ID,
+		eventmodels.TemplateCreated, nil, toTemplate(template))
 	return toTemplate(template), nil
 }
 
@@ -613,14 +619,17 @@ func (c *controller) CreateRelease(ctx context.Context,
 			return nil, err
 		}
 		chartVersion := fmt.Sprintf(common.ChartVersionFormat, release.Name, tag.ShortID)
-		err = c.syncReleaseToRepo(tag.ArchiveData, template.ChartName, chartVersion)
+		kind, digest, err := c.syncReleaseToRepo(tag.ArchiveData, template.ChartName, chartVersion)
 		if err != nil {
 			return nil, err
 		}
+		release.Kind = kind
+		release.ChartDigest = digest
 		release.CommitID = tag.ShortID
 		release.SyncStatus = trmodels.StatusSucceed
 		release.ChartVersion = chartVersion
 	} else {
+		release.Kind = templaterepo.KindHelm
 		release.SyncStatus = trmodels.StatusOutOfSync
 	}
 
@@ -631,6 +640,8 @@ func (c *controller) CreateRelease(ctx context.Context,
 	if newRelease, err = c.templateReleaseMgr.Create(ctx, release); err != nil {
 		return nil, err
 	}
+	c.recordEvent(ctx, common.ResourceTemplateRelease, newRelease.ID,
+		eventmodels.TemplateReleaseCreated, nil, toRelease(newRelease))
 	return toRelease(newRelease), nil
 }
 
@@ -698,6 +709,11 @@ func (c *controller) DeleteTemplate(ctx context.Context, templateID uint) error
 	const op = "template controller: deleteTemplate"
 	defer wlog.Start(ctx, op).StopPrint()
 
+	template, err := c.templateMgr.GetByID(ctx, templateID)
+	if err != nil {
+		return err
+	}
+
 	releases, err := c.templateReleaseMgr.ListByTemplateID(ctx, templateID)
 	if err != nil {
 		return err
@@ -725,13 +741,23 @@ func (c *controller) DeleteTemplate(ctx context.Context, templateID uint) error
 		return perror.Wrap(herrors.ErrSubResourceExist, "this template cannot be deleted because it was used by clusters.")
 	}
 
-	return c.templateMgr.DeleteByID(ctx, templateID)
+	if err := c.templateMgr.DeleteByID(ctx, templateID); err != nil {
+		return err
+	}
+	c.recordEvent(ctx, common.ResourceTemplate, templateID,
+		eventmodels.TemplateDeleted, toTemplate(template), nil)
+	return nil
 }
 
 func (c *controller) DeleteRelease(ctx context.Context, releaseID uint) error {
 	const op = "template controller: deleteRelease"
 	defer wlog.Start(ctx, op).StopPrint()
 
+	release, err := c.templateReleaseMgr.GetByID(ctx, releaseID)
+	if err != nil {
+		return err
+	}
+
 	ctx = context.WithValue(ctx, hctx.TemplateOnlyRefCount, true)
 	_, count, err := c.templateReleaseMgr.GetRefOfApplication(ctx, releaseID)
 	if err != nil {
@@ -749,7 +775,12 @@ func (c *controller) DeleteRelease(ctx context.Context, releaseID uint) error {
 		return perror.Wrap(herrors.ErrSubResourceExist, "this release cannot be deleted because it was used by clusters.")
 	}
 
-	return c.templateReleaseMgr.DeleteByID(ctx, releaseID)
+	if err := c.templateReleaseMgr.DeleteByID(ctx, releaseID); err != nil {
+		return err
+	}
+	c.recordEvent(ctx, common.ResourceTemplateRelease, releaseID,
+		eventmodels.TemplateReleaseDeleted, toRelease(release), nil)
+	return nil
 }
 
 // UpdateTemplate deletes a template by ID
@@ -779,7 +810,17 @@ func (c *controller) UpdateTemplate(ctx context.Context, templateID uint, reques
 		return err
 	}
 
-	return c.templateMgr.UpdateByID(ctx, templateID, tplUpdate)
+	if err := c.templateMgr.UpdateByID(ctx, templateID, tplUpdate); err != nil {
+		return err
+	}
+
+	updated, err := c.templateMgr.GetByID(ctx, templateID)
+	if err != nil {
+		return err
+	}
+	c.recordEvent(ctx, common.ResourceTemplate, templateID,
+		eventmodels.TemplateUpdated, toTemplate(template), toTemplate(updated))
+	return nil
 }
 
 // UpdateRelease deletes a template release by ID
@@ -787,12 +828,27 @@ func (c *controller) UpdateRelease(ctx context.Context, releaseID uint, request
 	const op = "template controller: updateRelease"
 	defer wlog.Start(ctx, op).StopPrint()
 
+	release, err := c.templateReleaseMgr.GetByID(ctx, releaseID)
+	if err != nil {
+		return err
+	}
+
 	trUpdate, err := request.toReleaseModel(ctx)
 	if err != nil {
 		return err
 	}
 
-	return c.templateReleaseMgr.UpdateByID(ctx, releaseID, trUpdate)
+	if err := c.templateReleaseMgr.UpdateByID(ctx, releaseID, trUpdate); err != nil {
+		return err
+	}
+
+	updated, err := c.templateReleaseMgr.GetByID(ctx, releaseID)
+	if err != nil {
+		return err
+	}
+	c.recordEvent(ctx, common.ResourceTemplateRelease, releaseID,
+		eventmodels.TemplateReleaseUpdated, toRelease(release), toRelease(updated))
+	return nil
 }
 
 func (c *controller) SyncReleaseToRepo(ctx context.Context, releaseID uint) error {
@@ -814,10 +870,12 @@ func (c *controller) SyncReleaseToRepo(ctx context.Context, releaseID uint) erro
 		return err
 	}
 	chartVersion := fmt.Sprintf(common.ChartVersionFormat, release.Name, tag.ShortID)
-	err = c.syncReleaseToRepo(tag.ArchiveData, template.ChartName, chartVersion)
+	kind, digest, err := c.syncReleaseToRepo(tag.ArchiveData, template.ChartName, chartVersion)
 	if err != nil {
 		_ = c.handleReleaseSyncStatus(ctx, release, tag.ShortID, err.Error())
 	} else {
+		release.Kind = kind
+		release.ChartDigest = digest
 		_ = c.handleReleaseSyncStatus(ctx, release, tag.ShortID, "")
 	}
 	return err
@@ -882,15 +940,20 @@ func (c *controller) checkStatusForRelease(ctx context.Context,
 	return release, nil
 }
 
-func (c *controller) syncReleaseToRepo(chartBytes []byte, name, tag string) error {
-	chart, err := loader.LoadArchive(bytes.NewReader(chartBytes))
+// syncReleaseToRepo uploads the template to repo, and returns the kind of it and the digest it's pinned to
+func (c *controller) syncReleaseToRepo(chartBytes []byte, name, tag string) (string, string, error) {
+	chart, err := templaterepo.LoadArchive(bytes.NewReader(chartBytes))
 	if err != nil {
-		return perror.Wrap(herrors.ErrLoadChartArchive, fmt.Sprintf("failed to load archive: %v", err))
+		return "", "", err
 	}
 	chart.Metadata.Version = tag
 	chart.Metadata.Name = name
 
-	return c.templateRepo.UploadChart(chart)
+	digest, err := c.templateRepo.UploadChart(chart)
+	if err != nil {
+		return "", "", err
+	}
+	return templaterepo.KindOf(chart), digest, nil
 }
 
 func (c *controller) checkHasOnlyOwnerPermissionForTemplate(ctx context.Context,
@@ -948,3 +1011,11 @@ func (c *controller) checkHasOnlyOwnerPermissionForRelease(ctx context.Context,
 	}
 	return false
 }
+
+func (c *controller) recordEvent(ctx context.Context, resourceType string, id uint,
+	eventType string, before, after interface{}) {
+	if _, err := c.eventMgr.CreateMutationEvent(ctx, resourceType, id,
+		eventType, before, after); err != nil {
+		log.Warningf(ctx, "failed to create event, err: %s", err.Error())
+	}
+}
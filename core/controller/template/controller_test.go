@@ -42,6 +42,7 @@ import (
 	gitconfig "github.com/horizoncd/horizon/pkg/config/git"
 	hctx "github.com/horizoncd/horizon/pkg/context"
 	perror "github.com/horizoncd/horizon/pkg/errors"
+	eventmodels "github.com/horizoncd/horizon/pkg/event/models"
 	"github.com/horizoncd/horizon/pkg/git"
 	"github.com/horizoncd/horizon/pkg/git/github"
 	groupmodels "github.com/horizoncd/horizon/pkg/group/models"
@@ -378,7 +379,7 @@ func TestCreateTemplate(t *testing.T) {
 	createContext()
 	ctl, repo := createController(t)
 
-	repo.EXPECT().UploadChart(gomock.Any()).Return(nil).Times(2)
+	repo.EXPECT().UploadChart(gomock.Any()).Return("sha256:1234", nil).Times(2)
 
 	var err error
 
@@ -403,6 +404,12 @@ func TestCreateTemplate(t *testing.T) {
 	versionPattern := regexp.MustCompile(`^v(\d\.){2}\d-(.+)$`)
 	assert.True(t, versionPattern.MatchString(releases[0].ChartVersion))
 
+	// the release is pinned to the digest of the uploaded chart
+	release, err := mgr.TemplateReleaseManager.GetByID(ctx, 1)
+	assert.Nil(t, err)
+	assert.Equal(t, "sha256:1234", release.ChartDigest)
+	assert.Equal(t, release.ChartVersion+"@sha256:1234", release.ChartReference())
+
 	err = ctl.SyncReleaseToRepo(ctx, 1)
 	assert.Nil(t, err)
 }
@@ -438,7 +445,7 @@ func TestDeleteTemplate(t *testing.T) {
 	createContext()
 	ctl, repo := createController(t)
 
-	repo.EXPECT().UploadChart(gomock.Any()).Return(nil).Times(1)
+	repo.EXPECT().UploadChart(gomock.Any()).Return("", nil).Times(1)
 
 	createChart(t, ctl, 0)
 
@@ -468,7 +475,7 @@ func TestGetTemplate(t *testing.T) {
 	createContext()
 	ctl, repo := createController(t)
 
-	repo.EXPECT().UploadChart(gomock.Any()).Return(nil).Times(1)
+	repo.EXPECT().UploadChart(gomock.Any()).Return("", nil).Times(1)
 	repo.EXPECT().GetChart(templateName, templateTag+"-5e5193b355961b983cab05a83fa22934001ddf4d", gomock.Any()).
 		Return(&chart.Chart{}, nil).Times(1)
 
@@ -591,7 +598,8 @@ func createContext() {
 	db, _ = orm.NewSqliteDB("")
 	if err := db.AutoMigrate(&trmodels.TemplateRelease{},
 		&amodels.Application{}, &cmodels.Cluster{}, &membermodels.Member{},
-		&tmodels.Template{}, &membermodels.Member{}, &groupmodels.Group{}, &usermodels.User{}); err != nil {
+		&tmodels.Template{}, &membermodels.Member{}, &groupmodels.Group{}, &usermodels.User{},
+		&eventmodels.Event{}); err != nil {
 		panic(err)
 	}
 	mgr = managerparam.InitManager(db)
@@ -633,6 +641,7 @@ func createController(t *testing.T) (Controller, *mock_repo.MockTemplateRepo) {
 		templateReleaseMgr:   mgr.TemplateReleaseManager,
 		memberMgr:            mgr.MemberManager,
 		templateSchemaGetter: getter,
+		eventMgr:             mgr.EventManager,
 	}
 	return ctl, repo
 }
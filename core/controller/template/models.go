@@ -184,6 +184,7 @@ type Release struct {
 	TemplateID     uint      `json:"templateID"`
 	TemplateName   string    `json:"templateName"`
 	ChartVersion   string    `json:"chartVersion"`
+	Kind           string    `json:"kind"`
 	Description    string    `json:"description"`
 	Recommended    bool      `json:"recommended"`
 	OnlyOwner      bool      `json:"onlyOwner"`
@@ -227,6 +228,7 @@ func toRelease(m *trmodels.TemplateRelease) *Release {
 		ID:             m.ID,
 		Name:           m.Name,
 		ChartVersion:   m.ChartVersion,
+		Kind:           m.Kind,
 		Description:    m.Description,
 		TemplateID:     m.Template,
 		TemplateName:   m.TemplateName,
@@ -16,11 +16,15 @@ package webhook
 
 import (
 	"context"
+	"time"
 
 	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
 	"github.com/horizoncd/horizon/lib/q"
 	applicationmanager "github.com/horizoncd/horizon/pkg/application/manager"
 	clustermanager "github.com/horizoncd/horizon/pkg/cluster/manager"
+	webhookconfig "github.com/horizoncd/horizon/pkg/config/webhook"
+	perror "github.com/horizoncd/horizon/pkg/errors"
 	eventmanager "github.com/horizoncd/horizon/pkg/event/manager"
 	groupmanager "github.com/horizoncd/horizon/pkg/group/manager"
 	"github.com/horizoncd/horizon/pkg/param"
@@ -43,9 +47,29 @@ type Controller interface {
 	ListWebhookLogs(ctx context.Context, wID uint, query *q.Query) ([]*LogSummary, int64, error)
 	GetWebhookLog(ctx context.Context, id uint) (*Log, error)
 	ResendWebhook(ctx context.Context, id uint) (*models.WebhookLog, error)
+	// RedeliverWebhookLog sends the webhook log again, its attempts are counted from zero
+	RedeliverWebhookLog(ctx context.Context, id uint) (*Log, error)
+	// RedeliverWebhookLogs sends the failed and dead letter logs of the webhook created in [start, end) again,
+	// the last day is redelivered if the range is not given
+	RedeliverWebhookLogs(ctx context.Context, wID uint, start, end time.Time) (*RedeliverResponse, error)
+	// RotateWebhookSecret replaces the secret of the webhook,
+	// requests are signed by both the new and the old secret until the overlap ends
+	RotateWebhookSecret(ctx context.Context, id uint, r *RotateSecretRequest) (*Webhook, error)
+
+	// notification channels are webhooks rendering messages for chat-ops,
+	// their logs are listed and redelivered as the logs of webhooks
+	CreateNotificationChannel(ctx context.Context, resourceType string,
+		resourceID uint, r *CreateNotificationChannelRequest) (*NotificationChannel, error)
+	GetNotificationChannel(ctx context.Context, id uint) (*NotificationChannel, error)
+	ListNotificationChannels(ctx context.Context, resourceType string,
+		resourceID uint, query *q.Query) ([]*NotificationChannel, int64, error)
+	UpdateNotificationChannel(ctx context.Context, id uint,
+		r *UpdateNotificationChannelRequest) (*NotificationChannel, error)
+	DeleteNotificationChannel(ctx context.Context, id uint) error
 }
 
 type controller struct {
+	config         *webhookconfig.Config
 	webhookMgr     wmanager.Manager
 	userMgr        usermanager.Manager
 	eventMgr       eventmanager.Manager
@@ -54,8 +78,9 @@ type controller struct {
 	clusterMgr     clustermanager.Manager
 }
 
-func NewController(param *param.Param) Controller {
+func NewController(config *webhookconfig.Config, param *param.Param) Controller {
 	return &controller{
+		config:         config,
 		webhookMgr:     param.WebhookManager,
 		userMgr:        param.UserManager,
 		eventMgr:       param.EventManager,
@@ -142,6 +167,8 @@ func (c *controller) ListWebhooks(ctx context.Context, resourceType string,
 	resource := map[string][]uint{
 		resourceType: {resourceID},
 	}
+	query = q.MustClone(query)
+	query.Keywords[common.Channel] = []string{models.ChannelWebhook}
 	webhooks, total, err := c.webhookMgr.ListWebhookOfResources(ctx, resource, query)
 	if err != nil {
 		return nil, total, err
@@ -242,8 +269,14 @@ func (c *controller) GetWebhookLog(ctx context.Context, id uint) (*Log, error) {
 		return nil, err
 	}
 
+	attempts, err := c.webhookMgr.ListWebhookLogAttempts(ctx, wl.ID)
+	if err != nil {
+		return nil, err
+	}
+
 	webhookLog := ofWebhookLogModel(wl)
 	webhookLog.CreatedBy = usermodels.ToUser(userMap[wl.CreatedBy])
+	webhookLog.History = ofWebhookLogAttemptModels(attempts)
 	return webhookLog, nil
 }
 
@@ -253,3 +286,75 @@ func (c *controller) ResendWebhook(ctx context.Context, id uint) (*models.Webhoo
 
 	return c.webhookMgr.ResendWebhook(ctx, id)
 }
+
+func (c *controller) RedeliverWebhookLog(ctx context.Context, id uint) (*Log, error) {
+	const op = "wehook controller: redeliver"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	wl, err := c.webhookMgr.GetWebhookLog(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if wl.Status == models.StatusWaiting {
+		return nil, perror.Wrapf(herrors.ErrParamInvalid, "webhook log %d is already waiting to be sent", id)
+	}
+	if _, err := c.webhookMgr.RedeliverWebhookLog(ctx, id); err != nil {
+		return nil, err
+	}
+	return c.GetWebhookLog(ctx, id)
+}
+
+func (c *controller) RedeliverWebhookLogs(ctx context.Context, wID uint,
+	start, end time.Time) (*RedeliverResponse, error) {
+	const op = "wehook controller: redeliver logs"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	if end.IsZero() {
+		end = time.Now()
+	}
+	if start.IsZero() {
+		start = end.Add(-24 * time.Hour)
+	}
+	if !start.Before(end) {
+		return nil, perror.Wrap(herrors.ErrParamInvalid, "start should be before end")
+	}
+	if _, err := c.webhookMgr.GetWebhook(ctx, wID); err != nil {
+		return nil, err
+	}
+	count, err := c.webhookMgr.RedeliverWebhookLogs(ctx, wID, start, end)
+	if err != nil {
+		return nil, err
+	}
+	return &RedeliverResponse{Count: count}, nil
+}
+
+func (c *controller) RotateWebhookSecret(ctx context.Context, id uint,
+	r *RotateSecretRequest) (*Webhook, error) {
+	const op = "wehook controller: rotate secret"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	if r.Secret == "" {
+		return nil, perror.Wrap(herrors.ErrParamInvalid, "secret should not be empty")
+	}
+	wm, err := c.webhookMgr.GetWebhook(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if r.Secret == wm.Secret {
+		return nil, perror.Wrap(herrors.ErrParamInvalid, "secret should be different from the current one")
+	}
+
+	overlap := r.Overlap
+	if overlap == 0 {
+		overlap = c.config.SecretRotationOverlap
+	}
+	expiredAt := time.Now().Add(time.Duration(overlap) * time.Second)
+	wm.PreviousSecret, wm.PreviousSecretExpiredAt = wm.Secret, &expiredAt
+	wm.Secret = r.Secret
+
+	wm, err = c.webhookMgr.UpdateWebhook(ctx, id, wm)
+	if err != nil {
+		return nil, err
+	}
+	return ofWebhookModel(wm), nil
+}
@@ -0,0 +1,122 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webhook
+
+import (
+	"context"
+
+	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/lib/q"
+	"github.com/horizoncd/horizon/pkg/util/wlog"
+	wmodels "github.com/horizoncd/horizon/pkg/webhook/models"
+)
+
+func (c *controller) CreateNotificationChannel(ctx context.Context, resourceType string,
+	resourceID uint, r *CreateNotificationChannelRequest) (*NotificationChannel, error) {
+	const op = "webhook controller: create notification channel"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	if err := c.validateResourceType(resourceType); err != nil {
+		return nil, err
+	}
+	if err := c.validateNotificationChannel(r.Type, r.URL, r.Recipients, r.Templates, r.Triggers); err != nil {
+		return nil, err
+	}
+
+	wm, err := c.webhookMgr.CreateWebhook(ctx, r.toModel(resourceType, resourceID))
+	if err != nil {
+		return nil, err
+	}
+	return ofNotificationChannelModel(wm), nil
+}
+
+func (c *controller) GetNotificationChannel(ctx context.Context, id uint) (*NotificationChannel, error) {
+	const op = "webhook controller: get notification channel"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	wm, err := c.getNotificationChannel(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return ofNotificationChannelModel(wm), nil
+}
+
+func (c *controller) ListNotificationChannels(ctx context.Context, resourceType string,
+	resourceID uint, query *q.Query) ([]*NotificationChannel, int64, error) {
+	const op = "webhook controller: list notification channels"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	resource := map[string][]uint{
+		resourceType: {resourceID},
+	}
+	query = q.MustClone(query)
+	query.Keywords[common.Channel] = wmodels.NotificationChannels
+	webhooks, total, err := c.webhookMgr.ListWebhookOfResources(ctx, resource, query)
+	if err != nil {
+		return nil, total, err
+	}
+
+	channels := make([]*NotificationChannel, 0, len(webhooks))
+	for _, wm := range webhooks {
+		channels = append(channels, ofNotificationChannelModel(wm))
+	}
+	return channels, total, nil
+}
+
+func (c *controller) UpdateNotificationChannel(ctx context.Context, id uint,
+	r *UpdateNotificationChannelRequest) (*NotificationChannel, error) {
+	const op = "webhook controller: update notification channel"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	wm, err := c.getNotificationChannel(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	wm = r.toModel(wm)
+	config := wm.GetChannelConfig()
+	if err := c.validateNotificationChannel(wm.Channel, wm.URL, config.Recipients,
+		config.Templates, ParseTriggerStr(wm.Triggers)); err != nil {
+		return nil, err
+	}
+
+	wm, err = c.webhookMgr.UpdateWebhook(ctx, id, wm)
+	if err != nil {
+		return nil, err
+	}
+	return ofNotificationChannelModel(wm), nil
+}
+
+func (c *controller) DeleteNotificationChannel(ctx context.Context, id uint) error {
+	const op = "webhook controller: delete notification channel"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	if _, err := c.getNotificationChannel(ctx, id); err != nil {
+		return err
+	}
+	return c.webhookMgr.DeleteWebhook(ctx, id)
+}
+
+// getNotificationChannel gets the webhook of the id, it's not found if it's a plain webhook
+func (c *controller) getNotificationChannel(ctx context.Context, id uint) (*wmodels.Webhook, error) {
+	wm, err := c.webhookMgr.GetWebhook(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if wm.Channel == wmodels.ChannelWebhook {
+		return nil, herrors.NewErrNotFound(herrors.WebhookInDB, "notification channel not found")
+	}
+	return wm, nil
+}
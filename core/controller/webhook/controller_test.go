@@ -17,16 +17,20 @@ package webhook
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"gorm.io/gorm"
 
 	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
 	"github.com/horizoncd/horizon/lib/orm"
 	"github.com/horizoncd/horizon/lib/q"
 	applicationmodels "github.com/horizoncd/horizon/pkg/application/models"
 	userauth "github.com/horizoncd/horizon/pkg/authentication/user"
 	clustermodels "github.com/horizoncd/horizon/pkg/cluster/models"
+	webhookconfig "github.com/horizoncd/horizon/pkg/config/webhook"
+	perror "github.com/horizoncd/horizon/pkg/errors"
 	eventmodels "github.com/horizoncd/horizon/pkg/event/models"
 	groupmodels "github.com/horizoncd/horizon/pkg/group/models"
 	"github.com/horizoncd/horizon/pkg/param"
@@ -66,6 +70,7 @@ func createContext() {
 	if err := db.AutoMigrate(
 		&webhookmodels.Webhook{},
 		&webhookmodels.WebhookLog{},
+		&webhookmodels.WebhookLogAttempt{},
 		&usermodels.User{},
 		&eventmodels.Event{},
 		&groupmodels.Group{},
@@ -88,7 +93,7 @@ func createContext() {
 	controllerParam := param.Param{
 		Manager: mgrParam,
 	}
-	c = NewController(&controllerParam).(*controller)
+	c = NewController(&webhookconfig.Config{SecretRotationOverlap: 3600}, &controllerParam).(*controller)
 }
 
 func Test(t *testing.T) {
@@ -166,3 +171,261 @@ func Test(t *testing.T) {
 	assert.Nil(t, err)
 	assert.Equal(t, false, ok)
 }
+
+func TestRetry(t *testing.T) {
+	createContext()
+
+	// invalid retry policy
+	req := createWebhookReq
+	req.RetryPolicy = &webhookmodels.RetryPolicy{MaxAttempts: 100}
+	_, err := c.CreateWebhook(ctx, resourceType, resourceID, &req)
+	assert.NotNil(t, err)
+	req.RetryPolicy = &webhookmodels.RetryPolicy{MaxAttempts: 3, InitialInterval: 10, MaxInterval: 5}
+	_, err = c.CreateWebhook(ctx, resourceType, resourceID, &req)
+	assert.NotNil(t, err)
+	req.RetryPolicy = &webhookmodels.RetryPolicy{MaxAttempts: 3, RetryOnStatusCodes: []int{1000}}
+	_, err = c.CreateWebhook(ctx, resourceType, resourceID, &req)
+	assert.NotNil(t, err)
+
+	policy := &webhookmodels.RetryPolicy{
+		MaxAttempts:        3,
+		InitialInterval:    10,
+		MaxInterval:        60,
+		Multiplier:         2,
+		RetryOnStatusCodes: []int{503},
+	}
+	req.RetryPolicy = policy
+	w, err := c.CreateWebhook(ctx, resourceType, resourceID, &req)
+	assert.Nil(t, err)
+	assert.Equal(t, policy, w.RetryPolicy)
+
+	// the default policy is used again
+	w, err = c.UpdateWebhook(ctx, w.ID, &UpdateWebhookRequest{
+		RetryPolicy: &webhookmodels.RetryPolicy{},
+	})
+	assert.Nil(t, err)
+	assert.Nil(t, w.RetryPolicy)
+
+	// the log failed after all attempts
+	wl, err := c.webhookMgr.CreateWebhookLog(ctx, &webhookmodels.WebhookLog{
+		WebhookID: w.ID,
+		URL:       w.URL,
+		Status:    webhookmodels.StatusWaiting,
+	})
+	assert.Nil(t, err)
+	_, err = c.RedeliverWebhookLog(ctx, wl.ID)
+	assert.NotNil(t, err)
+
+	for i := uint(1); i <= 3; i++ {
+		assert.Nil(t, c.webhookMgr.CreateWebhookLogAttempt(ctx, &webhookmodels.WebhookLogAttempt{
+			WebhookLogID: wl.ID,
+			Attempt:      i,
+			StatusCode:   503,
+			ErrorMessage: "unexpected response code: 503",
+		}))
+	}
+	wl.Status = webhookmodels.StatusDeadLetter
+	wl.Attempts = 3
+	_, err = c.webhookMgr.UpdateWebhookLog(ctx, wl)
+	assert.Nil(t, err)
+
+	log, err := c.GetWebhookLog(ctx, wl.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, uint(3), log.Attempts)
+	assert.Equal(t, 3, len(log.History))
+	assert.Equal(t, 503, log.History[2].StatusCode)
+
+	logs, err := c.webhookMgr.ListWebhookLogsToSend(ctx, w.ID, time.Now())
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(logs))
+
+	// redeliver the log
+	log, err = c.RedeliverWebhookLog(ctx, wl.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, webhookmodels.StatusWaiting, log.Status)
+	assert.Equal(t, uint(0), log.Attempts)
+	assert.Equal(t, 3, len(log.History))
+
+	logs, err = c.webhookMgr.ListWebhookLogsToSend(ctx, w.ID, time.Now())
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(logs))
+
+	// the retrying log is sent after its backoff
+	nextAttemptAt := time.Now().Add(time.Minute)
+	wl.Status = webhookmodels.StatusRetrying
+	wl.Attempts = 1
+	wl.NextAttemptAt = &nextAttemptAt
+	_, err = c.webhookMgr.UpdateWebhookLog(ctx, wl)
+	assert.Nil(t, err)
+	logs, err = c.webhookMgr.ListWebhookLogsToSend(ctx, w.ID, time.Now())
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(logs))
+	logs, err = c.webhookMgr.ListWebhookLogsToSend(ctx, w.ID, nextAttemptAt.Add(time.Second))
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(logs))
+
+	// redeliver the failed logs in the range
+	wl.Status = webhookmodels.StatusFailed
+	wl.NextAttemptAt = nil
+	_, err = c.webhookMgr.UpdateWebhookLog(ctx, wl)
+	assert.Nil(t, err)
+	resp, err := c.RedeliverWebhookLogs(ctx, w.ID, time.Now().Add(time.Hour), time.Now().Add(2*time.Hour))
+	assert.Nil(t, err)
+	assert.Equal(t, int64(0), resp.Count)
+	resp, err = c.RedeliverWebhookLogs(ctx, w.ID, time.Time{}, time.Time{})
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1), resp.Count)
+	_, err = c.RedeliverWebhookLogs(ctx, w.ID, time.Now(), time.Now().Add(-time.Hour))
+	assert.NotNil(t, err)
+	_, err = c.RedeliverWebhookLogs(ctx, w.ID+100, time.Time{}, time.Time{})
+	assert.NotNil(t, err)
+
+	// the attempt history is listed with the logs
+	query := q.New(nil)
+	query.PageNumber = common.DefaultPageNumber
+	query.PageSize = common.DefaultPageSize
+	summaries, _, err := c.ListWebhookLogs(ctx, w.ID, query)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(summaries))
+	assert.Equal(t, 3, len(summaries[0].History))
+}
+
+func TestRotateSecret(t *testing.T) {
+	createContext()
+
+	// invalid content mode
+	req := createWebhookReq
+	req.Secret = "old"
+	req.ContentMode = "xml"
+	_, err := c.CreateWebhook(ctx, resourceType, resourceID, &req)
+	assert.NotNil(t, err)
+
+	req.ContentMode = webhookmodels.ContentModeCloudEventsBinary
+	w, err := c.CreateWebhook(ctx, resourceType, resourceID, &req)
+	assert.Nil(t, err)
+	assert.Equal(t, webhookmodels.ContentModeCloudEventsBinary, w.ContentMode)
+	assert.Nil(t, w.PreviousSecretExpiredAt)
+	mode := webhookmodels.ContentModeCloudEventsStructured
+	w, err = c.UpdateWebhook(ctx, w.ID, &UpdateWebhookRequest{ContentMode: &mode})
+	assert.Nil(t, err)
+	assert.Equal(t, mode, w.ContentMode)
+
+	_, err = c.RotateWebhookSecret(ctx, w.ID, &RotateSecretRequest{})
+	assert.NotNil(t, err)
+	_, err = c.RotateWebhookSecret(ctx, w.ID, &RotateSecretRequest{Secret: "old"})
+	assert.NotNil(t, err)
+
+	// the old secret keeps signing during the default overlap
+	w, err = c.RotateWebhookSecret(ctx, w.ID, &RotateSecretRequest{Secret: "new"})
+	assert.Nil(t, err)
+	assert.Equal(t, "new", w.Secret)
+	assert.True(t, w.PreviousSecretExpiredAt.After(time.Now().Add(59*time.Minute)))
+	wm, err := c.webhookMgr.GetWebhook(ctx, w.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"new", "old"}, wm.Secrets(time.Now()))
+
+	w, err = c.RotateWebhookSecret(ctx, w.ID, &RotateSecretRequest{Secret: "newer", Overlap: 60})
+	assert.Nil(t, err)
+	assert.True(t, w.PreviousSecretExpiredAt.Before(time.Now().Add(2*time.Minute)))
+	wm, err = c.webhookMgr.GetWebhook(ctx, w.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"newer", "new"}, wm.Secrets(time.Now()))
+	assert.Equal(t, []string{"newer"}, wm.Secrets(time.Now().Add(time.Hour)))
+
+	// the previous secret is kept until it expires, and cleared after that
+	_, err = c.webhookMgr.ClearExpiredPreviousSecrets(ctx, time.Now())
+	assert.Nil(t, err)
+	wm, err = c.webhookMgr.GetWebhook(ctx, w.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, "new", wm.PreviousSecret)
+	cleared, err := c.webhookMgr.ClearExpiredPreviousSecrets(ctx, time.Now().Add(time.Hour))
+	assert.Nil(t, err)
+	assert.True(t, cleared >= 1)
+	wm, err = c.webhookMgr.GetWebhook(ctx, w.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, "", wm.PreviousSecret)
+	assert.Nil(t, wm.PreviousSecretExpiredAt)
+	assert.Equal(t, "newer", wm.Secret)
+}
+
+func TestNotificationChannel(t *testing.T) {
+	createContext()
+	// webhooks of other tests are created for resourceID
+	channelResourceID := uint(100)
+
+	req := CreateNotificationChannelRequest{
+		Enabled:  true,
+		Type:     "wechat",
+		URL:      "https://open.feishu.cn/open-apis/bot/v2/hook/x",
+		Triggers: []string{eventmodels.ClusterFreed, eventmodels.ClusterPipelinerunFailed},
+	}
+	_, err := c.CreateNotificationChannel(ctx, resourceType, channelResourceID, &req)
+	assert.NotNil(t, err)
+	req.Type = webhookmodels.ChannelFeishu
+	req.Templates = map[string]*webhookmodels.NotificationTemplate{
+		eventmodels.ClusterFreed: {Title: "{{.cluster.name", Text: "freed"},
+	}
+	_, err = c.CreateNotificationChannel(ctx, resourceType, channelResourceID, &req)
+	assert.NotNil(t, err)
+	req.Templates = map[string]*webhookmodels.NotificationTemplate{
+		"unknown": {Title: "{{.cluster.name}}", Text: "freed"},
+	}
+	_, err = c.CreateNotificationChannel(ctx, resourceType, channelResourceID, &req)
+	assert.NotNil(t, err)
+
+	req.Templates = map[string]*webhookmodels.NotificationTemplate{
+		eventmodels.ClusterFreed: {Title: "{{.cluster.name}}", Text: "freed"},
+	}
+	channel, err := c.CreateNotificationChannel(ctx, resourceType, channelResourceID, &req)
+	assert.Nil(t, err)
+	assert.Equal(t, webhookmodels.ChannelFeishu, channel.Type)
+	assert.Equal(t, "freed", channel.Templates[eventmodels.ClusterFreed].Text)
+
+	// email needs valid recipients
+	_, err = c.CreateNotificationChannel(ctx, resourceType, channelResourceID, &CreateNotificationChannelRequest{
+		Type:       webhookmodels.ChannelEmail,
+		Recipients: []string{"not an address"},
+		Triggers:   []string{eventmodels.Any},
+	})
+	assert.NotNil(t, err)
+	mailChannel, err := c.CreateNotificationChannel(ctx, resourceType, channelResourceID,
+		&CreateNotificationChannelRequest{
+			Type:       webhookmodels.ChannelEmail,
+			Recipients: []string{"dev@example.com"},
+			Triggers:   []string{eventmodels.Any},
+		})
+	assert.Nil(t, err)
+
+	// channels and webhooks are listed apart
+	w, err := c.CreateWebhook(ctx, resourceType, channelResourceID, &createWebhookReq)
+	assert.Nil(t, err)
+	channels, total, err := c.ListNotificationChannels(ctx, resourceType, channelResourceID, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(2), total)
+	assert.Equal(t, 2, len(channels))
+	webhooks, total, err := c.ListWebhooks(ctx, resourceType, channelResourceID, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1), total)
+	assert.Equal(t, w.ID, webhooks[0].ID)
+	_, err = c.GetNotificationChannel(ctx, w.ID)
+	_, ok := perror.Cause(err).(*herrors.HorizonErrNotFound)
+	assert.True(t, ok)
+
+	channel, err = c.UpdateNotificationChannel(ctx, channel.ID, &UpdateNotificationChannelRequest{
+		Enabled:  utilcommon.BoolPtr(false),
+		Triggers: []string{eventmodels.ClusterRollbacked},
+	})
+	assert.Nil(t, err)
+	assert.False(t, channel.Enabled)
+	assert.Equal(t, []string{eventmodels.ClusterRollbacked}, channel.Triggers)
+	assert.Equal(t, "freed", channel.Templates[eventmodels.ClusterFreed].Text)
+	_, err = c.UpdateNotificationChannel(ctx, mailChannel.ID, &UpdateNotificationChannelRequest{
+		Recipients: []string{},
+	})
+	assert.NotNil(t, err)
+
+	assert.Nil(t, c.DeleteNotificationChannel(ctx, channel.ID))
+	_, err = c.GetNotificationChannel(ctx, channel.ID)
+	assert.NotNil(t, err)
+	assert.NotNil(t, c.DeleteNotificationChannel(ctx, w.ID))
+}
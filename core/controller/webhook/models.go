@@ -17,6 +17,7 @@ package webhook
 import (
 	"context"
 	"fmt"
+	"net/mail"
 	"strings"
 	"time"
 
@@ -27,10 +28,14 @@ import (
 	usermodels "github.com/horizoncd/horizon/pkg/user/models"
 	commonvalidate "github.com/horizoncd/horizon/pkg/util/validate"
 	wmodels "github.com/horizoncd/horizon/pkg/webhook/models"
+	"github.com/horizoncd/horizon/pkg/webhook/notification"
 )
 
 const (
 	_triggerSeparator = ","
+
+	// _maxRetryAttempts limits the attempts of a delivery
+	_maxRetryAttempts = 10
 )
 
 type UpdateWebhookRequest struct {
@@ -40,6 +45,9 @@ type UpdateWebhookRequest struct {
 	Description      *string  `json:"description"`
 	Secret           *string  `json:"secret"`
 	Triggers         []string `json:"triggers"`
+	// RetryPolicy replaces the retry policy of the webhook, the default policy is used again if maxAttempts is 0
+	RetryPolicy *wmodels.RetryPolicy `json:"retryPolicy"`
+	ContentMode *string              `json:"contentMode"`
 }
 
 type CreateWebhookRequest struct {
@@ -49,33 +57,63 @@ type CreateWebhookRequest struct {
 	Description      string   `json:"description"`
 	Secret           string   `json:"secret"`
 	Triggers         []string `json:"triggers"`
+	// RetryPolicy is the retry policy of failed deliveries, the default policy is used if it's not set
+	RetryPolicy *wmodels.RetryPolicy `json:"retryPolicy,omitempty"`
+	// ContentMode is one of json, cloudevents-structured and cloudevents-binary, json by default
+	ContentMode string `json:"contentMode,omitempty"`
+}
+
+// RotateSecretRequest rotates the secret of a webhook
+type RotateSecretRequest struct {
+	Secret string `json:"secret"`
+	// Overlap is the seconds the old secret keeps signing requests, the default overlap is used if it's 0
+	Overlap uint `json:"overlap"`
 }
 
 type Webhook struct {
 	CreateWebhookRequest
-	ID        uint                  `json:"id"`
-	CreatedAt time.Time             `json:"createdAt"`
-	CreatedBy *usermodels.UserBasic `json:"createdBy,omitempty"`
-	UpdatedAt time.Time             `json:"updatedAt"`
-	UpdatedBy *usermodels.UserBasic `json:"updatedBy,omitempty"`
+	// PreviousSecretExpiredAt is when the secret before the last rotation stops signing requests
+	PreviousSecretExpiredAt *time.Time            `json:"previousSecretExpiredAt,omitempty"`
+	ID                      uint                  `json:"id"`
+	CreatedAt               time.Time             `json:"createdAt"`
+	CreatedBy               *usermodels.UserBasic `json:"createdBy,omitempty"`
+	UpdatedAt               time.Time             `json:"updatedAt"`
+	UpdatedBy               *usermodels.UserBasic `json:"updatedBy,omitempty"`
 }
 
 type LogSummary struct {
-	ID           uint                  `json:"id"`
-	WebhookID    uint                  `json:"webhookID"`
-	EventID      uint                  `json:"eventID"`
-	URL          string                `json:"url"`
-	Status       string                `json:"status"`
-	ResourceType string                `json:"resourceType"`
-	ResourceName string                `json:"resourceName"`
-	ResourceID   uint                  `json:"resourceID"`
-	EventType    string                `json:"eventType"`
-	Extra        *string               `json:"extra"`
-	ErrorMessage string                `json:"errorMessage"`
-	CreatedAt    time.Time             `json:"createdAt"`
-	CreatedBy    *usermodels.UserBasic `json:"createdBy,omitempty"`
-	UpdatedAt    time.Time             `json:"updatedAt"`
-	UpdatedBy    *usermodels.UserBasic `json:"updatedBy,omitempty"`
+	ID           uint    `json:"id"`
+	WebhookID    uint    `json:"webhookID"`
+	EventID      uint    `json:"eventID"`
+	URL          string  `json:"url"`
+	Status       string  `json:"status"`
+	ResourceType string  `json:"resourceType"`
+	ResourceName string  `json:"resourceName"`
+	ResourceID   uint    `json:"resourceID"`
+	EventType    string  `json:"eventType"`
+	Extra        *string `json:"extra"`
+	ErrorMessage string  `json:"errorMessage"`
+	// Attempts is the number of the attempts since the log is created or redelivered
+	Attempts      uint                  `json:"attempts"`
+	NextAttemptAt *time.Time            `json:"nextAttemptAt,omitempty"`
+	History       []*LogAttempt         `json:"history"`
+	CreatedAt     time.Time             `json:"createdAt"`
+	CreatedBy     *usermodels.UserBasic `json:"createdBy,omitempty"`
+	UpdatedAt     time.Time             `json:"updatedAt"`
+	UpdatedBy     *usermodels.UserBasic `json:"updatedBy,omitempty"`
+}
+
+// LogAttempt is an attempt to send the webhook log
+type LogAttempt struct {
+	Attempt      uint      `json:"attempt"`
+	StatusCode   int       `json:"statusCode"`
+	ErrorMessage string    `json:"errorMessage"`
+	CreatedAt    time.Time `json:"createdAt"`
+}
+
+// RedeliverResponse tells how many webhook logs are redelivered
+type RedeliverResponse struct {
+	Count int64 `json:"count"`
 }
 
 type Log struct {
@@ -105,6 +143,16 @@ func (w *UpdateWebhookRequest) toModel(wm *wmodels.Webhook) *wmodels.Webhook {
 	if len(w.Triggers) > 0 {
 		wm.Triggers = JoinTriggers(w.Triggers)
 	}
+	if w.RetryPolicy != nil {
+		if w.RetryPolicy.MaxAttempts == 0 {
+			wm.SetRetryPolicy(nil)
+		} else {
+			wm.SetRetryPolicy(w.RetryPolicy)
+		}
+	}
+	if w.ContentMode != nil {
+		wm.ContentMode = *w.ContentMode
+	}
 	return wm
 }
 
@@ -114,6 +162,16 @@ func (c *controller) validateUpdateRequest(w *UpdateWebhookRequest) error {
 			return err
 		}
 	}
+	if w.RetryPolicy != nil && w.RetryPolicy.MaxAttempts > 0 {
+		if err := validateRetryPolicy(w.RetryPolicy); err != nil {
+			return err
+		}
+	}
+	if w.ContentMode != nil {
+		if err := validateContentMode(*w.ContentMode); err != nil {
+			return err
+		}
+	}
 	if len(w.Triggers) > 0 {
 		return c.validateEvents(w.Triggers)
 	}
@@ -131,7 +189,9 @@ func (w *CreateWebhookRequest) toModel(ctx context.Context,
 		Description:      w.Description,
 		Secret:           w.Secret,
 		Triggers:         JoinTriggers(w.Triggers),
+		ContentMode:      w.ContentMode,
 	}
+	wm.SetRetryPolicy(w.RetryPolicy)
 	return wm, nil
 }
 
@@ -147,9 +207,48 @@ func (c *controller) validateCreateRequest(resourceType string, w *CreateWebhook
 		return perror.Wrapf(herrors.ErrParamInvalid, "sslVerifyEnabled is only valid for https")
 	}
 
+	if w.RetryPolicy != nil {
+		if err := validateRetryPolicy(w.RetryPolicy); err != nil {
+			return err
+		}
+	}
+	if err := validateContentMode(w.ContentMode); err != nil {
+		return err
+	}
+
 	return c.validateEvents(w.Triggers)
 }
 
+func validateContentMode(mode string) error {
+	switch mode {
+	case "", wmodels.ContentModeJSON, wmodels.ContentModeCloudEventsStructured,
+		wmodels.ContentModeCloudEventsBinary:
+	default:
+		return perror.Wrapf(herrors.ErrParamInvalid, "invalid content mode %s", mode)
+	}
+	return nil
+}
+
+func validateRetryPolicy(policy *wmodels.RetryPolicy) error {
+	if policy.MaxAttempts < 1 || policy.MaxAttempts > _maxRetryAttempts {
+		return perror.Wrapf(herrors.ErrParamInvalid,
+			"maxAttempts of retry policy should be between 1 and %d", _maxRetryAttempts)
+	}
+	if policy.Multiplier != 0 && policy.Multiplier < 1 {
+		return perror.Wrap(herrors.ErrParamInvalid, "multiplier of retry policy should not be less than 1")
+	}
+	if policy.MaxInterval != 0 && policy.MaxInterval < policy.InitialInterval {
+		return perror.Wrap(herrors.ErrParamInvalid,
+			"maxInterval of retry policy should not be less than initialInterval")
+	}
+	for _, code := range policy.RetryOnStatusCodes {
+		if code < 100 || code > 599 {
+			return perror.Wrapf(herrors.ErrParamInvalid, "invalid status code to retry on: %d", code)
+		}
+	}
+	return nil
+}
+
 func (c *controller) validateResourceType(resource string) error {
 	switch resource {
 	case common.ResourceGroup, common.ResourceApplication, common.ResourceCluster:
@@ -204,44 +303,67 @@ func ofWebhookModel(wm *wmodels.Webhook) *Webhook {
 			Description:      wm.Description,
 			Secret:           wm.Secret,
 			Triggers:         ParseTriggerStr(wm.Triggers),
+			RetryPolicy:      wm.GetRetryPolicy(),
+			ContentMode:      wm.ContentMode,
 		},
 		ID:        wm.ID,
 		CreatedAt: wm.CreatedAt,
 		UpdatedAt: wm.UpdatedAt,
 	}
+	if len(wm.Secrets(time.Now())) > 1 {
+		w.PreviousSecretExpiredAt = wm.PreviousSecretExpiredAt
+	}
 
 	return w
 }
 
 func ofWebhookLogSummaryModel(wm *wmodels.WebhookLogWithEventInfo) *LogSummary {
 	wl := &LogSummary{
-		ID:           wm.ID,
-		WebhookID:    wm.WebhookID,
-		EventID:      wm.EventID,
-		URL:          wm.URL,
-		ResourceType: wm.ResourceType,
-		ResourceID:   wm.ResourceID,
-		ResourceName: wm.ResourceName,
-		EventType:    wm.EventType,
-		Status:       wm.Status,
-		ErrorMessage: wm.ErrorMessage,
-		CreatedAt:    wm.CreatedAt,
-		UpdatedAt:    wm.UpdatedAt,
+		ID:            wm.ID,
+		WebhookID:     wm.WebhookID,
+		EventID:       wm.EventID,
+		URL:           wm.URL,
+		ResourceType:  wm.ResourceType,
+		ResourceID:    wm.ResourceID,
+		ResourceName:  wm.ResourceName,
+		EventType:     wm.EventType,
+		Status:        wm.Status,
+		ErrorMessage:  wm.ErrorMessage,
+		Attempts:      wm.Attempts,
+		NextAttemptAt: wm.NextAttemptAt,
+		History:       ofWebhookLogAttemptModels(wm.History),
+		CreatedAt:     wm.CreatedAt,
+		UpdatedAt:     wm.UpdatedAt,
 	}
 	return wl
 }
 
+func ofWebhookLogAttemptModels(attempts []*wmodels.WebhookLogAttempt) []*LogAttempt {
+	history := make([]*LogAttempt, 0, len(attempts))
+	for _, attempt := range attempts {
+		history = append(history, &LogAttempt{
+			Attempt:      attempt.Attempt,
+			StatusCode:   attempt.StatusCode,
+			ErrorMessage: attempt.ErrorMessage,
+			CreatedAt:    attempt.CreatedAt,
+		})
+	}
+	return history
+}
+
 func ofWebhookLogModel(wm *wmodels.WebhookLog) *Log {
 	wl := &Log{
 		LogSummary: LogSummary{
-			ID:           wm.ID,
-			WebhookID:    wm.WebhookID,
-			EventID:      wm.EventID,
-			URL:          wm.URL,
-			Status:       wm.Status,
-			ErrorMessage: wm.ErrorMessage,
-			CreatedAt:    wm.CreatedAt,
-			UpdatedAt:    wm.UpdatedAt,
+			ID:            wm.ID,
+			WebhookID:     wm.WebhookID,
+			EventID:       wm.EventID,
+			URL:           wm.URL,
+			Status:        wm.Status,
+			ErrorMessage:  wm.ErrorMessage,
+			Attempts:      wm.Attempts,
+			NextAttemptAt: wm.NextAttemptAt,
+			CreatedAt:     wm.CreatedAt,
+			UpdatedAt:     wm.UpdatedAt,
 		},
 		RequestHeaders:  wm.RequestHeaders,
 		RequestData:     wm.RequestData,
@@ -250,3 +372,134 @@ func ofWebhookLogModel(wm *wmodels.WebhookLog) *Log {
 	}
 	return wl
 }
+
+type CreateNotificationChannelRequest struct {
+	Enabled bool `json:"enabled"`
+	// Type is one of slack, dingtalk, feishu and email
+	Type string `json:"type"`
+	// URL is the url of the incoming webhook or the bot, it's not needed by email
+	URL string `json:"url"`
+	// Secret signs the requests to the bots of dingtalk and feishu
+	Secret      string   `json:"secret"`
+	Description string   `json:"description"`
+	Triggers    []string `json:"triggers"`
+	// Recipients are the addresses to mail for email
+	Recipients []string `json:"recipients,omitempty"`
+	// Templates override the default templates by event type, "*" overrides the templates of all the events
+	Templates map[string]*wmodels.NotificationTemplate `json:"templates,omitempty"`
+}
+
+// UpdateNotificationChannelRequest updates the channel, its type can't be changed
+type UpdateNotificationChannelRequest struct {
+	Enabled     *bool                                    `json:"enabled"`
+	URL         *string                                  `json:"url"`
+	Secret      *string                                  `json:"secret"`
+	Description *string                                  `json:"description"`
+	Triggers    []string                                 `json:"triggers"`
+	Recipients  []string                                 `json:"recipients"`
+	Templates   map[string]*wmodels.NotificationTemplate `json:"templates"`
+}
+
+type NotificationChannel struct {
+	CreateNotificationChannelRequest
+	ID        uint      `json:"id"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+func (r *CreateNotificationChannelRequest) toModel(resourceType string, resourceID uint) *wmodels.Webhook {
+	wm := &wmodels.Webhook{
+		ResourceType:     resourceType,
+		ResourceID:       resourceID,
+		Enabled:          r.Enabled,
+		URL:              r.URL,
+		SSLVerifyEnabled: true,
+		Description:      r.Description,
+		Secret:           r.Secret,
+		Triggers:         JoinTriggers(r.Triggers),
+		Channel:          r.Type,
+	}
+	wm.SetChannelConfig(&wmodels.ChannelConfig{
+		Recipients: r.Recipients,
+		Templates:  r.Templates,
+	})
+	return wm
+}
+
+func (r *UpdateNotificationChannelRequest) toModel(wm *wmodels.Webhook) *wmodels.Webhook {
+	if r.Enabled != nil {
+		wm.Enabled = *r.Enabled
+	}
+	if r.URL != nil {
+		wm.URL = *r.URL
+	}
+	if r.Secret != nil {
+		wm.Secret = *r.Secret
+	}
+	if r.Description != nil {
+		wm.Description = *r.Description
+	}
+	if len(r.Triggers) > 0 {
+		wm.Triggers = JoinTriggers(r.Triggers)
+	}
+	config := wm.GetChannelConfig()
+	if r.Recipients != nil {
+		config.Recipients = r.Recipients
+	}
+	if r.Templates != nil {
+		config.Templates = r.Templates
+	}
+	wm.SetChannelConfig(config)
+	return wm
+}
+
+func (c *controller) validateNotificationChannel(channel, url string, recipients []string,
+	templates map[string]*wmodels.NotificationTemplate, triggers []string) error {
+	switch channel {
+	case wmodels.ChannelSlack, wmodels.ChannelDingTalk, wmodels.ChannelFeishu:
+		if err := commonvalidate.CheckURL(url); err != nil {
+			return err
+		}
+	case wmodels.ChannelEmail:
+		if len(recipients) == 0 {
+			return perror.Wrap(herrors.ErrParamInvalid, "recipients should not be empty")
+		}
+		for _, recipient := range recipients {
+			if _, err := mail.ParseAddress(recipient); err != nil {
+				return perror.Wrapf(herrors.ErrParamInvalid, "invalid recipient %s", recipient)
+			}
+		}
+	default:
+		return perror.Wrapf(herrors.ErrParamInvalid, "invalid notification channel type %s", channel)
+	}
+	for eventType := range templates {
+		if eventType != models.Any {
+			if err := c.validateEvents([]string{eventType}); err != nil {
+				return err
+			}
+		}
+	}
+	if err := notification.ValidateTemplates(templates); err != nil {
+		return perror.Wrap(herrors.ErrParamInvalid, err.Error())
+	}
+	return c.validateEvents(triggers)
+}
+
+func ofNotificationChannelModel(wm *wmodels.Webhook) *NotificationChannel {
+	config := wm.GetChannelConfig()
+	return &NotificationChannel{
+		CreateNotificationChannelRequest: CreateNotificationChannelRequest{
+			Enabled:     wm.Enabled,
+			Type:        wm.Channel,
+			URL:         wm.URL,
+			Secret:      wm.Secret,
+			Description: wm.Description,
+			Triggers:    ParseTriggerStr(wm.Triggers),
+			Recipients:  config.Recipients,
+			Templates:   config.Templates,
+		},
+		ID:        wm.ID,
+		CreatedAt: wm.CreatedAt,
+		UpdatedAt: wm.UpdatedAt,
+	}
+}
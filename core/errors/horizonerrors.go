@@ -71,10 +71,24 @@ var (
 	WebhookInDB               = sourceType{name: "WebhookInDB"}
 	WebhookLogInDB            = sourceType{name: "WebhookLogInDB"}
 	MetatagInDB               = sourceType{name: "MetatagInDB"}
+	PromotionPathInDB         = sourceType{name: "PromotionPathInDB"}
+	FreezeWindowInDB          = sourceType{name: "FreezeWindowInDB"}
+	CanaryAnalysisInDB        = sourceType{name: "CanaryAnalysisInDB"}
+	ReleasePlanInDB           = sourceType{name: "ReleasePlanInDB"}
+	DriftReportInDB           = sourceType{name: "DriftReportInDB"}
+	BatchJobInDB              = sourceType{name: "BatchJobInDB"}
+	PipelinerunReportInDB     = sourceType{name: "PipelinerunReportInDB"}
+	ScheduleInDB              = sourceType{name: "ScheduleInDB"}
+	AutoDeployInDB            = sourceType{name: "AutoDeployInDB"}
+	RunQueueInDB              = sourceType{name: "RunQueueInDB"}
+	BuildContextInDB          = sourceType{name: "BuildContextInDB"}
+	PipelinerunProvenanceInDB = sourceType{name: "PipelinerunProvenanceInDB"}
+	AuditLogInDB              = sourceType{name: "AuditLogInDB"}
 
 	// S3
-	PipelinerunLog = sourceType{name: "PipelinerunLog"}
-	PipelinerunObj = sourceType{name: "PipelinerunObj"}
+	PipelinerunLog    = sourceType{name: "PipelinerunLog"}
+	PipelinerunObj    = sourceType{name: "PipelinerunObj"}
+	PipelinerunReport = sourceType{name: "PipelinerunReport"}
 
 	ArgoCD = sourceType{name: "ArgoCD"}
 
@@ -221,6 +235,22 @@ var (
 	ErrBuildDeployNotSupported = errors.New("builddeploy is not supported for this cluster")
 
 	// pipelinerun
+	ErrPipelinerunNotPending = errors.New("pipelinerun is not pending for approval")
+	ErrVulnerabilityPolicy   = errors.New("critical vulnerabilities exceed the limit of the deploy policy")
+	ErrClusterBusy           = errors.New("cluster is held by another pipelinerun")
+
+	// release plan
+	ErrReleasePlanStatusInvalid = errors.New("operation is not allowed in current status of release plan")
+	ErrReleasePlanConflict      = errors.New("another release plan of the application is in progress")
+
+	// batch job
+	ErrBatchJobStatusInvalid = errors.New("operation is not allowed in current status of batch job")
+
+	// drift
+	ErrDriftStatusInvalid = errors.New("operation is not allowed in current status of drift report")
+
+	// git
+	ErrSignatureInvalid = errors.New("signature of the webhook is invalid")
 
 	// context
 	ErrFailedToGetORM       = errors.New("cannot get the ORM from context")
@@ -255,7 +285,11 @@ var (
 	ErrTektonInternal = errors.New("tekton internal error")
 
 	// helm
-	ErrLoadChartArchive = errors.New("failed to load archive")
+	ErrLoadChartArchive    = errors.New("failed to load archive")
+	ErrChartDigestMismatch = errors.New("chart of the tag is not the one uploaded for the template release")
+
+	// tls
+	ErrLoadCertFailed = errors.New("failed to load certificates")
 
 	// group
 	// ErrHasChildren used when delete a group which still has some children
@@ -0,0 +1,159 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audit
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/horizoncd/horizon/core/common"
+	"github.com/horizoncd/horizon/core/controller/audit"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/lib/q"
+	auditexport "github.com/horizoncd/horizon/pkg/audit"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/server/response"
+	"github.com/horizoncd/horizon/pkg/server/rpcerror"
+	"github.com/horizoncd/horizon/pkg/util/log"
+)
+
+const _formatQuery = "format"
+
+type API struct {
+	auditCtl audit.Controller
+}
+
+func NewAPI(ctl audit.Controller) *API {
+	return &API{
+		auditCtl: ctl,
+	}
+}
+
+// ListAuditLogs lists the audit logs filtered by the query, times are in RFC3339
+func (a *API) ListAuditLogs(c *gin.Context) {
+	const op = "audit: list logs"
+	keywords, err := parseKeywords(c)
+	if err != nil {
+		response.AbortWithRPCError(c, rpcerror.ParamError.WithErrMsg(err.Error()))
+		return
+	}
+
+	items, total, err := a.auditCtl.List(c, q.New(keywords).WithPagination(c))
+	if err != nil {
+		if perror.Cause(err) == herrors.ErrForbidden {
+			response.AbortWithRPCError(c, rpcerror.ForbiddenError.WithErrMsg(err.Error()))
+			return
+		}
+		log.WithFiled(c, "op", op).Errorf("%+v", err)
+		response.AbortWithRPCError(c, rpcerror.InternalError.WithErrMsg(err.Error()))
+		return
+	}
+	response.SuccessWithData(c, response.DataWithTotal{
+		Items: items,
+		Total: total,
+	})
+}
+
+// ExportAuditLogs streams all the audit logs filtered by the query in JSON Lines or syslog format
+func (a *API) ExportAuditLogs(c *gin.Context) {
+	const op = "audit: export logs"
+	keywords, err := parseKeywords(c)
+	if err != nil {
+		response.AbortWithRPCError(c, rpcerror.ParamError.WithErrMsg(err.Error()))
+		return
+	}
+
+	format := c.Query(_formatQuery)
+	if format == "" {
+		format = auditexport.FormatJSONLines
+	}
+	w := &exportWriter{c: c, format: format}
+	if err := a.auditCtl.Export(c, q.New(keywords), format, w); err != nil {
+		if w.written {
+			// the response has been partially sent, nothing can be done except logging
+			log.WithFiled(c, "op", op).Errorf("failed to export audit logs: %+v", err)
+			return
+		}
+		if perror.Cause(err) == herrors.ErrParamInvalid {
+			response.AbortWithRPCError(c, rpcerror.ParamError.WithErrMsg(err.Error()))
+			return
+		} else if perror.Cause(err) == herrors.ErrForbidden {
+			response.AbortWithRPCError(c, rpcerror.ForbiddenError.WithErrMsg(err.Error()))
+			return
+		}
+		log.WithFiled(c, "op", op).Errorf("%+v", err)
+		response.AbortWithRPCError(c, rpcerror.InternalError.WithErrMsg(err.Error()))
+		return
+	}
+	if !w.written {
+		w.writeHeader()
+	}
+}
+
+func parseKeywords(c *gin.Context) (q.KeyWords, error) {
+	keywords := q.KeyWords{}
+	for _, key := range []string{common.AuditQueryActor, common.AuditQueryActorType,
+		common.AuditQueryResource, common.AuditQueryResourceName, common.AuditQueryDecision,
+		common.AuditQueryMethod, common.ReqID} {
+		if value := c.Query(key); value != "" {
+			keywords[key] = value
+		}
+	}
+	for _, key := range []string{common.AuditQueryStartTime, common.AuditQueryEndTime} {
+		value := c.Query(key)
+		if value == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %s, it should be in RFC3339", key, value)
+		}
+		keywords[key] = t
+	}
+	return keywords, nil
+}
+
+// exportWriter defers the headers of the attachment until the first write,
+// so that errors found before streaming can still be responded in json
+type exportWriter struct {
+	c       *gin.Context
+	format  string
+	written bool
+}
+
+func (w *exportWriter) writeHeader() {
+	contentType, ext := "application/x-ndjson", "jsonl"
+	if w.format == auditexport.FormatSyslog {
+		contentType, ext = "text/plain; charset=utf-8", "log"
+	}
+	w.c.Header("Content-Type", contentType)
+	w.c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=auditlogs.%s", ext))
+	w.c.Status(http.StatusOK)
+	w.written = true
+}
+
+func (w *exportWriter) Write(p []byte) (int, error) {
+	if !w.written {
+		w.writeHeader()
+	}
+	return w.c.Writer.Write(p)
+}
+
+func (w *exportWriter) Flush() {
+	w.c.Writer.Flush()
+}
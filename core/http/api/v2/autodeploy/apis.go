@@ -0,0 +1,138 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package autodeploy
+
+import (
+	"io/ioutil"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/horizoncd/horizon/core/common"
+	"github.com/horizoncd/horizon/core/controller/autodeploy"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/server/response"
+	"github.com/horizoncd/horizon/pkg/server/rpcerror"
+	"github.com/horizoncd/horizon/pkg/util/log"
+)
+
+type API struct {
+	autoDeployCtl autodeploy.Controller
+}
+
+func NewAPI(ctl autodeploy.Controller) *API {
+	return &API{
+		autoDeployCtl: ctl,
+	}
+}
+
+func (a *API) Get(c *gin.Context) {
+	const op = "auto deploy: get"
+	clusterID, ok := clusterID(c)
+	if !ok {
+		return
+	}
+	resp, err := a.autoDeployCtl.Get(c, clusterID)
+	if err != nil {
+		abortWithError(c, op, err)
+		return
+	}
+	response.SuccessWithData(c, resp)
+}
+
+func (a *API) Update(c *gin.Context) {
+	const op = "auto deploy: update"
+	clusterID, ok := clusterID(c)
+	if !ok {
+		return
+	}
+
+	var request autodeploy.UpdateAutoDeployRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		response.AbortWithRPCError(c, rpcerror.ParamError.
+			WithErrMsgf("invalid request body, err: %s", err.Error()))
+		return
+	}
+
+	resp, err := a.autoDeployCtl.Update(c, clusterID, &request)
+	if err != nil {
+		abortWithError(c, op, err)
+		return
+	}
+	response.SuccessWithData(c, resp)
+}
+
+func (a *API) Delete(c *gin.Context) {
+	const op = "auto deploy: delete"
+	clusterID, ok := clusterID(c)
+	if !ok {
+		return
+	}
+	if err := a.autoDeployCtl.Delete(c, clusterID); err != nil {
+		abortWithError(c, op, err)
+		return
+	}
+	response.Success(c)
+}
+
+// ReceiveGitWebhook receives the push webhooks of gitlab and github
+func (a *API) ReceiveGitWebhook(c *gin.Context) {
+	const op = "auto deploy: receive git webhook"
+	body, err := ioutil.ReadAll(c.Request.Body)
+	if err != nil {
+		response.AbortWithRPCError(c, rpcerror.ParamError.
+			WithErrMsgf("failed to read request body, err: %s", err.Error()))
+		return
+	}
+	resp, err := a.autoDeployCtl.ReceivePushEvent(c, c.Request.Header, body)
+	if err != nil {
+		if perror.Cause(err) == herrors.ErrSignatureInvalid {
+			response.AbortWithRPCError(c, rpcerror.Unauthorized.WithErrMsg(err.Error()))
+			return
+		}
+		abortWithError(c, op, err)
+		return
+	}
+	response.SuccessWithData(c, resp)
+}
+
+func clusterID(c *gin.Context) (uint, bool) {
+	idStr := c.Param(common.ParamClusterID)
+	id, err := strconv.ParseUint(idStr, 10, 0)
+	if err != nil {
+		response.AbortWithRPCError(c, rpcerror.ParamError.
+			WithErrMsgf("invalid cluster id: %s", idStr))
+		return 0, false
+	}
+	return uint(id), true
+}
+
+func abortWithError(c *gin.Context, op string, err error) {
+	if _, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); ok {
+		response.AbortWithRPCError(c, rpcerror.NotFoundError.WithErrMsg(err.Error()))
+		return
+	}
+	switch perror.Cause(err) {
+	case herrors.ErrParamInvalid:
+		response.AbortWithRPCError(c, rpcerror.ParamError.WithErrMsg(err.Error()))
+		return
+	case herrors.ErrForbidden:
+		response.AbortWithRPCError(c, rpcerror.ForbiddenError.WithErrMsg(err.Error()))
+		return
+	}
+	log.WithFiled(c, "op", op).Errorf("%+v", err)
+	response.AbortWithRPCError(c, rpcerror.InternalError.WithErrMsg(err.Error()))
+}
@@ -65,6 +65,10 @@ func (a *API) BuildDeploy(c *gin.Context) {
 			response.AbortWithRPCError(c, rpcerror.BadRequestError.WithErrMsg(err.Error()))
 			return
 		}
+		if perror.Cause(err) == herrors.ErrClusterBusy {
+			response.AbortWithRPCError(c, rpcerror.ConflictError.WithErrMsg(err.Error()))
+			return
+		}
 		log.WithFiled(c, "op", op).Errorf("%+v", err)
 		response.AbortWithRPCError(c, rpcerror.InternalError.WithErrMsg(err.Error()))
 		return
@@ -274,7 +278,7 @@ func (a *API) InternalDeploy(c *gin.Context) {
 			response.AbortWithUnauthorized(c, common.Unauthorized, err.Error())
 			return
 		}
-		if perror.Cause(err) == herrors.ErrForbidden {
+		if perror.Cause(err) == herrors.ErrForbidden || perror.Cause(err) == herrors.ErrVulnerabilityPolicy {
 			log.WithFiled(c, "op", op).Errorf("%+v", err)
 			response.AbortWithRPCError(c, rpcerror.ForbiddenError.WithErrMsg(err.Error()))
 			return
@@ -337,6 +341,9 @@ func (a *API) Restart(c *gin.Context) {
 		if e, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); ok && e.Source == herrors.ClusterInDB {
 			response.AbortWithRPCError(c, rpcerror.NotFoundError.WithErrMsg(err.Error()))
 			return
+		} else if perror.Cause(err) == herrors.ErrClusterBusy {
+			response.AbortWithRPCError(c, rpcerror.ConflictError.WithErrMsg(err.Error()))
+			return
 		}
 		log.WithFiled(c, "op", op).Errorf("%+v", err)
 		response.AbortWithRPCError(c, rpcerror.InternalError.WithErrMsg(err.Error()))
@@ -415,6 +422,10 @@ func (a *API) Deploy(c *gin.Context) {
 			response.AbortWithRPCError(c, rpcerror.BadRequestError.WithErrMsg(err.Error()))
 			return
 		}
+		if perror.Cause(err) == herrors.ErrClusterBusy {
+			response.AbortWithRPCError(c, rpcerror.ConflictError.WithErrMsg(err.Error()))
+			return
+		}
 
 		log.WithFiled(c, "op", op).Errorf("%+v", err)
 		response.AbortWithRPCError(c, rpcerror.InternalError.WithErrMsg(err.Error()))
@@ -509,6 +520,40 @@ func (a *API) Rollback(c *gin.Context) {
 	}
 
 	resp, err := a.clusterCtl.Rollback(c, uint(clusterID), request)
+	if err != nil {
+		if e, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); ok && e.Source == herrors.ClusterInDB {
+			response.AbortWithRPCError(c, rpcerror.NotFoundError.WithErrMsg(err.Error()))
+			return
+		} else if perror.Cause(err) == herrors.ErrParamInvalid {
+			response.AbortWithRPCError(c, rpcerror.BadRequestError.WithErrMsg(err.Error()))
+			return
+		} else if perror.Cause(err) == herrors.ErrClusterBusy {
+			response.AbortWithRPCError(c, rpcerror.ConflictError.WithErrMsg(err.Error()))
+			return
+		}
+		log.WithFiled(c, "op", op).Errorf("%+v", err)
+		response.AbortWithRPCError(c, rpcerror.InternalError.WithErrMsg(err.Error()))
+		return
+	}
+	response.SuccessWithData(c, resp)
+}
+
+func (a *API) Promote(c *gin.Context) {
+	op := "cluster: promote"
+	clusterIDStr := c.Param(common.ParamClusterID)
+	clusterID, err := strconv.ParseUint(clusterIDStr, 10, 0)
+	if err != nil {
+		response.AbortWithRequestError(c, common.InvalidRequestParam, err.Error())
+		return
+	}
+	var request *cluster.PromoteRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		response.AbortWithRequestError(c, common.InvalidRequestBody,
+			fmt.Sprintf("request body is invalid, err: %v", err))
+		return
+	}
+
+	resp, err := a.clusterCtl.PromoteCluster(c, uint(clusterID), request)
 	if err != nil {
 		if e, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); ok && e.Source == herrors.ClusterInDB {
 			response.AbortWithRPCError(c, rpcerror.NotFoundError.WithErrMsg(err.Error()))
@@ -524,6 +569,72 @@ func (a *API) Rollback(c *gin.Context) {
 	response.SuccessWithData(c, resp)
 }
 
+func (a *API) ApprovePipelinerun(c *gin.Context) {
+	op := "cluster: approve pipelinerun"
+	pipelinerunIDStr := c.Param(common.ParamPipelinerunID)
+	pipelinerunID, err := strconv.ParseUint(pipelinerunIDStr, 10, 0)
+	if err != nil {
+		response.AbortWithRequestError(c, common.InvalidRequestParam, err.Error())
+		return
+	}
+	var request *cluster.ReviewPipelinerunRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		response.AbortWithRequestError(c, common.InvalidRequestBody,
+			fmt.Sprintf("request body is invalid, err: %v", err))
+		return
+	}
+
+	resp, err := a.clusterCtl.ApprovePipelinerun(c, uint(pipelinerunID), request)
+	if err != nil {
+		a.abortWithReviewError(c, op, err)
+		return
+	}
+	response.SuccessWithData(c, resp)
+}
+
+func (a *API) RejectPipelinerun(c *gin.Context) {
+	op := "cluster: reject pipelinerun"
+	pipelinerunIDStr := c.Param(common.ParamPipelinerunID)
+	pipelinerunID, err := strconv.ParseUint(pipelinerunIDStr, 10, 0)
+	if err != nil {
+		response.AbortWithRequestError(c, common.InvalidRequestParam, err.Error())
+		return
+	}
+	var request *cluster.ReviewPipelinerunRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		response.AbortWithRequestError(c, common.InvalidRequestBody,
+			fmt.Sprintf("request body is invalid, err: %v", err))
+		return
+	}
+
+	if err := a.clusterCtl.RejectPipelinerun(c, uint(pipelinerunID), request); err != nil {
+		a.abortWithReviewError(c, op, err)
+		return
+	}
+	response.Success(c)
+}
+
+func (a *API) abortWithReviewError(c *gin.Context, op string, err error) {
+	if _, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); ok {
+		response.AbortWithRPCError(c, rpcerror.NotFoundError.WithErrMsg(err.Error()))
+		return
+	}
+	switch perror.Cause(err) {
+	case herrors.ErrForbidden:
+		response.AbortWithRPCError(c, rpcerror.ForbiddenError.WithErrMsg(err.Error()))
+		return
+	case herrors.ErrClusterBusy:
+		response.AbortWithRPCError(c, rpcerror.ConflictError.WithErrMsg(err.Error()))
+		return
+	case herrors.ErrPipelinerunNotPending, herrors.ErrParamInvalid, herrors.ErrClusterNoChange,
+		herrors.ErrShouldBuildDeployFirst:
+		response.AbortWithRPCError(c, rpcerror.BadRequestError.WithErrMsg(err.Error()))
+		return
+	}
+	log.WithFiled(c, "op", op).Errorf("%+v", err)
+	response.AbortWithRPCError(c, rpcerror.InternalError.WithErrMsg(err.Error()))
+}
+
 func (a *API) GetGrafanaDashBoard(c *gin.Context) {
 	op := "cluster: get dashboard"
 	clusterIDStr := c.Param(common.ParamClusterID)
@@ -571,3 +682,50 @@ func (a *API) DeleteClusterPods(c *gin.Context) {
 	}
 	response.SuccessWithData(c, resp)
 }
+
+func (a *API) MigrateTemplate(c *gin.Context) {
+	op := "cluster: migrate template"
+	releaseIDStr := c.Param(common.ParamReleaseID)
+	releaseID, err := strconv.ParseUint(releaseIDStr, 10, 0)
+	if err != nil {
+		response.AbortWithRequestError(c, common.InvalidRequestParam, err.Error())
+		return
+	}
+	dryRun := false
+	if dryRunStr, ok := c.GetQuery(common.ClusterQueryDryRun); ok {
+		dryRun, err = strconv.ParseBool(dryRunStr)
+		if err != nil {
+			response.AbortWithRequestError(c, common.InvalidRequestParam,
+				fmt.Sprintf("invalid %s: %v", common.ClusterQueryDryRun, err))
+			return
+		}
+	}
+	var request *cluster.TemplateMigrationRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&request); err != nil {
+			response.AbortWithRequestError(c, common.InvalidRequestBody,
+				fmt.Sprintf("request body is invalid, err: %v", err))
+			return
+		}
+	}
+
+	var resp *cluster.TemplateMigrationResponse
+	if dryRun {
+		resp, err = a.clusterCtl.DryRunTemplateMigration(c, uint(releaseID), request)
+	} else {
+		resp, err = a.clusterCtl.ApplyTemplateMigration(c, uint(releaseID), request)
+	}
+	if err != nil {
+		if e, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); ok && e.Source == herrors.TemplateReleaseInDB {
+			response.AbortWithRPCError(c, rpcerror.NotFoundError.WithErrMsg(err.Error()))
+			return
+		} else if perror.Cause(err) == herrors.ErrParamInvalid {
+			response.AbortWithRPCError(c, rpcerror.BadRequestError.WithErrMsg(err.Error()))
+			return
+		}
+		log.WithFiled(c, "op", op).Errorf("%+v", err)
+		response.AbortWithRPCError(c, rpcerror.InternalError.WithErrMsg(err.Error()))
+		return
+	}
+	response.SuccessWithData(c, resp)
+}
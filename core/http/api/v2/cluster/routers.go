@@ -86,6 +86,22 @@ func (api *API) RegisterRoute(engine *gin.Engine) {
 			Method:      http.MethodPost,
 			Pattern:     fmt.Sprintf("/clusters/:%v/rollback", common.ParamClusterID),
 			HandlerFunc: api.Rollback,
+		}, {
+			Method:      http.MethodPost,
+			Pattern:     fmt.Sprintf("/clusters/:%v/promotion", common.ParamClusterID),
+			HandlerFunc: api.Promote,
+		}, {
+			Method:      http.MethodPost,
+			Pattern:     fmt.Sprintf("/templatereleases/:%v/migration", common.ParamReleaseID),
+			HandlerFunc: api.MigrateTemplate,
+		}, {
+			Method:      http.MethodPost,
+			Pattern:     fmt.Sprintf("/pipelineruns/:%v/approve", common.ParamPipelinerunID),
+			HandlerFunc: api.ApprovePipelinerun,
+		}, {
+			Method:      http.MethodPost,
+			Pattern:     fmt.Sprintf("/pipelineruns/:%v/reject", common.ParamPipelinerunID),
+			HandlerFunc: api.RejectPipelinerun,
 		}, {
 			Method:      http.MethodPost,
 			Pattern:     fmt.Sprintf("/clusters/:%v/action", common.ParamClusterID),
@@ -0,0 +1,129 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dora
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/horizoncd/horizon/core/common"
+	"github.com/horizoncd/horizon/core/controller/dora"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/server/response"
+	"github.com/horizoncd/horizon/pkg/server/rpcerror"
+	"github.com/horizoncd/horizon/pkg/util/log"
+)
+
+const (
+	_startQuery       = "start"
+	_endQuery         = "end"
+	_intervalQuery    = "interval"
+	_environmentQuery = "environment"
+)
+
+type API struct {
+	doraCtl dora.Controller
+}
+
+func NewAPI(ctl dora.Controller) *API {
+	return &API{
+		doraCtl: ctl,
+	}
+}
+
+func (a *API) GetApplicationMetrics(c *gin.Context) {
+	const op = "dora: get application metrics"
+	applicationID, ok := parseID(c, common.ParamApplicationID, "application")
+	if !ok {
+		return
+	}
+	query, ok := parseQuery(c)
+	if !ok {
+		return
+	}
+	resp, err := a.doraCtl.GetApplicationMetrics(c, applicationID, query)
+	if err != nil {
+		abortWithError(c, op, err)
+		return
+	}
+	response.SuccessWithData(c, resp)
+}
+
+func (a *API) GetGroupMetrics(c *gin.Context) {
+	const op = "dora: get group metrics"
+	groupID, ok := parseID(c, common.ParamGroupID, "group")
+	if !ok {
+		return
+	}
+	query, ok := parseQuery(c)
+	if !ok {
+		return
+	}
+	resp, err := a.doraCtl.GetGroupMetrics(c, groupID, query)
+	if err != nil {
+		abortWithError(c, op, err)
+		return
+	}
+	response.SuccessWithData(c, resp)
+}
+
+func parseID(c *gin.Context, param, resource string) (uint, bool) {
+	idStr := c.Param(param)
+	id, err := strconv.ParseUint(idStr, 10, 0)
+	if err != nil {
+		response.AbortWithRPCError(c, rpcerror.ParamError.
+			WithErrMsgf("invalid %s id: %s", resource, idStr))
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// parseQuery parses the window in RFC3339
+func parseQuery(c *gin.Context) (*dora.Query, bool) {
+	query := &dora.Query{
+		Interval:    c.Query(_intervalQuery),
+		Environment: c.Query(_environmentQuery),
+	}
+	for key, t := range map[string]*time.Time{_startQuery: &query.Start, _endQuery: &query.End} {
+		value := c.Query(key)
+		if value == "" {
+			continue
+		}
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			response.AbortWithRPCError(c, rpcerror.ParamError.
+				WithErrMsgf("invalid %s: %s, it should be in RFC3339", key, value))
+			return nil, false
+		}
+		*t = parsed
+	}
+	return query, true
+}
+
+func abortWithError(c *gin.Context, op string, err error) {
+	if _, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); ok {
+		response.AbortWithRPCError(c, rpcerror.NotFoundError.WithErrMsg(err.Error()))
+		return
+	}
+	if perror.Cause(err) == herrors.ErrParamInvalid {
+		response.AbortWithRPCError(c, rpcerror.ParamError.WithErrMsg(err.Error()))
+		return
+	}
+	log.WithFiled(c, "op", op).Errorf("%+v", err)
+	response.AbortWithRPCError(c, rpcerror.InternalError.WithErrMsg(err.Error()))
+}
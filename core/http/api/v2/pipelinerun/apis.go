@@ -15,13 +15,17 @@
 package pipelinerun
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
+	"net/http"
 	"strconv"
 
 	"github.com/horizoncd/horizon/core/common"
 	prctl "github.com/horizoncd/horizon/core/controller/pipelinerun"
 	"github.com/horizoncd/horizon/lib/q"
 	"github.com/horizoncd/horizon/pkg/cluster/tekton/collector"
+	reportmodels "github.com/horizoncd/horizon/pkg/pipelinerun/report/models"
 	"github.com/horizoncd/horizon/pkg/server/request"
 	"github.com/horizoncd/horizon/pkg/server/response"
 	"github.com/horizoncd/horizon/pkg/util/errors"
@@ -33,6 +37,17 @@ const (
 	_pipelinerunIDParam = "pipelinerunID"
 	_clusterIDParam     = "clusterID"
 	_canRollbackParam   = "canRollback"
+	_offsetParam        = "offset"
+	_reportIDParam      = "reportID"
+	// _lastEventIDHeader is sent by the browser when it reconnects to an event stream
+	_lastEventIDHeader = "Last-Event-ID"
+)
+
+// events of the log stream
+const (
+	_eventLog   = "log"
+	_eventEnd   = "end"
+	_eventError = "error"
 )
 
 type API struct {
@@ -79,11 +94,7 @@ func (a *API) writeLog(c *gin.Context, l *collector.Log) {
 				logC = nil
 				continue
 			}
-			if l.Log == "EOFLOG" {
-				_, _ = c.Writer.Write([]byte("\n"))
-				continue
-			}
-			_, _ = c.Writer.Write([]byte(fmt.Sprintf("[%s : %s] %s\n", l.Task, l.Step, l.Log)))
+			_, _ = c.Writer.Write([]byte(collector.FormatLog(l)))
 		case e, ok := <-errC:
 			if !ok {
 				errC = nil
@@ -94,6 +105,77 @@ func (a *API) writeLog(c *gin.Context, l *collector.Log) {
 	}
 }
 
+// LogStream streams the log of the pipelinerun as server-sent events. The id of each log event is
+// the offset of the line, the stream is resumed from the query offset or the line after Last-Event-ID
+func (a *API) LogStream(c *gin.Context) {
+	prID, err := strconv.ParseUint(c.Param(_pipelinerunIDParam), 10, 0)
+	if err != nil {
+		response.AbortWithRequestError(c, common.InvalidRequestParam, err.Error())
+		return
+	}
+	offset := 0
+	if offsetStr := c.Query(_offsetParam); offsetStr != "" {
+		if offset, err = strconv.Atoi(offsetStr); err != nil || offset < 0 {
+			response.AbortWithRequestError(c, common.InvalidRequestParam,
+				fmt.Sprintf("invalid offset: %s", offsetStr))
+			return
+		}
+	}
+	if lastEventID := c.GetHeader(_lastEventIDHeader); lastEventID != "" {
+		if lastOffset, err := strconv.Atoi(lastEventID); err == nil && lastOffset >= 0 {
+			offset = lastOffset + 1
+		}
+	}
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+
+	ctx := &streamContext{Context: c.Request.Context(), c: c}
+	result, err := a.prCtl.StreamPipelinerunLog(ctx, uint(prID), offset, func(line *collector.LogLine) error {
+		return writeEvent(c, strconv.Itoa(line.Offset), _eventLog, line)
+	})
+	if err != nil {
+		if ctx.Err() == nil {
+			_ = writeEvent(c, "", _eventError, errors.Message(err))
+		}
+		return
+	}
+	_ = writeEvent(c, "", _eventEnd, result)
+}
+
+func writeEvent(c *gin.Context, id, event string, data interface{}) error {
+	bts, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	if id != "" {
+		if _, err := fmt.Fprintf(c.Writer, "id: %s\n", id); err != nil {
+			return err
+		}
+	}
+	if _, err := fmt.Fprintf(c.Writer, "event: %s\ndata: %s\n\n", event, bts); err != nil {
+		return err
+	}
+	c.Writer.Flush()
+	return nil
+}
+
+// streamContext is done when the client disconnects, and keeps the values of the gin context
+type streamContext struct {
+	context.Context
+	c *gin.Context
+}
+
+func (s *streamContext) Value(key interface{}) interface{} {
+	if v := s.c.Value(key); v != nil {
+		return v
+	}
+	return s.Context.Value(key)
+}
+
 func (a *API) GetDiff(c *gin.Context) {
 	pipelinerunIDStr := c.Param(_pipelinerunIDParam)
 	pipelinerunID, err := strconv.ParseUint(pipelinerunIDStr, 10, 0)
@@ -172,3 +254,60 @@ func (a *API) Stop(c *gin.Context) {
 	}
 	response.Success(c)
 }
+
+func (a *API) ListReports(c *gin.Context) {
+	pipelinerunIDStr := c.Param(_pipelinerunIDParam)
+	pipelinerunID, err := strconv.ParseUint(pipelinerunIDStr, 10, 0)
+	if err != nil {
+		response.AbortWithRequestError(c, common.InvalidRequestParam, err.Error())
+		return
+	}
+	reports, err := a.prCtl.ListReports(c, uint(pipelinerunID))
+	if err != nil {
+		response.AbortWithError(c, err)
+		return
+	}
+	response.SuccessWithData(c, reports)
+}
+
+func (a *API) GetProvenance(c *gin.Context) {
+	pipelinerunIDStr := c.Param(_pipelinerunIDParam)
+	pipelinerunID, err := strconv.ParseUint(pipelinerunIDStr, 10, 0)
+	if err != nil {
+		response.AbortWithRequestError(c, common.InvalidRequestParam, err.Error())
+		return
+	}
+	provenance, err := a.prCtl.GetProvenance(c, uint(pipelinerunID))
+	if err != nil {
+		response.AbortWithError(c, err)
+		return
+	}
+	response.SuccessWithData(c, provenance)
+}
+
+func (a *API) GetReportFile(c *gin.Context) {
+	pipelinerunIDStr := c.Param(_pipelinerunIDParam)
+	pipelinerunID, err := strconv.ParseUint(pipelinerunIDStr, 10, 0)
+	if err != nil {
+		response.AbortWithRequestError(c, common.InvalidRequestParam, err.Error())
+		return
+	}
+	reportIDStr := c.Param(_reportIDParam)
+	reportID, err := strconv.ParseUint(reportIDStr, 10, 0)
+	if err != nil {
+		response.AbortWithRequestError(c, common.InvalidRequestParam, err.Error())
+		return
+	}
+	report, content, err := a.prCtl.GetReportFile(c, uint(pipelinerunID), uint(reportID))
+	if err != nil {
+		response.AbortWithError(c, err)
+		return
+	}
+
+	contentType, ext := "application/json", "json"
+	if report.Format == reportmodels.FormatJUnit || report.Format == reportmodels.FormatCobertura {
+		contentType, ext = "application/xml", "xml"
+	}
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%s-%s.%s", report.Task, report.Name, ext))
+	c.Data(http.StatusOK, contentType, content)
+}
@@ -31,6 +31,22 @@ func (api *API) RegisterRoute(engine *gin.Engine) {
 			Method:      http.MethodGet,
 			Pattern:     fmt.Sprintf("/pipelineruns/:%v/log", _pipelinerunIDParam),
 			HandlerFunc: api.Log,
+		}, {
+			Method:      http.MethodGet,
+			Pattern:     fmt.Sprintf("/pipelineruns/:%v/logstream", _pipelinerunIDParam),
+			HandlerFunc: api.LogStream,
+		}, {
+			Method:      http.MethodGet,
+			Pattern:     fmt.Sprintf("/pipelineruns/:%v/reports", _pipelinerunIDParam),
+			HandlerFunc: api.ListReports,
+		}, {
+			Method:      http.MethodGet,
+			Pattern:     fmt.Sprintf("/pipelineruns/:%v/reports/:%v", _pipelinerunIDParam, _reportIDParam),
+			HandlerFunc: api.GetReportFile,
+		}, {
+			Method:      http.MethodGet,
+			Pattern:     fmt.Sprintf("/pipelineruns/:%v/provenance", _pipelinerunIDParam),
+			HandlerFunc: api.GetProvenance,
 		}, {
 			Method:      http.MethodPost,
 			Pattern:     fmt.Sprintf("/pipelineruns/:%v/stop", _pipelinerunIDParam),
@@ -0,0 +1,144 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package releaseplan
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/horizoncd/horizon/core/common"
+	"github.com/horizoncd/horizon/core/controller/releaseplan"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/lib/q"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/server/response"
+	"github.com/horizoncd/horizon/pkg/server/rpcerror"
+	"github.com/horizoncd/horizon/pkg/util/log"
+)
+
+type API struct {
+	releasePlanCtl releaseplan.Controller
+}
+
+func NewAPI(ctl releaseplan.Controller) *API {
+	return &API{
+		releasePlanCtl: ctl,
+	}
+}
+
+func (a *API) Create(c *gin.Context) {
+	const op = "release plan: create"
+	applicationIDStr := c.Param(common.ParamApplicationID)
+	applicationID, err := strconv.ParseUint(applicationIDStr, 10, 0)
+	if err != nil {
+		response.AbortWithRPCError(c, rpcerror.ParamError.
+			WithErrMsgf("invalid application id: %s", applicationIDStr))
+		return
+	}
+
+	var request releaseplan.CreateReleasePlanRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		response.AbortWithRPCError(c, rpcerror.ParamError.
+			WithErrMsgf("invalid request body, err: %s", err.Error()))
+		return
+	}
+
+	resp, err := a.releasePlanCtl.CreateReleasePlan(c, uint(applicationID), &request)
+	if err != nil {
+		abortWithError(c, op, err)
+		return
+	}
+	response.SuccessWithData(c, resp)
+}
+
+func (a *API) List(c *gin.Context) {
+	const op = "release plan: list"
+	applicationIDStr := c.Param(common.ParamApplicationID)
+	applicationID, err := strconv.ParseUint(applicationIDStr, 10, 0)
+	if err != nil {
+		response.AbortWithRPCError(c, rpcerror.ParamError.
+			WithErrMsgf("invalid application id: %s", applicationIDStr))
+		return
+	}
+
+	query := q.New(nil).WithPagination(c)
+	items, total, err := a.releasePlanCtl.ListReleasePlans(c, uint(applicationID), query)
+	if err != nil {
+		abortWithError(c, op, err)
+		return
+	}
+	response.SuccessWithData(c, response.DataWithTotal{
+		Items: items,
+		Total: total,
+	})
+}
+
+func (a *API) Get(c *gin.Context) {
+	a.handle(c, "release plan: get", a.releasePlanCtl.GetReleasePlan)
+}
+
+func (a *API) Start(c *gin.Context) {
+	a.handle(c, "release plan: start", a.releasePlanCtl.StartReleasePlan)
+}
+
+func (a *API) Pause(c *gin.Context) {
+	a.handle(c, "release plan: pause", a.releasePlanCtl.PauseReleasePlan)
+}
+
+func (a *API) Resume(c *gin.Context) {
+	a.handle(c, "release plan: resume", a.releasePlanCtl.ResumeReleasePlan)
+}
+
+func (a *API) Abort(c *gin.Context) {
+	a.handle(c, "release plan: abort", a.releasePlanCtl.AbortReleasePlan)
+}
+
+// handle serves the requests on a single release plan
+func (a *API) handle(c *gin.Context, op string,
+	f func(ctx context.Context, id uint) (*releaseplan.ReleasePlan, error)) {
+	idStr := c.Param(_releasePlanIDParam)
+	id, err := strconv.ParseUint(idStr, 10, 0)
+	if err != nil {
+		response.AbortWithRPCError(c, rpcerror.ParamError.
+			WithErrMsgf("invalid id: %s", idStr))
+		return
+	}
+
+	resp, err := f(c, uint(id))
+	if err != nil {
+		abortWithError(c, op, err)
+		return
+	}
+	response.SuccessWithData(c, resp)
+}
+
+func abortWithError(c *gin.Context, op string, err error) {
+	if _, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); ok {
+		response.AbortWithRPCError(c, rpcerror.NotFoundError.WithErrMsg(err.Error()))
+		return
+	}
+	switch perror.Cause(err) {
+	case herrors.ErrParamInvalid:
+		response.AbortWithRPCError(c, rpcerror.ParamError.WithErrMsg(err.Error()))
+		return
+	case herrors.ErrReleasePlanStatusInvalid, herrors.ErrReleasePlanConflict:
+		response.AbortWithRPCError(c, rpcerror.BadRequestError.WithErrMsg(err.Error()))
+		return
+	}
+	log.WithFiled(c, "op", op).Errorf("%+v", err)
+	response.AbortWithRPCError(c, rpcerror.InternalError.WithErrMsg(err.Error()))
+}
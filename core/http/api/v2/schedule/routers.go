@@ -0,0 +1,71 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schedule
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/horizoncd/horizon/core/common"
+	"github.com/horizoncd/horizon/pkg/server/route"
+)
+
+const (
+	_scheduleIDParam = "scheduleID"
+)
+
+func (api *API) RegisterRoute(engine *gin.Engine) {
+	group := engine.Group("/apis/core/v2")
+	var routers = route.Routes{
+		{
+			Method:      http.MethodPost,
+			Pattern:     fmt.Sprintf("/clusters/:%v/schedules", common.ParamClusterID),
+			HandlerFunc: api.Create,
+		},
+		{
+			Method:      http.MethodGet,
+			Pattern:     fmt.Sprintf("/clusters/:%v/schedules", common.ParamClusterID),
+			HandlerFunc: api.List,
+		},
+		{
+			Method:      http.MethodGet,
+			Pattern:     fmt.Sprintf("/schedules/:%v", _scheduleIDParam),
+			HandlerFunc: api.Get,
+		},
+		{
+			Method:      http.MethodPut,
+			Pattern:     fmt.Sprintf("/schedules/:%v", _scheduleIDParam),
+			HandlerFunc: api.Update,
+		},
+		{
+			Method:      http.MethodDelete,
+			Pattern:     fmt.Sprintf("/schedules/:%v", _scheduleIDParam),
+			HandlerFunc: api.Delete,
+		},
+		{
+			Method:      http.MethodPost,
+			Pattern:     fmt.Sprintf("/schedules/:%v/pause", _scheduleIDParam),
+			HandlerFunc: api.Pause,
+		},
+		{
+			Method:      http.MethodPost,
+			Pattern:     fmt.Sprintf("/schedules/:%v/resume", _scheduleIDParam),
+			HandlerFunc: api.Resume,
+		},
+	}
+	route.RegisterRoutes(group, routers)
+}
@@ -16,6 +16,7 @@ package webhook
 
 import (
 	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 
@@ -276,3 +277,103 @@ func (a *API) ResendWebhook(c *gin.Context) {
 	}
 	response.SuccessWithData(c, resp)
 }
+
+func (a *API) RedeliverWebhookLog(c *gin.Context) {
+	const op = "webhook: redeliver"
+	idStr := c.Param(_webhookLogIDParam)
+	id, err := strconv.ParseUint(idStr, 10, 0)
+	if err != nil {
+		response.AbortWithRPCError(c, rpcerror.ParamError.
+			WithErrMsgf("invalid id: %s", idStr))
+		return
+	}
+
+	resp, err := a.webhookCtl.RedeliverWebhookLog(c, uint(id))
+	if err != nil {
+		if perror.Cause(err) == herrors.ErrParamInvalid {
+			response.AbortWithRPCError(c, rpcerror.ParamError.WithErrMsg(err.Error()))
+			return
+		} else if _, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); ok {
+			response.AbortWithRPCError(c, rpcerror.NotFoundError.WithErrMsg(err.Error()))
+			return
+		}
+		log.WithFiled(c, "op", op).Errorf("%+v", err)
+		response.AbortWithRPCError(c, rpcerror.InternalError.WithErrMsg(err.Error()))
+		return
+	}
+	response.SuccessWithData(c, resp)
+}
+
+// RedeliverWebhookLogs redelivers the failed logs of the webhook created between start and end in RFC3339
+func (a *API) RedeliverWebhookLogs(c *gin.Context) {
+	const op = "webhook: redeliver logs"
+	idStr := c.Param(_webhookIDParam)
+	id, err := strconv.ParseUint(idStr, 10, 0)
+	if err != nil {
+		response.AbortWithRPCError(c, rpcerror.ParamError.
+			WithErrMsgf("invalid id: %s", idStr))
+		return
+	}
+
+	var start, end time.Time
+	for key, t := range map[string]*time.Time{_startQuery: &start, _endQuery: &end} {
+		value := c.Query(key)
+		if value == "" {
+			continue
+		}
+		if *t, err = time.Parse(time.RFC3339, value); err != nil {
+			response.AbortWithRPCError(c, rpcerror.ParamError.
+				WithErrMsgf("invalid %s: %s, it should be in RFC3339", key, value))
+			return
+		}
+	}
+
+	resp, err := a.webhookCtl.RedeliverWebhookLogs(c, uint(id), start, end)
+	if err != nil {
+		if perror.Cause(err) == herrors.ErrParamInvalid {
+			response.AbortWithRPCError(c, rpcerror.ParamError.WithErrMsg(err.Error()))
+			return
+		} else if _, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); ok {
+			response.AbortWithRPCError(c, rpcerror.NotFoundError.WithErrMsg(err.Error()))
+			return
+		}
+		log.WithFiled(c, "op", op).Errorf("%+v", err)
+		response.AbortWithRPCError(c, rpcerror.InternalError.WithErrMsg(err.Error()))
+		return
+	}
+	response.SuccessWithData(c, resp)
+}
+
+// RotateWebhookSecret rotates the secret, the old secret keeps signing requests during the overlap
+func (a *API) RotateWebhookSecret(c *gin.Context) {
+	const op = "webhook: rotate secret"
+	idStr := c.Param(_webhookIDParam)
+	id, err := strconv.ParseUint(idStr, 10, 0)
+	if err != nil {
+		response.AbortWithRPCError(c, rpcerror.ParamError.
+			WithErrMsgf("invalid id: %s", idStr))
+		return
+	}
+
+	var req webhook.RotateSecretRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.AbortWithRPCError(c, rpcerror.ParamError.
+			WithErrMsgf("invalid request body, err: %s", err.Error()))
+		return
+	}
+
+	resp, err := a.webhookCtl.RotateWebhookSecret(c, uint(id), &req)
+	if err != nil {
+		if perror.Cause(err) == herrors.ErrParamInvalid {
+			response.AbortWithRPCError(c, rpcerror.ParamError.WithErrMsg(err.Error()))
+			return
+		} else if _, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); ok {
+			response.AbortWithRPCError(c, rpcerror.NotFoundError.WithErrMsg(err.Error()))
+			return
+		}
+		log.WithFiled(c, "op", op).Errorf("%+v", err)
+		response.AbortWithRPCError(c, rpcerror.InternalError.WithErrMsg(err.Error()))
+		return
+	}
+	response.SuccessWithData(c, resp)
+}
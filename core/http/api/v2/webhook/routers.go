@@ -28,6 +28,9 @@ const (
 	_resourceIDParam   = "resourceID"
 	_webhookIDParam    = "webhookID"
 	_webhookLogIDParam = "webhookLogID"
+
+	_startQuery = "start"
+	_endQuery   = "end"
 )
 
 func (api *API) RegisterRoute(engine *gin.Engine) {
@@ -73,6 +76,52 @@ func (api *API) RegisterRoute(engine *gin.Engine) {
 			Pattern:     fmt.Sprintf("/webhooklogs/:%v/resend", _webhookLogIDParam),
 			HandlerFunc: api.ResendWebhook,
 		},
+		{
+			Method:      http.MethodPost,
+			Pattern:     fmt.Sprintf("/webhooklogs/:%v/redeliver", _webhookLogIDParam),
+			HandlerFunc: api.RedeliverWebhookLog,
+		},
+		{
+			Method:      http.MethodPost,
+			Pattern:     fmt.Sprintf("/webhooks/:%v/redeliver", _webhookIDParam),
+			HandlerFunc: api.RedeliverWebhookLogs,
+		},
+		{
+			Method:      http.MethodPost,
+			Pattern:     fmt.Sprintf("/webhooks/:%v/rotatesecret", _webhookIDParam),
+			HandlerFunc: api.RotateWebhookSecret,
+		},
+		{
+			Method:      http.MethodPost,
+			Pattern:     fmt.Sprintf("/:%v/:%v/notificationchannels", _resourceTypeParam, _resourceIDParam),
+			HandlerFunc: api.CreateNotificationChannel,
+		},
+		{
+			Method:      http.MethodGet,
+			Pattern:     fmt.Sprintf("/:%v/:%v/notificationchannels", _resourceTypeParam, _resourceIDParam),
+			HandlerFunc: api.ListNotificationChannels,
+		},
+		{
+			Method:      http.MethodGet,
+			Pattern:     fmt.Sprintf("/notificationchannels/:%v", _webhookIDParam),
+			HandlerFunc: api.GetNotificationChannel,
+		},
+		{
+			Method:      http.MethodPut,
+			Pattern:     fmt.Sprintf("/notificationchannels/:%v", _webhookIDParam),
+			HandlerFunc: api.UpdateNotificationChannel,
+		},
+		{
+			Method:      http.MethodDelete,
+			Pattern:     fmt.Sprintf("/notificationchannels/:%v", _webhookIDParam),
+			HandlerFunc: api.DeleteNotificationChannel,
+		},
+		{
+			// the logs of notification channels are webhook logs
+			Method:      http.MethodGet,
+			Pattern:     fmt.Sprintf("/notificationchannels/:%v/logs", _webhookIDParam),
+			HandlerFunc: api.ListWebhookLogs,
+		},
 	}
 	route.RegisterRoutes(group, routers)
 }
@@ -15,17 +15,24 @@
 package cloudevent
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/horizoncd/horizon/core/common"
 	"github.com/horizoncd/horizon/core/controller/cloudevent"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/pkg/cluster/ci"
+	perror "github.com/horizoncd/horizon/pkg/errors"
 	"github.com/horizoncd/horizon/pkg/server/response"
+	"github.com/horizoncd/horizon/pkg/server/rpcerror"
 	"github.com/horizoncd/horizon/pkg/util/log"
 
 	"github.com/gin-gonic/gin"
 	pipelinecloudevent "github.com/tektoncd/pipeline/pkg/reconciler/events/cloudevent"
 )
 
+const JWTTokenHeader = "X-Horizon-JWT-Token"
+
 type API struct {
 	cloudEventCtl cloudevent.Controller
 }
@@ -60,3 +67,46 @@ func (a *API) CloudEvent(c *gin.Context) {
 
 	response.Success(c)
 }
+
+// ExternalCallback receives the progress and the result of pipelines run by external ci engines
+func (a *API) ExternalCallback(c *gin.Context) {
+	op := "cloudEvent: external callback"
+	var callback *ci.ExternalCallback
+	if err := c.ShouldBindJSON(&callback); err != nil {
+		response.AbortWithRequestError(c, common.InvalidRequestBody,
+			fmt.Sprintf("request body is invalid, err: %v", err))
+		return
+	}
+
+	tokenString := c.Request.Header.Get(JWTTokenHeader)
+	if tokenString == "" {
+		response.AbortWithUnauthorized(c, common.Unauthorized,
+			"jwt token is empty!")
+		return
+	}
+	var ctx context.Context = c
+	ctx = common.WithContextJWTTokenString(ctx, tokenString)
+
+	if err := a.cloudEventCtl.ExternalCallback(ctx, callback); err != nil {
+		if _, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); ok {
+			response.AbortWithRPCError(c, rpcerror.NotFoundError.WithErrMsg(err.Error()))
+			return
+		}
+		switch perror.Cause(err) {
+		case herrors.ErrTokenInvalid:
+			response.AbortWithUnauthorized(c, common.Unauthorized, err.Error())
+			return
+		case herrors.ErrForbidden:
+			response.AbortWithRPCError(c, rpcerror.ForbiddenError.WithErrMsg(err.Error()))
+			return
+		case herrors.ErrParamInvalid:
+			response.AbortWithRPCError(c, rpcerror.ParamError.WithErrMsg(err.Error()))
+			return
+		}
+		log.WithFiled(c, "op", op).Errorf("%+v", err)
+		response.AbortWithRPCError(c, rpcerror.InternalError.WithErrMsg(err.Error()))
+		return
+	}
+
+	response.Success(c)
+}
@@ -29,6 +29,11 @@ func RegisterRoutes(engine *gin.Engine, api *API) {
 			Pattern:     "/cloudevents",
 			HandlerFunc: api.CloudEvent,
 		},
+		{
+			Method:      http.MethodPost,
+			Pattern:     "/cicallbacks",
+			HandlerFunc: api.ExternalCallback,
+		},
 	}
 	route.RegisterRoutes(group, routes)
 }
@@ -29,6 +29,7 @@ import (
 
 	// for template repo
 	_ "github.com/horizoncd/horizon/pkg/templaterepo/chartmuseumbase"
+	_ "github.com/horizoncd/horizon/pkg/templaterepo/oci"
 
 	// for k8s workload
 	_ "github.com/horizoncd/horizon/pkg/workload/deployment"
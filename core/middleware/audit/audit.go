@@ -0,0 +1,112 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audit
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/horizoncd/horizon/core/common"
+	"github.com/horizoncd/horizon/core/middleware"
+	"github.com/horizoncd/horizon/core/middleware/requestid"
+	"github.com/horizoncd/horizon/pkg/audit"
+	auditmodels "github.com/horizoncd/horizon/pkg/audit/models"
+	"github.com/horizoncd/horizon/pkg/auth"
+	"github.com/horizoncd/horizon/pkg/param"
+	tokenmodels "github.com/horizoncd/horizon/pkg/token/models"
+	usermodels "github.com/horizoncd/horizon/pkg/user/models"
+	"github.com/horizoncd/horizon/pkg/util/log"
+)
+
+// Middleware records an audit log for every mutating request after it's handled,
+// it should be used before the authentication middlewares to audit the rejected requests as well
+func Middleware(param *param.Param, skippers ...middleware.Skipper) gin.HandlerFunc {
+	return middleware.New(func(c *gin.Context) {
+		switch c.Request.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			c.Next()
+			return
+		}
+
+		annotations := audit.NewAnnotations()
+		c.Set(audit.ContextAnnotations, annotations)
+		c.Next()
+
+		auditLog := &auditmodels.AuditLog{
+			ActorType:  auditmodels.ActorAnonymous,
+			Method:     c.Request.Method,
+			Path:       c.Request.URL.Path,
+			Decision:   auditmodels.DecisionNotChecked,
+			StatusCode: c.Writer.Status(),
+			SourceIP:   c.ClientIP(),
+			UserAgent:  c.Request.UserAgent(),
+		}
+		auditLog.ReqID, _ = requestid.FromContext(c)
+		auditLog.SetAnnotations(annotations.Values())
+		setActor(c, param, auditLog)
+		if record, ok := c.Get(common.ContextAuthRecord); ok {
+			authRecord := record.(auth.AttributesRecord)
+			auditLog.Verb = authRecord.Verb
+			auditLog.Resource = authRecord.Resource
+			auditLog.ResourceName = authRecord.Name
+			auditLog.SubResource = authRecord.SubResource
+		}
+		if decision, ok := c.Get(common.ContextAuthDecision); ok {
+			auditLog.Decision = auditmodels.DecisionDeny
+			if decision.(auth.Decision) == auth.DecisionAllow {
+				auditLog.Decision = auditmodels.DecisionAllow
+			}
+			auditLog.Reason = c.GetString(common.ContextAuthReason)
+		}
+
+		if err := param.AuditLogMgr.Create(c, auditLog); err != nil {
+			log.Errorf(c, "failed to create audit log of %s %s, err: %s",
+				auditLog.Method, auditLog.Path, err.Error())
+		}
+	}, skippers...)
+}
+
+// setActor tells who sent the request, the user of an access token might be
+// the robot of a resource access token or an oauth app acting on behalf of the user
+func setActor(c *gin.Context, param *param.Param, auditLog *auditmodels.AuditLog) {
+	currentUser, err := common.UserFromContext(c)
+	if err != nil {
+		return
+	}
+	auditLog.ActorType = auditmodels.ActorUser
+	auditLog.ActorID = currentUser.GetID()
+	auditLog.ActorName = currentUser.GetName()
+
+	value, ok := c.Get(common.ContextAccessToken)
+	if !ok {
+		return
+	}
+	token := value.(*tokenmodels.Token)
+	auditLog.TokenID = token.ID
+	if token.ClientID != "" {
+		auditLog.ActorType = auditmodels.ActorOAuthApp
+		auditLog.ClientID = token.ClientID
+		return
+	}
+	user, err := param.UserManager.GetUserByID(c, currentUser.GetID())
+	if err != nil {
+		log.Warningf(c, "failed to get user %d, err: %s", currentUser.GetID(), err.Error())
+		return
+	}
+	if user.UserType == usermodels.UserTypeRobot {
+		auditLog.ActorType = auditmodels.ActorRobot
+	}
+}
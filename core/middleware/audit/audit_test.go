@@ -0,0 +1,83 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audit
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/horizoncd/horizon/core/common"
+	"github.com/horizoncd/horizon/lib/orm"
+	"github.com/horizoncd/horizon/lib/q"
+	"github.com/horizoncd/horizon/pkg/audit"
+	auditmodels "github.com/horizoncd/horizon/pkg/audit/models"
+	"github.com/horizoncd/horizon/pkg/auth"
+	userauth "github.com/horizoncd/horizon/pkg/authentication/user"
+	"github.com/horizoncd/horizon/pkg/param"
+	"github.com/horizoncd/horizon/pkg/param/managerparam"
+	usermodels "github.com/horizoncd/horizon/pkg/user/models"
+)
+
+func TestMiddleware(t *testing.T) {
+	db, _ := orm.NewSqliteDB("")
+	if err := db.AutoMigrate(&auditmodels.AuditLog{}, &usermodels.User{}); err != nil {
+		panic(err)
+	}
+	mgr := managerparam.InitManager(db)
+
+	r := gin.New()
+	r.Use(Middleware(&param.Param{Manager: mgr}))
+	handler := func(c *gin.Context) {
+		common.SetUser(c, &userauth.DefaultInfo{Name: "Tony", ID: 1})
+		c.Set(common.ContextAuthRecord, auth.AttributesRecord{
+			Verb:     "delete",
+			Resource: common.ResourceCluster,
+			Name:     "1",
+		})
+		c.Set(common.ContextAuthDecision, auth.DecisionDeny)
+		c.Set(common.ContextAuthReason, "not member")
+		audit.Annotate(c, "eventIDs", "3")
+		c.Status(http.StatusForbidden)
+	}
+	r.GET("/apis/core/v2/clusters/:id", handler)
+	r.DELETE("/apis/core/v2/clusters/:id", handler)
+
+	for _, method := range []string{http.MethodGet, http.MethodDelete} {
+		req := httptest.NewRequest(method, "/apis/core/v2/clusters/1", nil)
+		req.Header.Set("User-Agent", "curl/7.0")
+		r.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	// only the mutating request is recorded
+	logs, total, err := mgr.AuditLogMgr.List(context.TODO(), q.New(nil))
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1), total)
+	l := logs[0]
+	assert.Equal(t, auditmodels.ActorUser, l.ActorType)
+	assert.Equal(t, "Tony", l.ActorName)
+	assert.Equal(t, http.MethodDelete, l.Method)
+	assert.Equal(t, common.ResourceCluster, l.Resource)
+	assert.Equal(t, "1", l.ResourceName)
+	assert.Equal(t, auditmodels.DecisionDeny, l.Decision)
+	assert.Equal(t, "not member", l.Reason)
+	assert.Equal(t, http.StatusForbidden, l.StatusCode)
+	assert.Equal(t, "curl/7.0", l.UserAgent)
+	assert.Equal(t, []string{"3"}, l.GetAnnotations()["eventIDs"])
+}
@@ -52,6 +52,9 @@ func Middleware(authorizer rbac.Authorizer, skipMatchers ...middleware.Skipper)
 		}
 
 		decision, reason, err := authorizer.Authorize(c, authRecord)
+		// the decision and reason are recorded in the audit log
+		c.Set(common.ContextAuthDecision, decision)
+		c.Set(common.ContextAuthReason, reason)
 		if err != nil {
 			log.Warningf(c, "auth failed with err = %s", err.Error())
 			if _, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); ok {
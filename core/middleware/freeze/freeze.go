@@ -0,0 +1,181 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package freeze
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/core/middleware"
+	"github.com/horizoncd/horizon/pkg/auth"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	eventmodels "github.com/horizoncd/horizon/pkg/event/models"
+	"github.com/horizoncd/horizon/pkg/freeze/models"
+	"github.com/horizoncd/horizon/pkg/param"
+	"github.com/horizoncd/horizon/pkg/rbac"
+	"github.com/horizoncd/horizon/pkg/rbac/types"
+	"github.com/horizoncd/horizon/pkg/server/response"
+	"github.com/horizoncd/horizon/pkg/server/rpcerror"
+	"github.com/horizoncd/horizon/pkg/util/log"
+)
+
+const (
+	subResourceFavorite = "favorite"
+	subResourceApprove  = "approve"
+)
+
+type overrideExtra struct {
+	FreezeWindowID uint      `json:"freezeWindowID"`
+	Name           string    `json:"name"`
+	ActiveUntil    time.Time `json:"activeUntil"`
+	Method         string    `json:"method"`
+	Path           string    `json:"path"`
+}
+
+// Middleware rejects mutating requests to clusters inside an active freeze window,
+// unless the user is granted the freeze-override verb on the cluster
+func Middleware(authorizer rbac.Authorizer, param *param.Param, skippers ...middleware.Skipper) gin.HandlerFunc {
+	return middleware.New(func(c *gin.Context) {
+		record, ok := c.Get(common.ContextAuthRecord)
+		if !ok {
+			c.Next()
+			return
+		}
+		authRecord := record.(auth.AttributesRecord)
+		if authRecord.IsReadOnly() || authRecord.Name == "" {
+			c.Next()
+			return
+		}
+
+		clusterID, ok, err := getClusterID(c, param, authRecord)
+		if err != nil {
+			abortWithError(c, err)
+			return
+		}
+		if !ok {
+			c.Next()
+			return
+		}
+
+		window, until, err := param.FreezeSvc.GetActiveWindow(c, clusterID)
+		if err != nil {
+			abortWithError(c, err)
+			return
+		}
+		if window == nil {
+			c.Next()
+			return
+		}
+
+		currentUser, err := common.UserFromContext(c)
+		if err != nil {
+			response.AbortWithForbiddenError(c, common.Forbidden, err.Error())
+			return
+		}
+		overrideRecord := authRecord
+		overrideRecord.User = currentUser
+		overrideRecord.Verb = types.VerbFreezeOverride
+		overrideRecord.Resource = common.ResourceCluster
+		overrideRecord.SubResource = ""
+		overrideRecord.Name = strconv.FormatUint(uint64(clusterID), 10)
+		decision, reason, err := authorizer.Authorize(c, overrideRecord)
+		if err != nil {
+			abortWithError(c, err)
+			return
+		}
+		if decision != auth.DecisionAllow {
+			log.Warningf(c, "denied request in freeze window %d with reason = %s", window.ID, reason)
+			response.AbortWithForbiddenError(c, common.Forbidden,
+				fmt.Sprintf("cluster is frozen by freeze window %s until %s",
+					window.Name, until.Format(time.RFC3339)))
+			return
+		}
+
+		log.Infof(c, "freeze window %d is overridden by user %s", window.ID, currentUser.GetName())
+		recordOverrideEvent(c, param, clusterID, window, until)
+		c.Next()
+	}, skippers...)
+}
+
+// getClusterID returns the cluster which the request is going to mutate
+func getClusterID(c *gin.Context, param *param.Param, record auth.AttributesRecord) (uint, bool, error) {
+	if record.APIGroup != common.GroupCore {
+		return 0, false, nil
+	}
+	switch record.Resource {
+	case common.ResourceCluster:
+		if record.SubResource == subResourceFavorite {
+			return 0, false, nil
+		}
+		id, err := strconv.ParseUint(record.Name, 10, 0)
+		if err != nil {
+			return 0, false, nil
+		}
+		return uint(id), true, nil
+	case common.ResourcePipelinerun:
+		// an approved pipelinerun is executed right away
+		if record.SubResource != subResourceApprove {
+			return 0, false, nil
+		}
+		id, err := strconv.ParseUint(record.Name, 10, 0)
+		if err != nil {
+			return 0, false, nil
+		}
+		pr, err := param.PipelinerunMgr.GetByID(c, uint(id))
+		if err != nil {
+			return 0, false, err
+		}
+		return pr.ClusterID, true, nil
+	}
+	return 0, false, nil
+}
+
+func recordOverrideEvent(c *gin.Context, param *param.Param, clusterID uint,
+	window *models.FreezeWindow, until time.Time) {
+	extraBytes, err := json.Marshal(overrideExtra{
+		FreezeWindowID: window.ID,
+		Name:           window.Name,
+		ActiveUntil:    until,
+		Method:         c.Request.Method,
+		Path:           c.Request.URL.Path,
+	})
+	if err != nil {
+		log.Warningf(c, "failed to marshal event extra: %v", err.Error())
+	}
+	extra := string(extraBytes)
+	if _, err := param.EventManager.CreateEvent(c, &eventmodels.Event{
+		EventSummary: eventmodels.EventSummary{
+			ResourceType: common.ResourceCluster,
+			EventType:    eventmodels.ClusterFreezeOverride,
+			ResourceID:   clusterID,
+			Extra:        &extra,
+		},
+	}); err != nil {
+		log.Warningf(c, "failed to create event, err: %s", err.Error())
+	}
+}
+
+func abortWithError(c *gin.Context, err error) {
+	if _, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); ok {
+		response.AbortWithRPCError(c, rpcerror.NotFoundError.WithErrMsg(err.Error()))
+		return
+	}
+	response.AbortWithRPCError(c, rpcerror.InternalError.WithErrMsg(err.Error()))
+}
@@ -74,6 +74,9 @@ func MiddleWare(oauthCtl oauthcheck.Controller, skipMatchers ...middleware.Skipp
 			return
 		}
 		common.SetUser(c, user)
+		if accessToken, err := oauthCtl.LoadAccessToken(c, token); err == nil {
+			c.Set(common.ContextAccessToken, accessToken)
+		}
 
 		requestInfo, err := RequestInfoFty.NewRequestInfo(c.Request)
 		if err != nil {
@@ -89,6 +92,8 @@ func MiddleWare(oauthCtl oauthcheck.Controller, skipMatchers ...middleware.Skipp
 		}
 		if !result {
 			log.WithFiled(c, CheckResult, result).Warningf("reason = %s", reason)
+			c.Set(common.ContextAuthDecision, auth.DecisionDeny)
+			c.Set(common.ContextAuthReason, reason)
 			response.AbortWithForbiddenError(c, common.Forbidden, "")
 			return
 		}
@@ -0,0 +1,33 @@
+-- Copyright © 2023 Horizoncd.
+--
+-- Licensed under the Apache License, Version 2.0 (the "License");
+-- you may not use this file except in compliance with the License.
+-- You may obtain a copy of the License at
+--
+--     http://www.apache.org/licenses/LICENSE-2.0
+--
+-- Unless required by applicable law or agreed to in writing, software
+-- distributed under the License is distributed on an "AS IS" BASIS,
+-- WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+-- See the License for the specific language governing permissions and
+-- limitations under the License.
+
+-- promotion path table
+CREATE TABLE `tb_promotion_path`
+(
+    `id`             bigint(20) unsigned NOT NULL AUTO_INCREMENT,
+    `application_id` bigint(20) unsigned NOT NULL COMMENT 'application id',
+    `environments`   varchar(512)        NOT NULL DEFAULT '' COMMENT 'environments in promotion order, separated by comma',
+    `created_at`     datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `updated_at`     datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    `created_by`     bigint(20) unsigned NOT NULL DEFAULT 0 COMMENT 'creator',
+    `updated_by`     bigint(20) unsigned NOT NULL DEFAULT 0 COMMENT 'updater',
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `idx_application_id` (`application_id`)
+) ENGINE = InnoDB
+  AUTO_INCREMENT = 1
+  DEFAULT CHARSET = utf8mb4;
+
+ALTER TABLE tb_pipelinerun
+ADD COLUMN `promote_from` bigint(20) unsigned NULL
+COMMENT 'the pipelinerun id of the source cluster that this pipelinerun promoted from';
@@ -0,0 +1,26 @@
+-- Copyright © 2023 Horizoncd.
+--
+-- Licensed under the Apache License, Version 2.0 (the "License");
+-- you may not use this file except in compliance with the License.
+-- You may obtain a copy of the License at
+--
+--     http://www.apache.org/licenses/LICENSE-2.0
+--
+-- Unless required by applicable law or agreed to in writing, software
+-- distributed under the License is distributed on an "AS IS" BASIS,
+-- WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+-- See the License for the specific language governing permissions and
+-- limitations under the License.
+
+ALTER TABLE tb_environment
+ADD COLUMN `protected` tinyint(1) NOT NULL DEFAULT 0
+COMMENT 'whether operations on clusters in this environment need to be approved',
+ADD COLUMN `approver_role` varchar(64) NOT NULL DEFAULT ''
+COMMENT 'members with this role or a higher one can approve',
+ADD COLUMN `approvers` varchar(1024) NOT NULL DEFAULT ''
+COMMENT 'users who can approve, separated by comma';
+
+ALTER TABLE tb_pipelinerun
+ADD COLUMN `reviewed_by` bigint(20) unsigned NULL COMMENT 'who approved or rejected this pipelinerun',
+ADD COLUMN `reviewed_at` datetime NULL COMMENT 'when this pipelinerun was approved or rejected',
+ADD COLUMN `review_comment` varchar(1024) NOT NULL DEFAULT '' COMMENT 'comment of the reviewer';
@@ -0,0 +1,35 @@
+-- Copyright © 2023 Horizoncd.
+--
+-- Licensed under the Apache License, Version 2.0 (the "License");
+-- you may not use this file except in compliance with the License.
+-- You may obtain a copy of the License at
+--
+--     http://www.apache.org/licenses/LICENSE-2.0
+--
+-- Unless required by applicable law or agreed to in writing, software
+-- distributed under the License is distributed on an "AS IS" BASIS,
+-- WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+-- See the License for the specific language governing permissions and
+-- limitations under the License.
+
+-- freeze window table
+CREATE TABLE `tb_freeze_window`
+(
+    `id`            bigint(20) unsigned NOT NULL AUTO_INCREMENT,
+    `resource_type` varchar(64)         NOT NULL COMMENT 'environments, groups or applications',
+    `resource_id`   bigint(20) unsigned NOT NULL COMMENT 'id of the resource',
+    `name`          varchar(128)        NOT NULL DEFAULT '' COMMENT 'name of the freeze window',
+    `description`   varchar(1024)       NOT NULL DEFAULT '' COMMENT 'description of the freeze window',
+    `start_at`      datetime            NULL COMMENT 'begin of an absolute freeze window',
+    `end_at`        datetime            NULL COMMENT 'end of an absolute freeze window',
+    `cron`          varchar(128)        NOT NULL DEFAULT '' COMMENT 'when a recurring freeze window begins',
+    `duration`      varchar(64)         NOT NULL DEFAULT '' COMMENT 'how long a recurring freeze window lasts',
+    `created_at`    datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `updated_at`    datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    `created_by`    bigint(20) unsigned NOT NULL DEFAULT 0 COMMENT 'creator',
+    `updated_by`    bigint(20) unsigned NOT NULL DEFAULT 0 COMMENT 'updater',
+    PRIMARY KEY (`id`),
+    KEY `idx_resource` (`resource_type`, `resource_id`)
+) ENGINE = InnoDB
+  AUTO_INCREMENT = 1
+  DEFAULT CHARSET = utf8mb4;
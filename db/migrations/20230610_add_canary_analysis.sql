@@ -0,0 +1,34 @@
+-- Copyright © 2023 Horizoncd.
+--
+-- Licensed under the Apache License, Version 2.0 (the "License");
+-- you may not use this file except in compliance with the License.
+-- You may obtain a copy of the License at
+--
+--     http://www.apache.org/licenses/LICENSE-2.0
+--
+-- Unless required by applicable law or agreed to in writing, software
+-- distributed under the License is distributed on an "AS IS" BASIS,
+-- WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+-- See the License for the specific language governing permissions and
+-- limitations under the License.
+
+-- canary analysis table
+CREATE TABLE `tb_canary_analysis`
+(
+    `id`             bigint(20) unsigned NOT NULL AUTO_INCREMENT,
+    `pipelinerun_id` bigint(20) unsigned NOT NULL COMMENT 'pipelinerun which deployed the rollout',
+    `cluster_id`     bigint(20) unsigned NOT NULL COMMENT 'id of the cluster',
+    `step`           int(11)             NOT NULL DEFAULT 0 COMMENT 'index of the analyzed canary step',
+    `status`         varchar(64)         NOT NULL DEFAULT '' COMMENT 'running, passed, failed or canceled',
+    `metrics`        text COMMENT 'latest results of the metrics',
+    `message`        varchar(2048)       NOT NULL DEFAULT '' COMMENT 'reason of the status',
+    `started_at`     datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT 'when the step began to be observed',
+    `finished_at`    datetime            NULL COMMENT 'when the analysis finished',
+    `created_at`     datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `updated_at`     datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    PRIMARY KEY (`id`),
+    KEY `idx_pipelinerun_id` (`pipelinerun_id`),
+    KEY `idx_cluster_status` (`cluster_id`, `status`)
+) ENGINE = InnoDB
+  AUTO_INCREMENT = 1
+  DEFAULT CHARSET = utf8mb4;
@@ -0,0 +1,57 @@
+-- Copyright © 2023 Horizoncd.
+--
+-- Licensed under the Apache License, Version 2.0 (the "License");
+-- you may not use this file except in compliance with the License.
+-- You may obtain a copy of the License at
+--
+--     http://www.apache.org/licenses/LICENSE-2.0
+--
+-- Unless required by applicable law or agreed to in writing, software
+-- distributed under the License is distributed on an "AS IS" BASIS,
+-- WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+-- See the License for the specific language governing permissions and
+-- limitations under the License.
+
+-- release plan table
+CREATE TABLE `tb_release_plan`
+(
+    `id`               bigint(20) unsigned NOT NULL AUTO_INCREMENT,
+    `application_id`   bigint(20) unsigned NOT NULL COMMENT 'application whose clusters are released',
+    `environment`      varchar(128)        NOT NULL DEFAULT '' COMMENT 'environment of the released clusters',
+    `title`            varchar(256)        NOT NULL DEFAULT '' COMMENT 'title of the release plan',
+    `description`      varchar(2048)       NOT NULL DEFAULT '' COMMENT 'description of the release plan',
+    `image_tag`        varchar(256)        NOT NULL DEFAULT '' COMMENT 'image tag deployed to the clusters built from images',
+    `waves`            text COMMENT 'json of the waves',
+    `current_wave`     int(11)             NOT NULL DEFAULT 0 COMMENT 'index of the wave being released',
+    `wave_finished_at` datetime            NULL COMMENT 'when the current wave became healthy',
+    `status`           varchar(64)         NOT NULL DEFAULT '' COMMENT 'pending, running, paused, succeeded, failed or aborted',
+    `message`          varchar(2048)       NOT NULL DEFAULT '' COMMENT 'reason of the status',
+    `created_at`       datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `updated_at`       datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    `created_by`       bigint(20) unsigned NOT NULL DEFAULT 0 COMMENT 'creator',
+    `updated_by`       bigint(20) unsigned NOT NULL DEFAULT 0 COMMENT 'who started, paused, resumed or aborted the plan last',
+    PRIMARY KEY (`id`),
+    KEY `idx_application_status` (`application_id`, `status`),
+    KEY `idx_status` (`status`)
+) ENGINE = InnoDB
+  AUTO_INCREMENT = 1
+  DEFAULT CHARSET = utf8mb4;
+
+-- release plan cluster table
+CREATE TABLE `tb_release_plan_cluster`
+(
+    `id`             bigint(20) unsigned NOT NULL AUTO_INCREMENT,
+    `plan_id`        bigint(20) unsigned NOT NULL COMMENT 'id of the release plan',
+    `cluster_id`     bigint(20) unsigned NOT NULL COMMENT 'id of the cluster',
+    `wave`           int(11)             NOT NULL DEFAULT 0 COMMENT 'index of the wave releasing the cluster',
+    `pipelinerun_id` bigint(20) unsigned NOT NULL DEFAULT 0 COMMENT 'pipelinerun which deployed the cluster',
+    `status`         varchar(64)         NOT NULL DEFAULT '' COMMENT 'pending, deploying, healthy or failed',
+    `message`        varchar(2048)       NOT NULL DEFAULT '' COMMENT 'reason of the status',
+    `deployed_at`    datetime            NULL COMMENT 'when the cluster was deployed',
+    `created_at`     datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `updated_at`     datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    PRIMARY KEY (`id`),
+    KEY `idx_plan_id` (`plan_id`)
+) ENGINE = InnoDB
+  AUTO_INCREMENT = 1
+  DEFAULT CHARSET = utf8mb4;
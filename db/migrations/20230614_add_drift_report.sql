@@ -0,0 +1,35 @@
+-- Copyright © 2023 Horizoncd.
+--
+-- Licensed under the Apache License, Version 2.0 (the "License");
+-- you may not use this file except in compliance with the License.
+-- You may obtain a copy of the License at
+--
+--     http://www.apache.org/licenses/LICENSE-2.0
+--
+-- Unless required by applicable law or agreed to in writing, software
+-- distributed under the License is distributed on an "AS IS" BASIS,
+-- WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+-- See the License for the specific language governing permissions and
+-- limitations under the License.
+
+-- drift report table
+CREATE TABLE `tb_drift_report`
+(
+    `id`               bigint(20) unsigned NOT NULL AUTO_INCREMENT,
+    `cluster_id`       bigint(20) unsigned NOT NULL COMMENT 'id of the cluster',
+    `status`           varchar(64)         NOT NULL DEFAULT '' COMMENT 'drifted, accepted or resolved',
+    `revision`         varchar(128)        NOT NULL DEFAULT '' COMMENT 'gitops commit the desired state was rendered from',
+    `manifest_version` varchar(128)        NOT NULL DEFAULT '' COMMENT 'version of the manifest in gitops repo',
+    `resources`        text COMMENT 'json of the drifted resources and field paths',
+    `fingerprint`      varchar(64)         NOT NULL DEFAULT '' COMMENT 'hash of the drifted resources',
+    `detected_at`      datetime            NULL COMMENT 'when the drift was detected',
+    `checked_at`       datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT 'when the cluster was checked last',
+    `accepted_at`      datetime            NULL COMMENT 'when the drift was accepted',
+    `accepted_by`      bigint(20) unsigned NOT NULL DEFAULT 0 COMMENT 'who accepted the drift',
+    `created_at`       datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `updated_at`       datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `idx_cluster_id` (`cluster_id`)
+) ENGINE = InnoDB
+  AUTO_INCREMENT = 1
+  DEFAULT CHARSET = utf8mb4;
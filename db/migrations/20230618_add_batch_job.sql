@@ -0,0 +1,56 @@
+-- Copyright © 2023 Horizoncd.
+--
+-- Licensed under the Apache License, Version 2.0 (the "License");
+-- you may not use this file except in compliance with the License.
+-- You may obtain a copy of the License at
+--
+--     http://www.apache.org/licenses/LICENSE-2.0
+--
+-- Unless required by applicable law or agreed to in writing, software
+-- distributed under the License is distributed on an "AS IS" BASIS,
+-- WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+-- See the License for the specific language governing permissions and
+-- limitations under the License.
+
+-- batch job table
+CREATE TABLE `tb_batch_job`
+(
+    `id`             bigint(20) unsigned NOT NULL AUTO_INCREMENT,
+    `group_id`       bigint(20) unsigned NOT NULL COMMENT 'group whose subtree the clusters are selected from',
+    `title`          varchar(256)        NOT NULL DEFAULT '' COMMENT 'title of the batch job',
+    `operation`      varchar(64)         NOT NULL DEFAULT '' COMMENT 'deploy, restart, upgrade or updatetags',
+    `selector`       text COMMENT 'json of the cluster selector',
+    `params`         text COMMENT 'json of the operation params',
+    `concurrency`    int(11)             NOT NULL DEFAULT 1 COMMENT 'how many clusters are operated at the same time',
+    `failure_budget` int(11)             NOT NULL DEFAULT 0 COMMENT 'how many clusters may fail before the job fails',
+    `status`         varchar(64)         NOT NULL DEFAULT '' COMMENT 'running, succeeded, failed or cancelled',
+    `message`        varchar(2048)       NOT NULL DEFAULT '' COMMENT 'reason of the status',
+    `created_at`     datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `updated_at`     datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    `created_by`     bigint(20) unsigned NOT NULL DEFAULT 0 COMMENT 'creator, the operations are performed as this user',
+    `updated_by`     bigint(20) unsigned NOT NULL DEFAULT 0 COMMENT 'who cancelled the job',
+    PRIMARY KEY (`id`),
+    KEY `idx_group_id` (`group_id`),
+    KEY `idx_status` (`status`)
+) ENGINE = InnoDB
+  AUTO_INCREMENT = 1
+  DEFAULT CHARSET = utf8mb4;
+
+-- batch job cluster table
+CREATE TABLE `tb_batch_job_cluster`
+(
+    `id`             bigint(20) unsigned NOT NULL AUTO_INCREMENT,
+    `job_id`         bigint(20) unsigned NOT NULL COMMENT 'id of the batch job',
+    `cluster_id`     bigint(20) unsigned NOT NULL COMMENT 'id of the cluster',
+    `pipelinerun_id` bigint(20) unsigned NOT NULL DEFAULT 0 COMMENT 'pipelinerun created by deploy or restart',
+    `status`         varchar(64)         NOT NULL DEFAULT '' COMMENT 'pending, running, succeeded, failed or skipped',
+    `message`        varchar(2048)       NOT NULL DEFAULT '' COMMENT 'reason of the status',
+    `started_at`     datetime            NULL COMMENT 'when the operation on the cluster started',
+    `finished_at`    datetime            NULL COMMENT 'when the operation on the cluster finished',
+    `created_at`     datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `updated_at`     datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    PRIMARY KEY (`id`),
+    KEY `idx_job_id` (`job_id`)
+) ENGINE = InnoDB
+  AUTO_INCREMENT = 1
+  DEFAULT CHARSET = utf8mb4;
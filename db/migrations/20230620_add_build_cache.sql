@@ -0,0 +1,19 @@
+-- Copyright © 2023 Horizoncd.
+--
+-- Licensed under the Apache License, Version 2.0 (the "License");
+-- you may not use this file except in compliance with the License.
+-- You may obtain a copy of the License at
+--
+--     http://www.apache.org/licenses/LICENSE-2.0
+--
+-- Unless required by applicable law or agreed to in writing, software
+-- distributed under the License is distributed on an "AS IS" BASIS,
+-- WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+-- See the License for the specific language governing permissions and
+-- limitations under the License.
+
+ALTER TABLE tb_pipelinerun
+ADD COLUMN `git_subfolder` varchar(256) NOT NULL DEFAULT '' COMMENT 'subfolder of the git repo built by this pipelinerun',
+ADD COLUMN `build_config_hash` varchar(64) NOT NULL DEFAULT '' COMMENT 'sha256 of the build config',
+ADD COLUMN `reuse_from` bigint(20) unsigned NULL COMMENT 'the pipelinerun whose image this pipelinerun reused',
+ADD KEY `idx_build_cache` (`git_commit`, `build_config_hash`);
@@ -0,0 +1,44 @@
+-- Copyright © 2023 Horizoncd.
+--
+-- Licensed under the Apache License, Version 2.0 (the "License");
+-- you may not use this file except in compliance with the License.
+-- You may obtain a copy of the License at
+--
+--     http://www.apache.org/licenses/LICENSE-2.0
+--
+-- Unless required by applicable law or agreed to in writing, software
+-- distributed under the License is distributed on an "AS IS" BASIS,
+-- WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+-- See the License for the specific language governing permissions and
+-- limitations under the License.
+
+-- pipelinerun report table
+CREATE TABLE `tb_pipelinerun_report`
+(
+    `id`              bigint(20) unsigned NOT NULL AUTO_INCREMENT,
+    `pipelinerun_id`  bigint(20) unsigned NOT NULL COMMENT 'id of the pipelinerun',
+    `cluster_id`      bigint(20) unsigned NOT NULL COMMENT 'id of the cluster',
+    `task`            varchar(128)        NOT NULL DEFAULT '' COMMENT 'task producing the report',
+    `name`            varchar(128)        NOT NULL DEFAULT '' COMMENT 'name of the task result the report is read from',
+    `kind`            varchar(64)         NOT NULL DEFAULT '' COMMENT 'test, coverage or vulnerability',
+    `format`          varchar(64)         NOT NULL DEFAULT '' COMMENT 'junit, cobertura, sarif or trivy',
+    `object`          varchar(512)        NOT NULL DEFAULT '' COMMENT 's3 object of the report file',
+    `tests`           int(11)             NOT NULL DEFAULT 0,
+    `failures`        int(11)             NOT NULL DEFAULT 0,
+    `errors`          int(11)             NOT NULL DEFAULT 0,
+    `skipped`         int(11)             NOT NULL DEFAULT 0,
+    `line_coverage`   double              NOT NULL DEFAULT 0 COMMENT 'line coverage in percent',
+    `branch_coverage` double              NOT NULL DEFAULT 0 COMMENT 'branch coverage in percent',
+    `critical`        int(11)             NOT NULL DEFAULT 0,
+    `high`            int(11)             NOT NULL DEFAULT 0,
+    `medium`          int(11)             NOT NULL DEFAULT 0,
+    `low`             int(11)             NOT NULL DEFAULT 0,
+    `unknown`         int(11)             NOT NULL DEFAULT 0,
+    `created_at`      datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `updated_at`      datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    PRIMARY KEY (`id`),
+    KEY `idx_pipelinerun_id` (`pipelinerun_id`),
+    KEY `idx_cluster_id` (`cluster_id`)
+) ENGINE = InnoDB
+  AUTO_INCREMENT = 1
+  DEFAULT CHARSET = utf8mb4;
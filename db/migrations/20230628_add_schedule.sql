@@ -0,0 +1,42 @@
+-- Copyright © 2023 Horizoncd.
+--
+-- Licensed under the Apache License, Version 2.0 (the "License");
+-- you may not use this file except in compliance with the License.
+-- You may obtain a copy of the License at
+--
+--     http://www.apache.org/licenses/LICENSE-2.0
+--
+-- Unless required by applicable law or agreed to in writing, software
+-- distributed under the License is distributed on an "AS IS" BASIS,
+-- WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+-- See the License for the specific language governing permissions and
+-- limitations under the License.
+
+-- record what triggered the pipelinerun, empty means it's triggered by a user
+ALTER TABLE tb_pipelinerun ADD `trigger` varchar(64) NOT NULL DEFAULT '' COMMENT 'what triggered the pipelinerun' AFTER action;
+
+-- schedule table
+CREATE TABLE `tb_schedule`
+(
+    `id`                   bigint(20) unsigned NOT NULL AUTO_INCREMENT,
+    `cluster_id`           bigint(20) unsigned NOT NULL COMMENT 'id of the cluster',
+    `name`                 varchar(128)        NOT NULL DEFAULT '',
+    `cron`                 varchar(128)        NOT NULL COMMENT 'standard cron expression or descriptor',
+    `action`               varchar(64)         NOT NULL COMMENT 'builddeploy, deploy or restart',
+    `params`               text COMMENT 'json of the params of the action',
+    `operator_id`          bigint(20) unsigned NOT NULL COMMENT 'user whom the pipelineruns are created as',
+    `paused`               tinyint(1)          NOT NULL DEFAULT 0,
+    `next_run_at`          datetime                     DEFAULT NULL,
+    `last_run_at`          datetime                     DEFAULT NULL,
+    `last_pipelinerun_id`  bigint(20) unsigned NOT NULL DEFAULT 0,
+    `last_message`         varchar(1024)       NOT NULL DEFAULT '',
+    `created_at`           datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `updated_at`           datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    `created_by`           bigint(20) unsigned NOT NULL DEFAULT 0,
+    `updated_by`           bigint(20) unsigned NOT NULL DEFAULT 0,
+    PRIMARY KEY (`id`),
+    KEY `idx_cluster_id` (`cluster_id`),
+    KEY `idx_next_run_at` (`next_run_at`)
+) ENGINE = InnoDB
+  AUTO_INCREMENT = 1
+  DEFAULT CHARSET = utf8mb4;
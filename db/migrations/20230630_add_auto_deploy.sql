@@ -0,0 +1,35 @@
+-- Copyright © 2023 Horizoncd.
+--
+-- Licensed under the Apache License, Version 2.0 (the "License");
+-- you may not use this file except in compliance with the License.
+-- You may obtain a copy of the License at
+--
+--     http://www.apache.org/licenses/LICENSE-2.0
+--
+-- Unless required by applicable law or agreed to in writing, software
+-- distributed under the License is distributed on an "AS IS" BASIS,
+-- WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+-- See the License for the specific language governing permissions and
+-- limitations under the License.
+
+-- auto deploy table, clusters in it are built and deployed when their git refs are pushed
+CREATE TABLE `tb_auto_deploy`
+(
+    `id`                  bigint(20) unsigned NOT NULL AUTO_INCREMENT,
+    `cluster_id`          bigint(20) unsigned NOT NULL COMMENT 'id of the cluster',
+    `path_globs`          text COMMENT 'json of the globs changed files must match, any change if empty',
+    `tag_pattern`         varchar(256)        NOT NULL DEFAULT '' COMMENT 'glob of the tags deploying clusters built from tags',
+    `operator_id`         bigint(20) unsigned NOT NULL COMMENT 'user whom the pipelineruns are created as',
+    `last_triggered_at`   datetime                     DEFAULT NULL,
+    `last_commit`         varchar(128)        NOT NULL DEFAULT '',
+    `last_pipelinerun_id` bigint(20) unsigned NOT NULL DEFAULT 0,
+    `last_message`        varchar(1024)       NOT NULL DEFAULT '',
+    `created_at`          datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `updated_at`          datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    `created_by`          bigint(20) unsigned NOT NULL DEFAULT 0,
+    `updated_by`          bigint(20) unsigned NOT NULL DEFAULT 0,
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `idx_cluster_id` (`cluster_id`)
+) ENGINE = InnoDB
+  AUTO_INCREMENT = 1
+  DEFAULT CHARSET = utf8mb4;
@@ -0,0 +1,31 @@
+-- Copyright © 2023 Horizoncd.
+--
+-- Licensed under the Apache License, Version 2.0 (the "License");
+-- you may not use this file except in compliance with the License.
+-- You may obtain a copy of the License at
+--
+--     http://www.apache.org/licenses/LICENSE-2.0
+--
+-- Unless required by applicable law or agreed to in writing, software
+-- distributed under the License is distributed on an "AS IS" BASIS,
+-- WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+-- See the License for the specific language governing permissions and
+-- limitations under the License.
+
+-- run queue table, pipelineruns of a cluster wait in the queue while the cluster is held by another one
+CREATE TABLE `tb_run_queue`
+(
+    `id`             bigint(20) unsigned NOT NULL AUTO_INCREMENT,
+    `cluster_id`     bigint(20) unsigned NOT NULL COMMENT 'id of the cluster',
+    `policy`         varchar(32)         NOT NULL DEFAULT '' COMMENT 'parallel, serialize, cancel or reject, the default policy if empty',
+    `pipelinerun_id` bigint(20) unsigned NOT NULL DEFAULT 0 COMMENT 'pipelinerun holding the cluster, 0 if none',
+    `locked_at`      datetime                     DEFAULT NULL COMMENT 'when the pipelinerun started holding the cluster',
+    `created_at`     datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `updated_at`     datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    `created_by`     bigint(20) unsigned NOT NULL DEFAULT 0,
+    `updated_by`     bigint(20) unsigned NOT NULL DEFAULT 0,
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `idx_cluster_id` (`cluster_id`)
+) ENGINE = InnoDB
+  AUTO_INCREMENT = 1
+  DEFAULT CHARSET = utf8mb4;
@@ -0,0 +1,17 @@
+-- Copyright © 2023 Horizoncd.
+--
+-- Licensed under the Apache License, Version 2.0 (the "License");
+-- you may not use this file except in compliance with the License.
+-- You may obtain a copy of the License at
+--
+--     http://www.apache.org/licenses/LICENSE-2.0
+--
+-- Unless required by applicable law or agreed to in writing, software
+-- distributed under the License is distributed on an "AS IS" BASIS,
+-- WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+-- See the License for the specific language governing permissions and
+-- limitations under the License.
+-- commit time of pipelineruns, the lead time of changes is measured from it
+ALTER TABLE tb_pipelinerun
+ADD COLUMN `git_commit_time` datetime DEFAULT NULL COMMENT 'time when the git commit is committed' AFTER `git_commit`,
+ADD KEY `idx_finished_at` (`finished_at`);
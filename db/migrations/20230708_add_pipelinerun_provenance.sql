@@ -0,0 +1,34 @@
+-- Copyright © 2023 Horizoncd.
+--
+-- Licensed under the Apache License, Version 2.0 (the "License");
+-- you may not use this file except in compliance with the License.
+-- You may obtain a copy of the License at
+--
+--     http://www.apache.org/licenses/LICENSE-2.0
+--
+-- Unless required by applicable law or agreed to in writing, software
+-- distributed under the License is distributed on an "AS IS" BASIS,
+-- WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+-- See the License for the specific language governing permissions and
+-- limitations under the License.
+-- pipelinerun provenance table, the slsa provenance signed by tekton chains
+CREATE TABLE `tb_pipelinerun_provenance`
+(
+    `id`             bigint(20) unsigned NOT NULL AUTO_INCREMENT,
+    `pipelinerun_id` bigint(20) unsigned NOT NULL COMMENT 'id of the pipelinerun',
+    `cluster_id`     bigint(20) unsigned NOT NULL COMMENT 'id of the cluster',
+    `predicate_type` varchar(256)        NOT NULL DEFAULT '' COMMENT 'slsa version of the provenance',
+    `builder_id`     varchar(256)        NOT NULL DEFAULT '' COMMENT 'id of the builder',
+    `build_type`     varchar(256)        NOT NULL DEFAULT '' COMMENT 'type of the build',
+    `subjects`       text COMMENT 'json of the artifacts built',
+    `materials`      text COMMENT 'json of the sources the artifacts are built from',
+    `statement`      mediumtext COMMENT 'in-toto statement signed',
+    `signature`      text COMMENT 'signature of the statement in base64',
+    `created_at`     datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `updated_at`     datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `idx_pipelinerun_id` (`pipelinerun_id`),
+    KEY `idx_cluster_id` (`cluster_id`)
+) ENGINE = InnoDB
+  AUTO_INCREMENT = 1
+  DEFAULT CHARSET = utf8mb4;
@@ -0,0 +1,34 @@
+-- Copyright © 2023 Horizoncd.
+--
+-- Licensed under the Apache License, Version 2.0 (the "License");
+-- you may not use this file except in compliance with the License.
+-- You may obtain a copy of the License at
+--
+--     http://www.apache.org/licenses/LICENSE-2.0
+--
+-- Unless required by applicable law or agreed to in writing, software
+-- distributed under the License is distributed on an "AS IS" BASIS,
+-- WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+-- See the License for the specific language governing permissions and
+-- limitations under the License.
+-- webhook retry, failed deliveries are retried with backoff and dead lettered after all attempts fail
+ALTER TABLE tb_webhook
+ADD COLUMN `retry_policy` text COMMENT 'json of the retry policy, the default policy is used if it is empty';
+
+ALTER TABLE tb_webhook_log
+ADD COLUMN `attempts` int unsigned NOT NULL DEFAULT 0 COMMENT 'attempts since the log is created or redelivered',
+ADD COLUMN `next_attempt_at` datetime DEFAULT NULL COMMENT 'when the retrying log is sent again';
+
+CREATE TABLE `tb_webhook_log_attempt`
+(
+    `id`             bigint(20) unsigned NOT NULL AUTO_INCREMENT,
+    `webhook_log_id` bigint(20) unsigned NOT NULL COMMENT 'id of the webhook log',
+    `attempt`        int unsigned        NOT NULL DEFAULT 0 COMMENT 'sequence of the attempt',
+    `status_code`    int                 NOT NULL DEFAULT 0 COMMENT 'code of the response, 0 if there is no response',
+    `error_message`  text COMMENT 'error of the attempt, empty if it succeeded',
+    `created_at`     datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (`id`),
+    KEY `idx_webhook_log_id` (`webhook_log_id`)
+) ENGINE = InnoDB
+  AUTO_INCREMENT = 1
+  DEFAULT CHARSET = utf8mb4;
@@ -0,0 +1,18 @@
+-- Copyright © 2023 Horizoncd.
+--
+-- Licensed under the Apache License, Version 2.0 (the "License");
+-- you may not use this file except in compliance with the License.
+-- You may obtain a copy of the License at
+--
+--     http://www.apache.org/licenses/LICENSE-2.0
+--
+-- Unless required by applicable law or agreed to in writing, software
+-- distributed under the License is distributed on an "AS IS" BASIS,
+-- WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+-- See the License for the specific language governing permissions and
+-- limitations under the License.
+-- webhook signature, requests are signed by the secret and the previous secret while it's rotated
+ALTER TABLE tb_webhook
+ADD COLUMN `previous_secret` varchar(256) NOT NULL DEFAULT '' COMMENT 'secret before rotation, it signs requests until it expires',
+ADD COLUMN `previous_secret_expired_at` datetime DEFAULT NULL COMMENT 'when the previous secret stops signing requests',
+ADD COLUMN `content_mode` varchar(64) NOT NULL DEFAULT '' COMMENT 'format of the requests: json, cloudevents-structured or cloudevents-binary';
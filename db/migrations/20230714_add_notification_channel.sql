@@ -0,0 +1,17 @@
+-- Copyright © 2023 Horizoncd.
+--
+-- Licensed under the Apache License, Version 2.0 (the "License");
+-- you may not use this file except in compliance with the License.
+-- You may obtain a copy of the License at
+--
+--     http://www.apache.org/licenses/LICENSE-2.0
+--
+-- Unless required by applicable law or agreed to in writing, software
+-- distributed under the License is distributed on an "AS IS" BASIS,
+-- WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+-- See the License for the specific language governing permissions and
+-- limitations under the License.
+-- notification channels, webhooks rendering messages for slack, dingtalk, feishu and email
+ALTER TABLE tb_webhook
+ADD COLUMN `channel` varchar(64) NOT NULL DEFAULT '' COMMENT 'slack, dingtalk, feishu or email, empty for plain webhooks',
+ADD COLUMN `channel_config` text COMMENT 'json of the recipients and the templates of the notification channel';
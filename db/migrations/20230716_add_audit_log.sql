@@ -0,0 +1,43 @@
+-- Copyright © 2023 Horizoncd.
+--
+-- Licensed under the Apache License, Version 2.0 (the "License");
+-- you may not use this file except in compliance with the License.
+-- You may obtain a copy of the License at
+--
+--     http://www.apache.org/licenses/LICENSE-2.0
+--
+-- Unless required by applicable law or agreed to in writing, software
+-- distributed under the License is distributed on an "AS IS" BASIS,
+-- WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+-- See the License for the specific language governing permissions and
+-- limitations under the License.
+-- audit log table, who did what, when and from where
+CREATE TABLE `tb_audit_log`
+(
+    `id`            bigint(20) unsigned NOT NULL AUTO_INCREMENT,
+    `req_id`        varchar(64)         NOT NULL DEFAULT '' COMMENT 'request id',
+    `actor_type`    varchar(32)         NOT NULL DEFAULT '' COMMENT 'user, robot, oauthapp or anonymous',
+    `actor_id`      bigint(20) unsigned NOT NULL DEFAULT 0 COMMENT 'id of the user',
+    `actor_name`    varchar(128)        NOT NULL DEFAULT '' COMMENT 'name of the user',
+    `token_id`      bigint(20) unsigned NOT NULL DEFAULT 0 COMMENT 'id of the access token, 0 if authenticated by session',
+    `client_id`     varchar(256)        NOT NULL DEFAULT '' COMMENT 'client id of the oauth app',
+    `method`        varchar(16)         NOT NULL DEFAULT '' COMMENT 'http method',
+    `path`          varchar(1024)       NOT NULL DEFAULT '' COMMENT 'http path',
+    `verb`          varchar(32)         NOT NULL DEFAULT '' COMMENT 'verb of the auth record',
+    `resource`      varchar(64)         NOT NULL DEFAULT '' COMMENT 'resource of the auth record',
+    `resource_name` varchar(128)        NOT NULL DEFAULT '' COMMENT 'resource name of the auth record',
+    `sub_resource`  varchar(64)         NOT NULL DEFAULT '' COMMENT 'subresource of the auth record',
+    `decision`      varchar(16)         NOT NULL DEFAULT '' COMMENT 'allow, deny or notchecked',
+    `reason`        varchar(512)        NOT NULL DEFAULT '' COMMENT 'reason of the decision',
+    `status_code`   int(11)             NOT NULL DEFAULT 0 COMMENT 'http status code of the response',
+    `source_ip`     varchar(64)         NOT NULL DEFAULT '' COMMENT 'ip of the client',
+    `user_agent`    varchar(512)        NOT NULL DEFAULT '' COMMENT 'user agent of the client',
+    `annotations`   text COMMENT 'json of the details attached by controllers',
+    `created_at`    datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (`id`),
+    KEY `idx_created_at` (`created_at`),
+    KEY `idx_req_id` (`req_id`),
+    KEY `idx_actor_name` (`actor_name`)
+) ENGINE = InnoDB
+  AUTO_INCREMENT = 1
+  DEFAULT CHARSET = utf8mb4;
@@ -0,0 +1,16 @@
+-- Copyright © 2023 Horizoncd.
+--
+-- Licensed under the Apache License, Version 2.0 (the "License");
+-- you may not use this file except in compliance with the License.
+-- You may obtain a copy of the License at
+--
+--     http://www.apache.org/licenses/LICENSE-2.0
+--
+-- Unless required by applicable law or agreed to in writing, software
+-- distributed under the License is distributed on an "AS IS" BASIS,
+-- WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+-- See the License for the specific language governing permissions and
+-- limitations under the License.
+-- secrets of the push webhooks per auto deploy, replacing the secret shared by all repos of a git host
+ALTER TABLE tb_auto_deploy
+ADD COLUMN `webhook_secret` varchar(256) NOT NULL DEFAULT '' COMMENT 'secret of the push webhook deploying the cluster';
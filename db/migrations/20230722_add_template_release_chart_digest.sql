@@ -0,0 +1,17 @@
+-- Copyright © 2023 Horizoncd.
+--
+-- Licensed under the Apache License, Version 2.0 (the "License");
+-- you may not use this file except in compliance with the License.
+-- You may obtain a copy of the License at
+--
+--     http://www.apache.org/licenses/LICENSE-2.0
+--
+-- Unless required by applicable law or agreed to in writing, software
+-- distributed under the License is distributed on an "AS IS" BASIS,
+-- WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+-- See the License for the specific language governing permissions and
+-- limitations under the License.
+-- digests of charts uploaded to OCI registries, which pin the charts of template releases
+ALTER TABLE tb_template_release
+ADD COLUMN `chart_digest` varchar(128) NOT NULL DEFAULT ''
+COMMENT 'digest of the chart uploaded to repos addressing charts by digest' AFTER `chart_version`;
@@ -0,0 +1,20 @@
+-- Copyright © 2023 Horizoncd.
+--
+-- Licensed under the Apache License, Version 2.0 (the "License");
+-- you may not use this file except in compliance with the License.
+-- You may obtain a copy of the License at
+--
+--     http://www.apache.org/licenses/LICENSE-2.0
+--
+-- Unless required by applicable law or agreed to in writing, software
+-- distributed under the License is distributed on an "AS IS" BASIS,
+-- WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+-- See the License for the specific language governing permissions and
+-- limitations under the License.
+-- clear the secrets before rotation which have expired, they are cleared by the cleaner job from now on.
+-- Migration note: the plain secret is no longer sent in the X-Horizon-Webhook-Secret header by default,
+-- receivers still reading it should verify the X-Horizon-Webhook-Signature header instead,
+-- or set webhook.legacySecretHeader to true in the config until they are migrated.
+UPDATE tb_webhook
+SET `previous_secret` = '', `previous_secret_expired_at` = NULL
+WHERE `previous_secret` != '' AND `previous_secret_expired_at` < NOW();
@@ -137,6 +137,11 @@ type Interface interface {
 	// See https://docs.gitlab.com/ee/api/repository_files.html#get-file-from-repository for more information.
 	GetFile(ctx context.Context, pid interface{}, ref, filepath string) ([]byte, error)
 
+	// ListFiles lists paths of the files under the directory recursively in the specified project with the ref.
+	// The pid can be the project's ID or relative path such as fist/second.
+	// See https://docs.gitlab.com/ee/api/repositories.html#list-repository-tree for more information.
+	ListFiles(ctx context.Context, pid interface{}, ref, directory string) ([]string, error)
+
 	// TransferProject transfer a project with the specified pid to the new group with the gid.
 	// The pid can be the project's ID or relative path such as fist/second.
 	// The gid can be the group's ID or relative path such as first/third.
@@ -532,6 +537,35 @@ func (h *helper) GetFile(ctx context.Context, pid interface{}, ref, filepath str
 	return content, nil
 }
 
+func (h *helper) ListFiles(ctx context.Context, pid interface{}, ref, directory string) (_ []string, err error) {
+	const op = "gitlab: list files"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	var files []string
+	opts := &gitlab.ListTreeOptions{
+		ListOptions: gitlab.ListOptions{Page: 1, PerPage: 100},
+		Path:        &directory,
+		Ref:         &ref,
+		Recursive:   gitlab.Bool(true),
+	}
+	for {
+		nodes, rsp, err := h.client.Repositories.ListTree(pid, opts, gitlab.WithContext(ctx))
+		if err != nil {
+			return nil, parseError(rsp, err)
+		}
+		for _, node := range nodes {
+			if node.Type == "blob" {
+				files = append(files, node.Path)
+			}
+		}
+		if rsp.NextPage == 0 {
+			break
+		}
+		opts.Page = rsp.NextPage
+	}
+	return files, nil
+}
+
 func (h *helper) TransferProject(ctx context.Context, pid interface{}, gid interface{}) (err error) {
 	const op = "gitlab: transfer project"
 	defer wlog.Start(ctx, op).StopPrint()
@@ -346,6 +346,21 @@ func (mr *MockInterfaceMockRecorder) ListBranch(ctx, pid, listBranchOptions inte
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListBranch", reflect.TypeOf((*MockInterface)(nil).ListBranch), ctx, pid, listBranchOptions)
 }
 
+// ListFiles mocks base method.
+func (m *MockInterface) ListFiles(ctx context.Context, pid interface{}, ref, directory string) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListFiles", ctx, pid, ref, directory)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListFiles indicates an expected call of ListFiles.
+func (mr *MockInterfaceMockRecorder) ListFiles(ctx, pid, ref, directory interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListFiles", reflect.TypeOf((*MockInterface)(nil).ListFiles), ctx, pid, ref, directory)
+}
+
 // ListGroupProjects mocks base method.
 func (m *MockInterface) ListGroupProjects(ctx context.Context, gid interface{}, page, perPage int) ([]*gitlab0.Project, error) {
 	m.ctrl.T.Helper()
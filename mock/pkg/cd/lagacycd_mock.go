@@ -77,6 +77,21 @@ func (mr *MockLegacyCDMockRecorder) DeployCluster(ctx, params interface{}) *gomo
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeployCluster", reflect.TypeOf((*MockLegacyCD)(nil).DeployCluster), ctx, params)
 }
 
+// GetClusterDrift mocks base method.
+func (m *MockLegacyCD) GetClusterDrift(ctx context.Context, params *cd.GetClusterDriftParams) (*cd.ClusterDrift, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetClusterDrift", ctx, params)
+	ret0, _ := ret[0].(*cd.ClusterDrift)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetClusterDrift indicates an expected call of GetClusterDrift.
+func (mr *MockLegacyCDMockRecorder) GetClusterDrift(ctx, params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetClusterDrift", reflect.TypeOf((*MockLegacyCD)(nil).GetClusterDrift), ctx, params)
+}
+
 // GetClusterState mocks base method.
 func (m *MockLegacyCD) GetClusterState(ctx context.Context, params *cd.GetClusterStateV2Params) (*cd.ClusterStateV2, error) {
 	m.ctrl.T.Helper()
@@ -0,0 +1,182 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ci.go
+
+// Package mock_ci is a generated GoMock package.
+package mock_ci
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	ci "github.com/horizoncd/horizon/pkg/cluster/ci"
+	tekton "github.com/horizoncd/horizon/pkg/cluster/tekton"
+	collector "github.com/horizoncd/horizon/pkg/cluster/tekton/collector"
+	report "github.com/horizoncd/horizon/pkg/cluster/tekton/report"
+	models "github.com/horizoncd/horizon/pkg/pipelinerun/models"
+	global "github.com/horizoncd/horizon/pkg/server/global"
+)
+
+// MockEngine is a mock of Engine interface.
+type MockEngine struct {
+	ctrl     *gomock.Controller
+	recorder *MockEngineMockRecorder
+}
+
+// MockEngineMockRecorder is the mock recorder for MockEngine.
+type MockEngineMockRecorder struct {
+	mock *MockEngine
+}
+
+// NewMockEngine creates a new mock instance.
+func NewMockEngine(ctrl *gomock.Controller) *MockEngine {
+	mock := &MockEngine{ctrl: ctrl}
+	mock.recorder = &MockEngineMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockEngine) EXPECT() *MockEngineMockRecorder {
+	return m.recorder
+}
+
+// Complete mocks base method.
+func (m *MockEngine) Complete(ctx context.Context, callback *ci.Callback, metadata *global.HorizonMetaData) (*ci.Result, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Complete", ctx, callback, metadata)
+	ret0, _ := ret[0].(*ci.Result)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Complete indicates an expected call of Complete.
+func (mr *MockEngineMockRecorder) Complete(ctx, callback, metadata interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Complete", reflect.TypeOf((*MockEngine)(nil).Complete), ctx, callback, metadata)
+}
+
+// CreatePipelineRun mocks base method.
+func (m *MockEngine) CreatePipelineRun(ctx context.Context, pr *tekton.PipelineRun) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreatePipelineRun", ctx, pr)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreatePipelineRun indicates an expected call of CreatePipelineRun.
+func (mr *MockEngineMockRecorder) CreatePipelineRun(ctx, pr interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreatePipelineRun", reflect.TypeOf((*MockEngine)(nil).CreatePipelineRun), ctx, pr)
+}
+
+// GetPipelineRunLog mocks base method.
+func (m *MockEngine) GetPipelineRunLog(ctx context.Context, pr *models.Pipelinerun) (*collector.Log, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPipelineRunLog", ctx, pr)
+	ret0, _ := ret[0].(*collector.Log)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPipelineRunLog indicates an expected call of GetPipelineRunLog.
+func (mr *MockEngineMockRecorder) GetPipelineRunLog(ctx, pr interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPipelineRunLog", reflect.TypeOf((*MockEngine)(nil).GetPipelineRunLog), ctx, pr)
+}
+
+// GetPipelineRunReport mocks base method.
+func (m *MockEngine) GetPipelineRunReport(ctx context.Context, object string) ([]byte, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPipelineRunReport", ctx, object)
+	ret0, _ := ret[0].([]byte)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPipelineRunReport indicates an expected call of GetPipelineRunReport.
+func (mr *MockEngineMockRecorder) GetPipelineRunReport(ctx, object interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPipelineRunReport", reflect.TypeOf((*MockEngine)(nil).GetPipelineRunReport), ctx, object)
+}
+
+// GetPipelineRunReports mocks base method.
+func (m *MockEngine) GetPipelineRunReports(ctx context.Context, pr *models.Pipelinerun) ([]*report.Report, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPipelineRunReports", ctx, pr)
+	ret0, _ := ret[0].([]*report.Report)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPipelineRunReports indicates an expected call of GetPipelineRunReports.
+func (mr *MockEngineMockRecorder) GetPipelineRunReports(ctx, pr interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPipelineRunReports", reflect.TypeOf((*MockEngine)(nil).GetPipelineRunReports), ctx, pr)
+}
+
+// StopPipelineRun mocks base method.
+func (m *MockEngine) StopPipelineRun(ctx context.Context, pr *models.Pipelinerun) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StopPipelineRun", ctx, pr)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// StopPipelineRun indicates an expected call of StopPipelineRun.
+func (mr *MockEngineMockRecorder) StopPipelineRun(ctx, pr interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StopPipelineRun", reflect.TypeOf((*MockEngine)(nil).StopPipelineRun), ctx, pr)
+}
+
+// Type mocks base method.
+func (m *MockEngine) Type() string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Type")
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// Type indicates an expected call of Type.
+func (mr *MockEngineMockRecorder) Type() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Type", reflect.TypeOf((*MockEngine)(nil).Type))
+}
+
+// MockFactory is a mock of Factory interface.
+type MockFactory struct {
+	ctrl     *gomock.Controller
+	recorder *MockFactoryMockRecorder
+}
+
+// MockFactoryMockRecorder is the mock recorder for MockFactory.
+type MockFactoryMockRecorder struct {
+	mock *MockFactory
+}
+
+// NewMockFactory creates a new mock instance.
+func NewMockFactory(ctrl *gomock.Controller) *MockFactory {
+	mock := &MockFactory{ctrl: ctrl}
+	mock.recorder = &MockFactoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockFactory) EXPECT() *MockFactoryMockRecorder {
+	return m.recorder
+}
+
+// GetEngine mocks base method.
+func (m *MockFactory) GetEngine(environment string) (ci.Engine, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetEngine", environment)
+	ret0, _ := ret[0].(ci.Engine)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetEngine indicates an expected call of GetEngine.
+func (mr *MockFactoryMockRecorder) GetEngine(environment interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetEngine", reflect.TypeOf((*MockFactory)(nil).GetEngine), environment)
+}
@@ -178,6 +178,21 @@ func (mr *MockManagerMockRecorder) ListByApplicationID(ctx, applicationID interf
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListByApplicationID", reflect.TypeOf((*MockManager)(nil).ListByApplicationID), ctx, applicationID)
 }
 
+// ListByGitURLs mocks base method.
+func (m *MockManager) ListByGitURLs(ctx context.Context, gitURLs []string) ([]*models.Cluster, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListByGitURLs", ctx, gitURLs)
+	ret0, _ := ret[0].([]*models.Cluster)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListByGitURLs indicates an expected call of ListByGitURLs.
+func (mr *MockManagerMockRecorder) ListByGitURLs(ctx, gitURLs interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListByGitURLs", reflect.TypeOf((*MockManager)(nil).ListByGitURLs), ctx, gitURLs)
+}
+
 // ListClusterWithExpiry mocks base method.
 func (m *MockManager) ListClusterWithExpiry(ctx context.Context, query *q.Query) ([]*models.Cluster, error) {
 	m.ctrl.T.Helper()
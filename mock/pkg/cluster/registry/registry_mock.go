@@ -47,3 +47,18 @@ func (mr *MockRegistryMockRecorder) DeleteImage(ctx, appName, clusterName interf
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteImage", reflect.TypeOf((*MockRegistry)(nil).DeleteImage), ctx, appName, clusterName)
 }
+
+// ImageExists mocks base method.
+func (m *MockRegistry) ImageExists(ctx context.Context, imageURL string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ImageExists", ctx, imageURL)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ImageExists indicates an expected call of ImageExists.
+func (mr *MockRegistryMockRecorder) ImageExists(ctx, imageURL interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ImageExists", reflect.TypeOf((*MockRegistry)(nil).ImageExists), ctx, imageURL)
+}
@@ -97,3 +97,18 @@ func (mr *MockInterfaceMockRecorder) GetPipelineRunObject(ctx, object interface{
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPipelineRunObject", reflect.TypeOf((*MockInterface)(nil).GetPipelineRunObject), ctx, object)
 }
+
+// GetPipelineRunReport mocks base method.
+func (m *MockInterface) GetPipelineRunReport(ctx context.Context, object string) ([]byte, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPipelineRunReport", ctx, object)
+	ret0, _ := ret[0].([]byte)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPipelineRunReport indicates an expected call of GetPipelineRunReport.
+func (mr *MockInterfaceMockRecorder) GetPipelineRunReport(ctx, object interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPipelineRunReport", reflect.TypeOf((*MockInterface)(nil).GetPipelineRunReport), ctx, object)
+}
@@ -66,6 +66,21 @@ func (mr *MockInterfaceMockRecorder) DeletePipelineRun(ctx, pr interface{}) *gom
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeletePipelineRun", reflect.TypeOf((*MockInterface)(nil).DeletePipelineRun), ctx, pr)
 }
 
+// GetFinishedPipelineRun mocks base method.
+func (m *MockInterface) GetFinishedPipelineRun(ctx context.Context, pr *v1beta1.PipelineRun) (*v1beta1.PipelineRun, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetFinishedPipelineRun", ctx, pr)
+	ret0, _ := ret[0].(*v1beta1.PipelineRun)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetFinishedPipelineRun indicates an expected call of GetFinishedPipelineRun.
+func (mr *MockInterfaceMockRecorder) GetFinishedPipelineRun(ctx, pr interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFinishedPipelineRun", reflect.TypeOf((*MockInterface)(nil).GetFinishedPipelineRun), ctx, pr)
+}
+
 // GetPipelineRunByID mocks base method.
 func (m *MockInterface) GetPipelineRunByID(ctx context.Context, ciEventID string) (*v1beta1.PipelineRun, error) {
 	m.ctrl.T.Helper()
@@ -6,6 +6,7 @@ package mock_git
 
 import (
 	context "context"
+	http "net/http"
 	reflect "reflect"
 
 	gomock "github.com/golang/mock/gomock"
@@ -35,6 +36,21 @@ func (m *MockHelper) EXPECT() *MockHelperMockRecorder {
 	return m.recorder
 }
 
+// Compare mocks base method.
+func (m *MockHelper) Compare(ctx context.Context, gitURL, from, to string) (*git.Comparison, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Compare", ctx, gitURL, from, to)
+	ret0, _ := ret[0].(*git.Comparison)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Compare indicates an expected call of Compare.
+func (mr *MockHelperMockRecorder) Compare(ctx, gitURL, from, to interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Compare", reflect.TypeOf((*MockHelper)(nil).Compare), ctx, gitURL, from, to)
+}
+
 // GetCommit mocks base method.
 func (m *MockHelper) GetCommit(ctx context.Context, gitURL, refType, ref string) (*git.Commit, error) {
 	m.ctrl.T.Helper()
@@ -124,3 +140,32 @@ func (mr *MockHelperMockRecorder) ListTag(ctx, gitURL, params interface{}) *gomo
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListTag", reflect.TypeOf((*MockHelper)(nil).ListTag), ctx, gitURL, params)
 }
+
+// ParsePushEvent mocks base method.
+func (m *MockHelper) ParsePushEvent(header http.Header, body []byte) (*git.PushEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ParsePushEvent", header, body)
+	ret0, _ := ret[0].(*git.PushEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ParsePushEvent indicates an expected call of ParsePushEvent.
+func (mr *MockHelperMockRecorder) ParsePushEvent(header, body interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ParsePushEvent", reflect.TypeOf((*MockHelper)(nil).ParsePushEvent), header, body)
+}
+
+// VerifyWebhook mocks base method.
+func (m *MockHelper) VerifyWebhook(header http.Header, body []byte, secret string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "VerifyWebhook", header, body, secret)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// VerifyWebhook indicates an expected call of VerifyWebhook.
+func (mr *MockHelperMockRecorder) VerifyWebhook(header, body, secret interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VerifyWebhook", reflect.TypeOf((*MockHelper)(nil).VerifyWebhook), header, body, secret)
+}
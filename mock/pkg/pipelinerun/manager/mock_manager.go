@@ -190,6 +190,50 @@ func (mr *MockManagerMockRecorder) GetLatestSuccessByClusterID(ctx, clusterID in
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLatestSuccessByClusterID", reflect.TypeOf((*MockManager)(nil).GetLatestSuccessByClusterID), ctx, clusterID)
 }
 
+// ListBuilds mocks base method.
+func (m *MockManager) ListBuilds(ctx context.Context, gitURL, subfolder, commit, buildConfigHash string, limit int) ([]*models.Pipelinerun, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListBuilds", ctx, gitURL, subfolder, commit, buildConfigHash, limit)
+	ret0, _ := ret[0].([]*models.Pipelinerun)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListBuilds indicates an expected call of ListBuilds.
+func (mr *MockManagerMockRecorder) ListBuilds(ctx, gitURL, subfolder, commit, buildConfigHash, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListBuilds", reflect.TypeOf((*MockManager)(nil).ListBuilds), ctx, gitURL, subfolder, commit, buildConfigHash, limit)
+}
+
+// ListByStatus mocks base method.
+func (m *MockManager) ListByStatus(ctx context.Context, status models.PipelineStatus) ([]*models.Pipelinerun, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListByStatus", ctx, status)
+	ret0, _ := ret[0].([]*models.Pipelinerun)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListByStatus indicates an expected call of ListByStatus.
+func (mr *MockManagerMockRecorder) ListByStatus(ctx, status interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListByStatus", reflect.TypeOf((*MockManager)(nil).ListByStatus), ctx, status)
+}
+
+// UpdateByID mocks base method.
+func (m *MockManager) UpdateByID(ctx context.Context, pipelinerunID uint, pipelinerun *models.Pipelinerun) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateByID", ctx, pipelinerunID, pipelinerun)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateByID indicates an expected call of UpdateByID.
+func (mr *MockManagerMockRecorder) UpdateByID(ctx, pipelinerunID, pipelinerun interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateByID", reflect.TypeOf((*MockManager)(nil).UpdateByID), ctx, pipelinerunID, pipelinerun)
+}
+
 // UpdateCIEventIDByID mocks base method.
 func (m *MockManager) UpdateCIEventIDByID(ctx context.Context, pipelinerunID uint, ciEventID string) error {
 	m.ctrl.T.Helper()
@@ -232,6 +276,20 @@ func (mr *MockManagerMockRecorder) UpdateResultByID(ctx, pipelinerunID, result i
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateResultByID", reflect.TypeOf((*MockManager)(nil).UpdateResultByID), ctx, pipelinerunID, result)
 }
 
+// UpdateReviewByID mocks base method.
+func (m *MockManager) UpdateReviewByID(ctx context.Context, pipelinerunID uint, status models.PipelineStatus, reviewedBy uint, comment string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateReviewByID", ctx, pipelinerunID, status, reviewedBy, comment)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateReviewByID indicates an expected call of UpdateReviewByID.
+func (mr *MockManagerMockRecorder) UpdateReviewByID(ctx, pipelinerunID, status, reviewedBy, comment interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateReviewByID", reflect.TypeOf((*MockManager)(nil).UpdateReviewByID), ctx, pipelinerunID, status, reviewedBy, comment)
+}
+
 // UpdateStatusByID mocks base method.
 func (m *MockManager) UpdateStatusByID(ctx context.Context, pipelinerunID uint, result models.PipelineStatus) error {
 	m.ctrl.T.Helper()
@@ -245,3 +303,18 @@ func (mr *MockManagerMockRecorder) UpdateStatusByID(ctx, pipelinerunID, result i
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateStatusByID", reflect.TypeOf((*MockManager)(nil).UpdateStatusByID), ctx, pipelinerunID, result)
 }
+
+// UpdateStatusByIDAndStatus mocks base method.
+func (m *MockManager) UpdateStatusByIDAndStatus(ctx context.Context, pipelinerunID uint, status, newStatus models.PipelineStatus) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateStatusByIDAndStatus", ctx, pipelinerunID, status, newStatus)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateStatusByIDAndStatus indicates an expected call of UpdateStatusByIDAndStatus.
+func (mr *MockManagerMockRecorder) UpdateStatusByIDAndStatus(ctx, pipelinerunID, status, newStatus interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateStatusByIDAndStatus", reflect.TypeOf((*MockManager)(nil).UpdateStatusByIDAndStatus), ctx, pipelinerunID, status, newStatus)
+}
@@ -0,0 +1,51 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: migration.go
+
+// Package mock_migration is a generated GoMock package.
+package mock_migration
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	migration "github.com/horizoncd/horizon/pkg/templaterelease/migration"
+)
+
+// MockGetter is a mock of Getter interface.
+type MockGetter struct {
+	ctrl     *gomock.Controller
+	recorder *MockGetterMockRecorder
+}
+
+// MockGetterMockRecorder is the mock recorder for MockGetter.
+type MockGetterMockRecorder struct {
+	mock *MockGetter
+}
+
+// NewMockGetter creates a new mock instance.
+func NewMockGetter(ctrl *gomock.Controller) *MockGetter {
+	mock := &MockGetter{ctrl: ctrl}
+	mock.recorder = &MockGetterMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockGetter) EXPECT() *MockGetterMockRecorder {
+	return m.recorder
+}
+
+// GetTemplateMigration mocks base method.
+func (m *MockGetter) GetTemplateMigration(ctx context.Context, templateName, releaseName string) (*migration.Spec, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTemplateMigration", ctx, templateName, releaseName)
+	ret0, _ := ret[0].(*migration.Spec)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTemplateMigration indicates an expected call of GetTemplateMigration.
+func (mr *MockGetterMockRecorder) GetTemplateMigration(ctx, templateName, releaseName interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTemplateMigration", reflect.TypeOf((*MockGetter)(nil).GetTemplateMigration), ctx, templateName, releaseName)
+}
@@ -79,6 +79,21 @@ func (mr *MockTemplateRepoMockRecorder) GetChart(name, version, lastSyncAt inter
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetChart", reflect.TypeOf((*MockTemplateRepo)(nil).GetChart), name, version, lastSyncAt)
 }
 
+// GetChartDigest mocks base method.
+func (m *MockTemplateRepo) GetChartDigest(name, version string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetChartDigest", name, version)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetChartDigest indicates an expected call of GetChartDigest.
+func (mr *MockTemplateRepoMockRecorder) GetChartDigest(name, version interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetChartDigest", reflect.TypeOf((*MockTemplateRepo)(nil).GetChartDigest), name, version)
+}
+
 // GetLoc mocks base method.
 func (m *MockTemplateRepo) GetLoc() string {
 	m.ctrl.T.Helper()
@@ -94,11 +109,12 @@ func (mr *MockTemplateRepoMockRecorder) GetLoc() *gomock.Call {
 }
 
 // UploadChart mocks base method.
-func (m *MockTemplateRepo) UploadChart(chart *chart.Chart) error {
+func (m *MockTemplateRepo) UploadChart(chart *chart.Chart) (string, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "UploadChart", chart)
-	ret0, _ := ret[0].(error)
-	return ret0
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
 // UploadChart indicates an expected call of UploadChart.
@@ -52,6 +52,10 @@ type ApplicationSource struct {
 	TargetRevision string `json:"targetRevision" yaml:"targetRevision,omitempty"`
 	// Helm holds helm specific options
 	Helm *ApplicationSourceHelm `json:"helm" yaml:"helm,omitempty"`
+	// Kustomize holds kustomize specific options
+	Kustomize *ApplicationSourceKustomize `json:"kustomize,omitempty" yaml:"kustomize,omitempty"`
+	// Directory holds path/directory specific options
+	Directory *ApplicationSourceDirectory `json:"directory,omitempty" yaml:"directory,omitempty"`
 }
 
 // ApplicationSourceHelm holds helm specific options
@@ -60,6 +64,19 @@ type ApplicationSourceHelm struct {
 	ValueFiles []string `json:"valueFiles" yaml:"valueFiles,omitempty"`
 }
 
+// ApplicationSourceKustomize holds kustomize specific options,
+// an empty one is set to make argoCD build the source with kustomize
+type ApplicationSourceKustomize struct {
+	// Version controls which version of Kustomize to use for rendering manifests
+	Version string `json:"version,omitempty" yaml:"version,omitempty"`
+}
+
+// ApplicationSourceDirectory holds options for a source of plain manifests
+type ApplicationSourceDirectory struct {
+	// Recurse specifies whether to scan a directory recursively for manifests
+	Recurse bool `json:"recurse" yaml:"recurse"`
+}
+
 // SyncPolicy controls when a sync will be performed in response to updates in git
 type SyncPolicy struct {
 	// Options allow you to specify whole app sync-options
@@ -31,6 +31,7 @@ import (
 	"github.com/horizoncd/horizon/core/common"
 	herrors "github.com/horizoncd/horizon/core/errors"
 	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/templaterepo"
 	"github.com/horizoncd/horizon/pkg/util/errors"
 	"github.com/horizoncd/horizon/pkg/util/log"
 	"github.com/horizoncd/horizon/pkg/util/wlog"
@@ -49,9 +50,10 @@ var (
 type (
 	// ArgoCD interact with ArgoCD Server
 	ArgoCD interface {
-		// AssembleArgoApplication assemble application by params
+		// AssembleArgoApplication assemble application by params,
+		// the source is built according to the kind of cluster's template
 		AssembleArgoApplication(name, namespace, gitRepoURL, server string,
-			valueFiles []string, targetRevision string) *Application
+			valueFiles []string, targetRevision, templateKind string) *Application
 
 		// CreateApplication create an application in argoCD
 		CreateApplication(ctx context.Context, manifest []byte) error
@@ -81,6 +83,9 @@ type (
 		GetApplicationResource(ctx context.Context, application string,
 			param ResourceParams, resource interface{}) error
 
+		// GetManagedResources get the desired and live states of resources managed by an application in argoCD
+		GetManagedResources(ctx context.Context, application string) ([]*v1alpha1.ResourceDiff, error)
+
 		// ListResourceEvents get resource's events of an application in argoCD
 		ListResourceEvents(ctx context.Context, application string, param EventParam) (*corev1.EventList, error)
 
@@ -130,6 +135,11 @@ type (
 		TailLines     int    `json:"tailLines,omitempty" yaml:"tailLines,omitempty"`
 	}
 
+	// ManagedResourcesResponse the response of managed-resources api
+	ManagedResourcesResponse struct {
+		Items []*v1alpha1.ResourceDiff `json:"items,omitempty"`
+	}
+
 	ContainerLog struct {
 		Result struct {
 			Content   string `json:"content,omitempty" yaml:"content,omitempty"`
@@ -198,7 +208,7 @@ var (
 )
 
 func (h *helper) AssembleArgoApplication(name, namespace, gitRepoURL, server string,
-	valueFiles []string, targetRevision string) *Application {
+	valueFiles []string, targetRevision, templateKind string) *Application {
 	const finalizer = "resources-finalizer.argocd.argoproj.io"
 	const apiVersion = "argoproj.io/v1alpha1"
 	const kind = "Application"
@@ -213,14 +223,7 @@ func (h *helper) AssembleArgoApplication(name, namespace, gitRepoURL, server str
 			Namespace:  h.Namespace,
 		},
 		Spec: ApplicationSpec{
-			Source: ApplicationSource{
-				RepoURL:        gitRepoURL,
-				Path:           ".",
-				TargetRevision: targetRevision,
-				Helm: &ApplicationSourceHelm{
-					ValueFiles: valueFiles,
-				},
-			},
+			Source: assembleSource(gitRepoURL, valueFiles, targetRevision, templateKind),
 			Destination: ApplicationDestination{
 				Server:    server,
 				Namespace: namespace,
@@ -233,6 +236,27 @@ func (h *helper) AssembleArgoApplication(name, namespace, gitRepoURL, server str
 	}
 }
 
+func assembleSource(gitRepoURL string, valueFiles []string,
+	targetRevision, templateKind string) ApplicationSource {
+	source := ApplicationSource{
+		RepoURL:        gitRepoURL,
+		Path:           ".",
+		TargetRevision: targetRevision,
+	}
+	switch templateKind {
+	case templaterepo.KindKustomize:
+		source.Kustomize = &ApplicationSourceKustomize{}
+	case templaterepo.KindRaw:
+		source.Path = common.GitopsDirTemplate
+		source.Directory = &ApplicationSourceDirectory{Recurse: true}
+	default:
+		source.Helm = &ApplicationSourceHelm{
+			ValueFiles: valueFiles,
+		}
+	}
+	return source
+}
+
 func (h *helper) CreateApplication(ctx context.Context, manifest []byte) (err error) {
 	const op = "argo: create application"
 	defer wlog.Start(ctx, op).StopPrint()
@@ -423,6 +447,38 @@ func (h *helper) GetApplicationTree(ctx context.Context, application string) (
 	return tree, nil
 }
 
+func (h *helper) GetManagedResources(ctx context.Context,
+	application string) (resources []*v1alpha1.ResourceDiff, err error) {
+	const op = "argo: get managed resources"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	url := fmt.Sprintf("%v/api/v1/applications/%v/managed-resources", h.URL, application)
+	resp, err := h.sendHTTPRequest(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		if resp.StatusCode == http.StatusNotFound {
+			return nil, herrors.NewErrNotFound(herrors.ApplicationInArgo,
+				fmt.Sprintf("application not found for url %s", url))
+		}
+		return nil, perror.Wrap(herrors.ErrHTTPRespNotAsExpected, common.Response(ctx, resp))
+	}
+
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, perror.Wrap(herrors.ErrReadFailed, err.Error())
+	}
+
+	var managed ManagedResourcesResponse
+	if err = json.Unmarshal(data, &managed); err != nil {
+		return nil, perror.Wrap(herrors.ErrParamInvalid, err.Error())
+	}
+	return managed.Items, nil
+}
+
 func (h *helper) GetApplicationResource(ctx context.Context, application string,
 	gvk ResourceParams, resource interface{}) (err error) {
 	const op = "argo: get application resource"
@@ -29,6 +29,7 @@ import (
 	herrors "github.com/horizoncd/horizon/core/errors"
 	"github.com/horizoncd/horizon/pkg/argocd/mock"
 	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/templaterepo"
 	"github.com/stretchr/testify/assert"
 	"k8s.io/kubernetes/pkg/apis/apps"
 
@@ -149,6 +150,49 @@ func TestGetApplication(t *testing.T) {
 	}
 }
 
+func TestGetManagedResources(t *testing.T) {
+	ctx := log.WithContext(context.Background(), "TestGetManagedResources")
+
+	_, err := _argoClient.GetManagedResources(ctx, _application)
+	_, ok := perror.Cause(err).(*herrors.HorizonErrNotFound)
+	assert.True(t, ok)
+
+	if err := _argoClient.CreateApplication(ctx, _applicationManifest); err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = _argoClient.DeleteApplication(ctx, _application) }()
+
+	resources, err := _argoClient.GetManagedResources(ctx, _application)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(resources))
+	assert.Equal(t, "Deployment", resources[0].Kind)
+	assert.Equal(t, "deployment", resources[0].Name)
+	assert.Equal(t, `{"apiVersion":"apps/v1","kind":"Deployment"}`, resources[0].TargetState)
+}
+
+func TestAssembleArgoApplication(t *testing.T) {
+	valueFiles := []string{"application.yaml", "system/env.yaml"}
+	helmApp := _argoClient.AssembleArgoApplication(_application, "test-1", "https://gitlab.com/a.git",
+		"https://kubernetes.default.svc", valueFiles, "master", "")
+	assert.Equal(t, ".", helmApp.Spec.Source.Path)
+	assert.Equal(t, valueFiles, helmApp.Spec.Source.Helm.ValueFiles)
+	assert.Nil(t, helmApp.Spec.Source.Kustomize)
+
+	kustomizeApp := _argoClient.AssembleArgoApplication(_application, "test-1", "https://gitlab.com/a.git",
+		"https://kubernetes.default.svc", valueFiles, "master", templaterepo.KindKustomize)
+	assert.Equal(t, ".", kustomizeApp.Spec.Source.Path)
+	assert.Nil(t, kustomizeApp.Spec.Source.Helm)
+	b, err := json.Marshal(kustomizeApp.Spec.Source)
+	assert.Nil(t, err)
+	assert.True(t, strings.Contains(string(b), `"kustomize":{}`))
+
+	rawApp := _argoClient.AssembleArgoApplication(_application, "test-1", "https://gitlab.com/a.git",
+		"https://kubernetes.default.svc", valueFiles, "master", templaterepo.KindRaw)
+	assert.Equal(t, "template", rawApp.Spec.Source.Path)
+	assert.Nil(t, rawApp.Spec.Source.Helm)
+	assert.True(t, rawApp.Spec.Source.Directory.Recurse)
+}
+
 func TestDeleteApplication_Duplicate(t *testing.T) {
 	ctx := log.WithContext(context.Background(), "TestApplication")
 
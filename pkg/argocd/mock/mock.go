@@ -76,6 +76,8 @@ func NewArgoServer() *ArgoServer {
 		HandlerFunc(c.GetApplication)
 	r.Path("/api/v1/applications/{application}/resource-tree").Methods(http.MethodGet).
 		HandlerFunc(c.GetApplicationTree)
+	r.Path("/api/v1/applications/{application}/managed-resources").Methods(http.MethodGet).
+		HandlerFunc(c.GetManagedResources)
 	r.Path("/api/v1/applications/{application}/resource").Methods(http.MethodGet).
 		Queries("namespace", "{namespace}", "resourceName", "{resourceName}",
 			"group", "{group}", "version", "{version}", "kind", "{kind}").
@@ -333,6 +335,32 @@ func (argoServer *ArgoServer) GetApplicationTree(w http.ResponseWriter, r *http.
 	_, _ = w.Write(d)
 }
 
+func (argoServer *ArgoServer) GetManagedResources(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	application := vars["application"]
+
+	if argoServer.Applications[application] == nil {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	d := []byte(`
+{
+  "items": [
+    {
+      "group": "apps",
+      "kind": "Deployment",
+      "namespace": "test-1",
+      "name": "deployment",
+      "targetState": "{\"apiVersion\":\"apps/v1\",\"kind\":\"Deployment\"}",
+      "liveState": "{\"apiVersion\":\"apps/v1\",\"kind\":\"Deployment\"}"
+    }
+  ]
+}
+`)
+	_, _ = w.Write(d)
+}
+
 func (argoServer *ArgoServer) GetApplicationResource(w http.ResponseWriter, r *http.Request) {
 	var deployment = apps.Deployment{
 		ObjectMeta: metav1.ObjectMeta{
@@ -0,0 +1,58 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audit
+
+import (
+	"context"
+	"sync"
+)
+
+// ContextAnnotations is the key of the annotations of the audit log in the context of a request
+const ContextAnnotations = "auditAnnotations"
+
+// Annotations are the details attached to the audit log of a request by controllers
+type Annotations struct {
+	mu     sync.Mutex
+	values map[string][]string
+}
+
+func NewAnnotations() *Annotations {
+	return &Annotations{values: map[string][]string{}}
+}
+
+func (a *Annotations) Add(key, value string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.values[key] = append(a.values[key], value)
+}
+
+// Values returns a copy of the annotations
+func (a *Annotations) Values() map[string][]string {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	values := make(map[string][]string, len(a.values))
+	for k, v := range a.values {
+		values[k] = append([]string(nil), v...)
+	}
+	return values
+}
+
+// Annotate attaches the value to the audit log of the request in ctx,
+// it does nothing if the request is not audited, e.g. in jobs running in background
+func Annotate(ctx context.Context, key, value string) {
+	if annotations, ok := ctx.Value(ContextAnnotations).(*Annotations); ok {
+		annotations.Add(key, value)
+	}
+}
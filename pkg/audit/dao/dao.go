@@ -0,0 +1,115 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dao
+
+import (
+	"context"
+	"time"
+
+	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/lib/q"
+	"github.com/horizoncd/horizon/pkg/audit/models"
+	"gorm.io/gorm"
+)
+
+type DAO interface {
+	Create(ctx context.Context, auditLog *models.AuditLog) error
+	// List lists audit logs matched in descending order of id
+	List(ctx context.Context, query *q.Query) ([]*models.AuditLog, int64, error)
+	// ListAfter lists at most limit audit logs matched whose id is greater than startID in ascending order of id
+	ListAfter(ctx context.Context, query *q.Query, startID uint, limit int) ([]*models.AuditLog, error)
+	// DeleteBefore deletes the audit logs created before the time
+	DeleteBefore(ctx context.Context, before time.Time) (int64, error)
+}
+
+type dao struct{ db *gorm.DB }
+
+func NewDAO(db *gorm.DB) DAO {
+	return &dao{db: db}
+}
+
+func (d *dao) Create(ctx context.Context, auditLog *models.AuditLog) error {
+	if result := d.db.WithContext(ctx).Create(auditLog); result.Error != nil {
+		return herrors.NewErrInsertFailed(herrors.AuditLogInDB, result.Error.Error())
+	}
+	return nil
+}
+
+func (d *dao) filter(ctx context.Context, query *q.Query) *gorm.DB {
+	statement := d.db.WithContext(ctx).Model(&models.AuditLog{})
+	if query == nil {
+		return statement
+	}
+	for k, v := range query.Keywords {
+		switch k {
+		case common.AuditQueryActor:
+			statement = statement.Where("actor_name = ?", v)
+		case common.AuditQueryActorType:
+			statement = statement.Where("actor_type = ?", v)
+		case common.AuditQueryResource:
+			statement = statement.Where("resource = ?", v)
+		case common.AuditQueryResourceName:
+			statement = statement.Where("resource_name = ?", v)
+		case common.AuditQueryDecision:
+			statement = statement.Where("decision = ?", v)
+		case common.AuditQueryMethod:
+			statement = statement.Where("method = ?", v)
+		case common.ReqID:
+			statement = statement.Where("req_id = ?", v)
+		case common.AuditQueryStartTime:
+			statement = statement.Where("created_at >= ?", v)
+		case common.AuditQueryEndTime:
+			statement = statement.Where("created_at < ?", v)
+		}
+	}
+	return statement
+}
+
+func (d *dao) List(ctx context.Context, query *q.Query) ([]*models.AuditLog, int64, error) {
+	var (
+		auditLogs []*models.AuditLog
+		total     int64
+	)
+	statement := d.filter(ctx, query)
+	if result := statement.Count(&total); result.Error != nil {
+		return nil, 0, herrors.NewErrGetFailed(herrors.AuditLogInDB, result.Error.Error())
+	}
+	if query != nil {
+		statement = statement.Offset(query.Offset()).Limit(query.Limit())
+	}
+	if result := statement.Order("id desc").Find(&auditLogs); result.Error != nil {
+		return nil, 0, herrors.NewErrGetFailed(herrors.AuditLogInDB, result.Error.Error())
+	}
+	return auditLogs, total, nil
+}
+
+func (d *dao) ListAfter(ctx context.Context, query *q.Query, startID uint, limit int) ([]*models.AuditLog, error) {
+	var auditLogs []*models.AuditLog
+	result := d.filter(ctx, query).Where("id > ?", startID).
+		Order("id asc").Limit(limit).Find(&auditLogs)
+	if result.Error != nil {
+		return nil, herrors.NewErrGetFailed(herrors.AuditLogInDB, result.Error.Error())
+	}
+	return auditLogs, nil
+}
+
+func (d *dao) DeleteBefore(ctx context.Context, before time.Time) (int64, error) {
+	result := d.db.WithContext(ctx).Where("created_at < ?", before).Delete(&models.AuditLog{})
+	if result.Error != nil {
+		return 0, herrors.NewErrDeleteFailed(herrors.AuditLogInDB, result.Error.Error())
+	}
+	return result.RowsAffected, nil
+}
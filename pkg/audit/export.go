@@ -0,0 +1,163 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audit
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/horizoncd/horizon/pkg/audit/models"
+)
+
+const (
+	// FormatJSONLines writes an audit log as a json object per line
+	FormatJSONLines = "jsonl"
+	// FormatSyslog writes an audit log as a syslog message of RFC 5424 per line
+	FormatSyslog = "syslog"
+)
+
+const (
+	_syslogAppName = "horizon"
+	_syslogMsgID   = "audit"
+	// _syslogSDID uses the private enterprise number reserved for documentation
+	_syslogSDID = "horizon@32473"
+	// facility log audit (13)
+	_syslogFacility        = 13
+	_syslogSeverityInfo    = 6
+	_syslogSeverityWarning = 4
+)
+
+// Entry is an audit log exported or returned by APIs
+type Entry struct {
+	ID           uint                `json:"id"`
+	ReqID        string              `json:"reqID"`
+	ActorType    string              `json:"actorType"`
+	ActorID      uint                `json:"actorID"`
+	ActorName    string              `json:"actorName"`
+	TokenID      uint                `json:"tokenID,omitempty"`
+	ClientID     string              `json:"clientID,omitempty"`
+	Method       string              `json:"method"`
+	Path         string              `json:"path"`
+	Verb         string              `json:"verb,omitempty"`
+	Resource     string              `json:"resource,omitempty"`
+	ResourceName string              `json:"resourceName,omitempty"`
+	SubResource  string              `json:"subResource,omitempty"`
+	Decision     string              `json:"decision"`
+	Reason       string              `json:"reason,omitempty"`
+	StatusCode   int                 `json:"statusCode"`
+	SourceIP     string              `json:"sourceIP"`
+	UserAgent    string              `json:"userAgent"`
+	Annotations  map[string][]string `json:"annotations,omitempty"`
+	CreatedAt    time.Time           `json:"createdAt"`
+}
+
+func OfAuditLog(l *models.AuditLog) *Entry {
+	entry := &Entry{
+		ID:           l.ID,
+		ReqID:        l.ReqID,
+		ActorType:    l.ActorType,
+		ActorID:      l.ActorID,
+		ActorName:    l.ActorName,
+		TokenID:      l.TokenID,
+		ClientID:     l.ClientID,
+		Method:       l.Method,
+		Path:         l.Path,
+		Verb:         l.Verb,
+		Resource:     l.Resource,
+		ResourceName: l.ResourceName,
+		SubResource:  l.SubResource,
+		Decision:     l.Decision,
+		Reason:       l.Reason,
+		StatusCode:   l.StatusCode,
+		SourceIP:     l.SourceIP,
+		UserAgent:    l.UserAgent,
+		CreatedAt:    l.CreatedAt,
+	}
+	if annotations := l.GetAnnotations(); len(annotations) > 0 {
+		entry.Annotations = annotations
+	}
+	return entry
+}
+
+// Encoder writes audit logs in a format for SIEM to ingest
+type Encoder interface {
+	Encode(l *models.AuditLog) error
+}
+
+func NewEncoder(format string, w io.Writer) (Encoder, error) {
+	switch format {
+	case FormatJSONLines, "":
+		return &jsonLinesEncoder{encoder: json.NewEncoder(w)}, nil
+	case FormatSyslog:
+		hostname, err := os.Hostname()
+		if err != nil || hostname == "" {
+			hostname = "-"
+		}
+		return &syslogEncoder{w: w, hostname: hostname}, nil
+	default:
+		return nil, fmt.Errorf("unsupported format %s, should be one of %s and %s",
+			format, FormatJSONLines, FormatSyslog)
+	}
+}
+
+type jsonLinesEncoder struct {
+	encoder *json.Encoder
+}
+
+func (e *jsonLinesEncoder) Encode(l *models.AuditLog) error {
+	return e.encoder.Encode(OfAuditLog(l))
+}
+
+type syslogEncoder struct {
+	w        io.Writer
+	hostname string
+}
+
+// Encode writes <PRI>1 TIMESTAMP HOSTNAME APP-NAME PROCID MSGID [SD-ID params] MSG,
+// the key fields are in the structured data and the msg is the json of the whole log
+func (e *syslogEncoder) Encode(l *models.AuditLog) error {
+	severity := _syslogSeverityInfo
+	if l.Decision == models.DecisionDeny {
+		severity = _syslogSeverityWarning
+	}
+	msg, err := json.Marshal(OfAuditLog(l))
+	if err != nil {
+		return err
+	}
+	params := []string{
+		sdParam("reqID", l.ReqID),
+		sdParam("actorType", l.ActorType),
+		sdParam("actor", l.ActorName),
+		sdParam("method", l.Method),
+		sdParam("path", l.Path),
+		sdParam("decision", l.Decision),
+		sdParam("status", fmt.Sprint(l.StatusCode)),
+		sdParam("sourceIP", l.SourceIP),
+	}
+	_, err = fmt.Fprintf(e.w, "<%d>1 %s %s %s - %s [%s %s] %s\n",
+		_syslogFacility*8+severity, l.CreatedAt.UTC().Format(time.RFC3339Nano), e.hostname,
+		_syslogAppName, _syslogMsgID, _syslogSDID, strings.Join(params, " "), msg)
+	return err
+}
+
+var sdValueEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, `]`, `\]`)
+
+func sdParam(name, value string) string {
+	return fmt.Sprintf(`%s="%s"`, name, sdValueEscaper.Replace(value))
+}
@@ -0,0 +1,79 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/horizoncd/horizon/pkg/audit/models"
+)
+
+func TestEncoder(t *testing.T) {
+	l := &models.AuditLog{
+		ID:        1,
+		ReqID:     "abc",
+		ActorType: models.ActorRobot,
+		ActorName: `robot"]`,
+		TokenID:   2,
+		Method:    "POST",
+		Path:      "/apis/core/v2/clusters/3/deploy",
+		Decision:  models.DecisionDeny,
+		Reason:    "member not exist",
+		CreatedAt: time.Date(2023, 7, 16, 8, 0, 0, 0, time.UTC),
+	}
+	l.SetAnnotations(map[string][]string{"eventIDs": {"4"}})
+
+	buf := &bytes.Buffer{}
+	encoder, err := NewEncoder(FormatJSONLines, buf)
+	assert.Nil(t, err)
+	assert.Nil(t, encoder.Encode(l))
+	assert.Nil(t, encoder.Encode(l))
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	assert.Equal(t, 2, len(lines))
+	entry := Entry{}
+	assert.Nil(t, json.Unmarshal([]byte(lines[0]), &entry))
+	assert.Equal(t, *OfAuditLog(l), entry)
+
+	buf.Reset()
+	encoder, err = NewEncoder(FormatSyslog, buf)
+	assert.Nil(t, err)
+	assert.Nil(t, encoder.Encode(l))
+	hostname, _ := os.Hostname()
+	assert.True(t, strings.HasPrefix(buf.String(), "<108>1 2023-07-16T08:00:00Z "+hostname+
+		` horizon - audit [horizon@32473 reqID="abc" actorType="robot" actor="robot\"\]" method="POST"`))
+	assert.Contains(t, buf.String(), `] {"id":1,"reqID":"abc"`)
+
+	_, err = NewEncoder("csv", buf)
+	assert.NotNil(t, err)
+}
+
+func TestAnnotate(t *testing.T) {
+	// not audited
+	Annotate(context.TODO(), "eventIDs", "1")
+
+	annotations := NewAnnotations()
+	ctx := context.WithValue(context.TODO(), ContextAnnotations, annotations) // nolint
+	Annotate(ctx, "eventIDs", "1")
+	Annotate(ctx, "eventIDs", "2")
+	assert.Equal(t, map[string][]string{"eventIDs": {"1", "2"}}, annotations.Values())
+}
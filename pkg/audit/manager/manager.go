@@ -0,0 +1,83 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manager
+
+import (
+	"context"
+	"time"
+
+	"github.com/horizoncd/horizon/lib/q"
+	"github.com/horizoncd/horizon/pkg/audit/dao"
+	"github.com/horizoncd/horizon/pkg/audit/models"
+	"gorm.io/gorm"
+)
+
+// Manager manages the audit logs of the requests
+type Manager interface {
+	Create(ctx context.Context, auditLog *models.AuditLog) error
+	// List lists audit logs with pagination, the latest first
+	List(ctx context.Context, query *q.Query) ([]*models.AuditLog, int64, error)
+	// Iterate calls fn with the audit logs matched batch by batch in ascending order of id,
+	// it stops when all the logs are iterated or fn returns an error
+	Iterate(ctx context.Context, query *q.Query, batch int, fn func([]*models.AuditLog) error) error
+	// DeleteBefore deletes the audit logs created before the time
+	DeleteBefore(ctx context.Context, before time.Time) (int64, error)
+}
+
+type manager struct {
+	dao dao.DAO
+}
+
+func New(db *gorm.DB) Manager {
+	return &manager{
+		dao: dao.NewDAO(db),
+	}
+}
+
+func (m *manager) Create(ctx context.Context, auditLog *models.AuditLog) error {
+	return m.dao.Create(ctx, auditLog)
+}
+
+func (m *manager) List(ctx context.Context, query *q.Query) ([]*models.AuditLog, int64, error) {
+	return m.dao.List(ctx, query)
+}
+
+func (m *manager) Iterate(ctx context.Context, query *q.Query, batch int,
+	fn func([]*models.AuditLog) error) error {
+	cursor := uint(0)
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		auditLogs, err := m.dao.ListAfter(ctx, query, cursor, batch)
+		if err != nil {
+			return err
+		}
+		if len(auditLogs) == 0 {
+			return nil
+		}
+		if err := fn(auditLogs); err != nil {
+			return err
+		}
+		if len(auditLogs) < batch {
+			return nil
+		}
+		cursor = auditLogs[len(auditLogs)-1].ID
+	}
+}
+
+func (m *manager) DeleteBefore(ctx context.Context, before time.Time) (int64, error) {
+	return m.dao.DeleteBefore(ctx, before)
+}
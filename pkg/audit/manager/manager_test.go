@@ -0,0 +1,89 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manager
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/horizoncd/horizon/core/common"
+	"github.com/horizoncd/horizon/lib/orm"
+	"github.com/horizoncd/horizon/lib/q"
+	"github.com/horizoncd/horizon/pkg/audit/models"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	db, _ = orm.NewSqliteDB("")
+	ctx   = context.TODO()
+	mgr   = New(db)
+)
+
+func TestMain(m *testing.M) {
+	if err := db.AutoMigrate(&models.AuditLog{}); err != nil {
+		panic(err)
+	}
+	os.Exit(m.Run())
+}
+
+func TestManager(t *testing.T) {
+	now := time.Now()
+	for i, l := range []*models.AuditLog{
+		{ActorType: models.ActorUser, ActorName: "tom", Method: "POST", Resource: "clusters",
+			Decision: models.DecisionAllow, CreatedAt: now.Add(-48 * time.Hour)},
+		{ActorType: models.ActorRobot, ActorName: "robot", Method: "PUT", Resource: "clusters",
+			Decision: models.DecisionAllow, CreatedAt: now.Add(-time.Hour)},
+		{ActorType: models.ActorUser, ActorName: "tom", Method: "DELETE", Resource: "applications",
+			Decision: models.DecisionDeny, CreatedAt: now},
+	} {
+		l.ReqID = string(rune('a' + i))
+		assert.Nil(t, mgr.Create(ctx, l))
+	}
+
+	logs, total, err := mgr.List(ctx, q.New(q.KeyWords{common.AuditQueryActor: "tom"}))
+	assert.Nil(t, err)
+	assert.Equal(t, int64(2), total)
+	assert.Equal(t, "DELETE", logs[0].Method)
+
+	logs, total, err = mgr.List(ctx, &q.Query{
+		Keywords:   q.KeyWords{common.AuditQueryStartTime: now.Add(-2 * time.Hour)},
+		PageNumber: 1,
+		PageSize:   1,
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, int64(2), total)
+	assert.Equal(t, 1, len(logs))
+	assert.Equal(t, "c", logs[0].ReqID)
+
+	reqIDs := make([]string, 0)
+	err = mgr.Iterate(ctx, q.New(q.KeyWords{common.AuditQueryResource: "clusters"}), 1,
+		func(logs []*models.AuditLog) error {
+			for _, l := range logs {
+				reqIDs = append(reqIDs, l.ReqID)
+			}
+			return nil
+		})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"a", "b"}, reqIDs)
+
+	deleted, err := mgr.DeleteBefore(ctx, now.Add(-24*time.Hour))
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1), deleted)
+	_, total, err = mgr.List(ctx, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(2), total)
+}
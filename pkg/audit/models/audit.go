@@ -0,0 +1,91 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+const (
+	// ActorUser is a user logged in or authenticated by a personal access token
+	ActorUser = "user"
+	// ActorRobot is the robot user of a resource access token
+	ActorRobot = "robot"
+	// ActorOAuthApp is an oauth app acting on behalf of a user
+	ActorOAuthApp = "oauthapp"
+	// ActorAnonymous is the actor of the requests without any authentication
+	ActorAnonymous = "anonymous"
+)
+
+const (
+	DecisionAllow = "allow"
+	DecisionDeny  = "deny"
+	// DecisionNotChecked means the request is not checked by rbac, e.g. login or logout
+	DecisionNotChecked = "notchecked"
+)
+
+// AuditLog records who did what, when and from where
+type AuditLog struct {
+	ID    uint
+	ReqID string
+
+	ActorType string
+	ActorID   uint
+	ActorName string
+	// TokenID is the id of the access token used, it's 0 if the request is authenticated by session
+	TokenID uint
+	// ClientID is the client id of the oauth app
+	ClientID string
+
+	Method       string
+	Path         string
+	Verb         string
+	Resource     string
+	ResourceName string
+	SubResource  string
+
+	// Decision and Reason are made by rbac or the scopes of the access token
+	Decision   string
+	Reason     string
+	StatusCode int
+	SourceIP   string
+	UserAgent  string
+	// Annotations is the json of the details attached by controllers, e.g. ids of the events triggered
+	Annotations string
+
+	CreatedAt time.Time
+}
+
+func (AuditLog) TableName() string {
+	return "tb_audit_log"
+}
+
+func (l *AuditLog) GetAnnotations() map[string][]string {
+	annotations := map[string][]string{}
+	if l.Annotations != "" {
+		_ = json.Unmarshal([]byte(l.Annotations), &annotations)
+	}
+	return annotations
+}
+
+func (l *AuditLog) SetAnnotations(annotations map[string][]string) {
+	if len(annotations) == 0 {
+		l.Annotations = ""
+		return
+	}
+	b, _ := json.Marshal(annotations)
+	l.Annotations = string(b)
+}
@@ -0,0 +1,104 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dao
+
+import (
+	"context"
+	"time"
+
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/pkg/autodeploy/models"
+	"github.com/horizoncd/horizon/pkg/common"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type DAO interface {
+	GetByClusterID(ctx context.Context, clusterID uint) (*models.AutoDeploy, error)
+	ListByClusterIDs(ctx context.Context, clusterIDs []uint) ([]*models.AutoDeploy, error)
+	Upsert(ctx context.Context, autoDeploy *models.AutoDeploy) error
+	UpdateResultByID(ctx context.Context, id uint, triggeredAt time.Time, commit string,
+		pipelinerunID uint, message string) error
+	DeleteByClusterID(ctx context.Context, clusterID uint) error
+}
+
+type dao struct {
+	db *gorm.DB
+}
+
+func NewDAO(db *gorm.DB) DAO {
+	return &dao{db: db}
+}
+
+func (d *dao) GetByClusterID(ctx context.Context, clusterID uint) (*models.AutoDeploy, error) {
+	var autoDeploy models.AutoDeploy
+	result := d.db.WithContext(ctx).Raw(common.AutoDeployGetByClusterID, clusterID).Scan(&autoDeploy)
+	if result.Error != nil {
+		return nil, herrors.NewErrGetFailed(herrors.AutoDeployInDB, result.Error.Error())
+	}
+	if result.RowsAffected == 0 {
+		return nil, herrors.NewErrNotFound(herrors.AutoDeployInDB, "auto deploy not found")
+	}
+	return &autoDeploy, nil
+}
+
+func (d *dao) ListByClusterIDs(ctx context.Context, clusterIDs []uint) ([]*models.AutoDeploy, error) {
+	var autoDeploys []*models.AutoDeploy
+	if len(clusterIDs) == 0 {
+		return autoDeploys, nil
+	}
+	result := d.db.WithContext(ctx).Where("cluster_id in ?", clusterIDs).Order("cluster_id asc").Find(&autoDeploys)
+	if result.Error != nil {
+		return nil, herrors.NewErrListFailed(herrors.AutoDeployInDB, result.Error.Error())
+	}
+	return autoDeploys, nil
+}
+
+// Upsert creates the auto deploy of the cluster, or updates its settings if it exists
+func (d *dao) Upsert(ctx context.Context, autoDeploy *models.AutoDeploy) error {
+	result := d.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "cluster_id"}},
+		DoUpdates: clause.AssignmentColumns(
+			[]string{"path_globs", "tag_pattern", "operator_id", "webhook_secret", "updated_by"}),
+	}).Create(autoDeploy)
+	if result.Error != nil {
+		return herrors.NewErrInsertFailed(herrors.AutoDeployInDB, result.Error.Error())
+	}
+	return nil
+}
+
+func (d *dao) UpdateResultByID(ctx context.Context, id uint, triggeredAt time.Time, commit string,
+	pipelinerunID uint, message string) error {
+	result := d.db.WithContext(ctx).Model(&models.AutoDeploy{}).Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"last_triggered_at":   triggeredAt,
+			"last_commit":         commit,
+			"last_pipelinerun_id": pipelinerunID,
+			"last_message":        message,
+		})
+	if result.Error != nil {
+		return herrors.NewErrUpdateFailed(herrors.AutoDeployInDB, result.Error.Error())
+	}
+	return nil
+}
+
+func (d *dao) DeleteByClusterID(ctx context.Context, clusterID uint) error {
+	result := d.db.WithContext(ctx).Where("cluster_id = ?", clusterID).Delete(&models.AutoDeploy{})
+	if result.Error != nil {
+		return herrors.NewErrDeleteFailed(herrors.AutoDeployInDB, result.Error.Error())
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manager
+
+import (
+	"context"
+	"time"
+
+	"github.com/horizoncd/horizon/pkg/autodeploy/dao"
+	"github.com/horizoncd/horizon/pkg/autodeploy/models"
+	"github.com/horizoncd/horizon/pkg/util/wlog"
+	"gorm.io/gorm"
+)
+
+type Manager interface {
+	GetByClusterID(ctx context.Context, clusterID uint) (*models.AutoDeploy, error)
+	// ListByClusterIDs lists the auto deploys of the clusters which opted into it
+	ListByClusterIDs(ctx context.Context, clusterIDs []uint) ([]*models.AutoDeploy, error)
+	// Upsert creates the auto deploy of the cluster, or updates its path globs, tag pattern, operator and secret
+	Upsert(ctx context.Context, autoDeploy *models.AutoDeploy) (*models.AutoDeploy, error)
+	// UpdateResultByID records the last push of the cluster, and the pipelinerun created or why it's not created
+	UpdateResultByID(ctx context.Context, id uint, triggeredAt time.Time, commit string,
+		pipelinerunID uint, message string) error
+	DeleteByClusterID(ctx context.Context, clusterID uint) error
+}
+
+type manager struct {
+	dao dao.DAO
+}
+
+func New(db *gorm.DB) Manager {
+	return &manager{
+		dao: dao.NewDAO(db),
+	}
+}
+
+func (m *manager) GetByClusterID(ctx context.Context, clusterID uint) (*models.AutoDeploy, error) {
+	const op = "auto deploy manager: get by cluster id"
+	defer wlog.Start(ctx, op).StopPrint()
+	return m.dao.GetByClusterID(ctx, clusterID)
+}
+
+func (m *manager) ListByClusterIDs(ctx context.Context, clusterIDs []uint) ([]*models.AutoDeploy, error) {
+	const op = "auto deploy manager: list by cluster ids"
+	defer wlog.Start(ctx, op).StopPrint()
+	return m.dao.ListByClusterIDs(ctx, clusterIDs)
+}
+
+func (m *manager) Upsert(ctx context.Context, autoDeploy *models.AutoDeploy) (*models.AutoDeploy, error) {
+	const op = "auto deploy manager: upsert"
+	defer wlog.Start(ctx, op).StopPrint()
+	if err := m.dao.Upsert(ctx, autoDeploy); err != nil {
+		return nil, err
+	}
+	return m.dao.GetByClusterID(ctx, autoDeploy.ClusterID)
+}
+
+func (m *manager) UpdateResultByID(ctx context.Context, id uint, triggeredAt time.Time, commit string,
+	pipelinerunID uint, message string) error {
+	const op = "auto deploy manager: update result by id"
+	defer wlog.Start(ctx, op).StopPrint()
+	return m.dao.UpdateResultByID(ctx, id, triggeredAt, commit, pipelinerunID, message)
+}
+
+func (m *manager) DeleteByClusterID(ctx context.Context, clusterID uint) error {
+	const op = "auto deploy manager: delete by cluster id"
+	defer wlog.Start(ctx, op).StopPrint()
+	return m.dao.DeleteByClusterID(ctx, clusterID)
+}
@@ -0,0 +1,80 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manager
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/lib/orm"
+	"github.com/horizoncd/horizon/pkg/autodeploy/models"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	db, _ = orm.NewSqliteDB("")
+	ctx   context.Context
+	mgr   = New(db)
+)
+
+func TestMain(m *testing.M) {
+	if err := db.AutoMigrate(&models.AutoDeploy{}); err != nil {
+		panic(err)
+	}
+	ctx = context.TODO()
+	os.Exit(m.Run())
+}
+
+func Test(t *testing.T) {
+	_, err := mgr.GetByClusterID(ctx, 1)
+	_, ok := perror.Cause(err).(*herrors.HorizonErrNotFound)
+	assert.True(t, ok)
+
+	autoDeploy := &models.AutoDeploy{ClusterID: 1, OperatorID: 1, CreatedBy: 1, UpdatedBy: 1}
+	autoDeploy.SetPathGlobs([]string{"services/api/**"})
+	created, err := mgr.Upsert(ctx, autoDeploy)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"services/api/**"}, created.GetPathGlobs())
+	_, err = mgr.Upsert(ctx, &models.AutoDeploy{ClusterID: 2, TagPattern: "v*", OperatorID: 1})
+	assert.Nil(t, err)
+
+	now := time.Now()
+	assert.Nil(t, mgr.UpdateResultByID(ctx, created.ID, now, "abc", 3, ""))
+
+	// upserting keeps the result of the last push
+	updated, err := mgr.Upsert(ctx, &models.AutoDeploy{ClusterID: 1, OperatorID: 2, UpdatedBy: 2})
+	assert.Nil(t, err)
+	assert.Equal(t, created.ID, updated.ID)
+	assert.Equal(t, 0, len(updated.GetPathGlobs()))
+	assert.Equal(t, uint(2), updated.OperatorID)
+	assert.Equal(t, uint(1), updated.CreatedBy)
+	assert.Equal(t, "abc", updated.LastCommit)
+	assert.Equal(t, uint(3), updated.LastPipelinerunID)
+
+	autoDeploys, err := mgr.ListByClusterIDs(ctx, []uint{1, 2, 3})
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(autoDeploys))
+	assert.Equal(t, "v*", autoDeploys[1].TagPattern)
+
+	assert.Nil(t, mgr.DeleteByClusterID(ctx, 1))
+	_, err = mgr.GetByClusterID(ctx, 1)
+	_, ok = perror.Cause(err).(*herrors.HorizonErrNotFound)
+	assert.True(t, ok)
+}
@@ -0,0 +1,62 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// AutoDeploy opts a cluster into being built and deployed when its git ref is pushed
+type AutoDeploy struct {
+	ID        uint
+	ClusterID uint `gorm:"uniqueIndex:idx_cluster_id"`
+	// PathGlobs is the json of the globs the changed files must match, relative to the root of the repo
+	PathGlobs string
+	// TagPattern is the glob of the tags deploying the cluster if it builds from a tag, all tags if it's empty
+	TagPattern string
+	// OperatorID is the user whom the pipelineruns are created as
+	OperatorID uint
+	// WebhookSecret is the secret of the webhook in the repo sending pushes of the cluster,
+	// each cluster has its own, so the secret of a cluster cannot be used to deploy others
+	WebhookSecret     string
+	LastTriggeredAt   *time.Time
+	LastCommit        string
+	LastPipelinerunID uint
+	// LastMessage tells why the last push did not deploy the cluster, empty if it did
+	LastMessage string
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	CreatedBy   uint
+	UpdatedBy   uint
+}
+
+func (a *AutoDeploy) GetPathGlobs() []string {
+	var globs []string
+	if a.PathGlobs == "" {
+		return globs
+	}
+	_ = json.Unmarshal([]byte(a.PathGlobs), &globs)
+	return globs
+}
+
+func (a *AutoDeploy) SetPathGlobs(globs []string) {
+	if len(globs) == 0 {
+		a.PathGlobs = ""
+		return
+	}
+	bts, _ := json.Marshal(globs)
+	a.PathGlobs = string(bts)
+}
@@ -0,0 +1,152 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dao
+
+import (
+	"context"
+
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/lib/q"
+	"github.com/horizoncd/horizon/pkg/batchjob/models"
+	"github.com/horizoncd/horizon/pkg/common"
+
+	"gorm.io/gorm"
+)
+
+type DAO interface {
+	Create(ctx context.Context, job *models.BatchJob,
+		clusters []*models.BatchJobCluster) (*models.BatchJob, error)
+	GetByID(ctx context.Context, id uint) (*models.BatchJob, error)
+	ListByGroupID(ctx context.Context, groupID uint, query *q.Query) ([]*models.BatchJob, int64, error)
+	ListByStatus(ctx context.Context, status string) ([]*models.BatchJob, error)
+	UpdateStatusByID(ctx context.Context, id uint, statuses []string, job *models.BatchJob) (bool, error)
+	ListClustersByJobID(ctx context.Context, jobID uint) ([]*models.BatchJobCluster, error)
+	UpdateClusterByID(ctx context.Context, id uint, cluster *models.BatchJobCluster) error
+	UpdatePendingClustersByJobID(ctx context.Context, jobID uint, status, message string) error
+}
+
+type dao struct {
+	db *gorm.DB
+}
+
+func NewDAO(db *gorm.DB) DAO {
+	return &dao{db: db}
+}
+
+func (d *dao) Create(ctx context.Context, job *models.BatchJob,
+	clusters []*models.BatchJobCluster) (*models.BatchJob, error) {
+	err := d.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if result := tx.Create(job); result.Error != nil {
+			return herrors.NewErrInsertFailed(herrors.BatchJobInDB, result.Error.Error())
+		}
+		for _, cluster := range clusters {
+			cluster.JobID = job.ID
+		}
+		if len(clusters) == 0 {
+			return nil
+		}
+		if result := tx.Create(clusters); result.Error != nil {
+			return herrors.NewErrInsertFailed(herrors.BatchJobInDB, result.Error.Error())
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return job, nil
+}
+
+func (d *dao) GetByID(ctx context.Context, id uint) (*models.BatchJob, error) {
+	var job models.BatchJob
+	result := d.db.WithContext(ctx).Raw(common.BatchJobGetByID, id).Scan(&job)
+	if result.Error != nil {
+		return nil, herrors.NewErrGetFailed(herrors.BatchJobInDB, result.Error.Error())
+	}
+	if result.RowsAffected == 0 {
+		return nil, herrors.NewErrNotFound(herrors.BatchJobInDB, "batch job not found")
+	}
+	return &job, nil
+}
+
+func (d *dao) ListByGroupID(ctx context.Context, groupID uint,
+	query *q.Query) ([]*models.BatchJob, int64, error) {
+	var (
+		jobs  []*models.BatchJob
+		count int64
+	)
+	statement := d.db.WithContext(ctx).Model(&models.BatchJob{}).
+		Where("group_id = ?", groupID)
+	if result := statement.Count(&count); result.Error != nil {
+		return nil, 0, herrors.NewErrGetFailed(herrors.BatchJobInDB, result.Error.Error())
+	}
+	if query != nil {
+		statement = statement.Limit(query.Limit()).Offset(query.Offset())
+	}
+	if result := statement.Order("id desc").Find(&jobs); result.Error != nil {
+		return nil, 0, herrors.NewErrGetFailed(herrors.BatchJobInDB, result.Error.Error())
+	}
+	return jobs, count, nil
+}
+
+func (d *dao) ListByStatus(ctx context.Context, status string) ([]*models.BatchJob, error) {
+	var jobs []*models.BatchJob
+	result := d.db.WithContext(ctx).Raw(common.BatchJobListByStatus, status).Scan(&jobs)
+	if result.Error != nil {
+		return nil, herrors.NewErrGetFailed(herrors.BatchJobInDB, result.Error.Error())
+	}
+	return jobs, nil
+}
+
+// UpdateStatusByID updates the status of the job only if its status is one of statuses,
+// so that cancelling and finishing the job do not override each other
+func (d *dao) UpdateStatusByID(ctx context.Context, id uint, statuses []string,
+	job *models.BatchJob) (bool, error) {
+	result := d.db.WithContext(ctx).Model(&models.BatchJob{}).
+		Where("id = ? and status in ?", id, statuses).
+		Select("Status", "Message", "UpdatedBy").
+		Updates(job)
+	if result.Error != nil {
+		return false, herrors.NewErrUpdateFailed(herrors.BatchJobInDB, result.Error.Error())
+	}
+	return result.RowsAffected > 0, nil
+}
+
+func (d *dao) ListClustersByJobID(ctx context.Context, jobID uint) ([]*models.BatchJobCluster, error) {
+	var clusters []*models.BatchJobCluster
+	result := d.db.WithContext(ctx).Raw(common.BatchJobListClustersByJobID, jobID).Scan(&clusters)
+	if result.Error != nil {
+		return nil, herrors.NewErrGetFailed(herrors.BatchJobInDB, result.Error.Error())
+	}
+	return clusters, nil
+}
+
+func (d *dao) UpdateClusterByID(ctx context.Context, id uint, cluster *models.BatchJobCluster) error {
+	result := d.db.WithContext(ctx).Model(&models.BatchJobCluster{}).Where("id = ?", id).
+		Select("PipelinerunID", "Status", "Message", "StartedAt", "FinishedAt").Updates(cluster)
+	if result.Error != nil {
+		return herrors.NewErrUpdateFailed(herrors.BatchJobInDB, result.Error.Error())
+	}
+	return nil
+}
+
+func (d *dao) UpdatePendingClustersByJobID(ctx context.Context, jobID uint, status, message string) error {
+	result := d.db.WithContext(ctx).Model(&models.BatchJobCluster{}).
+		Where("job_id = ? and status = ?", jobID, models.ClusterStatusPending).
+		Updates(map[string]interface{}{"status": status, "message": message})
+	if result.Error != nil {
+		return herrors.NewErrUpdateFailed(herrors.BatchJobInDB, result.Error.Error())
+	}
+	return nil
+}
@@ -0,0 +1,103 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manager
+
+import (
+	"context"
+
+	"github.com/horizoncd/horizon/lib/q"
+	"github.com/horizoncd/horizon/pkg/batchjob/dao"
+	"github.com/horizoncd/horizon/pkg/batchjob/models"
+	"github.com/horizoncd/horizon/pkg/util/wlog"
+	"gorm.io/gorm"
+)
+
+type Manager interface {
+	// Create creates the job together with the clusters it operates
+	Create(ctx context.Context, job *models.BatchJob,
+		clusters []*models.BatchJobCluster) (*models.BatchJob, error)
+	GetByID(ctx context.Context, id uint) (*models.BatchJob, error)
+	ListByGroupID(ctx context.Context, groupID uint, query *q.Query) ([]*models.BatchJob, int64, error)
+	ListRunning(ctx context.Context) ([]*models.BatchJob, error)
+	// UpdateStatusByID updates the status, message and updater of the job if its status is one of statuses,
+	// false is returned if the job is not in these statuses
+	UpdateStatusByID(ctx context.Context, id uint, statuses []string, job *models.BatchJob) (bool, error)
+	// ListClustersByJobID lists the clusters of the job in the order they are operated
+	ListClustersByJobID(ctx context.Context, jobID uint) ([]*models.BatchJobCluster, error)
+	UpdateClusterByID(ctx context.Context, id uint, cluster *models.BatchJobCluster) error
+	// SkipPendingClusters marks the clusters of the job which are not started yet as skipped
+	SkipPendingClusters(ctx context.Context, jobID uint, message string) error
+}
+
+type manager struct {
+	dao dao.DAO
+}
+
+func New(db *gorm.DB) Manager {
+	return &manager{
+		dao: dao.NewDAO(db),
+	}
+}
+
+func (m *manager) Create(ctx context.Context, job *models.BatchJob,
+	clusters []*models.BatchJobCluster) (*models.BatchJob, error) {
+	const op = "batch job manager: create"
+	defer wlog.Start(ctx, op).StopPrint()
+	return m.dao.Create(ctx, job, clusters)
+}
+
+func (m *manager) GetByID(ctx context.Context, id uint) (*models.BatchJob, error) {
+	const op = "batch job manager: get by id"
+	defer wlog.Start(ctx, op).StopPrint()
+	return m.dao.GetByID(ctx, id)
+}
+
+func (m *manager) ListByGroupID(ctx context.Context, groupID uint,
+	query *q.Query) ([]*models.BatchJob, int64, error) {
+	const op = "batch job manager: list by group id"
+	defer wlog.Start(ctx, op).StopPrint()
+	return m.dao.ListByGroupID(ctx, groupID, query)
+}
+
+func (m *manager) ListRunning(ctx context.Context) ([]*models.BatchJob, error) {
+	const op = "batch job manager: list running"
+	defer wlog.Start(ctx, op).StopPrint()
+	return m.dao.ListByStatus(ctx, models.StatusRunning)
+}
+
+func (m *manager) UpdateStatusByID(ctx context.Context, id uint, statuses []string,
+	job *models.BatchJob) (bool, error) {
+	const op = "batch job manager: update status by id"
+	defer wlog.Start(ctx, op).StopPrint()
+	return m.dao.UpdateStatusByID(ctx, id, statuses, job)
+}
+
+func (m *manager) ListClustersByJobID(ctx context.Context, jobID uint) ([]*models.BatchJobCluster, error) {
+	const op = "batch job manager: list clusters by job id"
+	defer wlog.Start(ctx, op).StopPrint()
+	return m.dao.ListClustersByJobID(ctx, jobID)
+}
+
+func (m *manager) UpdateClusterByID(ctx context.Context, id uint, cluster *models.BatchJobCluster) error {
+	const op = "batch job manager: update cluster by id"
+	defer wlog.Start(ctx, op).StopPrint()
+	return m.dao.UpdateClusterByID(ctx, id, cluster)
+}
+
+func (m *manager) SkipPendingClusters(ctx context.Context, jobID uint, message string) error {
+	const op = "batch job manager: skip pending clusters"
+	defer wlog.Start(ctx, op).StopPrint()
+	return m.dao.UpdatePendingClustersByJobID(ctx, jobID, models.ClusterStatusSkipped, message)
+}
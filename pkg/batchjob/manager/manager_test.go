@@ -0,0 +1,119 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manager
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/lib/orm"
+	"github.com/horizoncd/horizon/lib/q"
+	"github.com/horizoncd/horizon/pkg/batchjob/models"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	tagmodels "github.com/horizoncd/horizon/pkg/tag/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	db, _ = orm.NewSqliteDB("")
+	ctx   context.Context
+	mgr   = New(db)
+)
+
+func TestMain(m *testing.M) {
+	if err := db.AutoMigrate(&models.BatchJob{}, &models.BatchJobCluster{}); err != nil {
+		panic(err)
+	}
+	ctx = context.TODO()
+	os.Exit(m.Run())
+}
+
+func Test(t *testing.T) {
+	_, err := mgr.GetByID(ctx, 1)
+	_, ok := perror.Cause(err).(*herrors.HorizonErrNotFound)
+	assert.True(t, ok)
+
+	job := &models.BatchJob{
+		GroupID:       1,
+		Title:         "fix cve",
+		Operation:     models.OperationUpdateTags,
+		Concurrency:   2,
+		FailureBudget: 1,
+		Status:        models.StatusRunning,
+	}
+	job.SetSelector(models.Selector{Environment: "online", TagSelector: "team=a"})
+	job.SetParams(models.Params{Tags: tagmodels.TagsBasic{{Key: "cve", Value: "fixed"}}})
+	job, err = mgr.Create(ctx, job, []*models.BatchJobCluster{
+		{ClusterID: 1, Status: models.ClusterStatusPending},
+		{ClusterID: 2, Status: models.ClusterStatusPending},
+		{ClusterID: 3, Status: models.ClusterStatusPending},
+	})
+	assert.Nil(t, err)
+
+	job, err = mgr.GetByID(ctx, job.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, "team=a", job.GetSelector().TagSelector)
+	assert.Equal(t, "fixed", job.GetParams().Tags[0].Value)
+
+	clusters, err := mgr.ListClustersByJobID(ctx, job.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, 3, len(clusters))
+	assert.Equal(t, uint(1), clusters[0].ClusterID)
+
+	now := time.Now()
+	clusters[0].Status = models.ClusterStatusRunning
+	clusters[0].PipelinerunID = 10
+	clusters[0].StartedAt = &now
+	assert.Nil(t, mgr.UpdateClusterByID(ctx, clusters[0].ID, clusters[0]))
+
+	// only the clusters not started yet are skipped
+	assert.Nil(t, mgr.SkipPendingClusters(ctx, job.ID, "cancelled"))
+	clusters, err = mgr.ListClustersByJobID(ctx, job.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, models.ClusterStatusRunning, clusters[0].Status)
+	assert.Equal(t, uint(10), clusters[0].PipelinerunID)
+	assert.NotNil(t, clusters[0].StartedAt)
+	assert.False(t, clusters[0].IsFinished())
+	assert.Equal(t, models.ClusterStatusSkipped, clusters[1].Status)
+	assert.Equal(t, "cancelled", clusters[2].Message)
+	assert.True(t, clusters[2].IsFinished())
+
+	running, err := mgr.ListRunning(ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(running))
+
+	// transitions only happen from the expected statuses
+	job.Status = models.StatusCancelled
+	job.UpdatedBy = 2
+	updated, err := mgr.UpdateStatusByID(ctx, job.ID, []string{models.StatusSucceeded}, job)
+	assert.Nil(t, err)
+	assert.False(t, updated)
+	updated, err = mgr.UpdateStatusByID(ctx, job.ID, []string{models.StatusRunning}, job)
+	assert.Nil(t, err)
+	assert.True(t, updated)
+
+	jobs, total, err := mgr.ListByGroupID(ctx, 1, &q.Query{PageNumber: 1, PageSize: 10})
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1), total)
+	assert.Equal(t, models.StatusCancelled, jobs[0].Status)
+	assert.Equal(t, uint(2), jobs[0].UpdatedBy)
+	running, err = mgr.ListRunning(ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(running))
+}
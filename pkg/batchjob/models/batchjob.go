@@ -0,0 +1,138 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	tagmodels "github.com/horizoncd/horizon/pkg/tag/models"
+)
+
+// operations of a batch job
+const (
+	OperationDeploy     = "deploy"
+	OperationRestart    = "restart"
+	OperationUpgrade    = "upgrade"
+	OperationUpdateTags = "updatetags"
+)
+
+// statuses of a batch job
+const (
+	StatusRunning   = "running"
+	StatusSucceeded = "succeeded"
+	StatusFailed    = "failed"
+	StatusCancelled = "cancelled"
+)
+
+// statuses of a cluster in a batch job
+const (
+	ClusterStatusPending   = "pending"
+	ClusterStatusRunning   = "running"
+	ClusterStatusSucceeded = "succeeded"
+	ClusterStatusFailed    = "failed"
+	ClusterStatusSkipped   = "skipped"
+)
+
+// BatchJob applies one operation to the clusters selected under a group
+type BatchJob struct {
+	ID uint
+	// GroupID is the group whose subtree the clusters are selected from
+	GroupID   uint
+	Title     string
+	Operation string
+	// Selector is the json of Selector
+	Selector string
+	// Params is the json of Params
+	Params string
+	// Concurrency is how many clusters are operated at the same time
+	Concurrency int
+	// FailureBudget is how many clusters may fail before the job fails and the rest are skipped
+	FailureBudget int
+	Status        string
+	Message       string
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+	CreatedBy     uint
+	UpdatedBy     uint
+}
+
+// Selector selects clusters in the group subtree, all conditions must be met
+type Selector struct {
+	Template        string `json:"template,omitempty"`
+	TemplateRelease string `json:"templateRelease,omitempty"`
+	// TagSelector is in the format of kubernetes label selectors, e.g. team=a,tier in (web,api)
+	TagSelector string `json:"tagSelector,omitempty"`
+	Environment string `json:"environment,omitempty"`
+}
+
+// Params are the arguments of the operation
+type Params struct {
+	// ImageTag is deployed by the deploy operation, the current tag is kept if it's empty
+	ImageTag string `json:"imageTag,omitempty"`
+	// TemplateRelease is what the upgrade operation upgrades clusters to
+	TemplateRelease string `json:"templateRelease,omitempty"`
+	// Tags are added to or overwrite the tags of clusters by the updatetags operation
+	Tags tagmodels.TagsBasic `json:"tags,omitempty"`
+}
+
+// BatchJobCluster tracks the operation on a cluster in a batch job
+type BatchJobCluster struct {
+	ID        uint
+	JobID     uint
+	ClusterID uint
+	// PipelinerunID is the pipelinerun created by deploy and restart operations
+	PipelinerunID uint
+	Status        string
+	Message       string
+	StartedAt     *time.Time
+	FinishedAt    *time.Time
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+}
+
+func (j *BatchJob) GetSelector() Selector {
+	var selector Selector
+	if j.Selector == "" {
+		return selector
+	}
+	_ = json.Unmarshal([]byte(j.Selector), &selector)
+	return selector
+}
+
+func (j *BatchJob) SetSelector(selector Selector) {
+	bts, _ := json.Marshal(selector)
+	j.Selector = string(bts)
+}
+
+func (j *BatchJob) GetParams() Params {
+	var params Params
+	if j.Params == "" {
+		return params
+	}
+	_ = json.Unmarshal([]byte(j.Params), &params)
+	return params
+}
+
+func (j *BatchJob) SetParams(params Params) {
+	bts, _ := json.Marshal(params)
+	j.Params = string(bts)
+}
+
+// IsFinished tells whether the cluster is done with, in whatever way
+func (c *BatchJobCluster) IsFinished() bool {
+	return c.Status == ClusterStatusSucceeded || c.Status == ClusterStatusFailed ||
+		c.Status == ClusterStatusSkipped
+}
@@ -0,0 +1,72 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dao
+
+import (
+	"context"
+
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/pkg/buildcontext/models"
+	"github.com/horizoncd/horizon/pkg/common"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type DAO interface {
+	GetByClusterID(ctx context.Context, clusterID uint) (*models.BuildContext, error)
+	Upsert(ctx context.Context, buildContext *models.BuildContext) error
+	DeleteByClusterID(ctx context.Context, clusterID uint) error
+}
+
+type dao struct {
+	db *gorm.DB
+}
+
+func NewDAO(db *gorm.DB) DAO {
+	return &dao{db: db}
+}
+
+func (d *dao) GetByClusterID(ctx context.Context, clusterID uint) (*models.BuildContext, error) {
+	var buildContext models.BuildContext
+	result := d.db.WithContext(ctx).Raw(common.BuildContextGetByClusterID, clusterID).Scan(&buildContext)
+	if result.Error != nil {
+		return nil, herrors.NewErrGetFailed(herrors.BuildContextInDB, result.Error.Error())
+	}
+	if result.RowsAffected == 0 {
+		return nil, herrors.NewErrNotFound(herrors.BuildContextInDB, "build context not found")
+	}
+	return &buildContext, nil
+}
+
+// Upsert creates the build context of the cluster, or updates its globs if it exists
+func (d *dao) Upsert(ctx context.Context, buildContext *models.BuildContext) error {
+	result := d.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "cluster_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"path_globs", "updated_by"}),
+	}).Create(buildContext)
+	if result.Error != nil {
+		return herrors.NewErrInsertFailed(herrors.BuildContextInDB, result.Error.Error())
+	}
+	return nil
+}
+
+func (d *dao) DeleteByClusterID(ctx context.Context, clusterID uint) error {
+	result := d.db.WithContext(ctx).Where("cluster_id = ?", clusterID).Delete(&models.BuildContext{})
+	if result.Error != nil {
+		return herrors.NewErrDeleteFailed(herrors.BuildContextInDB, result.Error.Error())
+	}
+	return nil
+}
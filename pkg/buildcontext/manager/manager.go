@@ -0,0 +1,62 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manager
+
+import (
+	"context"
+
+	"github.com/horizoncd/horizon/pkg/buildcontext/dao"
+	"github.com/horizoncd/horizon/pkg/buildcontext/models"
+	"github.com/horizoncd/horizon/pkg/util/wlog"
+	"gorm.io/gorm"
+)
+
+type Manager interface {
+	GetByClusterID(ctx context.Context, clusterID uint) (*models.BuildContext, error)
+	// Upsert creates the build context of the cluster, or updates its path globs
+	Upsert(ctx context.Context, buildContext *models.BuildContext) (*models.BuildContext, error)
+	DeleteByClusterID(ctx context.Context, clusterID uint) error
+}
+
+type manager struct {
+	dao dao.DAO
+}
+
+func New(db *gorm.DB) Manager {
+	return &manager{
+		dao: dao.NewDAO(db),
+	}
+}
+
+func (m *manager) GetByClusterID(ctx context.Context, clusterID uint) (*models.BuildContext, error) {
+	const op = "build context manager: get by cluster id"
+	defer wlog.Start(ctx, op).StopPrint()
+	return m.dao.GetByClusterID(ctx, clusterID)
+}
+
+func (m *manager) Upsert(ctx context.Context, buildContext *models.BuildContext) (*models.BuildContext, error) {
+	const op = "build context manager: upsert"
+	defer wlog.Start(ctx, op).StopPrint()
+	if err := m.dao.Upsert(ctx, buildContext); err != nil {
+		return nil, err
+	}
+	return m.dao.GetByClusterID(ctx, buildContext.ClusterID)
+}
+
+func (m *manager) DeleteByClusterID(ctx context.Context, clusterID uint) error {
+	const op = "build context manager: delete by cluster id"
+	defer wlog.Start(ctx, op).StopPrint()
+	return m.dao.DeleteByClusterID(ctx, clusterID)
+}
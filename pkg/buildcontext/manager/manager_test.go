@@ -0,0 +1,68 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manager
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/lib/orm"
+	"github.com/horizoncd/horizon/pkg/buildcontext/models"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	db, _ = orm.NewSqliteDB("")
+	ctx   context.Context
+	mgr   = New(db)
+)
+
+func TestMain(m *testing.M) {
+	if err := db.AutoMigrate(&models.BuildContext{}); err != nil {
+		panic(err)
+	}
+	ctx = context.TODO()
+	os.Exit(m.Run())
+}
+
+func Test(t *testing.T) {
+	_, err := mgr.GetByClusterID(ctx, 1)
+	_, ok := perror.Cause(err).(*herrors.HorizonErrNotFound)
+	assert.True(t, ok)
+
+	b := &models.BuildContext{ClusterID: 1, CreatedBy: 1, UpdatedBy: 1}
+	b.SetPathGlobs([]string{"services/api/**", "libs/**"})
+	b, err = mgr.Upsert(ctx, b)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"services/api/**", "libs/**"}, b.GetPathGlobs())
+
+	// updated in place
+	b = &models.BuildContext{ClusterID: 1, CreatedBy: 2, UpdatedBy: 2}
+	b.SetPathGlobs([]string{"services/api/**"})
+	b, err = mgr.Upsert(ctx, b)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"services/api/**"}, b.GetPathGlobs())
+	assert.Equal(t, uint(1), b.CreatedBy)
+	assert.Equal(t, uint(2), b.UpdatedBy)
+
+	assert.Nil(t, mgr.DeleteByClusterID(ctx, 1))
+	_, err = mgr.GetByClusterID(ctx, 1)
+	_, ok = perror.Cause(err).(*herrors.HorizonErrNotFound)
+	assert.True(t, ok)
+}
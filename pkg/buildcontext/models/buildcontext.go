@@ -0,0 +1,51 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// BuildContext declares the files in the repo which the build of a cluster depends on,
+// a build is skipped if none of them changed since the last build of the cluster
+type BuildContext struct {
+	ID        uint
+	ClusterID uint `gorm:"uniqueIndex"`
+	// PathGlobs is the json of the globs of the files, relative to the root of the repo
+	PathGlobs string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	CreatedBy uint
+	UpdatedBy uint
+}
+
+func (b *BuildContext) GetPathGlobs() []string {
+	var globs []string
+	if b.PathGlobs == "" {
+		return globs
+	}
+	_ = json.Unmarshal([]byte(b.PathGlobs), &globs)
+	return globs
+}
+
+func (b *BuildContext) SetPathGlobs(globs []string) {
+	if len(globs) == 0 {
+		b.PathGlobs = ""
+		return
+	}
+	bts, _ := json.Marshal(globs)
+	b.PathGlobs = string(bts)
+}
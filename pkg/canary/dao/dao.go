@@ -0,0 +1,86 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dao
+
+import (
+	"context"
+	"time"
+
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/pkg/canary/models"
+	"github.com/horizoncd/horizon/pkg/common"
+
+	"gorm.io/gorm"
+)
+
+type DAO interface {
+	Create(ctx context.Context, analysis *models.CanaryAnalysis) (*models.CanaryAnalysis, error)
+	ListByPipelinerunID(ctx context.Context, pipelinerunID uint) ([]*models.CanaryAnalysis, error)
+	ListRunning(ctx context.Context) ([]*models.CanaryAnalysis, error)
+	UpdateByID(ctx context.Context, id uint, analysis *models.CanaryAnalysis) error
+	CancelRunningByClusterID(ctx context.Context, clusterID uint) error
+}
+
+type dao struct {
+	db *gorm.DB
+}
+
+func NewDAO(db *gorm.DB) DAO {
+	return &dao{db: db}
+}
+
+func (d *dao) Create(ctx context.Context, analysis *models.CanaryAnalysis) (*models.CanaryAnalysis, error) {
+	result := d.db.WithContext(ctx).Create(analysis)
+	if result.Error != nil {
+		return nil, herrors.NewErrInsertFailed(herrors.CanaryAnalysisInDB, result.Error.Error())
+	}
+	return analysis, nil
+}
+
+func (d *dao) ListByPipelinerunID(ctx context.Context, pipelinerunID uint) ([]*models.CanaryAnalysis, error) {
+	var analyses []*models.CanaryAnalysis
+	result := d.db.WithContext(ctx).Raw(common.CanaryAnalysisListByPipelinerunID, pipelinerunID).Scan(&analyses)
+	if result.Error != nil {
+		return nil, herrors.NewErrGetFailed(herrors.CanaryAnalysisInDB, result.Error.Error())
+	}
+	return analyses, nil
+}
+
+func (d *dao) ListRunning(ctx context.Context) ([]*models.CanaryAnalysis, error) {
+	var analyses []*models.CanaryAnalysis
+	result := d.db.WithContext(ctx).Raw(common.CanaryAnalysisListByStatus, models.StatusRunning).Scan(&analyses)
+	if result.Error != nil {
+		return nil, herrors.NewErrGetFailed(herrors.CanaryAnalysisInDB, result.Error.Error())
+	}
+	return analyses, nil
+}
+
+func (d *dao) UpdateByID(ctx context.Context, id uint, analysis *models.CanaryAnalysis) error {
+	result := d.db.WithContext(ctx).Where("id = ?", id).Select("Step", "Status",
+		"Metrics", "Message", "StartedAt", "FinishedAt").Updates(analysis)
+	if result.Error != nil {
+		return herrors.NewErrUpdateFailed(herrors.CanaryAnalysisInDB, result.Error.Error())
+	}
+	return nil
+}
+
+func (d *dao) CancelRunningByClusterID(ctx context.Context, clusterID uint) error {
+	result := d.db.WithContext(ctx).Exec(common.CanaryAnalysisCancelRunningByClusterID,
+		models.StatusCanceled, time.Now(), clusterID, models.StatusRunning)
+	if result.Error != nil {
+		return herrors.NewErrUpdateFailed(herrors.CanaryAnalysisInDB, result.Error.Error())
+	}
+	return nil
+}
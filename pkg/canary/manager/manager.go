@@ -0,0 +1,76 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manager
+
+import (
+	"context"
+
+	"github.com/horizoncd/horizon/pkg/canary/dao"
+	"github.com/horizoncd/horizon/pkg/canary/models"
+	"github.com/horizoncd/horizon/pkg/util/wlog"
+	"gorm.io/gorm"
+)
+
+type Manager interface {
+	Create(ctx context.Context, analysis *models.CanaryAnalysis) (*models.CanaryAnalysis, error)
+	// ListByPipelinerunID lists the analyses of all the steps of the pipelinerun, in step order
+	ListByPipelinerunID(ctx context.Context, pipelinerunID uint) ([]*models.CanaryAnalysis, error)
+	// ListRunning lists the analyses which are not finished yet
+	ListRunning(ctx context.Context) ([]*models.CanaryAnalysis, error)
+	UpdateByID(ctx context.Context, id uint, analysis *models.CanaryAnalysis) error
+	// CancelRunningByClusterID cancels the running analyses of the cluster,
+	// it is called when the cluster is deployed again
+	CancelRunningByClusterID(ctx context.Context, clusterID uint) error
+}
+
+type manager struct {
+	dao dao.DAO
+}
+
+func New(db *gorm.DB) Manager {
+	return &manager{
+		dao: dao.NewDAO(db),
+	}
+}
+
+func (m *manager) Create(ctx context.Context, analysis *models.CanaryAnalysis) (*models.CanaryAnalysis, error) {
+	const op = "canary analysis manager: create"
+	defer wlog.Start(ctx, op).StopPrint()
+	return m.dao.Create(ctx, analysis)
+}
+
+func (m *manager) ListByPipelinerunID(ctx context.Context, pipelinerunID uint) ([]*models.CanaryAnalysis, error) {
+	const op = "canary analysis manager: list by pipelinerun id"
+	defer wlog.Start(ctx, op).StopPrint()
+	return m.dao.ListByPipelinerunID(ctx, pipelinerunID)
+}
+
+func (m *manager) ListRunning(ctx context.Context) ([]*models.CanaryAnalysis, error) {
+	const op = "canary analysis manager: list running"
+	defer wlog.Start(ctx, op).StopPrint()
+	return m.dao.ListRunning(ctx)
+}
+
+func (m *manager) UpdateByID(ctx context.Context, id uint, analysis *models.CanaryAnalysis) error {
+	const op = "canary analysis manager: update by id"
+	defer wlog.Start(ctx, op).StopPrint()
+	return m.dao.UpdateByID(ctx, id, analysis)
+}
+
+func (m *manager) CancelRunningByClusterID(ctx context.Context, clusterID uint) error {
+	const op = "canary analysis manager: cancel running by cluster id"
+	defer wlog.Start(ctx, op).StopPrint()
+	return m.dao.CancelRunningByClusterID(ctx, clusterID)
+}
@@ -0,0 +1,87 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manager
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/horizoncd/horizon/lib/orm"
+	"github.com/horizoncd/horizon/pkg/canary/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	db, _ = orm.NewSqliteDB("")
+	ctx   context.Context
+	mgr   = New(db)
+)
+
+func TestMain(m *testing.M) {
+	if err := db.AutoMigrate(&models.CanaryAnalysis{}); err != nil {
+		panic(err)
+	}
+	ctx = context.TODO()
+	os.Exit(m.Run())
+}
+
+func Test(t *testing.T) {
+	first, err := mgr.Create(ctx, &models.CanaryAnalysis{
+		PipelinerunID: 1,
+		ClusterID:     1,
+		Step:          1,
+		Status:        models.StatusRunning,
+		StartedAt:     time.Now(),
+	})
+	assert.Nil(t, err)
+
+	first.SetMetricResults([]models.MetricResult{{Name: "error-rate", Passed: true}})
+	first.Status = models.StatusPassed
+	now := time.Now()
+	first.FinishedAt = &now
+	assert.Nil(t, mgr.UpdateByID(ctx, first.ID, first))
+
+	_, err = mgr.Create(ctx, &models.CanaryAnalysis{
+		PipelinerunID: 1,
+		ClusterID:     1,
+		Step:          2,
+		Status:        models.StatusRunning,
+		StartedAt:     time.Now(),
+	})
+	assert.Nil(t, err)
+
+	running, err := mgr.ListRunning(ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(running))
+	assert.Equal(t, 2, running[0].Step)
+
+	analyses, err := mgr.ListByPipelinerunID(ctx, 1)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(analyses))
+	assert.Equal(t, models.StatusPassed, analyses[0].Status)
+	assert.Equal(t, "error-rate", analyses[0].MetricResults()[0].Name)
+	assert.NotNil(t, analyses[0].FinishedAt)
+
+	assert.Nil(t, mgr.CancelRunningByClusterID(ctx, 1))
+	running, err = mgr.ListRunning(ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(running))
+	analyses, err = mgr.ListByPipelinerunID(ctx, 1)
+	assert.Nil(t, err)
+	assert.Equal(t, models.StatusCanceled, analyses[1].Status)
+}
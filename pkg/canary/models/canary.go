@@ -0,0 +1,69 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+const (
+	StatusRunning  = "running"
+	StatusPassed   = "passed"
+	StatusFailed   = "failed"
+	StatusCanceled = "canceled"
+)
+
+// CanaryAnalysis is the analysis result of a canary step of the rollout deployed by a pipelinerun
+type CanaryAnalysis struct {
+	ID            uint
+	PipelinerunID uint
+	ClusterID     uint
+	// Step is the index of the canary step being analyzed, starting from 1
+	Step   int
+	Status string
+	// Metrics is the json of the latest []MetricResult
+	Metrics    string
+	Message    string
+	StartedAt  time.Time
+	FinishedAt *time.Time
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+}
+
+// MetricResult is the latest evaluation of a metric
+type MetricResult struct {
+	Name      string   `json:"name"`
+	Query     string   `json:"query"`
+	Condition string   `json:"condition"`
+	Threshold float64  `json:"threshold"`
+	Value     *float64 `json:"value,omitempty"`
+	Passed    bool     `json:"passed"`
+	Error     string   `json:"error,omitempty"`
+}
+
+func (a *CanaryAnalysis) MetricResults() []MetricResult {
+	var results []MetricResult
+	if a.Metrics == "" {
+		return results
+	}
+	_ = json.Unmarshal([]byte(a.Metrics), &results)
+	return results
+}
+
+func (a *CanaryAnalysis) SetMetricResults(results []MetricResult) {
+	bts, _ := json.Marshal(results)
+	a.Metrics = string(bts)
+}
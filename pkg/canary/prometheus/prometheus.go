@@ -0,0 +1,77 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prometheus
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/prometheus/client_golang/api"
+	v1 "github.com/prometheus/client_golang/api/prometheus/v1"
+	"github.com/prometheus/common/model"
+)
+
+// Querier queries the value of PromQL from prometheus
+type Querier interface {
+	// Query evaluates the query at now, the query must result in a single value
+	Query(ctx context.Context, prometheusURL, query string) (float64, error)
+}
+
+type querier struct {
+	timeout time.Duration
+}
+
+func NewQuerier(timeout time.Duration) Querier {
+	return &querier{timeout: timeout}
+}
+
+func (q *querier) Query(ctx context.Context, prometheusURL, query string) (float64, error) {
+	if prometheusURL == "" {
+		return 0, fmt.Errorf("prometheus url is not configured")
+	}
+	client, err := api.NewClient(api.Config{Address: prometheusURL})
+	if err != nil {
+		return 0, err
+	}
+	if q.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, q.timeout)
+		defer cancel()
+	}
+
+	value, _, err := v1.NewAPI(client).Query(ctx, query, time.Now())
+	if err != nil {
+		return 0, err
+	}
+
+	var result float64
+	switch v := value.(type) {
+	case *model.Scalar:
+		result = float64(v.Value)
+	case model.Vector:
+		if len(v) != 1 {
+			return 0, fmt.Errorf("query should result in 1 sample, but got %d", len(v))
+		}
+		result = float64(v[0].Value)
+	default:
+		return 0, fmt.Errorf("unsupported result type %s", value.Type())
+	}
+	if math.IsNaN(result) {
+		return 0, fmt.Errorf("query results in NaN")
+	}
+	return result, nil
+}
@@ -0,0 +1,59 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prometheus
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQuery(t *testing.T) {
+	responses := map[string]string{
+		"scalar": `{"status":"success","data":{"resultType":"scalar","result":[1680000000,"0.5"]}}`,
+		"vector": `{"status":"success","data":{"resultType":"vector",` +
+			`"result":[{"metric":{},"value":[1680000000,"12"]}]}}`,
+		"empty": `{"status":"success","data":{"resultType":"vector","result":[]}}`,
+		"nan":   `{"status":"success","data":{"resultType":"scalar","result":[1680000000,"NaN"]}}`,
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(responses[r.Form.Get("query")]))
+	}))
+	defer server.Close()
+
+	q := NewQuerier(time.Second)
+	ctx := context.TODO()
+
+	value, err := q.Query(ctx, server.URL, "scalar")
+	assert.Nil(t, err)
+	assert.Equal(t, 0.5, value)
+
+	value, err = q.Query(ctx, server.URL, "vector")
+	assert.Nil(t, err)
+	assert.Equal(t, float64(12), value)
+
+	_, err = q.Query(ctx, server.URL, "empty")
+	assert.NotNil(t, err)
+	_, err = q.Query(ctx, server.URL, "nan")
+	assert.NotNil(t, err)
+	_, err = q.Query(ctx, "", "scalar")
+	assert.NotNil(t, err)
+}
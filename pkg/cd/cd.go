@@ -87,6 +87,8 @@ type CD interface {
 	GetResourceTree(ctx context.Context, params *GetResourceTreeParams) ([]ResourceNode, error)
 	GetStep(ctx context.Context, params *GetStepParams) (*Step, error)
 	GetPodEvents(ctx context.Context, params *GetPodEventsParams) ([]Event, error)
+	// GetClusterDrift gets the resources whose live states differ from the desired states in gitops repo
+	GetClusterDrift(ctx context.Context, params *GetClusterDriftParams) (*ClusterDrift, error)
 }
 
 type cd struct {
@@ -126,7 +128,7 @@ func (c *cd) CreateCluster(ctx context.Context, params *CreateClusterParams) (er
 		return err
 	}
 	var argoApplication = argo.AssembleArgoApplication(params.Cluster, params.Namespace,
-		params.GitRepoURL, params.RegionEntity.Server, params.ValueFiles, c.targetRevision)
+		params.GitRepoURL, params.RegionEntity.Server, params.ValueFiles, c.targetRevision, params.TemplateKind)
 
 	manifest, err := json.Marshal(argoApplication)
 	if err != nil {
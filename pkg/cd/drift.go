@@ -0,0 +1,272 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	applicationV1alpha1 "github.com/argoproj/argo-cd/pkg/apis/application/v1alpha1"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	driftmodels "github.com/horizoncd/horizon/pkg/drift/models"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/util/wlog"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/informers"
+)
+
+// GetClusterDrift compares the resources rendered from gitops repo with the live ones in the region.
+// Only resources which argoCD considers out of sync are compared, their live states are read from
+// the region informers, and the paths of fields whose live values differ from the desired ones are reported.
+func (c *cd) GetClusterDrift(ctx context.Context, params *GetClusterDriftParams) (*ClusterDrift, error) {
+	const op = "cd: get cluster drift"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	argo, err := c.factory.GetArgoCD(params.Environment)
+	if err != nil {
+		return nil, err
+	}
+
+	argoApp, err := argo.GetApplication(ctx, params.Cluster)
+	if err != nil {
+		return nil, err
+	}
+
+	drift := &ClusterDrift{
+		Revision: argoApp.Status.Sync.Revision,
+	}
+	if argoApp.Status.OperationState != nil && !argoApp.Status.OperationState.Phase.Completed() {
+		drift.Reconciling = true
+		return drift, nil
+	}
+
+	// a deployment has been committed to gitops repo but not synced yet
+	lastConfigCommit, err := c.clusterGitRepo.GetConfigCommit(ctx, params.Application, params.Cluster)
+	if err != nil {
+		return nil, err
+	}
+	if lastConfigCommit.Master != drift.Revision {
+		drift.Reconciling = true
+		return drift, nil
+	}
+
+	manifest, err := c.clusterGitRepo.GetManifest(ctx, params.Application, params.Cluster, &drift.Revision)
+	if err != nil {
+		if _, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); !ok {
+			return nil, err
+		}
+	} else {
+		drift.ManifestVersion = manifest.Version
+	}
+
+	if argoApp.Status.Sync.Status == applicationV1alpha1.SyncStatusCodeSynced {
+		return drift, nil
+	}
+
+	outOfSync := make(map[string]struct{})
+	for _, resource := range argoApp.Status.Resources {
+		if resource.Hook || resource.Status != applicationV1alpha1.SyncStatusCodeOutOfSync {
+			continue
+		}
+		outOfSync[driftResourceKey(resource.Group, resource.Kind, resource.Namespace, resource.Name)] = struct{}{}
+	}
+	if len(outOfSync) == 0 {
+		return drift, nil
+	}
+
+	managedResources, err := argo.GetManagedResources(ctx, params.Cluster)
+	if err != nil {
+		return nil, err
+	}
+	for _, managed := range managedResources {
+		if managed.Hook || managed.TargetState == "" || managed.TargetState == "null" {
+			continue
+		}
+		if _, ok := outOfSync[driftResourceKey(managed.Group, managed.Kind,
+			managed.Namespace, managed.Name)]; !ok {
+			continue
+		}
+
+		desired := &unstructured.Unstructured{}
+		if err := json.Unmarshal([]byte(managed.TargetState), &desired.Object); err != nil {
+			return nil, perror.Wrapf(herrors.ErrParamInvalid,
+				"failed to unmarshal desired state of %s: %v", managed.FullName(), err)
+		}
+		gvk := desired.GroupVersionKind()
+		resource := driftmodels.Resource{
+			Group:     gvk.Group,
+			Version:   gvk.Version,
+			Kind:      gvk.Kind,
+			Namespace: managed.Namespace,
+			Name:      managed.Name,
+		}
+
+		live, err := c.getLiveObject(params.RegionEntity.ID, gvk, managed.Namespace, managed.Name)
+		if err != nil {
+			if !k8serrors.IsNotFound(err) {
+				return nil, err
+			}
+			resource.Missing = true
+			drift.Resources = append(drift.Resources, resource)
+			continue
+		}
+
+		resource.FieldPaths = DriftFieldPaths(desired.Object, live.Object)
+		if len(resource.FieldPaths) > 0 {
+			drift.Resources = append(drift.Resources, resource)
+		}
+	}
+	return drift, nil
+}
+
+func (c *cd) getLiveObject(regionID uint, gvk schema.GroupVersionKind,
+	namespace, name string) (*unstructured.Unstructured, error) {
+	gvr, err := c.informerFactories.GVK2GVR(regionID, gvk)
+	if err != nil {
+		return nil, err
+	}
+
+	var live *unstructured.Unstructured
+	err = c.informerFactories.GetDynamicInformer(regionID, gvr, func(informer informers.GenericInformer) error {
+		var obj interface{}
+		var err error
+		if namespace == "" {
+			obj, err = informer.Lister().Get(name)
+		} else {
+			obj, err = informer.Lister().ByNamespace(namespace).Get(name)
+		}
+		if err != nil {
+			return err
+		}
+		var ok bool
+		if live, ok = obj.(*unstructured.Unstructured); !ok {
+			return perror.Wrapf(herrors.ErrParamInvalid, "unexpected type of %s %s/%s", gvk, namespace, name)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return live, nil
+}
+
+func driftResourceKey(group, kind, namespace, name string) string {
+	return fmt.Sprintf("%s/%s/%s/%s", group, kind, namespace, name)
+}
+
+// DriftFieldPaths returns the paths of fields declared in desired whose values differ in live.
+// Fields only present in live are defaulted or maintained by kubernetes, so they are not considered as drift,
+// neither is the status.
+func DriftFieldPaths(desired, live map[string]interface{}) []string {
+	var paths []string
+	for _, key := range sortedKeys(desired) {
+		if key == "status" {
+			continue
+		}
+		paths = append(paths, driftFieldPaths(key, desired[key], live[key])...)
+	}
+	return paths
+}
+
+func driftFieldPaths(path string, desired, live interface{}) []string {
+	switch d := desired.(type) {
+	case nil:
+		return nil
+	case map[string]interface{}:
+		l, ok := live.(map[string]interface{})
+		if !ok {
+			if live == nil && len(d) == 0 {
+				return nil
+			}
+			return []string{path}
+		}
+		var paths []string
+		for _, key := range sortedKeys(d) {
+			paths = append(paths, driftFieldPaths(joinFieldPath(path, key), d[key], l[key])...)
+		}
+		return paths
+	case []interface{}:
+		l, ok := live.([]interface{})
+		if !ok {
+			if live == nil && len(d) == 0 {
+				return nil
+			}
+			return []string{path}
+		}
+		if len(l) != len(d) {
+			return []string{path}
+		}
+		var paths []string
+		for i := range d {
+			paths = append(paths, driftFieldPaths(fmt.Sprintf("%s[%d]", path, i), d[i], l[i])...)
+		}
+		return paths
+	default:
+		if dn, ok := toFloat64(desired); ok {
+			if ln, ok := toFloat64(live); ok && dn == ln {
+				return nil
+			}
+			return []string{path}
+		}
+		if reflect.DeepEqual(desired, live) {
+			return nil
+		}
+		return []string{path}
+	}
+}
+
+// joinFieldPath joins the key to path, keys like label names which contain dots or slashes are quoted
+func joinFieldPath(path, key string) string {
+	if strings.ContainsAny(key, "./") {
+		return fmt.Sprintf("%s[%s]", path, key)
+	}
+	return path + "." + key
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// toFloat64 converts numbers, which are float64 when decoded from json but int64 in informer caches
+func toFloat64(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case int:
+		return float64(n), true
+	case int32:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case float32:
+		return float64(n), true
+	case float64:
+		return n, true
+	case json.Number:
+		f, err := n.Float64()
+		return f, err == nil
+	default:
+		return 0, false
+	}
+}
@@ -0,0 +1,94 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cd
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDriftFieldPaths(t *testing.T) {
+	desired := map[string]interface{}{}
+	err := json.Unmarshal([]byte(`{
+  "apiVersion": "apps/v1",
+  "kind": "Deployment",
+  "metadata": {
+    "name": "demo",
+    "labels": {"app.kubernetes.io/instance": "demo"}
+  },
+  "spec": {
+    "replicas": 2,
+    "template": {
+      "spec": {
+        "containers": [{"name": "demo", "image": "demo:v1", "env": []}],
+        "volumes": []
+      }
+    }
+  },
+  "status": {"replicas": 2}
+}`), &desired)
+	assert.Nil(t, err)
+
+	live := map[string]interface{}{
+		"apiVersion": "apps/v1",
+		"kind":       "Deployment",
+		"metadata": map[string]interface{}{
+			"name":            "demo",
+			"resourceVersion": "100",
+			"labels": map[string]interface{}{
+				"app.kubernetes.io/instance": "demo",
+			},
+		},
+		"spec": map[string]interface{}{
+			"replicas": int64(2),
+			"template": map[string]interface{}{
+				"spec": map[string]interface{}{
+					"containers": []interface{}{
+						map[string]interface{}{
+							"name":            "demo",
+							"image":           "demo:v1",
+							"imagePullPolicy": "IfNotPresent",
+						},
+					},
+				},
+			},
+		},
+		"status": map[string]interface{}{"replicas": int64(1)},
+	}
+	assert.Empty(t, DriftFieldPaths(desired, live))
+
+	liveSpec := live["spec"].(map[string]interface{})
+	liveSpec["replicas"] = int64(3)
+	podSpec := liveSpec["template"].(map[string]interface{})["spec"].(map[string]interface{})
+	podSpec["containers"].([]interface{})[0].(map[string]interface{})["image"] = "demo:hotfix"
+	delete(live["metadata"].(map[string]interface{}), "labels")
+	assert.Equal(t, []string{
+		"metadata.labels",
+		"spec.replicas",
+		"spec.template.spec.containers[0].image",
+	}, DriftFieldPaths(desired, live))
+
+	live["metadata"].(map[string]interface{})["labels"] = map[string]interface{}{
+		"app.kubernetes.io/instance": "other",
+	}
+	podSpec["containers"] = []interface{}{}
+	assert.Equal(t, []string{
+		"metadata.labels[app.kubernetes.io/instance]",
+		"spec.replicas",
+		"spec.template.spec.containers",
+	}, DriftFieldPaths(desired, live))
+}
@@ -19,6 +19,7 @@ import (
 
 	applicationV1alpha1 "github.com/argoproj/argo-cd/pkg/apis/application/v1alpha1"
 	"github.com/argoproj/gitops-engine/pkg/health"
+	driftmodels "github.com/horizoncd/horizon/pkg/drift/models"
 	regionmodels "github.com/horizoncd/horizon/pkg/region/models"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -51,6 +52,13 @@ type GetClusterStateV2Params struct {
 	RegionEntity *regionmodels.RegionEntity
 }
 
+type GetClusterDriftParams struct {
+	Application  string
+	Environment  string
+	Cluster      string
+	RegionEntity *regionmodels.RegionEntity
+}
+
 type CreateClusterParams struct {
 	Environment  string
 	Cluster      string
@@ -58,6 +66,8 @@ type CreateClusterParams struct {
 	ValueFiles   []string
 	RegionEntity *regionmodels.RegionEntity
 	Namespace    string
+	// TemplateKind is the kind of cluster's template, defaults to helm
+	TemplateKind string
 }
 
 type DeployClusterParams struct {
@@ -211,6 +221,17 @@ type ClusterStateV2 struct {
 	Status string `json:"status"`
 }
 
+// ClusterDrift is the difference between the desired state in gitops repo and the live state of a cluster
+type ClusterDrift struct {
+	// Reconciling means the cluster is being synced to a new revision,
+	// drift can not be determined until the sync finishes
+	Reconciling bool
+	// Revision is the gitops commit the desired state was rendered from
+	Revision        string
+	ManifestVersion string
+	Resources       []driftmodels.Resource
+}
+
 // ClusterState cluster state
 type ClusterState struct {
 	// Status:
@@ -0,0 +1,138 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ci
+
+import (
+	"context"
+	"sync"
+
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/pkg/cluster/tekton"
+	"github.com/horizoncd/horizon/pkg/cluster/tekton/collector"
+	"github.com/horizoncd/horizon/pkg/cluster/tekton/factory"
+	"github.com/horizoncd/horizon/pkg/cluster/tekton/metrics"
+	"github.com/horizoncd/horizon/pkg/cluster/tekton/report"
+	ciconfig "github.com/horizoncd/horizon/pkg/config/ci"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	prmodels "github.com/horizoncd/horizon/pkg/pipelinerun/models"
+	provenancemodels "github.com/horizoncd/horizon/pkg/pipelinerun/provenance/models"
+	reportmodels "github.com/horizoncd/horizon/pkg/pipelinerun/report/models"
+	"github.com/horizoncd/horizon/pkg/server/global"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+)
+
+const (
+	TypeTekton   = "tekton"
+	TypeExternal = "external"
+
+	_default = "default"
+)
+
+// Engine runs the pipelines building and deploying clusters
+//
+//go:generate mockgen -source=$GOFILE -destination=../../../mock/pkg/cluster/ci/ci_mock.go -package=mock_ci
+type Engine interface {
+	// Type is the type of the engine
+	Type() string
+	// CreatePipelineRun starts the pipeline of the pipelinerun, the returned id identifies the run in the engine
+	CreatePipelineRun(ctx context.Context, pr *tekton.PipelineRun) (string, error)
+	// StopPipelineRun stops the running pipeline of the pipelinerun
+	StopPipelineRun(ctx context.Context, pr *prmodels.Pipelinerun) error
+	// GetPipelineRunLog gets the log of the pipelinerun
+	GetPipelineRunLog(ctx context.Context, pr *prmodels.Pipelinerun) (*collector.Log, error)
+	// GetPipelineRunReports gets the reports produced so far by the running pipelinerun
+	GetPipelineRunReports(ctx context.Context, pr *prmodels.Pipelinerun) ([]*report.Report, error)
+	// GetPipelineRunReport gets the file of a report collected when the pipelinerun finished
+	GetPipelineRunReport(ctx context.Context, object string) ([]byte, error)
+	// Complete resolves the result of a finished pipelinerun from the callback sent by the engine
+	Complete(ctx context.Context, callback *Callback, metadata *global.HorizonMetaData) (*Result, error)
+}
+
+// Callback is sent by an engine when a pipelinerun finishes, only the field of the engine is set
+type Callback struct {
+	// PipelineRun is sent by tekton in cloud events
+	PipelineRun *v1beta1.PipelineRun
+	// External is reported by the external pipeline
+	External *ExternalCallback
+}
+
+// Result is the result of a finished pipelinerun
+type Result struct {
+	prmodels.Result
+	// Pipeline is saved as the stats of the pipeline
+	Pipeline *metrics.PipelineResults
+	// Reports are the reports produced by the tasks
+	Reports []*reportmodels.Report
+	// Provenance is the provenance signed by tekton chains, nil if the pipelinerun is not signed
+	Provenance *provenancemodels.Provenance
+}
+
+type Factory interface {
+	// GetEngine gets the engine of the environment
+	GetEngine(environment string) (Engine, error)
+}
+
+type engineFactory struct {
+	mapper    ciconfig.Mapper
+	tektonFty factory.Factory
+	external  *sync.Map
+}
+
+// NewFactory creates the engine factory,
+// environments which are not configured in the mapper are built by tekton of the tekton factory
+func NewFactory(mapper ciconfig.Mapper, tektonFty factory.Factory) (Factory, error) {
+	external := &sync.Map{}
+	for env, engine := range mapper {
+		switch engine.Type {
+		case "", TypeTekton:
+		case TypeExternal:
+			e, err := NewExternal(engine.External)
+			if err != nil {
+				return nil, perror.WithMessagef(err, "invalid ci engine of %s", env)
+			}
+			external.Store(env, e)
+		default:
+			return nil, perror.Wrapf(herrors.ErrParamInvalid, "unsupported ci engine %s of %s", engine.Type, env)
+		}
+	}
+	return &engineFactory{
+		mapper:    mapper,
+		tektonFty: tektonFty,
+		external:  external,
+	}, nil
+}
+
+func (f *engineFactory) GetEngine(environment string) (Engine, error) {
+	key := environment
+	engine, ok := f.mapper[key]
+	if !ok {
+		key = _default
+		engine, ok = f.mapper[key]
+	}
+	if ok && engine.Type == TypeExternal {
+		e, _ := f.external.Load(key)
+		return e.(Engine), nil
+	}
+
+	t, err := f.tektonFty.GetTekton(environment)
+	if err != nil {
+		return nil, err
+	}
+	c, err := f.tektonFty.GetTektonCollector(environment)
+	if err != nil {
+		return nil, err
+	}
+	return NewTekton(t, c), nil
+}
@@ -0,0 +1,352 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ci
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/pkg/cluster/tekton"
+	"github.com/horizoncd/horizon/pkg/cluster/tekton/collector"
+	"github.com/horizoncd/horizon/pkg/cluster/tekton/metrics"
+	"github.com/horizoncd/horizon/pkg/cluster/tekton/report"
+	ciconfig "github.com/horizoncd/horizon/pkg/config/ci"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	prmodels "github.com/horizoncd/horizon/pkg/pipelinerun/models"
+	"github.com/horizoncd/horizon/pkg/server/global"
+	"github.com/horizoncd/horizon/pkg/util/wlog"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	ProviderGitLab = "gitlab"
+	ProviderGitHub = "github"
+
+	// the pipelinerun in json and the callback api are passed to the external pipeline as
+	// variables of gitlab ci or inputs of github actions
+	_variablePipelineRun = "HORIZON_PIPELINERUN"
+	_variableCallbackURL = "HORIZON_CALLBACK_URL"
+	_inputPipelineRun    = "pipelinerun"
+	_inputCallbackURL    = "callbackURL"
+
+	_httpTimeout = 30 * time.Second
+
+	// ExternalStatusRunning is reported when the external pipeline starts
+	ExternalStatusRunning = "running"
+)
+
+// ExternalCallback is reported by the external pipeline to the callback api,
+// with the jwt token of the pipelinerun in the header
+type ExternalCallback struct {
+	PipelinerunID uint `json:"pipelinerunID"`
+	// RunID is the id of the pipeline in the engine, github actions must report it for the run to be stopped
+	RunID string `json:"runID"`
+	// Status is running, ok, failed or cancelled, only the run id is recorded when it's running
+	Status string `json:"status"`
+	// Pipeline is the name of the pipeline in the stats, e.g. horizon-pipeline
+	Pipeline   string          `json:"pipeline"`
+	StartedAt  *time.Time      `json:"startedAt"`
+	FinishedAt *time.Time      `json:"finishedAt"`
+	Tasks      []*ExternalTask `json:"tasks"`
+}
+
+type ExternalTask struct {
+	Name       string          `json:"name"`
+	Result     string          `json:"result"`
+	StartedAt  *time.Time      `json:"startedAt"`
+	FinishedAt *time.Time      `json:"finishedAt"`
+	Steps      []*ExternalStep `json:"steps"`
+}
+
+type ExternalStep struct {
+	Name       string     `json:"name"`
+	Result     string     `json:"result"`
+	StartedAt  *time.Time `json:"startedAt"`
+	FinishedAt *time.Time `json:"finishedAt"`
+}
+
+// External triggers a pipeline of GitLab CI or GitHub Actions, which calls back the internal deploy api
+// like the tekton pipeline does, and reports its completion to the callback api
+type External struct {
+	config *ciconfig.External
+	client *http.Client
+}
+
+var _ Engine = (*External)(nil)
+
+func NewExternal(config *ciconfig.External) (*External, error) {
+	if config == nil || config.Server == "" || config.Project == "" {
+		return nil, perror.Wrap(herrors.ErrParamInvalid, "server and project of the external engine are required")
+	}
+	switch config.Provider {
+	case ProviderGitLab:
+		if config.TriggerToken == "" {
+			return nil, perror.Wrap(herrors.ErrParamInvalid, "trigger token is required by gitlab")
+		}
+	case ProviderGitHub:
+		if config.Workflow == "" || config.Token == "" {
+			return nil, perror.Wrap(herrors.ErrParamInvalid, "workflow and token are required by github")
+		}
+	default:
+		return nil, perror.Wrapf(herrors.ErrParamInvalid, "unsupported provider %s", config.Provider)
+	}
+	return &External{
+		config: config,
+		client: &http.Client{Timeout: _httpTimeout},
+	}, nil
+}
+
+func (e *External) Type() string {
+	return TypeExternal
+}
+
+func (e *External) CreatePipelineRun(ctx context.Context, pr *tekton.PipelineRun) (_ string, err error) {
+	const op = "external ci: create pipelineRun"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	prBytes, err := json.Marshal(pr)
+	if err != nil {
+		return "", perror.Wrap(herrors.ErrParamInvalid, err.Error())
+	}
+
+	if e.config.Provider == ProviderGitHub {
+		body, err := json.Marshal(map[string]interface{}{
+			"ref": e.config.Ref,
+			"inputs": map[string]string{
+				_inputPipelineRun: string(prBytes),
+				_inputCallbackURL: e.config.CallbackURL,
+			},
+		})
+		if err != nil {
+			return "", perror.Wrap(herrors.ErrParamInvalid, err.Error())
+		}
+		// the dispatch api does not return the run, its id is reported by the callback
+		_, err = e.do(ctx, http.MethodPost, fmt.Sprintf("/repos/%s/actions/workflows/%s/dispatches",
+			e.config.Project, e.config.Workflow), "application/json", bytes.NewReader(body), http.StatusNoContent)
+		return "", err
+	}
+
+	form := url.Values{}
+	form.Set("token", e.config.TriggerToken)
+	form.Set("ref", e.config.Ref)
+	form.Set(fmt.Sprintf("variables[%s]", _variablePipelineRun), string(prBytes))
+	form.Set(fmt.Sprintf("variables[%s]", _variableCallbackURL), e.config.CallbackURL)
+	respBytes, err := e.do(ctx, http.MethodPost, fmt.Sprintf("/projects/%s/trigger/pipeline",
+		url.PathEscape(e.config.Project)), "application/x-www-form-urlencoded",
+		strings.NewReader(form.Encode()), http.StatusCreated)
+	if err != nil {
+		return "", err
+	}
+	var pipeline struct {
+		ID uint `json:"id"`
+	}
+	if err := json.Unmarshal(respBytes, &pipeline); err != nil {
+		return "", perror.Wrap(herrors.ErrParamInvalid, err.Error())
+	}
+	return fmt.Sprintf("%d", pipeline.ID), nil
+}
+
+func (e *External) StopPipelineRun(ctx context.Context, pr *prmodels.Pipelinerun) (err error) {
+	const op = "external ci: stop pipelineRun"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	if pr.CIEventID == "" {
+		return perror.Wrapf(herrors.ErrParamInvalid,
+			"the external pipeline of pipelinerun %d has not reported its run yet", pr.ID)
+	}
+	if e.config.Provider == ProviderGitHub {
+		_, err = e.do(ctx, http.MethodPost, fmt.Sprintf("/repos/%s/actions/runs/%s/cancel",
+			e.config.Project, pr.CIEventID), "", nil, http.StatusAccepted)
+		return err
+	}
+	_, err = e.do(ctx, http.MethodPost, fmt.Sprintf("/projects/%s/pipelines/%s/cancel",
+		url.PathEscape(e.config.Project), pr.CIEventID), "", nil, http.StatusOK)
+	return err
+}
+
+// GetPipelineRunLog reads the traces of the gitlab jobs in the pipeline,
+// logs of github actions are archived and can only be viewed in github
+func (e *External) GetPipelineRunLog(ctx context.Context, pr *prmodels.Pipelinerun) (_ *collector.Log, err error) {
+	const op = "external ci: get pipelineRun log"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	if pr.CIEventID == "" {
+		return &collector.Log{LogBytes: []byte("the external pipeline has not reported its run yet")}, nil
+	}
+	if e.config.Provider == ProviderGitHub {
+		return &collector.Log{LogBytes: []byte(fmt.Sprintf(
+			"logs are kept by github actions, see run %s of %s", pr.CIEventID, e.config.Project))}, nil
+	}
+
+	project := url.PathEscape(e.config.Project)
+	respBytes, err := e.do(ctx, http.MethodGet, fmt.Sprintf("/projects/%s/pipelines/%s/jobs?per_page=100",
+		project, pr.CIEventID), "", nil, http.StatusOK)
+	if err != nil {
+		return nil, err
+	}
+	var jobs []struct {
+		ID    uint   `json:"id"`
+		Name  string `json:"name"`
+		Stage string `json:"stage"`
+	}
+	if err := json.Unmarshal(respBytes, &jobs); err != nil {
+		return nil, perror.Wrap(herrors.ErrParamInvalid, err.Error())
+	}
+	var buf bytes.Buffer
+	// jobs are listed from the latest
+	for i := len(jobs) - 1; i >= 0; i-- {
+		trace, err := e.do(ctx, http.MethodGet, fmt.Sprintf("/projects/%s/jobs/%d/trace",
+			project, jobs[i].ID), "", nil, http.StatusOK)
+		if err != nil {
+			return nil, err
+		}
+		buf.WriteString(fmt.Sprintf("[%s] %s\n", jobs[i].Stage, jobs[i].Name))
+		buf.Write(trace)
+		buf.WriteString("\n")
+	}
+	return &collector.Log{LogBytes: buf.Bytes()}, nil
+}
+
+// GetPipelineRunReports returns no report, as reports are not read from external pipelines
+func (e *External) GetPipelineRunReports(_ context.Context, _ *prmodels.Pipelinerun) ([]*report.Report, error) {
+	return nil, nil
+}
+
+func (e *External) GetPipelineRunReport(_ context.Context, object string) ([]byte, error) {
+	return nil, herrors.NewErrNotFound(herrors.PipelinerunReport, object)
+}
+
+func (e *External) Complete(_ context.Context, callback *Callback,
+	metadata *global.HorizonMetaData) (*Result, error) {
+	cb := callback.External
+	if cb == nil {
+		return nil, perror.Wrap(herrors.ErrParamInvalid, "external callback is missing")
+	}
+	switch prmodels.PipelineStatus(cb.Status) {
+	case prmodels.StatusOK, prmodels.StatusFailed, prmodels.StatusCancelled:
+	default:
+		return nil, perror.Wrapf(herrors.ErrParamInvalid, "pipelinerun is not finished, status: %s", cb.Status)
+	}
+	finishedAt := time.Now()
+	if cb.FinishedAt != nil {
+		finishedAt = *cb.FinishedAt
+	}
+	startedAt := finishedAt
+	if cb.StartedAt != nil {
+		startedAt = *cb.StartedAt
+	}
+	pipeline := cb.Pipeline
+	if pipeline == "" {
+		pipeline = e.config.Provider
+	}
+
+	results := &metrics.PipelineResults{
+		Metadata: &metrics.PrMetadata{
+			Name:     fmt.Sprintf("%s-%d", e.config.Provider, metadata.PipelinerunID),
+			Pipeline: pipeline,
+		},
+		PrResult: &metrics.PrResult{
+			DurationSeconds: finishedAt.Sub(startedAt).Seconds(),
+			Result:          cb.Status,
+			StartTime:       &metav1.Time{Time: startedAt},
+			CompletionTime:  &metav1.Time{Time: finishedAt},
+		},
+	}
+	for _, task := range cb.Tasks {
+		start, end, duration := spans(task.StartedAt, task.FinishedAt)
+		results.TrResults = append(results.TrResults, &metrics.TrResult{
+			Name:            task.Name,
+			Task:            task.Name,
+			StartTime:       start,
+			CompletionTime:  end,
+			DurationSeconds: duration,
+			Result:          task.Result,
+		})
+		for _, step := range task.Steps {
+			start, end, duration := spans(step.StartedAt, step.FinishedAt)
+			results.StepResults = append(results.StepResults, &metrics.StepResult{
+				Step:            step.Name,
+				Task:            task.Name,
+				TaskRun:         task.Name,
+				StartTime:       start,
+				CompletionTime:  end,
+				DurationSeconds: duration,
+				Result:          step.Result,
+			})
+		}
+	}
+
+	return &Result{
+		Result: prmodels.Result{
+			Result:     cb.Status,
+			StartedAt:  &startedAt,
+			FinishedAt: &finishedAt,
+		},
+		Pipeline: results,
+	}, nil
+}
+
+// spans converts the times of a task or step, tasks and steps without the completion time are not saved
+func spans(startedAt, finishedAt *time.Time) (*metav1.Time, *metav1.Time, float64) {
+	if startedAt == nil || finishedAt == nil {
+		return nil, nil, 0
+	}
+	return &metav1.Time{Time: *startedAt}, &metav1.Time{Time: *finishedAt}, finishedAt.Sub(*startedAt).Seconds()
+}
+
+func (e *External) do(ctx context.Context, method, path, contentType string,
+	body io.Reader, expectedCode int) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, method, strings.TrimSuffix(e.config.Server, "/")+path, body)
+	if err != nil {
+		return nil, perror.Wrap(herrors.ErrHTTPRequestFailed, err.Error())
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	if e.config.Token != "" {
+		if e.config.Provider == ProviderGitHub {
+			req.Header.Set("Authorization", "Bearer "+e.config.Token)
+			req.Header.Set("Accept", "application/vnd.github+json")
+		} else {
+			req.Header.Set("PRIVATE-TOKEN", e.config.Token)
+		}
+	}
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return nil, perror.Wrap(herrors.ErrHTTPRequestFailed, err.Error())
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != expectedCode {
+		message := common.Response(ctx, resp)
+		return nil, perror.Wrapf(herrors.ErrHTTPRespNotAsExpected,
+			"statusCode = %d, message = %s", resp.StatusCode, message)
+	}
+	respBytes, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, perror.Wrap(herrors.ErrReadFailed, err.Error())
+	}
+	return respBytes, nil
+}
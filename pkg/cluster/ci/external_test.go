@@ -0,0 +1,212 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ci
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	tektonftymock "github.com/horizoncd/horizon/mock/pkg/cluster/tekton/factory"
+	"github.com/horizoncd/horizon/pkg/cluster/tekton"
+	ciconfig "github.com/horizoncd/horizon/pkg/config/ci"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	prmodels "github.com/horizoncd/horizon/pkg/pipelinerun/models"
+	"github.com/horizoncd/horizon/pkg/server/global"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGitLab(t *testing.T) {
+	ctx := context.Background()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.EscapedPath() {
+		case "/api/v4/projects/group%2Fci/trigger/pipeline":
+			assert.Nil(t, r.ParseForm())
+			assert.Equal(t, "trigger", r.PostForm.Get("token"))
+			assert.Equal(t, "main", r.PostForm.Get("ref"))
+			assert.Contains(t, r.PostForm.Get("variables[HORIZON_PIPELINERUN]"), "\"pipelinerunID\":1")
+			assert.Equal(t, "http://horizon/apis/internal/cicallbacks",
+				r.PostForm.Get("variables[HORIZON_CALLBACK_URL]"))
+			w.WriteHeader(http.StatusCreated)
+			_, _ = w.Write([]byte(`{"id":100}`))
+		case "/api/v4/projects/group%2Fci/pipelines/100/cancel":
+			assert.Equal(t, "token", r.Header.Get("PRIVATE-TOKEN"))
+			_, _ = w.Write([]byte(`{"id":100}`))
+		case "/api/v4/projects/group%2Fci/pipelines/100/jobs":
+			_, _ = w.Write([]byte(`[{"id":2,"name":"deploy","stage":"deploy"},{"id":1,"name":"build","stage":"build"}]`))
+		case "/api/v4/projects/group%2Fci/jobs/1/trace":
+			_, _ = w.Write([]byte("building"))
+		case "/api/v4/projects/group%2Fci/jobs/2/trace":
+			_, _ = w.Write([]byte("deploying"))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	e, err := NewExternal(&ciconfig.External{
+		Provider:     ProviderGitLab,
+		Server:       server.URL + "/api/v4",
+		Project:      "group/ci",
+		Ref:          "main",
+		TriggerToken: "trigger",
+		Token:        "token",
+		CallbackURL:  "http://horizon/apis/internal/cicallbacks",
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, TypeExternal, e.Type())
+
+	id, err := e.CreatePipelineRun(ctx, &tekton.PipelineRun{PipelinerunID: 1})
+	assert.Nil(t, err)
+	assert.Equal(t, "100", id)
+
+	pr := &prmodels.Pipelinerun{CIEventID: id}
+	assert.Nil(t, e.StopPipelineRun(ctx, pr))
+
+	log, err := e.GetPipelineRunLog(ctx, pr)
+	assert.Nil(t, err)
+	assert.Equal(t, "[build] build\nbuilding\n[deploy] deploy\ndeploying\n", string(log.LogBytes))
+
+	err = e.StopPipelineRun(ctx, &prmodels.Pipelinerun{})
+	assert.Equal(t, herrors.ErrParamInvalid, perror.Cause(err))
+
+	// the pipeline which is not found
+	err = e.StopPipelineRun(ctx, &prmodels.Pipelinerun{CIEventID: "101"})
+	assert.Equal(t, herrors.ErrHTTPRespNotAsExpected, perror.Cause(err))
+}
+
+func TestGitHub(t *testing.T) {
+	ctx := context.Background()
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repos/org/ci/actions/workflows/horizon.yaml/dispatches",
+		func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+			w.WriteHeader(http.StatusNoContent)
+		})
+	mux.HandleFunc("/repos/org/ci/actions/runs/200/cancel", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusAccepted)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	_, err := NewExternal(&ciconfig.External{
+		Provider: ProviderGitHub,
+		Server:   server.URL,
+		Project:  "org/ci",
+	})
+	assert.Equal(t, herrors.ErrParamInvalid, perror.Cause(err))
+
+	e, err := NewExternal(&ciconfig.External{
+		Provider: ProviderGitHub,
+		Server:   server.URL,
+		Project:  "org/ci",
+		Ref:      "main",
+		Workflow: "horizon.yaml",
+		Token:    "token",
+	})
+	assert.Nil(t, err)
+
+	id, err := e.CreatePipelineRun(ctx, &tekton.PipelineRun{PipelinerunID: 1})
+	assert.Nil(t, err)
+	assert.Equal(t, "", id)
+
+	assert.Nil(t, e.StopPipelineRun(ctx, &prmodels.Pipelinerun{CIEventID: "200"}))
+}
+
+func TestComplete(t *testing.T) {
+	e, err := NewExternal(&ciconfig.External{
+		Provider:     ProviderGitLab,
+		Server:       "http://gitlab",
+		Project:      "group/ci",
+		TriggerToken: "trigger",
+	})
+	assert.Nil(t, err)
+
+	metadata := &global.HorizonMetaData{PipelinerunID: 1}
+	_, err = e.Complete(context.Background(), &Callback{External: &ExternalCallback{
+		PipelinerunID: 1,
+		Status:        ExternalStatusRunning,
+	}}, metadata)
+	assert.Equal(t, herrors.ErrParamInvalid, perror.Cause(err))
+
+	startedAt := time.Now().Add(-time.Minute)
+	finishedAt := time.Now()
+	result, err := e.Complete(context.Background(), &Callback{External: &ExternalCallback{
+		PipelinerunID: 1,
+		RunID:         "100",
+		Status:        string(prmodels.StatusOK),
+		StartedAt:     &startedAt,
+		FinishedAt:    &finishedAt,
+		Tasks: []*ExternalTask{
+			{
+				Name:       "build",
+				Result:     string(prmodels.StatusOK),
+				StartedAt:  &startedAt,
+				FinishedAt: &finishedAt,
+				Steps: []*ExternalStep{
+					{
+						Name:       "compile",
+						Result:     string(prmodels.StatusOK),
+						StartedAt:  &startedAt,
+						FinishedAt: &finishedAt,
+					},
+				},
+			},
+		},
+	}}, metadata)
+	assert.Nil(t, err)
+	assert.Equal(t, string(prmodels.StatusOK), result.Result.Result)
+	assert.Equal(t, finishedAt, *result.FinishedAt)
+	assert.Equal(t, "gitlab-1", result.Pipeline.Metadata.Name)
+	assert.Equal(t, ProviderGitLab, result.Pipeline.Metadata.Pipeline)
+	assert.Equal(t, 1, len(result.Pipeline.TrResults))
+	assert.Equal(t, 1, len(result.Pipeline.StepResults))
+	assert.Equal(t, "build", result.Pipeline.StepResults[0].Task)
+}
+
+func TestFactory(t *testing.T) {
+	mockCtl := gomock.NewController(t)
+	tektonFty := tektonftymock.NewMockFactory(mockCtl)
+	tektonFty.EXPECT().GetTekton("test").Return(nil, nil)
+	tektonFty.EXPECT().GetTektonCollector("test").Return(nil, nil)
+
+	_, err := NewFactory(ciconfig.Mapper{
+		"online": {Type: "jenkins"},
+	}, tektonFty)
+	assert.Equal(t, herrors.ErrParamInvalid, perror.Cause(err))
+
+	f, err := NewFactory(ciconfig.Mapper{
+		"test": {Type: TypeTekton},
+		"online": {Type: TypeExternal, External: &ciconfig.External{
+			Provider:     ProviderGitLab,
+			Server:       "http://gitlab",
+			Project:      "group/ci",
+			TriggerToken: "trigger",
+		}},
+	}, tektonFty)
+	assert.Nil(t, err)
+
+	engine, err := f.GetEngine("test")
+	assert.Nil(t, err)
+	assert.Equal(t, TypeTekton, engine.Type())
+
+	engine, err = f.GetEngine("online")
+	assert.Nil(t, err)
+	assert.Equal(t, TypeExternal, engine.Type())
+}
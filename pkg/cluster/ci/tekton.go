@@ -0,0 +1,110 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ci
+
+import (
+	"context"
+
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/pkg/cluster/tekton"
+	"github.com/horizoncd/horizon/pkg/cluster/tekton/chains"
+	"github.com/horizoncd/horizon/pkg/cluster/tekton/collector"
+	"github.com/horizoncd/horizon/pkg/cluster/tekton/metrics"
+	"github.com/horizoncd/horizon/pkg/cluster/tekton/report"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	prmodels "github.com/horizoncd/horizon/pkg/pipelinerun/models"
+	"github.com/horizoncd/horizon/pkg/server/global"
+	"github.com/horizoncd/horizon/pkg/util/log"
+)
+
+// Tekton runs pipelines by tekton triggers, and collects their logs and objects when they finish
+type Tekton struct {
+	tekton    tekton.Interface
+	collector collector.Interface
+}
+
+var _ Engine = (*Tekton)(nil)
+
+func NewTekton(t tekton.Interface, c collector.Interface) *Tekton {
+	return &Tekton{
+		tekton:    t,
+		collector: c,
+	}
+}
+
+func (t *Tekton) Type() string {
+	return TypeTekton
+}
+
+func (t *Tekton) CreatePipelineRun(ctx context.Context, pr *tekton.PipelineRun) (string, error) {
+	return t.tekton.CreatePipelineRun(ctx, pr)
+}
+
+func (t *Tekton) StopPipelineRun(ctx context.Context, pr *prmodels.Pipelinerun) error {
+	return t.tekton.StopPipelineRun(ctx, pr.CIEventID)
+}
+
+func (t *Tekton) GetPipelineRunLog(ctx context.Context, pr *prmodels.Pipelinerun) (*collector.Log, error) {
+	return t.collector.GetPipelineRunLog(ctx, pr)
+}
+
+func (t *Tekton) GetPipelineRunReports(ctx context.Context, pr *prmodels.Pipelinerun) ([]*report.Report, error) {
+	tektonPipelineRun, err := t.collector.GetPipelineRun(ctx, pr)
+	if err != nil {
+		return nil, err
+	}
+	return report.Resolve(ctx, tektonPipelineRun), nil
+}
+
+func (t *Tekton) GetPipelineRunReport(ctx context.Context, object string) ([]byte, error) {
+	return t.collector.GetPipelineRunReport(ctx, object)
+}
+
+func (t *Tekton) Complete(ctx context.Context, callback *Callback,
+	metadata *global.HorizonMetaData) (*Result, error) {
+	if callback.PipelineRun == nil {
+		return nil, perror.Wrap(herrors.ErrParamInvalid, "tekton pipelinerun is missing in the callback")
+	}
+	pr, err := t.tekton.GetFinishedPipelineRun(ctx, callback.PipelineRun)
+	if err != nil {
+		return nil, err
+	}
+	result, err := t.collector.Collect(ctx, pr, metadata)
+	if err != nil {
+		return nil, err
+	}
+	// a broken provenance shouldn't fail the pipelinerun
+	provenance, err := chains.Resolve(pr)
+	if err != nil {
+		log.Warningf(ctx, "failed to resolve provenance of pipelinerun %v: %v", metadata.PipelinerunID, err)
+	}
+	if provenance != nil {
+		provenance.PipelinerunID = metadata.PipelinerunID
+		provenance.ClusterID = metadata.ClusterID
+	}
+	return &Result{
+		Result: prmodels.Result{
+			S3Bucket:   result.Bucket,
+			LogObject:  result.LogObject,
+			PrObject:   result.PrObject,
+			Result:     result.Result,
+			StartedAt:  &result.StartTime.Time,
+			FinishedAt: &result.CompletionTime.Time,
+		},
+		Pipeline:   metrics.FormatPipelineResults(pr),
+		Reports:    result.Reports,
+		Provenance: provenance,
+	}, nil
+}
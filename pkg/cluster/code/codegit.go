@@ -16,6 +16,8 @@ package code
 
 import (
 	"context"
+	"encoding/json"
+	"net/http"
 	"regexp"
 
 	herrors "github.com/horizoncd/horizon/core/errors"
@@ -34,6 +36,13 @@ type GitGetter interface {
 	GetHTTPLink(gitURL string) (string, error)
 	GetCommitHistoryLink(gitURL string, commit string) (string, error)
 	GetTagArchive(ctx context.Context, gitURL, tagName string) (*git.Tag, error)
+	// Compare lists the files changed from a commit to another
+	Compare(ctx context.Context, gitURL, from, to string) (*git.Comparison, error)
+	// ParsePushEvent finds the repo which sent the webhook by the repo url in the payload,
+	// then the helper of the repo parses the push event in it, the event is not verified yet
+	ParsePushEvent(header http.Header, body []byte) (*git.PushEvent, error)
+	// VerifyWebhook verifies the webhook parsed by ParsePushEvent with the secret of one of its receivers
+	VerifyWebhook(header http.Header, body []byte, secret string) error
 }
 
 var _ GitGetter = (*gitGetter)(nil)
@@ -137,6 +146,56 @@ func (g *gitGetter) GetTagArchive(ctx context.Context, gitURL, tagName string) (
 	return helper.GetTagArchive(ctx, gitURL, tagName)
 }
 
+func (g *gitGetter) Compare(ctx context.Context, gitURL, from, to string) (*git.Comparison, error) {
+	helper, err := g.getGitHelper(gitURL)
+	if err != nil {
+		return nil, err
+	}
+	return helper.Compare(ctx, gitURL, from, to)
+}
+
+// webhookRepo is the repo url of gitlab and github webhook payloads
+type webhookRepo struct {
+	Project struct {
+		WebURL string `json:"web_url"`
+	} `json:"project"`
+	Repository struct {
+		HTMLURL string `json:"html_url"`
+	} `json:"repository"`
+}
+
+func (g *gitGetter) ParsePushEvent(header http.Header, body []byte) (*git.PushEvent, error) {
+	helper, err := g.getWebhookHelper(body)
+	if err != nil {
+		return nil, err
+	}
+	return helper.ParsePushEvent(header, body)
+}
+
+func (g *gitGetter) VerifyWebhook(header http.Header, body []byte, secret string) error {
+	helper, err := g.getWebhookHelper(body)
+	if err != nil {
+		return err
+	}
+	return helper.VerifyWebhook(header, body, secret)
+}
+
+// getWebhookHelper gets the helper of the repo which sent the webhook
+func (g *gitGetter) getWebhookHelper(body []byte) (git.Helper, error) {
+	var repo webhookRepo
+	if err := json.Unmarshal(body, &repo); err != nil {
+		return nil, perror.Wrapf(herrors.ErrParamInvalid, "invalid webhook payload: %v", err)
+	}
+	repoURL := repo.Project.WebURL
+	if repoURL == "" {
+		repoURL = repo.Repository.HTMLURL
+	}
+	if repoURL == "" {
+		return nil, perror.Wrap(herrors.ErrParamInvalid, "no repo url in webhook payload")
+	}
+	return g.getGitHelper(repoURL)
+}
+
 func (g *gitGetter) getGitHelper(gitURL string) (git.Helper, error) {
 	host, err := extractHostFromURL(gitURL)
 	if err != nil {
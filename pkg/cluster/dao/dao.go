@@ -46,6 +46,7 @@ type DAO interface {
 		withRegion bool, appIDs ...uint) (int, []*models.ClusterWithRegion, error)
 	ListClusterWithExpiry(ctx context.Context, query *q.Query) ([]*models.Cluster, error)
 	GetByNameFuzzily(ctx context.Context, name string, includeSoftDelete bool) ([]*models.Cluster, error)
+	ListByGitURLs(ctx context.Context, gitURLs []string) ([]*models.Cluster, error)
 }
 
 type dao struct {
@@ -266,6 +267,8 @@ func (d *dao) List(ctx context.Context, query *q.Query, userID uint,
 				}
 			case common.ParamApplicationID:
 				statement = statement.Where("c.application_id = ?", v)
+			case common.ClusterQueryByApplications:
+				statement = statement.Where("c.application_id in ?", v)
 			case common.ClusterQueryName:
 				statement = statement.Where("c.name like ?", fmt.Sprintf("%%%v%%", v))
 			case common.ClusterQueryByUser:
@@ -403,3 +406,16 @@ func (d *dao) GetByNameFuzzily(ctx context.Context, name string, includeSoftDele
 
 	return clusters, result.Error
 }
+
+func (d *dao) ListByGitURLs(ctx context.Context, gitURLs []string) ([]*models.Cluster, error) {
+	var clusters []*models.Cluster
+	if len(gitURLs) == 0 {
+		return clusters, nil
+	}
+	result := d.db.WithContext(ctx).Where("git_url in ?", gitURLs).
+		Where("deleted_ts = ?", 0).Order("id asc").Find(&clusters)
+	if result.Error != nil {
+		return nil, herrors.NewErrListFailed(herrors.ClusterInDB, result.Error.Error())
+	}
+	return clusters, nil
+}
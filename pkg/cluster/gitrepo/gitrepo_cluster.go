@@ -539,6 +539,11 @@ func (g *clusterGitopsRepo) CreateCluster(ctx context.Context, params *CreateClu
 		}
 		return gitActions
 	}()
+	templateActions, err := g.templateActions(ctx, pid, params.BaseParams, params.Image, true)
+	if err != nil {
+		return err
+	}
+	actions = append(actions, templateActions...)
 
 	commitMsg := angular.CommitMessage("cluster", angular.Subject{
 		Operator: currentUser.GetName(),
@@ -570,6 +575,9 @@ func (g *clusterGitopsRepo) UpdateCluster(ctx context.Context, params *UpdateClu
 
 	// 1. write files to repo
 	pid := fmt.Sprintf("%v/%v/%v", g.clustersGroup.FullPath, params.Application.Name, params.Cluster)
+	if err := g.checkTemplateKind(ctx, pid, params.TemplateRelease); err != nil {
+		return err
+	}
 	var applicationYAML, pipelineYAML, baseValueYAML, envValueYAML, chartYAML []byte
 	var err1, err2, err3, err4, err5 error
 	if params.Application != nil {
@@ -650,7 +658,11 @@ func (g *clusterGitopsRepo) UpdateCluster(ctx context.Context, params *UpdateClu
 				Content:  string(envValueYAML),
 			})
 		}
-		return gitActions, nil
+		templateActions, err := g.templateActions(ctx, pid, params.BaseParams, "", false)
+		if err != nil {
+			return nil, err
+		}
+		return append(gitActions, templateActions...), nil
 	}()
 	if err != nil {
 		return err
@@ -931,6 +943,20 @@ func (g *clusterGitopsRepo) UpdatePipelineOutput(ctx context.Context, applicatio
 		},
 	}
 
+	pid := fmt.Sprintf("%v/%v/%v", g.clustersGroup.FullPath, application, cluster)
+	if image := pipelineOutputImage(pipelineOutPutInternalFormat); image != "" {
+		action, err := g.updateKustomizationAction(ctx, pid, GitOpsBranch,
+			common.GitopsFileKustomization, func(kustomization *Kustomization) {
+				setKustomizeImage(kustomization, image)
+			})
+		if err != nil {
+			return "", err
+		}
+		if action != nil {
+			actions = append(actions, *action)
+		}
+	}
+
 	currentUser, err := common.UserFromContext(ctx)
 	if err != nil {
 		return "", err
@@ -941,7 +967,6 @@ func (g *clusterGitopsRepo) UpdatePipelineOutput(ctx context.Context, applicatio
 		Cluster:  angular.StringPtr(cluster),
 	}, pipelineOutput)
 
-	pid := fmt.Sprintf("%v/%v/%v", g.clustersGroup.FullPath, application, cluster)
 	commit, err := g.gitlabLib.WriteFiles(ctx, pid, GitOpsBranch, commitMsg, nil, actions)
 	if err != nil {
 		return "", perror.WithMessage(err, "failed to write gitlab files")
@@ -988,7 +1013,8 @@ func (g *clusterGitopsRepo) UpdateRestartTime(ctx context.Context,
 
 	var restartYAML []byte
 	var err1 error
-	marshal(&restartYAML, &err1, assembleRestart(template))
+	restart := assembleRestart(template)
+	marshal(&restartYAML, &err1, restart)
 	if err1 != nil {
 		return "", err1
 	}
@@ -1000,6 +1026,16 @@ func (g *clusterGitopsRepo) UpdateRestartTime(ctx context.Context,
 			Content:  string(restartYAML),
 		},
 	}
+	action, err := g.updateKustomizationAction(ctx, pid, g.defaultBranch,
+		common.GitopsFileRestartKustomization, func(kustomization *Kustomization) {
+			setKustomizeRestartTime(kustomization, restart[template]["restartTime"])
+		})
+	if err != nil {
+		return "", err
+	}
+	if action != nil {
+		actions = append(actions, *action)
+	}
 
 	commitMsg := angular.CommitMessage("cluster", angular.Subject{
 		Operator: currentUser.GetName(),
@@ -1217,6 +1253,11 @@ func (g *clusterGitopsRepo) UpgradeCluster(ctx context.Context,
 		return "", err
 	}
 
+	if err := templaterepo.CheckChartDigest(g.templateRepo, param.TargetRelease.ChartName,
+		param.TargetRelease.ChartVersion, param.TargetRelease.ChartDigest); err != nil {
+		return "", err
+	}
+
 	pid := fmt.Sprintf("%v/%v/%v", g.clustersGroup.FullPath,
 		param.Application, param.Cluster)
 
@@ -1645,10 +1686,11 @@ func (g *clusterGitopsRepo) assembleBaseValue(params *BaseParams) map[string]map
 }
 
 type Chart struct {
-	APIVersion   string       `yaml:"apiVersion"`
-	Name         string       `yaml:"name"`
-	Version      string       `yaml:"version"`
-	Dependencies []Dependency `yaml:"dependencies"`
+	APIVersion   string            `yaml:"apiVersion"`
+	Name         string            `yaml:"name"`
+	Version      string            `yaml:"version"`
+	Annotations  map[string]string `yaml:"annotations,omitempty"`
+	Dependencies []Dependency      `yaml:"dependencies"`
 }
 
 type Dependency struct {
@@ -1658,11 +1700,21 @@ type Dependency struct {
 }
 
 func (g *clusterGitopsRepo) assembleChart(params *BaseParams) (*Chart, error) {
+	// the dependency refers to the chart by its version, which must still be the chart of the release
+	if err := templaterepo.CheckChartDigest(g.templateRepo, params.TemplateRelease.ChartName,
+		params.TemplateRelease.ChartVersion, params.TemplateRelease.ChartDigest); err != nil {
+		return nil, err
+	}
 	templateRepo := g.templateRepo.GetLoc()
+	var annotations map[string]string
+	if kind := templateKind(params.TemplateRelease); kind != templaterepo.KindHelm {
+		annotations = map[string]string{templaterepo.KindAnnotation: kind}
+	}
 	return &Chart{
-		APIVersion: "v2",
-		Name:       params.Cluster,
-		Version:    "1.0.0",
+		APIVersion:  "v2",
+		Name:        params.Cluster,
+		Version:     "1.0.0",
+		Annotations: annotations,
 		Dependencies: []Dependency{
 			{
 				Repository: templateRepo,
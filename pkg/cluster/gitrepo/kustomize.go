@@ -0,0 +1,351 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gitrepo
+
+import (
+	"context"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	gitlablib "github.com/horizoncd/horizon/lib/gitlab"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	trmodels "github.com/horizoncd/horizon/pkg/templaterelease/models"
+	"github.com/horizoncd/horizon/pkg/templaterelease/schema"
+	"github.com/horizoncd/horizon/pkg/templaterepo"
+	timeutil "github.com/horizoncd/horizon/pkg/util/time"
+	"gopkg.in/yaml.v3"
+	"helm.sh/helm/v3/pkg/chart"
+)
+
+const (
+	_applicationSchemaPath = "schema/application.schema.json"
+	_pipelineOutputImage   = "image"
+)
+
+// Kustomization is the kustomization.yaml at the root of cluster repo for kustomize templates.
+// The template's base is vendored into GitopsDirTemplate, and it's built through the layer of
+// GitopsFileRestartKustomization, which is only updated in default branch on restarting just
+// like GitopsFileRestart, so that merging gitops branch does not conflict with it.
+type Kustomization struct {
+	APIVersion        string            `yaml:"apiVersion"`
+	Kind              string            `yaml:"kind"`
+	Resources         []string          `yaml:"resources"`
+	CommonAnnotations map[string]string `yaml:"commonAnnotations,omitempty"`
+	Images            []KustomizeImage  `yaml:"images,omitempty"`
+	Patches           []KustomizePatch  `yaml:"patches,omitempty"`
+}
+
+type KustomizeImage struct {
+	Name    string `yaml:"name"`
+	NewName string `yaml:"newName,omitempty"`
+	NewTag  string `yaml:"newTag,omitempty"`
+	Digest  string `yaml:"digest,omitempty"`
+}
+
+type KustomizePatch struct {
+	Target schema.PatchTarget `yaml:"target"`
+	Patch  string             `yaml:"patch"`
+}
+
+func templateKind(tr *trmodels.TemplateRelease) string {
+	switch tr.Kind {
+	case templaterepo.KindKustomize, templaterepo.KindRaw:
+		return tr.Kind
+	default:
+		return templaterepo.KindHelm
+	}
+}
+
+// templateActions returns the actions to vendor files of kustomize or raw templates into cluster repo,
+// and to write kustomization.yaml for kustomize templates. Files left by the former release are deleted.
+func (g *clusterGitopsRepo) templateActions(ctx context.Context, pid string,
+	params *BaseParams, image string, create bool) ([]gitlablib.CommitAction, error) {
+	kind := templateKind(params.TemplateRelease)
+	if kind == templaterepo.KindHelm {
+		return nil, nil
+	}
+
+	tr := params.TemplateRelease
+	chartPkg, err := g.templateRepo.GetChart(tr.ChartName, tr.ChartReference(), tr.LastSyncAt)
+	if err != nil {
+		return nil, err
+	}
+
+	existing := make(map[string]bool)
+	if !create {
+		files, err := g.gitlabLib.ListFiles(ctx, pid, GitOpsBranch, common.GitopsDirTemplate)
+		if err != nil {
+			if _, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); !ok {
+				return nil, err
+			}
+		}
+		for _, file := range files {
+			existing[file] = true
+		}
+	}
+
+	var actions []gitlablib.CommitAction
+	for _, file := range templaterepo.TemplateFiles(chartPkg) {
+		filePath := path.Join(common.GitopsDirTemplate, file.Name)
+		action := gitlablib.FileCreate
+		if existing[filePath] {
+			action = gitlablib.FileUpdate
+			delete(existing, filePath)
+		}
+		actions = append(actions, gitlablib.CommitAction{
+			Action:   action,
+			FilePath: filePath,
+			Content:  string(file.Data),
+		})
+	}
+	for filePath := range existing {
+		actions = append(actions, gitlablib.CommitAction{
+			Action:   gitlablib.FileDelete,
+			FilePath: filePath,
+		})
+	}
+
+	if kind != templaterepo.KindKustomize {
+		return actions, nil
+	}
+
+	kustomization, err := assembleKustomization(chartPkg, params)
+	if err != nil {
+		return nil, err
+	}
+	action := gitlablib.FileCreate
+	if !create {
+		current, err := g.getKustomization(ctx, pid, GitOpsBranch, common.GitopsFileKustomization)
+		if err != nil {
+			if _, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); !ok {
+				return nil, err
+			}
+		} else {
+			action = gitlablib.FileUpdate
+			inheritImages(kustomization, current)
+		}
+	}
+	if image != "" {
+		setKustomizeImage(kustomization, image)
+	}
+	var kustomizationYAML []byte
+	marshal(&kustomizationYAML, &err, kustomization)
+	if err != nil {
+		return nil, err
+	}
+	actions = append(actions, gitlablib.CommitAction{
+		Action:   action,
+		FilePath: common.GitopsFileKustomization,
+		Content:  string(kustomizationYAML),
+	})
+
+	if create {
+		var restartYAML []byte
+		marshal(&restartYAML, &err, assembleRestartKustomization(timeutil.Now(nil)))
+		if err != nil {
+			return nil, err
+		}
+		actions = append(actions, gitlablib.CommitAction{
+			Action:   gitlablib.FileCreate,
+			FilePath: common.GitopsFileRestartKustomization,
+			Content:  string(restartYAML),
+		})
+	}
+	return actions, nil
+}
+
+// checkTemplateKind checks that the template kind of cluster is not changed,
+// because the source of argo application is not updated once created
+func (g *clusterGitopsRepo) checkTemplateKind(ctx context.Context, pid string, tr *trmodels.TemplateRelease) error {
+	file, err := g.gitlabLib.GetFile(ctx, pid, GitOpsBranch, common.GitopsFileChart)
+	if err != nil {
+		return err
+	}
+	var current Chart
+	if err := yaml.Unmarshal(file, &current); err != nil {
+		return perror.Wrapf(herrors.ErrParamInvalid,
+			"yaml Unmarshal err, file = %s", common.GitopsFileChart)
+	}
+	currentKind := templaterepo.KindHelm
+	if kind, ok := current.Annotations[templaterepo.KindAnnotation]; ok {
+		currentKind = kind
+	}
+	if kind := templateKind(tr); kind != currentKind {
+		return perror.Wrapf(herrors.ErrParamInvalid,
+			"template kind of cluster cannot be changed from %s to %s", currentKind, kind)
+	}
+	return nil
+}
+
+func (g *clusterGitopsRepo) getKustomization(ctx context.Context,
+	pid, ref, filePath string) (*Kustomization, error) {
+	file, err := g.gitlabLib.GetFile(ctx, pid, ref, filePath)
+	if err != nil {
+		return nil, err
+	}
+	var kustomization Kustomization
+	if err := yaml.Unmarshal(file, &kustomization); err != nil {
+		return nil, perror.Wrapf(herrors.ErrParamInvalid,
+			"yaml Unmarshal err, file = %s", filePath)
+	}
+	return &kustomization, nil
+}
+
+// updateKustomizationAction returns the action to update the kustomization file by update,
+// or nil if cluster is not deployed from a kustomize template
+func (g *clusterGitopsRepo) updateKustomizationAction(ctx context.Context, pid, ref, filePath string,
+	update func(kustomization *Kustomization)) (*gitlablib.CommitAction, error) {
+	kustomization, err := g.getKustomization(ctx, pid, ref, filePath)
+	if err != nil {
+		if _, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); ok {
+			return nil, nil
+		}
+		return nil, err
+	}
+	update(kustomization)
+
+	var kustomizationYAML []byte
+	marshal(&kustomizationYAML, &err, kustomization)
+	if err != nil {
+		return nil, err
+	}
+	return &gitlablib.CommitAction{
+		Action:   gitlablib.FileUpdate,
+		FilePath: filePath,
+		Content:  string(kustomizationYAML),
+	}, nil
+}
+
+// assembleKustomization maps application values onto patches of the template's base,
+// as declared by the template's application schema
+func assembleKustomization(chartPkg *chart.Chart, params *BaseParams) (*Kustomization, error) {
+	kustomization := &Kustomization{
+		APIVersion: "kustomize.config.k8s.io/v1beta1",
+		Kind:       "Kustomization",
+		Resources:  []string{path.Dir(common.GitopsFileRestartKustomization)},
+	}
+
+	var schemaBytes []byte
+	for _, file := range chartPkg.Files {
+		if file.Name == _applicationSchemaPath {
+			schemaBytes = file.Data
+		}
+	}
+	if schemaBytes == nil {
+		return kustomization, nil
+	}
+	schemas, err := schema.ParseFiles(map[string]string{
+		schema.ClusterIDKey:    strconv.FormatUint(uint64(params.ClusterID), 10),
+		schema.ResourceTypeKey: "cluster",
+	}, nil, schemaBytes, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if name := schema.ImageName(schemas.Application.JSONSchema); name != "" {
+		kustomization.Images = []KustomizeImage{{Name: name}}
+	}
+
+	ops, err := schema.Patches(schemas.Application.JSONSchema, params.ApplicationJSONBlob)
+	if err != nil {
+		return nil, err
+	}
+	// operations on the same target are put into one patch, in the order of declaration
+	var targets []schema.PatchTarget
+	opsByTarget := make(map[schema.PatchTarget][]*schema.PatchOperation)
+	for _, op := range ops {
+		if _, ok := opsByTarget[op.Target]; !ok {
+			targets = append(targets, op.Target)
+		}
+		opsByTarget[op.Target] = append(opsByTarget[op.Target], op)
+	}
+	for _, target := range targets {
+		var patch []byte
+		marshal(&patch, &err, opsByTarget[target])
+		if err != nil {
+			return nil, err
+		}
+		kustomization.Patches = append(kustomization.Patches, KustomizePatch{
+			Target: target,
+			Patch:  string(patch),
+		})
+	}
+	return kustomization, nil
+}
+
+// inheritImages keeps the images built by pipeline in current kustomization
+func inheritImages(kustomization, current *Kustomization) {
+	for i := range kustomization.Images {
+		for _, image := range current.Images {
+			if image.Name == kustomization.Images[i].Name {
+				kustomization.Images[i] = image
+			}
+		}
+	}
+}
+
+func assembleRestartKustomization(restartTime string) *Kustomization {
+	kustomization := &Kustomization{
+		APIVersion: "kustomize.config.k8s.io/v1beta1",
+		Kind:       "Kustomization",
+		Resources:  []string{"../../" + common.GitopsDirTemplate},
+	}
+	setKustomizeRestartTime(kustomization, restartTime)
+	return kustomization
+}
+
+// setKustomizeImage replaces the images declared by template with image built by pipeline
+func setKustomizeImage(kustomization *Kustomization, image string) {
+	name, tag, digest := splitImage(image)
+	for i := range kustomization.Images {
+		kustomization.Images[i].NewName = name
+		kustomization.Images[i].NewTag = tag
+		kustomization.Images[i].Digest = digest
+	}
+}
+
+// setKustomizeRestartTime annotates all the resources including pod templates with restart time,
+// so that pods are recreated
+func setKustomizeRestartTime(kustomization *Kustomization, restartTime string) {
+	if kustomization.CommonAnnotations == nil {
+		kustomization.CommonAnnotations = make(map[string]string)
+	}
+	kustomization.CommonAnnotations[common.ClusterRestartTimeKey] = restartTime
+}
+
+// splitImage splits image into name, tag and digest,
+// e.g. harbor.com/app/demo:v1 is split into harbor.com/app/demo and v1
+func splitImage(image string) (name, tag, digest string) {
+	name = image
+	if i := strings.Index(name, "@"); i >= 0 {
+		name, digest = name[:i], name[i+1:]
+	}
+	// the colon after the last slash separates tag, otherwise it's a port of registry
+	if i := strings.LastIndex(name, ":"); i > strings.LastIndex(name, "/") {
+		name, tag = name[:i], name[i+1:]
+	}
+	return name, tag, digest
+}
+
+func pipelineOutputImage(pipelineOutput map[string]interface{}) string {
+	image, ok := pipelineOutput[_pipelineOutputImage].(string)
+	if !ok {
+		return ""
+	}
+	return image
+}
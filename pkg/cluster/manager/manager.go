@@ -43,6 +43,8 @@ type Manager interface {
 	ListByApplicationID(ctx context.Context, applicationID uint) (int, []*models.ClusterWithRegion, error)
 	ListClusterWithExpiry(ctx context.Context, query *q.Query) ([]*models.Cluster, error)
 	GetByNameFuzzilyIncludeSoftDelete(ctx context.Context, name string) ([]*models.Cluster, error)
+	// ListByGitURLs lists the clusters building from any of the git urls
+	ListByGitURLs(ctx context.Context, gitURLs []string) ([]*models.Cluster, error)
 }
 
 func New(db *gorm.DB) Manager {
@@ -146,3 +148,7 @@ func (m *manager) ListClusterWithExpiry(ctx context.Context,
 	}
 	return m.dao.ListClusterWithExpiry(ctx, query)
 }
+
+func (m *manager) ListByGitURLs(ctx context.Context, gitURLs []string) ([]*models.Cluster, error) {
+	return m.dao.ListByGitURLs(ctx, gitURLs)
+}
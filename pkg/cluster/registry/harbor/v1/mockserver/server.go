@@ -56,6 +56,8 @@ func NewHarborServer() *HarborServer {
 
 	r.Path("/api/repositories/{project}/{repository:[0-9a-zA-Z/-]+}").
 		Methods(http.MethodDelete).HandlerFunc(s.DeleteRepository)
+	r.Path("/api/repositories/{project}/{repository:[0-9a-zA-Z/-]+}/tags/{reference}").
+		Methods(http.MethodGet).HandlerFunc(s.GetArtifact)
 	return s
 }
 
@@ -153,6 +155,28 @@ func (s *HarborServer) DeleteRepository(w http.ResponseWriter, r *http.Request)
 	w.WriteHeader(http.StatusOK)
 }
 
+func (s *HarborServer) GetArtifact(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	project, repository, reference := vars["project"], vars["repository"], vars["reference"]
+	for _, p := range s.Projects {
+		if p.Name != project {
+			continue
+		}
+		for _, repo := range p.Repositories {
+			if repo.Name != repository {
+				continue
+			}
+			for _, tag := range repo.Tags {
+				if tag == reference {
+					w.WriteHeader(http.StatusOK)
+					return
+				}
+			}
+		}
+	}
+	s.responseError(w, http.StatusNotFound, fmt.Errorf("artifact %s:%s not found", repository, reference))
+}
+
 func (s *HarborServer) responseError(w http.ResponseWriter, code int, err error) {
 	w.WriteHeader(code)
 	if err != nil {
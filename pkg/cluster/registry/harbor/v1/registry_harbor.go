@@ -112,6 +112,36 @@ func (h *Registry) DeleteImage(ctx context.Context, appName string, clusterName
 	return perror.Wrap(herrors.ErrHTTPRespNotAsExpected, common.Response(ctx, resp))
 }
 
+func (h *Registry) ImageExists(ctx context.Context, imageURL string) (_ bool, err error) {
+	const op = "registry: image exists"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	repository, reference, err := registry.ParseImage(&registry.Config{
+		Server: h.server,
+		Path:   h.path,
+	}, imageURL)
+	if err != nil {
+		return false, err
+	}
+	link := path.Join("/api/repositories", h.path, repository, "tags", reference)
+
+	link = fmt.Sprintf("%s%s", strings.TrimSuffix(h.server, "/"), link)
+
+	resp, err := h.sendHTTPRequest(ctx, http.MethodGet, link, nil, true, "getArtifact")
+	if err != nil {
+		return false, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return true, nil
+	case http.StatusNotFound:
+		return false, nil
+	}
+	return false, perror.Wrap(herrors.ErrHTTPRespNotAsExpected, common.Response(ctx, resp))
+}
+
 func (h *Registry) sendHTTPRequest(ctx context.Context, method string,
 	url string, body io.Reader, retry bool, operation string) (*http.Response, error) {
 	begin := time.Now()
@@ -19,6 +19,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"strings"
 	"testing"
 
 	"github.com/horizoncd/horizon/pkg/cluster/registry"
@@ -44,7 +45,18 @@ func TestByMock(t *testing.T) {
 	server.CreateProject("project1", nil)
 	server.PushImage("project1", "horizon-demo/horizon-demo-dev", "v1")
 
-	err := h.DeleteImage(ctx, "horizon-demo", "horizon-demo-dev")
+	server.PushImage("project1", "horizon-demo/horizon-demo-dev", "v2")
+	host := strings.TrimPrefix(config.Server, "http://")
+	exists, err := h.ImageExists(ctx, host+"/project1/horizon-demo/horizon-demo-dev:v2")
+	assert.Nil(t, err)
+	assert.True(t, exists)
+	exists, err = h.ImageExists(ctx, host+"/project1/horizon-demo/horizon-demo-dev:v3")
+	assert.Nil(t, err)
+	assert.False(t, exists)
+	_, err = h.ImageExists(ctx, "docker.io/project1/horizon-demo/horizon-demo-dev:v2")
+	assert.NotNil(t, err)
+
+	err = h.DeleteImage(ctx, "horizon-demo", "horizon-demo-dev")
 	assert.Nil(t, err)
 	err = h.DeleteImage(ctx, "horizon-demo", "horizon-demo-dev")
 	assert.Nil(t, err)
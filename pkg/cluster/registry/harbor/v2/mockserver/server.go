@@ -55,6 +55,8 @@ func NewHarborServer() *HarborServer {
 	}
 	r.Path("/api/v2.0/projects/{project}/repositories/{repository}").
 		Methods(http.MethodDelete).HandlerFunc(s.DeleteRepository)
+	r.Path("/api/v2.0/projects/{project}/repositories/{repository:.+}/artifacts/{reference}").
+		Methods(http.MethodGet).HandlerFunc(s.GetArtifact)
 	return s
 }
 
@@ -152,6 +154,28 @@ func (s *HarborServer) DeleteRepository(w http.ResponseWriter, r *http.Request)
 	w.WriteHeader(http.StatusOK)
 }
 
+func (s *HarborServer) GetArtifact(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	project, repository, reference := vars["project"], vars["repository"], vars["reference"]
+	for _, p := range s.Projects {
+		if p.Name != project {
+			continue
+		}
+		for _, repo := range p.Repositories {
+			if repo.Name != repository {
+				continue
+			}
+			for _, tag := range repo.Tags {
+				if tag == reference {
+					w.WriteHeader(http.StatusOK)
+					return
+				}
+			}
+		}
+	}
+	s.responseError(w, http.StatusNotFound, fmt.Errorf("artifact %s:%s not found", repository, reference))
+}
+
 func (s *HarborServer) responseError(w http.ResponseWriter, code int, err error) {
 	w.WriteHeader(code)
 	if err != nil {
@@ -16,6 +16,7 @@ package registry
 
 import (
 	"context"
+	"strings"
 
 	herrors "github.com/horizoncd/horizon/core/errors"
 	perror "github.com/horizoncd/horizon/pkg/errors"
@@ -45,6 +46,8 @@ func Register(kind string, constructor Constructor) {
 type Registry interface {
 	// DeleteImage delete repository
 	DeleteImage(ctx context.Context, appName string, clusterName string) error
+	// ImageExists checks whether the image is still kept by the registry
+	ImageExists(ctx context.Context, imageURL string) (bool, error)
 }
 
 type Config struct {
@@ -64,3 +67,30 @@ func NewRegistry(config *Config) (Registry, error) {
 	}
 	return nil, perror.Wrapf(herrors.ErrParamInvalid, "kind = %v is not implement", config.Kind)
 }
+
+// ParseImage splits the image url into its repository and its reference, which is the tag or the digest.
+// The server and the path of the registry are trimmed from the repository,
+// and images which are not in the path of the registry are invalid
+func ParseImage(config *Config, imageURL string) (repository string, reference string, err error) {
+	domain := strings.TrimPrefix(strings.TrimPrefix(config.Server, "http://"), "https://")
+	prefix := strings.Trim(domain, "/") + "/"
+	if config.Path != "" {
+		prefix += strings.Trim(config.Path, "/") + "/"
+	}
+	if !strings.HasPrefix(imageURL, prefix) {
+		return "", "", perror.Wrapf(herrors.ErrParamInvalid,
+			"image %s is not in the registry %s", imageURL, prefix)
+	}
+	repository = strings.TrimPrefix(imageURL, prefix)
+	if i := strings.Index(repository, "@"); i >= 0 {
+		repository, reference = repository[:i], repository[i+1:]
+	} else if i := strings.LastIndex(repository, ":"); i >= 0 {
+		repository, reference = repository[:i], repository[i+1:]
+	} else {
+		reference = "latest"
+	}
+	if repository == "" || reference == "" {
+		return "", "", perror.Wrapf(herrors.ErrParamInvalid, "image %s is invalid", imageURL)
+	}
+	return repository, reference, nil
+}
@@ -0,0 +1,142 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package chains resolves the SLSA provenance which tekton chains signs and stores
+// in the annotations of a finished pipelinerun.
+package chains
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/horizoncd/horizon/pkg/pipelinerun/provenance/models"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+)
+
+const (
+	// AnnotationSigned is set to true once chains has signed the pipelinerun, or failed if it gives up
+	AnnotationSigned = "chains.tekton.dev/signed"
+
+	_annotationPayloadPrefix   = "chains.tekton.dev/payload-"
+	_annotationSignaturePrefix = "chains.tekton.dev/signature-"
+
+	_signedTrue   = "true"
+	_signedFailed = "failed"
+)
+
+// IsSigned tells whether chains has signed the pipelinerun
+func IsSigned(pr *v1beta1.PipelineRun) bool {
+	return pr != nil && pr.Annotations[AnnotationSigned] == _signedTrue
+}
+
+// IsSignDone tells whether chains has finished with the pipelinerun, whether it's signed or not
+func IsSignDone(pr *v1beta1.PipelineRun) bool {
+	if pr == nil {
+		return false
+	}
+	signed := pr.Annotations[AnnotationSigned]
+	return signed == _signedTrue || signed == _signedFailed
+}
+
+type (
+	// envelope is the DSSE envelope which the payload is wrapped in when chains signs in-toto format
+	envelope struct {
+		PayloadType string `json:"payloadType"`
+		Payload     string `json:"payload"`
+	}
+
+	statement struct {
+		PredicateType string             `json:"predicateType"`
+		Subject       []*models.Artifact `json:"subject"`
+		Predicate     predicate          `json:"predicate"`
+	}
+
+	// predicate holds the fields of both SLSA v0.2 and v1.0 which horizon cares about
+	predicate struct {
+		// SLSA v0.2
+		Builder   *builder           `json:"builder"`
+		BuildType string             `json:"buildType"`
+		Materials []*models.Artifact `json:"materials"`
+
+		// SLSA v1.0
+		BuildDefinition *struct {
+			BuildType            string             `json:"buildType"`
+			ResolvedDependencies []*models.Artifact `json:"resolvedDependencies"`
+		} `json:"buildDefinition"`
+		RunDetails *struct {
+			Builder *builder `json:"builder"`
+		} `json:"runDetails"`
+	}
+
+	builder struct {
+		ID string `json:"id"`
+	}
+)
+
+// Resolve resolves the provenance of the pipelinerun, nil is returned if it's not signed
+func Resolve(pr *v1beta1.PipelineRun) (*models.Provenance, error) {
+	if !IsSigned(pr) {
+		return nil, nil
+	}
+
+	var key, payload string
+	for k, v := range pr.Annotations {
+		if strings.HasPrefix(k, _annotationPayloadPrefix) {
+			key, payload = strings.TrimPrefix(k, _annotationPayloadPrefix), v
+			break
+		}
+	}
+	if payload == "" {
+		return nil, nil
+	}
+
+	raw, err := base64.StdEncoding.DecodeString(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode payload of %s: %v", key, err)
+	}
+	var env envelope
+	if err := json.Unmarshal(raw, &env); err == nil && env.PayloadType != "" && env.Payload != "" {
+		if raw, err = base64.StdEncoding.DecodeString(env.Payload); err != nil {
+			return nil, fmt.Errorf("failed to decode envelope payload of %s: %v", key, err)
+		}
+	}
+
+	var s statement
+	if err := json.Unmarshal(raw, &s); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal statement of %s: %v", key, err)
+	}
+
+	p := &models.Provenance{
+		PredicateType: s.PredicateType,
+		BuildType:     s.Predicate.BuildType,
+		Statement:     string(raw),
+		Signature:     pr.Annotations[_annotationSignaturePrefix+key],
+	}
+	materials := s.Predicate.Materials
+	if s.Predicate.Builder != nil {
+		p.BuilderID = s.Predicate.Builder.ID
+	}
+	if def := s.Predicate.BuildDefinition; def != nil {
+		p.BuildType = def.BuildType
+		materials = def.ResolvedDependencies
+	}
+	if details := s.Predicate.RunDetails; details != nil && details.Builder != nil {
+		p.BuilderID = details.Builder.ID
+	}
+	p.SetSubjects(s.Subject)
+	p.SetMaterials(materials)
+	return p, nil
+}
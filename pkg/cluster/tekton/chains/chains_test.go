@@ -0,0 +1,107 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package chains
+
+import (
+	"encoding/base64"
+	"testing"
+
+	"github.com/horizoncd/horizon/pkg/pipelinerun/provenance/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	statementV02 = `{"_type":"https://in-toto.io/Statement/v0.1","predicateType":"https://slsa.dev/provenance/v0.2",` +
+		`"subject":[{"name":"harbor.com/app/cluster","digest":{"sha256":"abc"}}],` +
+		`"predicate":{"builder":{"id":"https://tekton.dev/chains/v2"},` +
+		`"buildType":"tekton.dev/v1beta1/PipelineRun",` +
+		`"materials":[{"uri":"git+https://g.com/app.git","digest":{"sha1":"123"}}]}}`
+	statementV1 = `{"_type":"https://in-toto.io/Statement/v1","predicateType":"https://slsa.dev/provenance/v1",` +
+		`"subject":[{"name":"harbor.com/app/cluster","digest":{"sha256":"abc"}}],` +
+		`"predicate":{"buildDefinition":{"buildType":"https://tekton.dev/chains/v2/slsa",` +
+		`"resolvedDependencies":[{"uri":"git+https://g.com/app.git","digest":{"sha1":"123"}}]},` +
+		`"runDetails":{"builder":{"id":"https://tekton.dev/chains/v2"}}}}`
+)
+
+func pipelineRun(annotations map[string]string) *v1beta1.PipelineRun {
+	return &v1beta1.PipelineRun{ObjectMeta: metav1.ObjectMeta{Annotations: annotations}}
+}
+
+func encode(s string) string {
+	return base64.StdEncoding.EncodeToString([]byte(s))
+}
+
+func TestResolve(t *testing.T) {
+	subjects := []*models.Artifact{{Name: "harbor.com/app/cluster", Digest: map[string]string{"sha256": "abc"}}}
+	materials := []*models.Artifact{{URI: "git+https://g.com/app.git", Digest: map[string]string{"sha1": "123"}}}
+
+	// not signed yet
+	pr := pipelineRun(nil)
+	assert.False(t, IsSignDone(pr))
+	p, err := Resolve(pr)
+	assert.Nil(t, err)
+	assert.Nil(t, p)
+
+	// failed to sign
+	pr = pipelineRun(map[string]string{AnnotationSigned: "failed"})
+	assert.True(t, IsSignDone(pr))
+	p, err = Resolve(pr)
+	assert.Nil(t, err)
+	assert.Nil(t, p)
+
+	// slsa v0.2
+	pr = pipelineRun(map[string]string{
+		AnnotationSigned:                     "true",
+		"chains.tekton.dev/payload-pr-1":     encode(statementV02),
+		"chains.tekton.dev/signature-pr-1":   "c2lnbmF0dXJl",
+		"chains.tekton.dev/transparency-pr1": "https://rekor.sigstore.dev/api/v1/log/entries?logIndex=1",
+	})
+	p, err = Resolve(pr)
+	assert.Nil(t, err)
+	assert.Equal(t, "https://slsa.dev/provenance/v0.2", p.PredicateType)
+	assert.Equal(t, "https://tekton.dev/chains/v2", p.BuilderID)
+	assert.Equal(t, "tekton.dev/v1beta1/PipelineRun", p.BuildType)
+	assert.Equal(t, "c2lnbmF0dXJl", p.Signature)
+	assert.Equal(t, statementV02, p.Statement)
+	assert.Equal(t, subjects, p.GetSubjects())
+	assert.Equal(t, materials, p.GetMaterials())
+
+	// slsa v1.0 in a dsse envelope
+	envelope := `{"payloadType":"application/vnd.in-toto+json","payload":"` + encode(statementV1) +
+		`","signatures":[{"sig":"c2ln"}]}`
+	pr = pipelineRun(map[string]string{
+		AnnotationSigned:                   "true",
+		"chains.tekton.dev/payload-pr-1":   encode(envelope),
+		"chains.tekton.dev/signature-pr-1": "c2ln",
+	})
+	p, err = Resolve(pr)
+	assert.Nil(t, err)
+	assert.Equal(t, "https://slsa.dev/provenance/v1", p.PredicateType)
+	assert.Equal(t, "https://tekton.dev/chains/v2", p.BuilderID)
+	assert.Equal(t, "https://tekton.dev/chains/v2/slsa", p.BuildType)
+	assert.Equal(t, statementV1, p.Statement)
+	assert.Equal(t, subjects, p.GetSubjects())
+	assert.Equal(t, materials, p.GetMaterials())
+
+	// broken payload
+	pr = pipelineRun(map[string]string{
+		AnnotationSigned:                 "true",
+		"chains.tekton.dev/payload-pr-1": "not base64",
+	})
+	_, err = Resolve(pr)
+	assert.NotNil(t, err)
+}
@@ -19,7 +19,9 @@ import (
 	"strconv"
 
 	"github.com/horizoncd/horizon/pkg/cluster/tekton/log"
+	"github.com/horizoncd/horizon/pkg/cluster/tekton/report"
 	prmodels "github.com/horizoncd/horizon/pkg/pipelinerun/models"
+	reportmodels "github.com/horizoncd/horizon/pkg/pipelinerun/report/models"
 	"github.com/horizoncd/horizon/pkg/server/global"
 	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -110,6 +112,9 @@ type Interface interface {
 
 	// GetPipelineRun gets tekton pipelinerun
 	GetPipelineRun(ctx context.Context, pr *prmodels.Pipelinerun) (*v1beta1.PipelineRun, error)
+
+	// GetPipelineRunReport gets the file of a report collected
+	GetPipelineRunReport(ctx context.Context, object string) ([]byte, error)
 }
 
 var _ Interface = (*S3Collector)(nil)
@@ -117,3 +122,17 @@ var _ Interface = (*S3Collector)(nil)
 func resolveObjMetadata(pr *v1beta1.PipelineRun, horizonMetaData *global.HorizonMetaData) *ObjectMeta {
 	return NewObjectMeta(horizonMetaData, pr)
 }
+
+// newReport converts the report read from the pipelineRun into the one to be saved
+func newReport(horizonMetaData *global.HorizonMetaData, r *report.Report, object string) *reportmodels.Report {
+	return &reportmodels.Report{
+		PipelinerunID: horizonMetaData.PipelinerunID,
+		ClusterID:     horizonMetaData.ClusterID,
+		Task:          r.Task,
+		Name:          r.Name,
+		Kind:          r.Kind,
+		Format:        r.Format,
+		Object:        object,
+		Summary:       *r.Summary,
+	}
+}
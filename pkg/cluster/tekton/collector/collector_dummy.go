@@ -19,6 +19,7 @@ import (
 
 	herrors "github.com/horizoncd/horizon/core/errors"
 	"github.com/horizoncd/horizon/pkg/cluster/tekton"
+	"github.com/horizoncd/horizon/pkg/cluster/tekton/report"
 	perror "github.com/horizoncd/horizon/pkg/errors"
 	prmodels "github.com/horizoncd/horizon/pkg/pipelinerun/models"
 	"github.com/horizoncd/horizon/pkg/server/global"
@@ -48,6 +49,10 @@ func (c *DummyCollector) Collect(ctx context.Context, pr *v1beta1.PipelineRun,
 		StartTime:      metadata.PipelineRun.StartTime,
 		CompletionTime: metadata.PipelineRun.CompletionTime,
 	}
+	// only the summaries of reports are kept
+	for _, r := range report.Resolve(ctx, pr) {
+		collectResult.Reports = append(collectResult.Reports, newReport(horizonMetaData, r, ""))
+	}
 	logutil.Infof(ctx, "collected pipelineRun log: name: %v, %+v",
 		metadata.PipelineRun.Name, collectResult)
 	return collectResult, nil
@@ -89,3 +94,8 @@ func (c *DummyCollector) GetPipelineRun(ctx context.Context,
 	}
 	return tektonPipelineRun, nil
 }
+
+func (c *DummyCollector) GetPipelineRunReport(ctx context.Context, object string) ([]byte, error) {
+	// no storage to collect report files
+	return nil, herrors.NewErrNotFound(herrors.PipelinerunReport, "report files are not collected")
+}
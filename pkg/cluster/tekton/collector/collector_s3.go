@@ -30,6 +30,7 @@ import (
 	herrors "github.com/horizoncd/horizon/core/errors"
 	perror "github.com/horizoncd/horizon/pkg/errors"
 	prmodels "github.com/horizoncd/horizon/pkg/pipelinerun/models"
+	reportmodels "github.com/horizoncd/horizon/pkg/pipelinerun/report/models"
 	"github.com/horizoncd/horizon/pkg/server/global"
 
 	"github.com/aws/aws-sdk-go/aws/awserr"
@@ -41,6 +42,7 @@ import (
 
 	"github.com/horizoncd/horizon/lib/s3"
 	"github.com/horizoncd/horizon/pkg/cluster/tekton"
+	"github.com/horizoncd/horizon/pkg/cluster/tekton/report"
 	logutil "github.com/horizoncd/horizon/pkg/util/log"
 	"github.com/horizoncd/horizon/pkg/util/wlog"
 )
@@ -127,6 +129,7 @@ type CollectResult struct {
 	Result         string
 	StartTime      *metav1.Time
 	CompletionTime *metav1.Time
+	Reports        []*reportmodels.Report
 }
 
 func (c *S3Collector) Collect(ctx context.Context, pr *v1beta1.PipelineRun, horizonMetaData *global.HorizonMetaData) (
@@ -151,6 +154,11 @@ func (c *S3Collector) Collect(ctx context.Context, pr *v1beta1.PipelineRun, hori
 
 	logutil.Debugf(ctx, "collected object result: %+v", collectObjectResult)
 
+	reports, err := c.collectReports(ctx, pr, metadata, horizonMetaData)
+	if err != nil {
+		return nil, err
+	}
+
 	logStruct := NewLogStruct(collectObjectResult.PrURL,
 		metadata, collectLogResult.LogURL, collectLogResult.LogContent)
 	b, err := json.Marshal(logStruct)
@@ -168,6 +176,7 @@ func (c *S3Collector) Collect(ctx context.Context, pr *v1beta1.PipelineRun, hori
 		Result:         metadata.PipelineRun.Result,
 		StartTime:      metadata.PipelineRun.StartTime,
 		CompletionTime: metadata.PipelineRun.CompletionTime,
+		Reports:        reports,
 	}
 
 	// delete pipelinerun in k8s
@@ -226,6 +235,22 @@ func (c *S3Collector) getPipelineRunLog(ctx context.Context, logObject string) (
 	return b, nil
 }
 
+func (c *S3Collector) GetPipelineRunReport(ctx context.Context, object string) (_ []byte, err error) {
+	const op = "s3Collector: getPipelineRunReport"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	b, err := c.s3.GetObject(ctx, object)
+	if err != nil {
+		if e, ok := err.(awserr.Error); ok {
+			if e.Code() == awss3.ErrCodeNoSuchKey {
+				return nil, herrors.NewErrNotFound(herrors.PipelinerunReport, err.Error())
+			}
+		}
+		return nil, perror.Wrap(herrors.ErrS3GetObjFailed, err.Error())
+	}
+	return b, nil
+}
+
 func (c *S3Collector) GetPipelineRunObject(ctx context.Context, object string) (_ *Object, err error) {
 	const op = "s3Collector: getPipelineRunObject"
 	defer wlog.Start(ctx, op).StopPrint()
@@ -331,11 +356,7 @@ func (c *S3Collector) collectLog(ctx context.Context,
 					logC = nil
 					continue
 				}
-				if l.Log == "EOFLOG" {
-					_, _ = w.Write([]byte("\n"))
-					continue
-				}
-				_, _ = w.Write([]byte(fmt.Sprintf("[%s : %s] %s\n", l.Task, l.Step, l.Log)))
+				_, _ = w.Write([]byte(FormatLog(l)))
 			case e, ok := <-errC:
 				if !ok {
 					errC = nil
@@ -367,6 +388,23 @@ func (c *S3Collector) collectLog(ctx context.Context,
 	}, nil
 }
 
+// collectReports puts the report files read from the results of tasks into s3
+func (c *S3Collector) collectReports(ctx context.Context, pr *v1beta1.PipelineRun, metadata *ObjectMeta,
+	horizonMetaData *global.HorizonMetaData) (_ []*reportmodels.Report, err error) {
+	const op = "s3Collector: collectReports"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	var reports []*reportmodels.Report
+	for _, r := range report.Resolve(ctx, pr) {
+		reportPath := c.getPathForPrReport(metadata, r)
+		if err := c.s3.PutObject(ctx, reportPath, bytes.NewReader(r.Content), nil); err != nil {
+			return nil, perror.Wrap(herrors.ErrS3PutObjFailed, err.Error())
+		}
+		reports = append(reports, newReport(horizonMetaData, r, reportPath))
+	}
+	return reports, nil
+}
+
 func (c *S3Collector) getPathForPr(metadata *ObjectMeta) string {
 	timeFormat := "200601"
 	timeStr := time.Now().Format(timeFormat)
@@ -383,6 +421,14 @@ func (c *S3Collector) getPathForPrLog(metadata *ObjectMeta) string {
 		metadata.PipelineRun.Name)
 }
 
+func (c *S3Collector) getPathForPrReport(metadata *ObjectMeta, r *report.Report) string {
+	timeFormat := "200601"
+	timeStr := time.Now().Format(timeFormat)
+	return fmt.Sprintf("%s/pr-report/%s-%s/%s-%s/%s/%s-%s", timeStr,
+		metadata.Application, metadata.ApplicationID, metadata.Cluster, metadata.ClusterID,
+		metadata.PipelineRun.Name, r.Task, r.Name)
+}
+
 func cutByteInMiddle(data []byte, limit int, begin int, end int) []byte {
 	l := len(data)
 	if limit > 0 && l < limit {
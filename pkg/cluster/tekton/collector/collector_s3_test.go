@@ -70,6 +70,15 @@ func TestS3Collector_Collect(t *testing.T) {
 		t.Fatal(err)
 	}
 
+	junit := `<testsuite tests="2" failures="1"/>`
+	for _, tr := range pr.Status.TaskRuns {
+		if tr.Status != nil {
+			tr.Status.TaskRunResults = append(tr.Status.TaskRunResults,
+				v1beta1.TaskRunResult{Name: "junit-report", Value: junit})
+			break
+		}
+	}
+
 	ctx := context.Background()
 
 	ctl := gomock.NewController(t)
@@ -135,4 +144,11 @@ func TestS3Collector_Collect(t *testing.T) {
 	if !reflect.DeepEqual(tektonPR, pr) {
 		t.Fatalf("pipelineRun objectMeta: expected %v, got %v", objectMeta, obj.Metadata)
 	}
+
+	// 4. getPipelineRunReport
+	assert.Equal(t, 1, len(collectResult.Reports))
+	assert.Equal(t, 2, collectResult.Reports[0].Tests)
+	report, err := c.GetPipelineRunReport(ctx, collectResult.Reports[0].Object)
+	assert.Nil(t, err)
+	assert.Equal(t, junit, string(report))
 }
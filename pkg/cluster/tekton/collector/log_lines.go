@@ -0,0 +1,121 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/horizoncd/horizon/pkg/cluster/tekton/log"
+)
+
+// _eofLog is sent by the log reader when a step ends
+const _eofLog = "EOFLOG"
+
+var _logLinePattern = regexp.MustCompile(`^\[(.*?) : (.*?)\] (.*)$`)
+
+// LogLine is a line of the pipelineRun log. Lines are numbered from 0 in the order they are written
+// to the log object, so the live log and the collected log agree on the offset of each line
+type LogLine struct {
+	Offset int    `json:"offset"`
+	Task   string `json:"task,omitempty"`
+	Step   string `json:"step,omitempty"`
+	Log    string `json:"log"`
+}
+
+// FormatLog formats a log sent by the log reader as it is written to the log object
+func FormatLog(l log.Log) string {
+	if l.Log == _eofLog {
+		return "\n"
+	}
+	return fmt.Sprintf("[%s : %s] %s\n", l.Task, l.Step, l.Log)
+}
+
+// ReadLines calls fn with the lines of the log from offset until the log ends, ctx is done or fn fails.
+// It returns the offset of the next line
+func (l *Log) ReadLines(ctx context.Context, offset int, fn func(*LogLine) error) (int, error) {
+	next := 0
+	emit := func(text string) error {
+		for _, s := range strings.Split(strings.TrimSuffix(text, "\n"), "\n") {
+			if next >= offset {
+				line := &LogLine{Offset: next, Log: s}
+				if m := _logLinePattern.FindStringSubmatch(s); m != nil {
+					line.Task, line.Step, line.Log = m[1], m[2], m[3]
+				}
+				if err := fn(line); err != nil {
+					return err
+				}
+			}
+			next++
+		}
+		return nil
+	}
+
+	if l.LogChannel == nil && l.ErrChannel == nil {
+		if len(l.LogBytes) == 0 {
+			return next, nil
+		}
+		return next, emit(string(l.LogBytes))
+	}
+
+	logC, errC := l.LogChannel, l.ErrChannel
+	// the reader blocks on sending until the log is drained
+	defer drain(logC, errC)
+	for logC != nil || errC != nil {
+		select {
+		case <-ctx.Done():
+			return next, ctx.Err()
+		case lg, ok := <-logC:
+			if !ok {
+				logC = nil
+				continue
+			}
+			if err := emit(FormatLog(lg)); err != nil {
+				return next, err
+			}
+		case e, ok := <-errC:
+			if !ok {
+				errC = nil
+				continue
+			}
+			if err := emit(fmt.Sprintf("%s\n", e)); err != nil {
+				return next, err
+			}
+		}
+	}
+	return next, nil
+}
+
+func drain(logC <-chan log.Log, errC <-chan error) {
+	if logC == nil && errC == nil {
+		return
+	}
+	go func() {
+		for logC != nil || errC != nil {
+			select {
+			case _, ok := <-logC:
+				if !ok {
+					logC = nil
+				}
+			case _, ok := <-errC:
+				if !ok {
+					errC = nil
+				}
+			}
+		}
+	}()
+}
@@ -0,0 +1,87 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/horizoncd/horizon/pkg/cluster/tekton/log"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadLines(t *testing.T) {
+	ctx := context.Background()
+	logs := []log.Log{
+		{Task: "build", Step: "git", Log: "clone"},
+		{Task: "build", Step: "git", Log: "EOFLOG"},
+		{Task: "build", Step: "compile", Log: "mvn package"},
+		{Task: "deploy", Step: "deploy", Log: "deployed"},
+	}
+	live := func() *Log {
+		logC := make(chan log.Log)
+		errC := make(chan error)
+		go func() {
+			defer close(logC)
+			defer close(errC)
+			for _, l := range logs {
+				logC <- l
+			}
+		}()
+		return &Log{LogChannel: logC, ErrChannel: errC}
+	}
+	var collected []byte
+	for _, l := range logs {
+		collected = append(collected, FormatLog(l)...)
+	}
+
+	read := func(l *Log, offset int) ([]*LogLine, int) {
+		var lines []*LogLine
+		next, err := l.ReadLines(ctx, offset, func(line *LogLine) error {
+			lines = append(lines, line)
+			return nil
+		})
+		assert.Nil(t, err)
+		return lines, next
+	}
+
+	// the live log and the collected log have the same lines
+	liveLines, next := read(live(), 0)
+	assert.Equal(t, 4, next)
+	collectedLines, next := read(&Log{LogBytes: collected}, 0)
+	assert.Equal(t, 4, next)
+	assert.Equal(t, liveLines, collectedLines)
+	assert.Equal(t, &LogLine{Offset: 2, Task: "build", Step: "compile", Log: "mvn package"}, liveLines[2])
+	assert.Equal(t, &LogLine{Offset: 1}, liveLines[1])
+
+	// resume from an offset
+	lines, next := read(&Log{LogBytes: collected}, 3)
+	assert.Equal(t, 4, next)
+	assert.Equal(t, []*LogLine{{Offset: 3, Task: "deploy", Step: "deploy", Log: "deployed"}}, lines)
+	lines, _ = read(live(), 3)
+	assert.Equal(t, 1, len(lines))
+
+	// stop when the line can not be sent
+	errSend := errors.New("closed")
+	next, err := live().ReadLines(ctx, 0, func(line *LogLine) error {
+		if line.Offset == 2 {
+			return errSend
+		}
+		return nil
+	})
+	assert.Equal(t, errSend, err)
+	assert.Equal(t, 2, next)
+}
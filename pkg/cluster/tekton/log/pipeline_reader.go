@@ -17,8 +17,10 @@
 package log
 
 import (
+	"context"
 	"fmt"
 
+	tektonv1 "github.com/horizoncd/horizon/pkg/cluster/tekton/v1"
 	"github.com/tektoncd/cli/pkg/pipeline"
 	"github.com/tektoncd/cli/pkg/pipelinerun"
 	trh "github.com/tektoncd/cli/pkg/taskrun"
@@ -28,7 +30,15 @@ import (
 )
 
 func (r *Reader) readPipelineLog() (<-chan Log, <-chan error, error) {
-	pr, err := pipelinerun.GetV1beta1(r.clients, r.run, metav1.GetOptions{}, r.ns)
+	var (
+		pr  *v1beta1.PipelineRun
+		err error
+	)
+	if r.apiVersion == tektonv1.APIVersion {
+		pr, err = tektonv1.GetPipelineRun(context.Background(), r.clients.Dynamic, r.ns, r.run)
+	} else {
+		pr, err = pipelinerun.GetV1beta1(r.clients, r.run, metav1.GetOptions{}, r.ns)
+	}
 	if err != nil {
 		return nil, nil, err
 	}
@@ -38,6 +38,8 @@ type Reader struct {
 	logType  string
 	task     string
 	number   int
+	// apiVersion is the api version of the runs to read, tekton.dev/v1beta1 if empty
+	apiVersion string
 }
 
 func NewReader(logType string, opts *options.LogOptions) (*Reader, error) {
@@ -81,6 +83,11 @@ func (r *Reader) Read() (<-chan Log, <-chan error, error) {
 	return nil, nil, fmt.Errorf("unknown log type")
 }
 
+// SetAPIVersion sets the api version of the runs to read
+func (r *Reader) SetAPIVersion(apiVersion string) {
+	r.apiVersion = apiVersion
+}
+
 func (r *Reader) setNumber(number int) {
 	r.number = number
 }
@@ -17,9 +17,11 @@
 package log
 
 import (
+	"context"
 	"fmt"
 	"strings"
 
+	tektonv1 "github.com/horizoncd/horizon/pkg/cluster/tekton/v1"
 	"github.com/tektoncd/cli/pkg/pods"
 	tr "github.com/tektoncd/cli/pkg/taskrun"
 	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
@@ -42,13 +44,21 @@ func (s *step) hasStarted() bool {
 }
 
 func (r *Reader) readTaskLog() (<-chan Log, <-chan error, error) {
-	tr, err := tr.GetV1beta1(r.clients, r.run, metav1.GetOptions{}, r.ns)
+	var (
+		taskRun *v1beta1.TaskRun
+		err     error
+	)
+	if r.apiVersion == tektonv1.APIVersion {
+		taskRun, err = tektonv1.GetTaskRun(context.Background(), r.clients.Dynamic, r.ns, r.run)
+	} else {
+		taskRun, err = tr.GetV1beta1(r.clients, r.run, metav1.GetOptions{}, r.ns)
+	}
 	if err != nil {
 		return nil, nil, fmt.Errorf("%s: %s", MsgTRNotFoundErr, err)
 	}
-	r.formTaskName(tr)
+	r.formTaskName(taskRun)
 
-	return r.readAvailableTaskLogs(tr)
+	return r.readAvailableTaskLogs(taskRun)
 }
 
 func (r *Reader) formTaskName(tr *v1beta1.TaskRun) {
@@ -0,0 +1,77 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metrics
+
+import (
+	doramodels "github.com/horizoncd/horizon/pkg/dora/models"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	_doraDeploymentsGauge         *prometheus.GaugeVec
+	_doraDeploymentFrequencyGauge *prometheus.GaugeVec
+	_doraLeadTimeGauge            *prometheus.GaugeVec
+	_doraChangeFailureRateGauge   *prometheus.GaugeVec
+	_doraTimeToRestoreGauge       *prometheus.GaugeVec
+)
+
+// DORAMetrics are the DORA metrics of the clusters of an application in an environment
+type DORAMetrics struct {
+	Application string
+	Environment string
+	Metrics     *doramodels.Metrics
+}
+
+func init() {
+	labels := []string{_environment, _application}
+	newGauge := func(name, help string) *prometheus.GaugeVec {
+		return promauto.NewGaugeVec(prometheus.GaugeOpts{
+			Name: prometheus.BuildFQName(_namespace, _subsystem, name),
+			Help: help,
+		}, labels)
+	}
+	_doraDeploymentsGauge = newGauge("dora_deployments",
+		"Successful deployments in the window")
+	_doraDeploymentFrequencyGauge = newGauge("dora_deployment_frequency",
+		"Successful deployments per day in the window")
+	_doraLeadTimeGauge = newGauge("dora_lead_time_seconds",
+		"Median time from commits to their deployments in the window")
+	_doraChangeFailureRateGauge = newGauge("dora_change_failure_rate",
+		"Ratio of the changes failed or rolled back in the window")
+	_doraTimeToRestoreGauge = newGauge("dora_time_to_restore_seconds",
+		"Mean time to restore from failed changes in the window")
+}
+
+// ObserveDORA replaces the DORA metrics exported, so that applications without deployments
+// in the window are not exported anymore
+func ObserveDORA(metrics []*DORAMetrics) {
+	gauges := []*prometheus.GaugeVec{_doraDeploymentsGauge, _doraDeploymentFrequencyGauge,
+		_doraLeadTimeGauge, _doraChangeFailureRateGauge, _doraTimeToRestoreGauge}
+	for _, gauge := range gauges {
+		gauge.Reset()
+	}
+	for _, m := range metrics {
+		labels := prometheus.Labels{
+			_environment: m.Environment,
+			_application: m.Application,
+		}
+		_doraDeploymentsGauge.With(labels).Set(float64(m.Metrics.Deployments))
+		_doraDeploymentFrequencyGauge.With(labels).Set(m.Metrics.DeploymentFrequency)
+		_doraLeadTimeGauge.With(labels).Set(m.Metrics.LeadTimeSeconds)
+		_doraChangeFailureRateGauge.With(labels).Set(m.Metrics.ChangeFailureRate)
+		_doraTimeToRestoreGauge.With(labels).Set(m.Metrics.MTTRSeconds)
+	}
+}
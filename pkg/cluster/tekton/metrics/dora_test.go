@@ -0,0 +1,47 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metrics
+
+import (
+	"testing"
+
+	doramodels "github.com/horizoncd/horizon/pkg/dora/models"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestObserveDORA(t *testing.T) {
+	ObserveDORA([]*DORAMetrics{
+		{Application: "app1", Environment: "online", Metrics: &doramodels.Metrics{
+			Deployments: 3, DeploymentFrequency: 1.5, LeadTimeSeconds: 60, ChangeFailureRate: 0.25, MTTRSeconds: 30,
+		}},
+		{Application: "app2", Environment: "online", Metrics: &doramodels.Metrics{Deployments: 1}},
+	})
+	assert.Equal(t, 2, testutil.CollectAndCount(_doraDeploymentsGauge))
+	labels := prometheus.Labels{_environment: "online", _application: "app1"}
+	assert.Equal(t, 3.0, testutil.ToFloat64(_doraDeploymentsGauge.With(labels)))
+	assert.Equal(t, 1.5, testutil.ToFloat64(_doraDeploymentFrequencyGauge.With(labels)))
+	assert.Equal(t, 60.0, testutil.ToFloat64(_doraLeadTimeGauge.With(labels)))
+	assert.Equal(t, 0.25, testutil.ToFloat64(_doraChangeFailureRateGauge.With(labels)))
+	assert.Equal(t, 30.0, testutil.ToFloat64(_doraTimeToRestoreGauge.With(labels)))
+
+	// app2 has no deployments in the window anymore
+	ObserveDORA([]*DORAMetrics{
+		{Application: "app1", Environment: "online", Metrics: &doramodels.Metrics{Deployments: 2}},
+	})
+	assert.Equal(t, 1, testutil.CollectAndCount(_doraDeploymentsGauge))
+	assert.Equal(t, 2.0, testutil.ToFloat64(_doraDeploymentsGauge.With(labels)))
+}
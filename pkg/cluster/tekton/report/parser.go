@@ -0,0 +1,261 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package report
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"math"
+	"strconv"
+	"strings"
+
+	herrors "github.com/horizoncd/horizon/core/errors"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/pipelinerun/report/models"
+)
+
+// severities of vulnerabilities
+const (
+	_severityCritical = "CRITICAL"
+	_severityHigh     = "HIGH"
+	_severityMedium   = "MEDIUM"
+	_severityLow      = "LOW"
+)
+
+type junitSuite struct {
+	XMLName  xml.Name
+	Tests    int          `xml:"tests,attr"`
+	Failures int          `xml:"failures,attr"`
+	Errors   int          `xml:"errors,attr"`
+	Skipped  int          `xml:"skipped,attr"`
+	Suites   []junitSuite `xml:"testsuite"`
+	Cases    []junitCase  `xml:"testcase"`
+}
+
+type junitCase struct {
+	Failures []struct{} `xml:"failure"`
+	Errors   []struct{} `xml:"error"`
+	Skipped  *struct{}  `xml:"skipped"`
+}
+
+func parseJUnit(content []byte) (*models.Summary, error) {
+	var root junitSuite
+	if err := xml.Unmarshal(content, &root); err != nil {
+		return nil, perror.Wrapf(herrors.ErrParamInvalid, "invalid junit report: %v", err)
+	}
+	if root.XMLName.Local != "testsuites" && root.XMLName.Local != "testsuite" {
+		return nil, perror.Wrapf(herrors.ErrParamInvalid, "invalid junit report: unexpected element %s",
+			root.XMLName.Local)
+	}
+	summary := &models.Summary{}
+	sumJUnit(&root, summary)
+	return summary, nil
+}
+
+// sumJUnit counts the test cases of the suite, the attributes are used when there is no test case listed
+func sumJUnit(suite *junitSuite, summary *models.Summary) {
+	if len(suite.Suites) > 0 {
+		for i := range suite.Suites {
+			sumJUnit(&suite.Suites[i], summary)
+		}
+		return
+	}
+	if len(suite.Cases) == 0 {
+		summary.Tests += suite.Tests
+		summary.Failures += suite.Failures
+		summary.Errors += suite.Errors
+		summary.Skipped += suite.Skipped
+		return
+	}
+	for _, c := range suite.Cases {
+		summary.Tests++
+		switch {
+		case len(c.Errors) > 0:
+			summary.Errors++
+		case len(c.Failures) > 0:
+			summary.Failures++
+		case c.Skipped != nil:
+			summary.Skipped++
+		}
+	}
+}
+
+type coberturaCoverage struct {
+	XMLName    xml.Name `xml:"coverage"`
+	LineRate   float64  `xml:"line-rate,attr"`
+	BranchRate float64  `xml:"branch-rate,attr"`
+}
+
+func parseCobertura(content []byte) (*models.Summary, error) {
+	var coverage coberturaCoverage
+	if err := xml.Unmarshal(content, &coverage); err != nil {
+		return nil, perror.Wrapf(herrors.ErrParamInvalid, "invalid cobertura report: %v", err)
+	}
+	return &models.Summary{
+		LineCoverage:   percent(coverage.LineRate),
+		BranchCoverage: percent(coverage.BranchRate),
+	}, nil
+}
+
+func percent(rate float64) float64 {
+	return math.Round(rate*10000) / 100
+}
+
+type sarifLog struct {
+	Runs []struct {
+		Tool struct {
+			Driver struct {
+				Rules []sarifRule `json:"rules"`
+			} `json:"driver"`
+		} `json:"tool"`
+		Results []struct {
+			RuleID     string          `json:"ruleId"`
+			RuleIndex  *int            `json:"ruleIndex"`
+			Level      string          `json:"level"`
+			Properties sarifProperties `json:"properties"`
+		} `json:"results"`
+	} `json:"runs"`
+}
+
+type sarifRule struct {
+	ID         string          `json:"id"`
+	Properties sarifProperties `json:"properties"`
+}
+
+type sarifProperties struct {
+	SecuritySeverity string   `json:"security-severity"`
+	Tags             []string `json:"tags"`
+}
+
+// severity resolves the severity by the tags like trivy does, or by the cvss score in security-severity
+func (p *sarifProperties) severity() string {
+	for _, tag := range p.Tags {
+		switch s := strings.ToUpper(tag); s {
+		case _severityCritical, _severityHigh, _severityMedium, _severityLow:
+			return s
+		}
+	}
+	score, err := strconv.ParseFloat(p.SecuritySeverity, 64)
+	if err != nil {
+		return ""
+	}
+	switch {
+	case score >= 9:
+		return _severityCritical
+	case score >= 7:
+		return _severityHigh
+	case score >= 4:
+		return _severityMedium
+	case score > 0:
+		return _severityLow
+	}
+	return ""
+}
+
+func parseSARIF(content []byte) (*models.Summary, error) {
+	var l sarifLog
+	if err := json.Unmarshal(content, &l); err != nil {
+		return nil, perror.Wrapf(herrors.ErrParamInvalid, "invalid sarif report: %v", err)
+	}
+	summary := &models.Summary{}
+	for _, run := range l.Runs {
+		rules := run.Tool.Driver.Rules
+		for _, result := range run.Results {
+			severity := result.Properties.severity()
+			if severity == "" {
+				var rule *sarifRule
+				if result.RuleIndex != nil && *result.RuleIndex >= 0 && *result.RuleIndex < len(rules) {
+					rule = &rules[*result.RuleIndex]
+				} else {
+					for i := range rules {
+						if rules[i].ID == result.RuleID {
+							rule = &rules[i]
+							break
+						}
+					}
+				}
+				if rule != nil {
+					severity = rule.Properties.severity()
+				}
+			}
+			if severity == "" {
+				severity = levelSeverity(result.Level)
+			}
+			countSeverity(summary, severity)
+		}
+	}
+	return summary, nil
+}
+
+// levelSeverity maps the level of a sarif result when the severity is not given
+func levelSeverity(level string) string {
+	switch level {
+	case "error":
+		return _severityHigh
+	case "warning":
+		return _severityMedium
+	case "note":
+		return _severityLow
+	}
+	return ""
+}
+
+type trivyResult struct {
+	Vulnerabilities []struct {
+		Severity string `json:"Severity"`
+	} `json:"Vulnerabilities"`
+}
+
+// parseTrivy parses the json report of trivy, both the results array of old versions
+// and the object of schema version 2 are accepted
+func parseTrivy(content []byte) (*models.Summary, error) {
+	var results []trivyResult
+	var err error
+	if bytes.HasPrefix(bytes.TrimSpace(content), []byte("[")) {
+		err = json.Unmarshal(content, &results)
+	} else {
+		var report struct {
+			Results []trivyResult `json:"Results"`
+		}
+		err = json.Unmarshal(content, &report)
+		results = report.Results
+	}
+	if err != nil {
+		return nil, perror.Wrapf(herrors.ErrParamInvalid, "invalid trivy report: %v", err)
+	}
+	summary := &models.Summary{}
+	for _, result := range results {
+		for _, v := range result.Vulnerabilities {
+			countSeverity(summary, strings.ToUpper(v.Severity))
+		}
+	}
+	return summary, nil
+}
+
+func countSeverity(summary *models.Summary, severity string) {
+	switch severity {
+	case _severityCritical:
+		summary.Critical++
+	case _severityHigh:
+		summary.High++
+	case _severityMedium:
+		summary.Medium++
+	case _severityLow:
+		summary.Low++
+	default:
+		summary.Unknown++
+	}
+}
@@ -0,0 +1,153 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package report
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/base64"
+	"io/ioutil"
+	"sort"
+	"strings"
+
+	herrors "github.com/horizoncd/horizon/core/errors"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/pipelinerun/report/models"
+	"github.com/horizoncd/horizon/pkg/util/log"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+)
+
+// Report is a report read from the result of a task
+type Report struct {
+	Task    string
+	Name    string
+	Kind    string
+	Format  string
+	Content []byte
+	Summary *models.Summary
+}
+
+// results ending with these suffixes are read as reports, e.g. junit-report or api-junit-report
+var _resultFormats = []struct {
+	suffix string
+	kind   string
+	format string
+}{
+	{suffix: "junit-report", kind: models.KindTest, format: models.FormatJUnit},
+	{suffix: "cobertura-report", kind: models.KindCoverage, format: models.FormatCobertura},
+	{suffix: "sarif-report", kind: models.KindVulnerability, format: models.FormatSARIF},
+	{suffix: "trivy-report", kind: models.KindVulnerability, format: models.FormatTrivy},
+}
+
+// Resolve reads the reports from the results of the tasks of the pipelineRun.
+// As tekton results are small, a task reports a file in its workspace by writing it gzipped and base64 encoded,
+// such as `gzip -c junit.xml | base64 -w0 > $(results.junit-report.path)`, a plain report is accepted as well.
+// Results which can not be parsed are skipped.
+func Resolve(ctx context.Context, pr *v1beta1.PipelineRun) []*Report {
+	if pr == nil {
+		return nil
+	}
+	reports := make([]*Report, 0)
+	for _, trStatus := range pr.Status.TaskRuns {
+		if trStatus == nil || trStatus.Status == nil {
+			continue
+		}
+		for _, result := range trStatus.Status.TaskRunResults {
+			for _, f := range _resultFormats {
+				if !strings.HasSuffix(result.Name, f.suffix) {
+					continue
+				}
+				report, err := resolve(trStatus.PipelineTaskName, result.Name, f.kind, f.format, result.Value)
+				if err != nil {
+					log.Warningf(ctx, "failed to read report %s of task %s: %v",
+						result.Name, trStatus.PipelineTaskName, err)
+					break
+				}
+				reports = append(reports, report)
+				break
+			}
+		}
+	}
+	sort.Slice(reports, func(i, j int) bool {
+		if reports[i].Task != reports[j].Task {
+			return reports[i].Task < reports[j].Task
+		}
+		return reports[i].Name < reports[j].Name
+	})
+	return reports
+}
+
+func resolve(task, name, kind, format, value string) (*Report, error) {
+	content, err := decode(value)
+	if err != nil {
+		return nil, err
+	}
+	summary, err := Parse(format, content)
+	if err != nil {
+		return nil, err
+	}
+	return &Report{
+		Task:    task,
+		Name:    name,
+		Kind:    kind,
+		Format:  format,
+		Content: content,
+		Summary: summary,
+	}, nil
+}
+
+func decode(value string) ([]byte, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil, perror.Wrap(herrors.ErrParamInvalid, "report is empty")
+	}
+	if strings.ContainsAny(value[:1], "<{[") {
+		return []byte(value), nil
+	}
+	b, err := base64.StdEncoding.DecodeString(value)
+	if err != nil {
+		return nil, perror.Wrapf(herrors.ErrParamInvalid, "report is neither plain nor base64 encoded: %v", err)
+	}
+	if len(b) < 2 || b[0] != 0x1f || b[1] != 0x8b {
+		return b, nil
+	}
+	r, err := gzip.NewReader(bytes.NewReader(b))
+	if err != nil {
+		return nil, perror.Wrap(herrors.ErrParamInvalid, err.Error())
+	}
+	defer func() { _ = r.Close() }()
+	b, err = ioutil.ReadAll(r)
+	if err != nil {
+		return nil, perror.Wrap(herrors.ErrReadFailed, err.Error())
+	}
+	return b, nil
+}
+
+// Parse parses the summary of a report
+func Parse(format string, content []byte) (*models.Summary, error) {
+	switch format {
+	case models.FormatJUnit:
+		return parseJUnit(content)
+	case models.FormatCobertura:
+		return parseCobertura(content)
+	case models.FormatSARIF:
+		return parseSARIF(content)
+	case models.FormatTrivy:
+		return parseTrivy(content)
+	default:
+		return nil, perror.Wrapf(herrors.ErrParamInvalid, "unsupported report format %s", format)
+	}
+}
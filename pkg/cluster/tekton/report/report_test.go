@@ -0,0 +1,144 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package report
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/base64"
+	"testing"
+
+	"github.com/horizoncd/horizon/pkg/pipelinerun/report/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+)
+
+const (
+	_junit = `<?xml version="1.0" encoding="UTF-8"?>
+<testsuites tests="5" failures="1">
+  <testsuite name="a" tests="3">
+    <testcase name="a1"/>
+    <testcase name="a2"><failure message="expected"/></testcase>
+    <testcase name="a3"><skipped/></testcase>
+  </testsuite>
+  <testsuite name="b" tests="2" failures="0" errors="1" skipped="0"/>
+</testsuites>`
+	_cobertura = `<?xml version="1.0" ?>
+<coverage line-rate="0.8123" branch-rate="0.5" version="1.9">
+  <packages/>
+</coverage>`
+	_sarif = `{
+  "version": "2.1.0",
+  "runs": [{
+    "tool": {"driver": {"name": "Trivy", "rules": [
+      {"id": "CVE-1", "properties": {"security-severity": "9.8", "tags": ["vulnerability", "CRITICAL"]}},
+      {"id": "CVE-2", "properties": {"security-severity": "7.5"}}
+    ]}},
+    "results": [
+      {"ruleId": "CVE-1", "ruleIndex": 0, "level": "error"},
+      {"ruleId": "CVE-1", "level": "error"},
+      {"ruleId": "CVE-2", "ruleIndex": 1, "level": "error"},
+      {"ruleId": "lint", "level": "warning"},
+      {"ruleId": "style", "level": "none"}
+    ]
+  }]
+}`
+	_trivy = `{
+  "SchemaVersion": 2,
+  "Results": [
+    {"Target": "app", "Vulnerabilities": [{"Severity": "CRITICAL"}, {"Severity": "LOW"}]},
+    {"Target": "os", "Vulnerabilities": [{"Severity": "HIGH"}, {"Severity": "UNKNOWN"}]}
+  ]
+}`
+)
+
+func TestParse(t *testing.T) {
+	summary, err := Parse(models.FormatJUnit, []byte(_junit))
+	assert.Nil(t, err)
+	assert.Equal(t, &models.Summary{Tests: 5, Failures: 1, Errors: 1, Skipped: 1}, summary)
+
+	summary, err = Parse(models.FormatCobertura, []byte(_cobertura))
+	assert.Nil(t, err)
+	assert.Equal(t, &models.Summary{LineCoverage: 81.23, BranchCoverage: 50}, summary)
+
+	summary, err = Parse(models.FormatSARIF, []byte(_sarif))
+	assert.Nil(t, err)
+	assert.Equal(t, &models.Summary{Critical: 2, High: 1, Medium: 1, Unknown: 1}, summary)
+
+	summary, err = Parse(models.FormatTrivy, []byte(_trivy))
+	assert.Nil(t, err)
+	assert.Equal(t, &models.Summary{Critical: 1, High: 1, Low: 1, Unknown: 1}, summary)
+
+	// results array of old trivy versions
+	summary, err = Parse(models.FormatTrivy, []byte(`[{"Vulnerabilities": [{"Severity": "CRITICAL"}]}]`))
+	assert.Nil(t, err)
+	assert.Equal(t, 1, summary.Critical)
+
+	_, err = Parse(models.FormatJUnit, []byte(_cobertura))
+	assert.NotNil(t, err)
+	_, err = Parse(models.FormatSARIF, []byte("<xml/>"))
+	assert.NotNil(t, err)
+	_, err = Parse("html", []byte(_junit))
+	assert.NotNil(t, err)
+}
+
+func TestResolve(t *testing.T) {
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	_, _ = w.Write([]byte(_trivy))
+	_ = w.Close()
+	gzipped := base64.StdEncoding.EncodeToString(buf.Bytes())
+
+	pr := &v1beta1.PipelineRun{}
+	pr.Status.TaskRuns = map[string]*v1beta1.PipelineRunTaskRunStatus{
+		"pr-test-abcde": {
+			PipelineTaskName: "test",
+			Status: &v1beta1.TaskRunStatus{TaskRunStatusFields: v1beta1.TaskRunStatusFields{
+				TaskRunResults: []v1beta1.TaskRunResult{
+					{Name: "unit-junit-report", Value: _junit},
+					{Name: "cobertura-report", Value: base64.StdEncoding.EncodeToString([]byte(_cobertura))},
+					{Name: "properties", Value: "image"},
+				},
+			}},
+		},
+		"pr-scan-fghij": {
+			PipelineTaskName: "scan",
+			Status: &v1beta1.TaskRunStatus{TaskRunStatusFields: v1beta1.TaskRunStatusFields{
+				TaskRunResults: []v1beta1.TaskRunResult{
+					{Name: "trivy-report", Value: gzipped},
+					{Name: "sarif-report", Value: "not a report"},
+				},
+			}},
+		},
+		"pr-deploy-klmno": {
+			PipelineTaskName: "deploy",
+		},
+	}
+
+	reports := Resolve(context.TODO(), pr)
+	assert.Equal(t, 3, len(reports))
+	assert.Equal(t, "scan", reports[0].Task)
+	assert.Equal(t, models.FormatTrivy, reports[0].Format)
+	assert.Equal(t, []byte(_trivy), reports[0].Content)
+	assert.Equal(t, 1, reports[0].Summary.Critical)
+	assert.Equal(t, "cobertura-report", reports[1].Name)
+	assert.Equal(t, models.KindCoverage, reports[1].Kind)
+	assert.Equal(t, 81.23, reports[1].Summary.LineCoverage)
+	assert.Equal(t, "unit-junit-report", reports[2].Name)
+	assert.Equal(t, 5, reports[2].Summary.Tests)
+
+	assert.Nil(t, Resolve(context.TODO(), nil))
+}
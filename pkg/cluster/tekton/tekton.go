@@ -16,9 +16,13 @@ package tekton
 
 import (
 	"context"
+	"fmt"
 
+	herrors "github.com/horizoncd/horizon/core/errors"
 	"github.com/horizoncd/horizon/pkg/cluster/tekton/log"
+	tektonv1 "github.com/horizoncd/horizon/pkg/cluster/tekton/v1"
 	"github.com/horizoncd/horizon/pkg/config/tekton"
+	perror "github.com/horizoncd/horizon/pkg/errors"
 
 	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
 )
@@ -33,26 +37,46 @@ type Interface interface {
 	GetPipelineRunLogByID(ctx context.Context, ciEventID string) (<-chan log.Log, <-chan error, error)
 	GetPipelineRunLog(ctx context.Context, pr *v1beta1.PipelineRun) (<-chan log.Log, <-chan error, error)
 	DeletePipelineRun(ctx context.Context, pr *v1beta1.PipelineRun) error
+	// GetFinishedPipelineRun gets the finished pipelinerun in full by the one from the cloud event,
+	// it waits for the signature of tekton chains if chains is enabled
+	GetFinishedPipelineRun(ctx context.Context, pr *v1beta1.PipelineRun) (*v1beta1.PipelineRun, error)
 }
 
 type Tekton struct {
-	server    string
-	namespace string
-	client    *Client
+	server     string
+	namespace  string
+	apiVersion string
+	chains     *tekton.Chains
+	client     *Client
 }
 
 func NewTekton(tektonConfig *tekton.Tekton) (*Tekton, error) {
+	apiVersion := tektonConfig.APIVersion
+	switch apiVersion {
+	case "":
+		apiVersion = tektonv1.APIVersionV1beta1
+	case tektonv1.APIVersionV1beta1, tektonv1.APIVersion:
+	default:
+		return nil, perror.Wrap(herrors.ErrParamInvalid,
+			fmt.Sprintf("unsupported tekton api version: %s", apiVersion))
+	}
 	client, err := InitClient(tektonConfig.Kubeconfig)
 	if err != nil {
 		return nil, err
 	}
 	return &Tekton{
-		server:    tektonConfig.Server,
-		namespace: tektonConfig.Namespace,
-		client:    client,
+		server:     tektonConfig.Server,
+		namespace:  tektonConfig.Namespace,
+		apiVersion: apiVersion,
+		chains:     tektonConfig.Chains,
+		client:     client,
 	}, nil
 }
 
+func (t *Tekton) isV1() bool {
+	return t.apiVersion == tektonv1.APIVersion
+}
+
 type (
 	PipelineRun struct {
 		Action           string                 `json:"action"`
@@ -22,12 +22,15 @@ import (
 	"io"
 	"io/ioutil"
 	"net/http"
+	"time"
 
 	"github.com/horizoncd/horizon/core/common"
 	"k8s.io/apimachinery/pkg/fields"
 
 	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/pkg/cluster/tekton/chains"
 	"github.com/horizoncd/horizon/pkg/cluster/tekton/log"
+	tektonv1 "github.com/horizoncd/horizon/pkg/cluster/tekton/v1"
 	perror "github.com/horizoncd/horizon/pkg/errors"
 	"github.com/horizoncd/horizon/pkg/util/wlog"
 	"github.com/tektoncd/cli/pkg/options"
@@ -38,6 +41,9 @@ import (
 	"k8s.io/apimachinery/pkg/types"
 )
 
+// _signPollInterval is the interval to check whether tekton chains has signed a pipelinerun
+var _signPollInterval = 2 * time.Second
+
 func (t *Tekton) GetPipelineRunByID(ctx context.Context, ciEventID string) (_ *v1beta1.PipelineRun, err error) {
 	return t.getPipelineRunByID(ctx, ciEventID)
 }
@@ -110,11 +116,21 @@ func (t *Tekton) StopPipelineRun(ctx context.Context, ciEventID string) (err err
 		Path:  "/spec/status",
 		Value: v1beta1.PipelineRunSpecStatusCancelled,
 	}}
+	if t.isV1() {
+		payload[0].Value = tektonv1.PipelineRunSpecStatusCancelled
+	}
 
 	data, err := json.Marshal(payload)
 	if err != nil {
 		return perror.Wrap(herrors.ErrParamInvalid, err.Error())
 	}
+	if t.isV1() {
+		if _, err := t.client.Dynamic.Resource(tektonv1.PipelineRunResource).Namespace(pr.Namespace).
+			Patch(ctx, pr.Name, types.JSONPatchType, data, metav1.PatchOptions{}); err != nil {
+			return herrors.NewErrUpdateFailed(herrors.Pipelinerun, err.Error())
+		}
+		return nil
+	}
 	if _, err := t.client.Tekton.TektonV1beta1().PipelineRuns(pr.Namespace).Patch(ctx, pr.Name,
 		types.JSONPatchType, data, metav1.PatchOptions{}); err != nil {
 		return herrors.NewErrUpdateFailed(herrors.Pipelinerun, err.Error())
@@ -143,6 +159,7 @@ func (t *Tekton) GetPipelineRunLog(ctx context.Context, pr *v1beta1.PipelineRun)
 	if err != nil {
 		return nil, nil, err
 	}
+	lr.SetAPIVersion(t.apiVersion)
 	return lr.Read()
 }
 
@@ -150,8 +167,14 @@ func (t *Tekton) DeletePipelineRun(ctx context.Context, pr *v1beta1.PipelineRun)
 	if pr == nil {
 		return nil
 	}
-	err := t.client.Tekton.TektonV1beta1().PipelineRuns(t.namespace).
-		Delete(ctx, pr.Name, metav1.DeleteOptions{})
+	var err error
+	if t.isV1() {
+		err = t.client.Dynamic.Resource(tektonv1.PipelineRunResource).Namespace(t.namespace).
+			Delete(ctx, pr.Name, metav1.DeleteOptions{})
+	} else {
+		err = t.client.Tekton.TektonV1beta1().PipelineRuns(t.namespace).
+			Delete(ctx, pr.Name, metav1.DeleteOptions{})
+	}
 	if err != nil {
 		if k8serrors.IsNotFound(err) {
 			return herrors.NewErrNotFound(herrors.Pipelinerun, err.Error())
@@ -162,23 +185,67 @@ func (t *Tekton) DeletePipelineRun(ctx context.Context, pr *v1beta1.PipelineRun)
 	return nil
 }
 
+func (t *Tekton) GetFinishedPipelineRun(ctx context.Context,
+	pr *v1beta1.PipelineRun) (_ *v1beta1.PipelineRun, err error) {
+	const op = "tekton: get finished pipelineRun"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	// v1 pipelineruns sent by cloud events don't embed the statuses of their taskruns
+	if !t.isV1() && t.chains == nil {
+		return pr, nil
+	}
+
+	var deadline time.Time
+	if t.chains != nil {
+		deadline = time.Now().Add(t.chains.SignTimeout)
+	}
+	for {
+		got, err := t.getPipelineRunByID(ctx, pr.Labels[common.TektonTriggersEventIDKey])
+		if err != nil {
+			if _, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); ok {
+				return pr, nil
+			}
+			return nil, err
+		}
+		if t.chains == nil || chains.IsSignDone(got) || !time.Now().Before(deadline) {
+			return got, nil
+		}
+		select {
+		case <-ctx.Done():
+			return got, nil
+		case <-time.After(_signPollInterval):
+		}
+	}
+}
+
 func (t *Tekton) getPipelineRunByID(ctx context.Context, ciEventID string) (_ *v1beta1.PipelineRun,
 	err error) {
 	selector := fields.ParseSelectorOrDie(fmt.Sprintf("%v=%v", common.TektonTriggersEventIDKey, ciEventID))
-	prs, err := t.client.Tekton.TektonV1beta1().PipelineRuns(t.namespace).List(ctx, metav1.ListOptions{
-		LabelSelector: selector.String(),
-	})
+	var prs []*v1beta1.PipelineRun
+	if t.isV1() {
+		prs, err = tektonv1.ListPipelineRuns(ctx, t.client.Dynamic, t.namespace, selector.String())
+	} else {
+		var list *v1beta1.PipelineRunList
+		list, err = t.client.Tekton.TektonV1beta1().PipelineRuns(t.namespace).List(ctx, metav1.ListOptions{
+			LabelSelector: selector.String(),
+		})
+		if list != nil {
+			for i := range list.Items {
+				prs = append(prs, &list.Items[i])
+			}
+		}
+	}
 	if err != nil {
 		return nil, perror.Wrap(herrors.ErrTektonInternal, err.Error())
 	}
-	if prs == nil || len(prs.Items) == 0 {
+	if len(prs) == 0 {
 		return nil, herrors.NewErrNotFound(herrors.PipelinerunInTekton,
 			fmt.Sprintf("failed to list pipeline with selector %s", selector.String()))
-	} else if len(prs.Items) > 1 {
+	} else if len(prs) > 1 {
 		return nil, perror.Wrap(herrors.ErrTektonInternal,
 			fmt.Sprintf("unexpected: selector %s match multi pipeline runs", selector.String()))
 	}
-	return &(prs.Items[0]), nil
+	return prs[0], nil
 }
 
 func (t *Tekton) sendHTTPRequest(ctx context.Context, method string,
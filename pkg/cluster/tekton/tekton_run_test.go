@@ -22,9 +22,12 @@ import (
 	"net/http/httptest"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/horizoncd/horizon/core/common"
 	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/pkg/cluster/tekton/chains"
+	tektonv1 "github.com/horizoncd/horizon/pkg/cluster/tekton/v1"
 	"github.com/horizoncd/horizon/pkg/config/tekton"
 	perror "github.com/horizoncd/horizon/pkg/errors"
 
@@ -34,6 +37,7 @@ import (
 	fakedtekton "github.com/tektoncd/pipeline/pkg/client/clientset/versioned/fake"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	fakeddynamic "k8s.io/client-go/dynamic/fake"
 	"knative.dev/pkg/apis"
@@ -271,3 +275,76 @@ func TestTekton_getPipelineRunByID(t1 *testing.T) {
 	pr, err = t.getPipelineRunByID(context.Background(), "1")
 	assert.Equal(t1, herrors.ErrTektonInternal, perror.Cause(err))
 }
+
+func newV1PipelineRun(name, eventID string, annotations map[string]interface{}) *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": tektonv1.APIVersion,
+		"kind":       "PipelineRun",
+		"metadata": map[string]interface{}{
+			"name":        name,
+			"namespace":   "tekton",
+			"labels":      map[string]interface{}{common.TektonTriggersEventIDKey: eventID},
+			"annotations": annotations,
+		},
+		"spec": map[string]interface{}{
+			"pipelineSpec": map[string]interface{}{},
+		},
+		"status": map[string]interface{}{
+			"conditions": []interface{}{map[string]interface{}{
+				"type": "Succeeded", "status": "Unknown", "reason": "Running",
+			}},
+		},
+	}}
+}
+
+func TestTekton_V1(t1 *testing.T) {
+	ctx := context.Background()
+	pr1 := newV1PipelineRun("pr1", "1", nil)
+	pr2 := newV1PipelineRun("pr2", "2", map[string]interface{}{chains.AnnotationSigned: "true"})
+	dynamic := fakeddynamic.NewSimpleDynamicClient(runtime.NewScheme(), pr1, pr2)
+	t := &Tekton{
+		namespace:  "tekton",
+		apiVersion: tektonv1.APIVersion,
+		client: &Client{
+			Tekton:  fakedtekton.NewSimpleClientset(),
+			Dynamic: dynamic,
+		},
+	}
+
+	pr, err := t.GetPipelineRunByID(ctx, "1")
+	assert.Nil(t1, err)
+	assert.Equal(t1, "pr1", pr.Name)
+	_, err = t.GetPipelineRunByID(ctx, "3")
+	_, ok := perror.Cause(err).(*herrors.HorizonErrNotFound)
+	assert.True(t1, ok)
+
+	// the pipelinerun sent by the cloud event is refreshed
+	got, err := t.GetFinishedPipelineRun(ctx, &v1beta1.PipelineRun{ObjectMeta: metav1.ObjectMeta{
+		Name: "pr2", Labels: map[string]string{common.TektonTriggersEventIDKey: "2"},
+	}})
+	assert.Nil(t1, err)
+	assert.True(t1, chains.IsSigned(got))
+
+	// wait for chains to sign until timeout
+	_signPollInterval = 10 * time.Millisecond
+	t.chains = &tekton.Chains{SignTimeout: 50 * time.Millisecond}
+	got, err = t.GetFinishedPipelineRun(ctx, pr)
+	assert.Nil(t1, err)
+	assert.False(t1, chains.IsSigned(got))
+	got, err = t.GetFinishedPipelineRun(ctx, &v1beta1.PipelineRun{ObjectMeta: metav1.ObjectMeta{
+		Name: "pr3", Labels: map[string]string{common.TektonTriggersEventIDKey: "3"},
+	}})
+	assert.Nil(t1, err)
+	assert.Equal(t1, "pr3", got.Name)
+
+	assert.Nil(t1, t.StopPipelineRun(ctx, "1"))
+	u, err := dynamic.Resource(tektonv1.PipelineRunResource).Namespace("tekton").Get(ctx, "pr1", metav1.GetOptions{})
+	assert.Nil(t1, err)
+	status, _, _ := unstructured.NestedString(u.Object, "spec", "status")
+	assert.Equal(t1, tektonv1.PipelineRunSpecStatusCancelled, status)
+
+	assert.Nil(t1, t.DeletePipelineRun(ctx, pr))
+	_, err = t.GetPipelineRunByID(ctx, "1")
+	_, ok = perror.Cause(err).(*herrors.HorizonErrNotFound)
+	assert.True(t1, ok)
+}
@@ -31,6 +31,13 @@ func TestNewTekton(t *testing.T) {
 	assert.Nil(t, tekton)
 	assert.NotNil(t, err)
 
+	tektonConfig = &tektonconfig.Tekton{
+		APIVersion: "tekton.dev/v1alpha1",
+	}
+	tekton, err = NewTekton(tektonConfig)
+	assert.Nil(t, tekton)
+	assert.NotNil(t, err)
+
 	tektonConfig = &tektonconfig.Tekton{
 		Kubeconfig: "",
 	}
@@ -0,0 +1,174 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package v1 reads the pipelineruns and taskruns of the tekton.dev/v1 api by the dynamic client,
+// and converts them into v1beta1 ones which the rest of horizon is built on.
+package v1
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// APIVersion is the api version of tekton v1
+	APIVersion = "tekton.dev/v1"
+	// APIVersionV1beta1 is the api version of tekton v1beta1, which is deprecated upstream
+	APIVersionV1beta1 = "tekton.dev/v1beta1"
+
+	// PipelineRunSpecStatusCancelled cancels a v1 pipelinerun, it's PipelineRunCancelled in v1beta1
+	PipelineRunSpecStatusCancelled = "Cancelled"
+
+	_kindTaskRun = "TaskRun"
+)
+
+var (
+	PipelineRunResource = schema.GroupVersionResource{Group: "tekton.dev", Version: "v1", Resource: "pipelineruns"}
+	TaskRunResource     = schema.GroupVersionResource{Group: "tekton.dev", Version: "v1", Resource: "taskruns"}
+)
+
+// GetPipelineRun gets the v1 pipelinerun with its taskruns
+func GetPipelineRun(ctx context.Context, client dynamic.Interface, namespace,
+	name string) (*v1beta1.PipelineRun, error) {
+	u, err := client.Resource(PipelineRunResource).Namespace(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return ConvertPipelineRun(ctx, client, u)
+}
+
+// ListPipelineRuns lists the v1 pipelineruns matching the label selector with their taskruns
+func ListPipelineRuns(ctx context.Context, client dynamic.Interface, namespace,
+	labelSelector string) ([]*v1beta1.PipelineRun, error) {
+	list, err := client.Resource(PipelineRunResource).Namespace(namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: labelSelector,
+	})
+	if err != nil {
+		return nil, err
+	}
+	prs := make([]*v1beta1.PipelineRun, 0, len(list.Items))
+	for i := range list.Items {
+		pr, err := ConvertPipelineRun(ctx, client, &list.Items[i])
+		if err != nil {
+			return nil, err
+		}
+		prs = append(prs, pr)
+	}
+	return prs, nil
+}
+
+// GetTaskRun gets the v1 taskrun
+func GetTaskRun(ctx context.Context, client dynamic.Interface, namespace, name string) (*v1beta1.TaskRun, error) {
+	u, err := client.Resource(TaskRunResource).Namespace(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return ConvertTaskRun(u)
+}
+
+// ConvertPipelineRun converts the v1 pipelinerun, the taskruns referred by its child references
+// are got to fill the taskrun statuses which v1 doesn't embed anymore
+func ConvertPipelineRun(ctx context.Context, client dynamic.Interface,
+	u *unstructured.Unstructured) (*v1beta1.PipelineRun, error) {
+	obj := u.DeepCopy().Object
+	moveField(obj, []string{"spec", "timeouts", "pipeline"}, []string{"spec", "timeout"})
+	moveField(obj, []string{"spec", "taskRunTemplate", "serviceAccountName"}, []string{"spec", "serviceAccountName"})
+	if status, _, _ := unstructured.NestedString(obj, "spec", "status"); status == PipelineRunSpecStatusCancelled {
+		_ = unstructured.SetNestedField(obj, v1beta1.PipelineRunSpecStatusCancelled, "spec", "status")
+	}
+	convertResults(obj, "pipelineResults")
+
+	var pr v1beta1.PipelineRun
+	if err := fromUnstructured(obj, &pr); err != nil {
+		return nil, err
+	}
+
+	children, _, _ := unstructured.NestedSlice(u.Object, "status", "childReferences")
+	for _, child := range children {
+		ref, ok := child.(map[string]interface{})
+		if !ok || ref["kind"] != _kindTaskRun {
+			continue
+		}
+		name, _ := ref["name"].(string)
+		pipelineTaskName, _ := ref["pipelineTaskName"].(string)
+		tr, err := GetTaskRun(ctx, client, u.GetNamespace(), name)
+		if err != nil {
+			// the taskrun is pruned, only its name is known
+			tr = &v1beta1.TaskRun{}
+		}
+		if pr.Status.TaskRuns == nil {
+			pr.Status.TaskRuns = make(map[string]*v1beta1.PipelineRunTaskRunStatus)
+		}
+		pr.Status.TaskRuns[name] = &v1beta1.PipelineRunTaskRunStatus{
+			PipelineTaskName: pipelineTaskName,
+			Status:           &tr.Status,
+		}
+	}
+	return &pr, nil
+}
+
+// ConvertTaskRun converts the v1 taskrun
+func ConvertTaskRun(u *unstructured.Unstructured) (*v1beta1.TaskRun, error) {
+	obj := u.DeepCopy().Object
+	convertResults(obj, "taskResults")
+	var tr v1beta1.TaskRun
+	if err := fromUnstructured(obj, &tr); err != nil {
+		return nil, err
+	}
+	return &tr, nil
+}
+
+// convertResults moves status.results to the field of v1beta1, the values of v1 results
+// can be arrays or objects which are kept in json
+func convertResults(obj map[string]interface{}, field string) {
+	results, found, _ := unstructured.NestedSlice(obj, "status", "results")
+	if !found {
+		return
+	}
+	for _, result := range results {
+		r, ok := result.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if _, ok := r["value"].(string); !ok && r["value"] != nil {
+			b, _ := json.Marshal(r["value"])
+			r["value"] = string(b)
+		}
+	}
+	unstructured.RemoveNestedField(obj, "status", "results")
+	_ = unstructured.SetNestedSlice(obj, results, "status", field)
+}
+
+func moveField(obj map[string]interface{}, from, to []string) {
+	value, found, _ := unstructured.NestedFieldNoCopy(obj, from...)
+	if !found {
+		return
+	}
+	_ = unstructured.SetNestedField(obj, value, to...)
+}
+
+func fromUnstructured(obj map[string]interface{}, v interface{}) error {
+	b, err := json.Marshal(obj)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, v)
+}
@@ -0,0 +1,107 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	fakeddynamic "k8s.io/client-go/dynamic/fake"
+	"knative.dev/pkg/apis"
+)
+
+func newObject(kind, name string, spec, status map[string]interface{},
+	labels map[string]interface{}) *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": APIVersion,
+		"kind":       kind,
+		"metadata": map[string]interface{}{
+			"name":      name,
+			"namespace": "tekton",
+			"labels":    labels,
+		},
+		"spec":   spec,
+		"status": status,
+	}}
+}
+
+func TestConvert(t *testing.T) {
+	pr := newObject("PipelineRun", "pr1", map[string]interface{}{
+		"pipelineRef":     map[string]interface{}{"name": "default"},
+		"status":          PipelineRunSpecStatusCancelled,
+		"timeouts":        map[string]interface{}{"pipeline": "1h0m0s"},
+		"taskRunTemplate": map[string]interface{}{"serviceAccountName": "builder"},
+	}, map[string]interface{}{
+		"conditions": []interface{}{map[string]interface{}{
+			"type": "Succeeded", "status": "False", "reason": "Cancelled",
+		}},
+		"startTime":      "2023-07-01T00:00:00Z",
+		"completionTime": "2023-07-01T00:02:00Z",
+		"results":        []interface{}{map[string]interface{}{"name": "image", "value": "harbor/app:v1"}},
+		"childReferences": []interface{}{
+			map[string]interface{}{"kind": "TaskRun", "name": "pr1-build", "pipelineTaskName": "build"},
+			map[string]interface{}{"kind": "TaskRun", "name": "pr1-pruned", "pipelineTaskName": "deploy"},
+			map[string]interface{}{"kind": "Run", "name": "pr1-custom", "pipelineTaskName": "custom"},
+		},
+	}, map[string]interface{}{"triggers.tekton.dev/triggers-eventid": "1"})
+	tr := newObject("TaskRun", "pr1-build", map[string]interface{}{}, map[string]interface{}{
+		"podName": "pr1-build-pod",
+		"steps": []interface{}{map[string]interface{}{
+			"name": "compile", "container": "step-compile",
+			"terminated": map[string]interface{}{"exitCode": int64(0), "reason": "Completed"},
+		}},
+		"results": []interface{}{
+			map[string]interface{}{"name": "junit", "type": "string", "value": "<testsuites/>"},
+			map[string]interface{}{"name": "digests", "type": "array", "value": []interface{}{"sha256:1"}},
+		},
+	}, nil)
+	client := fakeddynamic.NewSimpleDynamicClient(runtime.NewScheme(), pr, tr)
+	ctx := context.Background()
+
+	converted, err := GetPipelineRun(ctx, client, "tekton", "pr1")
+	assert.Nil(t, err)
+	assert.Equal(t, "pr1", converted.Name)
+	assert.Equal(t, "default", converted.Spec.PipelineRef.Name)
+	assert.True(t, converted.IsCancelled())
+	assert.Equal(t, time.Hour, converted.Spec.Timeout.Duration)
+	assert.Equal(t, "builder", converted.Spec.ServiceAccountName)
+	assert.Equal(t, "Cancelled", converted.Status.GetCondition(apis.ConditionSucceeded).Reason)
+	assert.Equal(t, 2*time.Minute, converted.Status.CompletionTime.Sub(converted.Status.StartTime.Time))
+	assert.Equal(t, []v1beta1.PipelineRunResult{{Name: "image", Value: "harbor/app:v1"}},
+		converted.Status.PipelineResults)
+
+	assert.Equal(t, 2, len(converted.Status.TaskRuns))
+	build := converted.Status.TaskRuns["pr1-build"]
+	assert.Equal(t, "build", build.PipelineTaskName)
+	assert.Equal(t, "pr1-build-pod", build.Status.PodName)
+	assert.Equal(t, "compile", build.Status.Steps[0].Name)
+	assert.Equal(t, []v1beta1.TaskRunResult{
+		{Name: "junit", Value: "<testsuites/>"},
+		{Name: "digests", Value: `["sha256:1"]`},
+	}, build.Status.TaskRunResults)
+	assert.Equal(t, "deploy", converted.Status.TaskRuns["pr1-pruned"].PipelineTaskName)
+
+	prs, err := ListPipelineRuns(ctx, client, "tekton", "triggers.tekton.dev/triggers-eventid=1")
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(prs))
+	prs, err = ListPipelineRuns(ctx, client, "tekton", "triggers.tekton.dev/triggers-eventid=2")
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(prs))
+}
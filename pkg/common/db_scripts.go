@@ -219,6 +219,10 @@ const (
 	PipelinerunUpdateCIEventIDByID = "update tb_pipelinerun set ci_event_id = ? where id = ?"
 	PipelinerunUpdateResultByID    = "update tb_pipelinerun set status = ?, s3_bucket = ?, log_object = ?, " +
 		"pr_object = ?, started_at = ?, finished_at = ? where id = ?"
+	PipelinerunUpdateReviewByID = "update tb_pipelinerun set status = ?, reviewed_by = ?, reviewed_at = ?, " +
+		"review_comment = ? where id = ? and status = 'pending'"
+	PipelinerunUpdateStatusByIDAndStatus = "update tb_pipelinerun set status = ? where id = ? and status = ?"
+	PipelinerunListByStatus              = "select * from tb_pipelinerun where status = ? order by id"
 
 	PipelinerunGetByClusterID = "select * from tb_pipelinerun where cluster_id = ?" +
 		" order by created_at desc limit ? offset ?"
@@ -233,6 +237,10 @@ const (
 
 	PipelinerunGetFirstCanRollbackByClusterID = "select * from tb_pipelinerun where cluster_id = ?" +
 		" and action != 'restart' and status = 'ok' order by created_at desc limit 1 offset 0"
+
+	PipelinerunListBuilds = "select * from tb_pipelinerun where git_commit = ? and build_config_hash = ?" +
+		" and git_url = ? and git_subfolder = ? and action = 'builddeploy' and status = 'ok'" +
+		" and reuse_from is null and image_url != '' order by id desc limit ?"
 )
 
 /* sql about cluster tag */
@@ -267,6 +275,75 @@ const (
 	ApplicationRegionDeleteAllByApplicationID = "delete from tb_application_region where application_id = ?"
 )
 
+/* sql about promotion path */
+const (
+	PromotionPathGetByApplicationID    = "select * from tb_promotion_path where application_id = ?"
+	PromotionPathDeleteByApplicationID = "delete from tb_promotion_path where application_id = ?"
+)
+
+/* sql about freeze window */
+const (
+	FreezeWindowGetByID    = "select * from tb_freeze_window where id = ?"
+	FreezeWindowDeleteByID = "delete from tb_freeze_window where id = ?"
+)
+
+/* sql about release plan */
+const (
+	ReleasePlanGetByID              = "select * from tb_release_plan where id = ?"
+	ReleasePlanListByStatus         = "select * from tb_release_plan where status = ? order by id asc"
+	ReleasePlanListClustersByPlanID = "select * from tb_release_plan_cluster where plan_id = ? " +
+		"order by wave asc, id asc"
+
+	ReleasePlanCountByApplicationIDAndStatuses = "select count(1) from tb_release_plan " +
+		"where application_id = ? and status in ?"
+)
+
+/* sql about batch job */
+const (
+	BatchJobGetByID             = "select * from tb_batch_job where id = ?"
+	BatchJobListByStatus        = "select * from tb_batch_job where status = ? order by id asc"
+	BatchJobListClustersByJobID = "select * from tb_batch_job_cluster where job_id = ? order by id asc"
+)
+
+/* sql about schedule */
+const (
+	ScheduleGetByID         = "select * from tb_schedule where id = ?"
+	ScheduleListByClusterID = "select * from tb_schedule where cluster_id = ? order by id asc"
+	ScheduleListDue         = "select * from tb_schedule where paused = 0 and next_run_at <= ? order by next_run_at asc"
+)
+
+/* sql about auto deploy */
+const (
+	AutoDeployGetByClusterID = "select * from tb_auto_deploy where cluster_id = ?"
+)
+
+/* sql about run queue */
+const (
+	RunQueueGetByClusterID = "select * from tb_run_queue where cluster_id = ?"
+	// RunQueueTransfer moves the cluster to another pipelinerun only if it's still held by the given one
+	RunQueueTransfer = "update tb_run_queue set pipelinerun_id = ?, locked_at = ? " +
+		"where cluster_id = ? and pipelinerun_id = ?"
+)
+
+/* sql about build context */
+const (
+	BuildContextGetByClusterID = "select * from tb_build_context where cluster_id = ?"
+)
+
+/* sql about drift report */
+const (
+	DriftReportGetByClusterID = "select * from tb_drift_report where cluster_id = ?"
+)
+
+/* sql about canary analysis */
+const (
+	CanaryAnalysisListByPipelinerunID = "select * from tb_canary_analysis where pipelinerun_id = ? order by step asc"
+	CanaryAnalysisListByStatus        = "select * from tb_canary_analysis where status = ? order by id asc"
+
+	CanaryAnalysisCancelRunningByClusterID = "update tb_canary_analysis set status = ?, finished_at = ? " +
+		"where cluster_id = ? and status = ?"
+)
+
 /* sql about token*/
 const (
 	DeleteByCode     = "delete  from tb_token where code = ?"
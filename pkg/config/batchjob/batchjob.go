@@ -0,0 +1,26 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package batchjob
+
+import "time"
+
+type Config struct {
+	// JobInterval is how often running batch jobs are pushed forward
+	JobInterval time.Duration `yaml:"jobInterval"`
+	// ClusterTimeout is how long the pipelinerun of a cluster may take before the cluster is failed
+	ClusterTimeout time.Duration `yaml:"clusterTimeout"`
+	// MaxConcurrency limits how many clusters a batch job may operate at the same time
+	MaxConcurrency int `yaml:"maxConcurrency"`
+}
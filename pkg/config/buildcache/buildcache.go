@@ -0,0 +1,21 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package buildcache
+
+type Config struct {
+	// AutoReuse tells whether builddeploy reuses the image built from the same commit and build config
+	// by default, requests can still choose to reuse or to rebuild explicitly
+	AutoReuse bool `yaml:"autoReuse"`
+}
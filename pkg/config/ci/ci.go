@@ -0,0 +1,44 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ci
+
+// Mapper maps environments to their CI engines, environments not in it are built by tekton
+type Mapper map[string]*Engine
+
+type Engine struct {
+	// Type is tekton or external, tekton is used if it's empty
+	Type     string    `yaml:"type"`
+	External *External `yaml:"external"`
+}
+
+// External is a pipeline in GitLab CI or GitHub Actions which reports its completion back to horizon
+type External struct {
+	// Provider is gitlab or github
+	Provider string `yaml:"provider"`
+	// Server is the api server, e.g. https://gitlab.com/api/v4 or https://api.github.com
+	Server string `yaml:"server"`
+	// Project is the id or path of the gitlab project, or owner/repo of the github repository
+	Project string `yaml:"project"`
+	// Ref is the branch holding the pipeline definition
+	Ref string `yaml:"ref"`
+	// Workflow is the file name of the github workflow
+	Workflow string `yaml:"workflow"`
+	// TriggerToken is the pipeline trigger token of gitlab
+	TriggerToken string `yaml:"triggerToken"`
+	// Token is the access token to dispatch, cancel and read pipelines
+	Token string `yaml:"token"`
+	// CallbackURL is the callback api of horizon passed to the pipeline to report its completion
+	CallbackURL string `yaml:"callbackURL"`
+}
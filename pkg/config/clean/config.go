@@ -30,4 +30,6 @@ type Config struct {
 
 	WebhookLogCleanRules []WebhookLogCleanRule `yaml:"webhookLogCleanRules"`
 	EventCleanRules      []EventCleanRule      `yaml:"eventCleanRules"`
+	// AuditLogTTL is how long audit logs are kept, they are kept forever if it's zero
+	AuditLogTTL time.Duration `yaml:"auditLogTTL"`
 }
@@ -0,0 +1,25 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package drift
+
+import "time"
+
+type Config struct {
+	// Enabled tells whether clusters are checked for drift periodically
+	Enabled bool `yaml:"enabled"`
+	// AccountID is the identity the drift job lists clusters and reports drift with
+	AccountID   uint          `yaml:"accountID"`
+	JobInterval time.Duration `yaml:"jobInterval"`
+}
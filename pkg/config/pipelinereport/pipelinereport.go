@@ -0,0 +1,43 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pipelinereport
+
+type Config struct {
+	// DeployPolicy blocks deploying builds with too many critical vulnerabilities, no build is blocked when not set
+	DeployPolicy *DeployPolicy `yaml:"deployPolicy"`
+}
+
+type DeployPolicy struct {
+	// MaxCriticalVulnerabilities is the most critical vulnerabilities found in the reports of a build to be deployed
+	MaxCriticalVulnerabilities int `yaml:"maxCriticalVulnerabilities"`
+	// Environments are the environments the policy applies to, it applies to all environments when empty
+	Environments []string `yaml:"environments"`
+}
+
+// AppliesTo tells whether deploying to the environment is checked by the policy
+func (p *DeployPolicy) AppliesTo(environment string) bool {
+	if p == nil {
+		return false
+	}
+	if len(p.Environments) == 0 {
+		return true
+	}
+	for _, env := range p.Environments {
+		if env == environment {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,27 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package runqueue
+
+import "time"
+
+type Config struct {
+	// DefaultPolicy is the policy of the clusters which have not set their own
+	DefaultPolicy string `yaml:"defaultPolicy"`
+	// JobInterval is how often queued pipelineruns are checked whether their clusters are released
+	JobInterval time.Duration `yaml:"jobInterval"`
+	// LockTimeout is how long a pipelinerun can hold its cluster, a pipelinerun whose result is lost
+	// would hold the cluster forever otherwise
+	LockTimeout time.Duration `yaml:"lockTimeout"`
+}
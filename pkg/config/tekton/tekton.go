@@ -14,6 +14,8 @@
 
 package tekton
 
+import "time"
+
 type Mapper map[string]*Tekton
 
 type Tekton struct {
@@ -21,6 +23,17 @@ type Tekton struct {
 	Namespace  string      `yaml:"namespace"`
 	Kubeconfig string      `yaml:"kubeconfig"`
 	LogStorage *LogStorage `yaml:"logStorage"`
+	// APIVersion is the api version of the pipelineruns created by the trigger,
+	// tekton.dev/v1beta1 if empty
+	APIVersion string `yaml:"apiVersion"`
+	// Chains is set when the pipelineruns are signed by tekton chains
+	Chains *Chains `yaml:"chains"`
+}
+
+type Chains struct {
+	// SignTimeout is how long a finished pipelinerun waits to be signed before it's collected,
+	// the pipelinerun is collected without provenance once it times out
+	SignTimeout time.Duration `yaml:"signTimeout"`
 }
 
 type LogStorage struct {
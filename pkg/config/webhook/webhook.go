@@ -14,6 +14,8 @@
 
 package webhook
 
+import "github.com/horizoncd/horizon/pkg/webhook/models"
+
 type Config struct {
 	// seconds for http client timeout
 	ClientTimeout uint `yaml:"clientTimeout"`
@@ -23,4 +25,24 @@ type Config struct {
 	WorkerReconcileInterval uint `yaml:"workerReconcileInterval"`
 	// bytes limit to truncate for response body
 	ResponseBodyTruncateSize uint `yaml:"responseBodyTruncateSize"`
+	// RetryPolicy is the retry policy of the webhooks without their own
+	RetryPolicy models.RetryPolicy `yaml:"retryPolicy"`
+	// seconds the previous secret keeps signing requests after the secret is rotated
+	SecretRotationOverlap uint `yaml:"secretRotationOverlap"`
+	// LegacySecretHeader keeps sending the plain secret in X-Horizon-Webhook-Secret for the receivers
+	// which don't verify signatures yet, it leaks the secret and is going to be removed.
+	// It's off by default, receivers reading the header should be migrated to verify
+	// X-Horizon-Webhook-Signature before upgrading, or enable it until they are
+	LegacySecretHeader bool `yaml:"legacySecretHeader"`
+	// SMTP is the mail server of the email notification channels
+	SMTP SMTPConfig `yaml:"smtp"`
+}
+
+type SMTPConfig struct {
+	Host     string `yaml:"host"`
+	Port     int    `yaml:"port"`
+	Username string `yaml:"username"`
+	Password string `yaml:"password"`
+	// From is the sender address of the mails
+	From string `yaml:"from"`
 }
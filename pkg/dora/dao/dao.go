@@ -0,0 +1,65 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dao
+
+import (
+	"context"
+
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/pkg/dora/models"
+	prmodels "github.com/horizoncd/horizon/pkg/pipelinerun/models"
+
+	"gorm.io/gorm"
+)
+
+type DAO interface {
+	ListDeployments(ctx context.Context, query *models.Query) ([]*models.Deployment, error)
+}
+
+type dao struct {
+	db *gorm.DB
+}
+
+func NewDAO(db *gorm.DB) DAO {
+	return &dao{db: db}
+}
+
+// ListDeployments lists the finished deployments, clusters and applications deleted since are included
+// so that the history is not rewritten by deletions
+func (d *dao) ListDeployments(ctx context.Context, query *models.Query) ([]*models.Deployment, error) {
+	tx := d.db.WithContext(ctx).Table("tb_pipelinerun p").
+		Select("p.id as pipelinerun_id, p.cluster_id, a.name as application, c.environment_name as environment, "+
+			"p.action, p.status, p.git_commit_time, p.created_at, p.finished_at").
+		Joins("join tb_cluster c on c.id = p.cluster_id").
+		Joins("join tb_application a on a.id = c.application_id").
+		Where("p.action in ?", []string{prmodels.ActionBuildDeploy, prmodels.ActionDeploy,
+			prmodels.ActionPromote, prmodels.ActionRollback}).
+		Where("p.status in ?", []string{string(prmodels.StatusOK), string(prmodels.StatusFailed)}).
+		Where("p.finished_at >= ? and p.finished_at < ?", query.Start, query.End)
+	if query.ApplicationID != 0 {
+		tx = tx.Where("c.application_id = ?", query.ApplicationID)
+	} else if query.GroupIDs != nil {
+		tx = tx.Where("a.group_id in ?", query.GroupIDs)
+	}
+	if query.Environment != "" {
+		tx = tx.Where("c.environment_name = ?", query.Environment)
+	}
+
+	var deployments []*models.Deployment
+	if result := tx.Order("p.finished_at").Scan(&deployments); result.Error != nil {
+		return nil, herrors.NewErrGetFailed(herrors.PipelinerunInDB, result.Error.Error())
+	}
+	return deployments, nil
+}
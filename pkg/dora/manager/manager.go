@@ -0,0 +1,45 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manager
+
+import (
+	"context"
+
+	"github.com/horizoncd/horizon/pkg/dora/dao"
+	"github.com/horizoncd/horizon/pkg/dora/models"
+	"github.com/horizoncd/horizon/pkg/util/wlog"
+	"gorm.io/gorm"
+)
+
+type Manager interface {
+	// ListDeployments lists the deployments and rollbacks finished in the window of the query
+	ListDeployments(ctx context.Context, query *models.Query) ([]*models.Deployment, error)
+}
+
+type manager struct {
+	dao dao.DAO
+}
+
+func New(db *gorm.DB) Manager {
+	return &manager{
+		dao: dao.NewDAO(db),
+	}
+}
+
+func (m *manager) ListDeployments(ctx context.Context, query *models.Query) ([]*models.Deployment, error) {
+	const op = "dora manager: list deployments"
+	defer wlog.Start(ctx, op).StopPrint()
+	return m.dao.ListDeployments(ctx, query)
+}
@@ -0,0 +1,106 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manager
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/horizoncd/horizon/lib/orm"
+	appmodels "github.com/horizoncd/horizon/pkg/application/models"
+	clustermodels "github.com/horizoncd/horizon/pkg/cluster/models"
+	"github.com/horizoncd/horizon/pkg/dora/models"
+	prmodels "github.com/horizoncd/horizon/pkg/pipelinerun/models"
+	"github.com/horizoncd/horizon/pkg/server/global"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	db, _ = orm.NewSqliteDB("")
+	ctx   context.Context
+	mgr   = New(db)
+)
+
+func TestMain(m *testing.M) {
+	if err := db.AutoMigrate(&appmodels.Application{}, &clustermodels.Cluster{},
+		&prmodels.Pipelinerun{}); err != nil {
+		panic(err)
+	}
+	ctx = context.TODO()
+	os.Exit(m.Run())
+}
+
+func Test(t *testing.T) {
+	start := time.Date(2023, 7, 1, 0, 0, 0, 0, time.UTC)
+	at := func(hours int) *time.Time {
+		t := start.Add(time.Duration(hours) * time.Hour)
+		return &t
+	}
+	assert.Nil(t, db.Create([]*appmodels.Application{
+		{Model: global.Model{ID: 1}, GroupID: 1, Name: "app1"},
+		{Model: global.Model{ID: 2}, GroupID: 2, Name: "app2"},
+	}).Error)
+	assert.Nil(t, db.Create([]*clustermodels.Cluster{
+		{Model: global.Model{ID: 1}, ApplicationID: 1, Name: "app1-online", EnvironmentName: "online"},
+		{Model: global.Model{ID: 2}, ApplicationID: 1, Name: "app1-test", EnvironmentName: "test"},
+		{Model: global.Model{ID: 3}, ApplicationID: 2, Name: "app2-online", EnvironmentName: "online"},
+	}).Error)
+	assert.Nil(t, db.Create([]*prmodels.Pipelinerun{
+		{ClusterID: 1, Action: prmodels.ActionBuildDeploy, Status: string(prmodels.StatusOK),
+			GitCommitTime: at(0), FinishedAt: at(2)},
+		{ClusterID: 1, Action: prmodels.ActionRollback, Status: string(prmodels.StatusFailed), FinishedAt: at(3)},
+		// not deployments
+		{ClusterID: 1, Action: prmodels.ActionRestart, Status: string(prmodels.StatusOK), FinishedAt: at(4)},
+		{ClusterID: 1, Action: prmodels.ActionDeploy, Status: string(prmodels.StatusCancelled), FinishedAt: at(4)},
+		{ClusterID: 2, Action: prmodels.ActionDeploy, Status: string(prmodels.StatusOK), FinishedAt: at(5)},
+		{ClusterID: 3, Action: prmodels.ActionPromote, Status: string(prmodels.StatusOK), FinishedAt: at(6)},
+		// out of the window
+		{ClusterID: 3, Action: prmodels.ActionDeploy, Status: string(prmodels.StatusOK), FinishedAt: at(48)},
+	}).Error)
+
+	query := &models.Query{Start: start, End: *at(24)}
+	deployments, err := mgr.ListDeployments(ctx, query)
+	assert.Nil(t, err)
+	assert.Equal(t, 4, len(deployments))
+	assert.Equal(t, "app1", deployments[0].Application)
+	assert.Equal(t, "online", deployments[0].Environment)
+	assert.Equal(t, prmodels.ActionBuildDeploy, deployments[0].Action)
+	assert.True(t, start.Equal(*deployments[0].GitCommitTime))
+	assert.True(t, at(2).Equal(deployments[0].FinishedAt))
+
+	query.ApplicationID = 1
+	deployments, err = mgr.ListDeployments(ctx, query)
+	assert.Nil(t, err)
+	assert.Equal(t, 3, len(deployments))
+
+	query.Environment = "online"
+	deployments, err = mgr.ListDeployments(ctx, query)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(deployments))
+
+	query = &models.Query{GroupIDs: []uint{2}, Start: start, End: *at(72)}
+	deployments, err = mgr.ListDeployments(ctx, query)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(deployments))
+	assert.Equal(t, uint(3), deployments[1].ClusterID)
+
+	query.GroupIDs = []uint{}
+	deployments, err = mgr.ListDeployments(ctx, query)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(deployments))
+}
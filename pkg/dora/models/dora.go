@@ -0,0 +1,225 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package models
+
+import (
+	"sort"
+	"time"
+
+	prmodels "github.com/horizoncd/horizon/pkg/pipelinerun/models"
+)
+
+// Deployment is a finished pipelinerun which deploys or rolls back a cluster
+type Deployment struct {
+	PipelinerunID uint
+	ClusterID     uint
+	Application   string
+	Environment   string
+	Action        string
+	Status        string
+	// GitCommitTime when the deployed commit is committed, nil if unknown
+	GitCommitTime *time.Time
+	CreatedAt     time.Time
+	FinishedAt    time.Time
+}
+
+// Query selects the deployments finished in [Start, End)
+type Query struct {
+	ApplicationID uint
+	// GroupIDs the groups whose applications are selected, ignored when ApplicationID is set
+	GroupIDs    []uint
+	Environment string
+	Start       time.Time
+	End         time.Time
+}
+
+// Window is a time range [Start, End)
+type Window struct {
+	Start time.Time
+	End   time.Time
+}
+
+// Split divides the window into consecutive windows of the interval, the last one may be shorter
+func (w Window) Split(interval time.Duration) []Window {
+	if interval <= 0 {
+		return []Window{w}
+	}
+	var windows []Window
+	for start := w.Start; start.Before(w.End); start = start.Add(interval) {
+		end := start.Add(interval)
+		if end.After(w.End) {
+			end = w.End
+		}
+		windows = append(windows, Window{Start: start, End: end})
+	}
+	return windows
+}
+
+// Metrics are the DORA metrics of a window
+type Metrics struct {
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+	// Deployments is the number of the changes deployed successfully
+	Deployments int `json:"deployments"`
+	// DeploymentFrequency is the average number of the successful deployments per day
+	DeploymentFrequency float64 `json:"deploymentFrequency"`
+	// LeadTimeSeconds is the median time from the commits to their successful deployments
+	LeadTimeSeconds float64 `json:"leadTimeSeconds"`
+	// Changes is the number of the changes deployed, successful or not
+	Changes int `json:"changes"`
+	// FailedChanges is the number of the changes which failed to deploy or were rolled back
+	FailedChanges int `json:"failedChanges"`
+	// ChangeFailureRate is FailedChanges / Changes
+	ChangeFailureRate float64 `json:"changeFailureRate"`
+	// Restores is the number of the recoveries from failed changes
+	Restores int `json:"restores"`
+	// MTTRSeconds is the mean time from the failures to their recoveries
+	MTTRSeconds float64 `json:"mttrSeconds"`
+}
+
+// change is a pipelinerun deploying a new version of a cluster
+type change struct {
+	at       time.Time
+	ok       bool
+	failed   bool
+	leadTime *time.Duration
+}
+
+// restore is the recovery of a cluster from a failed change
+type restore struct {
+	at       time.Time
+	duration time.Duration
+}
+
+// isChange tells whether a pipelinerun of the action deploys a new version,
+// rollbacks restore an old version and restarts change nothing
+func isChange(action string) bool {
+	return action == prmodels.ActionBuildDeploy || action == prmodels.ActionDeploy ||
+		action == prmodels.ActionPromote
+}
+
+// analyze walks the deployments of each cluster in order. A change fails if its pipelinerun fails
+// or it's rolled back by the next deployment. A cluster is failing from the failure of its change
+// (or the start of the rollback if the change succeeded), until the next successful deployment.
+func analyze(deployments []*Deployment) ([]*change, []*restore) {
+	byCluster := make(map[uint][]*Deployment)
+	for _, d := range deployments {
+		byCluster[d.ClusterID] = append(byCluster[d.ClusterID], d)
+	}
+
+	var changes []*change
+	var restores []*restore
+	for _, ds := range byCluster {
+		sort.SliceStable(ds, func(i, j int) bool {
+			return ds[i].FinishedAt.Before(ds[j].FinishedAt)
+		})
+		var last *change
+		var failingSince *time.Time
+		for _, d := range ds {
+			ok := d.Status == string(prmodels.StatusOK)
+			if d.Action == prmodels.ActionRollback {
+				if last != nil {
+					last.failed = true
+				}
+				if failingSince == nil {
+					createdAt := d.CreatedAt
+					failingSince = &createdAt
+				}
+			} else if isChange(d.Action) {
+				last = &change{at: d.FinishedAt, ok: ok, failed: !ok}
+				if ok && d.GitCommitTime != nil && d.FinishedAt.After(*d.GitCommitTime) {
+					leadTime := d.FinishedAt.Sub(*d.GitCommitTime)
+					last.leadTime = &leadTime
+				}
+				changes = append(changes, last)
+				if !ok && failingSince == nil {
+					finishedAt := d.FinishedAt
+					failingSince = &finishedAt
+				}
+			} else {
+				continue
+			}
+			if ok && failingSince != nil {
+				restores = append(restores, &restore{at: d.FinishedAt, duration: d.FinishedAt.Sub(*failingSince)})
+				failingSince = nil
+			}
+		}
+	}
+	return changes, restores
+}
+
+// Compute computes the metrics of the deployments for each window,
+// changes and restores are counted in the windows they finish in
+func Compute(deployments []*Deployment, windows ...Window) []*Metrics {
+	changes, restores := analyze(deployments)
+	in := func(w Window, t time.Time) bool {
+		return !t.Before(w.Start) && t.Before(w.End)
+	}
+
+	metrics := make([]*Metrics, 0, len(windows))
+	for _, w := range windows {
+		m := &Metrics{Start: w.Start, End: w.End}
+		var leadTimes []time.Duration
+		for _, c := range changes {
+			if !in(w, c.at) {
+				continue
+			}
+			m.Changes++
+			if c.failed {
+				m.FailedChanges++
+			}
+			if c.ok {
+				m.Deployments++
+			}
+			if c.leadTime != nil {
+				leadTimes = append(leadTimes, *c.leadTime)
+			}
+		}
+		if days := w.End.Sub(w.Start).Hours() / 24; days > 0 {
+			m.DeploymentFrequency = float64(m.Deployments) / days
+		}
+		if m.Changes > 0 {
+			m.ChangeFailureRate = float64(m.FailedChanges) / float64(m.Changes)
+		}
+		m.LeadTimeSeconds = median(leadTimes).Seconds()
+
+		var restoreTime time.Duration
+		for _, r := range restores {
+			if in(w, r.at) {
+				m.Restores++
+				restoreTime += r.duration
+			}
+		}
+		if m.Restores > 0 {
+			m.MTTRSeconds = restoreTime.Seconds() / float64(m.Restores)
+		}
+		metrics = append(metrics, m)
+	}
+	return metrics
+}
+
+func median(durations []time.Duration) time.Duration {
+	if len(durations) == 0 {
+		return 0
+	}
+	sort.Slice(durations, func(i, j int) bool {
+		return durations[i] < durations[j]
+	})
+	mid := len(durations) / 2
+	if len(durations)%2 == 1 {
+		return durations[mid]
+	}
+	return (durations[mid-1] + durations[mid]) / 2
+}
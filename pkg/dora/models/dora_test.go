@@ -0,0 +1,85 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package models
+
+import (
+	"testing"
+	"time"
+
+	prmodels "github.com/horizoncd/horizon/pkg/pipelinerun/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSplit(t *testing.T) {
+	start := time.Date(2023, 7, 1, 0, 0, 0, 0, time.UTC)
+	w := Window{Start: start, End: start.Add(60 * time.Hour)}
+	windows := w.Split(24 * time.Hour)
+	assert.Equal(t, 3, len(windows))
+	assert.Equal(t, start.Add(48*time.Hour), windows[2].Start)
+	assert.Equal(t, w.End, windows[2].End)
+	assert.Equal(t, []Window{w}, w.Split(0))
+}
+
+func TestCompute(t *testing.T) {
+	start := time.Date(2023, 7, 1, 0, 0, 0, 0, time.UTC)
+	at := func(hours int) time.Time {
+		return start.Add(time.Duration(hours) * time.Hour)
+	}
+	commitAt := func(hours int) *time.Time {
+		t := at(hours)
+		return &t
+	}
+	ok, failed := string(prmodels.StatusOK), string(prmodels.StatusFailed)
+	deployments := []*Deployment{
+		// cluster 1: ok, ok then rolled back, failed then fixed
+		{ClusterID: 1, Action: prmodels.ActionBuildDeploy, Status: ok,
+			GitCommitTime: commitAt(0), CreatedAt: at(1), FinishedAt: at(2)},
+		{ClusterID: 1, Action: prmodels.ActionBuildDeploy, Status: ok,
+			GitCommitTime: commitAt(2), CreatedAt: at(5), FinishedAt: at(6)},
+		{ClusterID: 1, Action: prmodels.ActionRollback, Status: ok, CreatedAt: at(7), FinishedAt: at(8)},
+		{ClusterID: 1, Action: prmodels.ActionDeploy, Status: failed, CreatedAt: at(29), FinishedAt: at(30)},
+		{ClusterID: 1, Action: prmodels.ActionRestart, Status: ok, CreatedAt: at(30), FinishedAt: at(31)},
+		{ClusterID: 1, Action: prmodels.ActionBuildDeploy, Status: ok,
+			GitCommitTime: commitAt(31), CreatedAt: at(32), FinishedAt: at(34)},
+		// cluster 2: promoted without commit time
+		{ClusterID: 2, Action: prmodels.ActionPromote, Status: ok, CreatedAt: at(9), FinishedAt: at(10)},
+	}
+
+	w := Window{Start: start, End: at(48)}
+	metrics := Compute(deployments, append([]Window{w}, w.Split(24*time.Hour)...)...)
+	assert.Equal(t, 3, len(metrics))
+
+	total := metrics[0]
+	assert.Equal(t, 4, total.Deployments)
+	assert.Equal(t, 2.0, total.DeploymentFrequency)
+	assert.Equal(t, 5, total.Changes)
+	assert.Equal(t, 2, total.FailedChanges)
+	assert.Equal(t, 0.4, total.ChangeFailureRate)
+	// lead times of 2h, 4h and 3h
+	assert.Equal(t, (3 * time.Hour).Seconds(), total.LeadTimeSeconds)
+	// restored in 1h by the rollback, and in 4h by the fix
+	assert.Equal(t, 2, total.Restores)
+	assert.Equal(t, (150 * time.Minute).Seconds(), total.MTTRSeconds)
+
+	first, second := metrics[1], metrics[2]
+	assert.Equal(t, 3, first.Deployments)
+	assert.Equal(t, 1, first.FailedChanges)
+	assert.Equal(t, (3 * time.Hour).Seconds(), first.LeadTimeSeconds)
+	assert.Equal(t, time.Hour.Seconds(), first.MTTRSeconds)
+	assert.Equal(t, 1, second.Deployments)
+	assert.Equal(t, 2, second.Changes)
+	assert.Equal(t, 0.5, second.ChangeFailureRate)
+	assert.Equal(t, (4 * time.Hour).Seconds(), second.MTTRSeconds)
+}
@@ -0,0 +1,98 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dao
+
+import (
+	"context"
+
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/pkg/common"
+	"github.com/horizoncd/horizon/pkg/drift/models"
+
+	"gorm.io/gorm"
+)
+
+type DAO interface {
+	Create(ctx context.Context, report *models.DriftReport) (*models.DriftReport, error)
+	GetByClusterID(ctx context.Context, clusterID uint) (*models.DriftReport, error)
+	UpdateByID(ctx context.Context, id uint, report *models.DriftReport) error
+	UpdateCheckedByID(ctx context.Context, id uint, report *models.DriftReport) error
+	UpdateStatusByClusterID(ctx context.Context, clusterID uint, statuses []string,
+		report *models.DriftReport) (bool, error)
+}
+
+type dao struct {
+	db *gorm.DB
+}
+
+func NewDAO(db *gorm.DB) DAO {
+	return &dao{db: db}
+}
+
+func (d *dao) Create(ctx context.Context, report *models.DriftReport) (*models.DriftReport, error) {
+	if result := d.db.WithContext(ctx).Create(report); result.Error != nil {
+		return nil, herrors.NewErrInsertFailed(herrors.DriftReportInDB, result.Error.Error())
+	}
+	return report, nil
+}
+
+func (d *dao) GetByClusterID(ctx context.Context, clusterID uint) (*models.DriftReport, error) {
+	var report models.DriftReport
+	result := d.db.WithContext(ctx).Raw(common.DriftReportGetByClusterID, clusterID).Scan(&report)
+	if result.Error != nil {
+		return nil, herrors.NewErrGetFailed(herrors.DriftReportInDB, result.Error.Error())
+	}
+	if result.RowsAffected == 0 {
+		return nil, herrors.NewErrNotFound(herrors.DriftReportInDB, "drift report not found")
+	}
+	return &report, nil
+}
+
+func (d *dao) UpdateByID(ctx context.Context, id uint, report *models.DriftReport) error {
+	result := d.db.WithContext(ctx).Model(&models.DriftReport{}).Where("id = ?", id).
+		Select("Status", "Revision", "ManifestVersion", "Resources", "Fingerprint",
+			"DetectedAt", "CheckedAt", "AcceptedAt", "AcceptedBy").
+		Updates(report)
+	if result.Error != nil {
+		return herrors.NewErrUpdateFailed(herrors.DriftReportInDB, result.Error.Error())
+	}
+	return nil
+}
+
+// UpdateCheckedByID updates the desired revision and check time but keeps the status,
+// which may have been changed by an acceptance since the report was read
+func (d *dao) UpdateCheckedByID(ctx context.Context, id uint, report *models.DriftReport) error {
+	result := d.db.WithContext(ctx).Model(&models.DriftReport{}).Where("id = ?", id).
+		Select("Revision", "ManifestVersion", "CheckedAt").
+		Updates(report)
+	if result.Error != nil {
+		return herrors.NewErrUpdateFailed(herrors.DriftReportInDB, result.Error.Error())
+	}
+	return nil
+}
+
+// UpdateStatusByClusterID updates the status of the report only if its status is one of statuses,
+// so that an acceptance does not override a drift detected in the meantime
+func (d *dao) UpdateStatusByClusterID(ctx context.Context, clusterID uint, statuses []string,
+	report *models.DriftReport) (bool, error) {
+	result := d.db.WithContext(ctx).Model(&models.DriftReport{}).
+		Where("cluster_id = ? and status in ?", clusterID, statuses).
+		Select("Status", "AcceptedAt", "AcceptedBy").
+		Updates(report)
+	if result.Error != nil {
+		return false, herrors.NewErrUpdateFailed(herrors.DriftReportInDB, result.Error.Error())
+	}
+	return result.RowsAffected > 0, nil
+}
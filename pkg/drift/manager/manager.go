@@ -0,0 +1,79 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manager
+
+import (
+	"context"
+
+	"github.com/horizoncd/horizon/pkg/drift/dao"
+	"github.com/horizoncd/horizon/pkg/drift/models"
+	"github.com/horizoncd/horizon/pkg/util/wlog"
+	"gorm.io/gorm"
+)
+
+type Manager interface {
+	Create(ctx context.Context, report *models.DriftReport) (*models.DriftReport, error)
+	// GetByClusterID gets the latest drift report of the cluster
+	GetByClusterID(ctx context.Context, clusterID uint) (*models.DriftReport, error)
+	// UpdateByID updates the status, desired revision and drifted resources of the report
+	UpdateByID(ctx context.Context, id uint, report *models.DriftReport) error
+	// UpdateCheckedByID updates the desired revision and the check time of the report
+	UpdateCheckedByID(ctx context.Context, id uint, report *models.DriftReport) error
+	// UpdateStatusByClusterID updates the status and acceptor of the report if its status is one of statuses,
+	// false is returned if the report is not in these statuses
+	UpdateStatusByClusterID(ctx context.Context, clusterID uint, statuses []string,
+		report *models.DriftReport) (bool, error)
+}
+
+type manager struct {
+	dao dao.DAO
+}
+
+func New(db *gorm.DB) Manager {
+	return &manager{
+		dao: dao.NewDAO(db),
+	}
+}
+
+func (m *manager) Create(ctx context.Context, report *models.DriftReport) (*models.DriftReport, error) {
+	const op = "drift report manager: create"
+	defer wlog.Start(ctx, op).StopPrint()
+	return m.dao.Create(ctx, report)
+}
+
+func (m *manager) GetByClusterID(ctx context.Context, clusterID uint) (*models.DriftReport, error) {
+	const op = "drift report manager: get by cluster id"
+	defer wlog.Start(ctx, op).StopPrint()
+	return m.dao.GetByClusterID(ctx, clusterID)
+}
+
+func (m *manager) UpdateByID(ctx context.Context, id uint, report *models.DriftReport) error {
+	const op = "drift report manager: update by id"
+	defer wlog.Start(ctx, op).StopPrint()
+	return m.dao.UpdateByID(ctx, id, report)
+}
+
+func (m *manager) UpdateCheckedByID(ctx context.Context, id uint, report *models.DriftReport) error {
+	const op = "drift report manager: update checked by id"
+	defer wlog.Start(ctx, op).StopPrint()
+	return m.dao.UpdateCheckedByID(ctx, id, report)
+}
+
+func (m *manager) UpdateStatusByClusterID(ctx context.Context, clusterID uint, statuses []string,
+	report *models.DriftReport) (bool, error) {
+	const op = "drift report manager: update status by cluster id"
+	defer wlog.Start(ctx, op).StopPrint()
+	return m.dao.UpdateStatusByClusterID(ctx, clusterID, statuses, report)
+}
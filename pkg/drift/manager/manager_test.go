@@ -0,0 +1,91 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manager
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/lib/orm"
+	"github.com/horizoncd/horizon/pkg/drift/models"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	db, _ = orm.NewSqliteDB("")
+	ctx   context.Context
+	mgr   = New(db)
+)
+
+func TestMain(m *testing.M) {
+	if err := db.AutoMigrate(&models.DriftReport{}); err != nil {
+		panic(err)
+	}
+	ctx = context.TODO()
+	os.Exit(m.Run())
+}
+
+func Test(t *testing.T) {
+	_, err := mgr.GetByClusterID(ctx, 1)
+	_, ok := perror.Cause(err).(*herrors.HorizonErrNotFound)
+	assert.True(t, ok)
+
+	now := time.Now()
+	report := &models.DriftReport{
+		ClusterID:  1,
+		Status:     models.StatusDrifted,
+		Revision:   "r1",
+		DetectedAt: &now,
+		CheckedAt:  now,
+	}
+	report.SetResources([]models.Resource{{Version: "v1", Kind: "Service", Name: "svc", Missing: true}})
+	_, err = mgr.Create(ctx, report)
+	assert.Nil(t, err)
+
+	updated, err := mgr.UpdateStatusByClusterID(ctx, 1, []string{models.StatusDrifted},
+		&models.DriftReport{Status: models.StatusAccepted, AcceptedAt: &now, AcceptedBy: 2})
+	assert.Nil(t, err)
+	assert.True(t, updated)
+	updated, err = mgr.UpdateStatusByClusterID(ctx, 1, []string{models.StatusDrifted},
+		&models.DriftReport{Status: models.StatusAccepted})
+	assert.Nil(t, err)
+	assert.False(t, updated)
+
+	// a stale report read before the acceptance does not override it
+	report.Revision = "r2"
+	assert.Nil(t, mgr.UpdateCheckedByID(ctx, report.ID, report))
+	got, err := mgr.GetByClusterID(ctx, 1)
+	assert.Nil(t, err)
+	assert.Equal(t, models.StatusAccepted, got.Status)
+	assert.Equal(t, "r2", got.Revision)
+	assert.Equal(t, uint(2), got.AcceptedBy)
+	assert.True(t, got.GetResources()[0].Missing)
+
+	report.Status = models.StatusResolved
+	report.SetResources(nil)
+	report.AcceptedAt = nil
+	report.AcceptedBy = 0
+	assert.Nil(t, mgr.UpdateByID(ctx, report.ID, report))
+	got, err = mgr.GetByClusterID(ctx, 1)
+	assert.Nil(t, err)
+	assert.Equal(t, models.StatusResolved, got.Status)
+	assert.Nil(t, got.AcceptedAt)
+	assert.Empty(t, got.GetResources())
+}
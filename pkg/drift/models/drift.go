@@ -0,0 +1,79 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// statuses of a drift report
+const (
+	// StatusDrifted means the live state differs from the desired state in gitops repo
+	StatusDrifted = "drifted"
+	// StatusAccepted means the drift has been reviewed and kept on purpose
+	StatusAccepted = "accepted"
+	// StatusResolved means the live state is consistent with the desired state again
+	StatusResolved = "resolved"
+)
+
+// DriftReport is the latest drift of a cluster detected by the drift job
+type DriftReport struct {
+	ID        uint
+	ClusterID uint
+	Status    string
+	// Revision is the gitops commit the desired state was rendered from
+	Revision string
+	// ManifestVersion is the version of the manifest in gitops repo
+	ManifestVersion string
+	// Resources is the json of []Resource
+	Resources string
+	// Fingerprint identifies the drifted resources, a drift is only reported again when it changes
+	Fingerprint string
+	DetectedAt  *time.Time
+	CheckedAt   time.Time
+	AcceptedAt  *time.Time
+	AcceptedBy  uint
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+// Resource is a drifted resource of a cluster
+type Resource struct {
+	Group     string `json:"group"`
+	Version   string `json:"version"`
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	// Missing means the resource is declared in gitops repo but absent in the cluster
+	Missing bool `json:"missing,omitempty"`
+	// FieldPaths are the paths of fields whose live values differ from the desired ones,
+	// e.g. spec.template.spec.containers[0].image
+	FieldPaths []string `json:"fieldPaths,omitempty"`
+}
+
+func (r *DriftReport) GetResources() []Resource {
+	var resources []Resource
+	if r.Resources == "" {
+		return resources
+	}
+	_ = json.Unmarshal([]byte(r.Resources), &resources)
+	return resources
+}
+
+func (r *DriftReport) SetResources(resources []Resource) {
+	bts, _ := json.Marshal(resources)
+	r.Resources = string(bts)
+}
@@ -54,8 +54,11 @@ func (d *dao) UpdateByID(ctx context.Context, id uint, environment *models.Envir
 		return err
 	}
 
-	// set displayName and autoFree
+	// set displayName and protection
 	environmentInDB.DisplayName = environment.DisplayName
+	environmentInDB.Protected = environment.Protected
+	environmentInDB.ApproverRole = environment.ApproverRole
+	environmentInDB.Approvers = environment.Approvers
 	res := d.db.WithContext(ctx).Save(&environmentInDB)
 	if res.Error != nil {
 		return herrors.NewErrUpdateFailed(herrors.EnvironmentInDB, res.Error.Error())
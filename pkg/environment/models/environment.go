@@ -15,16 +15,36 @@
 package models
 
 import (
+	"strings"
+
 	"github.com/horizoncd/horizon/pkg/server/global"
 )
 
+// ApproverSeparator separates the approvers of a protected environment
+const ApproverSeparator = ","
+
 type Environment struct {
 	global.Model
 
 	Name        string
 	DisplayName string
-	CreatedBy   uint
-	UpdatedBy   uint
+	// Protected deploy, builddeploy, rollback and restart of clusters in a protected
+	// environment need to be approved before executing
+	Protected bool
+	// ApproverRole members of the cluster with this role or a higher one can approve
+	ApproverRole string
+	// Approvers names or emails of the users who can approve, joined by ApproverSeparator
+	Approvers string
+	CreatedBy uint
+	UpdatedBy uint
+}
+
+// ApproverList returns the approvers of the environment
+func (e *Environment) ApproverList() []string {
+	if e.Approvers == "" {
+		return nil
+	}
+	return strings.Split(e.Approvers, ApproverSeparator)
 }
 
 type EnvironmentList []*Environment
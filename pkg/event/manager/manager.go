@@ -16,6 +16,7 @@ package manager
 
 import (
 	"context"
+	"strconv"
 
 	"gorm.io/gorm"
 
@@ -23,12 +24,17 @@ import (
 	herrors "github.com/horizoncd/horizon/core/errors"
 	"github.com/horizoncd/horizon/core/middleware/requestid"
 	"github.com/horizoncd/horizon/lib/q"
+	"github.com/horizoncd/horizon/pkg/audit"
+	perror "github.com/horizoncd/horizon/pkg/errors"
 	"github.com/horizoncd/horizon/pkg/event/dao"
 	"github.com/horizoncd/horizon/pkg/event/models"
 	"github.com/horizoncd/horizon/pkg/util/log"
 	"github.com/horizoncd/horizon/pkg/util/wlog"
 )
 
+// auditEventIDs is the annotation of the audit log of the request which triggers the events
+const auditEventIDs = "eventIDs"
+
 type Manager interface {
 	CreateEvent(ctx context.Context, event ...*models.Event) ([]*models.Event, error)
 	ListEvents(ctx context.Context, query *q.Query) ([]*models.Event, error)
@@ -39,6 +45,10 @@ type Manager interface {
 	GetEvent(ctx context.Context, id uint) (*models.Event, error)
 	ListSupportEvents() map[string]string
 	DeleteEvents(ctx context.Context, id ...uint) (int64, error)
+	// CreateMutationEvent creates the event of the resource changed from before to after,
+	// the extra has both of them and the changed fields, before is nil for creations and after is nil for deletions
+	CreateMutationEvent(ctx context.Context, resourceType string, resourceID uint,
+		eventType string, before, after interface{}) (*models.Event, error)
 }
 
 type manager struct {
@@ -69,6 +79,9 @@ func (m *manager) CreateEvent(ctx context.Context,
 	if err != nil {
 		return nil, herrors.NewErrCreateFailed(herrors.EventInDB, err.Error())
 	}
+	for _, event := range e {
+		audit.Annotate(ctx, auditEventIDs, strconv.FormatUint(uint64(event.ID), 10))
+	}
 
 	return e, nil
 }
@@ -121,22 +134,79 @@ func (m *manager) DeleteEvents(ctx context.Context, id ...uint) (int64, error) {
 	return m.dao.DeleteEvents(ctx, id...)
 }
 
+func (m *manager) CreateMutationEvent(ctx context.Context, resourceType string, resourceID uint,
+	eventType string, before, after interface{}) (*models.Event, error) {
+	const op = "event manager: create mutation event"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	extra, err := models.Diff(before, after)
+	if err != nil {
+		return nil, perror.Wrapf(herrors.ErrParamInvalid, "failed to diff %s: %v", eventType, err)
+	}
+	events, err := m.CreateEvent(ctx, &models.Event{
+		EventSummary: models.EventSummary{
+			ResourceType: resourceType,
+			ResourceID:   resourceID,
+			EventType:    eventType,
+			Extra:        extra,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return events[0], nil
+}
+
 var supportedEvents = map[string]string{
-	models.ApplicationCreated:     "New application has been created",
-	models.ApplicationDeleted:     "Application has been deleted",
-	models.ApplicationTransfered:  "Application has been transferred to another group",
-	models.ApplicationUpdated:     "Application has been updated",
-	models.ClusterCreated:         "New cluster has been created",
-	models.ClusterDeleted:         "Cluster has been deleted",
-	models.ClusterUpdated:         "Cluster has been updated",
-	models.ClusterBuildDeployed:   "Cluster has completed a build task and triggered a deploy task",
-	models.ClusterDeployed:        "Cluster has triggered a deploying task",
-	models.ClusterRollbacked:      "Cluster has triggered a rollback task",
-	models.ClusterFreed:           "Cluster has been freed",
-	models.ClusterRestarted:       "Cluster has been restarted",
-	models.ClusterAction:          "Cluster has triggered an action",
-	models.ClusterPodsRescheduled: "Pods has been deleted to reschedule",
-	models.ClusterKubernetesEvent: "Kubernetes event associated with cluster has been triggered",
+	models.ApplicationCreated:       "New application has been created",
+	models.ApplicationDeleted:       "Application has been deleted",
+	models.ApplicationTransfered:    "Application has been transferred to another group",
+	models.ApplicationUpdated:       "Application has been updated",
+	models.ClusterCreated:           "New cluster has been created",
+	models.ClusterDeleted:           "Cluster has been deleted",
+	models.ClusterUpdated:           "Cluster has been updated",
+	models.ClusterBuildDeployed:     "Cluster has completed a build task and triggered a deploy task",
+	models.ClusterDeployed:          "Cluster has triggered a deploying task",
+	models.ClusterRollbacked:        "Cluster has triggered a rollback task",
+	models.ClusterPromoted:          "Cluster has been promoted from the previous environment",
+	models.ClusterFreed:             "Cluster has been freed",
+	models.ClusterRestarted:         "Cluster has been restarted",
+	models.ClusterAction:            "Cluster has triggered an action",
+	models.ClusterPending:           "Cluster operation in a protected environment is waiting for approval",
+	models.ClusterApproved:          "Cluster operation in a protected environment has been approved",
+	models.ClusterRejected:          "Cluster operation in a protected environment has been rejected",
+	models.ClusterFreezeOverride:    "Cluster operation has been performed during a freeze window by override",
+	models.ClusterDrifted:           "Live resources of cluster have drifted from the desired state in git",
+	models.ClusterPipelinerunFailed: "Pipelinerun of cluster has failed",
+	models.ClusterPodsRescheduled:   "Pods has been deleted to reschedule",
+	models.ClusterKubernetesEvent:   "Kubernetes event associated with cluster has been triggered",
+	models.GroupCreated:             "New group has been created",
+	models.GroupUpdated:             "Group has been updated",
+	models.GroupTransferred:         "Group has been transferred to another group",
+	models.GroupDeleted:             "Group has been deleted",
+	models.MemberAdded:              "New member has been added",
+	models.MemberRoleUpdated:        "Role of member has been updated",
+	models.MemberRemoved:            "Member has been removed",
+	models.TemplateCreated:          "New template has been created",
+	models.TemplateUpdated:          "Template has been updated",
+	models.TemplateDeleted:          "Template has been deleted",
+	models.TemplateReleaseCreated:   "New release of template has been created",
+	models.TemplateReleaseUpdated:   "Release of template has been updated",
+	models.TemplateReleaseDeleted:   "Release of template has been deleted",
+	models.AccessTokenCreated:       "New access token has been created",
+	models.AccessTokenRevoked:       "Access token has been revoked",
+	models.RegionCreated:            "New region has been created",
+	models.RegionUpdated:            "Region has been updated",
+	models.RegionDeleted:            "Region has been deleted",
+	models.RegistryCreated:          "New registry has been created",
+	models.RegistryUpdated:          "Registry has been updated",
+	models.RegistryDeleted:          "Registry has been deleted",
+	models.EnvironmentCreated:       "New environment has been created",
+	models.EnvironmentUpdated:       "Environment has been updated",
+	models.EnvironmentDeleted:       "Environment has been deleted",
+	models.IdpCreated:               "New identity provider has been created",
+	models.IdpUpdated:               "Identity provider has been updated",
+	models.IdpDeleted:               "Identity provider has been deleted",
 }
 
 func (m *manager) ListSupportEvents() map[string]string {
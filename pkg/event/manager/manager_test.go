@@ -16,6 +16,7 @@ package manager
 
 import (
 	"context"
+	"encoding/json"
 	"testing"
 
 	"github.com/horizoncd/horizon/core/common"
@@ -111,3 +112,38 @@ func Test(t *testing.T) {
 	assert.Nil(t, err)
 	assert.Equal(t, 0, len(events))
 }
+
+func TestCreateMutationEvent(t *testing.T) {
+	createCtx()
+	type region struct {
+		Name        string `json:"name"`
+		DisplayName string `json:"displayName"`
+	}
+
+	updated, err := m.CreateMutationEvent(ctx, common.ResourceRegion, 1, eventmodels.RegionUpdated,
+		&region{Name: "hz", DisplayName: "HZ"}, &region{Name: "hz", DisplayName: "HangZhou"})
+	assert.Nil(t, err)
+	assert.Equal(t, common.ResourceRegion, updated.ResourceType)
+	assert.Equal(t, eventmodels.RegionUpdated, updated.EventType)
+	extra := eventmodels.MutationExtra{}
+	assert.Nil(t, json.Unmarshal([]byte(*updated.Extra), &extra))
+	assert.Equal(t, "HZ", extra.Before["displayName"])
+	assert.Equal(t, "HangZhou", extra.After["displayName"])
+	assert.Equal(t, []string{"displayName"}, extra.Changes)
+
+	// creations have no before
+	var nilRegion *region
+	created, err := m.CreateMutationEvent(ctx, common.ResourceRegion, 2, eventmodels.RegionCreated,
+		nilRegion, &region{Name: "sh"})
+	assert.Nil(t, err)
+	extra = eventmodels.MutationExtra{}
+	assert.Nil(t, json.Unmarshal([]byte(*created.Extra), &extra))
+	assert.Nil(t, extra.Before)
+	assert.Equal(t, []string{"displayName", "name"}, extra.Changes)
+
+	_, err = m.CreateMutationEvent(ctx, common.ResourceRegion, 3, eventmodels.RegionDeleted,
+		make(chan int), nil)
+	assert.NotNil(t, err)
+	_, err = m.DeleteEvents(ctx, updated.ID, created.ID)
+	assert.Nil(t, err)
+}
@@ -0,0 +1,77 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package models
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+)
+
+// MutationExtra is the extra of the events of mutations
+type MutationExtra struct {
+	Before map[string]interface{} `json:"before,omitempty"`
+	After  map[string]interface{} `json:"after,omitempty"`
+	// Changes are the fields changed from before to after
+	Changes []string `json:"changes"`
+}
+
+// Diff makes the extra of the mutation from before to after by their json,
+// before is nil for creations and after is nil for deletions
+func Diff(before, after interface{}) (*string, error) {
+	beforeFields, err := toFields(before)
+	if err != nil {
+		return nil, err
+	}
+	afterFields, err := toFields(after)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := []string{}
+	for key, value := range beforeFields {
+		if afterValue, ok := afterFields[key]; !ok || !reflect.DeepEqual(value, afterValue) {
+			changes = append(changes, key)
+		}
+	}
+	for key := range afterFields {
+		if _, ok := beforeFields[key]; !ok {
+			changes = append(changes, key)
+		}
+	}
+	sort.Strings(changes)
+
+	b, err := json.Marshal(MutationExtra{Before: beforeFields, After: afterFields, Changes: changes})
+	if err != nil {
+		return nil, err
+	}
+	extra := string(b)
+	return &extra, nil
+}
+
+func toFields(v interface{}) (map[string]interface{}, error) {
+	if v == nil || (reflect.ValueOf(v).Kind() == reflect.Ptr && reflect.ValueOf(v).IsNil()) {
+		return nil, nil
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	fields := map[string]interface{}{}
+	if err := json.Unmarshal(b, &fields); err != nil {
+		return nil, err
+	}
+	return fields, nil
+}
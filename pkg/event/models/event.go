@@ -22,23 +22,64 @@ type EventType string
 type EventResourceType string
 
 const (
-	Any                    string = "*"
-	ApplicationCreated     string = "applications_created"
-	ApplicationDeleted     string = "applications_deleted"
-	ApplicationUpdated     string = "applications_updated"
-	ApplicationTransfered  string = "applications_transferred"
-	ClusterCreated         string = "clusters_created"
-	ClusterDeleted         string = "clusters_deleted"
-	ClusterBuildDeployed   string = "clusters_builddeployed"
-	ClusterDeployed        string = "clusters_deployed"
-	ClusterRollbacked      string = "clusters_rollbacked"
-	ClusterRestarted       string = "clsuters_restarted"
-	ClusterPodsRescheduled string = "clusters_rescheduled"
-	ClusterUpdated         string = "clusters_updated"
-	ClusterFreed           string = "clusters_freed"
-	ClusterKubernetesEvent string = "clusters_kubernetes_event"
-	ClusterAction                 = "clusters_action"
-	// TODO: add group events
+	Any                      string = "*"
+	ApplicationCreated       string = "applications_created"
+	ApplicationDeleted       string = "applications_deleted"
+	ApplicationUpdated       string = "applications_updated"
+	ApplicationTransfered    string = "applications_transferred"
+	ClusterCreated           string = "clusters_created"
+	ClusterDeleted           string = "clusters_deleted"
+	ClusterBuildDeployed     string = "clusters_builddeployed"
+	ClusterDeployed          string = "clusters_deployed"
+	ClusterRollbacked        string = "clusters_rollbacked"
+	ClusterPromoted          string = "clusters_promoted"
+	ClusterRestarted         string = "clsuters_restarted"
+	ClusterPodsRescheduled   string = "clusters_rescheduled"
+	ClusterUpdated           string = "clusters_updated"
+	ClusterFreed             string = "clusters_freed"
+	ClusterKubernetesEvent   string = "clusters_kubernetes_event"
+	ClusterAction                   = "clusters_action"
+	ClusterPending                  = "clusters_pending_approval"
+	ClusterApproved                 = "clusters_approved"
+	ClusterRejected                 = "clusters_rejected"
+	ClusterFreezeOverride           = "clusters_freeze_overridden"
+	ClusterDrifted                  = "clusters_drifted"
+	ClusterPipelinerunFailed        = "clusters_pipelinerun_failed"
+
+	GroupCreated     = "groups_created"
+	GroupUpdated     = "groups_updated"
+	GroupTransferred = "groups_transferred"
+	GroupDeleted     = "groups_deleted"
+
+	// events of members are triggered on the resources of the members
+	MemberAdded       = "members_added"
+	MemberRoleUpdated = "members_role_updated"
+	MemberRemoved     = "members_removed"
+
+	TemplateCreated        = "templates_created"
+	TemplateUpdated        = "templates_updated"
+	TemplateDeleted        = "templates_deleted"
+	TemplateReleaseCreated = "templatereleases_created"
+	TemplateReleaseUpdated = "templatereleases_updated"
+	TemplateReleaseDeleted = "templatereleases_deleted"
+
+	// events of resource access tokens are triggered on the resources of the tokens,
+	// and events of personal access tokens are triggered on the users
+	AccessTokenCreated = "accesstokens_created"
+	AccessTokenRevoked = "accesstokens_revoked"
+
+	RegionCreated      = "regions_created"
+	RegionUpdated      = "regions_updated"
+	RegionDeleted      = "regions_deleted"
+	RegistryCreated    = "registries_created"
+	RegistryUpdated    = "registries_updated"
+	RegistryDeleted    = "registries_deleted"
+	EnvironmentCreated = "environments_created"
+	EnvironmentUpdated = "environments_updated"
+	EnvironmentDeleted = "environments_deleted"
+	IdpCreated         = "idps_created"
+	IdpUpdated         = "idps_updated"
+	IdpDeleted         = "idps_deleted"
 )
 
 type EventSummary struct {
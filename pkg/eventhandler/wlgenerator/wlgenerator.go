@@ -19,6 +19,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"strings"
 
 	"gopkg.in/yaml.v3"
 
@@ -33,6 +34,8 @@ import (
 	"github.com/horizoncd/horizon/pkg/event/models"
 	groupmanager "github.com/horizoncd/horizon/pkg/group/manager"
 	"github.com/horizoncd/horizon/pkg/param/managerparam"
+	templatemanager "github.com/horizoncd/horizon/pkg/template/manager"
+	trmanager "github.com/horizoncd/horizon/pkg/templaterelease/manager"
 	usermanager "github.com/horizoncd/horizon/pkg/user/manager"
 	usermodels "github.com/horizoncd/horizon/pkg/user/models"
 	"github.com/horizoncd/horizon/pkg/util/log"
@@ -53,6 +56,7 @@ type MessageContent struct {
 	WebhookID   uint                  `json:"webhookID,omitempty"`
 	Application *ApplicationInfo      `json:"application,omitempty"`
 	Cluster     *ClusterInfo          `json:"cluster,omitempty"`
+	Resource    *ResourceInfo         `json:"resource,omitempty"`
 	EventType   string                `json:"eventType,omitempty"`
 	User        *usermodels.UserBasic `json:"user,omitempty"`
 	Extra       *string               `json:"extra,omitempty"`
@@ -76,6 +80,12 @@ type ClusterInfo struct {
 	Env             string `json:"env,omitempty"`
 }
 
+// ResourceInfo contains basic info of the resource of events other than applications and clusters
+type ResourceInfo struct {
+	ResourceCommonInfo
+	Type string `json:"type"`
+}
+
 // WebhookLogGenerator generates webhook logs by events
 type WebhookLogGenerator struct {
 	webhookMgr     webhookmanager.Manager
@@ -84,6 +94,8 @@ type WebhookLogGenerator struct {
 	applicationMgr applicationmanager.Manager
 	clusterMgr     clustermanager.Manager
 	userMgr        usermanager.Manager
+	templateMgr    templatemanager.Manager
+	releaseMgr     trmanager.Manager
 }
 
 func NewWebhookLogGenerator(manager *managerparam.Manager) *WebhookLogGenerator {
@@ -94,6 +106,8 @@ func NewWebhookLogGenerator(manager *managerparam.Manager) *WebhookLogGenerator
 		applicationMgr: manager.ApplicationManager,
 		clusterMgr:     manager.ClusterMgr,
 		userMgr:        manager.UserManager,
+		templateMgr:    manager.TemplateMgr,
+		releaseMgr:     manager.TemplateReleaseManager,
 	}
 }
 
@@ -102,6 +116,7 @@ type messageDependency struct {
 	event       *models.Event
 	application *applicationmodels.Application
 	cluster     *clustermodels.Cluster
+	resource    *ResourceInfo
 }
 
 // listSystemResources lists root group(0) as system resource
@@ -149,6 +164,89 @@ func (w *WebhookLogGenerator) listAssociatedResourcesOfCluster(ctx context.Conte
 	return cluster, app, resources
 }
 
+// snapshotOfEvent returns the latest snapshot of the resource recorded by the mutation event
+func snapshotOfEvent(e *models.Event) map[string]interface{} {
+	if e.Extra == nil || *e.Extra == "" {
+		return nil
+	}
+	extra := models.MutationExtra{}
+	if err := json.Unmarshal([]byte(*e.Extra), &extra); err != nil {
+		return nil
+	}
+	if extra.After != nil {
+		return extra.After
+	}
+	return extra.Before
+}
+
+// idOfSnapshot reads the id of key in snapshot, json numbers are decoded as float64
+func idOfSnapshot(snapshot map[string]interface{}, key string) uint {
+	if id, ok := snapshot[key].(float64); ok && id > 0 {
+		return uint(id)
+	}
+	return 0
+}
+
+// appendGroupResources appends the group and all of its parents to resources
+func (w *WebhookLogGenerator) appendGroupResources(ctx context.Context,
+	resources map[string][]uint, groupID uint) {
+	if groupID == 0 {
+		return
+	}
+	group, err := w.groupMgr.GetByID(ctx, groupID)
+	if err != nil {
+		log.Warningf(ctx, "group %d is not exist", groupID)
+		resources[common.ResourceGroup] = append(resources[common.ResourceGroup], groupID)
+		return
+	}
+	groupIDs := groupmanager.FormatIDsFromTraversalIDs(group.TraversalIDs)
+	resources[common.ResourceGroup] = append(resources[common.ResourceGroup], groupIDs...)
+}
+
+// listAssociatedResourcesOfGroup lists the group and its parents,
+// the parent is read from the snapshot if the group has been deleted
+func (w *WebhookLogGenerator) listAssociatedResourcesOfGroup(ctx context.Context,
+	id uint, snapshot map[string]interface{}) map[string][]uint {
+	resources := w.listSystemResources()
+	if _, err := w.groupMgr.GetByID(ctx, id); err == nil {
+		w.appendGroupResources(ctx, resources, id)
+		return resources
+	}
+	w.appendGroupResources(ctx, resources, idOfSnapshot(snapshot, "parentID"))
+	resources[common.ResourceGroup] = append(resources[common.ResourceGroup], id)
+	return resources
+}
+
+// listAssociatedResourcesOfTemplate lists the template and the groups it belongs to
+func (w *WebhookLogGenerator) listAssociatedResourcesOfTemplate(ctx context.Context,
+	id uint, snapshot map[string]interface{}) map[string][]uint {
+	resources := w.listSystemResources()
+	resources[common.ResourceTemplate] = []uint{id}
+	if template, err := w.templateMgr.GetByID(ctx, id); err == nil {
+		w.appendGroupResources(ctx, resources, template.GroupID)
+	} else {
+		w.appendGroupResources(ctx, resources, idOfSnapshot(snapshot, "group"))
+	}
+	return resources
+}
+
+// listAssociatedResourcesOfRelease lists the release, its template and the groups of the template
+func (w *WebhookLogGenerator) listAssociatedResourcesOfRelease(ctx context.Context,
+	id uint, snapshot map[string]interface{}) map[string][]uint {
+	templateID := idOfSnapshot(snapshot, "templateID")
+	if release, err := w.releaseMgr.GetByID(ctx, id); err == nil {
+		templateID = release.Template
+	}
+	var resources map[string][]uint
+	if templateID != 0 {
+		resources = w.listAssociatedResourcesOfTemplate(ctx, templateID, nil)
+	} else {
+		resources = w.listSystemResources()
+	}
+	resources[common.ResourceTemplateRelease] = []uint{id}
+	return resources
+}
+
 // listAssociatedResources list all the associated resources of event to find all the webhooks
 func (w *WebhookLogGenerator) listAssociatedResources(ctx context.Context,
 	e *models.Event) (*messageDependency, map[string][]uint) {
@@ -167,17 +265,42 @@ func (w *WebhookLogGenerator) listAssociatedResources(ctx context.Context,
 		cluster, application, resources = w.listAssociatedResourcesOfCluster(ctx, e.ResourceID)
 		dep.application = application
 		dep.cluster = cluster
+	case common.ResourceGroup:
+		resources = w.listAssociatedResourcesOfGroup(ctx, e.ResourceID, snapshotOfEvent(e))
+	case common.ResourceTemplate:
+		resources = w.listAssociatedResourcesOfTemplate(ctx, e.ResourceID, snapshotOfEvent(e))
+	case common.ResourceTemplateRelease:
+		resources = w.listAssociatedResourcesOfRelease(ctx, e.ResourceID, snapshotOfEvent(e))
+	case common.ResourceRegion, common.ResourceRegistry, common.ResourceEnvironment,
+		common.ResourceIdp, common.ResourceUser:
+		// system resources could only be subscribed by the webhooks of root group
+		resources = w.listSystemResources()
+		resources[e.ResourceType] = []uint{e.ResourceID}
 	default:
 		log.Infof(ctx, "resource type %s is unsupported",
 			e.ResourceType)
 	}
+	if resources != nil && e.ResourceType != common.ResourceApplication &&
+		e.ResourceType != common.ResourceCluster {
+		dep.resource = &ResourceInfo{
+			ResourceCommonInfo: ResourceCommonInfo{ID: e.ResourceID},
+			Type:               e.ResourceType,
+		}
+		// the snapshot is of the resource itself only when the event is of its own mutation,
+		// e.g. groups_updated rather than members_added of a group
+		if strings.HasPrefix(e.EventType, e.ResourceType+"_") {
+			if name, ok := snapshotOfEvent(e)["name"].(string); ok {
+				dep.resource.Name = name
+			}
+		}
+	}
 	return dep, resources
 }
 
 // makeRequestHeaders assemble headers of webhook request
-func (w *WebhookLogGenerator) makeRequestHeaders(secret string) (string, error) {
+// the secret is not saved with the log, requests are signed when they are sent
+func (w *WebhookLogGenerator) makeRequestHeaders() (string, error) {
 	header := http.Header{}
-	header.Add(WebhookSecretHeader, secret)
 	header.Add(WebhookContentTypeHeader, WebhookContentType)
 	headerByte, err := yaml.Marshal(header)
 	if err != nil {
@@ -192,6 +315,7 @@ func (w *WebhookLogGenerator) makeRequestBody(ctx context.Context, dep *messageD
 		EventID:   dep.event.ID,
 		WebhookID: dep.webhook.ID,
 		EventType: dep.event.EventType,
+		Resource:  dep.resource,
 		Extra:     dep.event.EventSummary.Extra,
 	}
 
@@ -280,6 +404,7 @@ func (w *WebhookLogGenerator) Process(ctx context.Context, events []*models.Even
 				event:       event,
 				application: dependency.application,
 				cluster:     dependency.cluster,
+				resource:    dependency.resource,
 			}
 			conditionsToQuery[event.ID] = append(conditionsToQuery[event.ID], webhook.ID)
 		}
@@ -308,7 +433,7 @@ func (w *WebhookLogGenerator) Process(ctx context.Context, events []*models.Even
 	}
 	for _, dependencyMap := range conditionsToCreate {
 		for _, dependency := range dependencyMap {
-			headers, err := w.makeRequestHeaders(dependency.webhook.Secret)
+			headers, err := w.makeRequestHeaders()
 			if err != nil {
 				log.Errorf(ctx, fmt.Sprintf("failed to make headers, error: %+v", err))
 				continue
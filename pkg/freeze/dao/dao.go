@@ -0,0 +1,106 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dao
+
+import (
+	"context"
+
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/pkg/common"
+	"github.com/horizoncd/horizon/pkg/freeze/models"
+
+	"gorm.io/gorm"
+)
+
+type DAO interface {
+	Create(ctx context.Context, window *models.FreezeWindow) (*models.FreezeWindow, error)
+	GetByID(ctx context.Context, id uint) (*models.FreezeWindow, error)
+	// ListByResources lists the freeze windows attached to any of the resources
+	ListByResources(ctx context.Context, resources map[string][]uint) ([]*models.FreezeWindow, error)
+	UpdateByID(ctx context.Context, id uint, window *models.FreezeWindow) error
+	DeleteByID(ctx context.Context, id uint) error
+}
+
+type dao struct {
+	db *gorm.DB
+}
+
+func NewDAO(db *gorm.DB) DAO {
+	return &dao{db: db}
+}
+
+func (d *dao) Create(ctx context.Context, window *models.FreezeWindow) (*models.FreezeWindow, error) {
+	result := d.db.WithContext(ctx).Create(window)
+	if result.Error != nil {
+		return nil, herrors.NewErrInsertFailed(herrors.FreezeWindowInDB, result.Error.Error())
+	}
+	return window, nil
+}
+
+func (d *dao) GetByID(ctx context.Context, id uint) (*models.FreezeWindow, error) {
+	var window models.FreezeWindow
+	result := d.db.WithContext(ctx).Raw(common.FreezeWindowGetByID, id).First(&window)
+	if result.Error != nil {
+		if result.Error == gorm.ErrRecordNotFound {
+			return nil, herrors.NewErrNotFound(herrors.FreezeWindowInDB, result.Error.Error())
+		}
+		return nil, herrors.NewErrGetFailed(herrors.FreezeWindowInDB, result.Error.Error())
+	}
+	return &window, nil
+}
+
+func (d *dao) ListByResources(ctx context.Context,
+	resources map[string][]uint) ([]*models.FreezeWindow, error) {
+	var windows []*models.FreezeWindow
+	if len(resources) == 0 {
+		return windows, nil
+	}
+
+	var condition *gorm.DB
+	for resourceType, resourceIDs := range resources {
+		subCondition := d.db.Where("resource_type = ?", resourceType).
+			Where("resource_id in ?", resourceIDs)
+		if condition != nil {
+			condition.Or(subCondition)
+		} else {
+			condition = subCondition
+		}
+	}
+	result := d.db.WithContext(ctx).Where(condition).Order("id asc").Find(&windows)
+	if result.Error != nil {
+		return nil, herrors.NewErrGetFailed(herrors.FreezeWindowInDB, result.Error.Error())
+	}
+	return windows, nil
+}
+
+func (d *dao) UpdateByID(ctx context.Context, id uint, window *models.FreezeWindow) error {
+	result := d.db.WithContext(ctx).Where("id = ?", id).Select("Name", "Description",
+		"StartAt", "EndAt", "Cron", "Duration").Updates(window)
+	if result.Error != nil {
+		return herrors.NewErrUpdateFailed(herrors.FreezeWindowInDB, result.Error.Error())
+	}
+	if result.RowsAffected == 0 {
+		return herrors.NewErrNotFound(herrors.FreezeWindowInDB, "freeze window not found")
+	}
+	return nil
+}
+
+func (d *dao) DeleteByID(ctx context.Context, id uint) error {
+	result := d.db.WithContext(ctx).Exec(common.FreezeWindowDeleteByID, id)
+	if result.Error != nil {
+		return herrors.NewErrDeleteFailed(herrors.FreezeWindowInDB, result.Error.Error())
+	}
+	return nil
+}
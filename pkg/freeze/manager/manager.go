@@ -0,0 +1,74 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manager
+
+import (
+	"context"
+
+	"github.com/horizoncd/horizon/pkg/freeze/dao"
+	"github.com/horizoncd/horizon/pkg/freeze/models"
+	"github.com/horizoncd/horizon/pkg/util/wlog"
+	"gorm.io/gorm"
+)
+
+type Manager interface {
+	Create(ctx context.Context, window *models.FreezeWindow) (*models.FreezeWindow, error)
+	GetByID(ctx context.Context, id uint) (*models.FreezeWindow, error)
+	// ListByResources lists the freeze windows attached to any of the resources, keyed by resource type
+	ListByResources(ctx context.Context, resources map[string][]uint) ([]*models.FreezeWindow, error)
+	UpdateByID(ctx context.Context, id uint, window *models.FreezeWindow) error
+	DeleteByID(ctx context.Context, id uint) error
+}
+
+type manager struct {
+	dao dao.DAO
+}
+
+func New(db *gorm.DB) Manager {
+	return &manager{
+		dao: dao.NewDAO(db),
+	}
+}
+
+func (m *manager) Create(ctx context.Context, window *models.FreezeWindow) (*models.FreezeWindow, error) {
+	const op = "freeze window manager: create"
+	defer wlog.Start(ctx, op).StopPrint()
+	return m.dao.Create(ctx, window)
+}
+
+func (m *manager) GetByID(ctx context.Context, id uint) (*models.FreezeWindow, error) {
+	const op = "freeze window manager: get by id"
+	defer wlog.Start(ctx, op).StopPrint()
+	return m.dao.GetByID(ctx, id)
+}
+
+func (m *manager) ListByResources(ctx context.Context,
+	resources map[string][]uint) ([]*models.FreezeWindow, error) {
+	const op = "freeze window manager: list by resources"
+	defer wlog.Start(ctx, op).StopPrint()
+	return m.dao.ListByResources(ctx, resources)
+}
+
+func (m *manager) UpdateByID(ctx context.Context, id uint, window *models.FreezeWindow) error {
+	const op = "freeze window manager: update by id"
+	defer wlog.Start(ctx, op).StopPrint()
+	return m.dao.UpdateByID(ctx, id, window)
+}
+
+func (m *manager) DeleteByID(ctx context.Context, id uint) error {
+	const op = "freeze window manager: delete by id"
+	defer wlog.Start(ctx, op).StopPrint()
+	return m.dao.DeleteByID(ctx, id)
+}
@@ -0,0 +1,77 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package models
+
+import (
+	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+// FreezeWindow is a period during which mutating operations on clusters are forbidden.
+// It is attached to an environment, a group (inherited by all the applications below it)
+// or an application, and it is either an absolute range or a recurring one.
+type FreezeWindow struct {
+	ID uint
+	// ResourceType can be environments, groups or applications
+	ResourceType string
+	ResourceID   uint
+	Name         string
+	Description  string
+	// StartAt and EndAt bound an absolute freeze window
+	StartAt *time.Time
+	EndAt   *time.Time
+	// Cron is the schedule when a recurring freeze window begins, in standard cron format
+	// with an optional CRON_TZ prefix, and Duration is how long it lasts, e.g. 48h
+	Cron      string
+	Duration  string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	CreatedBy uint
+	UpdatedBy uint
+}
+
+// IsRecurring tells whether the window is defined by a cron schedule
+func (w *FreezeWindow) IsRecurring() bool {
+	return w.Cron != ""
+}
+
+// ActiveUntil returns when the window ends if it is active at the given time
+func (w *FreezeWindow) ActiveUntil(now time.Time) (time.Time, bool) {
+	if !w.IsRecurring() {
+		if w.StartAt == nil || w.EndAt == nil {
+			return time.Time{}, false
+		}
+		if now.Before(*w.StartAt) || !now.Before(*w.EndAt) {
+			return time.Time{}, false
+		}
+		return *w.EndAt, true
+	}
+
+	schedule, err := cron.ParseStandard(w.Cron)
+	if err != nil {
+		return time.Time{}, false
+	}
+	duration, err := time.ParseDuration(w.Duration)
+	if err != nil || duration <= 0 {
+		return time.Time{}, false
+	}
+	// the latest begin of the window within (now - duration, now]
+	begin := schedule.Next(now.Add(-duration))
+	if begin.After(now) {
+		return time.Time{}, false
+	}
+	return begin.Add(duration), true
+}
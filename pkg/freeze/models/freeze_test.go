@@ -0,0 +1,50 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestActiveUntil(t *testing.T) {
+	now := time.Date(2023, 6, 10, 12, 0, 0, 0, time.UTC)
+	start, end := now.Add(-time.Hour), now.Add(time.Hour)
+
+	window := &FreezeWindow{StartAt: &start, EndAt: &end}
+	until, ok := window.ActiveUntil(now)
+	assert.True(t, ok)
+	assert.Equal(t, end, until)
+	_, ok = window.ActiveUntil(end)
+	assert.False(t, ok)
+	_, ok = window.ActiveUntil(start.Add(-time.Second))
+	assert.False(t, ok)
+
+	// every saturday from 00:00 for 48 hours, 2023-06-10 is a saturday
+	window = &FreezeWindow{Cron: "CRON_TZ=UTC 0 0 * * 6", Duration: "48h"}
+	until, ok = window.ActiveUntil(now)
+	assert.True(t, ok)
+	assert.Equal(t, time.Date(2023, 6, 12, 0, 0, 0, 0, time.UTC), until)
+	_, ok = window.ActiveUntil(time.Date(2023, 6, 12, 0, 0, 0, 0, time.UTC))
+	assert.False(t, ok)
+	_, ok = window.ActiveUntil(time.Date(2023, 6, 9, 23, 59, 0, 0, time.UTC))
+	assert.False(t, ok)
+
+	window = &FreezeWindow{Cron: "invalid", Duration: "48h"}
+	_, ok = window.ActiveUntil(now)
+	assert.False(t, ok)
+}
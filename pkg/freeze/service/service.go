@@ -0,0 +1,96 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/horizoncd/horizon/core/common"
+	applicationmanager "github.com/horizoncd/horizon/pkg/application/manager"
+	clustermanager "github.com/horizoncd/horizon/pkg/cluster/manager"
+	envmanager "github.com/horizoncd/horizon/pkg/environment/manager"
+	freezemanager "github.com/horizoncd/horizon/pkg/freeze/manager"
+	"github.com/horizoncd/horizon/pkg/freeze/models"
+	groupmanager "github.com/horizoncd/horizon/pkg/group/manager"
+	"github.com/horizoncd/horizon/pkg/param/managerparam"
+)
+
+type Service interface {
+	// GetActiveWindow returns the freeze window which is active now for the cluster and when it ends,
+	// windows of the cluster's environment, application and all the ancestor groups are considered.
+	// nil is returned if the cluster is not frozen
+	GetActiveWindow(ctx context.Context, clusterID uint) (*models.FreezeWindow, time.Time, error)
+}
+
+type service struct {
+	freezeWindowMgr freezemanager.Manager
+	clusterMgr      clustermanager.Manager
+	applicationMgr  applicationmanager.Manager
+	groupMgr        groupmanager.Manager
+	envMgr          envmanager.Manager
+}
+
+func NewService(manager *managerparam.Manager) Service {
+	return &service{
+		freezeWindowMgr: manager.FreezeWindowMgr,
+		clusterMgr:      manager.ClusterMgr,
+		applicationMgr:  manager.ApplicationManager,
+		groupMgr:        manager.GroupManager,
+		envMgr:          manager.EnvMgr,
+	}
+}
+
+func (s *service) GetActiveWindow(ctx context.Context,
+	clusterID uint) (*models.FreezeWindow, time.Time, error) {
+	cluster, err := s.clusterMgr.GetByID(ctx, clusterID)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	application, err := s.applicationMgr.GetByID(ctx, cluster.ApplicationID)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	group, err := s.groupMgr.GetByID(ctx, application.GroupID)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	env, err := s.envMgr.GetByName(ctx, cluster.EnvironmentName)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+
+	windows, err := s.freezeWindowMgr.ListByResources(ctx, map[string][]uint{
+		common.ResourceEnvironment: {env.ID},
+		common.ResourceApplication: {application.ID},
+		common.ResourceGroup:       groupmanager.FormatIDsFromTraversalIDs(group.TraversalIDs),
+	})
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+
+	// if several windows are active, report the one lasting longest
+	var (
+		active *models.FreezeWindow
+		until  time.Time
+		now    = time.Now()
+	)
+	for _, window := range windows {
+		if end, ok := window.ActiveUntil(now); ok && end.After(until) {
+			active, until = window, end
+		}
+	}
+	return active, until, nil
+}
@@ -0,0 +1,125 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/horizoncd/horizon/core/common"
+	"github.com/horizoncd/horizon/lib/orm"
+	applicationmodels "github.com/horizoncd/horizon/pkg/application/models"
+	clustermodels "github.com/horizoncd/horizon/pkg/cluster/models"
+	envmodels "github.com/horizoncd/horizon/pkg/environment/models"
+	"github.com/horizoncd/horizon/pkg/freeze/models"
+	groupmodels "github.com/horizoncd/horizon/pkg/group/models"
+	"github.com/horizoncd/horizon/pkg/param/managerparam"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	// use tmp sqlite
+	db, _   = orm.NewSqliteDB("")
+	ctx     = context.TODO()
+	manager = managerparam.InitManager(db)
+)
+
+// nolint
+func init() {
+	// create table
+	err := db.AutoMigrate(&clustermodels.Cluster{}, &applicationmodels.Application{},
+		&groupmodels.Group{}, &envmodels.Environment{}, &models.FreezeWindow{})
+	if err != nil {
+		fmt.Printf("%+v", err)
+		os.Exit(1)
+	}
+}
+
+func TestGetActiveWindow(t *testing.T) {
+	parent := &groupmodels.Group{Name: "a", Path: "a"}
+	db.Save(parent)
+	parent.TraversalIDs = fmt.Sprintf("%d", parent.ID)
+	db.Save(parent)
+	child := &groupmodels.Group{Name: "b", Path: "b", ParentID: parent.ID}
+	db.Save(child)
+	child.TraversalIDs = fmt.Sprintf("%d,%d", parent.ID, child.ID)
+	db.Save(child)
+
+	application := &applicationmodels.Application{Name: "app", GroupID: child.ID}
+	db.Save(application)
+	env := &envmodels.Environment{Name: "online", DisplayName: "online"}
+	db.Save(env)
+	cluster := &clustermodels.Cluster{Name: "app-online", ApplicationID: application.ID,
+		EnvironmentName: env.Name}
+	db.Save(cluster)
+
+	s := NewService(manager)
+
+	window, _, err := s.GetActiveWindow(ctx, cluster.ID)
+	assert.Nil(t, err)
+	assert.Nil(t, window)
+
+	now := time.Now()
+	past, future, later := now.Add(-time.Hour), now.Add(time.Hour), now.Add(2*time.Hour)
+	_, err = manager.FreezeWindowMgr.Create(ctx, &models.FreezeWindow{
+		ResourceType: common.ResourceGroup, ResourceID: parent.ID, Name: "expired",
+		StartAt: &past, EndAt: &past,
+	})
+	assert.Nil(t, err)
+	window, _, err = s.GetActiveWindow(ctx, cluster.ID)
+	assert.Nil(t, err)
+	assert.Nil(t, window)
+
+	// windows of ancestor groups are inherited
+	_, err = manager.FreezeWindowMgr.Create(ctx, &models.FreezeWindow{
+		ResourceType: common.ResourceGroup, ResourceID: parent.ID, Name: "release",
+		StartAt: &past, EndAt: &future,
+	})
+	assert.Nil(t, err)
+	window, until, err := s.GetActiveWindow(ctx, cluster.ID)
+	assert.Nil(t, err)
+	assert.NotNil(t, window)
+	assert.Equal(t, "release", window.Name)
+	assert.True(t, until.Equal(future))
+
+	// the longest active window is reported
+	_, err = manager.FreezeWindowMgr.Create(ctx, &models.FreezeWindow{
+		ResourceType: common.ResourceEnvironment, ResourceID: env.ID, Name: "holiday",
+		StartAt: &past, EndAt: &later,
+	})
+	assert.Nil(t, err)
+	window, _, err = s.GetActiveWindow(ctx, cluster.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, "holiday", window.Name)
+
+	// windows of other applications are ignored
+	other := &applicationmodels.Application{Name: "other", GroupID: child.ID}
+	db.Save(other)
+	otherCluster := &clustermodels.Cluster{Name: "other-online", ApplicationID: other.ID,
+		EnvironmentName: "online-other"}
+	db.Save(otherCluster)
+	db.Save(&envmodels.Environment{Name: "online-other", DisplayName: "online-other"})
+	_, err = manager.FreezeWindowMgr.Create(ctx, &models.FreezeWindow{
+		ResourceType: common.ResourceApplication, ResourceID: application.ID, Name: "app",
+		StartAt: &past, EndAt: &later,
+	})
+	assert.Nil(t, err)
+	window, _, err = s.GetActiveWindow(ctx, otherCluster.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, "release", window.Name)
+}
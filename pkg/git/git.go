@@ -16,6 +16,7 @@ package git
 
 import (
 	"context"
+	"net/http"
 	"regexp"
 
 	herrors "github.com/horizoncd/horizon/core/errors"
@@ -34,6 +35,13 @@ type Helper interface {
 	GetHTTPLink(gitURL string) (string, error)
 	GetCommitHistoryLink(gitURL string, commit string) (string, error)
 	GetTagArchive(ctx context.Context, gitURL, tagName string) (*Tag, error)
+	// Compare lists the files changed from a commit to another by the compare api of the git server
+	Compare(ctx context.Context, gitURL, from, to string) (*Comparison, error)
+	// ParsePushEvent parses the push event in the webhook sent by the repo, nil is returned for other events,
+	// the webhook is not verified, so nothing in it should be trusted before VerifyWebhook
+	ParsePushEvent(header http.Header, body []byte) (*PushEvent, error)
+	// VerifyWebhook verifies the webhook by the secret configured for it in the repo
+	VerifyWebhook(header http.Header, body []byte, secret string) error
 }
 
 type Constructor func(ctx context.Context, config *git.Repo) (Helper, error)
@@ -32,6 +32,9 @@ import (
 
 const Kind = "github"
 
+// _compareFilesLimit is the max number of files returned by the compare api of github
+const _compareFilesLimit = 300
+
 func init() {
 	git.Register(Kind, New)
 }
@@ -105,8 +108,9 @@ func (h Helper) GetCommit(ctx context.Context, gitURL string, refType string, re
 			return nil, err
 		}
 		return &git.Commit{
-			ID:      commit.GetSHA(),
-			Message: commit.Commit.GetMessage(),
+			ID:          commit.GetSHA(),
+			Message:     commit.Commit.GetMessage(),
+			CommittedAt: commit.Commit.GetCommitter().Date,
 		}, nil
 	case git.GitRefTypeTag:
 		// todo handle the situation that more than 100 tags exist
@@ -132,8 +136,9 @@ func (h Helper) GetCommit(ctx context.Context, gitURL string, refType string, re
 			return nil, err
 		}
 		return &git.Commit{
-			ID:      commit.GetSHA(),
-			Message: commit.Commit.GetMessage(),
+			ID:          commit.GetSHA(),
+			Message:     commit.Commit.GetMessage(),
+			CommittedAt: commit.Commit.GetCommitter().Date,
 		}, nil
 	case git.GitRefTypeBranch:
 		branch, _, err := h.client.Repositories.GetBranch(ctx, paths[0], paths[1], ref, true)
@@ -141,14 +146,39 @@ func (h Helper) GetCommit(ctx context.Context, gitURL string, refType string, re
 			return nil, err
 		}
 		return &git.Commit{
-			ID:      branch.Commit.GetSHA(),
-			Message: branch.Commit.Commit.GetMessage(),
+			ID:          branch.Commit.GetSHA(),
+			Message:     branch.Commit.Commit.GetMessage(),
+			CommittedAt: branch.Commit.Commit.GetCommitter().Date,
 		}, nil
 	default:
 		return nil, perror.Wrapf(herrors.ErrParamInvalid, "git ref type %s is invalid", refType)
 	}
 }
 
+func (h Helper) Compare(ctx context.Context, gitURL, from, to string) (*git.Comparison, error) {
+	pid, err := git.ExtractProjectPathFromURL(gitURL)
+	if err != nil {
+		return nil, err
+	}
+	paths := strings.Split(pid, "/")
+	comparison, _, err := h.client.Repositories.CompareCommits(ctx, paths[0], paths[1], from, to, nil)
+	if err != nil {
+		return nil, perror.Wrapf(herrors.NewErrGetFailed(herrors.GithubResource, "failed to compare commits"),
+			"failed to compare %s...%s of %s: err = %v", from, to, gitURL, err)
+	}
+	files := make([]string, 0, len(comparison.Files))
+	for _, file := range comparison.Files {
+		files = append(files, file.GetFilename())
+		if file.GetPreviousFilename() != "" {
+			files = append(files, file.GetPreviousFilename())
+		}
+	}
+	return &git.Comparison{
+		ChangedFiles: files,
+		Complete:     len(comparison.Files) < _compareFilesLimit,
+	}, nil
+}
+
 func (h Helper) ListBranch(ctx context.Context, gitURL string, params *git.SearchParams) ([]string, error) {
 	pid, err := git.ExtractProjectPathFromURL(gitURL)
 	if err != nil {
@@ -0,0 +1,76 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package github
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/google/go-github/v41/github"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/git"
+)
+
+const (
+	_eventHeader     = "X-GitHub-Event"
+	_signatureHeader = "X-Hub-Signature-256"
+	_pushEvent       = "push"
+	// _maxPushedCommits is the most commits github carries in a push webhook
+	_maxPushedCommits = 2048
+)
+
+// pushEvent is the part of push webhooks used by horizon
+type pushEvent struct {
+	Ref        string `json:"ref"`
+	After      string `json:"after"`
+	Repository struct {
+		SSHURL   string `json:"ssh_url"`
+		CloneURL string `json:"clone_url"`
+		HTMLURL  string `json:"html_url"`
+	} `json:"repository"`
+	Pusher struct {
+		Name string `json:"name"`
+	} `json:"pusher"`
+	Commits []*git.PushedCommit `json:"commits"`
+}
+
+// VerifyWebhook verifies the sha256 signature of the webhook
+func (h Helper) VerifyWebhook(header http.Header, body []byte, secret string) error {
+	if secret == "" {
+		return perror.Wrap(herrors.ErrSignatureInvalid, "webhook secret is empty")
+	}
+	signature := header.Get(_signatureHeader)
+	if signature == "" {
+		return perror.Wrapf(herrors.ErrSignatureInvalid, "%s is missing", _signatureHeader)
+	}
+	if err := github.ValidateSignature(signature, body, []byte(secret)); err != nil {
+		return perror.Wrap(herrors.ErrSignatureInvalid, err.Error())
+	}
+	return nil
+}
+
+// ParsePushEvent parses push webhooks, which are sent for both branches and tags
+func (h Helper) ParsePushEvent(header http.Header, body []byte) (*git.PushEvent, error) {
+	if header.Get(_eventHeader) != _pushEvent {
+		return nil, nil
+	}
+	var event pushEvent
+	if err := json.Unmarshal(body, &event); err != nil {
+		return nil, perror.Wrapf(herrors.ErrParamInvalid, "invalid push webhook: %v", err)
+	}
+	return git.NewPushEvent([]string{event.Repository.SSHURL, event.Repository.CloneURL, event.Repository.HTMLURL},
+		event.Ref, event.After, event.Pusher.Name, event.Commits, len(event.Commits) < _maxPushedCommits), nil
+}
@@ -100,8 +100,9 @@ func (h Helper) GetCommit(ctx context.Context, gitURL string, refType string, re
 			return nil, err
 		}
 		return &git.Commit{
-			ID:      commit.ID,
-			Message: commit.Message,
+			ID:          commit.ID,
+			Message:     commit.Message,
+			CommittedAt: commit.CommittedDate,
 		}, nil
 	case git.GitRefTypeTag:
 		tag, err := h.client.GetTag(ctx, pid, ref)
@@ -109,8 +110,9 @@ func (h Helper) GetCommit(ctx context.Context, gitURL string, refType string, re
 			return nil, err
 		}
 		return &git.Commit{
-			ID:      tag.Commit.ID,
-			Message: tag.Commit.Message,
+			ID:          tag.Commit.ID,
+			Message:     tag.Commit.Message,
+			CommittedAt: tag.Commit.CommittedDate,
 		}, nil
 	case git.GitRefTypeBranch:
 		branch, err := h.client.GetBranch(ctx, pid, ref)
@@ -118,14 +120,38 @@ func (h Helper) GetCommit(ctx context.Context, gitURL string, refType string, re
 			return nil, err
 		}
 		return &git.Commit{
-			ID:      branch.Commit.ID,
-			Message: branch.Commit.Message,
+			ID:          branch.Commit.ID,
+			Message:     branch.Commit.Message,
+			CommittedAt: branch.Commit.CommittedDate,
 		}, nil
 	default:
 		return nil, perror.Wrapf(herrors.ErrParamInvalid, "git ref type %s is invalid", refType)
 	}
 }
 
+func (h Helper) Compare(ctx context.Context, gitURL, from, to string) (*git.Comparison, error) {
+	pid, err := git.ExtractProjectPathFromURL(gitURL)
+	if err != nil {
+		return nil, err
+	}
+	straight := true
+	compare, err := h.client.Compare(ctx, pid, from, to, &straight)
+	if err != nil {
+		return nil, err
+	}
+	files := make([]string, 0, len(compare.Diffs))
+	for _, diff := range compare.Diffs {
+		files = append(files, diff.NewPath)
+		if diff.OldPath != "" && diff.OldPath != diff.NewPath {
+			files = append(files, diff.OldPath)
+		}
+	}
+	return &git.Comparison{
+		ChangedFiles: files,
+		Complete:     !compare.CompareTimeout,
+	}, nil
+}
+
 func (h Helper) ListBranch(ctx context.Context, gitURL string, params *git.SearchParams) ([]string, error) {
 	pid, err := git.ExtractProjectPathFromURL(gitURL)
 	if err != nil {
@@ -0,0 +1,73 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gitlab
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"net/http"
+
+	herrors "github.com/horizoncd/horizon/core/errors"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/git"
+	"github.com/xanzy/go-gitlab"
+)
+
+const (
+	_eventHeader = "X-Gitlab-Event"
+	_tokenHeader = "X-Gitlab-Token"
+)
+
+// pushEvent is the part of push hooks and tag push hooks used by horizon
+type pushEvent struct {
+	Ref      string `json:"ref"`
+	After    string `json:"after"`
+	UserName string `json:"user_username"`
+	Project  struct {
+		GitSSHURL  string `json:"git_ssh_url"`
+		GitHTTPURL string `json:"git_http_url"`
+		WebURL     string `json:"web_url"`
+	} `json:"project"`
+	Commits           []*git.PushedCommit `json:"commits"`
+	TotalCommitsCount int                 `json:"total_commits_count"`
+}
+
+// VerifyWebhook verifies the secret token of the webhook
+func (h Helper) VerifyWebhook(header http.Header, body []byte, secret string) error {
+	if secret == "" {
+		return perror.Wrap(herrors.ErrSignatureInvalid, "webhook secret is empty")
+	}
+	if subtle.ConstantTimeCompare([]byte(header.Get(_tokenHeader)), []byte(secret)) != 1 {
+		return perror.Wrap(herrors.ErrSignatureInvalid, "secret token mismatch")
+	}
+	return nil
+}
+
+// ParsePushEvent parses push hooks and tag push hooks
+func (h Helper) ParsePushEvent(header http.Header, body []byte) (*git.PushEvent, error) {
+	switch gitlab.EventType(header.Get(_eventHeader)) {
+	case gitlab.EventTypePush, gitlab.EventTypeTagPush:
+	default:
+		return nil, nil
+	}
+	var event pushEvent
+	if err := json.Unmarshal(body, &event); err != nil {
+		return nil, perror.Wrapf(herrors.ErrParamInvalid, "invalid push hook: %v", err)
+	}
+	// gitlab carries at most 20 commits in a push hook
+	return git.NewPushEvent([]string{event.Project.GitSSHURL, event.Project.GitHTTPURL, event.Project.WebURL},
+		event.Ref, event.After, event.UserName, event.Commits,
+		event.TotalCommitsCount <= len(event.Commits)), nil
+}
@@ -14,6 +14,8 @@
 
 package git
 
+import "time"
+
 const (
 	GitRefTypeBranch = "branch"
 	GitRefTypeTag    = "tag"
@@ -29,6 +31,8 @@ type Tag struct {
 type Commit struct {
 	ID      string
 	Message string
+	// CommittedAt when the commit is committed, used to measure the lead time of changes
+	CommittedAt *time.Time
 }
 
 // SearchParams contains parameters for searching operation
@@ -37,3 +41,29 @@ type SearchParams struct {
 	PageNumber int
 	PageSize   int
 }
+
+// Comparison is the changes from a commit to another
+type Comparison struct {
+	// ChangedFiles are the files added, modified or removed, both paths of renamed files are listed
+	ChangedFiles []string
+	// Complete tells whether ChangedFiles covers all the changes, git servers limit the files compared
+	Complete bool
+}
+
+// PushEvent is a branch or tag pushed to a repo
+type PushEvent struct {
+	// GitURLs are the urls of the repo, e.g. the ssh url and the http url
+	GitURLs []string
+	// RefType is branch or tag
+	RefType string
+	Ref     string
+	// Commit is the commit the ref points to after the push, empty if the ref is deleted
+	Commit string
+	// Pusher is the user name of who pushed
+	Pusher string
+	// ChangedFiles are the files added, modified or removed by the pushed commits
+	ChangedFiles []string
+	// ChangedFilesComplete tells whether ChangedFiles covers all the pushed commits,
+	// webhook payloads only carry a limited number of commits
+	ChangedFilesComplete bool
+}
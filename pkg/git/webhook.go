@@ -0,0 +1,68 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package git
+
+import "strings"
+
+const (
+	_refHeadsPrefix = "refs/heads/"
+	_refTagsPrefix  = "refs/tags/"
+)
+
+// PushedCommit is a commit carried by a push webhook payload
+type PushedCommit struct {
+	Added    []string `json:"added"`
+	Modified []string `json:"modified"`
+	Removed  []string `json:"removed"`
+}
+
+// NewPushEvent builds the push event of ref, which is a full ref like refs/heads/main,
+// nil is returned if ref is neither a branch nor a tag
+func NewPushEvent(gitURLs []string, ref, after, pusher string,
+	commits []*PushedCommit, complete bool) *PushEvent {
+	event := &PushEvent{
+		Pusher:               pusher,
+		ChangedFilesComplete: complete,
+	}
+	switch {
+	case strings.HasPrefix(ref, _refHeadsPrefix):
+		event.RefType, event.Ref = GitRefTypeBranch, strings.TrimPrefix(ref, _refHeadsPrefix)
+	case strings.HasPrefix(ref, _refTagsPrefix):
+		event.RefType, event.Ref = GitRefTypeTag, strings.TrimPrefix(ref, _refTagsPrefix)
+	default:
+		return nil
+	}
+	// the after commit of a deleted ref is all zeros
+	if strings.Trim(after, "0") != "" {
+		event.Commit = after
+	}
+	for _, url := range gitURLs {
+		if url != "" {
+			event.GitURLs = append(event.GitURLs, url)
+		}
+	}
+	seen := make(map[string]bool)
+	for _, commit := range commits {
+		for _, files := range [][]string{commit.Added, commit.Modified, commit.Removed} {
+			for _, file := range files {
+				if !seen[file] {
+					seen[file] = true
+					event.ChangedFiles = append(event.ChangedFiles, file)
+				}
+			}
+		}
+	}
+	return event
+}
@@ -0,0 +1,42 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package git
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewPushEvent(t *testing.T) {
+	commits := []*PushedCommit{
+		{Added: []string{"a.go"}, Modified: []string{"b.go"}},
+		{Modified: []string{"a.go"}, Removed: []string{"c.go"}},
+	}
+	event := NewPushEvent([]string{"git@github.com:horizoncd/horizon.git", ""}, "refs/heads/main",
+		"0123456789abcdef", "tony", commits, true)
+	assert.Equal(t, []string{"git@github.com:horizoncd/horizon.git"}, event.GitURLs)
+	assert.Equal(t, GitRefTypeBranch, event.RefType)
+	assert.Equal(t, "main", event.Ref)
+	assert.Equal(t, "0123456789abcdef", event.Commit)
+	assert.Equal(t, []string{"a.go", "b.go", "c.go"}, event.ChangedFiles)
+
+	event = NewPushEvent(nil, "refs/tags/v1.0.0", "0000000000000000000000000000000000000000", "tony", nil, true)
+	assert.Equal(t, GitRefTypeTag, event.RefType)
+	assert.Equal(t, "v1.0.0", event.Ref)
+	assert.Equal(t, "", event.Commit)
+
+	assert.Nil(t, NewPushEvent(nil, "refs/merge-requests/1/head", "0123456789abcdef", "tony", nil, true))
+}
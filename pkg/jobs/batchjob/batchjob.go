@@ -0,0 +1,457 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package batchjob
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/horizoncd/horizon/core/common"
+	accessctl "github.com/horizoncd/horizon/core/controller/access"
+	clusterctl "github.com/horizoncd/horizon/core/controller/cluster"
+	tagctl "github.com/horizoncd/horizon/core/controller/tag"
+	"github.com/horizoncd/horizon/core/middleware/requestid"
+	"github.com/horizoncd/horizon/pkg/auth"
+	userauth "github.com/horizoncd/horizon/pkg/authentication/user"
+	"github.com/horizoncd/horizon/pkg/batchjob/models"
+	"github.com/horizoncd/horizon/pkg/config/batchjob"
+	eventmodels "github.com/horizoncd/horizon/pkg/event/models"
+	freezemodels "github.com/horizoncd/horizon/pkg/freeze/models"
+	freezeservice "github.com/horizoncd/horizon/pkg/freeze/service"
+	"github.com/horizoncd/horizon/pkg/param/managerparam"
+	prmodels "github.com/horizoncd/horizon/pkg/pipelinerun/models"
+	"github.com/horizoncd/horizon/pkg/rbac"
+	"github.com/horizoncd/horizon/pkg/rbac/types"
+	tagmodels "github.com/horizoncd/horizon/pkg/tag/models"
+	"github.com/horizoncd/horizon/pkg/util/log"
+	uuid "github.com/satori/go.uuid"
+)
+
+// ClusterOperator is the part of the cluster controller used by the job
+type ClusterOperator interface {
+	Deploy(ctx context.Context, clusterID uint,
+		request *clusterctl.DeployRequest) (*clusterctl.PipelinerunIDResponse, error)
+	Restart(ctx context.Context, clusterID uint) (*clusterctl.PipelinerunIDResponse, error)
+	ApplyTemplateMigration(ctx context.Context, releaseID uint,
+		r *clusterctl.TemplateMigrationRequest) (*clusterctl.TemplateMigrationResponse, error)
+}
+
+// TagOperator is the part of the tag controller used by the job
+type TagOperator interface {
+	List(ctx context.Context, resourceType string, resourceID uint) (*tagctl.ListResponse, error)
+	Update(ctx context.Context, resourceType string, resourceID uint, r *tagctl.UpdateRequest) error
+}
+
+// Job pushes running batch jobs forward: it starts the operation on pending clusters
+// as long as the concurrency allows, and tracks the clusters being operated until all are done.
+// Operations are performed as the creator of the batch job, and the creator's permission on
+// each cluster is reviewed just like the request was sent to the cluster's api,
+// including whether the creator may override the freeze window the cluster is in.
+type Job struct {
+	config      *batchjob.Config
+	mgr         *managerparam.Manager
+	operator    ClusterOperator
+	tagOperator TagOperator
+	reviewer    accessctl.Controller
+	authorizer  rbac.Authorizer
+	freezeSvc   freezeservice.Service
+}
+
+type freezeOverrideExtra struct {
+	FreezeWindowID uint      `json:"freezeWindowID"`
+	Name           string    `json:"name"`
+	ActiveUntil    time.Time `json:"activeUntil"`
+	BatchJobID     uint      `json:"batchJobID"`
+}
+
+func New(config *batchjob.Config, mgr *managerparam.Manager, operator ClusterOperator,
+	tagOperator TagOperator, reviewer accessctl.Controller, authorizer rbac.Authorizer,
+	freezeSvc freezeservice.Service) *Job {
+	return &Job{
+		config:      config,
+		mgr:         mgr,
+		operator:    operator,
+		tagOperator: tagOperator,
+		reviewer:    reviewer,
+		authorizer:  authorizer,
+		freezeSvc:   freezeSvc,
+	}
+}
+
+func (j *Job) Run(ctx context.Context) {
+	log.Infof(ctx, "Starting processing batch jobs every %v", j.config.JobInterval)
+	defer log.Infof(ctx, "Stopping processing batch jobs")
+	ticker := time.NewTicker(j.config.JobInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			rid := uuid.NewV4().String()
+			// nolint
+			ctx := context.WithValue(ctx, requestid.HeaderXRequestID, rid)
+			j.process(ctx)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (j *Job) process(ctx context.Context) {
+	jobs, err := j.mgr.BatchJobMgr.ListRunning(ctx)
+	if err != nil {
+		log.Errorf(ctx, "failed to list running batch jobs, err: %v", err)
+		return
+	}
+	for _, job := range jobs {
+		user, err := j.mgr.UserManager.GetUserByID(ctx, job.CreatedBy)
+		if err != nil {
+			log.Errorf(ctx, "failed to get creator of batch job %d, err: %v", job.ID, err)
+			continue
+		}
+		userCtx := common.WithContext(ctx, &userauth.DefaultInfo{
+			Name:     user.Name,
+			FullName: user.FullName,
+			ID:       user.ID,
+			Email:    user.Email,
+			Admin:    user.Admin,
+		})
+		if err := j.execute(userCtx, job, time.Now()); err != nil {
+			log.Errorf(ctx, "failed to execute batch job %d, err: %v", job.ID, err)
+		}
+	}
+}
+
+// execute pushes the job forward by one step
+func (j *Job) execute(ctx context.Context, job *models.BatchJob, now time.Time) error {
+	clusters, err := j.mgr.BatchJobMgr.ListClustersByJobID(ctx, job.ID)
+	if err != nil {
+		return err
+	}
+
+	// 1. track the clusters being operated
+	for _, cluster := range clusters {
+		if cluster.Status == models.ClusterStatusRunning {
+			if err := j.check(ctx, cluster, now); err != nil {
+				return err
+			}
+		}
+	}
+
+	// 2. start pending clusters within the concurrency and the failure budget
+	var running, failed int
+	for _, cluster := range clusters {
+		switch cluster.Status {
+		case models.ClusterStatusRunning:
+			running++
+		case models.ClusterStatusFailed:
+			failed++
+		}
+	}
+	for _, cluster := range clusters {
+		if failed > job.FailureBudget {
+			if err := j.mgr.BatchJobMgr.SkipPendingClusters(ctx, job.ID, "failure budget exceeded"); err != nil {
+				return err
+			}
+			if running > 0 {
+				// wait for the clusters being operated before failing the job
+				return nil
+			}
+			return j.finish(ctx, job, models.StatusFailed,
+				fmt.Sprintf("%d clusters failed, exceeding the failure budget %d", failed, job.FailureBudget))
+		}
+		if running >= job.Concurrency {
+			return nil
+		}
+		if cluster.Status != models.ClusterStatusPending {
+			continue
+		}
+		if err := j.start(ctx, job, cluster, now); err != nil {
+			return err
+		}
+		switch cluster.Status {
+		case models.ClusterStatusRunning:
+			running++
+		case models.ClusterStatusFailed:
+			failed++
+		}
+	}
+
+	// 3. finish the job once every cluster is done with
+	for _, cluster := range clusters {
+		if !cluster.IsFinished() {
+			return nil
+		}
+	}
+	if failed > job.FailureBudget {
+		return j.finish(ctx, job, models.StatusFailed,
+			fmt.Sprintf("%d clusters failed, exceeding the failure budget %d", failed, job.FailureBudget))
+	}
+	message := fmt.Sprintf("all %d clusters are done", len(clusters))
+	if failed > 0 {
+		message = fmt.Sprintf("all %d clusters are done, %d failed within the failure budget", len(clusters), failed)
+	}
+	return j.finish(ctx, job, models.StatusSucceeded, message)
+}
+
+// start performs the operation on the cluster if the creator is allowed to,
+// deploy and restart leave the cluster running until their pipelineruns finish
+func (j *Job) start(ctx context.Context, job *models.BatchJob,
+	cluster *models.BatchJobCluster, now time.Time) error {
+	fail := func(format string, args ...interface{}) error {
+		cluster.Status = models.ClusterStatusFailed
+		cluster.Message = fmt.Sprintf(format, args...)
+		cluster.FinishedAt = &now
+		return j.mgr.BatchJobMgr.UpdateClusterByID(ctx, cluster.ID, cluster)
+	}
+
+	allowed, reason, err := j.review(ctx, job.Operation, cluster.ClusterID)
+	if err != nil {
+		return err
+	}
+	if !allowed {
+		return fail("forbidden: %s", reason)
+	}
+
+	// every operation mutates the cluster, the cluster waits until the freeze window ends
+	frozen, err := j.frozen(ctx, job, cluster.ClusterID)
+	if err != nil {
+		return err
+	}
+	if frozen != "" {
+		cluster.Message = frozen
+		return j.mgr.BatchJobMgr.UpdateClusterByID(ctx, cluster.ID, cluster)
+	}
+
+	cluster.StartedAt = &now
+	params := job.GetParams()
+	switch job.Operation {
+	case models.OperationDeploy:
+		resp, err := j.operator.Deploy(ctx, cluster.ClusterID, &clusterctl.DeployRequest{
+			Title:       job.Title,
+			Description: fmt.Sprintf("batch job %d", job.ID),
+			ImageTag:    params.ImageTag,
+		})
+		if err != nil {
+			return fail("failed to deploy: %v", err)
+		}
+		cluster.PipelinerunID = resp.PipelinerunID
+	case models.OperationRestart:
+		resp, err := j.operator.Restart(ctx, cluster.ClusterID)
+		if err != nil {
+			return fail("failed to restart: %v", err)
+		}
+		cluster.PipelinerunID = resp.PipelinerunID
+	case models.OperationUpgrade:
+		message, err := j.upgrade(ctx, cluster.ClusterID, params.TemplateRelease)
+		if err != nil {
+			return fail("failed to upgrade: %v", err)
+		}
+		return j.succeed(ctx, cluster, message, now)
+	case models.OperationUpdateTags:
+		if err := j.updateTags(ctx, cluster.ClusterID, params.Tags); err != nil {
+			return fail("failed to update tags: %v", err)
+		}
+		return j.succeed(ctx, cluster, "", now)
+	default:
+		return fail("unsupported operation %s", job.Operation)
+	}
+	cluster.Status = models.ClusterStatusRunning
+	cluster.Message = ""
+	return j.mgr.BatchJobMgr.UpdateClusterByID(ctx, cluster.ID, cluster)
+}
+
+// review tells whether the current user may perform the operation on the cluster
+func (j *Job) review(ctx context.Context, operation string, clusterID uint) (bool, string, error) {
+	var api accessctl.API
+	switch operation {
+	case models.OperationDeploy:
+		api = accessctl.API{URL: fmt.Sprintf("/apis/core/v2/clusters/%d/deploy", clusterID), Method: http.MethodPost}
+	case models.OperationRestart:
+		api = accessctl.API{URL: fmt.Sprintf("/apis/core/v2/clusters/%d/restart", clusterID), Method: http.MethodPost}
+	case models.OperationUpgrade:
+		api = accessctl.API{URL: fmt.Sprintf("/apis/core/v2/clusters/%d", clusterID), Method: http.MethodPut}
+	case models.OperationUpdateTags:
+		api = accessctl.API{URL: fmt.Sprintf("/apis/core/v2/clusters/%d/tags", clusterID), Method: http.MethodPost}
+	default:
+		return false, fmt.Sprintf("unsupported operation %s", operation), nil
+	}
+	results, err := j.reviewer.Review(ctx, []accessctl.API{api})
+	if err != nil {
+		return false, "", err
+	}
+	result := results[api.URL][api.Method]
+	if result == nil {
+		return false, "not reviewed", nil
+	}
+	return result.Allowed, result.Reason, nil
+}
+
+// frozen tells why the cluster can not be operated if it's in an active freeze window,
+// the window is overridden if the creator is granted the freeze-override verb on the cluster
+func (j *Job) frozen(ctx context.Context, job *models.BatchJob, clusterID uint) (string, error) {
+	if j.freezeSvc == nil {
+		return "", nil
+	}
+	window, until, err := j.freezeSvc.GetActiveWindow(ctx, clusterID)
+	if err != nil || window == nil {
+		return "", err
+	}
+	message := fmt.Sprintf("frozen by %s until %s", window.Name, until.Format(time.RFC3339))
+	if j.authorizer == nil {
+		return message, nil
+	}
+
+	currentUser, err := common.UserFromContext(ctx)
+	if err != nil {
+		return "", err
+	}
+	decision, reason, err := j.authorizer.Authorize(ctx, auth.AttributesRecord{
+		User:            currentUser,
+		Verb:            types.VerbFreezeOverride,
+		APIGroup:        common.GroupCore,
+		Resource:        common.ResourceCluster,
+		Name:            strconv.FormatUint(uint64(clusterID), 10),
+		ResourceRequest: true,
+	})
+	if err != nil {
+		return "", err
+	}
+	if decision != auth.DecisionAllow {
+		log.Infof(ctx, "batch job %d is denied in freeze window %d with reason = %s", job.ID, window.ID, reason)
+		return message, nil
+	}
+
+	log.Infof(ctx, "freeze window %d is overridden by batch job %d of user %s",
+		window.ID, job.ID, currentUser.GetName())
+	j.recordOverrideEvent(ctx, job, clusterID, window, until)
+	return "", nil
+}
+
+func (j *Job) recordOverrideEvent(ctx context.Context, job *models.BatchJob, clusterID uint,
+	window *freezemodels.FreezeWindow, until time.Time) {
+	extraBytes, err := json.Marshal(freezeOverrideExtra{
+		FreezeWindowID: window.ID,
+		Name:           window.Name,
+		ActiveUntil:    until,
+		BatchJobID:     job.ID,
+	})
+	if err != nil {
+		log.Warningf(ctx, "failed to marshal event extra: %v", err.Error())
+	}
+	extra := string(extraBytes)
+	if _, err := j.mgr.EventManager.CreateEvent(ctx, &eventmodels.Event{
+		EventSummary: eventmodels.EventSummary{
+			ResourceType: common.ResourceCluster,
+			EventType:    eventmodels.ClusterFreezeOverride,
+			ResourceID:   clusterID,
+			Extra:        &extra,
+		},
+	}); err != nil {
+		log.Warningf(ctx, "failed to create event, err: %s", err.Error())
+	}
+}
+
+// upgrade upgrades the cluster to the release of its template with migrated values
+func (j *Job) upgrade(ctx context.Context, clusterID uint, release string) (string, error) {
+	cluster, err := j.mgr.ClusterMgr.GetByID(ctx, clusterID)
+	if err != nil {
+		return "", err
+	}
+	if cluster.TemplateRelease == release {
+		return fmt.Sprintf("already on release %s", release), nil
+	}
+	tr, err := j.mgr.TemplateReleaseManager.GetByTemplateNameAndRelease(ctx, cluster.Template, release)
+	if err != nil {
+		return "", err
+	}
+	resp, err := j.operator.ApplyTemplateMigration(ctx, tr.ID, &clusterctl.TemplateMigrationRequest{
+		ClusterIDs: []uint{clusterID},
+	})
+	if err != nil {
+		return "", err
+	}
+	for _, migration := range resp.Clusters {
+		if len(migration.Errors) > 0 {
+			return "", fmt.Errorf("%s", strings.Join(migration.Errors, "; "))
+		}
+	}
+	return fmt.Sprintf("upgraded from release %s", cluster.TemplateRelease), nil
+}
+
+// updateTags adds the tags to the cluster, overwriting the values of existing keys
+func (j *Job) updateTags(ctx context.Context, clusterID uint, tags tagmodels.TagsBasic) error {
+	current, err := j.tagOperator.List(ctx, common.ResourceCluster, clusterID)
+	if err != nil {
+		return err
+	}
+	updates := make(map[string]string, len(tags))
+	for _, tag := range tags {
+		updates[tag.Key] = tag.Value
+	}
+	merged := make([]*tagmodels.TagBasic, 0, len(current.Tags)+len(tags))
+	for _, tag := range current.Tags {
+		if _, ok := updates[tag.Key]; !ok {
+			merged = append(merged, &tagmodels.TagBasic{Key: tag.Key, Value: tag.Value})
+		}
+	}
+	merged = append(merged, tags...)
+	return j.tagOperator.Update(ctx, common.ResourceCluster, clusterID, &tagctl.UpdateRequest{Tags: merged})
+}
+
+// check updates the status of the running cluster by its pipelinerun
+func (j *Job) check(ctx context.Context, cluster *models.BatchJobCluster, now time.Time) error {
+	pr, err := j.mgr.PipelinerunMgr.GetByID(ctx, cluster.PipelinerunID)
+	if err != nil {
+		return err
+	}
+	switch prmodels.PipelineStatus(pr.Status) {
+	case prmodels.StatusOK:
+		return j.succeed(ctx, cluster, "", now)
+	case prmodels.StatusFailed, prmodels.StatusCancelled, prmodels.StatusRejected:
+		cluster.Status = models.ClusterStatusFailed
+		cluster.Message = fmt.Sprintf("pipelinerun %d is %s", pr.ID, pr.Status)
+		cluster.FinishedAt = &now
+		return j.mgr.BatchJobMgr.UpdateClusterByID(ctx, cluster.ID, cluster)
+	case prmodels.StatusPending, prmodels.StatusQueued:
+		// waiting for approval or in the run queue does not count towards the timeout
+		return nil
+	}
+	if cluster.StartedAt == nil || now.Sub(*cluster.StartedAt) < j.config.ClusterTimeout {
+		return nil
+	}
+	cluster.Status = models.ClusterStatusFailed
+	cluster.Message = fmt.Sprintf("pipelinerun %d is not finished within %v", pr.ID, j.config.ClusterTimeout)
+	cluster.FinishedAt = &now
+	return j.mgr.BatchJobMgr.UpdateClusterByID(ctx, cluster.ID, cluster)
+}
+
+func (j *Job) succeed(ctx context.Context, cluster *models.BatchJobCluster, message string, now time.Time) error {
+	cluster.Status = models.ClusterStatusSucceeded
+	cluster.Message = message
+	cluster.FinishedAt = &now
+	return j.mgr.BatchJobMgr.UpdateClusterByID(ctx, cluster.ID, cluster)
+}
+
+func (j *Job) finish(ctx context.Context, job *models.BatchJob, status, message string) error {
+	job.Status = status
+	job.Message = message
+	_, err := j.mgr.BatchJobMgr.UpdateStatusByID(ctx, job.ID, []string{models.StatusRunning}, job)
+	return err
+}
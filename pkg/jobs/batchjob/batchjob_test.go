@@ -0,0 +1,290 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package batchjob
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/horizoncd/horizon/core/common"
+	accessctl "github.com/horizoncd/horizon/core/controller/access"
+	clusterctl "github.com/horizoncd/horizon/core/controller/cluster"
+	tagctl "github.com/horizoncd/horizon/core/controller/tag"
+	"github.com/horizoncd/horizon/lib/orm"
+	"github.com/horizoncd/horizon/pkg/auth"
+	userauth "github.com/horizoncd/horizon/pkg/authentication/user"
+	"github.com/horizoncd/horizon/pkg/batchjob/models"
+	clustermodels "github.com/horizoncd/horizon/pkg/cluster/models"
+	"github.com/horizoncd/horizon/pkg/config/batchjob"
+	eventmodels "github.com/horizoncd/horizon/pkg/event/models"
+	freezemodels "github.com/horizoncd/horizon/pkg/freeze/models"
+	"github.com/horizoncd/horizon/pkg/param/managerparam"
+	prmodels "github.com/horizoncd/horizon/pkg/pipelinerun/models"
+	rbactypes "github.com/horizoncd/horizon/pkg/rbac/types"
+	"github.com/horizoncd/horizon/pkg/server/global"
+	tagmodels "github.com/horizoncd/horizon/pkg/tag/models"
+	trmodels "github.com/horizoncd/horizon/pkg/templaterelease/models"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	db, _   = orm.NewSqliteDB("")
+	manager = managerparam.InitManager(db)
+	// nolint
+	ctx = common.WithContext(context.Background(), &userauth.DefaultInfo{Name: "Tony", ID: 1})
+)
+
+// fakeOperator creates a pipelinerun with the preset status for each restart
+type fakeOperator struct {
+	statuses  map[uint]prmodels.PipelineStatus
+	restarted []uint
+	upgraded  []uint
+}
+
+func (o *fakeOperator) Deploy(context.Context, uint,
+	*clusterctl.DeployRequest) (*clusterctl.PipelinerunIDResponse, error) {
+	return nil, fmt.Errorf("not implemented")
+}
+
+func (o *fakeOperator) Restart(ctx context.Context, clusterID uint) (*clusterctl.PipelinerunIDResponse, error) {
+	o.restarted = append(o.restarted, clusterID)
+	status, ok := o.statuses[clusterID]
+	if !ok {
+		status = prmodels.StatusOK
+	}
+	pr, err := manager.PipelinerunMgr.Create(ctx, &prmodels.Pipelinerun{
+		ClusterID: clusterID,
+		Action:    prmodels.ActionRestart,
+		Status:    string(status),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &clusterctl.PipelinerunIDResponse{PipelinerunID: pr.ID}, nil
+}
+
+func (o *fakeOperator) ApplyTemplateMigration(_ context.Context, _ uint,
+	r *clusterctl.TemplateMigrationRequest) (*clusterctl.TemplateMigrationResponse, error) {
+	o.upgraded = append(o.upgraded, r.ClusterIDs...)
+	return &clusterctl.TemplateMigrationResponse{}, nil
+}
+
+type fakeTagOperator struct {
+	tags map[uint][]*tagmodels.TagBasic
+}
+
+func (o *fakeTagOperator) List(_ context.Context, _ string, resourceID uint) (*tagctl.ListResponse, error) {
+	resp := &tagctl.ListResponse{}
+	for _, tag := range o.tags[resourceID] {
+		resp.Tags = append(resp.Tags, &tagctl.Tag{Key: tag.Key, Value: tag.Value})
+	}
+	return resp, nil
+}
+
+func (o *fakeTagOperator) Update(_ context.Context, _ string, resourceID uint, r *tagctl.UpdateRequest) error {
+	o.tags[resourceID] = r.Tags
+	return nil
+}
+
+// fakeReviewer denies the apis of the forbidden clusters
+type fakeReviewer struct {
+	forbidden map[uint]bool
+}
+
+func (r *fakeReviewer) Review(_ context.Context,
+	apis []accessctl.API) (map[string]map[string]*accessctl.ReviewResult, error) {
+	results := make(map[string]map[string]*accessctl.ReviewResult)
+	for _, api := range apis {
+		allowed := true
+		for id := range r.forbidden {
+			if api.URL == fmt.Sprintf("/apis/core/v2/clusters/%d/restart", id) ||
+				api.URL == fmt.Sprintf("/apis/core/v2/clusters/%d/tags", id) {
+				allowed = false
+			}
+		}
+		results[api.URL] = map[string]*accessctl.ReviewResult{
+			api.Method: {Allowed: allowed, Reason: "not a member"},
+		}
+	}
+	return results, nil
+}
+
+// fakeFreezeSvc freezes the clusters in the window
+type fakeFreezeSvc struct {
+	frozen map[uint]bool
+}
+
+func (s *fakeFreezeSvc) GetActiveWindow(_ context.Context,
+	clusterID uint) (*freezemodels.FreezeWindow, time.Time, error) {
+	if !s.frozen[clusterID] {
+		return nil, time.Time{}, nil
+	}
+	return &freezemodels.FreezeWindow{ID: 1, Name: "release-freeze"}, time.Now().Add(time.Hour), nil
+}
+
+// fakeAuthorizer allows to override the freeze window of the clusters
+type fakeAuthorizer struct {
+	overridable map[string]bool
+}
+
+func (a *fakeAuthorizer) Authorize(_ context.Context, attr auth.Attributes) (auth.Decision, string, error) {
+	if attr.GetVerb() == rbactypes.VerbFreezeOverride && attr.GetResource() == common.ResourceCluster &&
+		a.overridable[attr.GetName()] {
+		return auth.DecisionAllow, "release manager", nil
+	}
+	return auth.DecisionDeny, "not a release manager", nil
+}
+
+func TestMain(m *testing.M) {
+	if err := db.AutoMigrate(&prmodels.Pipelinerun{}, &models.BatchJob{}, &models.BatchJobCluster{},
+		&clustermodels.Cluster{}, &trmodels.TemplateRelease{}, &eventmodels.Event{}); err != nil {
+		panic(err)
+	}
+	os.Exit(m.Run())
+}
+
+func createJob(t *testing.T, operation string, params models.Params, concurrency, failureBudget int,
+	clusterIDs ...uint) *models.BatchJob {
+	job := &models.BatchJob{
+		GroupID:       1,
+		Title:         operation,
+		Operation:     operation,
+		Concurrency:   concurrency,
+		FailureBudget: failureBudget,
+		Status:        models.StatusRunning,
+		CreatedBy:     1,
+	}
+	job.SetParams(params)
+	clusters := make([]*models.BatchJobCluster, 0, len(clusterIDs))
+	for _, id := range clusterIDs {
+		clusters = append(clusters, &models.BatchJobCluster{ClusterID: id, Status: models.ClusterStatusPending})
+	}
+	job, err := manager.BatchJobMgr.Create(ctx, job, clusters)
+	assert.Nil(t, err)
+	return job
+}
+
+func tick(t *testing.T, job *Job, id uint, now time.Time) (*models.BatchJob, []*models.BatchJobCluster) {
+	b, err := manager.BatchJobMgr.GetByID(ctx, id)
+	assert.Nil(t, err)
+	assert.Nil(t, job.execute(ctx, b, now))
+	b, err = manager.BatchJobMgr.GetByID(ctx, id)
+	assert.Nil(t, err)
+	clusters, err := manager.BatchJobMgr.ListClustersByJobID(ctx, id)
+	assert.Nil(t, err)
+	return b, clusters
+}
+
+func TestExecuteConcurrency(t *testing.T) {
+	operator := &fakeOperator{statuses: map[uint]prmodels.PipelineStatus{2: prmodels.StatusCreated}}
+	job := New(&batchjob.Config{ClusterTimeout: 10 * time.Minute}, manager, operator,
+		nil, &fakeReviewer{}, nil, nil)
+	b := createJob(t, models.OperationRestart, models.Params{}, 2, 1, 1, 2, 3)
+	now := time.Now()
+
+	// 1. two clusters are restarted at a time
+	b, clusters := tick(t, job, b.ID, now)
+	assert.Equal(t, []uint{1, 2}, operator.restarted)
+	assert.Equal(t, models.ClusterStatusRunning, clusters[0].Status)
+	assert.Equal(t, models.ClusterStatusPending, clusters[2].Status)
+
+	// 2. the third starts once the first succeeds
+	b, clusters = tick(t, job, b.ID, now)
+	assert.Equal(t, []uint{1, 2, 3}, operator.restarted)
+	assert.Equal(t, models.ClusterStatusSucceeded, clusters[0].Status)
+	assert.Equal(t, models.StatusRunning, b.Status)
+
+	// 3. the stuck cluster times out, and the job succeeds within the failure budget
+	b, clusters = tick(t, job, b.ID, now.Add(20*time.Minute))
+	assert.Equal(t, models.ClusterStatusFailed, clusters[1].Status)
+	assert.Equal(t, models.ClusterStatusSucceeded, clusters[2].Status)
+	assert.Equal(t, models.StatusSucceeded, b.Status)
+	assert.Contains(t, b.Message, "1 failed")
+}
+
+func TestExecuteFailureBudget(t *testing.T) {
+	operator := &fakeOperator{statuses: map[uint]prmodels.PipelineStatus{1: prmodels.StatusFailed}}
+	job := New(&batchjob.Config{ClusterTimeout: 10 * time.Minute}, manager, operator,
+		nil, &fakeReviewer{forbidden: map[uint]bool{2: true}}, nil, nil)
+	b := createJob(t, models.OperationRestart, models.Params{}, 1, 1, 1, 2, 3)
+	now := time.Now()
+
+	tick(t, job, b.ID, now)
+	b, clusters := tick(t, job, b.ID, now)
+	assert.Equal(t, models.ClusterStatusFailed, clusters[0].Status)
+	assert.Equal(t, models.ClusterStatusFailed, clusters[1].Status)
+	assert.Contains(t, clusters[1].Message, "forbidden")
+	assert.Equal(t, models.StatusFailed, b.Status)
+
+	// the rest are skipped once the budget is exceeded
+	clusters, err := manager.BatchJobMgr.ListClustersByJobID(ctx, b.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, models.ClusterStatusSkipped, clusters[2].Status)
+	assert.Equal(t, []uint{1}, operator.restarted)
+}
+
+func TestExecuteUpdateTags(t *testing.T) {
+	tagOperator := &fakeTagOperator{tags: map[uint][]*tagmodels.TagBasic{
+		1: {{Key: "team", Value: "a"}, {Key: "tier", Value: "web"}},
+	}}
+	job := New(&batchjob.Config{}, manager, &fakeOperator{}, tagOperator, &fakeReviewer{}, nil, nil)
+	b := createJob(t, models.OperationUpdateTags, models.Params{
+		Tags: tagmodels.TagsBasic{{Key: "team", Value: "b"}},
+	}, 5, 0, 1, 2)
+
+	b, clusters := tick(t, job, b.ID, time.Now())
+	assert.Equal(t, models.StatusSucceeded, b.Status)
+	assert.Equal(t, models.ClusterStatusSucceeded, clusters[0].Status)
+	assert.Equal(t, []*tagmodels.TagBasic{{Key: "tier", Value: "web"}, {Key: "team", Value: "b"}},
+		tagOperator.tags[1])
+	assert.Equal(t, []*tagmodels.TagBasic{{Key: "team", Value: "b"}}, tagOperator.tags[2])
+}
+
+func TestExecuteUpgradeFrozen(t *testing.T) {
+	for _, id := range []uint{11, 12} {
+		db.Create(&clustermodels.Cluster{Model: global.Model{ID: id}, Name: fmt.Sprintf("app-%d", id),
+			Template: "javaapp", TemplateRelease: "v1.0.0"})
+	}
+	db.Create(&trmodels.TemplateRelease{TemplateName: "javaapp", Name: "v1.1.0"})
+
+	operator := &fakeOperator{}
+	freezeSvc := &fakeFreezeSvc{frozen: map[uint]bool{11: true, 12: true}}
+	job := New(&batchjob.Config{}, manager, operator, nil, &fakeReviewer{},
+		&fakeAuthorizer{overridable: map[string]bool{"12": true}}, freezeSvc)
+	b := createJob(t, models.OperationUpgrade, models.Params{TemplateRelease: "v1.1.0"}, 5, 0, 11, 12)
+
+	// the frozen cluster waits, while the one the creator may override the window of is upgraded
+	b, clusters := tick(t, job, b.ID, time.Now())
+	assert.Equal(t, models.StatusRunning, b.Status)
+	assert.Equal(t, models.ClusterStatusPending, clusters[0].Status)
+	assert.Contains(t, clusters[0].Message, "frozen by release-freeze")
+	assert.Equal(t, models.ClusterStatusSucceeded, clusters[1].Status)
+	assert.Equal(t, []uint{12}, operator.upgraded)
+	events, err := manager.EventManager.ListEventsByRange(ctx, 0, 100)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(events))
+	assert.Equal(t, eventmodels.ClusterFreezeOverride, events[0].EventType)
+	assert.Equal(t, uint(12), events[0].ResourceID)
+
+	// the cluster is upgraded once the window ends
+	freezeSvc.frozen[11] = false
+	b, clusters = tick(t, job, b.ID, time.Now())
+	assert.Equal(t, models.StatusSucceeded, b.Status)
+	assert.Equal(t, models.ClusterStatusSucceeded, clusters[0].Status)
+	assert.Equal(t, []uint{12, 11}, operator.upgraded)
+}
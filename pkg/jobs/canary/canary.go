@@ -0,0 +1,334 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package canary
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/horizoncd/horizon/core/common"
+	clusterctl "github.com/horizoncd/horizon/core/controller/cluster"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/core/middleware/requestid"
+	"github.com/horizoncd/horizon/lib/q"
+	userauth "github.com/horizoncd/horizon/pkg/authentication/user"
+	canarymodels "github.com/horizoncd/horizon/pkg/canary/models"
+	"github.com/horizoncd/horizon/pkg/canary/prometheus"
+	cmodels "github.com/horizoncd/horizon/pkg/cluster/models"
+	"github.com/horizoncd/horizon/pkg/config/canary"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/param/managerparam"
+	prmodels "github.com/horizoncd/horizon/pkg/pipelinerun/models"
+	"github.com/horizoncd/horizon/pkg/templaterelease/analysis"
+	"github.com/horizoncd/horizon/pkg/util/log"
+	"github.com/horizoncd/horizon/pkg/workload/rollout"
+	uuid "github.com/satori/go.uuid"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+const (
+	_actionPromote = "promote"
+	_actionAbort   = "abort"
+
+	// _rollbackCandidates is how many rollback-able pipelineruns are looked up for the rollback target
+	_rollbackCandidates = 10
+)
+
+// ClusterOperator is the part of the cluster controller used by the job
+type ClusterOperator interface {
+	GetStep(ctx context.Context, clusterID uint) (*clusterctl.GetStepResponse, error)
+	ExecuteAction(ctx context.Context, clusterID uint, action string, gvr schema.GroupVersionResource) error
+	Rollback(ctx context.Context, clusterID uint,
+		request *clusterctl.RollbackRequest) (*clusterctl.PipelinerunIDResponse, error)
+}
+
+// Job evaluates the success criteria of running canary analyses periodically,
+// promotes healthy steps and aborts and rolls back unhealthy ones
+type Job struct {
+	config   *canary.Config
+	mgr      *managerparam.Manager
+	operator ClusterOperator
+	getter   analysis.Getter
+	querier  prometheus.Querier
+}
+
+func New(config *canary.Config, mgr *managerparam.Manager, operator ClusterOperator,
+	getter analysis.Getter, querier prometheus.Querier) *Job {
+	return &Job{
+		config:   config,
+		mgr:      mgr,
+		operator: operator,
+		getter:   getter,
+		querier:  querier,
+	}
+}
+
+func (j *Job) Run(ctx context.Context) {
+	if !j.config.Enabled {
+		return
+	}
+
+	// verify account
+	user, err := j.mgr.UserManager.GetUserByID(ctx, j.config.AccountID)
+	if err != nil {
+		log.Errorf(ctx, "failed to verify operator of canary analysis, err: %v", err.Error())
+		return
+	}
+	ctx = common.WithContext(ctx, &userauth.DefaultInfo{
+		Name:     user.Name,
+		FullName: user.FullName,
+		ID:       user.ID,
+		Email:    user.Email,
+		Admin:    user.Admin,
+	})
+
+	log.Infof(ctx, "Starting analyzing canary steps every %v", j.config.JobInterval)
+	defer log.Infof(ctx, "Stopping analyzing canary steps")
+	ticker := time.NewTicker(j.config.JobInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			rid := uuid.NewV4().String()
+			// nolint
+			ctx = context.WithValue(ctx, requestid.HeaderXRequestID, rid)
+			j.process(ctx)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (j *Job) process(ctx context.Context) {
+	analyses, err := j.mgr.CanaryAnalysisMgr.ListRunning(ctx)
+	if err != nil {
+		log.Errorf(ctx, "failed to list running canary analyses, err: %v", err)
+		return
+	}
+	for _, a := range analyses {
+		if err := j.analyze(ctx, a, time.Now()); err != nil {
+			log.Errorf(ctx, "failed to analyze canary analysis %d of cluster %d, err: %v",
+				a.ID, a.ClusterID, err)
+		}
+	}
+}
+
+func (j *Job) analyze(ctx context.Context, a *canarymodels.CanaryAnalysis, now time.Time) error {
+	cluster, err := j.mgr.ClusterMgr.GetByID(ctx, a.ClusterID)
+	if err != nil {
+		if _, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); ok {
+			return j.finish(ctx, a, canarymodels.StatusCanceled, "cluster has been deleted", now)
+		}
+		return err
+	}
+	spec, err := j.getter.GetTemplateAnalysis(ctx, cluster.Template, cluster.TemplateRelease)
+	if err != nil {
+		return err
+	}
+	if spec == nil || len(spec.Metrics) == 0 {
+		return j.finish(ctx, a, canarymodels.StatusCanceled, "template declares no analysis", now)
+	}
+
+	step, err := j.operator.GetStep(ctx, a.ClusterID)
+	if err != nil {
+		return err
+	}
+
+	// the rollout has no canary steps left, the analysis is done once the last step has been observed
+	if step.Total <= 1 || step.Index >= step.Total {
+		if now.Sub(a.StartedAt) < spec.Interval {
+			return nil
+		}
+		return j.finish(ctx, a, canarymodels.StatusPassed, "rollout finished", now)
+	}
+	// the first batch has not been rolled out yet
+	if step.Index == 0 {
+		return nil
+	}
+	if a.Step == 0 {
+		a.Step = step.Index
+		a.StartedAt = now
+		return j.mgr.CanaryAnalysisMgr.UpdateByID(ctx, a.ID, a)
+	}
+	if step.Index < a.Step {
+		return nil
+	}
+	// the step has been promoted by someone else
+	if step.Index > a.Step {
+		if err := j.finish(ctx, a, canarymodels.StatusPassed, "promoted manually", now); err != nil {
+			return err
+		}
+		return j.next(ctx, a, step.Index, now)
+	}
+
+	results, healthy, complete := j.evaluate(ctx, cluster, spec)
+	a.SetMetricResults(results)
+	if !healthy {
+		return j.fail(ctx, a, results, now)
+	}
+	if !complete || now.Sub(a.StartedAt) < spec.Interval {
+		return j.mgr.CanaryAnalysisMgr.UpdateByID(ctx, a.ID, a)
+	}
+
+	if err := j.operator.ExecuteAction(ctx, a.ClusterID, _actionPromote, rollout.GVRRollout); err != nil {
+		return err
+	}
+	if err := j.finish(ctx, a, canarymodels.StatusPassed, "promoted", now); err != nil {
+		return err
+	}
+	if step.Index+1 < step.Total {
+		return j.next(ctx, a, step.Index+1, now)
+	}
+	return nil
+}
+
+// evaluate queries all metrics of the spec. healthy is false when any metric breaches its threshold,
+// complete is false when any metric can not be evaluated
+func (j *Job) evaluate(ctx context.Context, cluster *cmodels.Cluster,
+	spec *analysis.Spec) (results []canarymodels.MetricResult, healthy bool, complete bool) {
+	healthy, complete = true, true
+	vars := analysis.QueryVars{
+		Cluster:     cluster.Name,
+		Environment: cluster.EnvironmentName,
+		Region:      cluster.RegionName,
+	}
+	if application, err := j.mgr.ApplicationManager.GetByID(ctx, cluster.ApplicationID); err == nil {
+		vars.Application = application.Name
+	}
+
+	prometheusURL := ""
+	region, err := j.mgr.RegionMgr.GetRegionEntity(ctx, cluster.RegionName)
+	if err == nil {
+		prometheusURL = region.PrometheusURL
+	}
+
+	for i := range spec.Metrics {
+		metric := &spec.Metrics[i]
+		result := canarymodels.MetricResult{
+			Name:      metric.Name,
+			Condition: metric.Condition,
+			Threshold: metric.Threshold,
+		}
+		query, err := metric.RenderQuery(vars)
+		switch {
+		case err != nil:
+			result.Error = err.Error()
+		case prometheusURL == "":
+			result.Error = fmt.Sprintf("prometheus of region %s is not configured", cluster.RegionName)
+		default:
+			result.Query = query
+			value, err := j.querier.Query(ctx, prometheusURL, query)
+			if err != nil {
+				result.Error = err.Error()
+				break
+			}
+			result.Value = &value
+			result.Passed = metric.Passed(value)
+			if !result.Passed {
+				healthy = false
+			}
+		}
+		if result.Error != "" {
+			complete = false
+		}
+		results = append(results, result)
+	}
+	return results, healthy, complete
+}
+
+// fail aborts the rollout and rolls the cluster back to the previous deployment
+func (j *Job) fail(ctx context.Context, a *canarymodels.CanaryAnalysis,
+	results []canarymodels.MetricResult, now time.Time) error {
+	breached := make([]string, 0, len(results))
+	for _, result := range results {
+		if result.Value != nil && !result.Passed {
+			breached = append(breached, fmt.Sprintf("%s=%v", result.Name, *result.Value))
+		}
+	}
+	message := fmt.Sprintf("metrics breached: %s", strings.Join(breached, ", "))
+
+	if err := j.operator.ExecuteAction(ctx, a.ClusterID, _actionAbort, rollout.GVRRollout); err != nil {
+		message = fmt.Sprintf("%s; failed to abort rollout: %v", message, err)
+	}
+	target, err := j.rollbackTarget(ctx, a)
+	switch {
+	case err != nil:
+		message = fmt.Sprintf("%s; failed to find rollback target: %v", message, err)
+	case target == 0:
+		message = fmt.Sprintf("%s; no deployment to roll back to", message)
+	default:
+		message = fmt.Sprintf("%s; %s", message, j.rollback(ctx, a, target))
+	}
+	return j.finish(ctx, a, canarymodels.StatusFailed, message, now)
+}
+
+// rollback rolls the cluster back to the target pipelinerun, and describes the result of the rollback.
+// The rollback may be pending for approval or queued behind other pipelineruns,
+// so it's reported as rolled back only if the rollback pipelinerun is ok.
+func (j *Job) rollback(ctx context.Context, a *canarymodels.CanaryAnalysis, target uint) string {
+	resp, err := j.operator.Rollback(ctx, a.ClusterID, &clusterctl.RollbackRequest{PipelinerunID: target})
+	if err != nil {
+		return fmt.Sprintf("failed to roll back to pipelinerun %d: %v", target, err)
+	}
+	pr, err := j.mgr.PipelinerunMgr.GetByID(ctx, resp.PipelinerunID)
+	if err != nil {
+		return fmt.Sprintf("rollback to pipelinerun %d is requested by pipelinerun %d, failed to get its status: %v",
+			target, resp.PipelinerunID, err)
+	}
+	if pr.Status == string(prmodels.StatusOK) {
+		return fmt.Sprintf("rolled back to pipelinerun %d by pipelinerun %d", target, pr.ID)
+	}
+	return fmt.Sprintf("rollback to pipelinerun %d is requested by pipelinerun %d, which is %s",
+		target, pr.ID, pr.Status)
+}
+
+// rollbackTarget returns the latest rollback-able pipelinerun before the analyzed one
+func (j *Job) rollbackTarget(ctx context.Context, a *canarymodels.CanaryAnalysis) (uint, error) {
+	_, prs, err := j.mgr.PipelinerunMgr.GetByClusterID(ctx, a.ClusterID, true, q.Query{
+		PageNumber: 1,
+		PageSize:   _rollbackCandidates,
+	})
+	if err != nil {
+		return 0, err
+	}
+	for _, pr := range prs {
+		if pr.ID < a.PipelinerunID {
+			return pr.ID, nil
+		}
+	}
+	return 0, nil
+}
+
+func (j *Job) finish(ctx context.Context, a *canarymodels.CanaryAnalysis,
+	status, message string, now time.Time) error {
+	a.Status = status
+	a.Message = message
+	a.FinishedAt = &now
+	return j.mgr.CanaryAnalysisMgr.UpdateByID(ctx, a.ID, a)
+}
+
+// next starts analyzing the given step of the same deployment
+func (j *Job) next(ctx context.Context, a *canarymodels.CanaryAnalysis, step int, now time.Time) error {
+	_, err := j.mgr.CanaryAnalysisMgr.Create(ctx, &canarymodels.CanaryAnalysis{
+		PipelinerunID: a.PipelinerunID,
+		ClusterID:     a.ClusterID,
+		Step:          step,
+		Status:        canarymodels.StatusRunning,
+		StartedAt:     now,
+	})
+	return err
+}
@@ -0,0 +1,252 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package canary
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	clusterctl "github.com/horizoncd/horizon/core/controller/cluster"
+	"github.com/horizoncd/horizon/lib/orm"
+	appmodels "github.com/horizoncd/horizon/pkg/application/models"
+	canarymodels "github.com/horizoncd/horizon/pkg/canary/models"
+	clustermodels "github.com/horizoncd/horizon/pkg/cluster/models"
+	"github.com/horizoncd/horizon/pkg/config/canary"
+	"github.com/horizoncd/horizon/pkg/param/managerparam"
+	prmodels "github.com/horizoncd/horizon/pkg/pipelinerun/models"
+	regionmodels "github.com/horizoncd/horizon/pkg/region/models"
+	registrymodels "github.com/horizoncd/horizon/pkg/registry/models"
+	"github.com/horizoncd/horizon/pkg/server/global"
+	tagmodels "github.com/horizoncd/horizon/pkg/tag/models"
+	"github.com/horizoncd/horizon/pkg/templaterelease/analysis"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+var (
+	db, _   = orm.NewSqliteDB("")
+	manager = managerparam.InitManager(db)
+	ctx     = context.Background()
+)
+
+type fakeGetter struct {
+	spec *analysis.Spec
+}
+
+func (g *fakeGetter) GetTemplateAnalysis(context.Context, string, string) (*analysis.Spec, error) {
+	return g.spec, nil
+}
+
+type fakeQuerier struct {
+	values map[string]float64
+	err    error
+}
+
+func (q *fakeQuerier) Query(_ context.Context, _, query string) (float64, error) {
+	return q.values[query], q.err
+}
+
+type fakeOperator struct {
+	step      *clusterctl.GetStepResponse
+	actions   []string
+	rollbacks []uint
+	// rollbackStatus is the status of the rollback pipelineruns, defaults to ok
+	rollbackStatus prmodels.PipelineStatus
+}
+
+func (o *fakeOperator) GetStep(context.Context, uint) (*clusterctl.GetStepResponse, error) {
+	return o.step, nil
+}
+
+func (o *fakeOperator) ExecuteAction(_ context.Context, _ uint, action string,
+	_ schema.GroupVersionResource) error {
+	o.actions = append(o.actions, action)
+	return nil
+}
+
+func (o *fakeOperator) Rollback(_ context.Context, _ uint,
+	request *clusterctl.RollbackRequest) (*clusterctl.PipelinerunIDResponse, error) {
+	o.rollbacks = append(o.rollbacks, request.PipelinerunID)
+	status := o.rollbackStatus
+	if status == "" {
+		status = prmodels.StatusOK
+	}
+	pr, err := manager.PipelinerunMgr.Create(ctx, &prmodels.Pipelinerun{
+		ClusterID:    1,
+		Action:       prmodels.ActionRollback,
+		Status:       string(status),
+		RollbackFrom: &request.PipelinerunID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &clusterctl.PipelinerunIDResponse{PipelinerunID: pr.ID}, nil
+}
+
+func TestMain(m *testing.M) {
+	if err := db.AutoMigrate(&appmodels.Application{}, &clustermodels.Cluster{},
+		&regionmodels.Region{}, &registrymodels.Registry{}, &tagmodels.Tag{},
+		&prmodels.Pipelinerun{}, &canarymodels.CanaryAnalysis{}); err != nil {
+		panic(err)
+	}
+	db.Create(&registrymodels.Registry{Model: global.Model{ID: 1}, Name: "registry"})
+	db.Create(&regionmodels.Region{Name: "hz", PrometheusURL: "http://prometheus", RegistryID: 1})
+	db.Create(&appmodels.Application{Model: global.Model{ID: 1}, Name: "app"})
+	db.Create(&clustermodels.Cluster{Model: global.Model{ID: 1}, ApplicationID: 1, Name: "app-test",
+		EnvironmentName: "test", RegionName: "hz", Template: "rollout", TemplateRelease: "v1.0.0"})
+	db.Create(&prmodels.Pipelinerun{ID: 1, ClusterID: 1, Action: prmodels.ActionDeploy,
+		Status: string(prmodels.StatusOK), CreatedAt: time.Now().Add(-time.Hour)})
+	db.Create(&prmodels.Pipelinerun{ID: 2, ClusterID: 1, Action: prmodels.ActionDeploy,
+		Status: string(prmodels.StatusOK), CreatedAt: time.Now()})
+	os.Exit(m.Run())
+}
+
+func TestAnalyze(t *testing.T) {
+	spec := &analysis.Spec{
+		Interval: time.Minute,
+		Metrics: []analysis.Metric{{
+			Name:      "error-rate",
+			Query:     `errors{app="{{ .Application }}",region="{{ .Region }}"}`,
+			Condition: analysis.ConditionLessThan,
+			Threshold: 0.01,
+		}},
+	}
+	query := `errors{app="app",region="hz"}`
+	querier := &fakeQuerier{values: map[string]float64{query: 0.001}}
+	operator := &fakeOperator{step: &clusterctl.GetStepResponse{Index: 1, Total: 3}}
+	job := New(&canary.Config{Enabled: true}, manager, operator, &fakeGetter{spec: spec}, querier)
+	mgr := manager.CanaryAnalysisMgr
+
+	now := time.Now()
+	a, err := mgr.Create(ctx, &canarymodels.CanaryAnalysis{
+		PipelinerunID: 2,
+		ClusterID:     1,
+		Status:        canarymodels.StatusRunning,
+		StartedAt:     now,
+	})
+	assert.Nil(t, err)
+
+	// 1. the analysis is bound to the current step
+	assert.Nil(t, job.analyze(ctx, a, now))
+	assert.Equal(t, 1, a.Step)
+
+	// 2. healthy but not observed long enough
+	now = now.Add(30 * time.Second)
+	assert.Nil(t, job.analyze(ctx, a, now))
+	assert.Equal(t, canarymodels.StatusRunning, a.Status)
+	assert.Equal(t, query, a.MetricResults()[0].Query)
+	assert.Equal(t, 0, len(operator.actions))
+
+	// 3. metrics can not be evaluated, the step is not promoted
+	now = now.Add(time.Minute)
+	querier.err = errors.New("timeout")
+	assert.Nil(t, job.analyze(ctx, a, now))
+	assert.Equal(t, canarymodels.StatusRunning, a.Status)
+	assert.Equal(t, "timeout", a.MetricResults()[0].Error)
+
+	// 4. healthy for the interval, the step is promoted and the next one is analyzed
+	querier.err = nil
+	assert.Nil(t, job.analyze(ctx, a, now))
+	assert.Equal(t, canarymodels.StatusPassed, a.Status)
+	assert.Equal(t, []string{_actionPromote}, operator.actions)
+	running, err := mgr.ListRunning(ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(running))
+	assert.Equal(t, 2, running[0].Step)
+
+	// 5. the metric breaches, the rollout is aborted and rolled back
+	operator.step.Index = 2
+	querier.values[query] = 0.5
+	a = running[0]
+	assert.Nil(t, job.analyze(ctx, a, now))
+	assert.Equal(t, canarymodels.StatusFailed, a.Status)
+	assert.Equal(t, []string{_actionPromote, _actionAbort}, operator.actions)
+	assert.Equal(t, []uint{1}, operator.rollbacks)
+	assert.Contains(t, a.Message, "rolled back to pipelinerun 1")
+	running, err = mgr.ListRunning(ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(running))
+
+	analyses, err := mgr.ListByPipelinerunID(ctx, 2)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(analyses))
+}
+
+func TestAnalyzeFinished(t *testing.T) {
+	spec := &analysis.Spec{Interval: time.Minute, Metrics: []analysis.Metric{{Name: "a", Query: "up"}}}
+	operator := &fakeOperator{step: &clusterctl.GetStepResponse{Index: 3, Total: 3}}
+	job := New(&canary.Config{Enabled: true}, manager, operator, &fakeGetter{spec: spec}, &fakeQuerier{})
+
+	now := time.Now()
+	a, err := manager.CanaryAnalysisMgr.Create(ctx, &canarymodels.CanaryAnalysis{
+		PipelinerunID: 3,
+		ClusterID:     1,
+		Status:        canarymodels.StatusRunning,
+		StartedAt:     now,
+	})
+	assert.Nil(t, err)
+	assert.Nil(t, job.analyze(ctx, a, now))
+	assert.Equal(t, canarymodels.StatusRunning, a.Status)
+	assert.Nil(t, job.analyze(ctx, a, now.Add(time.Minute)))
+	assert.Equal(t, canarymodels.StatusPassed, a.Status)
+
+	// the cluster has been deleted
+	a, err = manager.CanaryAnalysisMgr.Create(ctx, &canarymodels.CanaryAnalysis{
+		PipelinerunID: 4,
+		ClusterID:     100,
+		Status:        canarymodels.StatusRunning,
+		StartedAt:     now,
+	})
+	assert.Nil(t, err)
+	assert.Nil(t, job.analyze(ctx, a, now))
+	assert.Equal(t, canarymodels.StatusCanceled, a.Status)
+	assert.Equal(t, 0, len(operator.actions))
+}
+
+func TestAnalyzeRollbackPending(t *testing.T) {
+	spec := &analysis.Spec{Interval: time.Minute, Metrics: []analysis.Metric{{
+		Name:      "latency",
+		Query:     "latency",
+		Condition: analysis.ConditionLessThan,
+		Threshold: 1,
+	}}}
+	querier := &fakeQuerier{values: map[string]float64{"latency": 2}}
+	operator := &fakeOperator{
+		step:           &clusterctl.GetStepResponse{Index: 1, Total: 3},
+		rollbackStatus: prmodels.StatusPending,
+	}
+	job := New(&canary.Config{Enabled: true}, manager, operator, &fakeGetter{spec: spec}, querier)
+
+	now := time.Now()
+	a, err := manager.CanaryAnalysisMgr.Create(ctx, &canarymodels.CanaryAnalysis{
+		PipelinerunID: 2,
+		ClusterID:     1,
+		Step:          1,
+		Status:        canarymodels.StatusRunning,
+		StartedAt:     now,
+	})
+	assert.Nil(t, err)
+
+	// the rollback is pending for approval, it's not reported as rolled back
+	assert.Nil(t, job.analyze(ctx, a, now))
+	assert.Equal(t, canarymodels.StatusFailed, a.Status)
+	assert.Equal(t, []uint{1}, operator.rollbacks)
+	assert.NotContains(t, a.Message, "rolled back")
+	assert.Contains(t, a.Message, "rollback to pipelinerun 1 is requested")
+	assert.Contains(t, a.Message, "which is pending")
+}
@@ -49,6 +49,8 @@ func (c *Cleaner) Run(ctx context.Context) {
 		log.Debugf(ctx, "start to clean")
 		c.webhookLogClean(ctx)
 		c.eventClean(ctx)
+		c.auditLogClean(ctx)
+		c.webhookSecretClean(ctx)
 	})
 	if err != nil {
 		panic(err)
@@ -207,3 +209,28 @@ func (c *Cleaner) eventClean(ctx context.Context) {
 		_, _ = c.mgr.EventManager.DeleteEvents(ctx, needDeleted...)
 	}
 }
+
+func (c *Cleaner) auditLogClean(ctx context.Context) {
+	if c.AuditLogTTL <= 0 {
+		return
+	}
+	log.Debugf(ctx, "start to clean audit logs")
+	deleted, err := c.mgr.AuditLogMgr.DeleteBefore(ctx, time.Now().Add(-c.AuditLogTTL))
+	if err != nil {
+		log.Errorf(ctx, "failed to clean audit logs: %v", err)
+		return
+	}
+	log.Infof(ctx, "deleted %d audit logs", deleted)
+}
+
+// webhookSecretClean clears the secrets of webhooks before rotation once they expire,
+// so that they are not kept in db after they stop signing requests
+func (c *Cleaner) webhookSecretClean(ctx context.Context) {
+	log.Debugf(ctx, "start to clean expired webhook secrets")
+	cleared, err := c.mgr.WebhookManager.ClearExpiredPreviousSecrets(ctx, time.Now())
+	if err != nil {
+		log.Errorf(ctx, "failed to clean expired webhook secrets: %v", err)
+		return
+	}
+	log.Infof(ctx, "cleared expired previous secrets of %d webhooks", cleared)
+}
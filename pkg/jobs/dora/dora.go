@@ -0,0 +1,92 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dora
+
+import (
+	"context"
+	"time"
+
+	"github.com/horizoncd/horizon/core/middleware/requestid"
+	"github.com/horizoncd/horizon/pkg/cluster/tekton/metrics"
+	"github.com/horizoncd/horizon/pkg/config/dora"
+	"github.com/horizoncd/horizon/pkg/dora/models"
+	"github.com/horizoncd/horizon/pkg/param/managerparam"
+	"github.com/horizoncd/horizon/pkg/util/log"
+	uuid "github.com/satori/go.uuid"
+)
+
+// Job exports the DORA metrics of the deployments in the last window by application and environment
+type Job struct {
+	config *dora.Config
+	mgr    *managerparam.Manager
+}
+
+func New(config *dora.Config, mgr *managerparam.Manager) *Job {
+	return &Job{
+		config: config,
+		mgr:    mgr,
+	}
+}
+
+func (j *Job) Run(ctx context.Context) {
+	log.Infof(ctx, "Starting exporting DORA metrics every %v", j.config.JobInterval)
+	defer log.Infof(ctx, "Stopping exporting DORA metrics")
+	ticker := time.NewTicker(j.config.JobInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			rid := uuid.NewV4().String()
+			// nolint
+			ctx := context.WithValue(ctx, requestid.HeaderXRequestID, rid)
+			j.process(ctx, time.Now())
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (j *Job) process(ctx context.Context, now time.Time) {
+	window := models.Window{Start: now.Add(-j.config.Window), End: now}
+	deployments, err := j.mgr.DORAMgr.ListDeployments(ctx, &models.Query{Start: window.Start, End: window.End})
+	if err != nil {
+		log.Errorf(ctx, "failed to list deployments, err: %v", err)
+		return
+	}
+
+	type key struct {
+		application string
+		environment string
+	}
+	var keys []key
+	deploymentsByKey := make(map[key][]*models.Deployment)
+	for _, d := range deployments {
+		k := key{application: d.Application, environment: d.Environment}
+		if _, ok := deploymentsByKey[k]; !ok {
+			keys = append(keys, k)
+		}
+		deploymentsByKey[k] = append(deploymentsByKey[k], d)
+	}
+
+	doraMetrics := make([]*metrics.DORAMetrics, 0, len(keys))
+	for _, k := range keys {
+		doraMetrics = append(doraMetrics, &metrics.DORAMetrics{
+			Application: k.application,
+			Environment: k.environment,
+			Metrics:     models.Compute(deploymentsByKey[k], window)[0],
+		})
+	}
+	metrics.ObserveDORA(doraMetrics)
+}
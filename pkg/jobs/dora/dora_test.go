@@ -0,0 +1,88 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dora
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/horizoncd/horizon/lib/orm"
+	appmodels "github.com/horizoncd/horizon/pkg/application/models"
+	clustermodels "github.com/horizoncd/horizon/pkg/cluster/models"
+	"github.com/horizoncd/horizon/pkg/config/dora"
+	"github.com/horizoncd/horizon/pkg/param/managerparam"
+	prmodels "github.com/horizoncd/horizon/pkg/pipelinerun/models"
+	"github.com/horizoncd/horizon/pkg/server/global"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+)
+
+// gauge gathers the value of the gauge of the application in the environment
+func gauge(t *testing.T, name, application, environment string) (float64, bool) {
+	families, err := prometheus.DefaultGatherer.Gather()
+	assert.Nil(t, err)
+	for _, family := range families {
+		if family.GetName() != name {
+			continue
+		}
+		for _, m := range family.GetMetric() {
+			labels := make(map[string]string)
+			for _, label := range m.GetLabel() {
+				labels[label.GetName()] = label.GetValue()
+			}
+			if labels["application"] == application && labels["environment"] == environment {
+				return m.GetGauge().GetValue(), true
+			}
+		}
+	}
+	return 0, false
+}
+
+func TestProcess(t *testing.T) {
+	db, _ := orm.NewSqliteDB("")
+	if err := db.AutoMigrate(&appmodels.Application{}, &clustermodels.Cluster{},
+		&prmodels.Pipelinerun{}); err != nil {
+		panic(err)
+	}
+	now := time.Now().UTC()
+	ago := func(hours int) *time.Time {
+		t := now.Add(-time.Duration(hours) * time.Hour)
+		return &t
+	}
+	db.Create(&appmodels.Application{Model: global.Model{ID: 1}, GroupID: 1, Name: "dora-app"})
+	db.Create([]*clustermodels.Cluster{
+		{Model: global.Model{ID: 1}, ApplicationID: 1, Name: "dora-app-online", EnvironmentName: "online"},
+		{Model: global.Model{ID: 2}, ApplicationID: 1, Name: "dora-app-test", EnvironmentName: "test"},
+	})
+	db.Create([]*prmodels.Pipelinerun{
+		{ClusterID: 1, Action: prmodels.ActionBuildDeploy, Status: string(prmodels.StatusOK),
+			GitCommitTime: ago(3), FinishedAt: ago(2)},
+		{ClusterID: 1, Action: prmodels.ActionDeploy, Status: string(prmodels.StatusOK), FinishedAt: ago(1)},
+		// out of the window
+		{ClusterID: 2, Action: prmodels.ActionDeploy, Status: string(prmodels.StatusOK), FinishedAt: ago(48)},
+	})
+
+	job := New(&dora.Config{JobInterval: time.Minute, Window: 24 * time.Hour}, managerparam.InitManager(db))
+	job.process(context.Background(), now)
+
+	deployments, ok := gauge(t, "horizon_dora_deployments", "dora-app", "online")
+	assert.True(t, ok)
+	assert.Equal(t, 2.0, deployments)
+	leadTime, _ := gauge(t, "horizon_dora_lead_time_seconds", "dora-app", "online")
+	assert.Equal(t, time.Hour.Seconds(), leadTime)
+	_, ok = gauge(t, "horizon_dora_deployments", "dora-app", "test")
+	assert.False(t, ok)
+}
@@ -0,0 +1,236 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package drift
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sort"
+	"time"
+
+	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/core/middleware/requestid"
+	"github.com/horizoncd/horizon/lib/q"
+	userauth "github.com/horizoncd/horizon/pkg/authentication/user"
+	"github.com/horizoncd/horizon/pkg/cd"
+	cmodels "github.com/horizoncd/horizon/pkg/cluster/models"
+	"github.com/horizoncd/horizon/pkg/config/drift"
+	"github.com/horizoncd/horizon/pkg/drift/models"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	eventmodels "github.com/horizoncd/horizon/pkg/event/models"
+	"github.com/horizoncd/horizon/pkg/param/managerparam"
+	"github.com/horizoncd/horizon/pkg/util/log"
+	uuid "github.com/satori/go.uuid"
+)
+
+// _pageSize is how many clusters are listed at a time
+const _pageSize = 50
+
+// Job detects clusters whose live resources have drifted from the desired state in gitops repo,
+// records a drift report for each of them and emits an event when a new drift is found
+type Job struct {
+	config *drift.Config
+	mgr    *managerparam.Manager
+	cd     cd.CD
+}
+
+func New(config *drift.Config, mgr *managerparam.Manager, cd cd.CD) *Job {
+	return &Job{
+		config: config,
+		mgr:    mgr,
+		cd:     cd,
+	}
+}
+
+func (j *Job) Run(ctx context.Context) {
+	if !j.config.Enabled {
+		return
+	}
+
+	// verify account
+	user, err := j.mgr.UserManager.GetUserByID(ctx, j.config.AccountID)
+	if err != nil {
+		log.Errorf(ctx, "failed to verify operator of drift detection, err: %v", err.Error())
+		return
+	}
+	ctx = common.WithContext(ctx, &userauth.DefaultInfo{
+		Name:     user.Name,
+		FullName: user.FullName,
+		ID:       user.ID,
+		Email:    user.Email,
+		Admin:    user.Admin,
+	})
+
+	log.Infof(ctx, "Starting detecting drift of clusters every %v", j.config.JobInterval)
+	defer log.Infof(ctx, "Stopping detecting drift of clusters")
+	ticker := time.NewTicker(j.config.JobInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			rid := uuid.NewV4().String()
+			// nolint
+			ctx = context.WithValue(ctx, requestid.HeaderXRequestID, rid)
+			j.process(ctx)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (j *Job) process(ctx context.Context) {
+	query := q.New(nil)
+	query.PageSize = _pageSize
+	for query.PageNumber = 1; ; query.PageNumber++ {
+		total, clusters, err := j.mgr.ClusterMgr.List(ctx, query)
+		if err != nil {
+			log.Errorf(ctx, "failed to list clusters, err: %v", err)
+			return
+		}
+		for _, cluster := range clusters {
+			if cluster.Status != common.ClusterStatusEmpty {
+				continue
+			}
+			if err := j.detect(ctx, cluster.Cluster, time.Now()); err != nil {
+				log.Errorf(ctx, "failed to detect drift of cluster %d, err: %v", cluster.ID, err)
+			}
+		}
+		if len(clusters) == 0 || query.PageNumber*query.PageSize >= total {
+			return
+		}
+	}
+}
+
+func (j *Job) detect(ctx context.Context, cluster *cmodels.Cluster, now time.Time) error {
+	application, err := j.mgr.ApplicationManager.GetByID(ctx, cluster.ApplicationID)
+	if err != nil {
+		return err
+	}
+	regionEntity, err := j.mgr.RegionMgr.GetRegionEntity(ctx, cluster.RegionName)
+	if err != nil {
+		return err
+	}
+
+	clusterDrift, err := j.cd.GetClusterDrift(ctx, &cd.GetClusterDriftParams{
+		Application:  application.Name,
+		Environment:  cluster.EnvironmentName,
+		Cluster:      cluster.Name,
+		RegionEntity: regionEntity,
+	})
+	if err != nil {
+		// the cluster has not been deployed yet
+		if _, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); ok {
+			return nil
+		}
+		return err
+	}
+	if clusterDrift.Reconciling {
+		return nil
+	}
+
+	report, err := j.mgr.DriftReportMgr.GetByClusterID(ctx, cluster.ID)
+	if err != nil {
+		if _, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); !ok {
+			return err
+		}
+		report = nil
+	}
+
+	resources := clusterDrift.Resources
+	if len(resources) == 0 {
+		if report == nil || report.Status == models.StatusResolved {
+			return nil
+		}
+		report.Status = models.StatusResolved
+		report.Revision = clusterDrift.Revision
+		report.ManifestVersion = clusterDrift.ManifestVersion
+		report.SetResources(nil)
+		report.Fingerprint = ""
+		report.CheckedAt = now
+		return j.mgr.DriftReportMgr.UpdateByID(ctx, report.ID, report)
+	}
+
+	sort.Slice(resources, func(a, b int) bool {
+		return resourceKey(resources[a]) < resourceKey(resources[b])
+	})
+	fingerprint := fingerprintOf(resources)
+	// the same drift has been reported, or accepted
+	if report != nil && report.Status != models.StatusResolved && report.Fingerprint == fingerprint {
+		report.Revision = clusterDrift.Revision
+		report.ManifestVersion = clusterDrift.ManifestVersion
+		report.CheckedAt = now
+		return j.mgr.DriftReportMgr.UpdateCheckedByID(ctx, report.ID, report)
+	}
+
+	if report == nil {
+		report = &models.DriftReport{ClusterID: cluster.ID}
+	}
+	report.Status = models.StatusDrifted
+	report.Revision = clusterDrift.Revision
+	report.ManifestVersion = clusterDrift.ManifestVersion
+	report.SetResources(resources)
+	report.Fingerprint = fingerprint
+	report.DetectedAt = &now
+	report.CheckedAt = now
+	report.AcceptedAt = nil
+	report.AcceptedBy = 0
+	if report.ID == 0 {
+		if _, err := j.mgr.DriftReportMgr.Create(ctx, report); err != nil {
+			return err
+		}
+	} else if err := j.mgr.DriftReportMgr.UpdateByID(ctx, report.ID, report); err != nil {
+		return err
+	}
+	j.recordEvent(ctx, cluster, report, resources)
+	return nil
+}
+
+func (j *Job) recordEvent(ctx context.Context, cluster *cmodels.Cluster,
+	report *models.DriftReport, resources []models.Resource) {
+	bts, err := json.Marshal(map[string]interface{}{
+		"revision":        report.Revision,
+		"manifestVersion": report.ManifestVersion,
+		"resources":       resources,
+	})
+	if err != nil {
+		log.Warningf(ctx, "failed to marshal event extra, err: %s", err.Error())
+	}
+	extra := string(bts)
+	if _, err := j.mgr.EventManager.CreateEvent(ctx, &eventmodels.Event{
+		EventSummary: eventmodels.EventSummary{
+			ResourceType: common.ResourceCluster,
+			EventType:    eventmodels.ClusterDrifted,
+			ResourceID:   cluster.ID,
+			Extra:        &extra,
+		},
+	}); err != nil {
+		log.Warningf(ctx, "failed to create event, err: %s", err.Error())
+	}
+}
+
+func resourceKey(r models.Resource) string {
+	return r.Group + "/" + r.Kind + "/" + r.Namespace + "/" + r.Name
+}
+
+// fingerprintOf identifies drifted resources and fields, regardless of the desired revision,
+// so that an accepted drift is not reported again after an unrelated deployment
+func fingerprintOf(resources []models.Resource) string {
+	bts, _ := json.Marshal(resources)
+	sum := sha256.Sum256(bts)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,140 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package drift
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/horizoncd/horizon/core/common"
+	"github.com/horizoncd/horizon/lib/orm"
+	cdmock "github.com/horizoncd/horizon/mock/pkg/cd"
+	appmodels "github.com/horizoncd/horizon/pkg/application/models"
+	userauth "github.com/horizoncd/horizon/pkg/authentication/user"
+	"github.com/horizoncd/horizon/pkg/cd"
+	clustermodels "github.com/horizoncd/horizon/pkg/cluster/models"
+	"github.com/horizoncd/horizon/pkg/config/drift"
+	"github.com/horizoncd/horizon/pkg/drift/models"
+	eventmodels "github.com/horizoncd/horizon/pkg/event/models"
+	"github.com/horizoncd/horizon/pkg/param/managerparam"
+	regionmodels "github.com/horizoncd/horizon/pkg/region/models"
+	registrymodels "github.com/horizoncd/horizon/pkg/registry/models"
+	"github.com/horizoncd/horizon/pkg/server/global"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	db, _   = orm.NewSqliteDB("")
+	manager = managerparam.InitManager(db)
+	// nolint
+	ctx = common.WithContext(context.Background(), &userauth.DefaultInfo{Name: "Tony", ID: 1})
+)
+
+func TestMain(m *testing.M) {
+	if err := db.AutoMigrate(&appmodels.Application{}, &clustermodels.Cluster{},
+		&regionmodels.Region{}, &registrymodels.Registry{}, &eventmodels.Event{},
+		&models.DriftReport{}); err != nil {
+		panic(err)
+	}
+	db.Create(&registrymodels.Registry{Model: global.Model{ID: 1}, Name: "registry"})
+	db.Create(&appmodels.Application{Model: global.Model{ID: 1}, Name: "app"})
+	db.Create(&regionmodels.Region{Name: "hz", RegistryID: 1})
+	db.Create(&clustermodels.Cluster{Model: global.Model{ID: 1}, ApplicationID: 1,
+		Name: "app-hz", EnvironmentName: "online", RegionName: "hz"})
+	os.Exit(m.Run())
+}
+
+func countEvents(t *testing.T) int64 {
+	var count int64
+	assert.Nil(t, db.Model(&eventmodels.Event{}).
+		Where("event_type = ?", eventmodels.ClusterDrifted).Count(&count).Error)
+	return count
+}
+
+func TestDetect(t *testing.T) {
+	mockCtl := gomock.NewController(t)
+	cdMock := cdmock.NewMockCD(mockCtl)
+	clusterDrift := &cd.ClusterDrift{}
+	cdMock.EXPECT().GetClusterDrift(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, params *cd.GetClusterDriftParams) (*cd.ClusterDrift, error) {
+			assert.Equal(t, "app", params.Application)
+			assert.Equal(t, "app-hz", params.Cluster)
+			return clusterDrift, nil
+		}).AnyTimes()
+
+	job := New(&drift.Config{JobInterval: time.Minute}, manager, cdMock)
+
+	// nothing is recorded for a cluster which has never drifted
+	job.process(ctx)
+	_, err := manager.DriftReportMgr.GetByClusterID(ctx, 1)
+	assert.NotNil(t, err)
+
+	deployment := models.Resource{Group: "apps", Version: "v1", Kind: "Deployment",
+		Namespace: "ns", Name: "app-hz", FieldPaths: []string{"spec.replicas"}}
+	service := models.Resource{Version: "v1", Kind: "Service", Namespace: "ns", Name: "app-hz", Missing: true}
+	clusterDrift.Revision = "r1"
+	clusterDrift.Resources = []models.Resource{deployment, service}
+	job.process(ctx)
+	report, err := manager.DriftReportMgr.GetByClusterID(ctx, 1)
+	assert.Nil(t, err)
+	assert.Equal(t, models.StatusDrifted, report.Status)
+	assert.Equal(t, "r1", report.Revision)
+	assert.Equal(t, []models.Resource{service, deployment}, report.GetResources())
+	assert.NotNil(t, report.DetectedAt)
+	assert.Equal(t, int64(1), countEvents(t))
+
+	// the same drift is not reported twice, and stays accepted after a new revision is synced
+	ok, err := manager.DriftReportMgr.UpdateStatusByClusterID(ctx, 1, []string{models.StatusDrifted},
+		&models.DriftReport{Status: models.StatusAccepted, AcceptedBy: 1})
+	assert.Nil(t, err)
+	assert.True(t, ok)
+	clusterDrift.Revision = "r2"
+	clusterDrift.Resources = []models.Resource{service, deployment}
+	job.process(ctx)
+	report, err = manager.DriftReportMgr.GetByClusterID(ctx, 1)
+	assert.Nil(t, err)
+	assert.Equal(t, models.StatusAccepted, report.Status)
+	assert.Equal(t, "r2", report.Revision)
+	assert.Equal(t, int64(1), countEvents(t))
+
+	// clusters being synced are skipped
+	clusterDrift.Reconciling = true
+	clusterDrift.Resources = nil
+	job.process(ctx)
+	report, err = manager.DriftReportMgr.GetByClusterID(ctx, 1)
+	assert.Nil(t, err)
+	assert.Equal(t, models.StatusAccepted, report.Status)
+	clusterDrift.Reconciling = false
+
+	// a different drift is reported again
+	clusterDrift.Resources = []models.Resource{deployment}
+	job.process(ctx)
+	report, err = manager.DriftReportMgr.GetByClusterID(ctx, 1)
+	assert.Nil(t, err)
+	assert.Equal(t, models.StatusDrifted, report.Status)
+	assert.Equal(t, uint(0), report.AcceptedBy)
+	assert.Equal(t, int64(2), countEvents(t))
+
+	clusterDrift.Resources = nil
+	job.process(ctx)
+	report, err = manager.DriftReportMgr.GetByClusterID(ctx, 1)
+	assert.Nil(t, err)
+	assert.Equal(t, models.StatusResolved, report.Status)
+	assert.Empty(t, report.GetResources())
+	assert.Equal(t, int64(2), countEvents(t))
+}
@@ -0,0 +1,296 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package releaseplan
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/argoproj/gitops-engine/pkg/health"
+	"github.com/horizoncd/horizon/core/common"
+	clusterctl "github.com/horizoncd/horizon/core/controller/cluster"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/core/middleware/requestid"
+	userauth "github.com/horizoncd/horizon/pkg/authentication/user"
+	"github.com/horizoncd/horizon/pkg/cd"
+	"github.com/horizoncd/horizon/pkg/config/releaseplan"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	freezeservice "github.com/horizoncd/horizon/pkg/freeze/service"
+	"github.com/horizoncd/horizon/pkg/param/managerparam"
+	prmodels "github.com/horizoncd/horizon/pkg/pipelinerun/models"
+	"github.com/horizoncd/horizon/pkg/releaseplan/models"
+	"github.com/horizoncd/horizon/pkg/util/log"
+	uuid "github.com/satori/go.uuid"
+)
+
+// ClusterOperator is the part of the cluster controller used by the job
+type ClusterOperator interface {
+	Deploy(ctx context.Context, clusterID uint,
+		request *clusterctl.DeployRequest) (*clusterctl.PipelinerunIDResponse, error)
+}
+
+// Job pushes running release plans forward: it deploys the clusters of the current wave,
+// waits for them to be healthy, and moves on to the next wave
+type Job struct {
+	config    *releaseplan.Config
+	mgr       *managerparam.Manager
+	operator  ClusterOperator
+	cd        cd.CD
+	freezeSvc freezeservice.Service
+}
+
+func New(config *releaseplan.Config, mgr *managerparam.Manager, operator ClusterOperator,
+	cd cd.CD, freezeSvc freezeservice.Service) *Job {
+	return &Job{
+		config:    config,
+		mgr:       mgr,
+		operator:  operator,
+		cd:        cd,
+		freezeSvc: freezeSvc,
+	}
+}
+
+func (j *Job) Run(ctx context.Context) {
+	log.Infof(ctx, "Starting releasing release plans every %v", j.config.JobInterval)
+	defer log.Infof(ctx, "Stopping releasing release plans")
+	ticker := time.NewTicker(j.config.JobInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			rid := uuid.NewV4().String()
+			// nolint
+			ctx := context.WithValue(ctx, requestid.HeaderXRequestID, rid)
+			j.process(ctx)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (j *Job) process(ctx context.Context) {
+	plans, err := j.mgr.ReleasePlanMgr.ListRunning(ctx)
+	if err != nil {
+		log.Errorf(ctx, "failed to list running release plans, err: %v", err)
+		return
+	}
+	for _, plan := range plans {
+		// clusters are deployed on behalf of whom started or resumed the plan
+		user, err := j.mgr.UserManager.GetUserByID(ctx, plan.UpdatedBy)
+		if err != nil {
+			log.Errorf(ctx, "failed to get operator of release plan %d, err: %v", plan.ID, err)
+			continue
+		}
+		userCtx := common.WithContext(ctx, &userauth.DefaultInfo{
+			Name:     user.Name,
+			FullName: user.FullName,
+			ID:       user.ID,
+			Email:    user.Email,
+			Admin:    user.Admin,
+		})
+		if err := j.release(userCtx, plan, time.Now()); err != nil {
+			log.Errorf(ctx, "failed to release release plan %d, err: %v", plan.ID, err)
+		}
+	}
+}
+
+// release pushes the plan forward by one step
+func (j *Job) release(ctx context.Context, plan *models.ReleasePlan, now time.Time) error {
+	waves := plan.GetWaves()
+	if plan.CurrentWave >= len(waves) {
+		return j.finish(ctx, plan, models.StatusSucceeded, "all waves are released")
+	}
+	wave := waves[plan.CurrentWave]
+
+	clusters, err := j.mgr.ReleasePlanMgr.ListClustersByPlanID(ctx, plan.ID)
+	if err != nil {
+		return err
+	}
+	waveHealthy := true
+	for _, cluster := range clusters {
+		if cluster.Wave != plan.CurrentWave {
+			continue
+		}
+		switch cluster.Status {
+		case models.ClusterStatusPending:
+			waveHealthy = false
+			if err := j.deploy(ctx, plan, cluster, now); err != nil {
+				return err
+			}
+		case models.ClusterStatusDeploying:
+			waveHealthy = false
+			if err := j.check(ctx, cluster, now); err != nil {
+				return err
+			}
+		case models.ClusterStatusHealthy:
+			continue
+		}
+		if cluster.Status == models.ClusterStatusFailed {
+			return j.finish(ctx, plan, models.StatusFailed,
+				fmt.Sprintf("wave %s halted, cluster %d failed: %s", wave.Name, cluster.ClusterID, cluster.Message))
+		}
+	}
+	if !waveHealthy {
+		return nil
+	}
+
+	// observe the healthy wave for a while before releasing the next one
+	if wave.Pause != "" {
+		pause, err := time.ParseDuration(wave.Pause)
+		if err != nil {
+			return j.finish(ctx, plan, models.StatusFailed, fmt.Sprintf("invalid pause of wave %s", wave.Name))
+		}
+		if plan.WaveFinishedAt == nil {
+			plan.WaveFinishedAt = &now
+			plan.Message = fmt.Sprintf("wave %s is healthy, observing for %s", wave.Name, wave.Pause)
+			_, err := j.mgr.ReleasePlanMgr.UpdateProgressByID(ctx, plan.ID, []string{models.StatusRunning}, plan)
+			return err
+		}
+		if now.Sub(*plan.WaveFinishedAt) < pause {
+			return nil
+		}
+	}
+
+	if plan.CurrentWave == len(waves)-1 {
+		return j.finish(ctx, plan, models.StatusSucceeded, "all waves are released")
+	}
+	plan.CurrentWave++
+	plan.WaveFinishedAt = nil
+	plan.Message = fmt.Sprintf("wave %s is released", wave.Name)
+	if wave.ManualResume {
+		plan.Status = models.StatusPaused
+		plan.Message = fmt.Sprintf("wave %s is released, waiting to be resumed", wave.Name)
+	}
+	_, err = j.mgr.ReleasePlanMgr.UpdateProgressByID(ctx, plan.ID, []string{models.StatusRunning}, plan)
+	return err
+}
+
+// deploy deploys the cluster unless it is frozen now
+func (j *Job) deploy(ctx context.Context, plan *models.ReleasePlan,
+	cluster *models.ReleasePlanCluster, now time.Time) error {
+	if j.freezeSvc != nil {
+		window, until, err := j.freezeSvc.GetActiveWindow(ctx, cluster.ClusterID)
+		if err != nil {
+			return err
+		}
+		if window != nil {
+			cluster.Message = fmt.Sprintf("frozen by %s until %s", window.Name, until.Format(time.RFC3339))
+			return j.mgr.ReleasePlanMgr.UpdateClusterByID(ctx, cluster.ID, cluster)
+		}
+	}
+
+	resp, err := j.operator.Deploy(ctx, cluster.ClusterID, &clusterctl.DeployRequest{
+		Title:       plan.Title,
+		Description: fmt.Sprintf("release plan %d: %s", plan.ID, plan.Description),
+		ImageTag:    plan.ImageTag,
+	})
+	if err != nil {
+		cluster.Status = models.ClusterStatusFailed
+		cluster.Message = fmt.Sprintf("failed to deploy: %v", err)
+	} else {
+		cluster.Status = models.ClusterStatusDeploying
+		cluster.PipelinerunID = resp.PipelinerunID
+		cluster.Message = ""
+		cluster.DeployedAt = &now
+	}
+	return j.mgr.ReleasePlanMgr.UpdateClusterByID(ctx, cluster.ID, cluster)
+}
+
+// check updates the status of the deploying cluster by its pipelinerun and its state in CD
+func (j *Job) check(ctx context.Context, cluster *models.ReleasePlanCluster, now time.Time) error {
+	pr, err := j.mgr.PipelinerunMgr.GetByID(ctx, cluster.PipelinerunID)
+	if err != nil {
+		return err
+	}
+	switch prmodels.PipelineStatus(pr.Status) {
+	case prmodels.StatusOK:
+	case prmodels.StatusFailed, prmodels.StatusCancelled, prmodels.StatusRejected:
+		cluster.Status = models.ClusterStatusFailed
+		cluster.Message = fmt.Sprintf("pipelinerun %d is %s", pr.ID, pr.Status)
+		return j.mgr.ReleasePlanMgr.UpdateClusterByID(ctx, cluster.ID, cluster)
+	case prmodels.StatusPending, prmodels.StatusQueued:
+		// waiting for approval or in the run queue does not count towards the health timeout
+		return nil
+	default:
+		return j.checkTimeout(ctx, cluster, cluster.DeployedAt, now)
+	}
+
+	state, err := j.getClusterState(ctx, cluster.ClusterID)
+	if err != nil {
+		return err
+	}
+	switch state {
+	case string(health.HealthStatusHealthy):
+		cluster.Status = models.ClusterStatusHealthy
+		cluster.Message = ""
+		return j.mgr.ReleasePlanMgr.UpdateClusterByID(ctx, cluster.ID, cluster)
+	case string(health.HealthStatusDegraded):
+		cluster.Status = models.ClusterStatusFailed
+		cluster.Message = "cluster is degraded"
+		return j.mgr.ReleasePlanMgr.UpdateClusterByID(ctx, cluster.ID, cluster)
+	}
+	deployedAt := cluster.DeployedAt
+	if pr.FinishedAt != nil {
+		deployedAt = pr.FinishedAt
+	}
+	return j.checkTimeout(ctx, cluster, deployedAt, now)
+}
+
+func (j *Job) checkTimeout(ctx context.Context, cluster *models.ReleasePlanCluster,
+	since *time.Time, now time.Time) error {
+	if since == nil || now.Sub(*since) < j.config.HealthTimeout {
+		return nil
+	}
+	cluster.Status = models.ClusterStatusFailed
+	cluster.Message = fmt.Sprintf("cluster is not healthy within %v", j.config.HealthTimeout)
+	return j.mgr.ReleasePlanMgr.UpdateClusterByID(ctx, cluster.ID, cluster)
+}
+
+func (j *Job) getClusterState(ctx context.Context, clusterID uint) (string, error) {
+	cluster, err := j.mgr.ClusterMgr.GetByID(ctx, clusterID)
+	if err != nil {
+		return "", err
+	}
+	application, err := j.mgr.ApplicationManager.GetByID(ctx, cluster.ApplicationID)
+	if err != nil {
+		return "", err
+	}
+	regionEntity, err := j.mgr.RegionMgr.GetRegionEntity(ctx, cluster.RegionName)
+	if err != nil {
+		return "", err
+	}
+	state, err := j.cd.GetClusterState(ctx, &cd.GetClusterStateV2Params{
+		Application:  application.Name,
+		Environment:  cluster.EnvironmentName,
+		Cluster:      cluster.Name,
+		RegionEntity: regionEntity,
+	})
+	if err != nil {
+		// the application may not be synced to argo yet
+		if _, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); ok {
+			return string(health.HealthStatusProgressing), nil
+		}
+		return "", err
+	}
+	return state.Status, nil
+}
+
+func (j *Job) finish(ctx context.Context, plan *models.ReleasePlan, status, message string) error {
+	plan.Status = status
+	plan.Message = message
+	_, err := j.mgr.ReleasePlanMgr.UpdateProgressByID(ctx, plan.ID, []string{models.StatusRunning}, plan)
+	return err
+}
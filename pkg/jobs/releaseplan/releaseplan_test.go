@@ -0,0 +1,189 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package releaseplan
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/horizoncd/horizon/core/common"
+	clusterctl "github.com/horizoncd/horizon/core/controller/cluster"
+	"github.com/horizoncd/horizon/lib/orm"
+	cdmock "github.com/horizoncd/horizon/mock/pkg/cd"
+	appmodels "github.com/horizoncd/horizon/pkg/application/models"
+	userauth "github.com/horizoncd/horizon/pkg/authentication/user"
+	"github.com/horizoncd/horizon/pkg/cd"
+	clustermodels "github.com/horizoncd/horizon/pkg/cluster/models"
+	"github.com/horizoncd/horizon/pkg/config/releaseplan"
+	"github.com/horizoncd/horizon/pkg/param/managerparam"
+	prmodels "github.com/horizoncd/horizon/pkg/pipelinerun/models"
+	regionmodels "github.com/horizoncd/horizon/pkg/region/models"
+	registrymodels "github.com/horizoncd/horizon/pkg/registry/models"
+	"github.com/horizoncd/horizon/pkg/releaseplan/models"
+	"github.com/horizoncd/horizon/pkg/server/global"
+	tagmodels "github.com/horizoncd/horizon/pkg/tag/models"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	db, _   = orm.NewSqliteDB("")
+	manager = managerparam.InitManager(db)
+	// nolint
+	ctx = common.WithContext(context.Background(), &userauth.DefaultInfo{Name: "Tony", ID: 1})
+)
+
+// fakeOperator creates a pipelinerun with the preset status for each deployment
+type fakeOperator struct {
+	statuses map[uint]prmodels.PipelineStatus
+	deployed []uint
+}
+
+func (o *fakeOperator) Deploy(ctx context.Context, clusterID uint,
+	_ *clusterctl.DeployRequest) (*clusterctl.PipelinerunIDResponse, error) {
+	o.deployed = append(o.deployed, clusterID)
+	status, ok := o.statuses[clusterID]
+	if !ok {
+		status = prmodels.StatusOK
+	}
+	pr, err := manager.PipelinerunMgr.Create(ctx, &prmodels.Pipelinerun{
+		ClusterID: clusterID,
+		Action:    prmodels.ActionDeploy,
+		Status:    string(status),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &clusterctl.PipelinerunIDResponse{PipelinerunID: pr.ID}, nil
+}
+
+func TestMain(m *testing.M) {
+	if err := db.AutoMigrate(&appmodels.Application{}, &clustermodels.Cluster{},
+		&regionmodels.Region{}, &registrymodels.Registry{}, &tagmodels.Tag{},
+		&prmodels.Pipelinerun{}, &models.ReleasePlan{}, &models.ReleasePlanCluster{}); err != nil {
+		panic(err)
+	}
+	db.Create(&registrymodels.Registry{Model: global.Model{ID: 1}, Name: "registry"})
+	db.Create(&appmodels.Application{Model: global.Model{ID: 1}, Name: "app"})
+	for i, region := range []string{"hz", "sh", "bj"} {
+		db.Create(&regionmodels.Region{Name: region, RegistryID: 1})
+		db.Create(&clustermodels.Cluster{Model: global.Model{ID: uint(i + 1)}, ApplicationID: 1,
+			Name: "app-" + region, EnvironmentName: "online", RegionName: region})
+	}
+	os.Exit(m.Run())
+}
+
+func createPlan(t *testing.T, waves []models.Wave) *models.ReleasePlan {
+	plan := &models.ReleasePlan{
+		ApplicationID: 1,
+		Environment:   "online",
+		Title:         "release",
+		Status:        models.StatusRunning,
+		UpdatedBy:     1,
+	}
+	plan.SetWaves(waves)
+	var clusters []*models.ReleasePlanCluster
+	for i, wave := range waves {
+		for _, clusterID := range wave.ClusterIDs {
+			clusters = append(clusters, &models.ReleasePlanCluster{
+				ClusterID: clusterID,
+				Wave:      i,
+				Status:    models.ClusterStatusPending,
+			})
+		}
+	}
+	plan, err := manager.ReleasePlanMgr.Create(ctx, plan, clusters)
+	assert.Nil(t, err)
+	return plan
+}
+
+func TestRelease(t *testing.T) {
+	mockCtl := gomock.NewController(t)
+	cdMock := cdmock.NewMockCD(mockCtl)
+	states := map[string]string{"app-hz": "Healthy", "app-sh": "Healthy", "app-bj": "Progressing"}
+	cdMock.EXPECT().GetClusterState(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, params *cd.GetClusterStateV2Params) (*cd.ClusterStateV2, error) {
+			return &cd.ClusterStateV2{Status: states[params.Cluster]}, nil
+		}).AnyTimes()
+	operator := &fakeOperator{}
+	job := New(&releaseplan.Config{HealthTimeout: 10 * time.Minute}, manager, operator, cdMock, nil)
+
+	plan := createPlan(t, []models.Wave{
+		{Name: "canary", ClusterIDs: []uint{1}, Pause: "1m", ManualResume: true},
+		{Name: "rest", ClusterIDs: []uint{2, 3}},
+	})
+	now := time.Now()
+	tick := func(at time.Time) *models.ReleasePlan {
+		p, err := manager.ReleasePlanMgr.GetByID(ctx, plan.ID)
+		assert.Nil(t, err)
+		assert.Nil(t, job.release(ctx, p, at))
+		p, err = manager.ReleasePlanMgr.GetByID(ctx, plan.ID)
+		assert.Nil(t, err)
+		return p
+	}
+
+	// 1. the canary wave is deployed and becomes healthy
+	tick(now)
+	assert.Equal(t, []uint{1}, operator.deployed)
+	tick(now)
+	p := tick(now)
+	assert.NotNil(t, p.WaveFinishedAt)
+	assert.Equal(t, 0, p.CurrentWave)
+
+	// 2. the wave is observed before the plan pauses for resuming
+	p = tick(now.Add(30 * time.Second))
+	assert.Equal(t, 0, p.CurrentWave)
+	p = tick(now.Add(2 * time.Minute))
+	assert.Equal(t, 1, p.CurrentWave)
+	assert.Equal(t, models.StatusPaused, p.Status)
+	assert.Nil(t, p.WaveFinishedAt)
+
+	// 3. the next wave is deployed after resumed, and the plan halts when a cluster is not healthy in time
+	p.Status = models.StatusRunning
+	_, err := manager.ReleasePlanMgr.UpdateStatusByID(ctx, p.ID, []string{models.StatusPaused}, p)
+	assert.Nil(t, err)
+	tick(now.Add(3 * time.Minute))
+	assert.Equal(t, []uint{1, 2, 3}, operator.deployed)
+	p = tick(now.Add(4 * time.Minute))
+	assert.Equal(t, models.StatusRunning, p.Status)
+	p = tick(now.Add(20 * time.Minute))
+	assert.Equal(t, models.StatusFailed, p.Status)
+
+	clusters, err := manager.ReleasePlanMgr.ListClustersByPlanID(ctx, p.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, models.ClusterStatusHealthy, clusters[1].Status)
+	assert.Equal(t, models.ClusterStatusFailed, clusters[2].Status)
+
+	// a failed plan is not released any more
+	assert.Nil(t, job.release(ctx, p, now.Add(30*time.Minute)))
+	assert.Equal(t, 3, len(operator.deployed))
+}
+
+func TestReleaseFailedPipelinerun(t *testing.T) {
+	operator := &fakeOperator{statuses: map[uint]prmodels.PipelineStatus{1: prmodels.StatusFailed}}
+	job := New(&releaseplan.Config{HealthTimeout: 10 * time.Minute}, manager, operator, nil, nil)
+
+	plan := createPlan(t, []models.Wave{{Name: "all", ClusterIDs: []uint{1}}})
+	now := time.Now()
+	assert.Nil(t, job.release(ctx, plan, now))
+	assert.Nil(t, job.release(ctx, plan, now))
+
+	plan, err := manager.ReleasePlanMgr.GetByID(ctx, plan.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, models.StatusFailed, plan.Status)
+	assert.Contains(t, plan.Message, "failed")
+}
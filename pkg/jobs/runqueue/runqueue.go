@@ -0,0 +1,116 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package runqueue
+
+import (
+	"context"
+	"time"
+
+	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/core/middleware/requestid"
+	userauth "github.com/horizoncd/horizon/pkg/authentication/user"
+	"github.com/horizoncd/horizon/pkg/config/runqueue"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/param/managerparam"
+	prmodels "github.com/horizoncd/horizon/pkg/pipelinerun/models"
+	"github.com/horizoncd/horizon/pkg/util/log"
+	uuid "github.com/satori/go.uuid"
+)
+
+// ClusterOperator is the part of the cluster controller used by the job
+type ClusterOperator interface {
+	DequeuePipelinerun(ctx context.Context, pipelinerunID uint) (bool, error)
+}
+
+// Job dequeues the queued pipelineruns once their clusters are released. The earliest queued
+// pipelinerun of each cluster is tried, and it's executed as its creator. Replicas racing for
+// the same cluster are arbitrated by the run queue of the cluster in db.
+type Job struct {
+	config   *runqueue.Config
+	mgr      *managerparam.Manager
+	operator ClusterOperator
+}
+
+func New(config *runqueue.Config, mgr *managerparam.Manager, operator ClusterOperator) *Job {
+	return &Job{
+		config:   config,
+		mgr:      mgr,
+		operator: operator,
+	}
+}
+
+func (j *Job) Run(ctx context.Context) {
+	log.Infof(ctx, "Starting dequeuing pipelineruns every %v", j.config.JobInterval)
+	defer log.Infof(ctx, "Stopping dequeuing pipelineruns")
+	ticker := time.NewTicker(j.config.JobInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			rid := uuid.NewV4().String()
+			// nolint
+			ctx := context.WithValue(ctx, requestid.HeaderXRequestID, rid)
+			j.process(ctx)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (j *Job) process(ctx context.Context) {
+	prs, err := j.mgr.PipelinerunMgr.ListByStatus(ctx, prmodels.StatusQueued)
+	if err != nil {
+		log.Errorf(ctx, "failed to list queued pipelineruns, err: %v", err)
+		return
+	}
+	tried := make(map[uint]bool)
+	for _, pr := range prs {
+		if tried[pr.ClusterID] {
+			continue
+		}
+		tried[pr.ClusterID] = true
+		dequeued, err := j.dequeue(ctx, pr)
+		if err != nil {
+			log.Warningf(ctx, "failed to dequeue pipelinerun %d of cluster %d, err: %v", pr.ID, pr.ClusterID, err)
+			continue
+		}
+		if dequeued {
+			log.Infof(ctx, "pipelinerun %d of cluster %d is dequeued", pr.ID, pr.ClusterID)
+		}
+	}
+}
+
+// dequeue executes the pipelinerun as its creator
+func (j *Job) dequeue(ctx context.Context, pr *prmodels.Pipelinerun) (bool, error) {
+	user, err := j.mgr.UserManager.GetUserByID(ctx, pr.CreatedBy)
+	if err != nil {
+		if _, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); ok {
+			// the pipelinerun can never be executed without its creator
+			if err := j.mgr.PipelinerunMgr.UpdateStatusByID(ctx, pr.ID, prmodels.StatusFailed); err != nil {
+				log.Errorf(ctx, "failed to update status of pipelinerun %d, err: %v", pr.ID, err)
+			}
+		}
+		return false, err
+	}
+	ctx = common.WithContext(ctx, &userauth.DefaultInfo{
+		Name:     user.Name,
+		FullName: user.FullName,
+		ID:       user.ID,
+		Email:    user.Email,
+		Admin:    user.Admin,
+	})
+	return j.operator.DequeuePipelinerun(ctx, pr.ID)
+}
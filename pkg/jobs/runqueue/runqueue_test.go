@@ -0,0 +1,87 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package runqueue
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/horizoncd/horizon/core/common"
+	"github.com/horizoncd/horizon/lib/orm"
+	"github.com/horizoncd/horizon/pkg/config/runqueue"
+	"github.com/horizoncd/horizon/pkg/param/managerparam"
+	prmodels "github.com/horizoncd/horizon/pkg/pipelinerun/models"
+	usermodels "github.com/horizoncd/horizon/pkg/user/models"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	db, _   = orm.NewSqliteDB("")
+	manager = managerparam.InitManager(db)
+	ctx     = context.Background()
+)
+
+// fakeOperator records the pipelineruns dequeued and the users they are dequeued as
+type fakeOperator struct {
+	pipelineruns []uint
+	users        []string
+}
+
+func (o *fakeOperator) DequeuePipelinerun(ctx context.Context, pipelinerunID uint) (bool, error) {
+	user, _ := common.UserFromContext(ctx)
+	o.pipelineruns = append(o.pipelineruns, pipelinerunID)
+	o.users = append(o.users, user.GetName())
+	return true, nil
+}
+
+func TestMain(m *testing.M) {
+	if err := db.AutoMigrate(&prmodels.Pipelinerun{}, &usermodels.User{}); err != nil {
+		panic(err)
+	}
+	db.Save(&usermodels.User{Name: "Tony"})
+	os.Exit(m.Run())
+}
+
+func createPipelinerun(t *testing.T, clusterID, createdBy uint, status prmodels.PipelineStatus) *prmodels.Pipelinerun {
+	pr, err := manager.PipelinerunMgr.Create(ctx, &prmodels.Pipelinerun{
+		ClusterID: clusterID,
+		Action:    prmodels.ActionRestart,
+		Status:    string(status),
+		CreatedBy: createdBy,
+	})
+	assert.Nil(t, err)
+	return pr
+}
+
+func TestProcess(t *testing.T) {
+	operator := &fakeOperator{}
+	job := New(&runqueue.Config{}, manager, operator)
+
+	first := createPipelinerun(t, 1, 1, prmodels.StatusQueued)
+	createPipelinerun(t, 1, 1, prmodels.StatusQueued)
+	createPipelinerun(t, 2, 1, prmodels.StatusOK)
+	orphan := createPipelinerun(t, 3, 99, prmodels.StatusQueued)
+
+	// only the earliest queued pipelinerun of each cluster is tried
+	job.process(ctx)
+	assert.Equal(t, []uint{first.ID}, operator.pipelineruns)
+	assert.Equal(t, []string{"Tony"}, operator.users)
+
+	// pipelineruns whose creators are deleted are failed
+	orphan, err := manager.PipelinerunMgr.GetByID(ctx, orphan.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, string(prmodels.StatusFailed), orphan.Status)
+}
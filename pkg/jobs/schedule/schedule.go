@@ -0,0 +1,224 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schedule
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/horizoncd/horizon/core/common"
+	accessctl "github.com/horizoncd/horizon/core/controller/access"
+	clusterctl "github.com/horizoncd/horizon/core/controller/cluster"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/core/middleware/requestid"
+	userauth "github.com/horizoncd/horizon/pkg/authentication/user"
+	"github.com/horizoncd/horizon/pkg/config/schedule"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	freezeservice "github.com/horizoncd/horizon/pkg/freeze/service"
+	"github.com/horizoncd/horizon/pkg/param/managerparam"
+	prmodels "github.com/horizoncd/horizon/pkg/pipelinerun/models"
+	"github.com/horizoncd/horizon/pkg/schedule/models"
+	"github.com/horizoncd/horizon/pkg/util/log"
+	uuid "github.com/satori/go.uuid"
+)
+
+// ClusterOperator is the part of the cluster controller used by the job
+type ClusterOperator interface {
+	BuildDeploy(ctx context.Context, clusterID uint,
+		request *clusterctl.BuildDeployRequest) (*clusterctl.BuildDeployResponse, error)
+	Deploy(ctx context.Context, clusterID uint,
+		request *clusterctl.DeployRequest) (*clusterctl.PipelinerunIDResponse, error)
+	Restart(ctx context.Context, clusterID uint) (*clusterctl.PipelinerunIDResponse, error)
+}
+
+// Job runs the schedules of clusters when they are due. Each due schedule is claimed by moving it
+// to its next run before it runs, so a schedule runs once for all the runs missed while horizon is down.
+// Pipelineruns are created as the operator of the schedule with the scheduled trigger,
+// and the operator's permission on the cluster is reviewed just like the request was sent to the cluster's api.
+type Job struct {
+	config    *schedule.Config
+	mgr       *managerparam.Manager
+	operator  ClusterOperator
+	reviewer  accessctl.Controller
+	freezeSvc freezeservice.Service
+}
+
+func New(config *schedule.Config, mgr *managerparam.Manager, operator ClusterOperator,
+	reviewer accessctl.Controller, freezeSvc freezeservice.Service) *Job {
+	return &Job{
+		config:    config,
+		mgr:       mgr,
+		operator:  operator,
+		reviewer:  reviewer,
+		freezeSvc: freezeSvc,
+	}
+}
+
+func (j *Job) Run(ctx context.Context) {
+	log.Infof(ctx, "Starting running schedules every %v", j.config.JobInterval)
+	defer log.Infof(ctx, "Stopping running schedules")
+	loc, err := time.LoadLocation(j.config.TimeZone)
+	if err != nil {
+		log.Errorf(ctx, "failed to load time zone %s, err: %v", j.config.TimeZone, err)
+		return
+	}
+	ticker := time.NewTicker(j.config.JobInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			rid := uuid.NewV4().String()
+			// nolint
+			ctx := context.WithValue(ctx, requestid.HeaderXRequestID, rid)
+			j.process(ctx, loc, time.Now())
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (j *Job) process(ctx context.Context, loc *time.Location, now time.Time) {
+	schedules, err := j.mgr.ScheduleMgr.ListDue(ctx, now)
+	if err != nil {
+		log.Errorf(ctx, "failed to list due schedules, err: %v", err)
+		return
+	}
+	for _, s := range schedules {
+		claimed, err := j.claim(ctx, s, loc, now)
+		if err != nil {
+			log.Errorf(ctx, "failed to claim schedule %d, err: %v", s.ID, err)
+			continue
+		}
+		if !claimed {
+			continue
+		}
+		prID, err := j.execute(ctx, s)
+		message := ""
+		if err != nil {
+			log.Warningf(ctx, "failed to run schedule %d of cluster %d, err: %v", s.ID, s.ClusterID, err)
+			message = err.Error()
+		}
+		if err := j.mgr.ScheduleMgr.UpdateResultByID(ctx, s.ID, prID, message); err != nil {
+			log.Errorf(ctx, "failed to update result of schedule %d, err: %v", s.ID, err)
+		}
+	}
+}
+
+// claim moves the schedule to its next run, a schedule whose cron can't be parsed anymore never runs again
+func (j *Job) claim(ctx context.Context, s *models.Schedule, loc *time.Location, now time.Time) (bool, error) {
+	var nextRunAt *time.Time
+	sched, err := models.ParseCron(s.Cron, loc)
+	if err != nil {
+		log.Warningf(ctx, "invalid cron %q of schedule %d, err: %v", s.Cron, s.ID, err)
+	} else if next := sched.Next(now); !next.IsZero() {
+		nextRunAt = &next
+	}
+	return j.mgr.ScheduleMgr.ClaimByID(ctx, s.ID, *s.NextRunAt, now, nextRunAt)
+}
+
+// execute performs the action of the schedule as its operator, and returns the pipelinerun created
+func (j *Job) execute(ctx context.Context, s *models.Schedule) (uint, error) {
+	if _, err := j.mgr.ClusterMgr.GetByID(ctx, s.ClusterID); err != nil {
+		if _, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); ok {
+			// the cluster is deleted along with its schedules
+			return 0, j.mgr.ScheduleMgr.DeleteByID(ctx, s.ID)
+		}
+		return 0, err
+	}
+	user, err := j.mgr.UserManager.GetUserByID(ctx, s.OperatorID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get operator: %v", err)
+	}
+	ctx = common.WithContext(ctx, &userauth.DefaultInfo{
+		Name:     user.Name,
+		FullName: user.FullName,
+		ID:       user.ID,
+		Email:    user.Email,
+		Admin:    user.Admin,
+	})
+	ctx = clusterctl.WithPipelinerunTrigger(ctx, prmodels.TriggerScheduled)
+
+	allowed, reason, err := j.review(ctx, s.Action, s.ClusterID)
+	if err != nil {
+		return 0, err
+	}
+	if !allowed {
+		return 0, fmt.Errorf("forbidden: %s", reason)
+	}
+
+	if j.freezeSvc != nil {
+		window, until, err := j.freezeSvc.GetActiveWindow(ctx, s.ClusterID)
+		if err != nil {
+			return 0, err
+		}
+		if window != nil {
+			return 0, fmt.Errorf("skipped, frozen by %s until %s", window.Name, until.Format(time.RFC3339))
+		}
+	}
+
+	title := fmt.Sprintf("scheduled %s", s.Action)
+	if s.Name != "" {
+		title = fmt.Sprintf("%s by schedule %s", title, s.Name)
+	}
+	description := fmt.Sprintf("schedule %d: %s", s.ID, s.Cron)
+	params := s.GetParams()
+	switch s.Action {
+	case models.ActionBuildDeploy:
+		resp, err := j.operator.BuildDeploy(ctx, s.ClusterID, &clusterctl.BuildDeployRequest{
+			Title:       title,
+			Description: description,
+			Git:         &clusterctl.BuildDeployRequestGit{Branch: params.GitBranch},
+		})
+		if err != nil {
+			return 0, err
+		}
+		return resp.PipelinerunID, nil
+	case models.ActionDeploy:
+		resp, err := j.operator.Deploy(ctx, s.ClusterID, &clusterctl.DeployRequest{
+			Title:       title,
+			Description: description,
+			ImageTag:    params.ImageTag,
+		})
+		if err != nil {
+			return 0, err
+		}
+		return resp.PipelinerunID, nil
+	case models.ActionRestart:
+		resp, err := j.operator.Restart(ctx, s.ClusterID)
+		if err != nil {
+			return 0, err
+		}
+		return resp.PipelinerunID, nil
+	default:
+		return 0, fmt.Errorf("unsupported action %s", s.Action)
+	}
+}
+
+// review tells whether the current user may perform the action on the cluster
+func (j *Job) review(ctx context.Context, action string, clusterID uint) (bool, string, error) {
+	api := accessctl.API{URL: fmt.Sprintf("/apis/core/v2/clusters/%d/%s", clusterID, action),
+		Method: http.MethodPost}
+	results, err := j.reviewer.Review(ctx, []accessctl.API{api})
+	if err != nil {
+		return false, "", err
+	}
+	result := results[api.URL][api.Method]
+	if result == nil {
+		return false, "not reviewed", nil
+	}
+	return result.Allowed, result.Reason, nil
+}
@@ -0,0 +1,173 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schedule
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/horizoncd/horizon/core/common"
+	accessctl "github.com/horizoncd/horizon/core/controller/access"
+	clusterctl "github.com/horizoncd/horizon/core/controller/cluster"
+	"github.com/horizoncd/horizon/lib/orm"
+	clustermodels "github.com/horizoncd/horizon/pkg/cluster/models"
+	"github.com/horizoncd/horizon/pkg/config/schedule"
+	"github.com/horizoncd/horizon/pkg/param/managerparam"
+	prmodels "github.com/horizoncd/horizon/pkg/pipelinerun/models"
+	regionmodels "github.com/horizoncd/horizon/pkg/region/models"
+	"github.com/horizoncd/horizon/pkg/schedule/models"
+	usermodels "github.com/horizoncd/horizon/pkg/user/models"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	db, _   = orm.NewSqliteDB("")
+	manager = managerparam.InitManager(db)
+	ctx     = context.Background()
+)
+
+// fakeOperator records the requests and the trigger they are sent with
+type fakeOperator struct {
+	branches []string
+	triggers []string
+	users    []string
+}
+
+func (o *fakeOperator) BuildDeploy(ctx context.Context, _ uint,
+	r *clusterctl.BuildDeployRequest) (*clusterctl.BuildDeployResponse, error) {
+	o.branches = append(o.branches, r.Git.Branch)
+	o.record(ctx)
+	return &clusterctl.BuildDeployResponse{PipelinerunID: uint(len(o.triggers))}, nil
+}
+
+func (o *fakeOperator) Deploy(context.Context, uint,
+	*clusterctl.DeployRequest) (*clusterctl.PipelinerunIDResponse, error) {
+	return nil, fmt.Errorf("image not found")
+}
+
+func (o *fakeOperator) Restart(ctx context.Context, _ uint) (*clusterctl.PipelinerunIDResponse, error) {
+	o.record(ctx)
+	return &clusterctl.PipelinerunIDResponse{PipelinerunID: uint(len(o.triggers))}, nil
+}
+
+func (o *fakeOperator) record(ctx context.Context) {
+	user, _ := common.UserFromContext(ctx)
+	o.users = append(o.users, user.GetName())
+	o.triggers = append(o.triggers, clusterctl.PipelinerunTriggerFromContext(ctx))
+}
+
+// fakeReviewer denies all apis of the forbidden clusters
+type fakeReviewer struct {
+	forbidden map[uint]bool
+}
+
+func (r *fakeReviewer) Review(_ context.Context,
+	apis []accessctl.API) (map[string]map[string]*accessctl.ReviewResult, error) {
+	results := make(map[string]map[string]*accessctl.ReviewResult)
+	for _, api := range apis {
+		allowed := true
+		for id := range r.forbidden {
+			if api.URL == fmt.Sprintf("/apis/core/v2/clusters/%d/restart", id) {
+				allowed = false
+			}
+		}
+		results[api.URL] = map[string]*accessctl.ReviewResult{
+			api.Method: {Allowed: allowed, Reason: "not a member"},
+		}
+	}
+	return results, nil
+}
+
+func TestMain(m *testing.M) {
+	if err := db.AutoMigrate(&models.Schedule{}, &clustermodels.Cluster{}, &regionmodels.Region{},
+		&usermodels.User{}); err != nil {
+		panic(err)
+	}
+	db.Save(&regionmodels.Region{Name: "schedule-hz"})
+	db.Save(&usermodels.User{Name: "Tony"})
+	db.Save(&clustermodels.Cluster{ApplicationID: 1, Name: "schedule-a", RegionName: "schedule-hz"})
+	db.Save(&clustermodels.Cluster{ApplicationID: 1, Name: "schedule-b", RegionName: "schedule-hz"})
+	os.Exit(m.Run())
+}
+
+func createSchedule(t *testing.T, clusterID uint, cron, action string, params models.Params,
+	nextRunAt time.Time) *models.Schedule {
+	s := &models.Schedule{
+		ClusterID:  clusterID,
+		Cron:       cron,
+		Action:     action,
+		OperatorID: 1,
+		NextRunAt:  &nextRunAt,
+	}
+	s.SetParams(params)
+	s, err := manager.ScheduleMgr.Create(ctx, s)
+	assert.Nil(t, err)
+	return s
+}
+
+func TestProcess(t *testing.T) {
+	operator := &fakeOperator{}
+	job := New(&schedule.Config{}, manager, operator, &fakeReviewer{forbidden: map[uint]bool{2: true}}, nil)
+	now := time.Date(2023, 6, 28, 10, 0, 30, 0, time.UTC)
+
+	// missed since two days ago, but runs only once
+	nightly := createSchedule(t, 1, "0 2 * * *", models.ActionBuildDeploy,
+		models.Params{GitBranch: "develop"}, now.Add(-56*time.Hour))
+	deploy := createSchedule(t, 1, "@hourly", models.ActionDeploy, models.Params{ImageTag: "v1"},
+		now.Add(-time.Minute))
+	forbidden := createSchedule(t, 2, "@hourly", models.ActionRestart, models.Params{}, now.Add(-time.Minute))
+	deleted := createSchedule(t, 3, "@hourly", models.ActionRestart, models.Params{}, now.Add(-time.Minute))
+	later := createSchedule(t, 1, "@hourly", models.ActionRestart, models.Params{}, now.Add(time.Minute))
+
+	job.process(ctx, time.UTC, now)
+	job.process(ctx, time.UTC, now)
+	assert.Equal(t, []string{"develop"}, operator.branches)
+	assert.Equal(t, []string{prmodels.TriggerScheduled}, operator.triggers)
+	assert.Equal(t, []string{"Tony"}, operator.users)
+
+	nightly, err := manager.ScheduleMgr.GetByID(ctx, nightly.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, uint(1), nightly.LastPipelinerunID)
+	assert.Equal(t, "", nightly.LastMessage)
+	assert.True(t, nightly.LastRunAt.Equal(now))
+	assert.True(t, nightly.NextRunAt.Equal(time.Date(2023, 6, 29, 2, 0, 0, 0, time.UTC)))
+
+	deploy, err = manager.ScheduleMgr.GetByID(ctx, deploy.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, "image not found", deploy.LastMessage)
+	assert.True(t, deploy.NextRunAt.Equal(time.Date(2023, 6, 28, 11, 0, 0, 0, time.UTC)))
+
+	forbidden, err = manager.ScheduleMgr.GetByID(ctx, forbidden.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, "forbidden: not a member", forbidden.LastMessage)
+
+	// schedules of deleted clusters are deleted
+	_, err = manager.ScheduleMgr.GetByID(ctx, deleted.ID)
+	assert.NotNil(t, err)
+
+	later, err = manager.ScheduleMgr.GetByID(ctx, later.ID)
+	assert.Nil(t, err)
+	assert.Nil(t, later.LastRunAt)
+
+	// the hourly restart runs once it's due
+	job.process(ctx, time.UTC, now.Add(time.Hour))
+	assert.Equal(t, 2, len(operator.triggers))
+	later, err = manager.ScheduleMgr.GetByID(ctx, later.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, uint(2), later.LastPipelinerunID)
+}
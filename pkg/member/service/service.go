@@ -24,9 +24,11 @@ import (
 	herror "github.com/horizoncd/horizon/core/errors"
 	applicationmanager "github.com/horizoncd/horizon/pkg/application/manager"
 	userauth "github.com/horizoncd/horizon/pkg/authentication/user"
+	batchjobmanager "github.com/horizoncd/horizon/pkg/batchjob/manager"
 	clustermanager "github.com/horizoncd/horizon/pkg/cluster/manager"
 	memberctx "github.com/horizoncd/horizon/pkg/context"
 	perror "github.com/horizoncd/horizon/pkg/errors"
+	freezemanager "github.com/horizoncd/horizon/pkg/freeze/manager"
 	groupmanager "github.com/horizoncd/horizon/pkg/group/manager"
 	"github.com/horizoncd/horizon/pkg/member"
 	"github.com/horizoncd/horizon/pkg/member/models"
@@ -34,6 +36,8 @@ import (
 	"github.com/horizoncd/horizon/pkg/param/managerparam"
 	pipelinerunmanager "github.com/horizoncd/horizon/pkg/pipelinerun/manager"
 	roleservice "github.com/horizoncd/horizon/pkg/rbac/role"
+	releaseplanmanager "github.com/horizoncd/horizon/pkg/releaseplan/manager"
+	schedulemanager "github.com/horizoncd/horizon/pkg/schedule/manager"
 	templatemanager "github.com/horizoncd/horizon/pkg/template/manager"
 	templatereleasemanager "github.com/horizoncd/horizon/pkg/templaterelease/manager"
 	usermanager "github.com/horizoncd/horizon/pkg/user/manager"
@@ -74,6 +78,10 @@ type service struct {
 	oauthManager              oauthmanager.Manager
 	userManager               usermanager.Manager
 	webhookManager            webhookmanager.Manager
+	freezeWindowManager       freezemanager.Manager
+	releasePlanManager        releaseplanmanager.Manager
+	batchJobManager           batchjobmanager.Manager
+	scheduleManager           schedulemanager.Manager
 }
 
 func NewService(roleService roleservice.Service, oauthManager oauthmanager.Manager,
@@ -90,6 +98,10 @@ func NewService(roleService roleservice.Service, oauthManager oauthmanager.Manag
 		oauthManager:              oauthManager,
 		userManager:               manager.UserManager,
 		webhookManager:            manager.WebhookManager,
+		freezeWindowManager:       manager.FreezeWindowMgr,
+		releasePlanManager:        manager.ReleasePlanMgr,
+		batchJobManager:           manager.BatchJobMgr,
+		scheduleManager:           manager.ScheduleMgr,
 	}
 }
 
@@ -230,6 +242,47 @@ func (s *service) listWebhookLogMember(ctx context.Context, id uint) ([]models.M
 	return s.listWebhookMember(ctx, webhookLog.WebhookID)
 }
 
+func (s *service) listFreezeWindowMember(ctx context.Context, id uint) ([]models.Member, error) {
+	if id == 0 {
+		return nil, nil
+	}
+	window, err := s.freezeWindowManager.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	switch window.ResourceType {
+	case common.ResourceGroup, common.ResourceApplication:
+		return s.ListMember(ctx, window.ResourceType,
+			window.ResourceID)
+	default:
+		return nil, nil
+	}
+}
+
+func (s *service) listReleasePlanMember(ctx context.Context, id uint) ([]models.Member, error) {
+	plan, err := s.releasePlanManager.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return s.ListMember(ctx, common.ResourceApplication, plan.ApplicationID)
+}
+
+func (s *service) listBatchJobMember(ctx context.Context, id uint) ([]models.Member, error) {
+	job, err := s.batchJobManager.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return s.ListMember(ctx, common.ResourceGroup, job.GroupID)
+}
+
+func (s *service) listScheduleMember(ctx context.Context, id uint) ([]models.Member, error) {
+	schedule, err := s.scheduleManager.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return s.ListMember(ctx, common.ResourceCluster, schedule.ClusterID)
+}
+
 func (s *service) GetMemberOfResource(ctx context.Context,
 	resourceType string, resourceIDStr string) (*models.Member, error) {
 	var currentUser userauth.User
@@ -353,6 +406,14 @@ func (s *service) ListMember(ctx context.Context, resourceType string, resourceI
 		allMembers, err = s.listWebhookMember(ctx, resourceID)
 	case common.ResourceWebhookLog:
 		allMembers, err = s.listWebhookLogMember(ctx, resourceID)
+	case common.ResourceFreezeWindow:
+		allMembers, err = s.listFreezeWindowMember(ctx, resourceID)
+	case common.ResourceReleasePlan:
+		allMembers, err = s.listReleasePlanMember(ctx, resourceID)
+	case common.ResourceBatchJob:
+		allMembers, err = s.listBatchJobMember(ctx, resourceID)
+	case common.ResourceSchedule:
+		allMembers, err = s.listScheduleMember(ctx, resourceID)
 	default:
 		err = errors.New("unsupported resourceType")
 	}
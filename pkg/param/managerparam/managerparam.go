@@ -21,17 +21,31 @@ import (
 	accesstokenmanager "github.com/horizoncd/horizon/pkg/accesstoken/manager"
 	applicationmanager "github.com/horizoncd/horizon/pkg/application/manager"
 	applicationregionmanager "github.com/horizoncd/horizon/pkg/applicationregion/manager"
+	auditmanager "github.com/horizoncd/horizon/pkg/audit/manager"
+	autodeploymanager "github.com/horizoncd/horizon/pkg/autodeploy/manager"
+	batchjobmanager "github.com/horizoncd/horizon/pkg/batchjob/manager"
+	buildcontextmanager "github.com/horizoncd/horizon/pkg/buildcontext/manager"
+	canarymanager "github.com/horizoncd/horizon/pkg/canary/manager"
 	clustermanager "github.com/horizoncd/horizon/pkg/cluster/manager"
+	doramanager "github.com/horizoncd/horizon/pkg/dora/manager"
+	driftmanager "github.com/horizoncd/horizon/pkg/drift/manager"
 	envmanager "github.com/horizoncd/horizon/pkg/environment/manager"
 	environmentregionmanager "github.com/horizoncd/horizon/pkg/environmentregion/manager"
 	eventManager "github.com/horizoncd/horizon/pkg/event/manager"
+	freezemanager "github.com/horizoncd/horizon/pkg/freeze/manager"
 	groupmanager "github.com/horizoncd/horizon/pkg/group/manager"
 	idpmanager "github.com/horizoncd/horizon/pkg/idp/manager"
 	membermanager "github.com/horizoncd/horizon/pkg/member"
 	prmanager "github.com/horizoncd/horizon/pkg/pipelinerun/manager"
 	pipelinemanager "github.com/horizoncd/horizon/pkg/pipelinerun/pipeline/manager"
+	prprovenancemanager "github.com/horizoncd/horizon/pkg/pipelinerun/provenance/manager"
+	prreportmanager "github.com/horizoncd/horizon/pkg/pipelinerun/report/manager"
+	promotionmanager "github.com/horizoncd/horizon/pkg/promotion/manager"
 	regionmanager "github.com/horizoncd/horizon/pkg/region/manager"
 	registrymanager "github.com/horizoncd/horizon/pkg/registry/manager"
+	releaseplanmanager "github.com/horizoncd/horizon/pkg/releaseplan/manager"
+	runqueuemanager "github.com/horizoncd/horizon/pkg/runqueue/manager"
+	schedulemanager "github.com/horizoncd/horizon/pkg/schedule/manager"
 	tagmanager "github.com/horizoncd/horizon/pkg/tag/manager"
 	templatemanager "github.com/horizoncd/horizon/pkg/template/manager"
 	trmanager "github.com/horizoncd/horizon/pkg/templaterelease/manager"
@@ -61,6 +75,7 @@ type Manager struct {
 	RegionMgr                regionmanager.Manager
 	PipelinerunMgr           prmanager.Manager
 	PipelineMgr              pipelinemanager.Manager
+	PipelinerunReportMgr     prreportmanager.Manager
 	EnvMgr                   envmanager.Manager
 	GroupManager             groupmanager.Manager
 	RegistryManager          registrymanager.Manager
@@ -69,6 +84,19 @@ type Manager struct {
 	WebhookManager           webhookManager.Manager
 	EventManager             eventManager.Manager
 	TokenManager             tokenmanager.Manager
+	PromotionPathMgr         promotionmanager.Manager
+	FreezeWindowMgr          freezemanager.Manager
+	CanaryAnalysisMgr        canarymanager.Manager
+	ReleasePlanMgr           releaseplanmanager.Manager
+	BatchJobMgr              batchjobmanager.Manager
+	DriftReportMgr           driftmanager.Manager
+	ScheduleMgr              schedulemanager.Manager
+	AutoDeployMgr            autodeploymanager.Manager
+	RunQueueMgr              runqueuemanager.Manager
+	BuildContextMgr          buildcontextmanager.Manager
+	DORAMgr                  doramanager.Manager
+	PipelinerunProvenanceMgr prprovenancemanager.Manager
+	AuditLogMgr              auditmanager.Manager
 }
 
 func InitManager(db *gorm.DB) *Manager {
@@ -90,6 +118,7 @@ func InitManager(db *gorm.DB) *Manager {
 		RegionMgr:                regionmanager.New(db),
 		PipelinerunMgr:           prmanager.New(db),
 		PipelineMgr:              pipelinemanager.New(db),
+		PipelinerunReportMgr:     prreportmanager.New(db),
 		EnvMgr:                   envmanager.New(db),
 		GroupManager:             groupmanager.New(db),
 		RegistryManager:          registrymanager.New(db),
@@ -98,5 +127,18 @@ func InitManager(db *gorm.DB) *Manager {
 		WebhookManager:           webhookManager.New(db),
 		EventManager:             eventManager.New(db),
 		TokenManager:             tokenmanager.New(db),
+		PromotionPathMgr:         promotionmanager.New(db),
+		FreezeWindowMgr:          freezemanager.New(db),
+		CanaryAnalysisMgr:        canarymanager.New(db),
+		ReleasePlanMgr:           releaseplanmanager.New(db),
+		BatchJobMgr:              batchjobmanager.New(db),
+		DriftReportMgr:           driftmanager.New(db),
+		ScheduleMgr:              schedulemanager.New(db),
+		AutoDeployMgr:            autodeploymanager.New(db),
+		RunQueueMgr:              runqueuemanager.New(db),
+		BuildContextMgr:          buildcontextmanager.New(db),
+		DORAMgr:                  doramanager.New(db),
+		PipelinerunProvenanceMgr: prprovenancemanager.New(db),
+		AuditLogMgr:              auditmanager.New(db),
 	}
 }
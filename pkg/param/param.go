@@ -18,11 +18,13 @@ import (
 	applicationgitrepo "github.com/horizoncd/horizon/pkg/application/gitrepo"
 	applicationservice "github.com/horizoncd/horizon/pkg/application/service"
 	"github.com/horizoncd/horizon/pkg/cd"
+	"github.com/horizoncd/horizon/pkg/cluster/ci"
 	"github.com/horizoncd/horizon/pkg/cluster/code"
 	clustergitrepo "github.com/horizoncd/horizon/pkg/cluster/gitrepo"
 	clusterservice "github.com/horizoncd/horizon/pkg/cluster/service"
 	"github.com/horizoncd/horizon/pkg/cluster/tekton/factory"
 	"github.com/horizoncd/horizon/pkg/environment/service"
+	freezeservice "github.com/horizoncd/horizon/pkg/freeze/service"
 	"github.com/horizoncd/horizon/pkg/grafana"
 	groupsvc "github.com/horizoncd/horizon/pkg/group/service"
 	"github.com/horizoncd/horizon/pkg/hook/hook"
@@ -34,6 +36,8 @@ import (
 
 	"github.com/horizoncd/horizon/core/controller/build"
 	"github.com/horizoncd/horizon/pkg/rbac/role"
+	"github.com/horizoncd/horizon/pkg/templaterelease/analysis"
+	"github.com/horizoncd/horizon/pkg/templaterelease/migration"
 	"github.com/horizoncd/horizon/pkg/templaterelease/output"
 	templateschema "github.com/horizoncd/horizon/pkg/templaterelease/schema"
 	userservice "github.com/horizoncd/horizon/pkg/user/service"
@@ -55,6 +59,7 @@ type Param struct {
 	RoleService    role.Service
 	ScopeService   scope.Service
 	GrafanaService grafana.Service
+	FreezeSvc      freezeservice.Service
 
 	// others
 	Hook                 hook.Hook
@@ -63,7 +68,10 @@ type Param struct {
 	CD                   cd.CD
 	K8sUtil              cd.K8sUtil
 	OutputGetter         output.Getter
+	AnalysisGetter       analysis.Getter
+	MigrationGetter      migration.Getter
 	TektonFty            factory.Factory
+	CIFty                ci.Factory
 	ClusterGitRepo       clustergitrepo.ClusterGitRepo
 	GitGetter            code.GitGetter
 	BuildSchema          *build.Schema
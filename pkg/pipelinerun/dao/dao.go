@@ -16,10 +16,12 @@ package dao
 
 import (
 	"context"
+	"time"
 
 	herrors "github.com/horizoncd/horizon/core/errors"
 	"github.com/horizoncd/horizon/lib/q"
 	"github.com/horizoncd/horizon/pkg/common"
+	perror "github.com/horizoncd/horizon/pkg/errors"
 	"github.com/horizoncd/horizon/pkg/pipelinerun/models"
 	"gorm.io/gorm"
 )
@@ -39,10 +41,24 @@ type DAO interface {
 	GetLatestByClusterIDAndActionAndStatus(ctx context.Context, clusterID uint,
 		action, status string) (*models.Pipelinerun, error)
 	UpdateStatusByID(ctx context.Context, pipelinerunID uint, result models.PipelineStatus) error
+	// UpdateStatusByIDAndStatus updates the status of the pipelinerun only if its status is still status,
+	// it returns false if the status is changed by others
+	UpdateStatusByIDAndStatus(ctx context.Context, pipelinerunID uint,
+		status, newStatus models.PipelineStatus) (bool, error)
+	// ListByStatus lists the pipelineruns of all clusters in the status, in the order they are created
+	ListByStatus(ctx context.Context, status models.PipelineStatus) ([]*models.Pipelinerun, error)
 	UpdateCIEventIDByID(ctx context.Context, pipelinerunID uint, ciEventID string) error
+	// UpdateByID update the fields of a pipelinerun which are determined when it starts executing
+	UpdateByID(ctx context.Context, pipelinerunID uint, pipelinerun *models.Pipelinerun) error
+	// UpdateReviewByID record the review result of a pending pipelinerun
+	UpdateReviewByID(ctx context.Context, pipelinerunID uint, status models.PipelineStatus,
+		reviewedBy uint, comment string) error
 	UpdateResultByID(ctx context.Context, pipelinerunID uint, result *models.Result) error
 	GetLatestSuccessByClusterID(ctx context.Context, clusterID uint) (*models.Pipelinerun, error)
 	GetFirstCanRollbackPipelinerun(ctx context.Context, clusterID uint) (*models.Pipelinerun, error)
+	// ListBuilds list the latest successful builds of the commit with the build config
+	ListBuilds(ctx context.Context, gitURL, subfolder, commit, buildConfigHash string,
+		limit int) ([]*models.Pipelinerun, error)
 }
 
 type dao struct{ db *gorm.DB }
@@ -162,6 +178,24 @@ func (d *dao) UpdateStatusByID(ctx context.Context, pipelinerunID uint, status m
 	return res.Error
 }
 
+func (d *dao) UpdateStatusByIDAndStatus(ctx context.Context, pipelinerunID uint,
+	status, newStatus models.PipelineStatus) (bool, error) {
+	res := d.db.WithContext(ctx).Exec(common.PipelinerunUpdateStatusByIDAndStatus, newStatus, pipelinerunID, status)
+	if res.Error != nil {
+		return false, herrors.NewErrUpdateFailed(herrors.PipelinerunInDB, res.Error.Error())
+	}
+	return res.RowsAffected > 0, nil
+}
+
+func (d *dao) ListByStatus(ctx context.Context, status models.PipelineStatus) ([]*models.Pipelinerun, error) {
+	var pipelineruns []*models.Pipelinerun
+	result := d.db.WithContext(ctx).Raw(common.PipelinerunListByStatus, status).Scan(&pipelineruns)
+	if result.Error != nil {
+		return nil, herrors.NewErrListFailed(herrors.PipelinerunInDB, result.Error.Error())
+	}
+	return pipelineruns, nil
+}
+
 func (d *dao) UpdateCIEventIDByID(ctx context.Context, pipelinerunID uint, ciEventID string) error {
 	res := d.db.WithContext(ctx).Exec(common.PipelinerunUpdateCIEventIDByID, ciEventID, pipelinerunID)
 	if res.Error != nil {
@@ -170,6 +204,31 @@ func (d *dao) UpdateCIEventIDByID(ctx context.Context, pipelinerunID uint, ciEve
 	return res.Error
 }
 
+func (d *dao) UpdateByID(ctx context.Context, pipelinerunID uint, pipelinerun *models.Pipelinerun) error {
+	result := d.db.WithContext(ctx).Where("id = ?", pipelinerunID).Select("Status", "Title", "Description",
+		"GitURL", "GitRefType", "GitRef", "GitCommit", "GitCommitTime", "GitSubfolder", "LastGitCommit", "BuildConfigHash",
+		"ImageURL", "LastConfigCommit", "ConfigCommit", "RollbackFrom", "PromoteFrom", "ReuseFrom",
+		"Request").Updates(pipelinerun)
+	if result.Error != nil {
+		return herrors.NewErrUpdateFailed(herrors.PipelinerunInDB, result.Error.Error())
+	}
+	return nil
+}
+
+func (d *dao) UpdateReviewByID(ctx context.Context, pipelinerunID uint, status models.PipelineStatus,
+	reviewedBy uint, comment string) error {
+	res := d.db.WithContext(ctx).Exec(common.PipelinerunUpdateReviewByID, status, reviewedBy, time.Now(),
+		comment, pipelinerunID)
+	if res.Error != nil {
+		return herrors.NewErrUpdateFailed(herrors.PipelinerunInDB, res.Error.Error())
+	}
+	if res.RowsAffected == 0 {
+		return perror.Wrapf(herrors.ErrPipelinerunNotPending,
+			"pipelinerun %d has already been reviewed", pipelinerunID)
+	}
+	return nil
+}
+
 func (d *dao) UpdateResultByID(ctx context.Context, pipelinerunID uint, result *models.Result) error {
 	res := d.db.WithContext(ctx).Exec(common.PipelinerunUpdateResultByID, result.Result, result.S3Bucket,
 		result.LogObject, result.PrObject, result.StartedAt, result.FinishedAt, pipelinerunID)
@@ -226,3 +285,14 @@ func (d *dao) GetFirstCanRollbackPipelinerun(ctx context.Context, clusterID uint
 	}
 	return &pipelinerun, nil
 }
+
+func (d *dao) ListBuilds(ctx context.Context, gitURL, subfolder, commit, buildConfigHash string,
+	limit int) ([]*models.Pipelinerun, error) {
+	var pipelineruns []*models.Pipelinerun
+	result := d.db.WithContext(ctx).Raw(common.PipelinerunListBuilds, commit, buildConfigHash,
+		gitURL, subfolder, limit).Scan(&pipelineruns)
+	if result.Error != nil {
+		return nil, herrors.NewErrGetFailed(herrors.PipelinerunInDB, result.Error.Error())
+	}
+	return pipelineruns, nil
+}
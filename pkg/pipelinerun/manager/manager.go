@@ -42,9 +42,25 @@ type Manager interface {
 		status string) (*models.Pipelinerun, error)
 	GetLatestSuccessByClusterID(ctx context.Context, clusterID uint) (*models.Pipelinerun, error)
 	UpdateStatusByID(ctx context.Context, pipelinerunID uint, result models.PipelineStatus) error
+	// UpdateStatusByIDAndStatus updates the status of the pipelinerun only if its status is still status,
+	// it returns false if the status is changed by others
+	UpdateStatusByIDAndStatus(ctx context.Context, pipelinerunID uint,
+		status, newStatus models.PipelineStatus) (bool, error)
+	// ListByStatus lists the pipelineruns of all clusters in the status, in the order they are created
+	ListByStatus(ctx context.Context, status models.PipelineStatus) ([]*models.Pipelinerun, error)
 	UpdateCIEventIDByID(ctx context.Context, pipelinerunID uint, ciEventID string) error
+	// UpdateByID update a pending pipelinerun with the fields determined when it starts executing
+	UpdateByID(ctx context.Context, pipelinerunID uint, pipelinerun *models.Pipelinerun) error
+	// UpdateReviewByID approve or reject a pending pipelinerun,
+	// returns ErrPipelinerunNotPending if it is not pending any more
+	UpdateReviewByID(ctx context.Context, pipelinerunID uint, status models.PipelineStatus,
+		reviewedBy uint, comment string) error
 	// UpdateResultByID  update the pipelinerun restore result
 	UpdateResultByID(ctx context.Context, pipelinerunID uint, result *models.Result) error
+	// ListBuilds list the latest successful builddeploy pipelineruns which built the commit
+	// of the git repo subfolder with the same build config, the latest one comes first
+	ListBuilds(ctx context.Context, gitURL, subfolder, commit, buildConfigHash string,
+		limit int) ([]*models.Pipelinerun, error)
 }
 
 type manager struct {
@@ -94,14 +110,37 @@ func (m *manager) UpdateStatusByID(ctx context.Context, pipelinerunID uint, resu
 	return m.dao.UpdateStatusByID(ctx, pipelinerunID, result)
 }
 
+func (m *manager) UpdateStatusByIDAndStatus(ctx context.Context, pipelinerunID uint,
+	status, newStatus models.PipelineStatus) (bool, error) {
+	return m.dao.UpdateStatusByIDAndStatus(ctx, pipelinerunID, status, newStatus)
+}
+
+func (m *manager) ListByStatus(ctx context.Context, status models.PipelineStatus) ([]*models.Pipelinerun, error) {
+	return m.dao.ListByStatus(ctx, status)
+}
+
 func (m *manager) UpdateCIEventIDByID(ctx context.Context, pipelinerunID uint, ciEventID string) error {
 	return m.dao.UpdateCIEventIDByID(ctx, pipelinerunID, ciEventID)
 }
 
+func (m *manager) UpdateByID(ctx context.Context, pipelinerunID uint, pipelinerun *models.Pipelinerun) error {
+	return m.dao.UpdateByID(ctx, pipelinerunID, pipelinerun)
+}
+
+func (m *manager) UpdateReviewByID(ctx context.Context, pipelinerunID uint, status models.PipelineStatus,
+	reviewedBy uint, comment string) error {
+	return m.dao.UpdateReviewByID(ctx, pipelinerunID, status, reviewedBy, comment)
+}
+
 func (m *manager) UpdateResultByID(ctx context.Context, pipelinerunID uint, result *models.Result) error {
 	return m.dao.UpdateResultByID(ctx, pipelinerunID, result)
 }
 
+func (m *manager) ListBuilds(ctx context.Context, gitURL, subfolder, commit, buildConfigHash string,
+	limit int) ([]*models.Pipelinerun, error) {
+	return m.dao.ListBuilds(ctx, gitURL, subfolder, commit, buildConfigHash, limit)
+}
+
 func (m *manager) GetByClusterID(ctx context.Context,
 	clusterID uint, canRollback bool, query q.Query) (int, []*models.Pipelinerun, error) {
 	return m.dao.GetByClusterID(ctx, clusterID, canRollback, query)
@@ -234,6 +234,40 @@ func TestGetFirstCanRollbackPipelinerun(t *testing.T) {
 	assert.Nil(t, pipelinerun)
 }
 
+func TestListBuilds(t *testing.T) {
+	var reuseFrom uint = 1
+	for _, pr := range []*models.Pipelinerun{
+		{ClusterID: 100, Action: models.ActionBuildDeploy, Status: string(models.StatusOK)},
+		{ClusterID: 100, Action: models.ActionBuildDeploy, Status: string(models.StatusFailed)},
+		{ClusterID: 101, Action: models.ActionBuildDeploy, Status: string(models.StatusOK)},
+		{ClusterID: 101, Action: models.ActionBuildDeploy, Status: string(models.StatusOK), ReuseFrom: &reuseFrom},
+		{ClusterID: 102, Action: models.ActionDeploy, Status: string(models.StatusOK)},
+		{ClusterID: 102, Action: models.ActionBuildDeploy, Status: string(models.StatusOK), BuildConfigHash: "hash2"},
+	} {
+		pr.GitURL = "ssh://git@cloudnative.com:22/demo/demo.git"
+		pr.GitSubfolder = "/app"
+		pr.GitCommit = "commit"
+		pr.ImageURL = "harbor.com/demo/demo:v1"
+		if pr.BuildConfigHash == "" {
+			pr.BuildConfigHash = "hash"
+		}
+		_, err := mgr.Create(ctx, pr)
+		assert.Nil(t, err)
+	}
+
+	builds, err := mgr.ListBuilds(ctx, "ssh://git@cloudnative.com:22/demo/demo.git", "/app",
+		"commit", "hash", 10)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(builds))
+	assert.Equal(t, uint(101), builds[0].ClusterID)
+	assert.Equal(t, uint(100), builds[1].ClusterID)
+
+	builds, err = mgr.ListBuilds(ctx, "ssh://git@cloudnative.com:22/demo/demo.git", "",
+		"commit", "hash", 10)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(builds))
+}
+
 func TestMain(m *testing.M) {
 	if err := db.AutoMigrate(&models.Pipelinerun{}); err != nil {
 		panic(err)
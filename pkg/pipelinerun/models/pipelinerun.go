@@ -23,11 +23,24 @@ const (
 	ActionDeploy      = "deploy"
 	ActionRestart     = "restart"
 	ActionRollback    = "rollback"
+	ActionPromote     = "promote"
+)
+
+// triggers of pipelineruns not created by users, pipelineruns created by users have an empty trigger
+const (
+	// TriggerScheduled is the trigger of pipelineruns created by schedules
+	TriggerScheduled = "scheduled"
+	// TriggerPush is the trigger of pipelineruns created by pushes to git repos
+	TriggerPush = "push"
 )
 
 type PipelineStatus string
 
 const (
+	StatusPending   PipelineStatus = "pending"
+	StatusQueued    PipelineStatus = "queued"
+	StatusApproved  PipelineStatus = "approved"
+	StatusRejected  PipelineStatus = "rejected"
 	StatusCreated   PipelineStatus = "created"
 	StatusCommitted PipelineStatus = "committed"
 	StatusMerged    PipelineStatus = "merged"
@@ -43,9 +56,12 @@ type Pipelinerun struct {
 	ID uint
 	// ClusterID cluster id which this pipelinerun belongs to
 	ClusterID uint
-	// Action type, which can be builddeploy, deploy, restart, rollback
+	// Action type, which can be builddeploy, deploy, restart, rollback, promote
 	Action string
-	// Status of this pipelinerun, which can be created, ok, failed, cancelled, unknown
+	// Trigger what triggered this pipelinerun, empty when it's created by a user
+	Trigger string
+	// Status of this pipelinerun, which can be pending, approved, rejected, queued, created, ok, failed, cancelled,
+	// unknown
 	Status string
 	// Title of this pipelinerun
 	Title string
@@ -59,6 +75,16 @@ type Pipelinerun struct {
 	GitRefType string
 	// GitCommit the git commit this pipelinerun to build with, can be empty when action is not builddeploy
 	GitCommit string
+	// GitCommitTime when the git commit is committed, the lead time of the change is measured from it
+	GitCommitTime *time.Time
+	// GitSubfolder the subfolder of the git repo this pipelinerun to build with
+	GitSubfolder string
+	// LastGitCommit the commit of the last build of the cluster which the code of this pipelinerun is compared with,
+	// only set when the cluster declares its build context
+	LastGitCommit string
+	// BuildConfigHash sha256 of the build config this pipelinerun to build with, builds are reused by
+	// pipelineruns with the same git url, subfolder, commit and build config hash
+	BuildConfigHash string
 	// ImageURL image url of this pipelinerun to build or deploy image
 	ImageURL string
 	// the two commit used to compare the config difference of this pipelinerun
@@ -78,6 +104,19 @@ type Pipelinerun struct {
 	FinishedAt *time.Time
 	// RollbackFrom which pipelinerun this pipelinerun rollback from
 	RollbackFrom *uint
+	// PromoteFrom which pipelinerun of the source cluster this pipelinerun promoted from
+	PromoteFrom *uint
+	// ReuseFrom which pipelinerun this pipelinerun reused the image of instead of building
+	ReuseFrom *uint
+	// Request json of the request of builddeploy or promote, which is executed again with it
+	// once this pipelinerun is approved or dequeued
+	Request string
+	// ReviewedBy who approved or rejected this pipelinerun, only set when the environment is protected
+	ReviewedBy *uint
+	// ReviewedAt when this pipelinerun was approved or rejected
+	ReviewedAt *time.Time
+	// ReviewComment comment left by the reviewer
+	ReviewComment string
 	// CIEventID event id returned from tekton-trigger EventListener
 	CIEventID string
 	CreatedAt time.Time
@@ -0,0 +1,61 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dao
+
+import (
+	"context"
+
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/pkg/pipelinerun/provenance/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type DAO interface {
+	// Upsert saves the provenance of a pipelinerun, the provenance is replaced if the pipelinerun is collected again
+	Upsert(ctx context.Context, provenance *models.Provenance) error
+	// GetByPipelinerunID gets the provenance of a pipelinerun
+	GetByPipelinerunID(ctx context.Context, pipelinerunID uint) (*models.Provenance, error)
+}
+
+type dao struct{ db *gorm.DB }
+
+func NewDAO(db *gorm.DB) DAO {
+	return &dao{db: db}
+}
+
+func (d *dao) Upsert(ctx context.Context, provenance *models.Provenance) error {
+	result := d.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "pipelinerun_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"predicate_type", "builder_id", "build_type",
+			"subjects", "materials", "statement", "signature", "updated_at"}),
+	}).Create(provenance)
+	if result.Error != nil {
+		return herrors.NewErrInsertFailed(herrors.PipelinerunProvenanceInDB, result.Error.Error())
+	}
+	return nil
+}
+
+func (d *dao) GetByPipelinerunID(ctx context.Context, pipelinerunID uint) (*models.Provenance, error) {
+	var provenance models.Provenance
+	result := d.db.WithContext(ctx).Where("pipelinerun_id = ?", pipelinerunID).Find(&provenance)
+	if result.Error != nil {
+		return nil, herrors.NewErrGetFailed(herrors.PipelinerunProvenanceInDB, result.Error.Error())
+	}
+	if result.RowsAffected == 0 {
+		return nil, herrors.NewErrNotFound(herrors.PipelinerunProvenanceInDB, "provenance not found")
+	}
+	return &provenance, nil
+}
@@ -0,0 +1,49 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manager
+
+import (
+	"context"
+
+	"github.com/horizoncd/horizon/pkg/pipelinerun/provenance/dao"
+	"github.com/horizoncd/horizon/pkg/pipelinerun/provenance/models"
+	"gorm.io/gorm"
+)
+
+// Manager manages the provenance of the pipelineruns signed by tekton chains
+type Manager interface {
+	// Upsert saves the provenance of a pipelinerun
+	Upsert(ctx context.Context, provenance *models.Provenance) error
+	// GetByPipelinerunID gets the provenance of a pipelinerun
+	GetByPipelinerunID(ctx context.Context, pipelinerunID uint) (*models.Provenance, error)
+}
+
+type manager struct {
+	dao dao.DAO
+}
+
+func New(db *gorm.DB) Manager {
+	return &manager{
+		dao: dao.NewDAO(db),
+	}
+}
+
+func (m *manager) Upsert(ctx context.Context, provenance *models.Provenance) error {
+	return m.dao.Upsert(ctx, provenance)
+}
+
+func (m *manager) GetByPipelinerunID(ctx context.Context, pipelinerunID uint) (*models.Provenance, error) {
+	return m.dao.GetByPipelinerunID(ctx, pipelinerunID)
+}
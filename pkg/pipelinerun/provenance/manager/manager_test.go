@@ -0,0 +1,70 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manager
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/lib/orm"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/pipelinerun/provenance/models"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	db, _ = orm.NewSqliteDB("")
+	ctx   = context.TODO()
+	mgr   = New(db)
+)
+
+func TestMain(m *testing.M) {
+	if err := db.AutoMigrate(&models.Provenance{}); err != nil {
+		panic(err)
+	}
+	os.Exit(m.Run())
+}
+
+func TestManager(t *testing.T) {
+	_, err := mgr.GetByPipelinerunID(ctx, 1)
+	_, ok := perror.Cause(err).(*herrors.HorizonErrNotFound)
+	assert.True(t, ok)
+
+	p := &models.Provenance{
+		PipelinerunID: 1,
+		ClusterID:     1,
+		BuilderID:     "https://tekton.dev/chains/v2",
+		Statement:     "{}",
+		Signature:     "c2lnbmF0dXJl",
+	}
+	subjects := []*models.Artifact{{Name: "harbor.com/app/cluster", Digest: map[string]string{"sha256": "abc"}}}
+	p.SetSubjects(subjects)
+	assert.Nil(t, mgr.Upsert(ctx, p))
+
+	got, err := mgr.GetByPipelinerunID(ctx, 1)
+	assert.Nil(t, err)
+	assert.Equal(t, "https://tekton.dev/chains/v2", got.BuilderID)
+	assert.Equal(t, subjects, got.GetSubjects())
+	assert.Equal(t, []*models.Artifact{}, got.GetMaterials())
+
+	// replaced when the pipelinerun is collected again
+	assert.Nil(t, mgr.Upsert(ctx, &models.Provenance{PipelinerunID: 1, ClusterID: 1, Signature: "bmV3"}))
+	got, err = mgr.GetByPipelinerunID(ctx, 1)
+	assert.Nil(t, err)
+	assert.Equal(t, "bmV3", got.Signature)
+	assert.Equal(t, "", got.BuilderID)
+}
@@ -0,0 +1,86 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Provenance is the SLSA provenance of a pipelinerun signed by tekton chains,
+// it tells where the images built by the pipelinerun came from
+type Provenance struct {
+	ID            uint
+	PipelinerunID uint `gorm:"uniqueIndex"`
+	ClusterID     uint
+	// PredicateType is the version of the SLSA provenance, e.g. https://slsa.dev/provenance/v0.2
+	PredicateType string
+	BuilderID     string
+	BuildType     string
+	// Subjects is the json of the artifacts built, e.g. the images with their digests
+	Subjects string
+	// Materials is the json of the sources the artifacts are built from, e.g. the git repo with its commit
+	Materials string
+	// Statement is the in-toto statement signed
+	Statement string
+	// Signature is the signature of the statement in base64
+	Signature string
+
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+func (Provenance) TableName() string {
+	return "tb_pipelinerun_provenance"
+}
+
+// Artifact is a subject or a material of the provenance
+type Artifact struct {
+	Name   string            `json:"name,omitempty"`
+	URI    string            `json:"uri,omitempty"`
+	Digest map[string]string `json:"digest,omitempty"`
+}
+
+func (p *Provenance) GetSubjects() []*Artifact {
+	return unmarshalArtifacts(p.Subjects)
+}
+
+func (p *Provenance) SetSubjects(subjects []*Artifact) {
+	p.Subjects = marshalArtifacts(subjects)
+}
+
+func (p *Provenance) GetMaterials() []*Artifact {
+	return unmarshalArtifacts(p.Materials)
+}
+
+func (p *Provenance) SetMaterials(materials []*Artifact) {
+	p.Materials = marshalArtifacts(materials)
+}
+
+func unmarshalArtifacts(s string) []*Artifact {
+	artifacts := make([]*Artifact, 0)
+	if s != "" {
+		_ = json.Unmarshal([]byte(s), &artifacts)
+	}
+	return artifacts
+}
+
+func marshalArtifacts(artifacts []*Artifact) string {
+	if len(artifacts) == 0 {
+		return ""
+	}
+	b, _ := json.Marshal(artifacts)
+	return string(b)
+}
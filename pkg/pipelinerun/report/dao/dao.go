@@ -0,0 +1,71 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dao
+
+import (
+	"context"
+
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/pkg/pipelinerun/report/models"
+	"gorm.io/gorm"
+)
+
+type DAO interface {
+	// Create creates the reports of a pipelinerun
+	Create(ctx context.Context, reports []*models.Report) error
+	// GetByID gets a report
+	GetByID(ctx context.Context, id uint) (*models.Report, error)
+	// ListByPipelinerunID lists the reports of a pipelinerun
+	ListByPipelinerunID(ctx context.Context, pipelinerunID uint) ([]*models.Report, error)
+}
+
+type dao struct{ db *gorm.DB }
+
+func NewDAO(db *gorm.DB) DAO {
+	return &dao{db: db}
+}
+
+func (d *dao) Create(ctx context.Context, reports []*models.Report) error {
+	if len(reports) == 0 {
+		return nil
+	}
+	result := d.db.WithContext(ctx).Create(reports)
+	if result.Error != nil {
+		return herrors.NewErrInsertFailed(herrors.PipelinerunReportInDB, result.Error.Error())
+	}
+	return nil
+}
+
+func (d *dao) GetByID(ctx context.Context, id uint) (*models.Report, error) {
+	var report models.Report
+	result := d.db.WithContext(ctx).Where("id = ?", id).Find(&report)
+	if result.Error != nil {
+		return nil, herrors.NewErrGetFailed(herrors.PipelinerunReportInDB, result.Error.Error())
+	}
+	if result.RowsAffected == 0 {
+		return nil, herrors.NewErrNotFound(herrors.PipelinerunReportInDB, "report not found")
+	}
+	return &report, nil
+}
+
+func (d *dao) ListByPipelinerunID(ctx context.Context, pipelinerunID uint) ([]*models.Report, error) {
+	var reports []*models.Report
+	result := d.db.WithContext(ctx).Where("pipelinerun_id = ?", pipelinerunID).
+		Order("task asc, name asc").Find(&reports)
+	if result.Error != nil {
+		return nil, herrors.NewErrListFailed(herrors.PipelinerunReportInDB, result.Error.Error())
+	}
+	return reports, nil
+}
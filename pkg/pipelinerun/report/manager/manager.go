@@ -0,0 +1,55 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manager
+
+import (
+	"context"
+
+	"github.com/horizoncd/horizon/pkg/pipelinerun/report/dao"
+	"github.com/horizoncd/horizon/pkg/pipelinerun/report/models"
+	"gorm.io/gorm"
+)
+
+// Manager manages the reports produced by pipelineruns
+type Manager interface {
+	// Create creates the reports of a pipelinerun
+	Create(ctx context.Context, reports []*models.Report) error
+	// GetByID gets a report
+	GetByID(ctx context.Context, id uint) (*models.Report, error)
+	// ListByPipelinerunID lists the reports of a pipelinerun
+	ListByPipelinerunID(ctx context.Context, pipelinerunID uint) ([]*models.Report, error)
+}
+
+type manager struct {
+	dao dao.DAO
+}
+
+func New(db *gorm.DB) Manager {
+	return &manager{
+		dao: dao.NewDAO(db),
+	}
+}
+
+func (m *manager) Create(ctx context.Context, reports []*models.Report) error {
+	return m.dao.Create(ctx, reports)
+}
+
+func (m *manager) GetByID(ctx context.Context, id uint) (*models.Report, error) {
+	return m.dao.GetByID(ctx, id)
+}
+
+func (m *manager) ListByPipelinerunID(ctx context.Context, pipelinerunID uint) ([]*models.Report, error) {
+	return m.dao.ListByPipelinerunID(ctx, pipelinerunID)
+}
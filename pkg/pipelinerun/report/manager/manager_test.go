@@ -0,0 +1,93 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manager
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/lib/orm"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/pipelinerun/report/models"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	db, _ = orm.NewSqliteDB("")
+	ctx   = context.TODO()
+	mgr   = New(db)
+)
+
+func TestMain(m *testing.M) {
+	if err := db.AutoMigrate(&models.Report{}); err != nil {
+		panic(err)
+	}
+	os.Exit(m.Run())
+}
+
+func TestManager(t *testing.T) {
+	err := mgr.Create(ctx, nil)
+	assert.Nil(t, err)
+
+	err = mgr.Create(ctx, []*models.Report{
+		{
+			PipelinerunID: 1,
+			ClusterID:     1,
+			Task:          "test",
+			Name:          "junit-report",
+			Kind:          models.KindTest,
+			Format:        models.FormatJUnit,
+			Object:        "object",
+			Summary:       models.Summary{Tests: 10, Failures: 2},
+		},
+		{
+			PipelinerunID: 1,
+			ClusterID:     1,
+			Task:          "scan",
+			Name:          "trivy-report",
+			Kind:          models.KindVulnerability,
+			Format:        models.FormatTrivy,
+			Summary:       models.Summary{Critical: 1, High: 3},
+		},
+		{
+			PipelinerunID: 2,
+			ClusterID:     1,
+			Task:          "test",
+			Name:          "junit-report",
+			Kind:          models.KindTest,
+			Format:        models.FormatJUnit,
+		},
+	})
+	assert.Nil(t, err)
+
+	reports, err := mgr.ListByPipelinerunID(ctx, 1)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(reports))
+	assert.Equal(t, "scan", reports[0].Task)
+	assert.Equal(t, 1, reports[0].Critical)
+	assert.Equal(t, "test", reports[1].Task)
+	assert.Equal(t, 10, reports[1].Tests)
+
+	report, err := mgr.GetByID(ctx, reports[1].ID)
+	assert.Nil(t, err)
+	assert.Equal(t, "object", report.Object)
+	assert.Equal(t, 2, report.Failures)
+
+	_, err = mgr.GetByID(ctx, 100)
+	_, ok := perror.Cause(err).(*herrors.HorizonErrNotFound)
+	assert.True(t, ok)
+}
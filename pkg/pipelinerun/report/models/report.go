@@ -0,0 +1,73 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package models
+
+import "time"
+
+// kinds of reports
+const (
+	KindTest          = "test"
+	KindCoverage      = "coverage"
+	KindVulnerability = "vulnerability"
+)
+
+// formats of reports
+const (
+	FormatJUnit     = "junit"
+	FormatCobertura = "cobertura"
+	FormatSARIF     = "sarif"
+	FormatTrivy     = "trivy"
+)
+
+// Report is a structured report produced by a task of the pipelinerun,
+// the report file is kept in Object and its summary is saved in columns to be queried
+type Report struct {
+	ID            uint
+	PipelinerunID uint
+	ClusterID     uint
+	Task          string
+	// Name is the name of the task result the report is read from
+	Name   string
+	Kind   string
+	Format string
+	// Object is the object of the report file in s3, empty when there is no storage to keep it
+	Object string
+	Summary
+
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+func (Report) TableName() string {
+	return "tb_pipelinerun_report"
+}
+
+// Summary is the summary of a report, only the fields of the report kind are set
+type Summary struct {
+	// test
+	Tests    int `json:"tests"`
+	Failures int `json:"failures"`
+	Errors   int `json:"errors"`
+	Skipped  int `json:"skipped"`
+	// coverage, in percent
+	LineCoverage   float64 `json:"lineCoverage"`
+	BranchCoverage float64 `json:"branchCoverage"`
+	// vulnerability
+	Critical int `json:"critical"`
+	High     int `json:"high"`
+	Medium   int `json:"medium"`
+	Low      int `json:"low"`
+	Unknown  int `json:"unknown"`
+}
@@ -0,0 +1,71 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dao
+
+import (
+	"context"
+
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/pkg/common"
+	"github.com/horizoncd/horizon/pkg/promotion/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type DAO interface {
+	GetByApplicationID(ctx context.Context, applicationID uint) (*models.PromotionPath, error)
+	UpsertByApplicationID(ctx context.Context, path *models.PromotionPath) error
+	DeleteByApplicationID(ctx context.Context, applicationID uint) error
+}
+
+type dao struct {
+	db *gorm.DB
+}
+
+func NewDAO(db *gorm.DB) DAO {
+	return &dao{db: db}
+}
+
+func (d *dao) GetByApplicationID(ctx context.Context, applicationID uint) (*models.PromotionPath, error) {
+	var path models.PromotionPath
+	result := d.db.WithContext(ctx).Raw(common.PromotionPathGetByApplicationID, applicationID).First(&path)
+	if result.Error != nil {
+		if result.Error == gorm.ErrRecordNotFound {
+			return nil, herrors.NewErrNotFound(herrors.PromotionPathInDB, result.Error.Error())
+		}
+		return nil, herrors.NewErrGetFailed(herrors.PromotionPathInDB, result.Error.Error())
+	}
+	return &path, nil
+}
+
+func (d *dao) UpsertByApplicationID(ctx context.Context, path *models.PromotionPath) error {
+	result := d.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "application_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"environments", "updated_by", "updated_at"}),
+	}).Create(path)
+	if result.Error != nil {
+		return herrors.NewErrInsertFailed(herrors.PromotionPathInDB, result.Error.Error())
+	}
+	return nil
+}
+
+func (d *dao) DeleteByApplicationID(ctx context.Context, applicationID uint) error {
+	result := d.db.WithContext(ctx).Exec(common.PromotionPathDeleteByApplicationID, applicationID)
+	if result.Error != nil {
+		return herrors.NewErrDeleteFailed(herrors.PromotionPathInDB, result.Error.Error())
+	}
+	return nil
+}
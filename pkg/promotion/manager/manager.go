@@ -0,0 +1,61 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manager
+
+import (
+	"context"
+
+	"github.com/horizoncd/horizon/pkg/promotion/dao"
+	"github.com/horizoncd/horizon/pkg/promotion/models"
+	"github.com/horizoncd/horizon/pkg/util/wlog"
+	"gorm.io/gorm"
+)
+
+type Manager interface {
+	// GetByApplicationID returns the promotion path configured for the application
+	GetByApplicationID(ctx context.Context, applicationID uint) (*models.PromotionPath, error)
+	// UpsertByApplicationID creates or replaces the promotion path of an application
+	UpsertByApplicationID(ctx context.Context, path *models.PromotionPath) error
+	// DeleteByApplicationID removes the promotion path of an application
+	DeleteByApplicationID(ctx context.Context, applicationID uint) error
+}
+
+func New(db *gorm.DB) Manager {
+	return &manager{
+		dao: dao.NewDAO(db),
+	}
+}
+
+type manager struct {
+	dao dao.DAO
+}
+
+func (m *manager) GetByApplicationID(ctx context.Context, applicationID uint) (*models.PromotionPath, error) {
+	const op = "promotion path manager: get by application id"
+	defer wlog.Start(ctx, op).StopPrint()
+	return m.dao.GetByApplicationID(ctx, applicationID)
+}
+
+func (m *manager) UpsertByApplicationID(ctx context.Context, path *models.PromotionPath) error {
+	const op = "promotion path manager: upsert by application id"
+	defer wlog.Start(ctx, op).StopPrint()
+	return m.dao.UpsertByApplicationID(ctx, path)
+}
+
+func (m *manager) DeleteByApplicationID(ctx context.Context, applicationID uint) error {
+	const op = "promotion path manager: delete by application id"
+	defer wlog.Start(ctx, op).StopPrint()
+	return m.dao.DeleteByApplicationID(ctx, applicationID)
+}
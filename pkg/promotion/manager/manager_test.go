@@ -0,0 +1,86 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manager
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/lib/orm"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/promotion/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	db, _ = orm.NewSqliteDB("")
+	ctx   context.Context
+	mgr   = New(db)
+)
+
+func TestMain(m *testing.M) {
+	if err := db.AutoMigrate(&models.PromotionPath{}); err != nil {
+		panic(err)
+	}
+	ctx = context.TODO()
+	os.Exit(m.Run())
+}
+
+func Test(t *testing.T) {
+	applicationID := uint(1)
+	_, err := mgr.GetByApplicationID(ctx, applicationID)
+	_, ok := perror.Cause(err).(*herrors.HorizonErrNotFound)
+	assert.True(t, ok)
+
+	err = mgr.UpsertByApplicationID(ctx, &models.PromotionPath{
+		ApplicationID: applicationID,
+		Environments:  models.JoinEnvironments([]string{"test", "reg", "pre", "online"}),
+		CreatedBy:     1,
+		UpdatedBy:     1,
+	})
+	assert.Nil(t, err)
+
+	path, err := mgr.GetByApplicationID(ctx, applicationID)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"test", "reg", "pre", "online"}, path.Stages())
+	next, ok := path.Next("reg")
+	assert.True(t, ok)
+	assert.Equal(t, "pre", next)
+	_, ok = path.Next("online")
+	assert.False(t, ok)
+	_, ok = path.Next("perf")
+	assert.False(t, ok)
+
+	err = mgr.UpsertByApplicationID(ctx, &models.PromotionPath{
+		ApplicationID: applicationID,
+		Environments:  models.JoinEnvironments([]string{"test", "online"}),
+		CreatedBy:     2,
+		UpdatedBy:     2,
+	})
+	assert.Nil(t, err)
+	path, err = mgr.GetByApplicationID(ctx, applicationID)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"test", "online"}, path.Stages())
+	assert.Equal(t, uint(2), path.UpdatedBy)
+	assert.Equal(t, uint(1), path.CreatedBy)
+
+	err = mgr.DeleteByApplicationID(ctx, applicationID)
+	assert.Nil(t, err)
+	_, err = mgr.GetByApplicationID(ctx, applicationID)
+	assert.NotNil(t, err)
+}
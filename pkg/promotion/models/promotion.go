@@ -0,0 +1,57 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+const EnvironmentSeparator = ","
+
+// PromotionPath is the ordered list of environments an application's release is promoted through,
+// e.g. test -> reg -> pre -> online.
+type PromotionPath struct {
+	ID            uint
+	ApplicationID uint `gorm:"uniqueIndex:idx_application_id"`
+	// Environments environment names joined by EnvironmentSeparator, in promotion order
+	Environments string
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+	CreatedBy    uint
+	UpdatedBy    uint
+}
+
+func (p *PromotionPath) Stages() []string {
+	if p == nil || p.Environments == "" {
+		return []string{}
+	}
+	return strings.Split(p.Environments, EnvironmentSeparator)
+}
+
+// Next returns the environment that follows env in the path, false if env is the last one or not in the path
+func (p *PromotionPath) Next(env string) (string, bool) {
+	stages := p.Stages()
+	for i, stage := range stages {
+		if stage == env && i+1 < len(stages) {
+			return stages[i+1], true
+		}
+	}
+	return "", false
+}
+
+func JoinEnvironments(environments []string) string {
+	return strings.Join(environments, EnvironmentSeparator)
+}
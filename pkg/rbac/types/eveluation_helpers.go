@@ -24,7 +24,7 @@ import (
 
 func VerbMatches(rule *PolicyRule, requestedVerb string) bool {
 	for _, rulesVerb := range rule.Verbs {
-		if rulesVerb == VerbAll {
+		if rulesVerb == VerbAll && requestedVerb != VerbFreezeOverride {
 			return true
 		}
 		if rulesVerb == requestedVerb {
@@ -25,6 +25,10 @@ const (
 	NonResourceAll = "*"
 )
 
+// VerbFreezeOverride allows mutating clusters during an active freeze window,
+// it must be granted explicitly and is never matched by VerbAll
+const VerbFreezeOverride = "freeze-override"
+
 type Role struct {
 	Name        string       `yaml:"name" json:"name"`
 	Desc        string       `yaml:"desc" json:"desc"`
@@ -262,6 +262,40 @@ func TestRuleAllow(t *testing.T) {
 				NonResourceURLs: []string{"/apis/front/*"},
 			},
 			allowed: false,
+		}, {
+			// wizard case deny (freeze-override is never matched by *)
+			attr: auth.AttributesRecord{
+				User:            &testUser,
+				Verb:            VerbFreezeOverride,
+				APIGroup:        "core",
+				Resource:        "clusters",
+				Scope:           "online/hz",
+				ResourceRequest: true,
+			},
+			policy: PolicyRule{
+				Verbs:     []string{"*"},
+				APIGroups: []string{"*"},
+				Resources: []string{"*"},
+				Scopes:    []string{"*"},
+			},
+			allowed: false,
+		}, {
+			// case list case pass (explicit freeze-override)
+			attr: auth.AttributesRecord{
+				User:            &testUser,
+				Verb:            VerbFreezeOverride,
+				APIGroup:        "core",
+				Resource:        "clusters",
+				Scope:           "online/hz",
+				ResourceRequest: true,
+			},
+			policy: PolicyRule{
+				Verbs:     []string{VerbFreezeOverride},
+				APIGroups: []string{"core"},
+				Resources: []string{"clusters"},
+				Scopes:    []string{"*"},
+			},
+			allowed: true,
 		},
 	}
 
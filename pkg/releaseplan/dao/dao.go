@@ -0,0 +1,165 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dao
+
+import (
+	"context"
+
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/lib/q"
+	"github.com/horizoncd/horizon/pkg/common"
+	"github.com/horizoncd/horizon/pkg/releaseplan/models"
+
+	"gorm.io/gorm"
+)
+
+type DAO interface {
+	Create(ctx context.Context, plan *models.ReleasePlan,
+		clusters []*models.ReleasePlanCluster) (*models.ReleasePlan, error)
+	GetByID(ctx context.Context, id uint) (*models.ReleasePlan, error)
+	ListByApplicationID(ctx context.Context, applicationID uint,
+		query *q.Query) ([]*models.ReleasePlan, int64, error)
+	ListByStatus(ctx context.Context, status string) ([]*models.ReleasePlan, error)
+	CountActiveByApplicationID(ctx context.Context, applicationID uint) (int64, error)
+	UpdateStatusByID(ctx context.Context, id uint, statuses []string, plan *models.ReleasePlan) (bool, error)
+	UpdateProgressByID(ctx context.Context, id uint, statuses []string, plan *models.ReleasePlan) (bool, error)
+	ListClustersByPlanID(ctx context.Context, planID uint) ([]*models.ReleasePlanCluster, error)
+	UpdateClusterByID(ctx context.Context, id uint, cluster *models.ReleasePlanCluster) error
+}
+
+type dao struct {
+	db *gorm.DB
+}
+
+func NewDAO(db *gorm.DB) DAO {
+	return &dao{db: db}
+}
+
+func (d *dao) Create(ctx context.Context, plan *models.ReleasePlan,
+	clusters []*models.ReleasePlanCluster) (*models.ReleasePlan, error) {
+	err := d.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if result := tx.Create(plan); result.Error != nil {
+			return herrors.NewErrInsertFailed(herrors.ReleasePlanInDB, result.Error.Error())
+		}
+		for _, cluster := range clusters {
+			cluster.PlanID = plan.ID
+		}
+		if len(clusters) == 0 {
+			return nil
+		}
+		if result := tx.Create(clusters); result.Error != nil {
+			return herrors.NewErrInsertFailed(herrors.ReleasePlanInDB, result.Error.Error())
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return plan, nil
+}
+
+func (d *dao) GetByID(ctx context.Context, id uint) (*models.ReleasePlan, error) {
+	var plan models.ReleasePlan
+	result := d.db.WithContext(ctx).Raw(common.ReleasePlanGetByID, id).Scan(&plan)
+	if result.Error != nil {
+		return nil, herrors.NewErrGetFailed(herrors.ReleasePlanInDB, result.Error.Error())
+	}
+	if result.RowsAffected == 0 {
+		return nil, herrors.NewErrNotFound(herrors.ReleasePlanInDB, "release plan not found")
+	}
+	return &plan, nil
+}
+
+func (d *dao) ListByApplicationID(ctx context.Context, applicationID uint,
+	query *q.Query) ([]*models.ReleasePlan, int64, error) {
+	var (
+		plans []*models.ReleasePlan
+		count int64
+	)
+	statement := d.db.WithContext(ctx).Model(&models.ReleasePlan{}).
+		Where("application_id = ?", applicationID)
+	if result := statement.Count(&count); result.Error != nil {
+		return nil, 0, herrors.NewErrGetFailed(herrors.ReleasePlanInDB, result.Error.Error())
+	}
+	if query != nil {
+		statement = statement.Limit(query.Limit()).Offset(query.Offset())
+	}
+	if result := statement.Order("id desc").Find(&plans); result.Error != nil {
+		return nil, 0, herrors.NewErrGetFailed(herrors.ReleasePlanInDB, result.Error.Error())
+	}
+	return plans, count, nil
+}
+
+func (d *dao) ListByStatus(ctx context.Context, status string) ([]*models.ReleasePlan, error) {
+	var plans []*models.ReleasePlan
+	result := d.db.WithContext(ctx).Raw(common.ReleasePlanListByStatus, status).Scan(&plans)
+	if result.Error != nil {
+		return nil, herrors.NewErrGetFailed(herrors.ReleasePlanInDB, result.Error.Error())
+	}
+	return plans, nil
+}
+
+func (d *dao) CountActiveByApplicationID(ctx context.Context, applicationID uint) (int64, error) {
+	var count int64
+	result := d.db.WithContext(ctx).Raw(common.ReleasePlanCountByApplicationIDAndStatuses, applicationID,
+		[]string{models.StatusRunning, models.StatusPaused}).Scan(&count)
+	if result.Error != nil {
+		return 0, herrors.NewErrGetFailed(herrors.ReleasePlanInDB, result.Error.Error())
+	}
+	return count, nil
+}
+
+// UpdateStatusByID updates the status of the plan only if its status is one of statuses,
+// so that concurrent transitions do not override each other
+func (d *dao) UpdateStatusByID(ctx context.Context, id uint, statuses []string,
+	plan *models.ReleasePlan) (bool, error) {
+	return d.updateIfStatusIn(ctx, id, statuses, plan, "Status", "Message", "UpdatedBy")
+}
+
+// UpdateProgressByID updates the current wave and the status of the plan only if its status is one of statuses
+func (d *dao) UpdateProgressByID(ctx context.Context, id uint, statuses []string,
+	plan *models.ReleasePlan) (bool, error) {
+	return d.updateIfStatusIn(ctx, id, statuses, plan, "CurrentWave", "WaveFinishedAt", "Status", "Message")
+}
+
+func (d *dao) updateIfStatusIn(ctx context.Context, id uint, statuses []string,
+	plan *models.ReleasePlan, columns ...string) (bool, error) {
+	result := d.db.WithContext(ctx).Model(&models.ReleasePlan{}).
+		Where("id = ? and status in ?", id, statuses).
+		Select(columns).
+		Updates(plan)
+	if result.Error != nil {
+		return false, herrors.NewErrUpdateFailed(herrors.ReleasePlanInDB, result.Error.Error())
+	}
+	return result.RowsAffected > 0, nil
+}
+
+func (d *dao) ListClustersByPlanID(ctx context.Context, planID uint) ([]*models.ReleasePlanCluster, error) {
+	var clusters []*models.ReleasePlanCluster
+	result := d.db.WithContext(ctx).Raw(common.ReleasePlanListClustersByPlanID, planID).Scan(&clusters)
+	if result.Error != nil {
+		return nil, herrors.NewErrGetFailed(herrors.ReleasePlanInDB, result.Error.Error())
+	}
+	return clusters, nil
+}
+
+func (d *dao) UpdateClusterByID(ctx context.Context, id uint, cluster *models.ReleasePlanCluster) error {
+	result := d.db.WithContext(ctx).Model(&models.ReleasePlanCluster{}).Where("id = ?", id).
+		Select("PipelinerunID", "Status", "Message", "DeployedAt").Updates(cluster)
+	if result.Error != nil {
+		return herrors.NewErrUpdateFailed(herrors.ReleasePlanInDB, result.Error.Error())
+	}
+	return nil
+}
@@ -0,0 +1,114 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manager
+
+import (
+	"context"
+
+	"github.com/horizoncd/horizon/lib/q"
+	"github.com/horizoncd/horizon/pkg/releaseplan/dao"
+	"github.com/horizoncd/horizon/pkg/releaseplan/models"
+	"github.com/horizoncd/horizon/pkg/util/wlog"
+	"gorm.io/gorm"
+)
+
+type Manager interface {
+	// Create creates the plan together with the clusters it releases
+	Create(ctx context.Context, plan *models.ReleasePlan,
+		clusters []*models.ReleasePlanCluster) (*models.ReleasePlan, error)
+	GetByID(ctx context.Context, id uint) (*models.ReleasePlan, error)
+	ListByApplicationID(ctx context.Context, applicationID uint,
+		query *q.Query) ([]*models.ReleasePlan, int64, error)
+	ListRunning(ctx context.Context) ([]*models.ReleasePlan, error)
+	// CountActiveByApplicationID counts the running and paused plans of the application
+	CountActiveByApplicationID(ctx context.Context, applicationID uint) (int64, error)
+	// UpdateStatusByID updates the status, message and updater of the plan if its status is one of statuses,
+	// false is returned if the plan is not in these statuses
+	UpdateStatusByID(ctx context.Context, id uint, statuses []string, plan *models.ReleasePlan) (bool, error)
+	// UpdateProgressByID updates the current wave, status and message of the plan
+	// if its status is one of statuses, false is returned if the plan is not in these statuses
+	UpdateProgressByID(ctx context.Context, id uint, statuses []string, plan *models.ReleasePlan) (bool, error)
+	// ListClustersByPlanID lists the clusters of the plan, in wave order
+	ListClustersByPlanID(ctx context.Context, planID uint) ([]*models.ReleasePlanCluster, error)
+	UpdateClusterByID(ctx context.Context, id uint, cluster *models.ReleasePlanCluster) error
+}
+
+type manager struct {
+	dao dao.DAO
+}
+
+func New(db *gorm.DB) Manager {
+	return &manager{
+		dao: dao.NewDAO(db),
+	}
+}
+
+func (m *manager) Create(ctx context.Context, plan *models.ReleasePlan,
+	clusters []*models.ReleasePlanCluster) (*models.ReleasePlan, error) {
+	const op = "release plan manager: create"
+	defer wlog.Start(ctx, op).StopPrint()
+	return m.dao.Create(ctx, plan, clusters)
+}
+
+func (m *manager) GetByID(ctx context.Context, id uint) (*models.ReleasePlan, error) {
+	const op = "release plan manager: get by id"
+	defer wlog.Start(ctx, op).StopPrint()
+	return m.dao.GetByID(ctx, id)
+}
+
+func (m *manager) ListByApplicationID(ctx context.Context, applicationID uint,
+	query *q.Query) ([]*models.ReleasePlan, int64, error) {
+	const op = "release plan manager: list by application id"
+	defer wlog.Start(ctx, op).StopPrint()
+	return m.dao.ListByApplicationID(ctx, applicationID, query)
+}
+
+func (m *manager) ListRunning(ctx context.Context) ([]*models.ReleasePlan, error) {
+	const op = "release plan manager: list running"
+	defer wlog.Start(ctx, op).StopPrint()
+	return m.dao.ListByStatus(ctx, models.StatusRunning)
+}
+
+func (m *manager) CountActiveByApplicationID(ctx context.Context, applicationID uint) (int64, error) {
+	const op = "release plan manager: count active by application id"
+	defer wlog.Start(ctx, op).StopPrint()
+	return m.dao.CountActiveByApplicationID(ctx, applicationID)
+}
+
+func (m *manager) UpdateStatusByID(ctx context.Context, id uint, statuses []string,
+	plan *models.ReleasePlan) (bool, error) {
+	const op = "release plan manager: update status by id"
+	defer wlog.Start(ctx, op).StopPrint()
+	return m.dao.UpdateStatusByID(ctx, id, statuses, plan)
+}
+
+func (m *manager) UpdateProgressByID(ctx context.Context, id uint, statuses []string,
+	plan *models.ReleasePlan) (bool, error) {
+	const op = "release plan manager: update progress by id"
+	defer wlog.Start(ctx, op).StopPrint()
+	return m.dao.UpdateProgressByID(ctx, id, statuses, plan)
+}
+
+func (m *manager) ListClustersByPlanID(ctx context.Context, planID uint) ([]*models.ReleasePlanCluster, error) {
+	const op = "release plan manager: list clusters by plan id"
+	defer wlog.Start(ctx, op).StopPrint()
+	return m.dao.ListClustersByPlanID(ctx, planID)
+}
+
+func (m *manager) UpdateClusterByID(ctx context.Context, id uint, cluster *models.ReleasePlanCluster) error {
+	const op = "release plan manager: update cluster by id"
+	defer wlog.Start(ctx, op).StopPrint()
+	return m.dao.UpdateClusterByID(ctx, id, cluster)
+}
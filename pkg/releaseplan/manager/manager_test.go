@@ -0,0 +1,122 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manager
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/lib/orm"
+	"github.com/horizoncd/horizon/lib/q"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/releaseplan/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	db, _ = orm.NewSqliteDB("")
+	ctx   context.Context
+	mgr   = New(db)
+)
+
+func TestMain(m *testing.M) {
+	if err := db.AutoMigrate(&models.ReleasePlan{}, &models.ReleasePlanCluster{}); err != nil {
+		panic(err)
+	}
+	ctx = context.TODO()
+	os.Exit(m.Run())
+}
+
+func Test(t *testing.T) {
+	_, err := mgr.GetByID(ctx, 1)
+	_, ok := perror.Cause(err).(*herrors.HorizonErrNotFound)
+	assert.True(t, ok)
+
+	plan := &models.ReleasePlan{
+		ApplicationID: 1,
+		Environment:   "online",
+		Title:         "release v1",
+		Status:        models.StatusPending,
+	}
+	plan.SetWaves([]models.Wave{{Name: "canary", ClusterIDs: []uint{1}, Pause: "10m"},
+		{Name: "rest", ClusterIDs: []uint{2, 3}}})
+	plan, err = mgr.Create(ctx, plan, []*models.ReleasePlanCluster{
+		{ClusterID: 2, Wave: 1, Status: models.ClusterStatusPending},
+		{ClusterID: 1, Wave: 0, Status: models.ClusterStatusPending},
+		{ClusterID: 3, Wave: 1, Status: models.ClusterStatusPending},
+	})
+	assert.Nil(t, err)
+
+	plan, err = mgr.GetByID(ctx, plan.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, "10m", plan.GetWaves()[0].Pause)
+
+	clusters, err := mgr.ListClustersByPlanID(ctx, plan.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, 3, len(clusters))
+	assert.Equal(t, uint(1), clusters[0].ClusterID)
+
+	now := time.Now()
+	clusters[0].Status = models.ClusterStatusDeploying
+	clusters[0].PipelinerunID = 10
+	clusters[0].DeployedAt = &now
+	assert.Nil(t, mgr.UpdateClusterByID(ctx, clusters[0].ID, clusters[0]))
+	clusters, err = mgr.ListClustersByPlanID(ctx, plan.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, uint(10), clusters[0].PipelinerunID)
+	assert.NotNil(t, clusters[0].DeployedAt)
+
+	// transitions only happen from the expected statuses
+	plan.Status = models.StatusRunning
+	updated, err := mgr.UpdateStatusByID(ctx, plan.ID, []string{models.StatusPaused}, plan)
+	assert.Nil(t, err)
+	assert.False(t, updated)
+	updated, err = mgr.UpdateStatusByID(ctx, plan.ID, []string{models.StatusPending}, plan)
+	assert.Nil(t, err)
+	assert.True(t, updated)
+
+	// the status update does not touch the progress
+	plan.CurrentWave = 1
+	plan.WaveFinishedAt = &now
+	updated, err = mgr.UpdateStatusByID(ctx, plan.ID, []string{models.StatusRunning}, plan)
+	assert.Nil(t, err)
+	assert.True(t, updated)
+	plan, err = mgr.GetByID(ctx, plan.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, plan.CurrentWave)
+	plan.CurrentWave = 1
+	updated, err = mgr.UpdateProgressByID(ctx, plan.ID, []string{models.StatusRunning}, plan)
+	assert.Nil(t, err)
+	assert.True(t, updated)
+	plan, err = mgr.GetByID(ctx, plan.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, plan.CurrentWave)
+
+	running, err := mgr.ListRunning(ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(running))
+	count, err := mgr.CountActiveByApplicationID(ctx, 1)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1), count)
+
+	plans, total, err := mgr.ListByApplicationID(ctx, 1, &q.Query{PageNumber: 1, PageSize: 10})
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1), total)
+	assert.Equal(t, models.StatusRunning, plans[0].Status)
+}
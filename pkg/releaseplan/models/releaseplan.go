@@ -0,0 +1,105 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// statuses of a release plan
+const (
+	StatusPending   = "pending"
+	StatusRunning   = "running"
+	StatusPaused    = "paused"
+	StatusSucceeded = "succeeded"
+	StatusFailed    = "failed"
+	StatusAborted   = "aborted"
+)
+
+// statuses of a cluster in a release plan
+const (
+	ClusterStatusPending   = "pending"
+	ClusterStatusDeploying = "deploying"
+	ClusterStatusHealthy   = "healthy"
+	ClusterStatusFailed    = "failed"
+)
+
+// ReleasePlan deploys clusters of an application in one environment wave by wave,
+// the next wave is released only after all clusters of the current one are healthy
+type ReleasePlan struct {
+	ID            uint
+	ApplicationID uint
+	Environment   string
+	Title         string
+	Description   string
+	// ImageTag is the image tag deployed to clusters built from images, keeps the current tag if empty
+	ImageTag string
+	// Waves is the json of []Wave
+	Waves string
+	// CurrentWave is the index of the wave being released
+	CurrentWave int
+	// WaveFinishedAt is when all clusters of the current wave became healthy
+	WaveFinishedAt *time.Time
+	Status         string
+	Message        string
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+	CreatedBy      uint
+	UpdatedBy      uint
+}
+
+// Wave is a batch of clusters released at the same time
+type Wave struct {
+	Name       string `json:"name"`
+	ClusterIDs []uint `json:"clusterIDs"`
+	// Pause is how long the wave is observed after it became healthy, e.g. 10m
+	Pause string `json:"pause,omitempty"`
+	// ManualResume pauses the plan after the wave until it is resumed
+	ManualResume bool `json:"manualResume,omitempty"`
+}
+
+// ReleasePlanCluster tracks the deployment of a cluster in a release plan
+type ReleasePlanCluster struct {
+	ID            uint
+	PlanID        uint
+	ClusterID     uint
+	Wave          int
+	PipelinerunID uint
+	Status        string
+	Message       string
+	DeployedAt    *time.Time
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+}
+
+func (p *ReleasePlan) GetWaves() []Wave {
+	var waves []Wave
+	if p.Waves == "" {
+		return waves
+	}
+	_ = json.Unmarshal([]byte(p.Waves), &waves)
+	return waves
+}
+
+func (p *ReleasePlan) SetWaves(waves []Wave) {
+	bts, _ := json.Marshal(waves)
+	p.Waves = string(bts)
+}
+
+// IsActive tells whether the plan is started but not finished yet
+func (p *ReleasePlan) IsActive() bool {
+	return p.Status == StatusRunning || p.Status == StatusPaused
+}
@@ -0,0 +1,83 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dao
+
+import (
+	"context"
+	"time"
+
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/pkg/common"
+	"github.com/horizoncd/horizon/pkg/runqueue/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type DAO interface {
+	GetByClusterID(ctx context.Context, clusterID uint) (*models.RunQueue, error)
+	UpsertPolicy(ctx context.Context, runQueue *models.RunQueue) error
+	Transfer(ctx context.Context, clusterID, fromPipelinerunID, toPipelinerunID uint, lockedAt time.Time) (bool, error)
+}
+
+type dao struct {
+	db *gorm.DB
+}
+
+func NewDAO(db *gorm.DB) DAO {
+	return &dao{db: db}
+}
+
+func (d *dao) GetByClusterID(ctx context.Context, clusterID uint) (*models.RunQueue, error) {
+	var runQueue models.RunQueue
+	result := d.db.WithContext(ctx).Raw(common.RunQueueGetByClusterID, clusterID).Scan(&runQueue)
+	if result.Error != nil {
+		return nil, herrors.NewErrGetFailed(herrors.RunQueueInDB, result.Error.Error())
+	}
+	if result.RowsAffected == 0 {
+		return nil, herrors.NewErrNotFound(herrors.RunQueueInDB, "run queue not found")
+	}
+	return &runQueue, nil
+}
+
+// UpsertPolicy creates the queue of the cluster, or updates its policy if it exists
+func (d *dao) UpsertPolicy(ctx context.Context, runQueue *models.RunQueue) error {
+	result := d.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "cluster_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"policy", "updated_by"}),
+	}).Create(runQueue)
+	if result.Error != nil {
+		return herrors.NewErrInsertFailed(herrors.RunQueueInDB, result.Error.Error())
+	}
+	return nil
+}
+
+// Transfer moves the cluster from the pipelinerun holding it to another one in a single statement,
+// so that only one of the replicas racing for the cluster wins
+func (d *dao) Transfer(ctx context.Context, clusterID, fromPipelinerunID, toPipelinerunID uint,
+	lockedAt time.Time) (bool, error) {
+	// the queue is created on demand for clusters using the default policy
+	result := d.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&models.RunQueue{ClusterID: clusterID})
+	if result.Error != nil {
+		return false, herrors.NewErrInsertFailed(herrors.RunQueueInDB, result.Error.Error())
+	}
+	result = d.db.WithContext(ctx).Exec(common.RunQueueTransfer, toPipelinerunID, lockedAt,
+		clusterID, fromPipelinerunID)
+	if result.Error != nil {
+		return false, herrors.NewErrUpdateFailed(herrors.RunQueueInDB, result.Error.Error())
+	}
+	return result.RowsAffected > 0, nil
+}
@@ -0,0 +1,66 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manager
+
+import (
+	"context"
+	"time"
+
+	"github.com/horizoncd/horizon/pkg/runqueue/dao"
+	"github.com/horizoncd/horizon/pkg/runqueue/models"
+	"github.com/horizoncd/horizon/pkg/util/wlog"
+	"gorm.io/gorm"
+)
+
+type Manager interface {
+	GetByClusterID(ctx context.Context, clusterID uint) (*models.RunQueue, error)
+	// UpsertPolicy sets the policy of the cluster, and returns its queue
+	UpsertPolicy(ctx context.Context, runQueue *models.RunQueue) (*models.RunQueue, error)
+	// Transfer makes the cluster held by toPipelinerunID if it's held by fromPipelinerunID,
+	// 0 means the cluster is not held, it returns false if the cluster is held by another pipelinerun
+	Transfer(ctx context.Context, clusterID, fromPipelinerunID, toPipelinerunID uint, lockedAt time.Time) (bool, error)
+}
+
+type manager struct {
+	dao dao.DAO
+}
+
+func New(db *gorm.DB) Manager {
+	return &manager{
+		dao: dao.NewDAO(db),
+	}
+}
+
+func (m *manager) GetByClusterID(ctx context.Context, clusterID uint) (*models.RunQueue, error) {
+	const op = "run queue manager: get by cluster id"
+	defer wlog.Start(ctx, op).StopPrint()
+	return m.dao.GetByClusterID(ctx, clusterID)
+}
+
+func (m *manager) UpsertPolicy(ctx context.Context, runQueue *models.RunQueue) (*models.RunQueue, error) {
+	const op = "run queue manager: upsert policy"
+	defer wlog.Start(ctx, op).StopPrint()
+	if err := m.dao.UpsertPolicy(ctx, runQueue); err != nil {
+		return nil, err
+	}
+	return m.dao.GetByClusterID(ctx, runQueue.ClusterID)
+}
+
+func (m *manager) Transfer(ctx context.Context, clusterID, fromPipelinerunID, toPipelinerunID uint,
+	lockedAt time.Time) (bool, error) {
+	const op = "run queue manager: transfer"
+	defer wlog.Start(ctx, op).StopPrint()
+	return m.dao.Transfer(ctx, clusterID, fromPipelinerunID, toPipelinerunID, lockedAt)
+}
@@ -0,0 +1,83 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manager
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/lib/orm"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/runqueue/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	db, _ = orm.NewSqliteDB("")
+	ctx   context.Context
+	mgr   = New(db)
+)
+
+func TestMain(m *testing.M) {
+	if err := db.AutoMigrate(&models.RunQueue{}); err != nil {
+		panic(err)
+	}
+	ctx = context.TODO()
+	os.Exit(m.Run())
+}
+
+func Test(t *testing.T) {
+	_, err := mgr.GetByClusterID(ctx, 1)
+	_, ok := perror.Cause(err).(*herrors.HorizonErrNotFound)
+	assert.True(t, ok)
+
+	now := time.Now()
+	// the queue is created when the cluster is held for the first time
+	held, err := mgr.Transfer(ctx, 1, 0, 10, now)
+	assert.Nil(t, err)
+	assert.True(t, held)
+	held, err = mgr.Transfer(ctx, 1, 0, 11, now)
+	assert.Nil(t, err)
+	assert.False(t, held)
+	q, err := mgr.GetByClusterID(ctx, 1)
+	assert.Nil(t, err)
+	assert.Equal(t, uint(10), q.PipelinerunID)
+	assert.Equal(t, "", q.Policy)
+
+	// the policy is set without touching the pipelinerun holding the cluster
+	q, err = mgr.UpsertPolicy(ctx, &models.RunQueue{ClusterID: 1, Policy: models.PolicySerialize, UpdatedBy: 2})
+	assert.Nil(t, err)
+	assert.Equal(t, models.PolicySerialize, q.Policy)
+	assert.Equal(t, uint(10), q.PipelinerunID)
+	assert.Equal(t, uint(2), q.UpdatedBy)
+
+	held, err = mgr.Transfer(ctx, 1, 10, 11, now)
+	assert.Nil(t, err)
+	assert.True(t, held)
+	held, err = mgr.Transfer(ctx, 1, 10, 0, now)
+	assert.Nil(t, err)
+	assert.False(t, held)
+	held, err = mgr.Transfer(ctx, 1, 11, 0, now)
+	assert.Nil(t, err)
+	assert.True(t, held)
+	q, err = mgr.GetByClusterID(ctx, 1)
+	assert.Nil(t, err)
+	assert.Equal(t, uint(0), q.PipelinerunID)
+	assert.Equal(t, models.PolicySerialize, q.Policy)
+}
@@ -0,0 +1,54 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package models
+
+import "time"
+
+// policies of what happens to a new pipelinerun when the cluster is held by another one
+const (
+	// PolicyParallel runs pipelineruns of the cluster at the same time
+	PolicyParallel = "parallel"
+	// PolicySerialize queues the new pipelinerun until the pipelinerun holding the cluster finishes
+	PolicySerialize = "serialize"
+	// PolicyCancel cancels the pipelinerun holding the cluster and the queued ones, then queues the new one
+	PolicyCancel = "cancel"
+	// PolicyReject cancels the new pipelinerun
+	PolicyReject = "reject"
+)
+
+// RunQueue is the queue of the pipelineruns of a cluster, which are executed one at a time.
+// Executing a pipelinerun holds the cluster, until the pipelinerun finishes or stops changing for a long time.
+type RunQueue struct {
+	ID        uint
+	ClusterID uint `gorm:"uniqueIndex:idx_cluster_id"`
+	// Policy is the policy of the cluster, the default policy is used if it's empty
+	Policy string
+	// PipelinerunID is the pipelinerun holding the cluster, 0 if the cluster is not held
+	PipelinerunID uint
+	LockedAt      *time.Time
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+	CreatedBy     uint
+	UpdatedBy     uint
+}
+
+func ValidPolicy(policy string) bool {
+	switch policy {
+	case PolicyParallel, PolicySerialize, PolicyCancel, PolicyReject:
+		return true
+	default:
+		return false
+	}
+}
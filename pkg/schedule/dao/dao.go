@@ -0,0 +1,123 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dao
+
+import (
+	"context"
+	"time"
+
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/pkg/common"
+	"github.com/horizoncd/horizon/pkg/schedule/models"
+
+	"gorm.io/gorm"
+)
+
+type DAO interface {
+	Create(ctx context.Context, schedule *models.Schedule) (*models.Schedule, error)
+	GetByID(ctx context.Context, id uint) (*models.Schedule, error)
+	ListByClusterID(ctx context.Context, clusterID uint) ([]*models.Schedule, error)
+	ListDue(ctx context.Context, now time.Time) ([]*models.Schedule, error)
+	UpdateByID(ctx context.Context, id uint, schedule *models.Schedule) error
+	ClaimByID(ctx context.Context, id uint, dueAt time.Time, runAt time.Time, nextRunAt *time.Time) (bool, error)
+	UpdateResultByID(ctx context.Context, id uint, pipelinerunID uint, message string) error
+	DeleteByID(ctx context.Context, id uint) error
+}
+
+type dao struct {
+	db *gorm.DB
+}
+
+func NewDAO(db *gorm.DB) DAO {
+	return &dao{db: db}
+}
+
+func (d *dao) Create(ctx context.Context, schedule *models.Schedule) (*models.Schedule, error) {
+	if result := d.db.WithContext(ctx).Create(schedule); result.Error != nil {
+		return nil, herrors.NewErrInsertFailed(herrors.ScheduleInDB, result.Error.Error())
+	}
+	return schedule, nil
+}
+
+func (d *dao) GetByID(ctx context.Context, id uint) (*models.Schedule, error) {
+	var schedule models.Schedule
+	result := d.db.WithContext(ctx).Raw(common.ScheduleGetByID, id).Scan(&schedule)
+	if result.Error != nil {
+		return nil, herrors.NewErrGetFailed(herrors.ScheduleInDB, result.Error.Error())
+	}
+	if result.RowsAffected == 0 {
+		return nil, herrors.NewErrNotFound(herrors.ScheduleInDB, "schedule not found")
+	}
+	return &schedule, nil
+}
+
+func (d *dao) ListByClusterID(ctx context.Context, clusterID uint) ([]*models.Schedule, error) {
+	var schedules []*models.Schedule
+	result := d.db.WithContext(ctx).Raw(common.ScheduleListByClusterID, clusterID).Scan(&schedules)
+	if result.Error != nil {
+		return nil, herrors.NewErrListFailed(herrors.ScheduleInDB, result.Error.Error())
+	}
+	return schedules, nil
+}
+
+func (d *dao) ListDue(ctx context.Context, now time.Time) ([]*models.Schedule, error) {
+	var schedules []*models.Schedule
+	result := d.db.WithContext(ctx).Raw(common.ScheduleListDue, now).Scan(&schedules)
+	if result.Error != nil {
+		return nil, herrors.NewErrListFailed(herrors.ScheduleInDB, result.Error.Error())
+	}
+	return schedules, nil
+}
+
+// UpdateByID updates the definition of the schedule, including whether it's paused
+func (d *dao) UpdateByID(ctx context.Context, id uint, schedule *models.Schedule) error {
+	result := d.db.WithContext(ctx).Model(&models.Schedule{}).Where("id = ?", id).
+		Select("Name", "Cron", "Action", "Params", "OperatorID", "Paused", "NextRunAt", "UpdatedBy").
+		Updates(schedule)
+	if result.Error != nil {
+		return herrors.NewErrUpdateFailed(herrors.ScheduleInDB, result.Error.Error())
+	}
+	return nil
+}
+
+// ClaimByID moves the schedule due at dueAt to its next run, false is returned if the schedule
+// is not due at dueAt anymore, which means it's updated or claimed by someone else in the meantime
+func (d *dao) ClaimByID(ctx context.Context, id uint, dueAt time.Time, runAt time.Time,
+	nextRunAt *time.Time) (bool, error) {
+	result := d.db.WithContext(ctx).Model(&models.Schedule{}).
+		Where("id = ? and paused = ? and next_run_at = ?", id, false, dueAt).
+		Updates(map[string]interface{}{"next_run_at": nextRunAt, "last_run_at": runAt})
+	if result.Error != nil {
+		return false, herrors.NewErrUpdateFailed(herrors.ScheduleInDB, result.Error.Error())
+	}
+	return result.RowsAffected > 0, nil
+}
+
+func (d *dao) UpdateResultByID(ctx context.Context, id uint, pipelinerunID uint, message string) error {
+	result := d.db.WithContext(ctx).Model(&models.Schedule{}).Where("id = ?", id).
+		Updates(map[string]interface{}{"last_pipelinerun_id": pipelinerunID, "last_message": message})
+	if result.Error != nil {
+		return herrors.NewErrUpdateFailed(herrors.ScheduleInDB, result.Error.Error())
+	}
+	return nil
+}
+
+func (d *dao) DeleteByID(ctx context.Context, id uint) error {
+	result := d.db.WithContext(ctx).Where("id = ?", id).Delete(&models.Schedule{})
+	if result.Error != nil {
+		return herrors.NewErrDeleteFailed(herrors.ScheduleInDB, result.Error.Error())
+	}
+	return nil
+}
@@ -0,0 +1,100 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manager
+
+import (
+	"context"
+	"time"
+
+	"github.com/horizoncd/horizon/pkg/schedule/dao"
+	"github.com/horizoncd/horizon/pkg/schedule/models"
+	"github.com/horizoncd/horizon/pkg/util/wlog"
+	"gorm.io/gorm"
+)
+
+type Manager interface {
+	Create(ctx context.Context, schedule *models.Schedule) (*models.Schedule, error)
+	GetByID(ctx context.Context, id uint) (*models.Schedule, error)
+	ListByClusterID(ctx context.Context, clusterID uint) ([]*models.Schedule, error)
+	// ListDue lists the schedules which are not paused and due at now
+	ListDue(ctx context.Context, now time.Time) ([]*models.Schedule, error)
+	// UpdateByID updates the name, cron, action, params, operator, paused and next run of the schedule
+	UpdateByID(ctx context.Context, id uint, schedule *models.Schedule) error
+	// ClaimByID records that the schedule due at dueAt runs at runAt and is due at nextRunAt next,
+	// false is returned if the schedule is paused, updated or claimed after it's listed
+	ClaimByID(ctx context.Context, id uint, dueAt time.Time, runAt time.Time, nextRunAt *time.Time) (bool, error)
+	// UpdateResultByID records the pipelinerun created by the last run, or why the last run failed
+	UpdateResultByID(ctx context.Context, id uint, pipelinerunID uint, message string) error
+	DeleteByID(ctx context.Context, id uint) error
+}
+
+type manager struct {
+	dao dao.DAO
+}
+
+func New(db *gorm.DB) Manager {
+	return &manager{
+		dao: dao.NewDAO(db),
+	}
+}
+
+func (m *manager) Create(ctx context.Context, schedule *models.Schedule) (*models.Schedule, error) {
+	const op = "schedule manager: create"
+	defer wlog.Start(ctx, op).StopPrint()
+	return m.dao.Create(ctx, schedule)
+}
+
+func (m *manager) GetByID(ctx context.Context, id uint) (*models.Schedule, error) {
+	const op = "schedule manager: get by id"
+	defer wlog.Start(ctx, op).StopPrint()
+	return m.dao.GetByID(ctx, id)
+}
+
+func (m *manager) ListByClusterID(ctx context.Context, clusterID uint) ([]*models.Schedule, error) {
+	const op = "schedule manager: list by cluster id"
+	defer wlog.Start(ctx, op).StopPrint()
+	return m.dao.ListByClusterID(ctx, clusterID)
+}
+
+func (m *manager) ListDue(ctx context.Context, now time.Time) ([]*models.Schedule, error) {
+	const op = "schedule manager: list due"
+	defer wlog.Start(ctx, op).StopPrint()
+	return m.dao.ListDue(ctx, now)
+}
+
+func (m *manager) UpdateByID(ctx context.Context, id uint, schedule *models.Schedule) error {
+	const op = "schedule manager: update by id"
+	defer wlog.Start(ctx, op).StopPrint()
+	return m.dao.UpdateByID(ctx, id, schedule)
+}
+
+func (m *manager) ClaimByID(ctx context.Context, id uint, dueAt time.Time, runAt time.Time,
+	nextRunAt *time.Time) (bool, error) {
+	const op = "schedule manager: claim by id"
+	defer wlog.Start(ctx, op).StopPrint()
+	return m.dao.ClaimByID(ctx, id, dueAt, runAt, nextRunAt)
+}
+
+func (m *manager) UpdateResultByID(ctx context.Context, id uint, pipelinerunID uint, message string) error {
+	const op = "schedule manager: update result by id"
+	defer wlog.Start(ctx, op).StopPrint()
+	return m.dao.UpdateResultByID(ctx, id, pipelinerunID, message)
+}
+
+func (m *manager) DeleteByID(ctx context.Context, id uint) error {
+	const op = "schedule manager: delete by id"
+	defer wlog.Start(ctx, op).StopPrint()
+	return m.dao.DeleteByID(ctx, id)
+}
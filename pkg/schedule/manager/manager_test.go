@@ -0,0 +1,127 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manager
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/lib/orm"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/schedule/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	db, _ = orm.NewSqliteDB("")
+	ctx   context.Context
+	mgr   = New(db)
+)
+
+func TestMain(m *testing.M) {
+	if err := db.AutoMigrate(&models.Schedule{}); err != nil {
+		panic(err)
+	}
+	ctx = context.TODO()
+	os.Exit(m.Run())
+}
+
+func Test(t *testing.T) {
+	_, err := mgr.GetByID(ctx, 1)
+	_, ok := perror.Cause(err).(*herrors.HorizonErrNotFound)
+	assert.True(t, ok)
+
+	now := time.Date(2023, 6, 28, 10, 0, 0, 0, time.UTC)
+	dueAt := now.Add(-time.Minute)
+	nightly := &models.Schedule{
+		ClusterID:  1,
+		Name:       "nightly",
+		Cron:       "0 2 * * *",
+		Action:     models.ActionBuildDeploy,
+		OperatorID: 1,
+		NextRunAt:  &dueAt,
+	}
+	nightly.SetParams(models.Params{GitBranch: "develop"})
+	nightly, err = mgr.Create(ctx, nightly)
+	assert.Nil(t, err)
+
+	later := now.Add(time.Hour)
+	restart, err := mgr.Create(ctx, &models.Schedule{
+		ClusterID:  1,
+		Name:       "restart",
+		Cron:       "@hourly",
+		Action:     models.ActionRestart,
+		OperatorID: 1,
+		NextRunAt:  &later,
+	})
+	assert.Nil(t, err)
+	_, err = mgr.Create(ctx, &models.Schedule{
+		ClusterID:  2,
+		Cron:       "@daily",
+		Action:     models.ActionRestart,
+		OperatorID: 1,
+		Paused:     true,
+		NextRunAt:  &dueAt,
+	})
+	assert.Nil(t, err)
+
+	schedules, err := mgr.ListByClusterID(ctx, 1)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(schedules))
+	assert.Equal(t, "develop", schedules[0].GetParams().GitBranch)
+
+	// only the schedules which are due and not paused are listed
+	due, err := mgr.ListDue(ctx, now)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(due))
+	assert.Equal(t, nightly.ID, due[0].ID)
+
+	next := now.Add(16 * time.Hour)
+	claimed, err := mgr.ClaimByID(ctx, nightly.ID, *due[0].NextRunAt, now, &next)
+	assert.Nil(t, err)
+	assert.True(t, claimed)
+	// the schedule is not due at dueAt anymore once it's claimed
+	claimed, err = mgr.ClaimByID(ctx, nightly.ID, *due[0].NextRunAt, now, &next)
+	assert.Nil(t, err)
+	assert.False(t, claimed)
+	assert.Nil(t, mgr.UpdateResultByID(ctx, nightly.ID, 3, ""))
+
+	nightly, err = mgr.GetByID(ctx, nightly.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, uint(3), nightly.LastPipelinerunID)
+	assert.True(t, nightly.LastRunAt.Equal(now))
+	assert.True(t, nightly.NextRunAt.Equal(next))
+
+	restart.Paused = true
+	restart.Cron = "@daily"
+	restart.UpdatedBy = 2
+	assert.Nil(t, mgr.UpdateByID(ctx, restart.ID, restart))
+	restart, err = mgr.GetByID(ctx, restart.ID)
+	assert.Nil(t, err)
+	assert.True(t, restart.Paused)
+	assert.Equal(t, "@daily", restart.Cron)
+	claimed, err = mgr.ClaimByID(ctx, restart.ID, *restart.NextRunAt, now, &next)
+	assert.Nil(t, err)
+	assert.False(t, claimed)
+
+	assert.Nil(t, mgr.DeleteByID(ctx, restart.ID))
+	_, err = mgr.GetByID(ctx, restart.ID)
+	_, ok = perror.Cause(err).(*herrors.HorizonErrNotFound)
+	assert.True(t, ok)
+}
@@ -0,0 +1,75 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// actions of a schedule
+const (
+	ActionBuildDeploy = "builddeploy"
+	ActionDeploy      = "deploy"
+	ActionRestart     = "restart"
+)
+
+// Schedule creates pipelineruns of a cluster by a cron expression
+type Schedule struct {
+	ID        uint
+	ClusterID uint
+	Name      string
+	// Cron is a standard cron expression with five fields or a descriptor like @daily,
+	// it's in the time zone of the config unless prefixed with CRON_TZ=
+	Cron   string
+	Action string
+	// Params is the json of Params
+	Params string
+	// OperatorID is the user whom the pipelineruns are created as
+	OperatorID uint
+	Paused     bool
+	// NextRunAt is when the schedule is due next
+	NextRunAt         *time.Time
+	LastRunAt         *time.Time
+	LastPipelinerunID uint
+	// LastMessage tells why the last run failed, empty if it succeeded
+	LastMessage string
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	CreatedBy   uint
+	UpdatedBy   uint
+}
+
+// Params are the arguments of the action
+type Params struct {
+	// GitBranch is built by the builddeploy action
+	GitBranch string `json:"gitBranch,omitempty"`
+	// ImageTag is deployed by the deploy action, the current tag is kept if it's empty
+	ImageTag string `json:"imageTag,omitempty"`
+}
+
+func (s *Schedule) GetParams() Params {
+	var params Params
+	if s.Params == "" {
+		return params
+	}
+	_ = json.Unmarshal([]byte(s.Params), &params)
+	return params
+}
+
+func (s *Schedule) SetParams(params Params) {
+	bts, _ := json.Marshal(params)
+	s.Params = string(bts)
+}
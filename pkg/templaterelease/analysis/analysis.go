@@ -0,0 +1,182 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package analysis
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"text/template"
+	"time"
+
+	herrors "github.com/horizoncd/horizon/core/errors"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/param/managerparam"
+	"github.com/horizoncd/horizon/pkg/templaterelease/manager"
+	"github.com/horizoncd/horizon/pkg/templaterepo"
+	"github.com/horizoncd/horizon/pkg/util/wlog"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// analysis yaml file path
+	_analysisPath = "analysis/analysis.yaml"
+
+	// DefaultInterval is how long a step is observed if the template does not specify it
+	DefaultInterval = 5 * time.Minute
+)
+
+const (
+	ConditionLessThan       = "<"
+	ConditionLessOrEqual    = "<="
+	ConditionGreaterThan    = ">"
+	ConditionGreaterOrEqual = ">="
+)
+
+// Spec declares how the canary steps of a rollout are analyzed, for example:
+//
+//	interval: 5m
+//	metrics:
+//	  - name: error-rate
+//	    query: sum(rate(http_requests_total{app="{{ .Cluster }}",code=~"5.."}[1m]))
+//	      / sum(rate(http_requests_total{app="{{ .Cluster }}"}[1m]))
+//	    condition: "<"
+//	    threshold: 0.01
+type Spec struct {
+	// Interval is how long each step is observed before it is promoted
+	Interval time.Duration `yaml:"interval"`
+	Metrics  []Metric      `yaml:"metrics"`
+}
+
+// Metric is a success criteria of a canary step
+type Metric struct {
+	Name string `yaml:"name"`
+	// Query is a PromQL query which results in a single value,
+	// .Application, .Cluster, .Environment and .Region can be used as template variables
+	Query string `yaml:"query"`
+	// Condition is how the value compares to the threshold when the step is healthy,
+	// it is one of <, <=, > and >=, and defaults to <=
+	Condition string  `yaml:"condition"`
+	Threshold float64 `yaml:"threshold"`
+}
+
+// QueryVars are the variables which can be used in queries
+type QueryVars struct {
+	Application string
+	Cluster     string
+	Environment string
+	Region      string
+}
+
+// RenderQuery renders the query of the metric with the variables
+func (m *Metric) RenderQuery(vars QueryVars) (string, error) {
+	tpl, err := template.New(m.Name).Option("missingkey=error").Parse(m.Query)
+	if err != nil {
+		return "", perror.Wrapf(herrors.ErrParamInvalid, "invalid query of metric %s: %v", m.Name, err)
+	}
+	var buf bytes.Buffer
+	if err := tpl.Execute(&buf, vars); err != nil {
+		return "", perror.Wrapf(herrors.ErrParamInvalid, "failed to render query of metric %s: %v", m.Name, err)
+	}
+	return buf.String(), nil
+}
+
+// Passed tells whether the value meets the metric's condition
+func (m *Metric) Passed(value float64) bool {
+	switch m.Condition {
+	case ConditionLessThan:
+		return value < m.Threshold
+	case ConditionGreaterThan:
+		return value > m.Threshold
+	case ConditionGreaterOrEqual:
+		return value >= m.Threshold
+	default:
+		return value <= m.Threshold
+	}
+}
+
+// Parse parses and validates the analysis spec
+func Parse(content []byte) (*Spec, error) {
+	var spec Spec
+	if err := yaml.Unmarshal(content, &spec); err != nil {
+		return nil, perror.Wrapf(herrors.ErrParamInvalid, "invalid analysis spec: %v", err)
+	}
+	if spec.Interval <= 0 {
+		spec.Interval = DefaultInterval
+	}
+	for i := range spec.Metrics {
+		metric := &spec.Metrics[i]
+		if metric.Name == "" || metric.Query == "" {
+			return nil, perror.Wrapf(herrors.ErrParamInvalid,
+				"name and query of metric %d should not be empty", i)
+		}
+		switch metric.Condition {
+		case "":
+			metric.Condition = ConditionLessOrEqual
+		case ConditionLessThan, ConditionLessOrEqual, ConditionGreaterThan, ConditionGreaterOrEqual:
+		default:
+			return nil, perror.Wrapf(herrors.ErrParamInvalid,
+				"invalid condition %s of metric %s", metric.Condition, metric.Name)
+		}
+	}
+	return &spec, nil
+}
+
+// Getter provides the analysis spec of templates
+type Getter interface {
+	// GetTemplateAnalysis get the analysis spec of the specified template release,
+	// nil is returned if the template does not declare one
+	GetTemplateAnalysis(ctx context.Context, templateName, releaseName string) (*Spec, error)
+}
+
+type getter struct {
+	templateRepo       templaterepo.TemplateRepo
+	templateReleaseMgr manager.Manager
+}
+
+func NewAnalysisGetter(repo templaterepo.TemplateRepo, m *managerparam.Manager) Getter {
+	return &getter{
+		templateRepo:       repo,
+		templateReleaseMgr: m.TemplateReleaseManager,
+	}
+}
+
+func (g *getter) GetTemplateAnalysis(ctx context.Context,
+	templateName, releaseName string) (*Spec, error) {
+	const op = "template analysis getter: getTemplateAnalysis"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	tr, err := g.templateReleaseMgr.GetByTemplateNameAndRelease(ctx, templateName, releaseName)
+	if err != nil {
+		return nil, err
+	}
+
+	chart, err := g.templateRepo.GetChart(tr.ChartName, tr.ChartReference(), tr.LastSyncAt)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, file := range chart.Files {
+		if file.Name == _analysisPath {
+			spec, err := Parse(file.Data)
+			if err != nil {
+				return nil, perror.WithMessage(err,
+					fmt.Sprintf("template %s release %s", templateName, releaseName))
+			}
+			return spec, nil
+		}
+	}
+	return nil, nil
+}
@@ -0,0 +1,116 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package analysis
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	templatereleasemock "github.com/horizoncd/horizon/mock/pkg/templaterelease/manager"
+	repomock "github.com/horizoncd/horizon/mock/pkg/templaterepo"
+	trm "github.com/horizoncd/horizon/pkg/templaterelease/models"
+	"github.com/stretchr/testify/assert"
+	"helm.sh/helm/v3/pkg/chart"
+)
+
+const _spec = `interval: 2m
+metrics:
+  - name: error-rate
+    query: 'sum(rate(http_errors{app="{{ .Application }}",cluster="{{ .Cluster }}"}[1m]))'
+    condition: "<"
+    threshold: 0.01
+  - name: latency
+    query: 'histogram_quantile(0.99, latency{env="{{ .Environment }}"})'
+    threshold: 500
+`
+
+func TestParse(t *testing.T) {
+	spec, err := Parse([]byte(_spec))
+	assert.Nil(t, err)
+	assert.Equal(t, 2*time.Minute, spec.Interval)
+	assert.Equal(t, 2, len(spec.Metrics))
+	assert.Equal(t, ConditionLessThan, spec.Metrics[0].Condition)
+	assert.Equal(t, ConditionLessOrEqual, spec.Metrics[1].Condition)
+
+	spec, err = Parse([]byte("metrics:\n  - name: a\n    query: up\n"))
+	assert.Nil(t, err)
+	assert.Equal(t, DefaultInterval, spec.Interval)
+
+	_, err = Parse([]byte("metrics:\n  - name: a\n"))
+	assert.NotNil(t, err)
+	_, err = Parse([]byte("metrics:\n  - name: a\n    query: up\n    condition: \"==\"\n"))
+	assert.NotNil(t, err)
+}
+
+func TestMetric(t *testing.T) {
+	spec, err := Parse([]byte(_spec))
+	assert.Nil(t, err)
+
+	query, err := spec.Metrics[0].RenderQuery(QueryVars{Application: "app", Cluster: "app-test"})
+	assert.Nil(t, err)
+	assert.Equal(t, `sum(rate(http_errors{app="app",cluster="app-test"}[1m]))`, query)
+
+	metric := Metric{Name: "a", Query: "{{ .Namespace }}"}
+	_, err = metric.RenderQuery(QueryVars{})
+	assert.NotNil(t, err)
+
+	assert.True(t, spec.Metrics[0].Passed(0.001))
+	assert.False(t, spec.Metrics[0].Passed(0.01))
+	assert.True(t, spec.Metrics[1].Passed(500))
+	assert.False(t, spec.Metrics[1].Passed(501))
+	metric.Condition = ConditionGreaterOrEqual
+	metric.Threshold = 1
+	assert.True(t, metric.Passed(1))
+	assert.False(t, metric.Passed(0))
+}
+
+func TestGetTemplateAnalysis(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	repoMock := repomock.NewMockTemplateRepo(mockCtrl)
+	templatereleaseMockMgr := templatereleasemock.NewMockManager(mockCtrl)
+	var analysisGetter Getter = &getter{
+		templateRepo:       repoMock,
+		templateReleaseMgr: templatereleaseMockMgr,
+	}
+
+	templateName, releaseName := "java", "v1.0.0"
+	tm := time.Now()
+	tr := &trm.TemplateRelease{
+		TemplateName: templateName,
+		ChartVersion: releaseName,
+		ChartName:    templateName,
+		LastSyncAt:   tm,
+	}
+	templatereleaseMockMgr.EXPECT().GetByTemplateNameAndRelease(gomock.Any(),
+		templateName, releaseName).Return(tr, nil).Times(2)
+
+	// 1. template without analysis
+	repoMock.EXPECT().GetChart(templateName, releaseName, tm).
+		Return(&chart.Chart{}, nil).Times(1)
+	spec, err := analysisGetter.GetTemplateAnalysis(context.TODO(), templateName, releaseName)
+	assert.Nil(t, err)
+	assert.Nil(t, spec)
+
+	// 2. template with analysis
+	repoMock.EXPECT().GetChart(templateName, releaseName, tm).
+		Return(&chart.Chart{Files: []*chart.File{{Name: _analysisPath, Data: []byte(_spec)}}}, nil).Times(1)
+	spec, err = analysisGetter.GetTemplateAnalysis(context.TODO(), templateName, releaseName)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(spec.Metrics))
+}
@@ -0,0 +1,504 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package migration
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	herrors "github.com/horizoncd/horizon/core/errors"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/param/managerparam"
+	"github.com/horizoncd/horizon/pkg/templaterelease/manager"
+	"github.com/horizoncd/horizon/pkg/templaterepo"
+	"github.com/horizoncd/horizon/pkg/util/wlog"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// migration yaml file path
+	_migrationPath = "migration/migration.yaml"
+)
+
+const (
+	OpAdd     = "add"
+	OpRemove  = "remove"
+	OpReplace = "replace"
+	OpMove    = "move"
+	OpCopy    = "copy"
+	OpTest    = "test"
+	// OpDefault adds the value only if the path does not exist yet
+	OpDefault = "default"
+)
+
+const (
+	ChangeAdded   = "added"
+	ChangeRemoved = "removed"
+	ChangeUpdated = "updated"
+)
+
+var (
+	errPathNotFound = errors.New("path not found")
+	errTestFailed   = errors.New("test failed")
+)
+
+// Spec declares how the values of clusters are migrated when they are upgraded to the release
+// which ships the spec, for example:
+//
+//	migrations:
+//	  - from: ["v1.0.0", "v1.0.1"]
+//	    application:
+//	      - op: move
+//	        from: /app/spec/replica
+//	        path: /app/spec/replicas
+//	      - op: default
+//	        path: /app/health/port
+//	        value: 8080
+//	    pipeline:
+//	      - op: remove
+//	        path: /buildxml
+//	        optional: true
+type Spec struct {
+	Migrations []Migration `yaml:"migrations"`
+}
+
+// Migration is a list of operations applied to the values of clusters on one of the From releases
+type Migration struct {
+	// From are the releases the migration applies to, it applies to any release if it's empty
+	From []string `yaml:"from"`
+	// Application are the operations on the application values
+	Application []Operation `yaml:"application"`
+	// Pipeline are the operations on the pipeline values
+	Pipeline []Operation `yaml:"pipeline"`
+}
+
+// Operation is a JSON patch (RFC 6902) operation, besides the standard operations,
+// default adds the value only when the path does not exist.
+// Parent objects of add and default operations are created if they are missing.
+type Operation struct {
+	Op    string      `yaml:"op"`
+	Path  string      `yaml:"path"`
+	From  string      `yaml:"from"`
+	Value interface{} `yaml:"value"`
+	// Optional skips the operation instead of failing if the path or from does not exist
+	Optional bool `yaml:"optional"`
+}
+
+// Change is a difference between two values
+type Change struct {
+	// Path is a JSON pointer of the changed value
+	Path string      `json:"path"`
+	Type string      `json:"type"`
+	Old  interface{} `json:"old,omitempty"`
+	New  interface{} `json:"new,omitempty"`
+}
+
+// Parse parses and validates the migration spec
+func Parse(content []byte) (*Spec, error) {
+	var spec Spec
+	if err := yaml.Unmarshal(content, &spec); err != nil {
+		return nil, perror.Wrapf(herrors.ErrParamInvalid, "invalid migration spec: %v", err)
+	}
+	for i := range spec.Migrations {
+		m := &spec.Migrations[i]
+		for _, ops := range [][]Operation{m.Application, m.Pipeline} {
+			for j := range ops {
+				if err := ops[j].normalize(); err != nil {
+					return nil, perror.Wrapf(herrors.ErrParamInvalid,
+						"invalid operation %d of migration %d: %v", j, i, err)
+				}
+			}
+		}
+	}
+	return &spec, nil
+}
+
+func (o *Operation) normalize() error {
+	switch o.Op {
+	case OpAdd, OpReplace, OpTest, OpDefault:
+		// values are compared with and written into values decoded from json,
+		// so numbers and objects are converted to the same types
+		value, err := deepCopy(o.Value)
+		if err != nil {
+			return err
+		}
+		o.Value = value
+	case OpRemove:
+	case OpMove, OpCopy:
+		if _, err := parsePointer(o.From); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("unknown op %q", o.Op)
+	}
+	_, err := parsePointer(o.Path)
+	return err
+}
+
+// Match returns the first migration which applies to the release, nil is returned if there is none
+func (s *Spec) Match(release string) *Migration {
+	for i := range s.Migrations {
+		m := &s.Migrations[i]
+		if len(m.From) == 0 {
+			return m
+		}
+		for _, from := range m.From {
+			if from == release {
+				return m
+			}
+		}
+	}
+	return nil
+}
+
+// Apply applies the operations to a copy of the values
+func Apply(values map[string]interface{}, ops []Operation) (map[string]interface{}, error) {
+	if len(ops) == 0 {
+		return values, nil
+	}
+	copied, err := deepCopy(values)
+	if err != nil {
+		return nil, err
+	}
+	root, ok := copied.(map[string]interface{})
+	if !ok {
+		root = make(map[string]interface{})
+	}
+	for i, op := range ops {
+		if err := op.apply(root); err != nil {
+			if op.Optional && err == errPathNotFound {
+				continue
+			}
+			return nil, perror.Wrapf(herrors.ErrParamInvalid,
+				"operation %d (%s %s) failed: %v", i, op.Op, op.Path, err)
+		}
+	}
+	return root, nil
+}
+
+func (o *Operation) apply(root map[string]interface{}) error {
+	path, err := parsePointer(o.Path)
+	if err != nil {
+		return err
+	}
+	switch o.Op {
+	case OpAdd:
+		return add(root, path, o.Value)
+	case OpDefault:
+		if _, err := get(root, path); err == nil {
+			return nil
+		}
+		return add(root, path, o.Value)
+	case OpRemove:
+		_, err := remove(root, path)
+		return err
+	case OpReplace:
+		return replace(root, path, o.Value)
+	case OpTest:
+		value, err := get(root, path)
+		if err != nil {
+			return err
+		}
+		if !reflect.DeepEqual(value, o.Value) {
+			return errTestFailed
+		}
+		return nil
+	case OpMove, OpCopy:
+		from, err := parsePointer(o.From)
+		if err != nil {
+			return err
+		}
+		var value interface{}
+		if o.Op == OpMove {
+			value, err = remove(root, from)
+		} else {
+			value, err = get(root, from)
+			if err == nil {
+				value, err = deepCopy(value)
+			}
+		}
+		if err != nil {
+			return err
+		}
+		return add(root, path, value)
+	}
+	return fmt.Errorf("unknown op %q", o.Op)
+}
+
+// Diff returns the changes from before to after sorted by path,
+// objects are compared recursively while arrays are compared as a whole
+func Diff(before, after map[string]interface{}) []Change {
+	var changes []Change
+	diff("", before, after, &changes)
+	return changes
+}
+
+func diff(path string, before, after interface{}, changes *[]Change) {
+	beforeMap, ok1 := before.(map[string]interface{})
+	afterMap, ok2 := after.(map[string]interface{})
+	if !ok1 || !ok2 {
+		if !reflect.DeepEqual(before, after) {
+			*changes = append(*changes, Change{Path: path, Type: ChangeUpdated, Old: before, New: after})
+		}
+		return
+	}
+
+	keys := make([]string, 0, len(beforeMap)+len(afterMap))
+	for key := range beforeMap {
+		keys = append(keys, key)
+	}
+	for key := range afterMap {
+		if _, ok := beforeMap[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		childPath := path + "/" + escapeToken(key)
+		beforeValue, inBefore := beforeMap[key]
+		afterValue, inAfter := afterMap[key]
+		switch {
+		case !inBefore:
+			*changes = append(*changes, Change{Path: childPath, Type: ChangeAdded, New: afterValue})
+		case !inAfter:
+			*changes = append(*changes, Change{Path: childPath, Type: ChangeRemoved, Old: beforeValue})
+		default:
+			diff(childPath, beforeValue, afterValue, changes)
+		}
+	}
+}
+
+// parsePointer parses a JSON pointer (RFC 6901), the whole document can not be referenced
+func parsePointer(pointer string) ([]string, error) {
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, fmt.Errorf("invalid path %q, it should start with /", pointer)
+	}
+	tokens := strings.Split(pointer[1:], "/")
+	for i, token := range tokens {
+		tokens[i] = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+	}
+	return tokens, nil
+}
+
+func escapeToken(token string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(token, "~", "~0"), "/", "~1")
+}
+
+func get(root map[string]interface{}, path []string) (interface{}, error) {
+	var node interface{} = root
+	for _, token := range path {
+		switch n := node.(type) {
+		case map[string]interface{}:
+			child, ok := n[token]
+			if !ok {
+				return nil, errPathNotFound
+			}
+			node = child
+		case []interface{}:
+			i, err := index(token, len(n)-1)
+			if err != nil {
+				return nil, err
+			}
+			node = n[i]
+		default:
+			return nil, errPathNotFound
+		}
+	}
+	return node, nil
+}
+
+func add(root map[string]interface{}, path []string, value interface{}) error {
+	_, err := update(root, path, true, func(container interface{}, key string) (interface{}, error) {
+		switch c := container.(type) {
+		case map[string]interface{}:
+			c[key] = value
+			return c, nil
+		case []interface{}:
+			if key == "-" {
+				return append(c, value), nil
+			}
+			i, err := index(key, len(c))
+			if err != nil {
+				return nil, err
+			}
+			c = append(c, nil)
+			copy(c[i+1:], c[i:])
+			c[i] = value
+			return c, nil
+		}
+		return nil, errPathNotFound
+	})
+	return err
+}
+
+func replace(root map[string]interface{}, path []string, value interface{}) error {
+	_, err := update(root, path, false, func(container interface{}, key string) (interface{}, error) {
+		switch c := container.(type) {
+		case map[string]interface{}:
+			if _, ok := c[key]; !ok {
+				return nil, errPathNotFound
+			}
+			c[key] = value
+			return c, nil
+		case []interface{}:
+			i, err := index(key, len(c)-1)
+			if err != nil {
+				return nil, err
+			}
+			c[i] = value
+			return c, nil
+		}
+		return nil, errPathNotFound
+	})
+	return err
+}
+
+func remove(root map[string]interface{}, path []string) (interface{}, error) {
+	var removed interface{}
+	_, err := update(root, path, false, func(container interface{}, key string) (interface{}, error) {
+		switch c := container.(type) {
+		case map[string]interface{}:
+			value, ok := c[key]
+			if !ok {
+				return nil, errPathNotFound
+			}
+			removed = value
+			delete(c, key)
+			return c, nil
+		case []interface{}:
+			i, err := index(key, len(c)-1)
+			if err != nil {
+				return nil, err
+			}
+			removed = c[i]
+			return append(c[:i:i], c[i+1:]...), nil
+		}
+		return nil, errPathNotFound
+	})
+	return removed, err
+}
+
+// update walks to the container of the last token of the path, and replaces it with the one returned by fn.
+// Arrays are replaced in their parents since their length may change.
+func update(node interface{}, path []string, create bool,
+	fn func(container interface{}, key string) (interface{}, error)) (interface{}, error) {
+	if len(path) == 1 {
+		return fn(node, path[0])
+	}
+	switch n := node.(type) {
+	case map[string]interface{}:
+		child, ok := n[path[0]]
+		if !ok || child == nil {
+			if !create {
+				return nil, errPathNotFound
+			}
+			child = make(map[string]interface{})
+		}
+		child, err := update(child, path[1:], create, fn)
+		if err != nil {
+			return nil, err
+		}
+		n[path[0]] = child
+		return n, nil
+	case []interface{}:
+		i, err := index(path[0], len(n)-1)
+		if err != nil {
+			return nil, err
+		}
+		child, err := update(n[i], path[1:], create, fn)
+		if err != nil {
+			return nil, err
+		}
+		n[i] = child
+		return n, nil
+	}
+	return nil, errPathNotFound
+}
+
+// index parses the array index, which should be in [0, max]
+func index(token string, max int) (int, error) {
+	i, err := strconv.Atoi(token)
+	if err != nil || i < 0 || i > max {
+		return 0, errPathNotFound
+	}
+	return i, nil
+}
+
+func deepCopy(value interface{}) (interface{}, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	var copied interface{}
+	if err := json.Unmarshal(data, &copied); err != nil {
+		return nil, err
+	}
+	return copied, nil
+}
+
+// Getter provides the migration spec of templates
+//
+//go:generate mockgen -source=$GOFILE -destination=../../../mock/pkg/templaterelease/migration/migration_mock.go -package=mock_migration
+type Getter interface {
+	// GetTemplateMigration get the migration spec of the specified template release,
+	// nil is returned if the template does not ship one
+	GetTemplateMigration(ctx context.Context, templateName, releaseName string) (*Spec, error)
+}
+
+type getter struct {
+	templateRepo       templaterepo.TemplateRepo
+	templateReleaseMgr manager.Manager
+}
+
+func NewMigrationGetter(repo templaterepo.TemplateRepo, m *managerparam.Manager) Getter {
+	return &getter{
+		templateRepo:       repo,
+		templateReleaseMgr: m.TemplateReleaseManager,
+	}
+}
+
+func (g *getter) GetTemplateMigration(ctx context.Context,
+	templateName, releaseName string) (*Spec, error) {
+	const op = "template migration getter: getTemplateMigration"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	tr, err := g.templateReleaseMgr.GetByTemplateNameAndRelease(ctx, templateName, releaseName)
+	if err != nil {
+		return nil, err
+	}
+
+	chart, err := g.templateRepo.GetChart(tr.ChartName, tr.ChartReference(), tr.LastSyncAt)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, file := range chart.Files {
+		if file.Name == _migrationPath {
+			spec, err := Parse(file.Data)
+			if err != nil {
+				return nil, perror.WithMessage(err,
+					fmt.Sprintf("template %s release %s", templateName, releaseName))
+			}
+			return spec, nil
+		}
+	}
+	return nil, nil
+}
@@ -0,0 +1,208 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package migration
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	templatereleasemock "github.com/horizoncd/horizon/mock/pkg/templaterelease/manager"
+	repomock "github.com/horizoncd/horizon/mock/pkg/templaterepo"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	trm "github.com/horizoncd/horizon/pkg/templaterelease/models"
+	"github.com/stretchr/testify/assert"
+	"helm.sh/helm/v3/pkg/chart"
+)
+
+const _spec = `migrations:
+  - from: ["v1.0.0"]
+    application:
+      - op: move
+        from: /app/spec/replica
+        path: /app/spec/replicas
+      - op: default
+        path: /app/health/port
+        value: 8080
+      - op: replace
+        path: /app/envs/0
+        value: {name: b, value: "2"}
+      - op: add
+        path: /app/envs/-
+        value: {name: c, value: "3"}
+      - op: remove
+        path: /app/legacy
+        optional: true
+    pipeline:
+      - op: test
+        path: /buildType
+        value: netease-normal
+      - op: copy
+        from: /buildxml
+        path: /build~1xml
+  - application:
+      - op: add
+        path: /app/spec/replicas
+        value: 1
+`
+
+func TestParse(t *testing.T) {
+	spec, err := Parse([]byte(_spec))
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(spec.Migrations))
+	// numbers are converted into the types of json
+	assert.Equal(t, float64(8080), spec.Migrations[0].Application[1].Value)
+
+	assert.Equal(t, &spec.Migrations[0], spec.Match("v1.0.0"))
+	assert.Equal(t, &spec.Migrations[1], spec.Match("v0.0.1"))
+	assert.Nil(t, (&Spec{}).Match("v1.0.0"))
+
+	for _, content := range []string{
+		"migrations:\n  - application:\n      - op: patch\n        path: /a\n",
+		"migrations:\n  - application:\n      - op: add\n        path: a\n",
+		"migrations:\n  - pipeline:\n      - op: move\n        path: /a\n",
+		"migrations: {}",
+	} {
+		_, err = Parse([]byte(content))
+		assert.Equal(t, herrors.ErrParamInvalid, perror.Cause(err))
+	}
+}
+
+func TestApply(t *testing.T) {
+	spec, err := Parse([]byte(_spec))
+	assert.Nil(t, err)
+	m := spec.Match("v1.0.0")
+
+	application := map[string]interface{}{
+		"app": map[string]interface{}{
+			"spec": map[string]interface{}{
+				"replica": float64(2),
+			},
+			"envs": []interface{}{
+				map[string]interface{}{"name": "a", "value": "1"},
+			},
+		},
+	}
+	migrated, err := Apply(application, m.Application)
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"app": map[string]interface{}{
+			"spec": map[string]interface{}{
+				"replicas": float64(2),
+			},
+			"health": map[string]interface{}{
+				"port": float64(8080),
+			},
+			"envs": []interface{}{
+				map[string]interface{}{"name": "b", "value": "2"},
+				map[string]interface{}{"name": "c", "value": "3"},
+			},
+		},
+	}, migrated)
+	// the original values are not modified
+	assert.Equal(t, float64(2), application["app"].(map[string]interface{})["spec"].(map[string]interface{})["replica"])
+
+	// default does not override the existing value
+	migrated, err = Apply(migrated, []Operation{{Op: OpDefault, Path: "/app/health/port", Value: float64(80)}})
+	assert.Nil(t, err)
+	assert.Equal(t, float64(8080), migrated["app"].(map[string]interface{})["health"].(map[string]interface{})["port"])
+
+	// the source of move does not exist
+	_, err = Apply(application, spec.Migrations[0].Application[:1])
+	assert.Nil(t, err)
+	_, err = Apply(map[string]interface{}{}, spec.Migrations[0].Application[:1])
+	assert.Equal(t, herrors.ErrParamInvalid, perror.Cause(err))
+
+	pipeline := map[string]interface{}{"buildType": "netease-normal", "buildxml": "<xml/>"}
+	migrated, err = Apply(pipeline, m.Pipeline)
+	assert.Nil(t, err)
+	assert.Equal(t, "<xml/>", migrated["build/xml"])
+	_, err = Apply(map[string]interface{}{"buildType": "other", "buildxml": "<xml/>"}, m.Pipeline)
+	assert.Equal(t, herrors.ErrParamInvalid, perror.Cause(err))
+
+	// values are kept if there is nothing to do
+	migrated, err = Apply(nil, nil)
+	assert.Nil(t, err)
+	assert.Nil(t, migrated)
+}
+
+func TestDiff(t *testing.T) {
+	before := map[string]interface{}{
+		"app": map[string]interface{}{
+			"spec": map[string]interface{}{
+				"replica": float64(2),
+				"cpu":     "1",
+			},
+			"envs": []interface{}{"a"},
+		},
+	}
+	after := map[string]interface{}{
+		"app": map[string]interface{}{
+			"spec": map[string]interface{}{
+				"replicas": float64(2),
+				"cpu":      "2",
+			},
+			"envs": []interface{}{"a", "b"},
+		},
+	}
+	assert.Equal(t, []Change{
+		{Path: "/app/envs", Type: ChangeUpdated, Old: []interface{}{"a"}, New: []interface{}{"a", "b"}},
+		{Path: "/app/spec/cpu", Type: ChangeUpdated, Old: "1", New: "2"},
+		{Path: "/app/spec/replica", Type: ChangeRemoved, Old: float64(2)},
+		{Path: "/app/spec/replicas", Type: ChangeAdded, New: float64(2)},
+	}, Diff(before, after))
+	assert.Nil(t, Diff(before, before))
+	assert.Equal(t, []Change{{Path: "/a~1b", Type: ChangeAdded, New: "c"}},
+		Diff(nil, map[string]interface{}{"a/b": "c"}))
+}
+
+func TestGetTemplateMigration(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	repoMock := repomock.NewMockTemplateRepo(mockCtrl)
+	templatereleaseMockMgr := templatereleasemock.NewMockManager(mockCtrl)
+	var migrationGetter Getter = &getter{
+		templateRepo:       repoMock,
+		templateReleaseMgr: templatereleaseMockMgr,
+	}
+
+	templateName, releaseName := "java", "v1.0.1"
+	tm := time.Now()
+	tr := &trm.TemplateRelease{
+		TemplateName: templateName,
+		ChartVersion: releaseName,
+		ChartName:    templateName,
+		LastSyncAt:   tm,
+	}
+	templatereleaseMockMgr.EXPECT().GetByTemplateNameAndRelease(gomock.Any(),
+		templateName, releaseName).Return(tr, nil).Times(2)
+
+	// 1. template without migration
+	repoMock.EXPECT().GetChart(templateName, releaseName, tm).
+		Return(&chart.Chart{}, nil).Times(1)
+	spec, err := migrationGetter.GetTemplateMigration(context.TODO(), templateName, releaseName)
+	assert.Nil(t, err)
+	assert.Nil(t, spec)
+
+	// 2. template with migration
+	repoMock.EXPECT().GetChart(templateName, releaseName, tm).
+		Return(&chart.Chart{Files: []*chart.File{{Name: _migrationPath, Data: []byte(_spec)}}}, nil).Times(1)
+	spec, err = migrationGetter.GetTemplateMigration(context.TODO(), templateName, releaseName)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(spec.Migrations))
+}
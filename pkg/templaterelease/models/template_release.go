@@ -31,6 +31,10 @@ type TemplateRelease struct {
 	Name string
 	// v1.0.0-33da3204
 	ChartVersion string
+	// sha256:..., digest of the chart uploaded to repos addressing charts by digest like OCI registries
+	ChartDigest string
+	// helm, kustomize or raw
+	Kind string
 
 	Description  string
 	Recommended  *bool
@@ -43,6 +47,15 @@ type TemplateRelease struct {
 	UpdatedBy    uint
 }
 
+// ChartReference returns the version to get the chart of the release by,
+// which is pinned to the digest of the chart if the repo returned one on upload
+func (t *TemplateRelease) ChartReference() string {
+	if t.ChartDigest == "" {
+		return t.ChartVersion
+	}
+	return t.ChartVersion + "@" + t.ChartDigest
+}
+
 type SyncStatus uint8
 
 const (
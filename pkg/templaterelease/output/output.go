@@ -66,7 +66,7 @@ func (g *getter) GetTemplateOutPut(ctx context.Context,
 		return "", err
 	}
 
-	chart, err := g.templateRepo.GetChart(tr.ChartName, tr.ChartVersion, tr.LastSyncAt)
+	chart, err := g.templateRepo.GetChart(tr.ChartName, tr.ChartReference(), tr.LastSyncAt)
 	if err != nil {
 		return "", err
 	}
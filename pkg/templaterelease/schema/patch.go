@@ -0,0 +1,154 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	herrors "github.com/horizoncd/horizon/core/errors"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+)
+
+const (
+	// PatchKeyword maps the value of a property onto a field of the manifests of
+	// kustomize templates. It's an object or a list of objects like:
+	//
+	//	"replicas": {
+	//	  "type": "integer",
+	//	  "x-kustomize-patch": {"target": {"kind": "Deployment", "name": "app"}, "path": "/spec/replicas"}
+	//	}
+	PatchKeyword = "x-kustomize-patch"
+	// ImageKeyword is declared at the root of application schema, it names the image
+	// in the manifests which is replaced by the image built by pipeline.
+	ImageKeyword = "x-kustomize-image"
+
+	_propertiesKey = "properties"
+	_opAdd         = "add"
+)
+
+// PatchTarget selects the resources a patch applies to
+type PatchTarget struct {
+	Group     string `json:"group,omitempty" yaml:"group,omitempty"`
+	Version   string `json:"version,omitempty" yaml:"version,omitempty"`
+	Kind      string `json:"kind,omitempty" yaml:"kind,omitempty"`
+	Name      string `json:"name,omitempty" yaml:"name,omitempty"`
+	Namespace string `json:"namespace,omitempty" yaml:"namespace,omitempty"`
+}
+
+// PatchOperation is a json6902 operation on the target resources
+type PatchOperation struct {
+	Target PatchTarget `json:"-" yaml:"-"`
+	Op     string      `json:"op" yaml:"op"`
+	Path   string      `json:"path" yaml:"path"`
+	Value  interface{} `json:"value" yaml:"value"`
+}
+
+type patchSpec struct {
+	Target PatchTarget `json:"target"`
+	Path   string      `json:"path"`
+}
+
+// Patches walks through the properties of jsonSchema along with values, and returns
+// the operations declared by PatchKeyword for the values that are set.
+func Patches(jsonSchema map[string]interface{}, values map[string]interface{}) ([]*PatchOperation, error) {
+	var ops []*PatchOperation
+	if err := collectPatches(jsonSchema, values, "", &ops); err != nil {
+		return nil, err
+	}
+	return ops, nil
+}
+
+func collectPatches(schema map[string]interface{}, value interface{},
+	property string, ops *[]*PatchOperation) error {
+	if keyword, ok := schema[PatchKeyword]; ok {
+		specs, err := parsePatchSpecs(keyword)
+		if err != nil {
+			return perror.Wrapf(herrors.ErrParamInvalid,
+				"invalid %s of property %s: %v", PatchKeyword, property, err)
+		}
+		for _, spec := range specs {
+			*ops = append(*ops, &PatchOperation{
+				Target: spec.Target,
+				Op:     _opAdd,
+				Path:   spec.Path,
+				Value:  value,
+			})
+		}
+	}
+
+	properties, ok := schema[_propertiesKey].(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	values, ok := value.(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	names := make([]string, 0, len(properties))
+	for name := range properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		v, ok := values[name]
+		if !ok {
+			continue
+		}
+		sub, ok := properties[name].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		subProperty := name
+		if property != "" {
+			subProperty = fmt.Sprintf("%s.%s", property, name)
+		}
+		if err := collectPatches(sub, v, subProperty, ops); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func parsePatchSpecs(keyword interface{}) ([]patchSpec, error) {
+	b, err := json.Marshal(keyword)
+	if err != nil {
+		return nil, err
+	}
+	var specs []patchSpec
+	if _, ok := keyword.([]interface{}); ok {
+		err = json.Unmarshal(b, &specs)
+	} else {
+		var spec patchSpec
+		err = json.Unmarshal(b, &spec)
+		specs = append(specs, spec)
+	}
+	if err != nil {
+		return nil, err
+	}
+	for _, spec := range specs {
+		if spec.Path == "" || spec.Target.Kind == "" {
+			return nil, fmt.Errorf("both path and target kind are required")
+		}
+	}
+	return specs, nil
+}
+
+// ImageName returns the image declared by ImageKeyword
+func ImageName(jsonSchema map[string]interface{}) string {
+	name, _ := jsonSchema[ImageKeyword].(string)
+	return name
+}
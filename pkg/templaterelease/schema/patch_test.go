@@ -0,0 +1,95 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schema
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPatches(t *testing.T) {
+	jsonSchema := map[string]interface{}{}
+	assert.Nil(t, json.Unmarshal([]byte(`{
+  "type": "object",
+  "x-kustomize-image": "app",
+  "properties": {
+    "app": {
+      "type": "object",
+      "properties": {
+        "spec": {
+          "type": "object",
+          "properties": {
+            "replicas": {
+              "type": "integer",
+              "x-kustomize-patch": {"target": {"kind": "Deployment", "name": "app"}, "path": "/spec/replicas"}
+            },
+            "port": {
+              "type": "integer",
+              "x-kustomize-patch": [
+                {"target": {"kind": "Service", "name": "app"}, "path": "/spec/ports/0/targetPort"},
+                {"target": {"group": "apps", "kind": "Deployment", "name": "app"},
+                 "path": "/spec/template/spec/containers/0/ports/0/containerPort"}
+              ]
+            },
+            "resource": {
+              "type": "string",
+              "x-kustomize-patch": {"target": {"kind": "Deployment"}, "path": "/metadata/annotations/resource"}
+            }
+          }
+        }
+      }
+    }
+  }
+}`), &jsonSchema))
+
+	values := map[string]interface{}{
+		"app": map[string]interface{}{
+			"spec": map[string]interface{}{
+				"replicas": 2,
+				"port":     8080,
+			},
+		},
+	}
+	ops, err := Patches(jsonSchema, values)
+	assert.Nil(t, err)
+	assert.Equal(t, []*PatchOperation{
+		{
+			Target: PatchTarget{Kind: "Service", Name: "app"},
+			Op:     "add",
+			Path:   "/spec/ports/0/targetPort",
+			Value:  8080,
+		}, {
+			Target: PatchTarget{Group: "apps", Kind: "Deployment", Name: "app"},
+			Op:     "add",
+			Path:   "/spec/template/spec/containers/0/ports/0/containerPort",
+			Value:  8080,
+		}, {
+			Target: PatchTarget{Kind: "Deployment", Name: "app"},
+			Op:     "add",
+			Path:   "/spec/replicas",
+			Value:  2,
+		},
+	}, ops)
+	assert.Equal(t, "app", ImageName(jsonSchema))
+
+	// path is required
+	jsonSchema["x-kustomize-patch"] = map[string]interface{}{
+		"target": map[string]interface{}{"kind": "Deployment"},
+	}
+	_, err = Patches(jsonSchema, values)
+	assert.NotNil(t, err)
+}
@@ -51,7 +51,7 @@ func (g *Getter) GetTemplateSchema(ctx context.Context,
 	if err != nil {
 		return nil, err
 	}
-	chartPkg, err := g.repo.GetChart(release.ChartName, release.ChartVersion, release.LastSyncAt)
+	chartPkg, err := g.repo.GetChart(release.ChartName, release.ChartReference(), release.LastSyncAt)
 	if err != nil {
 		return nil, err
 	}
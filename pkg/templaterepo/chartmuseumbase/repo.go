@@ -80,8 +80,8 @@ func NewRepo(config config.Repo) (templaterepo.TemplateRepo, error) {
 
 	tlsConf, err := tlsutil.NewClientTLS(config.CertFile, config.KeyFile, config.CAFile)
 	if err != nil {
-		return nil, perror.Wrap(herrors.NewErrCreateFailed(herrors.TLS, err.Error()),
-			"failed to create TLS: %v")
+		return nil, perror.Wrapf(herrors.ErrLoadCertFailed,
+			"failed to create TLS: %v", err)
 	}
 	tlsConf.InsecureSkipVerify = config.Insecure
 
@@ -114,32 +114,32 @@ func (h *Repo) GetLoc() string {
 	return fmt.Sprintf("%s://%s", h.host.Scheme, h.host.Host)
 }
 
-func (h *Repo) UploadChart(chartPkg *chart.Chart) error {
+func (h *Repo) UploadChart(chartPkg *chart.Chart) (string, error) {
 	var buf bytes.Buffer
 	bodyWriter := multipart.NewWriter(&buf)
 	chartWriter, err := bodyWriter.CreateFormFile("chart",
 		fmt.Sprintf("%s-%s", chartPkg.Metadata.Name, chartPkg.Metadata.Version))
 	if err != nil {
-		return perror.Wrap(herrors.ErrHTTPRequestFailed,
+		return "", perror.Wrap(herrors.ErrHTTPRequestFailed,
 			fmt.Sprintf("failed to create multipart writer: %v", err))
 	}
 
 	err = templaterepo.ChartSerialize(chartPkg, chartWriter)
 	if err != nil {
-		return err
+		return "", err
 	}
 
 	contentType := bodyWriter.FormDataContentType()
 	err = bodyWriter.Close()
 	if err != nil {
-		return perror.Wrap(herrors.ErrHTTPRequestFailed,
+		return "", perror.Wrap(herrors.ErrHTTPRequestFailed,
 			fmt.Sprintf("failed to create multipart writer: %v", err))
 	}
 
 	resp, err := h.do(http.MethodPost, h.uploadLink(),
 		ioutil.NopCloser(&buf), http.Header{"Content-Type": []string{contentType}})
 	if err != nil {
-		return err
+		return "", err
 	}
 	defer func() {
 		_ = resp.Body.Close()
@@ -149,13 +149,13 @@ func (h *Repo) UploadChart(chartPkg *chart.Chart) error {
 		var b []byte
 		b, err = ioutil.ReadAll(resp.Body)
 		if err != nil {
-			return perror.Wrap(herrors.ErrReadFailed,
+			return "", perror.Wrap(herrors.ErrReadFailed,
 				fmt.Sprintf("failed to read response: %v", err))
 		}
-		return perror.Wrap(herrors.ErrHTTPRespNotAsExpected,
+		return "", perror.Wrap(herrors.ErrHTTPRespNotAsExpected,
 			fmt.Sprintf("%s: %s", resp.Status, string(b)))
 	}
-	return nil
+	return "", nil
 }
 
 func (h *Repo) DeleteChart(name string, version string) error {
@@ -188,6 +188,11 @@ func (h *Repo) ExistChart(name string, version string) (bool, error) {
 	return true, nil
 }
 
+// GetChartDigest returns empty, since chartmuseum does not address charts by digest
+func (h *Repo) GetChartDigest(name string, version string) (string, error) {
+	return "", nil
+}
+
 func (h *Repo) GetChart(name string, version string, lastSyncAt time.Time) (*chart.Chart, error) {
 	resp, err := h.do(http.MethodGet, h.downloadLink(name, version), nil)
 	if err != nil {
@@ -90,8 +90,9 @@ func testRepo(t *testing.T) {
 	c.Metadata.Name = repoConfig.TemplateName
 	c.Metadata.Version = repoConfig.TemplateTag
 
-	err := repo.UploadChart(c)
+	digest, err := repo.UploadChart(c)
 	assert.Nil(t, err)
+	assert.Equal(t, "", digest)
 
 	tm := time.Now()
 	c, err = repo.GetChart(repoConfig.TemplateName, repoConfig.TemplateTag, tm)
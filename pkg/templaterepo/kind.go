@@ -0,0 +1,162 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package templaterepo
+
+import (
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strings"
+
+	herrors "github.com/horizoncd/horizon/core/errors"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chart/loader"
+)
+
+const (
+	// KindHelm is a helm chart, rendered by helm with the cluster's value files
+	KindHelm = "helm"
+	// KindKustomize is a kustomize base, cluster values are applied as kustomize patches
+	KindKustomize = "kustomize"
+	// KindRaw is a directory of plain kubernetes manifests, which are applied as they are
+	KindRaw = "raw"
+
+	// KindAnnotation records the kind of template in the annotations of Chart.yaml,
+	// templates without it are helm charts.
+	KindAnnotation = "horizon.io/template-kind"
+
+	// SchemaDir holds the json schemas of a template, it's not a part of the manifests
+	SchemaDir = "schema"
+)
+
+// horizonDirs hold the files read by horizon itself, such as schemas, outputs, analysis and migration specs
+var horizonDirs = []string{SchemaDir, "output", "analysis", "migration"}
+
+var kustomizationFileNames = []string{"kustomization.yaml", "kustomization.yml", "Kustomization"}
+
+// LoadArchive loads a gzipped template archive. Helm charts are loaded as they are,
+// kustomize bases and plain manifests are packed into a chart whose files are the
+// template's files, so that they can be stored in a chart repo as well.
+func LoadArchive(in io.Reader) (*chart.Chart, error) {
+	files, err := loader.LoadArchiveFiles(in)
+	if err != nil {
+		return nil, perror.Wrap(herrors.ErrLoadChartArchive,
+			fmt.Sprintf("failed to load archive: %v", err))
+	}
+
+	kind := detectKind(files)
+	if kind == KindHelm {
+		c, err := loader.LoadFiles(files)
+		if err != nil {
+			return nil, perror.Wrap(herrors.ErrLoadChartArchive,
+				fmt.Sprintf("failed to load archive: %v", err))
+		}
+		return c, nil
+	}
+
+	c := &chart.Chart{
+		Metadata: &chart.Metadata{
+			APIVersion:  chart.APIVersionV2,
+			Type:        "application",
+			Annotations: map[string]string{KindAnnotation: kind},
+		},
+	}
+	for _, f := range files {
+		c.Files = append(c.Files, &chart.File{Name: f.Name, Data: f.Data})
+	}
+	if len(TemplateFiles(c)) == 0 {
+		return nil, perror.Wrap(herrors.ErrLoadChartArchive,
+			"failed to load archive: no Chart.yaml, kustomization or manifests found")
+	}
+	return c, nil
+}
+
+func detectKind(files []*loader.BufferedFile) string {
+	kind := KindRaw
+	for _, f := range files {
+		if f.Name == ChartfileName {
+			return KindHelm
+		}
+		for _, name := range kustomizationFileNames {
+			if f.Name == name {
+				kind = KindKustomize
+			}
+		}
+	}
+	return kind
+}
+
+// KindOf returns the kind of template the chart was packed from
+func KindOf(c *chart.Chart) string {
+	if c == nil || c.Metadata == nil {
+		return KindHelm
+	}
+	switch kind := c.Metadata.Annotations[KindAnnotation]; kind {
+	case KindKustomize, KindRaw:
+		return kind
+	default:
+		return KindHelm
+	}
+}
+
+// TemplateFiles returns the files that make up a kustomize base or a set of plain manifests,
+// sorted by name. Chart.yaml and schemas are excluded, and so are the files that are not
+// manifests for the raw kind.
+func TemplateFiles(c *chart.Chart) []*chart.File {
+	kind := KindOf(c)
+	if kind == KindHelm {
+		return nil
+	}
+
+	// a chart loaded from repo holds every file in Raw, while a packed one only has Files
+	files := c.Raw
+	if len(files) == 0 {
+		files = c.Files
+	}
+	ret := make([]*chart.File, 0, len(files))
+	for _, f := range files {
+		if f.Name == ChartfileName || isHorizonFile(f.Name) {
+			continue
+		}
+		if kind == KindRaw && !isManifest(f.Name) {
+			continue
+		}
+		ret = append(ret, f)
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		return ret[i].Name < ret[j].Name
+	})
+	return ret
+}
+
+func isHorizonFile(name string) bool {
+	for _, dir := range horizonDirs {
+		if strings.HasPrefix(name, dir+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+func isManifest(name string) bool {
+	switch path.Ext(name) {
+	case ".yaml", ".yml", ".json":
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,102 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package templaterepo
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"helm.sh/helm/v3/pkg/chart/loader"
+)
+
+func archive(t *testing.T, files map[string]string) []byte {
+	var buf bytes.Buffer
+	gw := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gw)
+	for name, content := range files {
+		// archives of gitlab tags are prefixed with a directory
+		assert.Nil(t, tw.WriteHeader(&tar.Header{
+			Name: "javaapp-v1.0.0-33da3204/" + name,
+			Mode: 0644,
+			Size: int64(len(content)),
+		}))
+		_, err := tw.Write([]byte(content))
+		assert.Nil(t, err)
+	}
+	assert.Nil(t, tw.Close())
+	assert.Nil(t, gw.Close())
+	return buf.Bytes()
+}
+
+func TestLoadArchive(t *testing.T) {
+	schema := `{"type": "object"}`
+	deployment := "apiVersion: apps/v1\nkind: Deployment\nmetadata:\n  name: app\n"
+
+	// helm chart
+	c, err := LoadArchive(bytes.NewReader(archive(t, map[string]string{
+		"Chart.yaml":                     "apiVersion: v2\nname: javaapp\nversion: 1.0.0\n",
+		"templates/deployment.yaml":      deployment,
+		"schema/application.schema.json": schema,
+	})))
+	assert.Nil(t, err)
+	assert.Equal(t, KindHelm, KindOf(c))
+	assert.Nil(t, TemplateFiles(c))
+
+	// kustomize base
+	c, err = LoadArchive(bytes.NewReader(archive(t, map[string]string{
+		"kustomization.yaml":             "resources:\n- deployment.yaml\n",
+		"deployment.yaml":                deployment,
+		"config/app.properties":          "a=b",
+		"schema/application.schema.json": schema,
+	})))
+	assert.Nil(t, err)
+	assert.Equal(t, KindKustomize, KindOf(c))
+	c.Metadata.Name = "javaapp"
+	c.Metadata.Version = "v1.0.0-33da3204"
+
+	// files are kept after being stored in a chart repo
+	var buf bytes.Buffer
+	assert.Nil(t, ChartSerialize(c, &buf))
+	loaded, err := loader.LoadArchive(&buf)
+	assert.Nil(t, err)
+	assert.Equal(t, KindKustomize, KindOf(loaded))
+	var names []string
+	for _, f := range TemplateFiles(loaded) {
+		names = append(names, f.Name)
+	}
+	assert.Equal(t, []string{"config/app.properties", "deployment.yaml", "kustomization.yaml"}, names)
+
+	// plain manifests
+	c, err = LoadArchive(bytes.NewReader(archive(t, map[string]string{
+		"deployment.yaml":                deployment,
+		"README.md":                      "# javaapp",
+		"schema/application.schema.json": schema,
+		"migration/migration.yaml":       "migrations: []\n",
+	})))
+	assert.Nil(t, err)
+	assert.Equal(t, KindRaw, KindOf(c))
+	files := TemplateFiles(c)
+	assert.Equal(t, 1, len(files))
+	assert.Equal(t, "deployment.yaml", files[0].Name)
+
+	// nothing to deploy
+	_, err = LoadArchive(bytes.NewReader(archive(t, map[string]string{
+		"README.md": "# javaapp",
+	})))
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,570 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oci
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	herrors "github.com/horizoncd/horizon/core/errors"
+	config "github.com/horizoncd/horizon/pkg/config/templaterepo"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/templaterepo"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chart/loader"
+	"k8s.io/helm/pkg/tlsutil"
+)
+
+const (
+	kindOCI = "oci"
+
+	MediaTypeManifest    = "application/vnd.oci.image.manifest.v1+json"
+	MediaTypeHelmConfig  = "application/vnd.cncf.helm.config.v1+json"
+	MediaTypeHelmChart   = "application/vnd.cncf.helm.chart.content.v1.tar+gzip"
+	mediaTypeLegacyChart = "application/tar+gzip"
+
+	annotationTitle   = "org.opencontainers.image.title"
+	annotationVersion = "org.opencontainers.image.version"
+
+	headerContentDigest = "Docker-Content-Digest"
+	digestAlgorithm     = "sha256"
+)
+
+func init() {
+	templaterepo.Register(kindOCI, NewRepo)
+}
+
+// Descriptor describes a blob in registry
+type Descriptor struct {
+	MediaType   string            `json:"mediaType"`
+	Digest      string            `json:"digest"`
+	Size        int64             `json:"size"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+// Manifest is an OCI image manifest, which is how helm stores charts in registry
+type Manifest struct {
+	SchemaVersion int               `json:"schemaVersion"`
+	MediaType     string            `json:"mediaType,omitempty"`
+	Config        Descriptor        `json:"config"`
+	Layers        []Descriptor      `json:"layers"`
+	Annotations   map[string]string `json:"annotations,omitempty"`
+}
+
+// Repo stores charts in an OCI distribution registry as helm does,
+// at <host>/<repoName>/<chart name>:<version>.
+// A version like v1.0.0-33da3204@sha256:... pins the chart to the digest of its manifest.
+type Repo struct {
+	host     *url.URL
+	repoName string
+	username string
+	password string
+	token    string
+	client   *http.Client
+
+	// bearer tokens issued by the registry's auth server, by repository
+	tokens map[string]string
+	m      sync.Mutex
+}
+
+func NewRepo(config config.Repo) (templaterepo.TemplateRepo, error) {
+	host, err := url.Parse(config.Host)
+	if err != nil {
+		return nil, perror.Wrap(herrors.ErrParamInvalid,
+			fmt.Sprintf("url is incorrect: %v", err))
+	}
+	if host.Scheme == "" || host.Host == "" {
+		return nil, perror.Wrapf(herrors.ErrParamInvalid,
+			"url is incorrect: %s, scheme and host are required", config.Host)
+	}
+
+	tlsConf, err := tlsutil.NewClientTLS(config.CertFile, config.KeyFile, config.CAFile)
+	if err != nil {
+		return nil, perror.Wrapf(herrors.ErrLoadCertFailed,
+			"failed to create TLS: %v", err)
+	}
+	tlsConf.InsecureSkipVerify = config.Insecure
+
+	return &Repo{
+		host:     host,
+		repoName: strings.Trim(config.RepoName, "/"),
+		username: config.Username,
+		password: config.Password,
+		token:    config.Token,
+		client: &http.Client{
+			Transport: &http.Transport{
+				TLSClientConfig: tlsConf,
+			},
+		},
+		tokens: make(map[string]string),
+	}, nil
+}
+
+func (r *Repo) GetLoc() string {
+	return fmt.Sprintf("oci://%s", path.Join(r.host.Host, r.repoName))
+}
+
+func (r *Repo) UploadChart(chartPkg *chart.Chart) (string, error) {
+	var chartBuf bytes.Buffer
+	if err := templaterepo.ChartSerialize(chartPkg, &chartBuf); err != nil {
+		return "", err
+	}
+	configBytes, err := json.Marshal(chartPkg.Metadata)
+	if err != nil {
+		return "", perror.Wrap(herrors.ErrParamInvalid,
+			fmt.Sprintf("failed to marshal chart metadata: %v", err))
+	}
+
+	name := chartPkg.Metadata.Name
+	configDesc, err := r.pushBlob(name, MediaTypeHelmConfig, configBytes)
+	if err != nil {
+		return "", err
+	}
+	chartDesc, err := r.pushBlob(name, MediaTypeHelmChart, chartBuf.Bytes())
+	if err != nil {
+		return "", err
+	}
+
+	manifest, err := json.Marshal(&Manifest{
+		SchemaVersion: 2,
+		MediaType:     MediaTypeManifest,
+		Config:        *configDesc,
+		Layers:        []Descriptor{*chartDesc},
+		Annotations: map[string]string{
+			annotationTitle:   name,
+			annotationVersion: chartPkg.Metadata.Version,
+		},
+	})
+	if err != nil {
+		return "", perror.Wrap(herrors.ErrParamInvalid,
+			fmt.Sprintf("failed to marshal manifest: %v", err))
+	}
+
+	resp, err := r.do(name, http.MethodPut, r.manifestLink(name, tagOf(chartPkg.Metadata.Version)),
+		manifest, http.Header{"Content-Type": []string{MediaTypeManifest}})
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusCreated {
+		return "", unexpectedResponse(resp)
+	}
+	// the digest of manifest pins the chart, while the tag can be moved by uploading again
+	return digestOf(manifest), nil
+}
+
+func (r *Repo) DeleteChart(name string, version string) error {
+	// registries only delete manifests by digest
+	digest, err := r.resolve(name, version)
+	if err != nil {
+		return err
+	}
+	resp, err := r.do(name, http.MethodDelete, r.manifestLink(name, digest), nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode == http.StatusNotFound {
+		return notFound(resp, name, version)
+	}
+	if resp.StatusCode != http.StatusAccepted && resp.StatusCode != http.StatusOK {
+		return unexpectedResponse(resp)
+	}
+	return nil
+}
+
+func (r *Repo) ExistChart(name string, version string) (bool, error) {
+	_, err := r.resolve(name, version)
+	if err != nil {
+		if _, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); ok {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+func (r *Repo) GetChartDigest(name string, version string) (string, error) {
+	return r.resolve(name, version)
+}
+
+func (r *Repo) GetChart(name string, version string, lastSyncAt time.Time) (*chart.Chart, error) {
+	tag, digest := splitVersion(version)
+	reference := tag
+	if digest != "" {
+		reference = digest
+	}
+
+	resp, err := r.do(name, http.MethodGet, r.manifestLink(name, reference), nil,
+		http.Header{"Accept": []string{MediaTypeManifest}})
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, notFound(resp, name, version)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, unexpectedResponse(resp)
+	}
+	b, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, perror.Wrap(herrors.ErrReadFailed,
+			fmt.Sprintf("failed to read response: %v", err))
+	}
+	if digest != "" {
+		if err := verify(digest, b); err != nil {
+			return nil, err
+		}
+	}
+
+	var manifest Manifest
+	if err := json.Unmarshal(b, &manifest); err != nil {
+		return nil, perror.Wrap(herrors.ErrParamInvalid,
+			fmt.Sprintf("could not unmarshal manifest: %v", err))
+	}
+	for _, layer := range manifest.Layers {
+		if layer.MediaType != MediaTypeHelmChart && layer.MediaType != mediaTypeLegacyChart {
+			continue
+		}
+		content, err := r.pullBlob(name, layer)
+		if err != nil {
+			return nil, err
+		}
+		chartPackage, err := loader.LoadArchive(bytes.NewReader(content))
+		if err != nil {
+			return nil, perror.Wrap(herrors.ErrLoadChartArchive,
+				fmt.Sprintf("failed to load archive: %v", err))
+		}
+		return chartPackage, nil
+	}
+	return nil, perror.Wrapf(herrors.ErrParamInvalid,
+		"no chart layer in manifest, chart name = %s version = %s", name, version)
+}
+
+// resolve returns the digest of manifest of the chart
+func (r *Repo) resolve(name, version string) (string, error) {
+	tag, digest := splitVersion(version)
+	reference := tag
+	if digest != "" {
+		reference = digest
+	}
+	resp, err := r.do(name, http.MethodHead, r.manifestLink(name, reference), nil,
+		http.Header{"Accept": []string{MediaTypeManifest}})
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode == http.StatusNotFound {
+		return "", notFound(resp, name, version)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", unexpectedResponse(resp)
+	}
+	if digest != "" {
+		return digest, nil
+	}
+	digest = resp.Header.Get(headerContentDigest)
+	if digest == "" {
+		return "", perror.Wrapf(herrors.ErrHTTPRespNotAsExpected,
+			"no %s in response, chart name = %s version = %s", headerContentDigest, name, version)
+	}
+	return digest, nil
+}
+
+func (r *Repo) pushBlob(name, mediaType string, content []byte) (*Descriptor, error) {
+	desc := &Descriptor{
+		MediaType: mediaType,
+		Digest:    digestOf(content),
+		Size:      int64(len(content)),
+	}
+
+	resp, err := r.do(name, http.MethodHead, r.blobLink(name, desc.Digest), nil)
+	if err != nil {
+		return nil, err
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode == http.StatusOK {
+		return desc, nil
+	}
+
+	resp, err = r.do(name, http.MethodPost, r.blobUploadLink(name), nil)
+	if err != nil {
+		return nil, err
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusAccepted {
+		return nil, unexpectedResponse(resp)
+	}
+	location, err := resp.Location()
+	if err != nil {
+		return nil, perror.Wrap(herrors.ErrHTTPRespNotAsExpected,
+			fmt.Sprintf("invalid location of blob upload: %v", err))
+	}
+	query := location.Query()
+	query.Set("digest", desc.Digest)
+	location.RawQuery = query.Encode()
+
+	resp, err = r.do(name, http.MethodPut, location.String(), content,
+		http.Header{"Content-Type": []string{"application/octet-stream"}})
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusCreated {
+		return nil, unexpectedResponse(resp)
+	}
+	return desc, nil
+}
+
+func (r *Repo) pullBlob(name string, desc Descriptor) ([]byte, error) {
+	resp, err := r.do(name, http.MethodGet, r.blobLink(name, desc.Digest), nil)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		return nil, unexpectedResponse(resp)
+	}
+	content, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, perror.Wrap(herrors.ErrReadFailed,
+			fmt.Sprintf("failed to read response: %v", err))
+	}
+	if err := verify(desc.Digest, content); err != nil {
+		return nil, err
+	}
+	return content, nil
+}
+
+// do sends request with credentials, when registry challenges for a bearer token,
+// the token is requested from its auth server and the request is retried once.
+func (r *Repo) do(name, method, link string, body []byte, headers ...http.Header) (*http.Response, error) {
+	send := func() (*http.Response, error) {
+		req, err := http.NewRequest(method, link, bytes.NewReader(body))
+		if err != nil {
+			return nil, perror.Wrap(herrors.ErrHTTPRequestFailed,
+				fmt.Sprintf("failed to create request: %v", err))
+		}
+		for _, header := range headers {
+			for k, values := range header {
+				for _, v := range values {
+					req.Header.Add(k, v)
+				}
+			}
+		}
+		r.authorize(req, name)
+		resp, err := r.client.Do(req)
+		if err != nil {
+			return nil, perror.Wrap(herrors.ErrHTTPRequestFailed,
+				fmt.Sprintf("failed to send request: %v", err))
+		}
+		return resp, nil
+	}
+
+	resp, err := send()
+	if err != nil || resp.StatusCode != http.StatusUnauthorized {
+		return resp, err
+	}
+	challenge := parseChallenge(resp.Header.Get("WWW-Authenticate"))
+	if challenge == nil {
+		return resp, nil
+	}
+	_ = resp.Body.Close()
+	if err := r.fetchToken(name, challenge); err != nil {
+		return nil, err
+	}
+	return send()
+}
+
+func (r *Repo) authorize(req *http.Request, name string) {
+	r.m.Lock()
+	token, ok := r.tokens[name]
+	r.m.Unlock()
+	switch {
+	case ok:
+		req.Header.Set("Authorization", "Bearer "+token)
+	case r.token != "":
+		req.Header.Set("Authorization", "Bearer "+r.token)
+	case r.username != "":
+		req.SetBasicAuth(r.username, r.password)
+	}
+}
+
+type tokenResponse struct {
+	Token       string `json:"token"`
+	AccessToken string `json:"access_token"`
+}
+
+func (r *Repo) fetchToken(name string, challenge map[string]string) error {
+	realm, err := url.Parse(challenge["realm"])
+	if err != nil || realm.Host == "" {
+		return perror.Wrapf(herrors.ErrHTTPRespNotAsExpected,
+			"invalid realm of auth challenge: %s", challenge["realm"])
+	}
+	query := realm.Query()
+	for _, key := range []string{"service", "scope"} {
+		if v := challenge[key]; v != "" {
+			query.Set(key, v)
+		}
+	}
+	realm.RawQuery = query.Encode()
+
+	req, err := http.NewRequest(http.MethodGet, realm.String(), nil)
+	if err != nil {
+		return perror.Wrap(herrors.ErrHTTPRequestFailed,
+			fmt.Sprintf("failed to create request: %v", err))
+	}
+	if r.username != "" {
+		req.SetBasicAuth(r.username, r.password)
+	} else if r.token != "" {
+		req.Header.Set("Authorization", "Bearer "+r.token)
+	}
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return perror.Wrap(herrors.ErrHTTPRequestFailed,
+			fmt.Sprintf("failed to request token: %v", err))
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		return unexpectedResponse(resp)
+	}
+	var token tokenResponse
+	if err := json.NewDecoder(resp.Body).Decode(&token); err != nil {
+		return perror.Wrap(herrors.ErrHTTPRespNotAsExpected,
+			fmt.Sprintf("could not unmarshal token: %v", err))
+	}
+	if token.Token == "" {
+		token.Token = token.AccessToken
+	}
+	if token.Token == "" {
+		return perror.Wrap(herrors.ErrHTTPRespNotAsExpected, "no token issued by auth server")
+	}
+
+	r.m.Lock()
+	defer r.m.Unlock()
+	r.tokens[name] = token.Token
+	return nil
+}
+
+// parseChallenge parses a bearer challenge like
+// Bearer realm="https://auth.example.com/token",service="registry",scope="repository:a/b:pull,push"
+func parseChallenge(header string) map[string]string {
+	const scheme = "bearer "
+	if len(header) < len(scheme) || !strings.EqualFold(header[:len(scheme)], scheme) {
+		return nil
+	}
+	params := make(map[string]string)
+	rest := strings.TrimSpace(header[len(scheme):])
+	for rest != "" {
+		i := strings.Index(rest, "=")
+		if i < 0 {
+			break
+		}
+		key := strings.ToLower(strings.TrimSpace(rest[:i]))
+		rest = rest[i+1:]
+		var value string
+		if strings.HasPrefix(rest, `"`) {
+			end := strings.Index(rest[1:], `"`)
+			if end < 0 {
+				break
+			}
+			value, rest = rest[1:end+1], rest[end+2:]
+		} else if end := strings.Index(rest, ","); end >= 0 {
+			value, rest = rest[:end], rest[end:]
+		} else {
+			value, rest = rest, ""
+		}
+		params[key] = value
+		rest = strings.TrimLeft(rest, ", ")
+	}
+	if params["realm"] == "" {
+		return nil
+	}
+	return params
+}
+
+func (r *Repo) repository(name string) string {
+	return path.Join(r.repoName, name)
+}
+
+func (r *Repo) linkWithSchemeAndHost() string {
+	return fmt.Sprintf("%s://%s", r.host.Scheme, r.host.Host)
+}
+
+func (r *Repo) manifestLink(name, reference string) string {
+	return fmt.Sprintf("%s/v2/%s/manifests/%s", r.linkWithSchemeAndHost(), r.repository(name), reference)
+}
+
+func (r *Repo) blobLink(name, digest string) string {
+	return fmt.Sprintf("%s/v2/%s/blobs/%s", r.linkWithSchemeAndHost(), r.repository(name), digest)
+}
+
+func (r *Repo) blobUploadLink(name string) string {
+	return fmt.Sprintf("%s/v2/%s/blobs/uploads/", r.linkWithSchemeAndHost(), r.repository(name))
+}
+
+// splitVersion splits version into tag and digest, digest is empty if not pinned
+func splitVersion(version string) (tag, digest string) {
+	if i := strings.Index(version, "@"); i >= 0 {
+		return tagOf(version[:i]), version[i+1:]
+	}
+	return tagOf(version), ""
+}
+
+// tagOf converts chart version to tag, '+' is not allowed in tags, helm replaces it with '_'
+func tagOf(version string) string {
+	return strings.ReplaceAll(version, "+", "_")
+}
+
+func digestOf(content []byte) string {
+	return fmt.Sprintf("%s:%x", digestAlgorithm, sha256.Sum256(content))
+}
+
+func verify(digest string, content []byte) error {
+	if !strings.HasPrefix(digest, digestAlgorithm+":") {
+		return perror.Wrapf(herrors.ErrParamInvalid, "unsupported digest: %s", digest)
+	}
+	if actual := digestOf(content); actual != digest {
+		return perror.Wrapf(herrors.ErrParamInvalid,
+			"digest mismatch, expected %s, actual %s", digest, actual)
+	}
+	return nil
+}
+
+func notFound(resp *http.Response, name, version string) error {
+	return perror.Wrap(herrors.NewErrNotFound(herrors.TemplateReleaseInRepo,
+		fmt.Sprintf("%s: chart name = %s version = %s", resp.Status, name, version)),
+		"not found")
+}
+
+func unexpectedResponse(resp *http.Response) error {
+	b, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return perror.Wrap(herrors.ErrReadFailed,
+			fmt.Sprintf("failed to read response: %v", err))
+	}
+	return perror.Wrap(herrors.ErrHTTPRespNotAsExpected,
+		fmt.Sprintf("%s: %s", resp.Status, string(b)))
+}
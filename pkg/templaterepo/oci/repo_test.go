@@ -0,0 +1,276 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oci
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	herrors "github.com/horizoncd/horizon/core/errors"
+	config "github.com/horizoncd/horizon/pkg/config/templaterepo"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/templaterepo"
+	"github.com/stretchr/testify/assert"
+	"helm.sh/helm/v3/pkg/chart"
+)
+
+const (
+	_username    = "robot"
+	_password    = "secret"
+	_staticToken = "static-token"
+	_issuedToken = "issued-token"
+)
+
+// registry is an in-process stub of OCI distribution registry with a token auth server
+type registry struct {
+	server    *httptest.Server
+	m         sync.Mutex
+	blobs     map[string][]byte
+	manifests map[string][]byte
+	// tampered manifests are served instead of the stored ones
+	tampered map[string][]byte
+}
+
+func newRegistry() *registry {
+	r := &registry{
+		blobs:     make(map[string][]byte),
+		manifests: make(map[string][]byte),
+		tampered:  make(map[string][]byte),
+	}
+	r.server = httptest.NewServer(http.HandlerFunc(r.serve))
+	return r
+}
+
+func (r *registry) serve(w http.ResponseWriter, req *http.Request) {
+	if req.URL.Path == "/token" {
+		user, password, ok := req.BasicAuth()
+		if !ok || user != _username || password != _password {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]string{"token": _issuedToken})
+		return
+	}
+
+	auth := req.Header.Get("Authorization")
+	if auth != "Bearer "+_issuedToken && auth != "Bearer "+_staticToken {
+		w.Header().Set("WWW-Authenticate", fmt.Sprintf(
+			`Bearer realm="%s/token",service="registry",scope="repository:horizon/javaapp:pull,push"`, r.server.URL))
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	r.m.Lock()
+	defer r.m.Unlock()
+	parts := strings.SplitN(strings.TrimPrefix(req.URL.Path, "/v2/"), "/", 3)
+	repository := parts[0] + "/" + parts[1]
+	rest := parts[2]
+	switch {
+	case rest == "blobs/uploads/" && req.Method == http.MethodPost:
+		w.Header().Set("Location", fmt.Sprintf("/v2/%s/blobs/uploads/upload-1?state=abc", repository))
+		w.WriteHeader(http.StatusAccepted)
+	case strings.HasPrefix(rest, "blobs/uploads/") && req.Method == http.MethodPut:
+		content, _ := ioutil.ReadAll(req.Body)
+		digest := req.URL.Query().Get("digest")
+		if digestOf(content) != digest || req.URL.Query().Get("state") != "abc" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		r.blobs[digest] = content
+		w.WriteHeader(http.StatusCreated)
+	case strings.HasPrefix(rest, "blobs/"):
+		content, ok := r.blobs[strings.TrimPrefix(rest, "blobs/")]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write(content)
+	case strings.HasPrefix(rest, "manifests/"):
+		key := repository + ":" + strings.TrimPrefix(rest, "manifests/")
+		switch req.Method {
+		case http.MethodPut:
+			content, _ := ioutil.ReadAll(req.Body)
+			digest := digestOf(content)
+			r.manifests[key] = content
+			r.manifests[repository+":"+digest] = content
+			w.Header().Set(headerContentDigest, digest)
+			w.WriteHeader(http.StatusCreated)
+		case http.MethodDelete:
+			content, ok := r.manifests[key]
+			if !ok || !strings.HasPrefix(key, repository+":sha256:") {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			for k, v := range r.manifests {
+				if string(v) == string(content) {
+					delete(r.manifests, k)
+				}
+			}
+			w.WriteHeader(http.StatusAccepted)
+		default:
+			content, ok := r.manifests[key]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			w.Header().Set(headerContentDigest, digestOf(content))
+			w.Header().Set("Content-Type", MediaTypeManifest)
+			if tampered, ok := r.tampered[key]; ok {
+				content = tampered
+			}
+			_, _ = w.Write(content)
+		}
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func newChart(version string) *chart.Chart {
+	return &chart.Chart{
+		Metadata: &chart.Metadata{
+			APIVersion: chart.APIVersionV2,
+			Name:       "javaapp",
+			Version:    version,
+		},
+		Files: []*chart.File{
+			{Name: "schema/application.schema.json", Data: []byte(`{"type": "object"}`)},
+		},
+	}
+}
+
+func TestRepo(t *testing.T) {
+	r := newRegistry()
+	defer r.server.Close()
+
+	repo, err := NewRepo(config.Repo{
+		Kind:     kindOCI,
+		Host:     r.server.URL,
+		Username: _username,
+		Password: _password,
+		RepoName: "horizon",
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, "oci://"+strings.TrimPrefix(r.server.URL, "http://")+"/horizon", repo.GetLoc())
+
+	version := "v1.0.0-33da3204"
+	exist, err := repo.ExistChart("javaapp", version)
+	assert.Nil(t, err)
+	assert.False(t, exist)
+	_, err = repo.GetChart("javaapp", version, time.Now())
+	_, ok := perror.Cause(err).(*herrors.HorizonErrNotFound)
+	assert.True(t, ok)
+
+	uploadedDigest, err := repo.UploadChart(newChart(version))
+	assert.Nil(t, err)
+	exist, err = repo.ExistChart("javaapp", version)
+	assert.Nil(t, err)
+	assert.True(t, exist)
+
+	c, err := repo.GetChart("javaapp", version, time.Now())
+	assert.Nil(t, err)
+	assert.Equal(t, version, c.Metadata.Version)
+	assert.Equal(t, 1, len(c.Files))
+	assert.Equal(t, "schema/application.schema.json", c.Files[0].Name)
+
+	// pinned by digest
+	digest, err := repo.(*Repo).resolve("javaapp", version)
+	assert.Nil(t, err)
+	assert.Equal(t, uploadedDigest, digest)
+	c, err = repo.GetChart("javaapp", version+"@"+digest, time.Now())
+	assert.Nil(t, err)
+	assert.Equal(t, version, c.Metadata.Version)
+	assert.Nil(t, templaterepo.CheckChartDigest(repo, "javaapp", version, digest))
+
+	// content which does not match the pinned digest is rejected
+	r.tampered["horizon/javaapp:"+digest] = []byte(`{"schemaVersion": 2}`)
+	_, err = repo.GetChart("javaapp", version+"@"+digest, time.Now())
+	assert.NotNil(t, err)
+	assert.True(t, strings.Contains(err.Error(), "digest mismatch"))
+	delete(r.tampered, "horizon/javaapp:"+digest)
+
+	// the tag is moved by uploading again, while the pinned one is kept
+	resyncedDigest, err := repo.UploadChart(func() *chart.Chart {
+		c := newChart(version)
+		c.Metadata.Description = "resynced"
+		return c
+	}())
+	assert.Nil(t, err)
+	assert.NotEqual(t, digest, resyncedDigest)
+	// deploying the release pinned to the former digest is refused once the tag is re-pushed
+	err = templaterepo.CheckChartDigest(repo, "javaapp", version, digest)
+	assert.Equal(t, herrors.ErrChartDigestMismatch, perror.Cause(err))
+	assert.Nil(t, templaterepo.CheckChartDigest(repo, "javaapp", version, resyncedDigest))
+	c, err = repo.GetChart("javaapp", version, time.Now())
+	assert.Nil(t, err)
+	assert.Equal(t, "resynced", c.Metadata.Description)
+	c, err = repo.GetChart("javaapp", version+"@"+digest, time.Now())
+	assert.Nil(t, err)
+	assert.Equal(t, "", c.Metadata.Description)
+	c, err = repo.GetChart("javaapp", version+"@"+resyncedDigest, time.Now())
+	assert.Nil(t, err)
+	assert.Equal(t, "resynced", c.Metadata.Description)
+
+	assert.Nil(t, repo.DeleteChart("javaapp", version))
+	exist, err = repo.ExistChart("javaapp", version)
+	assert.Nil(t, err)
+	assert.False(t, exist)
+}
+
+func TestRepoAuth(t *testing.T) {
+	r := newRegistry()
+	defer r.server.Close()
+
+	// static token is sent as it is
+	repo, err := NewRepo(config.Repo{
+		Kind:     kindOCI,
+		Host:     r.server.URL,
+		Token:    _staticToken,
+		RepoName: "horizon",
+	})
+	assert.Nil(t, err)
+	_, err = repo.UploadChart(newChart("v1.0.0"))
+	assert.Nil(t, err)
+
+	// no token is issued with wrong password
+	repo, err = NewRepo(config.Repo{
+		Kind:     kindOCI,
+		Host:     r.server.URL,
+		Username: _username,
+		Password: "wrong",
+		RepoName: "horizon",
+	})
+	assert.Nil(t, err)
+	_, err = repo.ExistChart("javaapp", "v1.0.0")
+	assert.NotNil(t, err)
+	assert.Equal(t, herrors.ErrHTTPRespNotAsExpected, perror.Cause(err))
+}
+
+func TestParseChallenge(t *testing.T) {
+	assert.Equal(t, map[string]string{
+		"realm":   "https://auth.example.com/token",
+		"service": "registry",
+		"scope":   "repository:a/b:pull,push",
+	}, parseChallenge(`Bearer realm="https://auth.example.com/token",service="registry",`+
+		`scope="repository:a/b:pull,push"`))
+	assert.Nil(t, parseChallenge(`Basic realm="registry"`))
+	assert.Equal(t, "v1.0.0_build.1", tagOf("v1.0.0+build.1"))
+}
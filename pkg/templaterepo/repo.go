@@ -51,10 +51,32 @@ func NewRepo(config templaterepo.Repo) (TemplateRepo, error) {
 //go:generate mockgen -source=$GOFILE -destination=../../mock/pkg/templaterepo/mock_repo.go -package=mock_repo
 type TemplateRepo interface {
 	GetLoc() string
-	UploadChart(chart *chart.Chart) error
+	// UploadChart uploads the chart, and returns the digest which the uploaded chart can be pinned to,
+	// the digest is empty if the repo does not address charts by digest
+	UploadChart(chart *chart.Chart) (string, error)
 	DeleteChart(name string, version string) error
 	ExistChart(name string, version string) (bool, error)
 	GetChart(name string, version string, lastSyncAt time.Time) (*chart.Chart, error)
+	// GetChartDigest returns the digest which the version of the chart points to now,
+	// the digest is empty if the repo does not address charts by digest
+	GetChartDigest(name string, version string) (string, error)
+}
+
+// CheckChartDigest makes sure the version of the chart still points to the digest uploaded for the release,
+// the version could be moved by uploading again, and deploying it would deploy a chart which is not validated
+func CheckChartDigest(repo TemplateRepo, name, version, digest string) error {
+	if digest == "" {
+		return nil
+	}
+	current, err := repo.GetChartDigest(name, version)
+	if err != nil {
+		return err
+	}
+	if current != digest {
+		return perror.Wrapf(herrors.ErrChartDigestMismatch,
+			"chart %s:%s points to %s instead of %s", name, version, current, digest)
+	}
+	return nil
 }
 
 type RepoWithCache struct {
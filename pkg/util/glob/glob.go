@@ -0,0 +1,79 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package glob
+
+import (
+	"path"
+	"strings"
+
+	herrors "github.com/horizoncd/horizon/core/errors"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+)
+
+const _doubleStar = "**"
+
+// Validate checks the syntax of the glob
+func Validate(pattern string) error {
+	if pattern == "" {
+		return perror.Wrap(herrors.ErrParamInvalid, "glob is empty")
+	}
+	for _, segment := range strings.Split(pattern, "/") {
+		if segment == _doubleStar {
+			continue
+		}
+		if _, err := path.Match(segment, ""); err != nil {
+			return perror.Wrapf(herrors.ErrParamInvalid, "invalid glob %q: %v", pattern, err)
+		}
+	}
+	return nil
+}
+
+// Match tells whether the slash separated name matches the glob, which supports the syntax of
+// path.Match in each segment, plus ** matching zero or more segments, e.g. services/**/*.go
+func Match(pattern, name string) bool {
+	return match(strings.Split(strings.Trim(pattern, "/"), "/"), strings.Split(strings.Trim(name, "/"), "/"))
+}
+
+// MatchAny tells whether the name matches any of the globs
+func MatchAny(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		if Match(pattern, name) {
+			return true
+		}
+	}
+	return false
+}
+
+func match(patterns, names []string) bool {
+	for len(patterns) > 0 {
+		if patterns[0] == _doubleStar {
+			// try to let ** match 0, 1, ... segments
+			for i := 0; i <= len(names); i++ {
+				if match(patterns[1:], names[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(names) == 0 {
+			return false
+		}
+		if ok, _ := path.Match(patterns[0], names[0]); !ok {
+			return false
+		}
+		patterns, names = patterns[1:], names[1:]
+	}
+	return len(names) == 0
+}
@@ -0,0 +1,50 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package glob
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMatch(t *testing.T) {
+	cases := []struct {
+		pattern string
+		name    string
+		matched bool
+	}{
+		{"*.go", "main.go", true},
+		{"*.go", "cmd/main.go", false},
+		{"**/*.go", "main.go", true},
+		{"**/*.go", "cmd/app/main.go", true},
+		{"services/api/**", "services/api/handler/user.go", true},
+		{"services/api/**", "services/api", true},
+		{"services/api/**", "services/web/index.html", false},
+		{"services/**/Dockerfile", "services/api/Dockerfile", true},
+		{"services/**/Dockerfile", "services/api/docs/README.md", false},
+		{"docs/[a-c]?.md", "docs/a1.md", true},
+		{"/docs/", "docs", true},
+	}
+	for _, c := range cases {
+		assert.Equal(t, c.matched, Match(c.pattern, c.name), "%s ~ %s", c.pattern, c.name)
+	}
+	assert.True(t, MatchAny([]string{"docs/**", "**/*.go"}, "pkg/util/glob/glob.go"))
+	assert.False(t, MatchAny(nil, "main.go"))
+
+	assert.Nil(t, Validate("services/**/*.go"))
+	assert.NotNil(t, Validate("services/[a-"))
+	assert.NotNil(t, Validate(""))
+}
@@ -17,6 +17,7 @@ package dao
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"gorm.io/gorm"
 
@@ -34,6 +35,7 @@ type DAO interface {
 		resources map[string][]uint, query *q.Query) ([]*models.Webhook, int64, error)
 	ListWebhooks(ctx context.Context) ([]*models.Webhook, error)
 	UpdateWebhook(ctx context.Context, id uint, w *models.Webhook) (*models.Webhook, error)
+	ClearExpiredPreviousSecrets(ctx context.Context, now time.Time) (int64, error)
 	DeleteWebhook(ctx context.Context, id uint) error
 	CreateWebhookLog(ctx context.Context, wl *models.WebhookLog) (*models.WebhookLog, error)
 	CreateWebhookLogs(ctx context.Context, wls []*models.WebhookLog) ([]*models.WebhookLog, error)
@@ -41,6 +43,7 @@ type DAO interface {
 		resources map[string][]uint) ([]*models.WebhookLogWithEventInfo, int64, error)
 	ListWebhookLogsByStatus(ctx context.Context, wID uint,
 		status string) ([]*models.WebhookLog, error)
+	ListWebhookLogsToSend(ctx context.Context, wID uint, now time.Time) ([]*models.WebhookLog, error)
 	ListWebhookLogsByMap(ctx context.Context,
 		webhookEventMap map[uint][]uint) ([]*models.WebhookLog, error)
 	UpdateWebhookLog(ctx context.Context, wl *models.WebhookLog) (*models.WebhookLog, error)
@@ -48,6 +51,10 @@ type DAO interface {
 	GetWebhookLogByEventID(ctx context.Context, webhookID, eventID uint) (*models.WebhookLog, error)
 	GetMaxEventIDOfLog(ctx context.Context) (uint, error)
 	DeleteWebhookLogs(ctx context.Context, id ...uint) (int64, error)
+	RedeliverWebhookLog(ctx context.Context, id uint) error
+	RedeliverWebhookLogs(ctx context.Context, wID uint, start, end time.Time) (int64, error)
+	CreateWebhookLogAttempt(ctx context.Context, attempt *models.WebhookLogAttempt) error
+	ListWebhookLogAttempts(ctx context.Context, logIDs ...uint) ([]*models.WebhookLogAttempt, error)
 }
 
 type dao struct{ db *gorm.DB }
@@ -116,6 +123,9 @@ func (d *dao) ListWebhookOfResources(ctx context.Context,
 		if v, ok := query.Keywords[common.Enabled]; ok {
 			statement = statement.Where("enabled = ?", v)
 		}
+		if v, ok := query.Keywords[common.Channel]; ok {
+			statement = statement.Where("channel in ?", v)
+		}
 	}
 
 	if result := statement.
@@ -130,13 +140,27 @@ func (d *dao) ListWebhookOfResources(ctx context.Context,
 func (d *dao) UpdateWebhook(ctx context.Context, id uint,
 	w *models.Webhook) (*models.Webhook, error) {
 	if result := d.db.WithContext(ctx).Where("id = ?", id).
-		Select("enabled", "url", "enable_ssl_verify", "description", "secret", "triggers").
+		Select("enabled", "url", "enable_ssl_verify", "description", "secret", "triggers", "retry_policy",
+			"previous_secret", "previous_secret_expired_at", "content_mode", "channel_config").
 		Updates(w); result.Error != nil {
 		return nil, herrors.NewErrUpdateFailed(herrors.WebhookInDB, result.Error.Error())
 	}
 	return w, nil
 }
 
+func (d *dao) ClearExpiredPreviousSecrets(ctx context.Context, now time.Time) (int64, error) {
+	result := d.db.WithContext(ctx).Model(&models.Webhook{}).
+		Where("previous_secret != '' and previous_secret_expired_at < ?", now).
+		Updates(map[string]interface{}{
+			"previous_secret":            "",
+			"previous_secret_expired_at": nil,
+		})
+	if result.Error != nil {
+		return 0, herrors.NewErrUpdateFailed(herrors.WebhookInDB, result.Error.Error())
+	}
+	return result.RowsAffected, nil
+}
+
 func (d *dao) DeleteWebhook(ctx context.Context, id uint) error {
 	deleteFunc := func(tx *gorm.DB) error {
 		if result := d.db.WithContext(ctx).Where("webhook_id = ?", id).
@@ -229,6 +253,23 @@ func (d *dao) ListWebhookLogs(ctx context.Context, query *q.Query,
 		return nil, 0, herrors.NewErrGetFailed(herrors.WebhookLogInDB, result.Error.Error())
 	}
 
+	// attach attempts of the logs
+	logIDs := make([]uint, 0, len(logs))
+	for _, l := range logs {
+		logIDs = append(logIDs, l.ID)
+	}
+	attempts, err := d.ListWebhookLogAttempts(ctx, logIDs...)
+	if err != nil {
+		return nil, 0, err
+	}
+	history := make(map[uint][]*models.WebhookLogAttempt)
+	for _, attempt := range attempts {
+		history[attempt.WebhookLogID] = append(history[attempt.WebhookLogID], attempt)
+	}
+	for _, l := range logs {
+		l.History = history[l.ID]
+	}
+
 	return logs, count, nil
 }
 
@@ -268,10 +309,23 @@ func (d *dao) ListWebhookLogsByStatus(ctx context.Context, wID uint,
 	return ws, nil
 }
 
+// ListWebhookLogsToSend lists the waiting logs and the retrying logs whose backoff has passed
+func (d *dao) ListWebhookLogsToSend(ctx context.Context, wID uint, now time.Time) ([]*models.WebhookLog, error) {
+	var ws []*models.WebhookLog
+	if result := d.db.WithContext(ctx).Where("webhook_id = ?", wID).
+		Where(d.db.Where("status = ?", models.StatusWaiting).
+			Or("status = ? and next_attempt_at <= ?", models.StatusRetrying, now)).
+		Order("id").
+		Find(&ws); result.Error != nil {
+		return nil, herrors.NewErrGetFailed(herrors.WebhookLogInDB, result.Error.Error())
+	}
+	return ws, nil
+}
+
 func (d *dao) UpdateWebhookLog(ctx context.Context, wl *models.WebhookLog) (*models.WebhookLog, error) {
 	if result := d.db.WithContext(ctx).Where("id = ?", wl.ID).
 		Select("status", "response_headers", "response_body",
-			"status", "error_message").
+			"status", "error_message", "attempts", "next_attempt_at").
 		Updates(wl); result.Error != nil {
 		return nil, herrors.NewErrUpdateFailed(herrors.WebhookLogInDB, result.Error.Error())
 	}
@@ -314,6 +368,61 @@ func (d *dao) DeleteWebhookLogs(ctx context.Context, ids ...uint) (int64, error)
 	return result.RowsAffected, nil
 }
 
+// RedeliverWebhookLog makes the log waiting to be sent again, its attempts are counted from zero
+func (d *dao) RedeliverWebhookLog(ctx context.Context, id uint) error {
+	result := d.db.WithContext(ctx).Model(&models.WebhookLog{}).Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"status":          models.StatusWaiting,
+			"attempts":        0,
+			"next_attempt_at": nil,
+		})
+	if result.Error != nil {
+		return herrors.NewErrUpdateFailed(herrors.WebhookLogInDB, result.Error.Error())
+	}
+	if result.RowsAffected == 0 {
+		return herrors.NewErrNotFound(herrors.WebhookLogInDB,
+			fmt.Sprintf("failed to find webhook log by id: %d", id))
+	}
+	return nil
+}
+
+// RedeliverWebhookLogs redelivers the failed and dead letter logs of the webhook created in the time range
+func (d *dao) RedeliverWebhookLogs(ctx context.Context, wID uint, start, end time.Time) (int64, error) {
+	result := d.db.WithContext(ctx).Model(&models.WebhookLog{}).
+		Where("webhook_id = ?", wID).
+		Where("status in ?", []string{models.StatusFailed, models.StatusDeadLetter}).
+		Where("created_at >= ? and created_at < ?", start, end).
+		Updates(map[string]interface{}{
+			"status":          models.StatusWaiting,
+			"attempts":        0,
+			"next_attempt_at": nil,
+		})
+	if result.Error != nil {
+		return 0, herrors.NewErrUpdateFailed(herrors.WebhookLogInDB, result.Error.Error())
+	}
+	return result.RowsAffected, nil
+}
+
+func (d *dao) CreateWebhookLogAttempt(ctx context.Context, attempt *models.WebhookLogAttempt) error {
+	if result := d.db.WithContext(ctx).Create(attempt); result.Error != nil {
+		return herrors.NewErrInsertFailed(herrors.WebhookLogInDB, result.Error.Error())
+	}
+	return nil
+}
+
+func (d *dao) ListWebhookLogAttempts(ctx context.Context,
+	logIDs ...uint) ([]*models.WebhookLogAttempt, error) {
+	attempts := make([]*models.WebhookLogAttempt, 0)
+	if len(logIDs) == 0 {
+		return attempts, nil
+	}
+	if result := d.db.WithContext(ctx).Where("webhook_log_id in ?", logIDs).
+		Order("id").Find(&attempts); result.Error != nil {
+		return nil, herrors.NewErrGetFailed(herrors.WebhookLogInDB, result.Error.Error())
+	}
+	return attempts, nil
+}
+
 func (d *dao) GetMaxEventIDOfLog(ctx context.Context) (uint, error) {
 	var maxID uint
 	if result := d.db.WithContext(ctx).Model(&models.WebhookLog{}).Select("ifnull(max(event_id),0)").
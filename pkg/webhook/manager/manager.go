@@ -16,6 +16,7 @@ package manager
 
 import (
 	"context"
+	"time"
 
 	"gorm.io/gorm"
 
@@ -32,6 +33,8 @@ type Manager interface {
 		resources map[string][]uint, query *q.Query) ([]*models.Webhook, int64, error)
 	ListWebhooks(ctx context.Context) ([]*models.Webhook, error)
 	UpdateWebhook(ctx context.Context, id uint, w *models.Webhook) (*models.Webhook, error)
+	// ClearExpiredPreviousSecrets clears the secrets before rotation which have stopped signing requests
+	ClearExpiredPreviousSecrets(ctx context.Context, now time.Time) (int64, error)
 	DeleteWebhook(ctx context.Context, id uint) error
 	CreateWebhookLog(ctx context.Context, wl *models.WebhookLog) (*models.WebhookLog, error)
 	CreateWebhookLogs(ctx context.Context, wls []*models.WebhookLog) ([]*models.WebhookLog, error)
@@ -41,12 +44,20 @@ type Manager interface {
 		webhookEventMap map[uint][]uint) ([]*models.WebhookLog, error)
 	ListWebhookLogsByStatus(ctx context.Context, wID uint,
 		status string) ([]*models.WebhookLog, error)
+	// ListWebhookLogsToSend lists the waiting logs and the retrying logs due at now
+	ListWebhookLogsToSend(ctx context.Context, wID uint, now time.Time) ([]*models.WebhookLog, error)
 	UpdateWebhookLog(ctx context.Context, wl *models.WebhookLog) (*models.WebhookLog, error)
 	GetWebhookLog(ctx context.Context, id uint) (*models.WebhookLog, error)
 	ResendWebhook(ctx context.Context, id uint) (*models.WebhookLog, error)
 	GetWebhookLogByEventID(ctx context.Context, webhookID, eventID uint) (*models.WebhookLog, error)
 	GetMaxEventIDOfLog(ctx context.Context) (uint, error)
 	DeleteWebhookLogs(ctx context.Context, id ...uint) (int64, error)
+	// RedeliverWebhookLog sends the log again with its attempts counted from zero
+	RedeliverWebhookLog(ctx context.Context, id uint) (*models.WebhookLog, error)
+	// RedeliverWebhookLogs sends the failed and dead letter logs of the webhook created in [start, end) again
+	RedeliverWebhookLogs(ctx context.Context, wID uint, start, end time.Time) (int64, error)
+	CreateWebhookLogAttempt(ctx context.Context, attempt *models.WebhookLogAttempt) error
+	ListWebhookLogAttempts(ctx context.Context, logID uint) ([]*models.WebhookLogAttempt, error)
 }
 
 type manager struct {
@@ -92,6 +103,12 @@ func (m *manager) UpdateWebhook(ctx context.Context, id uint,
 	return m.dao.UpdateWebhook(ctx, id, w)
 }
 
+func (m *manager) ClearExpiredPreviousSecrets(ctx context.Context, now time.Time) (int64, error) {
+	const op = "webhook manager: clear expired previous secrets"
+	defer wlog.Start(ctx, op).StopPrint()
+	return m.dao.ClearExpiredPreviousSecrets(ctx, now)
+}
+
 func (m *manager) DeleteWebhook(ctx context.Context, id uint) error {
 	const op = "webhook manager: delete webhook"
 	defer wlog.Start(ctx, op).StopPrint()
@@ -130,6 +147,11 @@ func (m *manager) ListWebhookLogsByStatus(ctx context.Context, wID uint,
 	return m.dao.ListWebhookLogsByStatus(ctx, wID, status)
 }
 
+func (m *manager) ListWebhookLogsToSend(ctx context.Context, wID uint,
+	now time.Time) ([]*models.WebhookLog, error) {
+	return m.dao.ListWebhookLogsToSend(ctx, wID, now)
+}
+
 func (m *manager) UpdateWebhookLog(ctx context.Context, wl *models.WebhookLog) (*models.WebhookLog, error) {
 	const op = "webhook manager: update  webhook log"
 	defer wlog.Start(ctx, op).StopPrint()
@@ -179,6 +201,34 @@ func (m *manager) ResendWebhook(ctx context.Context, id uint) (*models.WebhookLo
 	return m.dao.CreateWebhookLog(ctx, &wlCopy)
 }
 
+func (m *manager) RedeliverWebhookLog(ctx context.Context, id uint) (*models.WebhookLog, error) {
+	const op = "webhook manager: redeliver"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	if err := m.dao.RedeliverWebhookLog(ctx, id); err != nil {
+		return nil, err
+	}
+	return m.dao.GetWebhookLog(ctx, id)
+}
+
+func (m *manager) RedeliverWebhookLogs(ctx context.Context, wID uint, start, end time.Time) (int64, error) {
+	const op = "webhook manager: redeliver logs"
+	defer wlog.Start(ctx, op).StopPrint()
+	return m.dao.RedeliverWebhookLogs(ctx, wID, start, end)
+}
+
+func (m *manager) CreateWebhookLogAttempt(ctx context.Context, attempt *models.WebhookLogAttempt) error {
+	const op = "webhook manager: create webhook log attempt"
+	defer wlog.Start(ctx, op).StopPrint()
+	return m.dao.CreateWebhookLogAttempt(ctx, attempt)
+}
+
+func (m *manager) ListWebhookLogAttempts(ctx context.Context, logID uint) ([]*models.WebhookLogAttempt, error) {
+	const op = "webhook manager: list webhook log attempts"
+	defer wlog.Start(ctx, op).StopPrint()
+	return m.dao.ListWebhookLogAttempts(ctx, logID)
+}
+
 func (m *manager) GetMaxEventIDOfLog(ctx context.Context) (uint, error) {
 	return m.dao.GetMaxEventIDOfLog(ctx)
 }
@@ -14,14 +14,54 @@
 
 package models
 
-import "time"
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"math"
+	"net"
+	"net/http"
+	"net/url"
+	"syscall"
+	"time"
+)
 
 const (
 	StatusWaiting = "waiting"
-	StatusSuccess = "success"
-	StatusFailed  = "failed"
+	// StatusRetrying is set when a delivery failed and is sent again after the backoff
+	StatusRetrying = "retrying"
+	StatusSuccess  = "success"
+	StatusFailed   = "failed"
+	// StatusDeadLetter is set when all the attempts of a delivery failed,
+	// the log is only sent again when it's redelivered
+	StatusDeadLetter = "deadletter"
+)
+
+const (
+	// ContentModeJSON sends the message as the json body, it's the default mode
+	ContentModeJSON = "json"
+	// ContentModeCloudEventsStructured sends the message as the data of a cloud event in the json body
+	ContentModeCloudEventsStructured = "cloudevents-structured"
+	// ContentModeCloudEventsBinary sends the message as the json body with the cloud event attributes in headers
+	ContentModeCloudEventsBinary = "cloudevents-binary"
 )
 
+const (
+	// ChannelWebhook posts the message to the url, webhooks have no channel saved
+	ChannelWebhook = ""
+	// ChannelSlack posts the rendered message to an incoming webhook of slack
+	ChannelSlack = "slack"
+	// ChannelDingTalk posts the rendered message to a dingtalk bot, the secret signs the url if it's set
+	ChannelDingTalk = "dingtalk"
+	// ChannelFeishu posts the rendered message to a feishu bot, the secret signs the body if it's set
+	ChannelFeishu = "feishu"
+	// ChannelEmail mails the rendered message to the recipients by the smtp server in config
+	ChannelEmail = "email"
+)
+
+// NotificationChannels are the channels which render messages, i.e. all channels except webhook
+var NotificationChannels = []string{ChannelSlack, ChannelDingTalk, ChannelFeishu, ChannelEmail}
+
 type Webhook struct {
 	ID               uint
 	Enabled          bool
@@ -29,13 +69,152 @@ type Webhook struct {
 	SSLVerifyEnabled bool
 	Description      string
 	Secret           string
-	Triggers         string
-	ResourceType     string
-	ResourceID       uint
-	CreatedAt        time.Time
-	CreatedBy        uint
-	UpdatedAt        time.Time
-	UpdatedBy        uint
+	// PreviousSecret keeps signing the requests with Secret until PreviousSecretExpiredAt after rotation
+	PreviousSecret          string
+	PreviousSecretExpiredAt *time.Time
+	Triggers                string
+	ResourceType            string
+	ResourceID              uint
+	// RetryPolicy is the json of the retry policy, the default policy is used if it's empty
+	RetryPolicy string
+	// ContentMode is the format of the requests, json if it's empty
+	ContentMode string
+	// Channel is where the message is delivered, it's a plain webhook if it's empty
+	Channel string
+	// ChannelConfig is the json of the config of the notification channel
+	ChannelConfig string
+	CreatedAt     time.Time
+	CreatedBy     uint
+	UpdatedAt     time.Time
+	UpdatedBy     uint
+}
+
+// Secrets are the secrets to sign requests at now, the previous secret is included before it expires
+func (w *Webhook) Secrets(now time.Time) []string {
+	secrets := []string{w.Secret}
+	if w.PreviousSecret != "" && w.PreviousSecretExpiredAt != nil && now.Before(*w.PreviousSecretExpiredAt) {
+		secrets = append(secrets, w.PreviousSecret)
+	}
+	return secrets
+}
+
+// GetRetryPolicy gets the retry policy of the webhook, nil if it's not set
+func (w *Webhook) GetRetryPolicy() *RetryPolicy {
+	if w.RetryPolicy == "" {
+		return nil
+	}
+	var policy RetryPolicy
+	if err := json.Unmarshal([]byte(w.RetryPolicy), &policy); err != nil {
+		return nil
+	}
+	return &policy
+}
+
+func (w *Webhook) SetRetryPolicy(policy *RetryPolicy) {
+	if policy == nil {
+		w.RetryPolicy = ""
+		return
+	}
+	b, _ := json.Marshal(policy)
+	w.RetryPolicy = string(b)
+}
+
+// GetChannelConfig gets the config of the notification channel, it's empty if it's not set
+func (w *Webhook) GetChannelConfig() *ChannelConfig {
+	var config ChannelConfig
+	if w.ChannelConfig != "" {
+		_ = json.Unmarshal([]byte(w.ChannelConfig), &config)
+	}
+	return &config
+}
+
+func (w *Webhook) SetChannelConfig(config *ChannelConfig) {
+	if config == nil {
+		w.ChannelConfig = ""
+		return
+	}
+	b, _ := json.Marshal(config)
+	w.ChannelConfig = string(b)
+}
+
+// ChannelConfig configures how a notification channel renders and delivers messages
+type ChannelConfig struct {
+	// Recipients are the addresses to mail for the email channel
+	Recipients []string `json:"recipients,omitempty"`
+	// Templates override the default templates by event type, "*" overrides the template of all the events
+	Templates map[string]*NotificationTemplate `json:"templates,omitempty"`
+}
+
+// NotificationTemplate is the text/template of a message, it's executed on the json body of webhooks,
+// e.g. "{{.cluster.name}} is freed by {{.user.name}}"
+type NotificationTemplate struct {
+	Title string `json:"title"`
+	Text  string `json:"text"`
+}
+
+// RetryPolicy tells whether and when a failed delivery is sent again
+type RetryPolicy struct {
+	// MaxAttempts is the max attempts of a delivery including the first one, it's not retried if it's 1
+	MaxAttempts uint `json:"maxAttempts" yaml:"maxAttempts"`
+	// InitialInterval is the seconds to wait before the first retry
+	InitialInterval uint `json:"initialInterval" yaml:"initialInterval"`
+	// MaxInterval is the max seconds to wait before a retry
+	MaxInterval uint `json:"maxInterval" yaml:"maxInterval"`
+	// Multiplier multiplies the interval after each retry
+	Multiplier float64 `json:"multiplier" yaml:"multiplier"`
+	// RetryOnStatusCodes are the response codes to retry on, 429 and 5xx if it's empty.
+	// Deliveries failed without responses are retried only if they failed in transport
+	RetryOnStatusCodes []int `json:"retryOnStatusCodes,omitempty" yaml:"retryOnStatusCodes"`
+}
+
+// Backoff is the time to wait before the next attempt after the given attempts failed
+func (p *RetryPolicy) Backoff(attempts uint) time.Duration {
+	if attempts == 0 {
+		return 0
+	}
+	multiplier := p.Multiplier
+	if multiplier < 1 {
+		multiplier = 1
+	}
+	interval := float64(p.InitialInterval) * math.Pow(multiplier, float64(attempts-1))
+	if p.MaxInterval > 0 && interval > float64(p.MaxInterval) {
+		interval = float64(p.MaxInterval)
+	}
+	return time.Duration(interval * float64(time.Second))
+}
+
+// ShouldRetry tells whether a delivery failed with the response code is retried,
+// the code is 0 if there is no response, and err is why the delivery failed then
+func (p *RetryPolicy) ShouldRetry(statusCode int, err error) bool {
+	if statusCode == 0 {
+		return isTransportError(err)
+	}
+	if len(p.RetryOnStatusCodes) == 0 {
+		return statusCode == http.StatusTooManyRequests || statusCode >= http.StatusInternalServerError
+	}
+	for _, code := range p.RetryOnStatusCodes {
+		if code == statusCode {
+			return true
+		}
+	}
+	return false
+}
+
+// isTransportError tells whether the request failed in transport, such as dial errors, timeouts
+// and connection resets, which may succeed later. The errors of making the request never do
+func isTransportError(err error) bool {
+	if err == nil {
+		return false
+	}
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		err = urlErr.Err
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return true
+	}
+	return errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, syscall.ECONNRESET)
 }
 
 type WebhookLog struct {
@@ -49,9 +228,25 @@ type WebhookLog struct {
 	ResponseBody    string
 	Status          string
 	ErrorMessage    string
-	CreatedAt       time.Time
-	CreatedBy       uint
-	UpdatedAt       time.Time
+	// Attempts is the number of the attempts since the log is created or redelivered
+	Attempts uint
+	// NextAttemptAt is when the retrying log is sent again
+	NextAttemptAt *time.Time
+	CreatedAt     time.Time
+	CreatedBy     uint
+	UpdatedAt     time.Time
+}
+
+// WebhookLogAttempt records an attempt to send a webhook log
+type WebhookLogAttempt struct {
+	ID           uint
+	WebhookLogID uint `gorm:"index"`
+	// Attempt is the sequence of the attempt since the log is created or redelivered
+	Attempt uint
+	// StatusCode is the code of the response, 0 if there is no response
+	StatusCode   int
+	ErrorMessage string
+	CreatedAt    time.Time
 }
 
 type WebhookLogWithEventInfo struct {
@@ -61,4 +256,6 @@ type WebhookLogWithEventInfo struct {
 	ResourceName string
 	ResourceID   uint
 	Extra        *string
+	// History is the attempts to send the log
+	History []*WebhookLogAttempt `gorm:"-"`
 }
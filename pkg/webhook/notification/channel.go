@@ -0,0 +1,122 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package notification
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/horizoncd/horizon/pkg/webhook/models"
+)
+
+const _contentTypeJSON = "application/json;charset=utf-8"
+
+// NewRequest makes the request to post the message to the bot of the channel,
+// the request is signed by the secret if it's set, see the docs of the bots:
+// https://api.slack.com/messaging/webhooks,
+// https://open.dingtalk.com/document/robots/custom-robot-access,
+// https://open.feishu.cn/document/client-docs/bot-v3/add-custom-bot
+func NewRequest(channel, botURL, secret string, msg *Message, now time.Time) (*http.Request, error) {
+	var body map[string]interface{}
+	switch channel {
+	case models.ChannelSlack:
+		body = map[string]interface{}{
+			"text": fmt.Sprintf("*%s*\n%s", msg.Title, msg.Text),
+		}
+	case models.ChannelDingTalk:
+		body = map[string]interface{}{
+			"msgtype": "markdown",
+			"markdown": map[string]string{
+				"title": msg.Title,
+				"text":  fmt.Sprintf("### %s\n\n%s", msg.Title, msg.Text),
+			},
+		}
+		if secret != "" {
+			u, err := url.Parse(botURL)
+			if err != nil {
+				return nil, err
+			}
+			timestamp := now.UnixNano() / int64(time.Millisecond)
+			query := u.Query()
+			query.Set("timestamp", strconv.FormatInt(timestamp, 10))
+			query.Set("sign", sign(secret, fmt.Sprintf("%d\n%s", timestamp, secret)))
+			u.RawQuery = query.Encode()
+			botURL = u.String()
+		}
+	case models.ChannelFeishu:
+		body = map[string]interface{}{
+			"msg_type": "text",
+			"content": map[string]string{
+				"text": fmt.Sprintf("%s\n%s", msg.Title, msg.Text),
+			},
+		}
+		if secret != "" {
+			timestamp := now.Unix()
+			// feishu signs nothing with the timestamp and the secret as the key
+			body["timestamp"] = strconv.FormatInt(timestamp, 10)
+			body["sign"] = sign(fmt.Sprintf("%d\n%s", timestamp, secret), "")
+		}
+	default:
+		return nil, fmt.Errorf("channel %s is not supported to post messages", channel)
+	}
+
+	reqBody, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequest(http.MethodPost, botURL, bytes.NewBuffer(reqBody))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", _contentTypeJSON)
+	return req, nil
+}
+
+// CheckResponse checks the response body of the bot, dingtalk and feishu respond errors with 200
+func CheckResponse(channel string, body []byte) error {
+	var resp struct {
+		ErrCode int    `json:"errcode"`
+		ErrMsg  string `json:"errmsg"`
+		Code    int    `json:"code"`
+		Msg     string `json:"msg"`
+	}
+	switch channel {
+	case models.ChannelDingTalk, models.ChannelFeishu:
+		if err := json.Unmarshal(body, &resp); err != nil {
+			return fmt.Errorf("failed to unmarshal response of %s: %v", channel, err)
+		}
+	}
+	if resp.ErrCode != 0 {
+		return fmt.Errorf("%s responded error %d: %s", channel, resp.ErrCode, resp.ErrMsg)
+	}
+	if resp.Code != 0 {
+		return fmt.Errorf("%s responded error %d: %s", channel, resp.Code, resp.Msg)
+	}
+	return nil
+}
+
+func sign(key, content string) string {
+	h := hmac.New(sha256.New, []byte(key))
+	h.Write([]byte(content))
+	return base64.StdEncoding.EncodeToString(h.Sum(nil))
+}
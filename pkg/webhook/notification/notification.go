@@ -0,0 +1,146 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package notification renders the messages of events and delivers them to chat-ops channels,
+// such as slack, dingtalk, feishu and email.
+//
+// Templates are text/template executed on the json body of webhooks, the extra of the event
+// is decoded if it's json, e.g. "{{.cluster.name}} failed in pipelinerun {{.extra.pipelinerunID}}".
+package notification
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"text/template"
+
+	eventmodels "github.com/horizoncd/horizon/pkg/event/models"
+	"github.com/horizoncd/horizon/pkg/webhook/models"
+)
+
+// Message is the rendered notification of an event
+type Message struct {
+	Title string
+	Text  string
+}
+
+const (
+	_clusterText = "Cluster {{.cluster.name}} of application {{.cluster.applicationName}} in {{.cluster.env}}"
+	_byUser      = "{{with .user}} by {{.name}}{{end}}"
+)
+
+var defaultTemplates = map[string]*models.NotificationTemplate{
+	eventmodels.Any: {
+		Title: "[Horizon] {{.eventType}}",
+		Text: "{{with .cluster}}Cluster {{.name}} of application {{.applicationName}}" +
+			"{{else}}{{with .application}}Application {{.name}}" +
+			"{{else}}{{with .resource}}{{.type}} {{if .name}}{{.name}}{{else}}{{.id}}{{end}}{{end}}{{end}}{{end}} " +
+			"triggered {{.eventType}}" + _byUser,
+	},
+	eventmodels.ClusterPipelinerunFailed: {
+		Title: "[Horizon] Pipeline of {{.cluster.name}} failed",
+		Text: _clusterText + " failed in pipelinerun {{.extra.pipelinerunID}}" +
+			"{{with .user}}, triggered by {{.name}}{{end}}",
+	},
+	eventmodels.ClusterDeployed: {
+		Title: "[Horizon] {{.cluster.name}} is deployed",
+		Text:  _clusterText + " is deployed" + _byUser,
+	},
+	eventmodels.ClusterBuildDeployed: {
+		Title: "[Horizon] {{.cluster.name}} is built and deployed",
+		Text:  _clusterText + " is built and deployed" + _byUser,
+	},
+	eventmodels.ClusterRollbacked: {
+		Title: "[Horizon] {{.cluster.name}} is rolled back",
+		Text:  _clusterText + " is rolled back" + _byUser,
+	},
+	eventmodels.ClusterFreed: {
+		Title: "[Horizon] {{.cluster.name}} is freed",
+		Text:  _clusterText + " is freed" + _byUser,
+	},
+	eventmodels.ClusterPending: {
+		Title: "[Horizon] {{.cluster.name}} is waiting for approval",
+		Text:  "An operation of " + _clusterText + " is waiting for approval",
+	},
+	eventmodels.ClusterDrifted: {
+		Title: "[Horizon] {{.cluster.name}} has drifted",
+		Text:  "Live resources of " + _clusterText + " have drifted from git",
+	},
+}
+
+// ValidateTemplates checks whether the templates can be parsed
+func ValidateTemplates(templates map[string]*models.NotificationTemplate) error {
+	for eventType, tpl := range templates {
+		if tpl == nil {
+			return fmt.Errorf("template of %s is empty", eventType)
+		}
+		for _, text := range []string{tpl.Title, tpl.Text} {
+			if _, err := template.New(eventType).Parse(text); err != nil {
+				return fmt.Errorf("invalid template of %s: %v", eventType, err)
+			}
+		}
+	}
+	return nil
+}
+
+// Render renders the message of the json body of webhooks, the templates override the default templates
+func Render(templates map[string]*models.NotificationTemplate, content []byte) (*Message, error) {
+	data := map[string]interface{}{}
+	if err := json.Unmarshal(content, &data); err != nil {
+		return nil, err
+	}
+	if extra, ok := data["extra"].(string); ok {
+		var v interface{}
+		if err := json.Unmarshal([]byte(extra), &v); err == nil {
+			data["extra"] = v
+		}
+	}
+	eventType, _ := data["eventType"].(string)
+
+	tpl := lookupTemplate(templates, eventType)
+	title, err := execute(tpl.Title, data)
+	if err != nil {
+		return nil, err
+	}
+	text, err := execute(tpl.Text, data)
+	if err != nil {
+		return nil, err
+	}
+	return &Message{Title: title, Text: text}, nil
+}
+
+func lookupTemplate(templates map[string]*models.NotificationTemplate,
+	eventType string) *models.NotificationTemplate {
+	for _, candidates := range []map[string]*models.NotificationTemplate{templates, defaultTemplates} {
+		if tpl, ok := candidates[eventType]; ok && tpl != nil {
+			return tpl
+		}
+		if tpl, ok := candidates[eventmodels.Any]; ok && tpl != nil {
+			return tpl
+		}
+	}
+	return defaultTemplates[eventmodels.Any]
+}
+
+func execute(text string, data map[string]interface{}) (string, error) {
+	tpl, err := template.New("").Parse(text)
+	if err != nil {
+		return "", err
+	}
+	buf := &bytes.Buffer{}
+	if err := tpl.Execute(buf, data); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
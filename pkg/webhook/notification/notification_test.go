@@ -0,0 +1,119 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package notification
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	eventmodels "github.com/horizoncd/horizon/pkg/event/models"
+	"github.com/horizoncd/horizon/pkg/webhook/models"
+)
+
+const _content = `{"id":1,"eventID":2,"cluster":{"id":3,"name":"c","applicationName":"app","env":"test"},` +
+	`"eventType":"clusters_pipelinerun_failed","user":{"id":1,"name":"tom"},"extra":"{\"pipelinerunID\":4}"}`
+
+func TestRender(t *testing.T) {
+	msg, err := Render(nil, []byte(_content))
+	assert.Nil(t, err)
+	assert.Equal(t, "[Horizon] Pipeline of c failed", msg.Title)
+	assert.Equal(t, "Cluster c of application app in test failed in pipelinerun 4, triggered by tom", msg.Text)
+
+	// the default template of all the events
+	msg, err = Render(nil, []byte(`{"application":{"id":1,"name":"app"},"eventType":"applications_created"}`))
+	assert.Nil(t, err)
+	assert.Equal(t, "[Horizon] applications_created", msg.Title)
+	assert.Equal(t, "Application app triggered applications_created", msg.Text)
+	msg, err = Render(nil, []byte(`{"resource":{"id":5,"name":"hz","type":"regions"},`+
+		`"eventType":"regions_updated","user":{"name":"tom"}}`))
+	assert.Nil(t, err)
+	assert.Equal(t, "regions hz triggered regions_updated by tom", msg.Text)
+
+	// overridden templates
+	templates := map[string]*models.NotificationTemplate{
+		eventmodels.Any: {Title: "{{.eventType}}", Text: "{{.cluster.name}}"},
+	}
+	msg, err = Render(templates, []byte(_content))
+	assert.Nil(t, err)
+	assert.Equal(t, "clusters_pipelinerun_failed", msg.Title)
+	assert.Equal(t, "c", msg.Text)
+	templates[eventmodels.ClusterPipelinerunFailed] = &models.NotificationTemplate{Title: "failed", Text: "{{.extra}}"}
+	msg, err = Render(templates, []byte(_content))
+	assert.Nil(t, err)
+	assert.Equal(t, "failed", msg.Title)
+	assert.Equal(t, "map[pipelinerunID:4]", msg.Text)
+
+	assert.Nil(t, ValidateTemplates(templates))
+	templates[eventmodels.ClusterFreed] = &models.NotificationTemplate{Text: "{{.cluster"}
+	assert.NotNil(t, ValidateTemplates(templates))
+}
+
+func TestNewRequest(t *testing.T) {
+	msg := &Message{Title: "title", Text: "text"}
+	now := time.Unix(1690000000, 0)
+	body := func(req *http.Request) map[string]interface{} {
+		b, err := ioutil.ReadAll(req.Body)
+		assert.Nil(t, err)
+		m := map[string]interface{}{}
+		assert.Nil(t, json.Unmarshal(b, &m))
+		return m
+	}
+
+	req, err := NewRequest(models.ChannelSlack, "https://hooks.slack.com/services/x", "", msg, now)
+	assert.Nil(t, err)
+	assert.Equal(t, "*title*\ntext", body(req)["text"])
+
+	req, err = NewRequest(models.ChannelDingTalk, "https://oapi.dingtalk.com/robot/send?access_token=x",
+		"secret", msg, now)
+	assert.Nil(t, err)
+	assert.Equal(t, "markdown", body(req)["msgtype"])
+	assert.Equal(t, "x", req.URL.Query().Get("access_token"))
+	assert.Equal(t, "1690000000000", req.URL.Query().Get("timestamp"))
+	assert.Equal(t, sign("secret", "1690000000000\nsecret"), req.URL.Query().Get("sign"))
+
+	req, err = NewRequest(models.ChannelFeishu, "https://open.feishu.cn/open-apis/bot/v2/hook/x",
+		"secret", msg, now)
+	assert.Nil(t, err)
+	m := body(req)
+	assert.Equal(t, "text", m["msg_type"])
+	assert.Equal(t, "1690000000", m["timestamp"])
+	assert.Equal(t, sign("1690000000\nsecret", ""), m["sign"])
+
+	_, err = NewRequest(models.ChannelEmail, "", "", msg, now)
+	assert.NotNil(t, err)
+}
+
+func TestCheckResponse(t *testing.T) {
+	assert.Nil(t, CheckResponse(models.ChannelSlack, []byte("ok")))
+	assert.Nil(t, CheckResponse(models.ChannelDingTalk, []byte(`{"errcode":0,"errmsg":"ok"}`)))
+	assert.NotNil(t, CheckResponse(models.ChannelDingTalk, []byte(`{"errcode":310000,"errmsg":"sign not match"}`)))
+	assert.Nil(t, CheckResponse(models.ChannelFeishu, []byte(`{"code":0,"msg":"success"}`)))
+	assert.NotNil(t, CheckResponse(models.ChannelFeishu, []byte(`{"code":19021,"msg":"sign match fail"}`)))
+	assert.NotNil(t, CheckResponse(models.ChannelFeishu, []byte(`not json`)))
+}
+
+func TestBuildMail(t *testing.T) {
+	mail := string(buildMail("horizon@example.com", []string{"a@example.com", "b@example.com"},
+		&Message{Title: "部署失败", Text: "line1\nline2"}))
+	assert.True(t, strings.HasPrefix(mail, "From: horizon@example.com\r\nTo: a@example.com, b@example.com\r\n"))
+	assert.Contains(t, mail, "Subject: =?utf-8?q?")
+	assert.True(t, strings.HasSuffix(mail, "\r\n\r\nline1\r\nline2\r\n"))
+}
@@ -0,0 +1,93 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/horizoncd/horizon/pkg/eventhandler/wlgenerator"
+	"github.com/horizoncd/horizon/pkg/webhook/models"
+)
+
+const (
+	_cloudEventsSpecVersion = "1.0"
+	_cloudEventsTypePrefix  = "cd.horizon."
+	_cloudEventsContentType = "application/cloudevents+json;charset=utf-8"
+	_cloudEventsDataType    = "application/json"
+
+	_headerContentType = "Content-Type"
+	_headerPrefixCE    = "ce-"
+)
+
+// cloudEvent is a cloud event in structured content mode,
+// see https://github.com/cloudevents/spec/blob/v1.0.2/cloudevents/formats/json-format.md
+type cloudEvent struct {
+	SpecVersion     string                      `json:"specversion"`
+	ID              string                      `json:"id"`
+	Source          string                      `json:"source"`
+	Type            string                      `json:"type"`
+	Subject         string                      `json:"subject,omitempty"`
+	Time            string                      `json:"time"`
+	DataContentType string                      `json:"datacontenttype"`
+	Data            *wlgenerator.MessageContent `json:"data"`
+}
+
+func newCloudEvent(content *wlgenerator.MessageContent, createdAt time.Time) *cloudEvent {
+	event := &cloudEvent{
+		SpecVersion:     _cloudEventsSpecVersion,
+		ID:              fmt.Sprintf("%d", content.EventID),
+		Source:          "/",
+		Type:            _cloudEventsTypePrefix + content.EventType,
+		Time:            createdAt.UTC().Format(time.RFC3339),
+		DataContentType: _cloudEventsDataType,
+		Data:            content,
+	}
+	switch {
+	case content.Cluster != nil:
+		event.Source = fmt.Sprintf("/clusters/%d", content.Cluster.ID)
+		event.Subject = content.Cluster.Name
+	case content.Application != nil:
+		event.Source = fmt.Sprintf("/applications/%d", content.Application.ID)
+		event.Subject = content.Application.Name
+	}
+	return event
+}
+
+// formatContent marshals the content of the webhook log by the content mode,
+// headers of the cloud event are set in binary mode
+func formatContent(mode string, content *wlgenerator.MessageContent,
+	createdAt time.Time, header http.Header) ([]byte, error) {
+	switch mode {
+	case models.ContentModeCloudEventsStructured:
+		header.Set(_headerContentType, _cloudEventsContentType)
+		return json.Marshal(newCloudEvent(content, createdAt))
+	case models.ContentModeCloudEventsBinary:
+		event := newCloudEvent(content, createdAt)
+		header.Set(_headerPrefixCE+"specversion", event.SpecVersion)
+		header.Set(_headerPrefixCE+"id", event.ID)
+		header.Set(_headerPrefixCE+"source", event.Source)
+		header.Set(_headerPrefixCE+"type", event.Type)
+		header.Set(_headerPrefixCE+"time", event.Time)
+		if event.Subject != "" {
+			header.Set(_headerPrefixCE+"subject", event.Subject)
+		}
+		return json.Marshal(content)
+	default:
+		return json.Marshal(content)
+	}
+}
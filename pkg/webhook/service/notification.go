@@ -0,0 +1,70 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/horizoncd/horizon/pkg/eventhandler/wlgenerator"
+	"github.com/horizoncd/horizon/pkg/util/log"
+	"github.com/horizoncd/horizon/pkg/webhook/models"
+	"github.com/horizoncd/horizon/pkg/webhook/notification"
+)
+
+// sendNotification renders the content by the templates of the channel and delivers it
+func (w *worker) sendNotification(ctx context.Context, webhook *models.Webhook,
+	wl *models.WebhookLog, content *wlgenerator.MessageContent) (*models.WebhookLog, int, error) {
+	config := webhook.GetChannelConfig()
+	body, err := json.Marshal(content)
+	if err != nil {
+		wl.ErrorMessage = fmt.Sprintf("failed to marshal content, error: %+v", err)
+		log.Errorf(ctx, wl.ErrorMessage)
+		return wl, 0, err
+	}
+	msg, err := notification.Render(config.Templates, body)
+	if err != nil {
+		wl.ErrorMessage = fmt.Sprintf("failed to render message, error: %+v", err)
+		log.Errorf(ctx, wl.ErrorMessage)
+		return wl, 0, err
+	}
+
+	if webhook.Channel == models.ChannelEmail {
+		if err := notification.SendMail(w.smtp, config.Recipients, msg,
+			w.secureClient.Timeout); err != nil {
+			wl.ErrorMessage = fmt.Sprintf("failed to send mail, error: %+v", err)
+			log.Errorf(ctx, wl.ErrorMessage)
+			return wl, 0, err
+		}
+		return wl, http.StatusOK, nil
+	}
+
+	req, err := notification.NewRequest(webhook.Channel, wl.URL, webhook.Secret, msg, time.Now())
+	if err != nil {
+		wl.ErrorMessage = fmt.Sprintf("failed to new request, error: %+v", err)
+		log.Errorf(ctx, wl.ErrorMessage)
+		return wl, 0, err
+	}
+	wl, statusCode, err := w.do(ctx, webhook, wl, req)
+	if wl.ErrorMessage == "" {
+		if err := notification.CheckResponse(webhook.Channel, []byte(wl.ResponseBody)); err != nil {
+			wl.ErrorMessage = err.Error()
+		}
+	}
+	return wl, statusCode, err
+}
@@ -39,11 +39,16 @@ import (
 	webhookmanager "github.com/horizoncd/horizon/pkg/webhook/manager"
 	"github.com/horizoncd/horizon/pkg/webhook/models"
 	webhookmodels "github.com/horizoncd/horizon/pkg/webhook/models"
+	"github.com/horizoncd/horizon/pkg/webhook/signature"
 )
 
 type worker struct {
 	idleWaitInterval         uint
 	responseBodyTruncateSize uint
+	// retryPolicy is used if the webhook has no retry policy
+	retryPolicy        models.RetryPolicy
+	legacySecretHeader bool
+	smtp               webhookconfig.SMTPConfig
 
 	ctx            context.Context
 	insecureClient http.Client
@@ -132,7 +137,8 @@ func (s *service) reconcileWorkers() {
 			// 2.2 create workers
 			s.workers[id] = newWebhookWorker(s.webhookManager, s.eventManager,
 				s.userManager, webhook, s.config.IdleWaitInterval,
-				s.config.ClientTimeout, s.config.ResponseBodyTruncateSize)
+				s.config.ClientTimeout, s.config.ResponseBodyTruncateSize, s.config.RetryPolicy,
+				s.config.LegacySecretHeader, s.config.SMTP)
 		}
 		reconciled[id] = true
 	}
@@ -183,10 +189,14 @@ func (s *service) StopAndWait() {
 func newWebhookWorker(webhookMgr webhookmanager.Manager,
 	eventMgr eventmanager.Manager, userMgr usermanager.Manager,
 	webhook *models.Webhook, idleWaitInterval uint,
-	clientTimeout, responseBodyTruncateSize uint) *worker {
+	clientTimeout, responseBodyTruncateSize uint, retryPolicy models.RetryPolicy,
+	legacySecretHeader bool, smtp webhookconfig.SMTPConfig) *worker {
 	ww := &worker{
 		idleWaitInterval:         idleWaitInterval,
 		responseBodyTruncateSize: responseBodyTruncateSize,
+		retryPolicy:              retryPolicy,
+		legacySecretHeader:       legacySecretHeader,
+		smtp:                     smtp,
 		ctx:                      context.Background(),
 		quit:                     make(chan bool, 1),
 		insecureClient: http.Client{
@@ -209,40 +219,67 @@ func newWebhookWorker(webhookMgr webhookmanager.Manager,
 	return ww
 }
 
-func (w *worker) sendWebhook(ctx context.Context, wl *models.WebhookLog) *models.WebhookLog {
-	// 1. make request and set body
-	reqBody, err := addWebhookLogID([]byte(wl.RequestData), wl.ID)
+// sendWebhook sends the webhook log, the code of the response is returned,
+// it's 0 if there is no response, and the error tells why the log is not delivered
+func (w *worker) sendWebhook(ctx context.Context, wl *models.WebhookLog) (*models.WebhookLog, int, error) {
+	// the error of the last attempt is cleared
+	wl.ErrorMessage = ""
+
+	webhook, err := w.getWebhook()
 	if err != nil {
-		wl.ErrorMessage = fmt.Sprintf("failed to add id, error: %+v", err)
-		log.Errorf(ctx, wl.ErrorMessage)
-		return wl
+		log.Error(ctx, err)
+		wl.ErrorMessage = err.Error()
+		return wl, 0, err
 	}
-	req, err := http.NewRequest(http.MethodPost, wl.URL,
-		bytes.NewBuffer(reqBody))
+
+	content, err := addWebhookLogID([]byte(wl.RequestData), wl.ID)
 	if err != nil {
-		wl.ErrorMessage = fmt.Sprintf("failed to new request, error: %+v", err)
+		wl.ErrorMessage = fmt.Sprintf("failed to add id, error: %+v", err)
 		log.Errorf(ctx, wl.ErrorMessage)
-		return wl
+		return wl, 0, err
+	}
+	if webhook.Channel != models.ChannelWebhook {
+		return w.sendNotification(ctx, webhook, wl, content)
 	}
 
-	// 2. set headers
+	// 1. set headers
 	headers := http.Header{}
 	if err := yaml.Unmarshal([]byte(wl.RequestHeaders), &headers); err != nil {
 		wl.ErrorMessage = fmt.Sprintf("failed to unmarshal header, error: %+v", err)
 		log.Errorf(ctx, wl.ErrorMessage)
-		return wl
+		return wl, 0, err
+	}
+	if w.legacySecretHeader {
+		headers.Set(wlgenerator.WebhookSecretHeader, webhook.Secret)
 	}
-	req.Header = headers
 
-	// 3. send request
-	cli := w.secureClient
-	webhook, err := w.getWebhook()
+	// 2. make body by the content mode and sign it
+	reqBody, err := formatContent(webhook.ContentMode, content, wl.CreatedAt, headers)
 	if err != nil {
-		log.Error(ctx, err)
-		wl.ErrorMessage = err.Error()
-		return wl
+		wl.ErrorMessage = fmt.Sprintf("failed to format content, error: %+v", err)
+		log.Errorf(ctx, wl.ErrorMessage)
+		return wl, 0, err
 	}
+	now := time.Now()
+	signature.Sign(headers, reqBody, now, webhook.Secrets(now)...)
 
+	req, err := http.NewRequest(http.MethodPost, wl.URL,
+		bytes.NewBuffer(reqBody))
+	if err != nil {
+		wl.ErrorMessage = fmt.Sprintf("failed to new request, error: %+v", err)
+		log.Errorf(ctx, wl.ErrorMessage)
+		return wl, 0, err
+	}
+	req.Header = headers
+
+	return w.do(ctx, webhook, wl, req)
+}
+
+// do sends the request and records the response in the webhook log
+func (w *worker) do(ctx context.Context, webhook *models.Webhook,
+	wl *models.WebhookLog, req *http.Request) (*models.WebhookLog, int, error) {
+	// 3. send request
+	cli := w.secureClient
 	if !webhook.SSLVerifyEnabled {
 		cli = w.insecureClient
 	}
@@ -250,7 +287,7 @@ func (w *worker) sendWebhook(ctx context.Context, wl *models.WebhookLog) *models
 	if err != nil {
 		wl.ErrorMessage = fmt.Sprintf("failed to send req, error: %+v", err)
 		log.Errorf(ctx, wl.ErrorMessage)
-		return wl
+		return wl, 0, err
 	}
 
 	// 4. update response body
@@ -261,7 +298,7 @@ func (w *worker) sendWebhook(ctx context.Context, wl *models.WebhookLog) *models
 		wl.ErrorMessage = fmt.Sprintf("failed to read response body, error: %+v", err)
 		log.Errorf(ctx, wl.ErrorMessage)
 		resp.Body.Close()
-		return wl
+		return wl, resp.StatusCode, nil
 	}
 	wl.ResponseBody = string(respBody)
 
@@ -271,14 +308,51 @@ func (w *worker) sendWebhook(ctx context.Context, wl *models.WebhookLog) *models
 		wl.ErrorMessage = fmt.Sprintf("failed to marshal, error: %+v", err)
 		log.Errorf(ctx, wl.ErrorMessage)
 		resp.Body.Close()
-		return wl
+		return wl, resp.StatusCode, nil
 	}
 	if resp.StatusCode >= http.StatusBadRequest || resp.StatusCode < http.StatusOK {
 		wl.ErrorMessage = fmt.Sprintf("unexpected response code: %d", resp.StatusCode)
 	}
 	wl.ResponseHeaders = string(respHeader)
 	resp.Body.Close()
-	return wl
+	return wl, resp.StatusCode, nil
+}
+
+// saveResult records the attempt, and retries the failed log by the retry policy of the webhook
+func (w *worker) saveResult(ctx context.Context, webhook *models.Webhook,
+	wl *models.WebhookLog, statusCode int, sendErr error) {
+	wl.Attempts++
+	if err := w.webhookManager.CreateWebhookLogAttempt(ctx, &models.WebhookLogAttempt{
+		WebhookLogID: wl.ID,
+		Attempt:      wl.Attempts,
+		StatusCode:   statusCode,
+		ErrorMessage: wl.ErrorMessage,
+	}); err != nil {
+		log.Errorf(ctx, "failed to create attempt of webhook log %d, error: %s", wl.ID, err.Error())
+	}
+
+	policy := webhook.GetRetryPolicy()
+	if policy == nil {
+		policy = &w.retryPolicy
+	}
+	wl.NextAttemptAt = nil
+	switch {
+	case wl.ErrorMessage == "":
+		wl.Status = webhookmodels.StatusSuccess
+	case !policy.ShouldRetry(statusCode, sendErr):
+		wl.Status = webhookmodels.StatusFailed
+	case wl.Attempts < policy.MaxAttempts:
+		wl.Status = webhookmodels.StatusRetrying
+		nextAttemptAt := time.Now().Add(policy.Backoff(wl.Attempts))
+		wl.NextAttemptAt = &nextAttemptAt
+	case policy.MaxAttempts > 1:
+		wl.Status = webhookmodels.StatusDeadLetter
+	default:
+		wl.Status = webhookmodels.StatusFailed
+	}
+	if _, err := w.webhookManager.UpdateWebhookLog(ctx, wl); err != nil {
+		log.Errorf(ctx, "failed to update webhook log %d, error: %s", wl.ID, err.Error())
+	}
 }
 
 // start webhook worker and begin to send process webhook logs
@@ -303,8 +377,7 @@ L:
 				log.Error(ctx, err)
 				continue
 			}
-			wls, err := w.webhookManager.ListWebhookLogsByStatus(ctx, webhook.ID,
-				webhookmodels.StatusWaiting)
+			wls, err := w.webhookManager.ListWebhookLogsToSend(ctx, webhook.ID, time.Now())
 			if err != nil {
 				log.Errorf(ctx, "failed to list webhook logs of %d, error: %s", webhook.ID, err.Error())
 				continue
@@ -314,20 +387,8 @@ L:
 				continue
 			}
 			for _, wl := range wls {
-				saveResult := func() {
-					if wl.ErrorMessage != "" {
-						wl.Status = webhookmodels.StatusFailed
-					} else {
-						wl.Status = webhookmodels.StatusSuccess
-					}
-					_, err := w.webhookManager.UpdateWebhookLog(ctx, wl)
-					if err != nil {
-						log.Errorf(ctx, "failed to update webhook log %d, error: %s", wl.ID, err.Error())
-					}
-				}
-
-				wl = w.sendWebhook(ctx, wl)
-				saveResult()
+				wl, statusCode, err := w.sendWebhook(ctx, wl)
+				w.saveResult(ctx, webhook, wl, statusCode, err)
 			}
 		}
 	}
@@ -347,7 +408,7 @@ func (w *worker) Wait() {
 	log.Infof(w.ctx, "webhook worker %d stopped", webhook.ID)
 }
 
-func addWebhookLogID(reqData []byte, id uint) ([]byte, error) {
+func addWebhookLogID(reqData []byte, id uint) (*wlgenerator.MessageContent, error) {
 	var content wlgenerator.MessageContent
 	err := json.Unmarshal([]byte(reqData), &content)
 	if err != nil {
@@ -355,5 +416,5 @@ func addWebhookLogID(reqData []byte, id uint) ([]byte, error) {
 	}
 
 	content.ID = id
-	return json.Marshal(content)
+	return &content, nil
 }
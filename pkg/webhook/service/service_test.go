@@ -0,0 +1,321 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/horizoncd/horizon/lib/orm"
+	"github.com/horizoncd/horizon/pkg/eventhandler/wlgenerator"
+	webhookmanager "github.com/horizoncd/horizon/pkg/webhook/manager"
+	"github.com/horizoncd/horizon/pkg/webhook/models"
+	"github.com/horizoncd/horizon/pkg/webhook/signature"
+)
+
+var (
+	db, _ = orm.NewSqliteDB("")
+	ctx   = context.TODO()
+	mgr   = webhookmanager.New(db)
+)
+
+func TestMain(m *testing.M) {
+	if err := db.AutoMigrate(&models.Webhook{}, &models.WebhookLog{}, &models.WebhookLogAttempt{}); err != nil {
+		panic(err)
+	}
+	os.Exit(m.Run())
+}
+
+func TestSendWithRetry(t *testing.T) {
+	codes := []int{http.StatusServiceUnavailable, http.StatusServiceUnavailable, http.StatusBadRequest,
+		http.StatusServiceUnavailable, http.StatusOK}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(codes[0])
+		codes = codes[1:]
+	}))
+	defer server.Close()
+
+	webhook, err := mgr.CreateWebhook(ctx, &models.Webhook{URL: server.URL, Enabled: true})
+	assert.Nil(t, err)
+	webhook.SetRetryPolicy(&models.RetryPolicy{MaxAttempts: 2, InitialInterval: 10})
+	w := &worker{
+		responseBodyTruncateSize: 1024,
+		retryPolicy:              models.RetryPolicy{MaxAttempts: 1},
+		webhookManager:           mgr,
+	}
+	w.setWebhook(webhook)
+
+	send := func(wl *models.WebhookLog) *models.WebhookLog {
+		wl, statusCode, err := w.sendWebhook(ctx, wl)
+		w.saveResult(ctx, webhook, wl, statusCode, err)
+		got, err := mgr.GetWebhookLog(ctx, wl.ID)
+		assert.Nil(t, err)
+		return got
+	}
+
+	wl, err := mgr.CreateWebhookLog(ctx, &models.WebhookLog{
+		WebhookID:   webhook.ID,
+		URL:         server.URL,
+		RequestData: `{"eventType":"clustersCreated"}`,
+		Status:      models.StatusWaiting,
+	})
+	assert.Nil(t, err)
+
+	// retried after the backoff
+	wl = send(wl)
+	assert.Equal(t, models.StatusRetrying, wl.Status)
+	assert.Equal(t, uint(1), wl.Attempts)
+	assert.True(t, wl.NextAttemptAt.After(time.Now().Add(9*time.Second)))
+
+	// dead lettered after all attempts failed
+	wl = send(wl)
+	assert.Equal(t, models.StatusDeadLetter, wl.Status)
+	assert.Nil(t, wl.NextAttemptAt)
+
+	// not retried on the code not in the policy
+	wl, err = mgr.RedeliverWebhookLog(ctx, wl.ID)
+	assert.Nil(t, err)
+	wl = send(wl)
+	assert.Equal(t, models.StatusFailed, wl.Status)
+
+	// the error of the last attempt is cleared once it succeeds
+	wl, err = mgr.RedeliverWebhookLog(ctx, wl.ID)
+	assert.Nil(t, err)
+	wl = send(wl)
+	assert.Equal(t, models.StatusRetrying, wl.Status)
+	wl = send(wl)
+	assert.Equal(t, models.StatusSuccess, wl.Status)
+	assert.Equal(t, "", wl.ErrorMessage)
+	assert.Equal(t, uint(2), wl.Attempts)
+
+	attempts, err := mgr.ListWebhookLogAttempts(ctx, wl.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, 5, len(attempts))
+	assert.Equal(t, http.StatusBadRequest, attempts[2].StatusCode)
+	assert.Equal(t, uint(1), attempts[2].Attempt)
+
+	// the webhook without its own policy isn't retried by default
+	webhook.SetRetryPolicy(nil)
+	codes = []int{http.StatusServiceUnavailable}
+	wl, err = mgr.RedeliverWebhookLog(ctx, wl.ID)
+	assert.Nil(t, err)
+	wl = send(wl)
+	assert.Equal(t, models.StatusFailed, wl.Status)
+}
+
+func TestSendWithoutResponse(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	webhook, err := mgr.CreateWebhook(ctx, &models.Webhook{URL: server.URL, Enabled: true})
+	assert.Nil(t, err)
+	webhook.SetRetryPolicy(&models.RetryPolicy{MaxAttempts: 3, InitialInterval: 10})
+	w := &worker{
+		responseBodyTruncateSize: 1024,
+		webhookManager:           mgr,
+	}
+	w.setWebhook(webhook)
+
+	send := func(wl *models.WebhookLog) *models.WebhookLog {
+		wl, statusCode, err := w.sendWebhook(ctx, wl)
+		assert.Equal(t, 0, statusCode)
+		w.saveResult(ctx, webhook, wl, statusCode, err)
+		got, err := mgr.GetWebhookLog(ctx, wl.ID)
+		assert.Nil(t, err)
+		return got
+	}
+
+	// the log which can't be made into a request fails without retry
+	wl, err := mgr.CreateWebhookLog(ctx, &models.WebhookLog{
+		WebhookID:      webhook.ID,
+		URL:            server.URL,
+		RequestHeaders: "Content-Type: [",
+		RequestData:    `{"eventType":"clustersCreated"}`,
+		Status:         models.StatusWaiting,
+	})
+	assert.Nil(t, err)
+	wl = send(wl)
+	assert.Equal(t, models.StatusFailed, wl.Status)
+	assert.Equal(t, uint(1), wl.Attempts)
+	assert.Nil(t, wl.NextAttemptAt)
+	assert.Contains(t, wl.ErrorMessage, "failed to unmarshal header")
+
+	// the log is retried if the server can't be connected
+	server.Close()
+	wl, err = mgr.CreateWebhookLog(ctx, &models.WebhookLog{
+		WebhookID:   webhook.ID,
+		URL:         server.URL,
+		RequestData: `{"eventType":"clustersCreated"}`,
+		Status:      models.StatusWaiting,
+	})
+	assert.Nil(t, err)
+	wl = send(wl)
+	assert.Equal(t, models.StatusRetrying, wl.Status)
+	assert.Contains(t, wl.ErrorMessage, "failed to send req")
+}
+
+func TestBackoff(t *testing.T) {
+	policy := &models.RetryPolicy{MaxAttempts: 5, InitialInterval: 10, MaxInterval: 60, Multiplier: 2}
+	assert.Equal(t, 10*time.Second, policy.Backoff(1))
+	assert.Equal(t, 20*time.Second, policy.Backoff(2))
+	assert.Equal(t, 40*time.Second, policy.Backoff(3))
+	assert.Equal(t, 60*time.Second, policy.Backoff(4))
+
+	assert.True(t, policy.ShouldRetry(0, &url.Error{Op: "Post", Err: &net.OpError{Op: "dial", Err: syscall.ECONNREFUSED}}))
+	assert.True(t, policy.ShouldRetry(0, &url.Error{Op: "Post", Err: syscall.ECONNRESET}))
+	assert.False(t, policy.ShouldRetry(0, &url.Error{Op: "Post", Err: errors.New("unsupported protocol scheme")}))
+	assert.False(t, policy.ShouldRetry(0, errors.New("failed to unmarshal header")))
+	assert.True(t, policy.ShouldRetry(http.StatusTooManyRequests, nil))
+	assert.True(t, policy.ShouldRetry(http.StatusBadGateway, nil))
+	assert.False(t, policy.ShouldRetry(http.StatusNotFound, nil))
+}
+
+func TestSignAndCloudEvents(t *testing.T) {
+	var (
+		header http.Header
+		body   []byte
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header
+		body, _ = ioutil.ReadAll(r.Body)
+	}))
+	defer server.Close()
+
+	webhook, err := mgr.CreateWebhook(ctx, &models.Webhook{URL: server.URL, Enabled: true, Secret: "new"})
+	assert.Nil(t, err)
+	w := &worker{
+		responseBodyTruncateSize: 1024,
+		webhookManager:           mgr,
+	}
+	w.setWebhook(webhook)
+
+	wl, err := mgr.CreateWebhookLog(ctx, &models.WebhookLog{
+		WebhookID:      webhook.ID,
+		URL:            server.URL,
+		RequestHeaders: "Content-Type:\n    - application/json;charset=utf-8\n",
+		RequestData:    `{"eventID":3,"cluster":{"id":2,"name":"c"},"eventType":"clustersCreated"}`,
+		Status:         models.StatusWaiting,
+	})
+	assert.Nil(t, err)
+
+	// signed by the secret, the plain secret isn't sent
+	_, statusCode, _ := w.sendWebhook(ctx, wl)
+	assert.Equal(t, http.StatusOK, statusCode)
+	assert.Nil(t, signature.Verify(header, body, "new", time.Now(), signature.DefaultReplayWindow))
+	assert.Equal(t, "", header.Get(wlgenerator.WebhookSecretHeader))
+	content := wlgenerator.MessageContent{}
+	assert.Nil(t, json.Unmarshal(body, &content))
+	assert.Equal(t, wl.ID, content.ID)
+
+	// signed by both secrets before the previous one expires
+	expiredAt := time.Now().Add(time.Hour)
+	webhook.PreviousSecret, webhook.PreviousSecretExpiredAt = "old", &expiredAt
+	w.sendWebhook(ctx, wl)
+	assert.Nil(t, signature.Verify(header, body, "new", time.Now(), signature.DefaultReplayWindow))
+	assert.Nil(t, signature.Verify(header, body, "old", time.Now(), signature.DefaultReplayWindow))
+	expiredAt = time.Now().Add(-time.Second)
+	w.sendWebhook(ctx, wl)
+	assert.Equal(t, signature.ErrMismatch,
+		signature.Verify(header, body, "old", time.Now(), signature.DefaultReplayWindow))
+
+	// structured mode
+	webhook.ContentMode = models.ContentModeCloudEventsStructured
+	w.sendWebhook(ctx, wl)
+	assert.Nil(t, signature.Verify(header, body, "new", time.Now(), signature.DefaultReplayWindow))
+	assert.Equal(t, _cloudEventsContentType, header.Get(_headerContentType))
+	event := cloudEvent{}
+	assert.Nil(t, json.Unmarshal(body, &event))
+	assert.Equal(t, "1.0", event.SpecVersion)
+	assert.Equal(t, "3", event.ID)
+	assert.Equal(t, "/clusters/2", event.Source)
+	assert.Equal(t, "cd.horizon.clustersCreated", event.Type)
+	assert.Equal(t, "c", event.Subject)
+	assert.Equal(t, wl.ID, event.Data.ID)
+
+	// binary mode
+	webhook.ContentMode = models.ContentModeCloudEventsBinary
+	w.sendWebhook(ctx, wl)
+	assert.Equal(t, "application/json;charset=utf-8", header.Get(_headerContentType))
+	assert.Equal(t, "1.0", header.Get("ce-specversion"))
+	assert.Equal(t, "3", header.Get("ce-id"))
+	assert.Equal(t, "/clusters/2", header.Get("ce-source"))
+	assert.Equal(t, "cd.horizon.clustersCreated", header.Get("ce-type"))
+	assert.NotEmpty(t, header.Get("ce-time"))
+	assert.Nil(t, json.Unmarshal(body, &content))
+	assert.Equal(t, "clustersCreated", content.EventType)
+
+	// the plain secret is still sent if it's configured
+	w.legacySecretHeader = true
+	w.sendWebhook(ctx, wl)
+	assert.Equal(t, "new", header.Get(wlgenerator.WebhookSecretHeader))
+}
+
+func TestSendNotification(t *testing.T) {
+	var body []byte
+	respBody := "ok"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ = ioutil.ReadAll(r.Body)
+		_, _ = w.Write([]byte(respBody))
+	}))
+	defer server.Close()
+
+	webhook, err := mgr.CreateWebhook(ctx, &models.Webhook{URL: server.URL, Enabled: true,
+		Channel: models.ChannelSlack})
+	assert.Nil(t, err)
+	webhook.SetChannelConfig(&models.ChannelConfig{Templates: map[string]*models.NotificationTemplate{
+		"*": {Title: "{{.eventType}}", Text: "{{.cluster.name}} by {{.user.name}}"},
+	}})
+	w := &worker{
+		responseBodyTruncateSize: 1024,
+		webhookManager:           mgr,
+	}
+	w.setWebhook(webhook)
+
+	wl, err := mgr.CreateWebhookLog(ctx, &models.WebhookLog{
+		WebhookID:   webhook.ID,
+		URL:         server.URL,
+		RequestData: `{"cluster":{"id":2,"name":"c"},"eventType":"clusters_freed","user":{"name":"tom"}}`,
+		Status:      models.StatusWaiting,
+	})
+	assert.Nil(t, err)
+
+	wl, statusCode, _ := w.sendWebhook(ctx, wl)
+	assert.Equal(t, http.StatusOK, statusCode)
+	assert.Equal(t, "", wl.ErrorMessage)
+	assert.Equal(t, `{"text":"*clusters_freed*\nc by tom"}`, string(body))
+
+	// dingtalk responds errors with 200
+	webhook.Channel = models.ChannelDingTalk
+	respBody = `{"errcode":310000,"errmsg":"keywords not in content"}`
+	wl, statusCode, _ = w.sendWebhook(ctx, wl)
+	assert.Equal(t, http.StatusOK, statusCode)
+	assert.Contains(t, wl.ErrorMessage, "keywords not in content")
+
+	// the mail server is not configured
+	webhook.Channel = models.ChannelEmail
+	wl, statusCode, _ = w.sendWebhook(ctx, wl)
+	assert.Equal(t, 0, statusCode)
+	assert.Contains(t, wl.ErrorMessage, "smtp server is not configured")
+}
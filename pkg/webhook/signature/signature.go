@@ -0,0 +1,104 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package signature signs webhook requests with HMAC-SHA256 and verifies them.
+//
+// The signed content is the unix timestamp and the body joined by a dot, e.g. "1690000000.{...}".
+// A request is signed by each of the secrets of the webhook, so receivers keep verifying
+// with the old secret while the secret is rotated.
+package signature
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// HeaderTimestamp is the unix timestamp when the request is signed
+	HeaderTimestamp = "X-Horizon-Webhook-Timestamp"
+	// HeaderSignature is the signatures of the request, e.g. "v1=5257a869...,v1=6ffbb59b..."
+	HeaderSignature = "X-Horizon-Webhook-Signature"
+
+	// DefaultReplayWindow is how long a signed request is accepted
+	DefaultReplayWindow = 5 * time.Minute
+
+	_version   = "v1"
+	_separator = ","
+)
+
+var (
+	ErrMissingSignature = errors.New("signature or timestamp is missing")
+	ErrInvalidTimestamp = errors.New("timestamp is invalid")
+	ErrOutOfWindow      = errors.New("timestamp is out of the replay window")
+	ErrMismatch         = errors.New("no signature matches")
+)
+
+// Sign sets the timestamp and the signatures of the body by each of the secrets in the headers,
+// nothing is set if there is no secret
+func Sign(header http.Header, body []byte, timestamp time.Time, secrets ...string) {
+	signatures := make([]string, 0, len(secrets))
+	ts := strconv.FormatInt(timestamp.Unix(), 10)
+	for _, secret := range secrets {
+		if secret == "" {
+			continue
+		}
+		signatures = append(signatures, _version+"="+compute(secret, ts, body))
+	}
+	if len(signatures) == 0 {
+		return
+	}
+	header.Set(HeaderTimestamp, ts)
+	header.Set(HeaderSignature, strings.Join(signatures, _separator))
+}
+
+// Verify verifies the request is signed by the secret within the replay window before now
+func Verify(header http.Header, body []byte, secret string, now time.Time, window time.Duration) error {
+	ts, signatures := header.Get(HeaderTimestamp), header.Get(HeaderSignature)
+	if ts == "" || signatures == "" {
+		return ErrMissingSignature
+	}
+	unix, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil {
+		return ErrInvalidTimestamp
+	}
+	if diff := now.Sub(time.Unix(unix, 0)); diff > window || diff < -window {
+		return ErrOutOfWindow
+	}
+
+	expected := []byte(compute(secret, ts, body))
+	for _, signature := range strings.Split(signatures, _separator) {
+		parts := strings.SplitN(strings.TrimSpace(signature), "=", 2)
+		if len(parts) != 2 || parts[0] != _version {
+			continue
+		}
+		if hmac.Equal([]byte(parts[1]), expected) {
+			return nil
+		}
+	}
+	return ErrMismatch
+}
+
+func compute(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,51 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package signature
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSignAndVerify(t *testing.T) {
+	body := []byte(`{"eventType":"clustersCreated"}`)
+	now := time.Unix(1690000000, 0)
+
+	header := http.Header{}
+	Sign(header, body, now)
+	assert.Equal(t, "", header.Get(HeaderSignature))
+	assert.Equal(t, ErrMissingSignature, Verify(header, body, "new", now, DefaultReplayWindow))
+
+	// signed by both the new and the old secret while rotating
+	Sign(header, body, now, "new", "old")
+	assert.Equal(t, "1690000000", header.Get(HeaderTimestamp))
+	assert.Nil(t, Verify(header, body, "new", now, DefaultReplayWindow))
+	assert.Nil(t, Verify(header, body, "old", now.Add(time.Minute), DefaultReplayWindow))
+	assert.Equal(t, ErrMismatch, Verify(header, body, "other", now, DefaultReplayWindow))
+	assert.Equal(t, ErrMismatch, Verify(header, []byte(`{}`), "new", now, DefaultReplayWindow))
+
+	// replayed out of the window
+	assert.Equal(t, ErrOutOfWindow, Verify(header, body, "new", now.Add(10*time.Minute), DefaultReplayWindow))
+	assert.Equal(t, ErrOutOfWindow, Verify(header, body, "new", now.Add(-10*time.Minute), DefaultReplayWindow))
+
+	// the timestamp is signed
+	header.Set(HeaderTimestamp, "1690000001")
+	assert.Equal(t, ErrMismatch, Verify(header, body, "new", now, DefaultReplayWindow))
+	header.Set(HeaderTimestamp, "now")
+	assert.Equal(t, ErrInvalidTimestamp, Verify(header, body, "new", now, DefaultReplayWindow))
+}